@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/model"
 	"ad-tracker/youtube-webhook-ingestion/internal/queue"
+	"ad-tracker/youtube-webhook-ingestion/internal/service"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/ollama"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
@@ -26,6 +31,12 @@ const (
 	defaultBatchSize      = 50
 	defaultDailyQuota     = 10000
 	defaultQuotaThreshold = 90 // Stop at 90% of quota
+	defaultQuotaReserve   = 0  // Background work shares the full threshold unless a reserve is configured
+
+	defaultTrendingSnapshotMinutes = 360 // Snapshot trending charts four times a day
+
+	// callbackShutdownTimeout bounds how long shutdown waits for queued HTTP callbacks
+	callbackShutdownTimeout = 15 * time.Second
 )
 
 type Config struct {
@@ -34,6 +45,7 @@ type Config struct {
 	YouTubeAPIKey           string
 	DailyQuota              int
 	QuotaThreshold          int
+	QuotaReserve            int
 	Concurrency             int
 	BatchSize               int
 	EnrichmentEnabled       bool
@@ -43,6 +55,70 @@ type Config struct {
 	OllamaModel             string
 	OllamaTimeout           int
 	OllamaAPIKey            string
+	EvidenceMaxLength       int
+	SponsorCommitChunkSize  int
+	LiveVideoPolicy         string
+	LiveRecheckMinutes      int
+	CompressRawResponse     bool
+
+	// StoreRawResponse keeps raw_api_response on new enrichments; when false only the
+	// structured columns are written
+	StoreRawResponse bool
+
+	// InferCaptionLanguage infers a language from caption tracks for videos whose snippet
+	// reports none, at 50 extra quota units per such video
+	InferCaptionLanguage bool
+
+	// SponsorDenylist names sponsors that are never stored; SponsorDenylistFile adds one
+	// name per line and is re-read on SIGHUP
+	SponsorDenylist     []string
+	SponsorDenylistFile string
+
+	// AvailabilityCheckMinutes is how often older videos are re-fetched to detect
+	// silent removals; 0 disables the check
+	AvailabilityCheckMinutes int
+
+	// TrendingRegions are the region codes whose mostPopular chart is snapshotted;
+	// empty disables trending snapshots
+	TrendingRegions         []string
+	TrendingSnapshotMinutes int
+
+	// SponsorBackfillMinutes is how often enriched videos without a sponsor detection job
+	// are enqueued for detection; 0 disables the backfill
+	SponsorBackfillMinutes int
+
+	// LiveViewerPollMinutes is how often concurrent viewers of live videos are sampled;
+	// 0 disables the poller
+	LiveViewerPollMinutes int
+
+	// SponsorBreakerThreshold pauses the sponsor_detection queue for SponsorBreakerCooldownSeconds
+	// after this many consecutive tasks find Ollama unreachable; 0 disables the breaker
+	SponsorBreakerThreshold       int
+	SponsorBreakerCooldownSeconds int
+
+	// WriteConcurrency bounds concurrent enrichment inserts; 0 derives it from the pool size
+	// (max connections minus queue.DefaultWriteHeadroom), negative removes the bound
+	WriteConcurrency int
+
+	// EnrichmentCallbackURLs are POSTed a summary of each successful enrichment, signed with
+	// EnrichmentCallbackSecret when set and retried up to EnrichmentCallbackAttempts times
+	EnrichmentCallbackURLs     []string
+	EnrichmentCallbackSecret   string
+	EnrichmentCallbackAttempts int
+
+	// MetricsAddr is the address serving expvar metrics at /debug/vars; empty disables it
+	MetricsAddr string
+
+	// QuotaSyncSeconds keeps quota usage in memory, reconciling it with the database at
+	// most this often; 0 queries the database on every check
+	QuotaSyncSeconds int
+
+	// QuotaCosts is the quota cost of each YouTube API operation, from YOUTUBE_QUOTA_COSTS overrides
+	QuotaCosts quota.CostTable
+
+	// EnrichPrivacyStatuses lists the privacy statuses whose videos are stored after the
+	// fetch; empty stores every video
+	EnrichPrivacyStatuses []string
 }
 
 func main() {
@@ -60,6 +136,7 @@ func main() {
 		"batch_size", config.BatchSize,
 		"daily_quota", config.DailyQuota,
 		"quota_threshold", config.QuotaThreshold,
+		"quota_interactive_reserve", config.QuotaReserve,
 		"enabled", config.EnrichmentEnabled,
 	)
 
@@ -87,10 +164,18 @@ func main() {
 	logger.Info("database connection established")
 
 	// Initialize repositories
-	enrichmentRepo := repository.NewEnrichmentRepository(pool)
-	channelEnrichmentRepo := repository.NewChannelEnrichmentRepository(pool)
+	enrichmentRepoConfig := repository.EnrichmentRepositoryConfig{
+		CompressRawResponse: config.CompressRawResponse,
+		DropRawResponse:     !config.StoreRawResponse,
+	}
+	enrichmentRepo := repository.NewEnrichmentRepositoryWithConfig(pool, enrichmentRepoConfig)
+	channelEnrichmentRepo := repository.NewChannelEnrichmentRepositoryWithConfig(pool, enrichmentRepoConfig)
 	quotaRepo := repository.NewQuotaRepository(pool)
 	jobRepo := repository.NewEnrichmentJobRepository(pool)
+	videoRepo := repository.NewVideoRepository(pool)
+	channelRepo := repository.NewChannelRepository(pool)
+	trendingRepo := repository.NewTrendingRepository(pool)
+	liveViewerRepo := repository.NewLiveViewerRepository(pool)
 
 	// Initialize YouTube API client
 	youtubeClient, err := youtube.NewClient(config.YouTubeAPIKey)
@@ -103,9 +188,13 @@ func main() {
 
 	// Initialize quota manager
 	quotaManager := quota.NewManager(quotaRepo, config.DailyQuota, config.QuotaThreshold)
+	quotaManager.SetInteractiveReserve(config.QuotaReserve)
+	quotaManager.SetSyncInterval(time.Duration(config.QuotaSyncSeconds) * time.Second)
 
 	// Wire up quota tracking to YouTube client
 	youtubeClient.SetQuotaTracker(quotaManager)
+	youtubeClient.SetQuotaCosts(config.QuotaCosts)
+	youtubeClient.SetCaptionLanguageInference(config.InferCaptionLanguage)
 
 	// Check initial quota status
 	quotaInfo, err := quotaManager.GetQuotaInfo(ctx)
@@ -145,8 +234,50 @@ func main() {
 		jobRepo,
 		config.BatchSize,
 	)
+	handler.SetVideoRepository(videoRepo)
+	handler.SetChannelRepository(channelRepo)
+
+	writeConcurrency := config.WriteConcurrency
+	if writeConcurrency == 0 {
+		writeConcurrency = max(int(pool.Config().MaxConns)-queue.DefaultWriteHeadroom, 1)
+	}
+	handler.SetWriteConcurrencyLimit(writeConcurrency)
+	logger.Info("enrichment write concurrency configured",
+		"limit", writeConcurrency,
+		"pool_max_conns", pool.Config().MaxConns,
+	)
+
+	// Initialize queue client for re-enqueueing deferred videos and sponsor detection callbacks
+	queueClient, err := queue.NewClient(config.RedisURL, jobRepo)
+	if err != nil {
+		logger.Error("failed to create queue client", "error", err)
+		os.Exit(1)
+	}
+	defer queueClient.Close()
+
+	// Configure handling of live streams and premieres
+	livePolicy, err := queue.ParseLiveVideoPolicy(config.LiveVideoPolicy)
+	if err != nil {
+		logger.Error("invalid LIVE_VIDEO_POLICY", "error", err)
+		os.Exit(1)
+	}
+	handler.SetLiveVideoPolicy(livePolicy, time.Duration(config.LiveRecheckMinutes)*time.Minute, queueClient)
+
+	logger.Info("live video policy configured",
+		"policy", livePolicy,
+		"recheck_minutes", config.LiveRecheckMinutes,
+	)
+
+	handler.SetPrivacyStatusAllowlist(config.EnrichPrivacyStatuses)
+	if len(config.EnrichPrivacyStatuses) > 0 {
+		logger.Info("privacy status allowlist configured", "statuses", config.EnrichPrivacyStatuses)
+	}
+
+	// Callbacks run after each successful enrichment
+	callbackManager := queue.NewCallbackManager()
 
 	// Configure sponsor detection if enabled
+	var sponsorDetectionRepo repository.SponsorDetectionRepository
 	if config.SponsorDetectionEnabled {
 		if config.OllamaBaseURL == "" || config.OllamaModel == "" {
 			logger.Error("OLLAMA_BASE_URL and OLLAMA_MODEL are required when SPONSOR_DETECTION_ENABLED=true")
@@ -168,27 +299,62 @@ func main() {
 			Timeout: time.Duration(config.OllamaTimeout) * time.Second,
 		})
 
+		sponsorDenylist := models.NewSponsorDenylist(nil)
+		if err := loadSponsorDenylist(sponsorDenylist, config); err != nil {
+			logger.Error("failed to load sponsor denylist", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("sponsor denylist loaded", "names", sponsorDenylist.Len(), "file", config.SponsorDenylistFile)
+
+		// Re-read the denylist file on SIGHUP so names can be added without a restart
+		if config.SponsorDenylistFile != "" {
+			reload := make(chan os.Signal, 1)
+			signal.Notify(reload, syscall.SIGHUP)
+			go func() {
+				for range reload {
+					if err := loadSponsorDenylist(sponsorDenylist, config); err != nil {
+						logger.Warn("failed to reload sponsor denylist, keeping the previous list", "error", err)
+						continue
+					}
+					logger.Info("sponsor denylist reloaded", "names", sponsorDenylist.Len())
+				}
+			}()
+		}
+
 		// Initialize sponsor detection repository
-		sponsorDetectionRepo := repository.NewSponsorDetectionRepository(pool)
+		sponsorDetectionRepo = repository.NewSponsorDetectionRepositoryWithConfig(pool, repository.SponsorDetectionRepositoryConfig{
+			MaxEvidenceLength: config.EvidenceMaxLength,
+			CommitChunkSize:   config.SponsorCommitChunkSize,
+			Denylist:          sponsorDenylist,
+		})
 
 		// Configure handler with sponsor detection
 		handler.SetSponsorDetection(ollamaClient, sponsorDetectionRepo, true)
-
-		// Initialize queue client for callbacks
-		queueClient, err := queue.NewClient(config.RedisURL, jobRepo)
-		if err != nil {
-			logger.Error("failed to create queue client for sponsor detection", "error", err)
-			os.Exit(1)
-		}
-		defer queueClient.Close()
+		handler.SetSponsorDetectionBreaker(queueClient, config.SponsorBreakerThreshold, time.Duration(config.SponsorBreakerCooldownSeconds)*time.Second)
 
 		// Register sponsor detection callback
-		handler.SetCallbackManager(queue.NewCallbackManager())
-		handler.SetCallbackManager(registerSponsorDetectionCallback(logger, queueClient, sponsorDetectionRepo, config.OllamaModel))
+		registerSponsorDetectionCallback(callbackManager, logger, queueClient, sponsorDetectionRepo, config.OllamaModel)
 	} else {
 		logger.Info("sponsor detection disabled")
 	}
 
+	// Notify external systems of completed enrichments
+	var callbackNotifier *queue.HTTPCallbackNotifier
+	if len(config.EnrichmentCallbackURLs) > 0 {
+		callbackNotifier = queue.NewHTTPCallbackNotifier(queue.HTTPCallbackConfig{
+			URLs:     config.EnrichmentCallbackURLs,
+			Secret:   config.EnrichmentCallbackSecret,
+			Attempts: config.EnrichmentCallbackAttempts,
+		})
+		callbackManager.RegisterCallback(callbackNotifier.Notify)
+		logger.Info("enrichment HTTP callbacks enabled",
+			"urls", len(config.EnrichmentCallbackURLs),
+			"signed", config.EnrichmentCallbackSecret != "",
+		)
+	}
+
+	handler.SetCallbackManager(callbackManager)
+
 	// Calculate total concurrency (enrichment + sponsor detection workers)
 	totalConcurrency := config.Concurrency
 	if config.SponsorDetectionEnabled {
@@ -202,6 +368,64 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Periodic background jobs stop when the service shuts down
+	backgroundCtx, stopBackgroundJobs := context.WithCancel(ctx)
+	defer stopBackgroundJobs()
+
+	// Periodically enqueue sponsor detection for enriched videos that never had a detection job
+	if sponsorDetectionRepo != nil && config.SponsorBackfillMinutes > 0 {
+		backfiller := service.NewSponsorDetectionBackfiller(sponsorDetectionRepo, queueClient, config.OllamaModel, 0)
+		go backfiller.Run(backgroundCtx, time.Duration(config.SponsorBackfillMinutes)*time.Minute)
+
+		logger.Info("sponsor detection backfill enabled", "interval_minutes", config.SponsorBackfillMinutes)
+	}
+
+	// Periodically re-check older videos for silent removals
+	if config.AvailabilityCheckMinutes > 0 {
+		availabilityChecker := service.NewVideoAvailabilityChecker(videoRepo, youtubeClient, quotaManager, service.VideoAvailabilityConfig{})
+		go availabilityChecker.Run(backgroundCtx, time.Duration(config.AvailabilityCheckMinutes)*time.Minute)
+
+		logger.Info("video availability check enabled", "interval_minutes", config.AvailabilityCheckMinutes)
+	} else {
+		logger.Info("video availability check disabled")
+	}
+
+	// Periodically record which tracked videos are on the trending charts
+	if len(config.TrendingRegions) > 0 && config.TrendingSnapshotMinutes > 0 {
+		trendingSnapshotter := service.NewTrendingSnapshotter(trendingRepo, youtubeClient, quotaManager, config.TrendingRegions)
+		go trendingSnapshotter.Run(backgroundCtx, time.Duration(config.TrendingSnapshotMinutes)*time.Minute)
+
+		logger.Info("trending snapshots enabled",
+			"regions", config.TrendingRegions,
+			"interval_minutes", config.TrendingSnapshotMinutes,
+		)
+	} else {
+		logger.Info("trending snapshots disabled")
+	}
+
+	// Periodically sample concurrent viewers of ongoing livestreams
+	if config.LiveViewerPollMinutes > 0 {
+		liveViewerPoller := service.NewLiveViewerPoller(liveViewerRepo, youtubeClient, quotaManager, 0)
+		go liveViewerPoller.Run(backgroundCtx, time.Duration(config.LiveViewerPollMinutes)*time.Minute)
+
+		logger.Info("live viewer polling enabled", "interval_minutes", config.LiveViewerPollMinutes)
+	}
+
+	// Serve expvar metrics, such as queue.QuotaBlockedTasks
+	var metricsServer *http.Server
+	if config.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/debug/vars", expvar.Handler())
+		metricsServer = &http.Server{Addr: config.MetricsAddr, Handler: metricsMux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics server error", "error", err)
+			}
+		}()
+
+		logger.Info("metrics enabled", "addr", config.MetricsAddr, "path", "/debug/vars")
+	}
+
 	// Set up graceful shutdown
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
@@ -224,7 +448,21 @@ func main() {
 		os.Exit(1)
 	case sig := <-shutdown:
 		logger.Info("shutdown signal received", "signal", sig)
+		stopBackgroundJobs()
 		server.Stop()
+		if callbackNotifier != nil {
+			callbackCtx, cancel := context.WithTimeout(context.Background(), callbackShutdownTimeout)
+			if err := callbackNotifier.Shutdown(callbackCtx); err != nil {
+				logger.Warn("HTTP callbacks still pending at shutdown were dropped", "error", err)
+			}
+			cancel()
+		}
+		if metricsServer != nil {
+			metricsServer.Close()
+		}
+		if err := quotaManager.Sync(context.Background()); err != nil {
+			logger.Error("failed to persist quota usage", "error", err)
+		}
 		logger.Info("enrichment service stopped gracefully")
 	}
 }
@@ -247,12 +485,16 @@ func loadConfig() *Config {
 	// Parse numeric configs
 	dailyQuota := getEnvInt("YOUTUBE_DAILY_QUOTA", defaultDailyQuota)
 	quotaThreshold := getEnvInt("QUOTA_THRESHOLD_PERCENT", defaultQuotaThreshold)
+	quotaReserve := getEnvInt("QUOTA_INTERACTIVE_RESERVE_PERCENT", defaultQuotaReserve)
 	concurrency := getEnvInt("ENRICHMENT_WORKERS", defaultConcurrency)
 	batchSize := getEnvInt("ENRICHMENT_BATCH_SIZE", defaultBatchSize)
 
 	// Parse boolean config
 	enrichmentEnabled := getEnvBool("ENRICHMENT_ENABLED", true)
 	sponsorDetectionEnabled := getEnvBool("SPONSOR_DETECTION_ENABLED", false)
+	compressRawResponse := getEnvBool("ENRICHMENT_COMPRESS_RAW_RESPONSE", false)
+	storeRawResponse := getEnvBool("STORE_RAW_RESPONSE", true)
+	inferCaptionLanguage := getEnvBool("ENRICHMENT_INFER_CAPTION_LANGUAGE", false)
 
 	// Sponsor detection config
 	sponsorDetectionWorkers := getEnvInt("SPONSOR_DETECTION_WORKERS", 1)
@@ -260,6 +502,65 @@ func loadConfig() *Config {
 	ollamaModel := os.Getenv("OLLAMA_MODEL")
 	ollamaTimeout := getEnvInt("OLLAMA_TIMEOUT", 60)
 	ollamaAPIKey := os.Getenv("OLLAMA_API_KEY") // Optional
+	evidenceMaxLength := getEnvInt("SPONSOR_EVIDENCE_MAX_LENGTH", repository.DefaultMaxEvidenceLength)
+	sponsorCommitChunkSize := getEnvInt("SPONSOR_COMMIT_CHUNK_SIZE", 0) // 0 = single transaction
+	var sponsorDenylist []string
+	for _, name := range strings.Split(os.Getenv("SPONSOR_DENYLIST"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sponsorDenylist = append(sponsorDenylist, name)
+		}
+	}
+	sponsorDenylistFile := os.Getenv("SPONSOR_DENYLIST_FILE")
+	sponsorBreakerThreshold := getEnvInt("SPONSOR_DETECTION_BREAKER_THRESHOLD", 0)
+	sponsorBreakerCooldownSeconds := getEnvInt("SPONSOR_DETECTION_BREAKER_COOLDOWN_SECONDS", 300)
+
+	// Live stream and premiere handling: enrich, defer, or skip
+	liveVideoPolicy := os.Getenv("LIVE_VIDEO_POLICY")
+	if liveVideoPolicy == "" {
+		liveVideoPolicy = string(queue.LiveVideoPolicyEnrich)
+	}
+	liveRecheckMinutes := getEnvInt("LIVE_VIDEO_RECHECK_MINUTES", int(queue.DefaultLiveRecheckDelay/time.Minute))
+
+	availabilityCheckMinutes := getEnvInt("VIDEO_AVAILABILITY_CHECK_MINUTES", 0)
+
+	// Trending charts: comma-separated region codes such as "US,GB"
+	var trendingRegions []string
+	for _, region := range strings.Split(os.Getenv("TRENDING_REGIONS"), ",") {
+		if region = strings.ToUpper(strings.TrimSpace(region)); region != "" {
+			trendingRegions = append(trendingRegions, region)
+		}
+	}
+	trendingSnapshotMinutes := getEnvInt("TRENDING_SNAPSHOT_MINUTES", defaultTrendingSnapshotMinutes)
+	sponsorBackfillMinutes := getEnvInt("SPONSOR_DETECTION_BACKFILL_MINUTES", 0)
+	liveViewerPollMinutes := getEnvInt("LIVE_VIEWER_POLL_MINUTES", 0)
+	writeConcurrency := getEnvInt("ENRICHMENT_WRITE_CONCURRENCY", 0)
+
+	// Enrichment callbacks: comma-separated URLs POSTed a signed summary of each enrichment
+	var enrichmentCallbackURLs []string
+	for _, url := range strings.Split(os.Getenv("ENRICHMENT_CALLBACK_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			enrichmentCallbackURLs = append(enrichmentCallbackURLs, url)
+		}
+	}
+	enrichmentCallbackSecret := os.Getenv("ENRICHMENT_CALLBACK_SECRET")
+	enrichmentCallbackAttempts := getEnvInt("ENRICHMENT_CALLBACK_ATTEMPTS", queue.DefaultHTTPCallbackAttempts)
+
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	quotaSyncSeconds := getEnvInt("QUOTA_SYNC_SECONDS", 0)
+
+	// Quota costs: comma-separated operation=cost overrides such as "search_list=100"
+	quotaCosts, err := quota.ParseCostTable(os.Getenv("YOUTUBE_QUOTA_COSTS"))
+	if err != nil {
+		slog.Error("invalid YOUTUBE_QUOTA_COSTS", "error", err)
+		os.Exit(1)
+	}
+
+	// Privacy allowlist: comma-separated statuses such as "public,unlisted"
+	enrichPrivacyStatuses, err := queue.ParsePrivacyStatuses(os.Getenv("ENRICH_PRIVACY_STATUSES"))
+	if err != nil {
+		slog.Error("invalid ENRICH_PRIVACY_STATUSES", "error", err)
+		os.Exit(1)
+	}
 
 	return &Config{
 		DatabaseURL:             databaseURL,
@@ -267,6 +568,7 @@ func loadConfig() *Config {
 		YouTubeAPIKey:           youtubeAPIKey,
 		DailyQuota:              dailyQuota,
 		QuotaThreshold:          quotaThreshold,
+		QuotaReserve:            quotaReserve,
 		Concurrency:             concurrency,
 		BatchSize:               batchSize,
 		EnrichmentEnabled:       enrichmentEnabled,
@@ -276,15 +578,70 @@ func loadConfig() *Config {
 		OllamaModel:             ollamaModel,
 		OllamaTimeout:           ollamaTimeout,
 		OllamaAPIKey:            ollamaAPIKey,
+		EvidenceMaxLength:       evidenceMaxLength,
+		SponsorCommitChunkSize:  sponsorCommitChunkSize,
+		SponsorDenylist:         sponsorDenylist,
+		SponsorDenylistFile:     sponsorDenylistFile,
+		LiveVideoPolicy:         liveVideoPolicy,
+		LiveRecheckMinutes:      liveRecheckMinutes,
+		CompressRawResponse:     compressRawResponse,
+
+		StoreRawResponse: storeRawResponse,
+
+		InferCaptionLanguage: inferCaptionLanguage,
+
+		AvailabilityCheckMinutes: availabilityCheckMinutes,
+
+		TrendingRegions:         trendingRegions,
+		TrendingSnapshotMinutes: trendingSnapshotMinutes,
+		SponsorBackfillMinutes:  sponsorBackfillMinutes,
+		LiveViewerPollMinutes:   liveViewerPollMinutes,
+
+		SponsorBreakerThreshold:       sponsorBreakerThreshold,
+		SponsorBreakerCooldownSeconds: sponsorBreakerCooldownSeconds,
+
+		WriteConcurrency: writeConcurrency,
+
+		EnrichmentCallbackURLs:     enrichmentCallbackURLs,
+		EnrichmentCallbackSecret:   enrichmentCallbackSecret,
+		EnrichmentCallbackAttempts: enrichmentCallbackAttempts,
+
+		MetricsAddr: metricsAddr,
+
+		QuotaSyncSeconds: quotaSyncSeconds,
+		QuotaCosts:       quotaCosts,
+
+		EnrichPrivacyStatuses: enrichPrivacyStatuses,
 	}
 }
 
+// loadSponsorDenylist replaces the denylist with the names from SPONSOR_DENYLIST and SPONSOR_DENYLIST_FILE
+func loadSponsorDenylist(denylist *models.SponsorDenylist, config *Config) error {
+	names := append([]string(nil), config.SponsorDenylist...)
+
+	if config.SponsorDenylistFile != "" {
+		contents, err := os.ReadFile(config.SponsorDenylistFile)
+		if err != nil {
+			return fmt.Errorf("read sponsor denylist file: %w", err)
+		}
+		names = append(names, models.ParseSponsorDenylist(string(contents))...)
+	}
+
+	denylist.Replace(names)
+	return nil
+}
+
 func initDatabase(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
 	}
 
+	// pgxpool defaults apply unless overridden per deployment
+	if err := db.ApplyPoolEnv(config); err != nil {
+		return nil, fmt.Errorf("failed to configure connection pool: %w", err)
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -336,10 +693,8 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return boolVal
 }
 
-// registerSponsorDetectionCallback creates and returns a callback manager with sponsor detection registered
-func registerSponsorDetectionCallback(logger *slog.Logger, queueClient *queue.Client, sponsorDetectionRepo repository.SponsorDetectionRepository, llmModel string) *queue.CallbackManager {
-	callbackManager := queue.NewCallbackManager()
-
+// registerSponsorDetectionCallback registers sponsor detection on the callback manager
+func registerSponsorDetectionCallback(callbackManager *queue.CallbackManager, logger *slog.Logger, queueClient *queue.Client, sponsorDetectionRepo repository.SponsorDetectionRepository, llmModel string) {
 	// Register sponsor detection callback
 	callbackManager.RegisterCallback(func(ctx context.Context, videoID, channelID string, enrichment *model.VideoEnrichment) error {
 		// Skip if description is empty or nil
@@ -389,6 +744,4 @@ func registerSponsorDetectionCallback(logger *slog.Logger, queueClient *queue.Cl
 
 		return nil
 	})
-
-	return callbackManager
 }
@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/service"
@@ -22,6 +23,9 @@ const (
 	defaultBatchSize       = 100           // Process up to 100 subscriptions per run
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
 	// Initialize structured logger
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
@@ -35,6 +39,7 @@ func main() {
 	logger.Info("subscription renewal service starting",
 		"renewal_interval", config.RenewalInterval,
 		"batch_size", config.BatchSize,
+		"max_failures", config.MaxFailures,
 	)
 
 	// Initialize database connection
@@ -50,7 +55,7 @@ func main() {
 
 	// Initialize repository and service
 	subscriptionRepo := repository.NewSubscriptionRepository(pool)
-	pubSubHubService := service.NewPubSubHubService(&http.Client{}, logger)
+	pubSubHubService := service.NewPubSubHubServiceWithConfig(&http.Client{}, logger, pubSubHubConfig(config))
 
 	// Create renewal service
 	renewalService := &RenewalService{
@@ -58,6 +63,7 @@ func main() {
 		hubService:    pubSubHubService,
 		logger:        logger,
 		batchSize:     config.BatchSize,
+		maxFailures:   config.MaxFailures,
 		webhookSecret: config.WebhookSecret,
 		webhookURL:    config.WebhookURL,
 	}
@@ -98,6 +104,7 @@ type RenewalService struct {
 	hubService    service.PubSubHub
 	logger        *slog.Logger
 	batchSize     int
+	maxFailures   int // consecutive failures before a subscription is abandoned (0 = never)
 	webhookSecret string
 	webhookURL    string
 }
@@ -150,6 +157,12 @@ func (s *RenewalService) RenewExpiring(ctx context.Context) error {
 
 // renewSubscription renews a single subscription.
 func (s *RenewalService) renewSubscription(ctx context.Context, sub *models.Subscription) error {
+	// Record the request before sending it, since the hub may verify before it responds
+	sub.MarkLeaseRequested()
+	if err := s.repo.Update(ctx, sub); err != nil {
+		return fmt.Errorf("failed to record lease request: %w", err)
+	}
+
 	// Create subscription request
 	hubReq := &service.SubscribeRequest{
 		HubURL:       sub.HubURL,
@@ -163,7 +176,7 @@ func (s *RenewalService) renewSubscription(ctx context.Context, sub *models.Subs
 	hubResp, err := s.hubService.Subscribe(ctx, hubReq)
 	if err != nil {
 		// Mark as failed
-		sub.MarkFailed()
+		s.markFailed(sub)
 		if updateErr := s.repo.Update(ctx, sub); updateErr != nil {
 			s.logger.Error("failed to mark subscription as failed",
 				"subscription_id", sub.ID,
@@ -177,18 +190,34 @@ func (s *RenewalService) renewSubscription(ctx context.Context, sub *models.Subs
 	if hubResp.Accepted {
 		sub.MarkActive()
 		sub.UpdateExpiry(sub.LeaseSeconds)
+		err = s.repo.Activate(ctx, sub)
 	} else {
-		sub.MarkFailed()
+		s.markFailed(sub)
+		err = s.repo.Update(ctx, sub)
 	}
 
-	// Save updated subscription
-	if err := s.repo.Update(ctx, sub); err != nil {
+	if err != nil {
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
 	return nil
 }
 
+// markFailed records a failed renewal and abandons the subscription once it has
+// failed maxFailures times in a row.
+func (s *RenewalService) markFailed(sub *models.Subscription) {
+	sub.MarkFailed()
+
+	if s.maxFailures > 0 && sub.FailureCount >= s.maxFailures {
+		sub.MarkAbandoned()
+		s.logger.Warn("abandoning subscription after repeated renewal failures",
+			"subscription_id", sub.ID,
+			"channel_id", sub.ChannelID,
+			"failure_count", sub.FailureCount,
+		)
+	}
+}
+
 // Config holds application configuration.
 type Config struct {
 	DatabaseURL     string
@@ -196,6 +225,12 @@ type Config struct {
 	WebhookURL      string
 	RenewalInterval time.Duration
 	BatchSize       int
+
+	// MaxFailures is how many consecutive renewal failures abandon a subscription (0 = never)
+	MaxFailures int
+
+	PubSubUserAgent string
+	PubSubReferer   string
 }
 
 // loadConfig loads configuration from environment variables.
@@ -206,6 +241,10 @@ func loadConfig() *Config {
 		WebhookURL:      getEnv("WEBHOOK_URL", ""),
 		RenewalInterval: parseDuration(getEnv("RENEWAL_INTERVAL", "6h")),
 		BatchSize:       parseInt(getEnv("BATCH_SIZE", "100")),
+		MaxFailures:     parseInt(getEnv("MAX_RENEWAL_FAILURES", "10")),
+
+		PubSubUserAgent: getEnv("PUBSUB_USER_AGENT", service.DefaultUserAgent(version)),
+		PubSubReferer:   getEnv("PUBSUB_REFERER", ""),
 	}
 
 	if config.DatabaseURL == "" {
@@ -223,6 +262,15 @@ func loadConfig() *Config {
 	return config
 }
 
+// pubSubHubConfig builds the outbound header settings for hub requests.
+func pubSubHubConfig(config *Config) service.PubSubHubConfig {
+	hubConfig := service.PubSubHubConfig{UserAgent: config.PubSubUserAgent}
+	if config.PubSubReferer != "" {
+		hubConfig.Headers = map[string]string{"Referer": config.PubSubReferer}
+	}
+	return hubConfig
+}
+
 // getEnv gets an environment variable or returns a default value.
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -266,12 +314,17 @@ func initDatabase(ctx context.Context, databaseURL string) (*pgxpool.Pool, error
 		return nil, fmt.Errorf("parse database URL: %w", err)
 	}
 
-	// Configure connection pool
+	// Configure connection pool defaults
 	poolConfig.MaxConns = 10
 	poolConfig.MinConns = 2
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 
+	// Allow the sizing above to be overridden per deployment
+	if err := db.ApplyPoolEnv(poolConfig); err != nil {
+		return nil, fmt.Errorf("configure connection pool: %w", err)
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("create connection pool: %w", err)
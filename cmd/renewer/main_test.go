@@ -48,6 +48,11 @@ func (m *mockSubscriptionRepository) Update(ctx context.Context, sub *models.Sub
 	return args.Error(0)
 }
 
+func (m *mockSubscriptionRepository) Activate(ctx context.Context, sub *models.Subscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
 func (m *mockSubscriptionRepository) Delete(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -77,6 +82,11 @@ func (m *mockSubscriptionRepository) List(ctx context.Context, filters *reposito
 	return args.Get(0).([]*models.Subscription), args.Int(1), args.Error(2)
 }
 
+func (m *mockSubscriptionRepository) ConfirmLease(ctx context.Context, topicURL string, leaseSeconds int) (int64, error) {
+	args := m.Called(ctx, topicURL, leaseSeconds)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // mockPubSubHub mocks the PubSubHub interface
 type mockPubSubHub struct {
 	mock.Mock
@@ -151,8 +161,11 @@ func TestRenewalService_RenewExpiring_Success(t *testing.T) {
 	}
 	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(hubResponse, nil).Times(3)
 
-	// Mock repository to update each subscription
+	// Mock repository to record each lease request and activate each subscription
 	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.LeaseRequestedAt != nil
+	})).Return(nil).Times(3)
+	repo.On("Activate", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.Status == models.StatusActive
 	})).Return(nil).Times(3)
 
@@ -260,8 +273,13 @@ func TestRenewalService_RenewExpiring_PartialSuccess(t *testing.T) {
 		LeaseSeconds: 432000,
 	}, nil).Once()
 
-	// Update should be called twice for successful renewals and once for failed
+	// Each lease request is recorded before subscribing
 	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.Status == models.StatusActive
+	})).Return(nil).Times(3)
+
+	// Activate should be called twice for successful renewals and Update once for failed
+	repo.On("Activate", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.Status == models.StatusActive && (sub.ID == 1 || sub.ID == 3)
 	})).Return(nil).Twice()
 
@@ -304,8 +322,11 @@ func TestRenewalService_renewSubscription_Success(t *testing.T) {
 			req.LeaseSeconds == subscription.LeaseSeconds
 	})).Return(hubResponse, nil)
 
-	// Mock repository to update subscription
+	// Mock repository to record the lease request and activate the subscription
 	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ID == subscription.ID && sub.LeaseRequestedAt != nil
+	})).Return(nil).Once()
+	repo.On("Activate", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.ID == subscription.ID && sub.Status == models.StatusActive
 	})).Return(nil)
 
@@ -340,7 +361,10 @@ func TestRenewalService_renewSubscription_HubRejected(t *testing.T) {
 	}
 	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(hubResponse, nil)
 
-	// Mock repository to update subscription with failed status
+	// Mock repository to record the lease request, then update subscription with failed status
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ID == subscription.ID && sub.Status == models.StatusActive
+	})).Return(nil).Once()
 	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.ID == subscription.ID && sub.Status == models.StatusFailed
 	})).Return(nil)
@@ -373,7 +397,10 @@ func TestRenewalService_renewSubscription_HubError(t *testing.T) {
 	hubErr := errors.New("network timeout")
 	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(nil, hubErr)
 
-	// Mock repository to update subscription with failed status
+	// Mock repository to record the lease request, then update subscription with failed status
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ID == subscription.ID && sub.Status == models.StatusActive
+	})).Return(nil).Once()
 	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.ID == subscription.ID && sub.Status == models.StatusFailed
 	})).Return(nil)
@@ -412,9 +439,10 @@ func TestRenewalService_renewSubscription_UpdateError(t *testing.T) {
 	}
 	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(hubResponse, nil)
 
-	// Mock repository to return error on update
+	// Mock repository to return error on activation
 	dbErr := errors.New("database update failed")
-	repo.On("Update", mock.Anything, mock.Anything).Return(dbErr)
+	repo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	repo.On("Activate", mock.Anything, mock.Anything).Return(dbErr)
 
 	// Execute renewal
 	err := renewalService.renewSubscription(context.Background(), subscription)
@@ -447,6 +475,9 @@ func TestRenewalService_renewSubscription_MarkFailedUpdateError(t *testing.T) {
 
 	// Mock repository to fail when marking as failed
 	updateErr := errors.New("database update failed")
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.Status == models.StatusActive
+	})).Return(nil).Once()
 	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.Status == models.StatusFailed
 	})).Return(updateErr)
@@ -471,11 +502,12 @@ func TestLoadConfig(t *testing.T) {
 		{
 			name: "all values set",
 			envVars: map[string]string{
-				"DATABASE_URL":     "postgres://localhost/testdb",
-				"WEBHOOK_SECRET":   "test-secret-123",
-				"WEBHOOK_URL":      "https://example.com/webhook",
-				"RENEWAL_INTERVAL": "4h",
-				"BATCH_SIZE":       "50",
+				"DATABASE_URL":         "postgres://localhost/testdb",
+				"WEBHOOK_SECRET":       "test-secret-123",
+				"WEBHOOK_URL":          "https://example.com/webhook",
+				"RENEWAL_INTERVAL":     "4h",
+				"BATCH_SIZE":           "50",
+				"MAX_RENEWAL_FAILURES": "5",
 			},
 			expected: &Config{
 				DatabaseURL:     "postgres://localhost/testdb",
@@ -483,6 +515,7 @@ func TestLoadConfig(t *testing.T) {
 				WebhookURL:      "https://example.com/webhook",
 				RenewalInterval: 4 * time.Hour,
 				BatchSize:       50,
+				MaxFailures:     5,
 			},
 			wantExit: false,
 		},
@@ -499,6 +532,7 @@ func TestLoadConfig(t *testing.T) {
 				WebhookURL:      "https://example.com/webhook",
 				RenewalInterval: 6 * time.Hour,
 				BatchSize:       100,
+				MaxFailures:     10,
 			},
 			wantExit: false,
 		},
@@ -518,6 +552,7 @@ func TestLoadConfig(t *testing.T) {
 			assert.Equal(t, tt.expected.WebhookURL, config.WebhookURL)
 			assert.Equal(t, tt.expected.RenewalInterval, config.RenewalInterval)
 			assert.Equal(t, tt.expected.BatchSize, config.BatchSize)
+			assert.Equal(t, tt.expected.MaxFailures, config.MaxFailures)
 		})
 	}
 }
@@ -691,6 +726,7 @@ func TestRenewalService_RenewExpiring_WithSecret(t *testing.T) {
 	}, nil)
 
 	repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	err := renewalService.RenewExpiring(context.Background())
 
@@ -732,6 +768,7 @@ func TestRenewalService_RenewExpiring_WithoutSecret(t *testing.T) {
 	}, nil)
 
 	repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	err := renewalService.RenewExpiring(context.Background())
 
@@ -767,3 +804,120 @@ func TestRenewalService_RenewExpiring_ContextCancellation(t *testing.T) {
 	assert.ErrorIs(t, err, context.Canceled)
 	repo.AssertExpectations(t)
 }
+
+func TestRenewalService_RenewExpiring_AbandonsAfterMaxFailures(t *testing.T) {
+	t.Parallel()
+
+	repo := new(mockSubscriptionRepository)
+	hubService := new(mockPubSubHub)
+	renewalService := &RenewalService{
+		repo:        repo,
+		hubService:  hubService,
+		logger:      newTestLogger(),
+		batchSize:   100,
+		maxFailures: 3,
+		webhookURL:  "https://example.com/webhook",
+	}
+
+	failing := createTestSubscription(1, "UCdeleted", 12*time.Hour)
+	failing.Status = models.StatusFailed
+	failing.FailureCount = 2
+
+	repo.On("GetExpiringSoon", mock.Anything, 100).Return([]*models.Subscription{failing}, nil)
+	hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
+		return req.TopicURL == failing.TopicURL
+	})).Return(&service.SubscribeResponse{Accepted: false, StatusCode: 404}, nil).Once()
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ID == failing.ID && sub.Status == models.StatusFailed
+	})).Return(nil).Once()
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ID == failing.ID && sub.Status == models.StatusAbandoned
+	})).Return(nil).Once()
+
+	err := renewalService.RenewExpiring(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusAbandoned, failing.Status)
+	assert.Equal(t, 3, failing.FailureCount)
+	hubService.AssertNumberOfCalls(t, "Subscribe", 1)
+	repo.AssertExpectations(t)
+	hubService.AssertExpectations(t)
+}
+
+func TestRenewalService_renewSubscription_SuccessResetsFailureCount(t *testing.T) {
+	t.Parallel()
+
+	repo := new(mockSubscriptionRepository)
+	hubService := new(mockPubSubHub)
+	renewalService := &RenewalService{
+		repo:        repo,
+		hubService:  hubService,
+		logger:      newTestLogger(),
+		batchSize:   100,
+		maxFailures: 3,
+		webhookURL:  "https://example.com/webhook",
+	}
+
+	subscription := createTestSubscription(1, "UCflaky", 12*time.Hour)
+	subscription.Status = models.StatusFailed
+	subscription.FailureCount = 2
+
+	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(&service.SubscribeResponse{Accepted: true, StatusCode: 202}, nil)
+	repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
+
+	require.NoError(t, renewalService.renewSubscription(context.Background(), subscription))
+	assert.Equal(t, models.StatusActive, subscription.Status)
+	assert.Equal(t, 0, subscription.FailureCount)
+}
+
+func TestRenewalService_renewSubscription_KeepsLeaseConfirmedBeforeResponse(t *testing.T) {
+	t.Parallel()
+
+	repo := new(mockSubscriptionRepository)
+	hubService := new(mockPubSubHub)
+	renewalService := &RenewalService{
+		repo:       repo,
+		hubService: hubService,
+		logger:     newTestLogger(),
+		batchSize:  100,
+		webhookURL: "https://example.com/webhook",
+	}
+
+	subscription := createTestSubscription(1, "UCtest1", 12*time.Hour)
+	confirmedExpiry := time.Now().Add(86400 * time.Second)
+
+	// The hub verifies the request, granting a shorter lease, before it responds
+	var steps []string
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.LeaseRequestedAt != nil
+	})).Run(func(mock.Arguments) {
+		steps = append(steps, "record request")
+	}).Return(nil).Once()
+	hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
+		return req.LeaseSeconds == 432000
+	})).Run(func(mock.Arguments) {
+		steps = append(steps, "subscribe")
+		confirmed, err := repo.ConfirmLease(context.Background(), subscription.TopicURL, 86400)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), confirmed)
+	}).Return(&service.SubscribeResponse{Accepted: true, StatusCode: 202}, nil)
+	repo.On("ConfirmLease", mock.Anything, subscription.TopicURL, 86400).Return(int64(1), nil)
+
+	// Activation keeps the confirmed lease and reports it back
+	repo.On("Activate", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		steps = append(steps, "activate")
+		sub := args.Get(1).(*models.Subscription)
+		sub.LeaseSeconds = 86400
+		sub.ExpiresAt = confirmedExpiry
+	}).Return(nil)
+
+	require.NoError(t, renewalService.renewSubscription(context.Background(), subscription))
+
+	assert.Equal(t, []string{"record request", "subscribe", "activate"}, steps)
+	assert.Equal(t, 86400, subscription.LeaseSeconds)
+	assert.Equal(t, confirmedExpiry, subscription.ExpiresAt)
+	assert.Equal(t, models.StatusActive, subscription.Status)
+	repo.AssertExpectations(t)
+	hubService.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		webhookURL  string
+		webhookPath string
+		wantErr     string
+	}{
+		{name: "matching path", webhookURL: "https://webhooks.example.com/webhook", webhookPath: "/webhook"},
+		{name: "matching nested path", webhookURL: "https://example.com/yt/hook", webhookPath: "/yt/hook"},
+		{name: "query string is ignored", webhookURL: "https://example.com/webhook?source=yt", webhookPath: "/webhook"},
+		{name: "root path", webhookURL: "https://example.com", webhookPath: "/"},
+		{name: "mismatched path", webhookURL: "https://example.com/hook", webhookPath: "/webhook", wantErr: `path "/hook" does not equal WEBHOOK_PATH "/webhook"`},
+		{name: "trailing slash differs", webhookURL: "https://example.com/webhook/", webhookPath: "/webhook", wantErr: "does not equal"},
+		{name: "missing path", webhookURL: "https://example.com", webhookPath: "/webhook", wantErr: `path "/" does not equal`},
+		{name: "relative URL", webhookURL: "/webhook", webhookPath: "/webhook", wantErr: "must be an absolute URL"},
+		{name: "unparseable URL", webhookURL: "https://exa mple.com/webhook", webhookPath: "/webhook", wantErr: "invalid WEBHOOK_URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateWebhookURL(tt.webhookURL, tt.webhookPath)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadSponsorDenylist(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "denylist.txt")
+	require.NoError(t, os.WriteFile(file, []byte("# own brands\nCreator Merch\n"), 0o600))
+
+	denylist := models.NewSponsorDenylist(nil)
+	config := &Config{SponsorDenylist: []string{"YouTube", "Patreon"}, SponsorDenylistFile: file}
+	require.NoError(t, loadSponsorDenylist(denylist, config))
+
+	assert.Equal(t, 3, denylist.Len())
+	assert.True(t, denylist.Contains("patreon"))
+	assert.True(t, denylist.Contains("Creator Merch"))
+
+	config.SponsorDenylistFile = filepath.Join(t.TempDir(), "missing.txt")
+	assert.Error(t, loadSponsorDenylist(denylist, config))
+	assert.Equal(t, 3, denylist.Len(), "a failed reload keeps the previous list")
+}
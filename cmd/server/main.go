@@ -5,17 +5,22 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/handler"
 	"ad-tracker/youtube-webhook-ingestion/internal/middleware"
 	"ad-tracker/youtube-webhook-ingestion/internal/queue"
 	"ad-tracker/youtube-webhook-ingestion/internal/service"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/ollama"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
 
@@ -27,8 +32,27 @@ const (
 	defaultPort        = "8080"
 	defaultWebhookPath = "/webhook"
 	shutdownTimeout    = 30 * time.Second
+	closeTimeout       = 5 * time.Second
+	serverWriteTimeout = 15 * time.Second
+
+	// Per-request bounds on handler database work. The webhook path is kept short so
+	// a slow database makes the hub retry instead of holding its connection open.
+	defaultAPITimeout     = 10 * time.Second
+	defaultWebhookTimeout = 3 * time.Second
+
+	// Routes that call the hub or YouTube for many items, or stream large reports, get a
+	// longer bound, and their write deadline is extended past serverWriteTimeout to match
+	defaultLongAPITimeout = 5 * time.Minute
+	defaultGzipMinSize    = 1024
+
+	// Fast-ack webhook mode: background workers and the number of stored events waiting for one
+	defaultFastAckWorkers   = 4
+	defaultFastAckQueueSize = 1000
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -49,7 +73,9 @@ func main() {
 		"max_conns", pool.Config().MaxConns,
 	)
 
-	webhookEventRepo := repository.NewWebhookEventRepository(pool)
+	webhookEventRepo := repository.NewWebhookEventRepositoryWithConfig(pool, repository.WebhookEventRepositoryConfig{
+		MaxRawXMLSize: config.MaxStoredXMLSize,
+	})
 	videoRepo := repository.NewVideoRepository(pool)
 	channelRepo := repository.NewChannelRepository(pool)
 	videoUpdateRepo := repository.NewVideoUpdateRepository(pool)
@@ -59,19 +85,62 @@ func main() {
 	quotaRepo := repository.NewQuotaRepository(pool)
 	blockedVideoRepo := repository.NewBlockedVideoRepository(pool)
 	enrichmentJobRepo := repository.NewEnrichmentJobRepository(pool)
-	sponsorDetectionRepo := repository.NewSponsorDetectionRepository(pool)
-
-	processor := service.NewEventProcessor(
-		pool,
-		webhookEventRepo,
-		videoRepo,
-		channelRepo,
-		videoUpdateRepo,
-	)
+
+	// Reapplying stored detections must skip the same sponsors the enricher does
+	sponsorDenylist := models.NewSponsorDenylist(nil)
+	if err := loadSponsorDenylist(sponsorDenylist, config); err != nil {
+		logger.Error("failed to load sponsor denylist", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("sponsor denylist loaded", "names", sponsorDenylist.Len(), "file", config.SponsorDenylistFile)
+
+	// Re-read the denylist file on SIGHUP so names can be added without a restart
+	if config.SponsorDenylistFile != "" {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		go func() {
+			for range reload {
+				if err := loadSponsorDenylist(sponsorDenylist, config); err != nil {
+					logger.Warn("failed to reload sponsor denylist, keeping the previous list", "error", err)
+					continue
+				}
+				logger.Info("sponsor denylist reloaded", "names", sponsorDenylist.Len())
+			}
+		}()
+	}
+
+	sponsorDetectionRepo := repository.NewSponsorDetectionRepositoryWithConfig(pool, repository.SponsorDetectionRepositoryConfig{
+		MaxEvidenceLength: config.SponsorEvidenceMaxLength,
+		CommitChunkSize:   config.SponsorCommitChunkSize,
+		Denylist:          sponsorDenylist,
+	})
+	auditLogRepo := repository.NewAuditLogRepository(pool)
+
+	var processor service.EventProcessor
+	if config.IngestOnly {
+		processor = service.NewIngestOnlyProcessor(webhookEventRepo)
+		logger.Info("ingest-only mode: webhook events are stored without projections, enrichment or sponsor detection")
+	} else {
+		processor = service.NewEventProcessorWithConfig(
+			pool,
+			webhookEventRepo,
+			videoRepo,
+			channelRepo,
+			videoUpdateRepo,
+			service.EventProcessorConfig{MaxEnrichAge: time.Duration(config.MaxEnrichAgeDays) * 24 * time.Hour},
+		)
+		if config.MaxEnrichAgeDays > 0 {
+			logger.Info("new videos older than the enrichment cutoff are not enriched",
+				"max_enrich_age_days", config.MaxEnrichAgeDays,
+			)
+		}
+	}
 
 	// Initialize Redis client and blocked video cache (optional)
 	// If Redis URL is configured, set up both enrichment job enqueueing and blocked video caching
 	var blockedVideoCache *service.BlockedVideoCache
+	var redisClient *redis.Client
+	var queueClients []*queue.Client
 	if config.RedisURL != "" {
 		// Parse Redis URL for direct Redis client
 		redisOpt, err := redis.ParseURL(config.RedisURL)
@@ -80,7 +149,7 @@ func main() {
 				"error", err,
 			)
 		} else {
-			redisClient := redis.NewClient(redisOpt)
+			redisClient = redis.NewClient(redisOpt)
 
 			// Test Redis connection
 			if err := redisClient.Ping(ctx).Err(); err != nil {
@@ -108,6 +177,7 @@ func main() {
 				"error", err,
 			)
 		} else {
+			queueClients = append(queueClients, queueClient)
 			processor.SetQueueClient(queueClient)
 			logger.Info("queue client initialized, enrichment jobs will be enqueued for new videos")
 		}
@@ -115,7 +185,7 @@ func main() {
 		logger.Info("Redis URL not configured, blocked video caching and enrichment job enqueueing will not be available")
 	}
 
-	pubSubHubService := service.NewPubSubHubService(&http.Client{}, logger)
+	pubSubHubService := service.NewPubSubHubServiceWithConfig(&http.Client{}, logger, pubSubHubConfig(config))
 
 	// YouTube API client (optional - only if API key is provided)
 	var youtubeClient *youtube.Client
@@ -134,6 +204,7 @@ func main() {
 
 			// Wire up quota tracking to YouTube client
 			youtubeClient.SetQuotaTracker(quotaManager)
+			youtubeClient.SetQuotaCosts(config.QuotaCosts)
 
 			channelResolverService = service.NewChannelResolverService(
 				youtubeClient,
@@ -145,6 +216,8 @@ func main() {
 				config.WebhookSecret,
 				config.WebhookURL,
 			)
+			channelResolverService.SetConcurrencyLimit(config.ChannelResolverConcurrency)
+			channelResolverService.SetRateLimit(config.ChannelResolveRateLimit)
 
 			logger.Info("YouTube API client initialized, URL-based channel addition is available")
 		}
@@ -153,18 +226,76 @@ func main() {
 	}
 
 	webhookHandler := handler.NewWebhookHandler(processor, blockedVideoCache, config.WebhookSecret, logger)
+	webhookHandler.SetSubscriptionRepository(subscriptionRepo)
+	if len(config.PreviousWebhookSecrets) > 0 {
+		webhookHandler.SetPreviousSecrets(config.PreviousWebhookSecrets)
+		logger.Info("accepting previous webhook secrets during rotation",
+			"previous_secrets", len(config.PreviousWebhookSecrets),
+		)
+	}
+	if config.FastAck {
+		if asyncProcessor, ok := processor.(service.AsyncEventProcessor); ok {
+			webhookHandler.EnableFastAck(asyncProcessor, config.FastAckWorkers, defaultFastAckQueueSize)
+			logger.Info("fast-ack mode: webhook events are stored and acknowledged before processing",
+				"workers", config.FastAckWorkers,
+			)
+		} else {
+			logger.Info("WEBHOOK_FAST_ACK has no effect in ingest-only mode")
+		}
+	}
+
+	// Poll channel feeds for videos whose notification was missed (optional)
+	var stopFeedPoller context.CancelFunc
+	feedPollerDone := make(chan struct{})
+	if config.FeedPollInterval > 0 {
+		if config.IngestOnly {
+			logger.Info("FEED_POLL_INTERVAL has no effect in ingest-only mode")
+		} else {
+			feedPoller := service.NewFeedPoller(subscriptionRepo, videoRepo, processor, service.FeedPollerConfig{
+				UserAgent: config.PubSubUserAgent,
+			})
+			if blockedVideoCache != nil {
+				feedPoller.SetBlockedVideoChecker(blockedVideoCache)
+			}
+
+			var pollCtx context.Context
+			pollCtx, stopFeedPoller = context.WithCancel(ctx)
+			go func() {
+				defer close(feedPollerDone)
+				feedPoller.Run(pollCtx, config.FeedPollInterval)
+			}()
+			logger.Info("channel feed polling enabled", "interval", config.FeedPollInterval)
+		}
+	}
 
 	webhookEventHandler := handler.NewWebhookEventHandler(webhookEventRepo, logger)
 	channelHandler := handler.NewChannelHandler(channelRepo, logger)
+	channelHandler.SetAuditLog(auditLogRepo)
 	videoHandler := handler.NewVideoHandler(videoRepo, logger)
+	videoHandler.SetAuditLog(auditLogRepo)
 	videoUpdateHandler := handler.NewVideoUpdateHandler(videoUpdateRepo, logger)
 	subscriptionCRUDHandler := handler.NewSubscriptionCRUDHandler(subscriptionRepo, pubSubHubService, config.WebhookSecret, config.WebhookURL, logger)
+	subscriptionCRUDHandler.SetAuditLog(auditLogRepo)
+	subscriptionCRUDHandler.SetMinLeaseSeconds(config.MinLeaseSeconds)
+	webhookTestHandler := handler.NewWebhookTestHandler(config.WebhookSecret, config.WebhookURL, nil, logger)
 	enrichmentHandler := handler.NewEnrichmentHandler(videoEnrichmentRepo, channelEnrichmentRepo, videoRepo, logger)
+	if youtubeClient != nil {
+		enrichmentHandler.SetCategoryNameResolver(service.NewCategoryNameCache(youtubeClient), config.CategoryRegionCode)
+	}
+	enrichmentHandler.SetDedupWindow(config.EnrichmentDedupWindow)
+	enrichmentHandler.SetStaleAfter(config.EnrichmentStaleAfter)
 	enrichmentJobHandler := handler.NewEnrichmentJobHandler(enrichmentJobRepo, logger)
-	sponsorHandler := handler.NewSponsorHandler(sponsorDetectionRepo, videoRepo, logger)
+	adminHandler := handler.NewAdminHandler(repository.NewDBStatsRepository(pool), logger)
+	adminHandler.SetAuditLogRepository(auditLogRepo)
+	sponsorHandler := handler.NewSponsorHandler(sponsorDetectionRepo, logger)
+	sponsorHandler.SetAuditLog(auditLogRepo)
 	videoSponsorHandler := handler.NewVideoSponsorHandler(sponsorDetectionRepo, logger)
+	videoSponsorHandler.SetMaxSponsors(config.MaxSponsorsPerVideo)
 	channelSponsorHandler := handler.NewChannelSponsorHandler(sponsorDetectionRepo, videoRepo, logger)
 	sponsorDetectionJobHandler := handler.NewSponsorDetectionJobHandler(sponsorDetectionRepo, logger)
+	sponsorDetectionJobHandler.SetAuditLog(auditLogRepo)
+	// Rendering the prompt needs no Ollama connection, so the client is left unconfigured
+	sponsorPromptHandler := handler.NewSponsorPromptHandler(ollama.NewClient(ollama.Config{}), videoRepo, videoEnrichmentRepo, logger)
 
 	// Set queue client on enrichment handler if Redis is configured
 	if config.RedisURL != "" {
@@ -175,21 +306,10 @@ func main() {
 				"error", err,
 			)
 		} else {
+			queueClients = append(queueClients, queueClient)
 			enrichmentHandler.SetQueueClient(queueClient)
-			logger.Info("queue client set on enrichment handler, manual channel enrichment endpoint is available")
-		}
-	}
-
-	// Set queue client on enrichment handler if Redis is configured
-	if config.RedisURL != "" {
-		jobRepo := repository.NewEnrichmentJobRepository(pool)
-		queueClient, err := queue.NewClient(config.RedisURL, jobRepo)
-		if err != nil {
-			logger.Warn("failed to initialize queue client for enrichment handler",
-				"error", err,
-			)
-		} else {
-			enrichmentHandler.SetQueueClient(queueClient)
+			enrichmentJobHandler.SetTaskCanceller(queueClient)
+			sponsorDetectionJobHandler.SetDetectionEnqueuer(queueClient, videoRepo, videoEnrichmentRepo)
 			logger.Info("queue client set on enrichment handler, manual channel enrichment endpoint is available")
 		}
 	}
@@ -204,40 +324,67 @@ func main() {
 	var channelFromURLHandler *handler.ChannelFromURLHandler
 	if channelResolverService != nil {
 		channelFromURLHandler = handler.NewChannelFromURLHandler(channelResolverService, logger)
+		channelFromURLHandler.SetAuditLog(auditLogRepo)
 	}
 
 	authMiddleware := middleware.NewAPIKeyAuth(config.APIKeys, logger)
+	apiTimeout := middleware.NewRequestTimeout(config.APITimeout, logger)
+	longAPITimeout := middleware.NewRequestTimeout(config.LongAPITimeout, logger)
+	webhookTimeout := middleware.NewRequestTimeout(config.WebhookTimeout, logger)
+	apiGzip := middleware.NewGzip(config.GzipMinSize, logger)
+
+	// protected applies API key authentication, response compression and the API request timeout
+	protected := func(h http.Handler) http.Handler {
+		return authMiddleware.Middleware(apiGzip.Middleware(apiTimeout.Middleware(h)))
+	}
 
-	mux := http.NewServeMux()
+	// protectedLong is protected with the long-running route timeout in place of the API timeout
+	protectedLong := func(h http.Handler) http.Handler {
+		return extendWriteDeadline(config.LongAPITimeout, logger)(
+			authMiddleware.Middleware(apiGzip.Middleware(longAPITimeout.Middleware(h))))
+	}
 
-	mux.Handle(config.WebhookPath, webhookHandler)
+	mux := http.NewServeMux()
 
-	mux.Handle("/api/v1/webhook-events", authMiddleware.Middleware(webhookEventHandler))
-	mux.Handle("/api/v1/webhook-events/", authMiddleware.Middleware(webhookEventHandler))
-	mux.Handle("/api/v1/channels", authMiddleware.Middleware(channelHandler))
-	mux.Handle("/api/v1/videos", authMiddleware.Middleware(videoHandler))
-	mux.Handle("/api/v1/video-updates", authMiddleware.Middleware(videoUpdateHandler))
-	mux.Handle("/api/v1/video-updates/", authMiddleware.Middleware(videoUpdateHandler))
-	mux.Handle("/api/v1/subscriptions", authMiddleware.Middleware(subscriptionCRUDHandler))
-	mux.Handle("/api/v1/subscriptions/", authMiddleware.Middleware(subscriptionCRUDHandler))
-	mux.Handle("/api/v1/enrichments/", authMiddleware.Middleware(enrichmentHandler))
-	mux.Handle("/api/v1/jobs", authMiddleware.Middleware(enrichmentJobHandler))
+	mux.Handle(config.WebhookPath, webhookTimeout.Middleware(webhookHandler))
+
+	mux.Handle("/api/v1/webhook-events", protected(webhookEventHandler))
+	mux.Handle("/api/v1/webhook-events/", protected(webhookEventHandler))
+	mux.Handle("/api/v1/channels", protected(channelHandler))
+	mux.Handle("/api/v1/videos", protected(videoHandler))
+	mux.Handle("/api/v1/video-updates", protected(videoUpdateHandler))
+	mux.Handle("/api/v1/video-updates/", protected(videoUpdateHandler))
+	mux.Handle("/api/v1/subscriptions", protected(subscriptionCRUDHandler))
+	mux.Handle("/api/v1/subscriptions/", protected(subscriptionCRUDHandler))
+	mux.Handle("/api/v1/subscriptions/renew-all", protectedLong(subscriptionCRUDHandler))
+	mux.Handle("/api/v1/webhook-test", protectedLong(webhookTestHandler))
+	mux.Handle("/api/v1/enrichments/", protected(enrichmentHandler))
+	mux.Handle("/api/v1/jobs", protected(enrichmentJobHandler))
+	mux.Handle("/api/v1/jobs/", protected(enrichmentJobHandler))
+	mux.Handle("/api/v1/admin/", protected(adminHandler))
 
 	// Blocked videos endpoints (only available if Redis is configured)
 	if blockedVideoHandler != nil {
-		mux.Handle("/api/v1/blocked-videos", authMiddleware.Middleware(blockedVideoHandler))
-		mux.Handle("/api/v1/blocked-videos/", authMiddleware.Middleware(blockedVideoHandler))
+		mux.Handle("/api/v1/blocked-videos", protected(blockedVideoHandler))
+		mux.Handle("/api/v1/blocked-videos/", protected(blockedVideoHandler))
+	}
+
+	// JSON notifications for local testing (development only)
+	if config.DevIngest {
+		mux.Handle("/api/v1/dev/ingest", protected(handler.NewDevIngestHandler(processor, logger)))
+		logger.Warn("DEV_INGEST_ENABLED is set: /api/v1/dev/ingest processes unsigned notifications; do not enable in production")
 	}
 
 	// Sponsor detection endpoints
-	mux.Handle("/api/v1/sponsors", authMiddleware.Middleware(sponsorHandler))
-	mux.Handle("/api/v1/sponsors/", authMiddleware.Middleware(sponsorHandler))
-	mux.Handle("/api/v1/sponsor-detection-jobs", authMiddleware.Middleware(sponsorDetectionJobHandler))
-	mux.Handle("/api/v1/sponsor-detection-jobs/", authMiddleware.Middleware(sponsorDetectionJobHandler))
+	mux.Handle("/api/v1/sponsors", protected(sponsorHandler))
+	mux.Handle("/api/v1/sponsors/", protected(sponsorHandler))
+	mux.Handle("/api/v1/sponsor-detection-jobs", protected(sponsorDetectionJobHandler))
+	mux.Handle("/api/v1/sponsor-detection-jobs/", protected(sponsorDetectionJobHandler))
+	mux.Handle("/api/v1/sponsor-prompts/", protected(sponsorPromptHandler))
 
 	// Nested sponsor endpoints for videos and channels
 	// We need to create wrapper handlers for these nested routes
-	mux.Handle("/api/v1/videos/", authMiddleware.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/videos/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/api/v1/videos/")
 		parts := strings.Split(path, "/")
 
@@ -252,7 +399,7 @@ func main() {
 		videoHandler.ServeHTTP(w, r)
 	})))
 
-	mux.Handle("/api/v1/channels/", authMiddleware.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	channelRoutes := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/api/v1/channels/")
 		parts := strings.Split(path, "/")
 
@@ -262,6 +409,12 @@ func main() {
 			return
 		}
 
+		// Check if this is /channels/batch-latest
+		if len(parts) == 1 && parts[0] == "batch-latest" {
+			enrichmentHandler.HandleBatchLatestChannelEnrichments(w, r)
+			return
+		}
+
 		// Check if this is a /channels/{id}/sponsors request
 		if len(parts) == 2 && parts[1] == "sponsors" {
 			channelID := parts[0]
@@ -269,17 +422,45 @@ func main() {
 			return
 		}
 
+		// Check if this is a /channels/{id}/sponsor-trend request
+		if len(parts) == 2 && parts[1] == "sponsor-trend" {
+			channelSponsorHandler.HandleGetChannelSponsorTrend(w, r, parts[0])
+			return
+		}
+
+		// Check if this is a /channels/{id}/sponsor-report request
+		if len(parts) == 2 && parts[1] == "sponsor-report" {
+			channelSponsorHandler.HandleGetChannelSponsorReport(w, r, parts[0])
+			return
+		}
+
+		// Check if this is a /channels/{id}/name-history request
+		if len(parts) == 2 && parts[1] == "name-history" {
+			channelHandler.HandleGetNameHistory(w, r, parts[0])
+			return
+		}
+
+		// Check if this is a /channels/{id}/growth request
+		if len(parts) == 2 && parts[1] == "growth" {
+			enrichmentHandler.HandleGetChannelGrowth(w, r, parts[0])
+			return
+		}
+
 		// Otherwise, delegate to the channel handler
 		channelHandler.ServeHTTP(w, r)
-	})))
+	})
+	mux.Handle("/api/v1/channels/", protected(channelRoutes))
+	mux.Handle("/api/v1/channels/from-url", protectedLong(channelRoutes))
+	mux.Handle("/api/v1/channels/{id}/sponsor-report", protectedLong(channelRoutes))
 
 	mux.HandleFunc("/health", handleHealth(pool))
+	mux.Handle("/openapi.json", handler.NewOpenAPIHandler(version))
 
 	server := &http.Server{
 		Addr:         ":" + config.Port,
 		Handler:      loggingMiddleware(logger)(mux),
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		WriteTimeout: serverWriteTimeout,
 		IdleTimeout:  60 * time.Second,
 	}
 
@@ -302,14 +483,44 @@ func main() {
 	case sig := <-shutdown:
 		logger.Info("shutdown signal received", "signal", sig)
 
-		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-		defer cancel()
+		// Stop accepting requests first so nothing enqueues or reads the cache after its client is closed
+		steps := []shutdownStep{
+			{name: "http server", timeout: shutdownTimeout, fn: func(ctx context.Context) error {
+				if err := server.Shutdown(ctx); err != nil {
+					server.Close()
+					return err
+				}
+				return nil
+			}},
+			{name: "webhook workers", timeout: shutdownTimeout, fn: func(ctx context.Context) error {
+				// Drain fast-ack workers; they may still enqueue enrichment jobs
+				return webhookHandler.Shutdown(ctx)
+			}},
+			{name: "callback migrations", timeout: shutdownTimeout, fn: func(ctx context.Context) error {
+				// Stop migrations between subscriptions; a restart does not resume them
+				return subscriptionCRUDHandler.Shutdown(ctx)
+			}},
+		}
+		if stopFeedPoller != nil {
+			// Stop polling before closing the queue clients an in-flight poll may enqueue with
+			steps = append(steps, shutdownStep{name: "feed poller", timeout: shutdownTimeout, fn: func(ctx context.Context) error {
+				stopFeedPoller()
+				select {
+				case <-feedPollerDone:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}})
+		}
+		for _, queueClient := range queueClients {
+			steps = append(steps, shutdownStep{name: "queue client", timeout: closeTimeout, fn: closeStep(queueClient.Close)})
+		}
+		if redisClient != nil {
+			steps = append(steps, shutdownStep{name: "redis client", timeout: closeTimeout, fn: closeStep(redisClient.Close)})
+		}
 
-		if err := server.Shutdown(ctx); err != nil {
-			logger.Error("graceful shutdown failed", "error", err)
-			if err := server.Close(); err != nil {
-				logger.Error("failed to close server", "error", err)
-			}
+		if !runShutdown(logger, steps) {
 			os.Exit(1)
 		}
 
@@ -327,6 +538,77 @@ type Config struct {
 	WebhookURL    string
 	APIKeys       []string
 	YouTubeAPIKey string
+
+	// PreviousWebhookSecrets are still accepted for verification during secret rotation
+	PreviousWebhookSecrets []string
+
+	APITimeout     time.Duration
+	WebhookTimeout time.Duration
+
+	// LongAPITimeout bounds the long-running API routes (renew-all, webhook-test,
+	// channels/from-url and sponsor-report) instead of APITimeout
+	LongAPITimeout time.Duration
+
+	// GzipMinSize is the smallest API response body, in bytes, that is gzip-compressed (0 = disabled)
+	GzipMinSize int
+
+	PubSubUserAgent string
+	PubSubReferer   string
+
+	// IngestOnly stores raw webhook events without projections or enqueueing, and skips Redis
+	IngestOnly bool
+
+	// FastAck acknowledges notifications once stored and processes them on FastAckWorkers goroutines
+	FastAck        bool
+	FastAckWorkers int
+
+	// ChannelResolverConcurrency bounds concurrent YouTube lookups for /channels/from-url (0 = unbounded)
+	ChannelResolverConcurrency int
+
+	// ChannelResolveRateLimit caps /channels/from-url YouTube lookups per minute across all API keys (0 = unlimited)
+	ChannelResolveRateLimit int
+
+	// MaxSponsorsPerVideo caps the sponsors returned by GET /api/v1/videos/{id}/sponsors
+	MaxSponsorsPerVideo int
+
+	// CategoryRegionCode is the region whose video category names are served as category_name
+	CategoryRegionCode string
+
+	// EnrichmentDedupWindow skips manual video enrichment requests for videos enriched within it (0 = always enqueue)
+	EnrichmentDedupWindow time.Duration
+
+	// EnrichmentStaleAfter is the age past which a served video enrichment is flagged is_stale
+	EnrichmentStaleAfter time.Duration
+
+	// MaxEnrichAgeDays skips enrichment of new videos published more than this many days ago (0 = no limit)
+	MaxEnrichAgeDays int
+
+	// MinLeaseSeconds is the shortest non-zero lease_seconds a subscription create request may ask for
+	MinLeaseSeconds int
+
+	// MaxStoredXMLSize caps the bytes of each notification body stored in raw_xml (0 = no limit)
+	MaxStoredXMLSize int
+
+	// FeedPollInterval is how often the feeds of actively subscribed channels are polled for
+	// videos whose notification was missed (0 = disabled)
+	FeedPollInterval time.Duration
+
+	// QuotaCosts is the quota cost of each YouTube API operation, from YOUTUBE_QUOTA_COSTS overrides
+	QuotaCosts quota.CostTable
+
+	// SponsorDenylist names sponsors that reapplied detections never store; SponsorDenylistFile adds one
+	// name per line and is re-read on SIGHUP
+	SponsorDenylist     []string
+	SponsorDenylistFile string
+
+	// SponsorEvidenceMaxLength and SponsorCommitChunkSize apply to reapplied detections, as
+	// they do to detections the enricher saves
+	SponsorEvidenceMaxLength int
+	SponsorCommitChunkSize   int
+
+	// DevIngest serves POST /api/v1/dev/ingest, which processes JSON video notifications
+	// without XML or signatures; for development only
+	DevIngest bool
 }
 
 // loadConfig loads configuration from environment variables.
@@ -338,8 +620,50 @@ func loadConfig() *Config {
 		WebhookSecret: getEnv("WEBHOOK_SECRET", ""),
 		WebhookPath:   getEnv("WEBHOOK_PATH", defaultWebhookPath),
 		WebhookURL:    getEnv("WEBHOOK_URL", ""),
-		APIKeys:       parseAPIKeys(getEnv("API_KEYS", "")),
+		APIKeys:       parseCommaList(getEnv("API_KEYS", "")),
 		YouTubeAPIKey: getEnv("YOUTUBE_API_KEY", ""),
+
+		PreviousWebhookSecrets: parseCommaList(getEnv("WEBHOOK_SECRET_PREVIOUS", "")),
+
+		APITimeout:     getEnvDuration("API_REQUEST_TIMEOUT", defaultAPITimeout),
+		WebhookTimeout: getEnvDuration("WEBHOOK_REQUEST_TIMEOUT", defaultWebhookTimeout),
+		LongAPITimeout: getEnvDuration("API_LONG_REQUEST_TIMEOUT", defaultLongAPITimeout),
+
+		GzipMinSize: getEnvInt("API_GZIP_MIN_SIZE", defaultGzipMinSize),
+
+		PubSubUserAgent: getEnv("PUBSUB_USER_AGENT", service.DefaultUserAgent(version)),
+		PubSubReferer:   getEnv("PUBSUB_REFERER", ""),
+
+		IngestOnly: getEnvBool("INGEST_ONLY", false),
+
+		FastAck:        getEnvBool("WEBHOOK_FAST_ACK", false),
+		FastAckWorkers: getEnvInt("WEBHOOK_FAST_ACK_WORKERS", defaultFastAckWorkers),
+
+		MaxEnrichAgeDays: getEnvInt("MAX_ENRICH_AGE", 0),
+
+		ChannelResolverConcurrency: getEnvInt("CHANNEL_RESOLVER_CONCURRENCY", service.DefaultChannelResolverConcurrency),
+		ChannelResolveRateLimit:    getEnvInt("CHANNEL_RESOLVE_RATE_LIMIT", service.DefaultChannelResolveRateLimit),
+
+		CategoryRegionCode: getEnv("CATEGORY_REGION_CODE", "US"),
+
+		EnrichmentDedupWindow: getEnvDuration("ENRICHMENT_DEDUP_WINDOW", 0),
+		EnrichmentStaleAfter:  getEnvDuration("ENRICHMENT_STALE_AFTER", handler.DefaultEnrichmentStaleAfter),
+
+		MaxSponsorsPerVideo: getEnvInt("MAX_SPONSORS_PER_VIDEO", handler.DefaultMaxSponsorsPerVideo),
+
+		MinLeaseSeconds: getEnvInt("SUBSCRIPTION_MIN_LEASE_SECONDS", handler.DefaultMinLeaseSeconds),
+
+		FeedPollInterval: getEnvDuration("FEED_POLL_INTERVAL", 0),
+
+		DevIngest: getEnvBool("DEV_INGEST_ENABLED", false),
+
+		SponsorDenylist:     parseCommaList(getEnv("SPONSOR_DENYLIST", "")),
+		SponsorDenylistFile: getEnv("SPONSOR_DENYLIST_FILE", ""),
+
+		SponsorEvidenceMaxLength: getEnvInt("SPONSOR_EVIDENCE_MAX_LENGTH", repository.DefaultMaxEvidenceLength),
+		SponsorCommitChunkSize:   getEnvInt("SPONSOR_COMMIT_CHUNK_SIZE", 0), // 0 = single transaction
+
+		MaxStoredXMLSize: getEnvInt("WEBHOOK_MAX_STORED_XML_BYTES", repository.DefaultMaxRawXMLSize),
 	}
 
 	if config.DatabaseURL == "" {
@@ -347,6 +671,13 @@ func loadConfig() *Config {
 		os.Exit(1)
 	}
 
+	quotaCosts, err := quota.ParseCostTable(getEnv("YOUTUBE_QUOTA_COSTS", ""))
+	if err != nil {
+		slog.Error("invalid YOUTUBE_QUOTA_COSTS", "error", err)
+		os.Exit(1)
+	}
+	config.QuotaCosts = quotaCosts
+
 	if config.WebhookSecret == "" {
 		slog.Error("WEBHOOK_SECRET environment variable is required",
 			"help", "This secret is used to verify webhook signatures from YouTube PubSubHub",
@@ -361,6 +692,27 @@ func loadConfig() *Config {
 		os.Exit(1)
 	}
 
+	if err := validateWebhookURL(config.WebhookURL, config.WebhookPath); err != nil {
+		slog.Error("WEBHOOK_URL does not match WEBHOOK_PATH",
+			"error", err,
+			"help", "Subscriptions point the hub at WEBHOOK_URL, but notifications are only served on WEBHOOK_PATH; set both to the same path (or set WEBHOOK_PATH if a proxy rewrites it). Existing hub subscriptions keep the callback URL they were created with, so after changing the path run POST /api/v1/subscriptions/migrate-callback to move them",
+		)
+		os.Exit(1)
+	}
+
+	if config.IngestOnly && config.RedisURL != "" {
+		slog.Info("INGEST_ONLY is set, ignoring REDIS_URL")
+		config.RedisURL = ""
+	}
+
+	if config.APITimeout >= serverWriteTimeout || config.WebhookTimeout >= serverWriteTimeout {
+		slog.Warn("request timeouts should be shorter than the server write timeout, or clients see a dropped connection instead of a 504",
+			"api_timeout", config.APITimeout.String(),
+			"webhook_timeout", config.WebhookTimeout.String(),
+			"write_timeout", serverWriteTimeout.String(),
+		)
+	}
+
 	if len(config.APIKeys) == 0 {
 		slog.Warn("no API keys configured - subscription endpoints will reject all requests",
 			"env_var", "API_KEYS",
@@ -370,6 +722,53 @@ func loadConfig() *Config {
 	return config
 }
 
+// validateWebhookURL checks that the public webhook URL is absolute and that its path
+// is the path the webhook handler is mounted on, so hub notifications are not sent to a 404.
+func validateWebhookURL(webhookURL, webhookPath string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid WEBHOOK_URL %q: %w", webhookURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("WEBHOOK_URL %q must be an absolute URL such as https://webhooks.example.com%s", webhookURL, webhookPath)
+	}
+
+	urlPath := u.Path
+	if urlPath == "" {
+		urlPath = "/"
+	}
+	if urlPath != webhookPath {
+		return fmt.Errorf("WEBHOOK_URL path %q does not equal WEBHOOK_PATH %q", urlPath, webhookPath)
+	}
+
+	return nil
+}
+
+// pubSubHubConfig builds the outbound header settings for hub requests.
+func pubSubHubConfig(config *Config) service.PubSubHubConfig {
+	hubConfig := service.PubSubHubConfig{UserAgent: config.PubSubUserAgent}
+	if config.PubSubReferer != "" {
+		hubConfig.Headers = map[string]string{"Referer": config.PubSubReferer}
+	}
+	return hubConfig
+}
+
+// loadSponsorDenylist replaces the denylist with the names from SPONSOR_DENYLIST and SPONSOR_DENYLIST_FILE
+func loadSponsorDenylist(denylist *models.SponsorDenylist, config *Config) error {
+	names := append([]string(nil), config.SponsorDenylist...)
+
+	if config.SponsorDenylistFile != "" {
+		contents, err := os.ReadFile(config.SponsorDenylistFile)
+		if err != nil {
+			return fmt.Errorf("read sponsor denylist file: %w", err)
+		}
+		names = append(names, models.ParseSponsorDenylist(string(contents))...)
+	}
+
+	denylist.Replace(names)
+	return nil
+}
+
 // getEnv gets an environment variable or returns a default value.
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -378,14 +777,74 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// parseAPIKeys parses a comma-separated list of API keys.
-// Empty strings and whitespace are trimmed from each key.
-func parseAPIKeys(apiKeysEnv string) []string {
-	if apiKeysEnv == "" {
+// getEnvDuration gets a duration (e.g. "10s") from an environment variable or returns a default value.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("invalid duration for environment variable, using default",
+			"key", key,
+			"value", value,
+			"default", defaultValue.String(),
+		)
+		return defaultValue
+	}
+
+	return d
+}
+
+// getEnvBool gets a boolean (e.g. "true", "1") from an environment variable or returns a default value.
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("invalid boolean for environment variable, using default",
+			"key", key,
+			"value", value,
+			"default", defaultValue,
+		)
+		return defaultValue
+	}
+
+	return b
+}
+
+// getEnvInt gets an integer from an environment variable or returns a default value.
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("invalid integer for environment variable, using default",
+			"key", key,
+			"value", value,
+			"default", defaultValue,
+		)
+		return defaultValue
+	}
+
+	return n
+}
+
+// parseCommaList parses a comma-separated list such as API keys or secrets.
+// Empty strings and whitespace are trimmed from each entry.
+func parseCommaList(value string) []string {
+	if value == "" {
 		return nil
 	}
 
-	parts := strings.Split(apiKeysEnv, ",")
+	parts := strings.Split(value, ",")
 	keys := make([]string, 0, len(parts))
 
 	for _, key := range parts {
@@ -405,12 +864,17 @@ func initDatabase(ctx context.Context, databaseURL string) (*pgxpool.Pool, error
 		return nil, fmt.Errorf("parse database URL: %w", err)
 	}
 
-	// Configure connection pool
+	// Configure connection pool defaults
 	poolConfig.MaxConns = 25
 	poolConfig.MinConns = 5
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 
+	// Allow the sizing above to be overridden per deployment
+	if err := db.ApplyPoolEnv(poolConfig); err != nil {
+		return nil, fmt.Errorf("configure connection pool: %w", err)
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("create connection pool: %w", err)
@@ -466,6 +930,25 @@ func loggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// extendWriteDeadline moves the connection's write deadline to timeout from now, plus a margin
+// for sending the timeout response, so routes bounded by a timeout longer than
+// serverWriteTimeout are not cut off by the server first.
+func extendWriteDeadline(timeout time.Duration, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline := time.Now().Add(timeout + serverWriteTimeout)
+			if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+				logger.Warn("failed to extend write deadline", "path", r.URL.Path, "error", err)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // responseWriter wraps http.ResponseWriter to capture the status code.
 type responseWriter struct {
 	http.ResponseWriter
@@ -481,5 +964,20 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
-// Ensure responseWriter implements http.ResponseWriter
-var _ http.ResponseWriter = (*responseWriter)(nil)
+// Flush passes flushes through so streamed responses reach the client.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Ensure responseWriter implements http.ResponseWriter and http.Flusher
+var (
+	_ http.ResponseWriter = (*responseWriter)(nil)
+	_ http.Flusher        = (*responseWriter)(nil)
+)
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddleware_PassesFlushThrough(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var flushedBeforeReturn bool
+	rec := httptest.NewRecorder()
+	h := loggingMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		require.NoError(t, http.NewResponseController(w).Flush())
+		flushedBeforeReturn = rec.Flushed
+	}))
+
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/channels/UC1/sponsor-report", nil))
+
+	assert.True(t, flushedBeforeReturn)
+	assert.Equal(t, "partial", rec.Body.String())
+}
+
+func TestExtendWriteDeadline_OutlastsServerWriteTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	srv := httptest.NewUnstartedServer(loggingMiddleware(logger)(extendWriteDeadline(time.Minute, logger)(slow)))
+	srv.Config.WriteTimeout = 10 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "done", string(body))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// shutdownStep releases one resource during shutdown.
+type shutdownStep struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// runShutdown runs the steps in order, each bounded by its own timeout. A step that
+// fails, times out or panics is logged and the remaining steps still run once it has
+// returned, so a stuck resource cannot keep later ones open. It reports whether every step succeeded.
+func runShutdown(logger *slog.Logger, steps []shutdownStep) bool {
+	ok := true
+
+	for _, step := range steps {
+		start := time.Now()
+		logger.Info("shutdown step starting", "step", step.name)
+
+		if err := runShutdownStep(step); err != nil {
+			ok = false
+			logger.Error("shutdown step failed",
+				"step", step.name,
+				"error", err,
+				"duration", time.Since(start).String(),
+			)
+			continue
+		}
+
+		logger.Info("shutdown step completed",
+			"step", step.name,
+			"duration", time.Since(start).String(),
+		)
+	}
+
+	return ok
+}
+
+// runShutdownStep runs a single step. When its timeout expires the step's context is
+// cancelled and the step is still waited for, so it cannot touch a resource that a later
+// step closes; steps must therefore return promptly once their context is done.
+func runShutdownStep(step shutdownStep) error {
+	ctx, cancel := context.WithTimeout(context.Background(), step.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- step.fn(ctx)
+	}()
+
+	err := <-done
+	if ctx.Err() == nil {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("timed out after %s: %w", step.timeout, err)
+	}
+	return fmt.Errorf("timed out after %s", step.timeout)
+}
+
+// closeStep adapts a Close method, which cannot be cancelled, into a step function that
+// gives up once ctx is done. A close still running then is left to finish in the
+// background, so it must only be used for resources that no later step touches.
+func closeStep(closeFn func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- closeFn()
+		}()
+
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunShutdown_Order(t *testing.T) {
+	t.Parallel()
+
+	var order []string
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	ok := runShutdown(logger, []shutdownStep{
+		{name: "http server", timeout: time.Second, fn: record("http server")},
+		{name: "queue client", timeout: time.Second, fn: record("queue client")},
+		{name: "redis client", timeout: time.Second, fn: record("redis client")},
+	})
+
+	assert.True(t, ok)
+	assert.Equal(t, []string{"http server", "queue client", "redis client"}, order)
+}
+
+func TestRunShutdown_FailuresDoNotStopLaterSteps(t *testing.T) {
+	t.Parallel()
+
+	var closed []string
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	ok := runShutdown(logger, []shutdownStep{
+		{name: "slow", timeout: 10 * time.Millisecond, fn: func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}},
+		{name: "failing", timeout: time.Second, fn: func(ctx context.Context) error {
+			return errors.New("close failed")
+		}},
+		{name: "panicking", timeout: time.Second, fn: func(ctx context.Context) error {
+			panic("enqueue after close")
+		}},
+		{name: "last", timeout: time.Second, fn: func(ctx context.Context) error {
+			closed = append(closed, "last")
+			return nil
+		}},
+	})
+
+	assert.False(t, ok)
+	assert.Equal(t, []string{"last"}, closed)
+	assert.Contains(t, logs.String(), "timed out after 10ms")
+	assert.Contains(t, logs.String(), "close failed")
+	assert.Contains(t, logs.String(), "panic: enqueue after close")
+}
+
+func TestRunShutdown_WaitsForTimedOutStep(t *testing.T) {
+	t.Parallel()
+
+	var order []string
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	ok := runShutdown(logger, []shutdownStep{
+		{name: "webhook workers", timeout: 10 * time.Millisecond, fn: func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			order = append(order, "webhook workers")
+			return ctx.Err()
+		}},
+		{name: "queue client", timeout: time.Second, fn: func(ctx context.Context) error {
+			order = append(order, "queue client")
+			return nil
+		}},
+	})
+
+	assert.False(t, ok)
+	assert.Equal(t, []string{"webhook workers", "queue client"}, order, "a timed-out step finishes before the next one starts")
+}
+
+func TestCloseStep_GivesUpAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	start := time.Now()
+	ok := runShutdown(logger, []shutdownStep{
+		{name: "redis client", timeout: 10 * time.Millisecond, fn: closeStep(func() error {
+			<-release
+			return nil
+		})},
+	})
+
+	assert.False(t, ok)
+	assert.Less(t, time.Since(start), time.Second, "a stuck close should not hold up shutdown")
+	assert.Contains(t, logs.String(), "timed out after 10ms")
+}
+
+func TestCloseStep_ReturnsCloseError(t *testing.T) {
+	t.Parallel()
+
+	err := closeStep(func() error { return errors.New("close failed") })(context.Background())
+	assert.EqualError(t, err, "close failed")
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Channel represents a YouTube channel that we're tracking.
 type Channel struct {
@@ -11,6 +14,10 @@ type Channel struct {
 	LastUpdatedAt time.Time `db:"last_updated_at" json:"last_updated_at"`
 	CreatedAt     time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+
+	// SubscriptionExpiresAt is the expiry of the channel's subscription. It is only set when
+	// channels are listed by subscription status.
+	SubscriptionExpiresAt *time.Time `db:"-" json:"subscription_expires_at,omitempty"`
 }
 
 // NewChannel creates a new Channel with the given information.
@@ -34,3 +41,33 @@ func (c *Channel) Update(title, channelURL string) {
 	c.LastUpdatedAt = time.Now()
 	c.UpdatedAt = time.Now()
 }
+
+// DisplayName returns the channel title, or the channel ID when the title is not known yet.
+// Channels first seen in a webhook without an author name have an empty title until enrichment.
+func (c *Channel) DisplayName() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return c.ChannelID
+}
+
+// MarshalJSON adds the computed display_name to the stored fields
+func (c Channel) MarshalJSON() ([]byte, error) {
+	type channel Channel
+	return json.Marshal(struct {
+		channel
+		DisplayName string `json:"display_name"`
+	}{
+		channel:     channel(c),
+		DisplayName: c.DisplayName(),
+	})
+}
+
+// ChannelNameChange records a channel title replaced after YouTube reported a new one.
+type ChannelNameChange struct {
+	ID        int64     `db:"id" json:"id"`
+	ChannelID string    `db:"channel_id" json:"channel_id"`
+	OldTitle  string    `db:"old_title" json:"old_title"`
+	NewTitle  string    `db:"new_title" json:"new_title"`
+	ChangedAt time.Time `db:"changed_at" json:"changed_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannel_DisplayName(t *testing.T) {
+	assert.Equal(t, "Test Channel", NewChannel("UC123", "Test Channel", "").DisplayName())
+	assert.Equal(t, "UC123", NewChannel("UC123", "", "").DisplayName(), "a titleless channel is shown by its ID")
+}
+
+func TestChannel_MarshalJSONIncludesDisplayName(t *testing.T) {
+	data, err := json.Marshal(NewChannel("UC123", "", "https://youtube.com/channel/UC123"))
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	assert.Equal(t, "UC123", decoded["display_name"])
+	assert.Equal(t, "", decoded["title"])
+	assert.Equal(t, "UC123", decoded["channel_id"])
+}
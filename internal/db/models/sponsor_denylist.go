@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"sync"
+)
+
+// SponsorDenylist holds sponsor names that are never stored, such as platforms the LLM
+// keeps mistaking for sponsors ("YouTube", "Patreon") or a creator's own brand.
+// Names are matched by NormalizeSponsorName. It is safe for concurrent use, and Replace
+// swaps the list in place so it can be reloaded while detections are running.
+type SponsorDenylist struct {
+	mu    sync.RWMutex
+	names map[string]bool
+}
+
+// NewSponsorDenylist creates a denylist of the given raw sponsor names
+func NewSponsorDenylist(names []string) *SponsorDenylist {
+	d := &SponsorDenylist{}
+	d.Replace(names)
+	return d
+}
+
+// Replace swaps the denylisted names for the given raw names
+func (d *SponsorDenylist) Replace(names []string) {
+	normalized := make(map[string]bool, len(names))
+	for _, name := range names {
+		if key := NormalizeSponsorName(name); key != "" {
+			normalized[key] = true
+		}
+	}
+
+	d.mu.Lock()
+	d.names = normalized
+	d.mu.Unlock()
+}
+
+// Contains reports whether a raw sponsor name is denylisted. A nil denylist contains nothing.
+func (d *SponsorDenylist) Contains(name string) bool {
+	if d == nil {
+		return false
+	}
+
+	key := NormalizeSponsorName(name)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.names[key]
+}
+
+// Len returns the number of distinct denylisted names
+func (d *SponsorDenylist) Len() int {
+	if d == nil {
+		return 0
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.names)
+}
+
+// ParseSponsorDenylist splits denylist file contents into names: one per line,
+// ignoring blank lines and lines starting with '#'
+func ParseSponsorDenylist(contents string) []string {
+	var names []string
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSponsorDenylist(t *testing.T) {
+	denylist := NewSponsorDenylist([]string{"YouTube", " patreon ", "🎉"})
+	assert.Equal(t, 2, denylist.Len(), "names that normalize to nothing are dropped")
+
+	assert.True(t, denylist.Contains("youtube"))
+	assert.True(t, denylist.Contains("Patreon (Sponsor)"), "matched on the normalized name")
+	assert.False(t, denylist.Contains("NordVPN"))
+
+	denylist.Replace([]string{"My Channel Merch"})
+	assert.False(t, denylist.Contains("YouTube"), "replaced names are no longer denylisted")
+	assert.True(t, denylist.Contains("my channel merch"))
+
+	var none *SponsorDenylist
+	assert.False(t, none.Contains("YouTube"))
+	assert.Equal(t, 0, none.Len())
+}
+
+func TestParseSponsorDenylist(t *testing.T) {
+	contents := "# platforms\nYouTube\n\n  Patreon  \n# own brand\nMy Channel Merch\n"
+
+	assert.Equal(t, []string{"YouTube", "Patreon", "My Channel Merch"}, ParseSponsorDenylist(contents))
+	assert.Empty(t, ParseSponsorDenylist(""))
+}
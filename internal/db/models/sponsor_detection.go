@@ -1,7 +1,11 @@
 package models
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 )
@@ -70,6 +74,26 @@ type VideoSponsorDetail struct {
 	SponsorCategory *string `db:"sponsor_category" json:"sponsor_category,omitempty"`
 }
 
+// RecentSponsorDetection is a video-sponsor relationship with sponsor and video details,
+// used for the feed of latest detections across all videos.
+type RecentSponsorDetection struct {
+	VideoSponsorDetail
+	VideoTitle     string `db:"video_title" json:"video_title"`
+	VideoChannelID string `db:"video_channel_id" json:"channel_id"`
+	ChannelTitle   string `db:"channel_title" json:"channel_title"` // channel ID when the channel has no title
+}
+
+// SponsorVideoDetail is a video-sponsor relationship joined with the video's details,
+// used to list a sponsor's videos. The video fields are nil when the video row is missing.
+type SponsorVideoDetail struct {
+	VideoSponsor
+	VideoTitle       *string    `db:"video_title" json:"video_title,omitempty"`
+	VideoURL         *string    `db:"video_url" json:"video_url,omitempty"`
+	VideoChannelID   *string    `db:"video_channel_id" json:"channel_id,omitempty"`
+	VideoPublishedAt *time.Time `db:"video_published_at" json:"published_at,omitempty"`
+	ChannelTitle     *string    `db:"channel_title" json:"channel_title,omitempty"` // channel ID when the channel has no title
+}
+
 // LLMSponsorResult represents a single sponsor detection result from the LLM.
 // This is used for parsing the JSON response from Ollama.
 type LLMSponsorResult struct {
@@ -82,3 +106,128 @@ type LLMSponsorResult struct {
 type LLMAnalysisResponse struct {
 	Sponsors []LLMSponsorResult `json:"sponsors"`
 }
+
+// SponsorTrendBucket aggregates a channel's sponsorships for one time period.
+type SponsorTrendBucket struct {
+	Period              time.Time `db:"period" json:"period"`
+	SponsorCount        int       `db:"sponsor_count" json:"sponsor_count"`
+	SponsoredVideoCount int       `db:"sponsored_video_count" json:"sponsored_video_count"`
+}
+
+// ChannelSponsorReportEntry summarizes one sponsor's appearances across a channel's videos.
+// FirstSeenAt and LastSeenAt are the publish dates of the earliest and latest sponsored video.
+type ChannelSponsorReportEntry struct {
+	SponsorID       uuid.UUID `db:"sponsor_id" json:"sponsor_id"`
+	SponsorName     string    `db:"sponsor_name" json:"sponsor_name"`
+	SponsorCategory *string   `db:"sponsor_category" json:"sponsor_category,omitempty"`
+	VideoCount      int       `db:"video_count" json:"video_count"`
+	FirstSeenAt     time.Time `db:"first_seen_at" json:"first_seen_at"`
+	LastSeenAt      time.Time `db:"last_seen_at" json:"last_seen_at"`
+}
+
+// ConfidenceBucket counts video sponsor detections whose confidence falls in [Min, Max).
+// The last bucket also includes a confidence of exactly Max.
+type ConfidenceBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// UndetectedVideo is an enriched video with a description that has no sponsor detection job.
+type UndetectedVideo struct {
+	VideoID     string `json:"video_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// SponsorVideoCountCheck compares a sponsor's stored video_count with the number of
+// distinct videos linked to it in video_sponsors.
+type SponsorVideoCountCheck struct {
+	SponsorID   uuid.UUID `json:"sponsor_id"`
+	StoredCount int       `json:"stored_count"`
+	ActualCount int       `json:"actual_count"`
+	Consistent  bool      `json:"consistent"`
+	Fixed       bool      `json:"fixed"`
+}
+
+// sponsorSuffixRegex matches annotations the LLM appends to sponsor names,
+// e.g. "NordVPN (Sponsor)", "Squarespace [sponsored]" or "Raid - paid promotion"
+var sponsorSuffixRegex = regexp.MustCompile(`(?i)\s*(?:[(\[]\s*(?:sponsor(?:ed)?|ad|advertisement|paid promotion|paid partnership)\s*[)\]]|[-–—:]\s*(?:sponsor(?:ed)?|paid promotion|paid partnership))\s*$`)
+
+// CleanSponsorName turns a raw LLM sponsor name into a display name. It strips emojis,
+// surrounding quotes and markdown emphasis, trailing punctuation and "(sponsor)"-style
+// suffixes, and collapses whitespace. Names given entirely in lowercase are title-cased;
+// any other capitalization is kept as the brand's own. Returns "" if nothing is left.
+func CleanSponsorName(raw string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.So, r) || unicode.Is(unicode.Cs, r) || r == '\u200d' || r == '\ufe0f' {
+			return -1
+		}
+		return r
+	}, raw)
+
+	// Suffix removal can expose more punctuation and vice versa, so repeat until stable
+	for {
+		before := name
+		name = strings.TrimSpace(name)
+		name = sponsorSuffixRegex.ReplaceAllString(name, "")
+		name = strings.Trim(name, "\"'`*_")
+		name = strings.TrimRight(name, ".,;:!?-–— ")
+		if name == before {
+			break
+		}
+	}
+
+	name = strings.Join(strings.Fields(name), " ")
+
+	if name != "" && name == strings.ToLower(name) {
+		words := strings.Fields(name)
+		for i, word := range words {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		name = strings.Join(words, " ")
+	}
+
+	return name
+}
+
+// NormalizeSponsorName returns the key used to match sponsors across detections:
+// the cleaned name, lowercased. Returns "" if the name is empty after cleaning.
+func NormalizeSponsorName(raw string) string {
+	return strings.ToLower(CleanSponsorName(raw))
+}
+
+// Video formats from the video_formats view, which treats videos up to 180 seconds long as Shorts
+const (
+	VideoFormatShort = "short"
+	VideoFormatLong  = "long"
+)
+
+// IsValidVideoFormat reports whether format is a known video format. The empty string means
+// no format filter and is also accepted.
+func IsValidVideoFormat(format string) bool {
+	return format == "" || format == VideoFormatShort || format == VideoFormatLong
+}
+
+// llmModelNameRegex matches Ollama model names such as "llama3:8b", "mistral" or
+// "hf.co/org/model:Q4_K_M": a name of letters, digits, '.', '_', '-' and '/', with an optional ":tag"
+var llmModelNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*(?::[A-Za-z0-9][A-Za-z0-9._-]*)?$`)
+
+// maxLLMModelNameLength matches the width of sponsor_detection_jobs.llm_model
+const maxLLMModelNameLength = 100
+
+// ValidateLLMModelName checks that name is a well-formed LLM model name
+func ValidateLLMModelName(name string) error {
+	if name == "" {
+		return fmt.Errorf("model name is required")
+	}
+	if len(name) > maxLLMModelNameLength {
+		return fmt.Errorf("model name exceeds %d characters", maxLLMModelNameLength)
+	}
+	if !llmModelNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid model name %q", name)
+	}
+	return nil
+}
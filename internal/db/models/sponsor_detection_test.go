@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestCleanSponsorName(t *testing.T) {
+	tests := []struct {
+		raw            string
+		wantName       string
+		wantNormalized string
+	}{
+		{"NordVPN", "NordVPN", "nordvpn"},
+		{"NordVPN (Sponsor)  ", "NordVPN", "nordvpn"},
+		{"  nordvpn.", "Nordvpn", "nordvpn"},
+		{"Squarespace [sponsored]!", "Squarespace", "squarespace"},
+		{"**Raid: Shadow Legends** - paid promotion", "Raid: Shadow Legends", "raid: shadow legends"},
+		{"\"Audible\"", "Audible", "audible"},
+		{"Hello   Fresh 🎉", "Hello Fresh", "hello fresh"},
+		{"raid shadow legends (ad)", "Raid Shadow Legends", "raid shadow legends"},
+		{"Ad Council", "Ad Council", "ad council"},
+		{"Sponsorship Co", "Sponsorship Co", "sponsorship co"},
+		{" 🎉 ", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := CleanSponsorName(tt.raw); got != tt.wantName {
+				t.Errorf("CleanSponsorName(%q) = %q, want %q", tt.raw, got, tt.wantName)
+			}
+			if got := NormalizeSponsorName(tt.raw); got != tt.wantNormalized {
+				t.Errorf("NormalizeSponsorName(%q) = %q, want %q", tt.raw, got, tt.wantNormalized)
+			}
+		})
+	}
+}
+
+func TestValidateLLMModelName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"llama3:8b", false},
+		{"mistral", false},
+		{"qwen2.5:14b-instruct-q4_K_M", false},
+		{"hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF:Q4_K_M", false},
+		{"", true},
+		{"llama3 8b", true},
+		{":8b", true},
+		{"llama3:8b:extra", true},
+		{"model;rm", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLLMModelName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLLMModelName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
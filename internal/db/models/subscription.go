@@ -11,6 +11,10 @@ const (
 	StatusActive  = "active"
 	StatusExpired = "expired"
 	StatusFailed  = "failed"
+
+	// StatusAbandoned marks a subscription whose renewal failed too many times in a row;
+	// it is no longer renewed
+	StatusAbandoned = "abandoned"
 )
 
 // Subscription represents a PubSubHubbub subscription for a YouTube channel.
@@ -22,9 +26,15 @@ type Subscription struct {
 	LeaseSeconds   int        `db:"lease_seconds" json:"lease_seconds"`
 	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
 	Status         string     `db:"status" json:"status"`
+	FailureCount   int        `db:"failure_count" json:"failure_count"`
 	LastVerifiedAt *time.Time `db:"last_verified_at" json:"last_verified_at,omitempty"`
-	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+
+	// LeaseRequestedAt is when a subscribe request awaiting hub verification was sent. It is
+	// only written, and an unset value leaves the stored one unchanged.
+	LeaseRequestedAt *time.Time `db:"lease_requested_at" json:"-"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
 // NewSubscription creates a new Subscription with the given parameters.
@@ -45,17 +55,34 @@ func NewSubscription(channelID string, leaseSeconds int) *Subscription {
 	}
 }
 
-// MarkActive marks the subscription as active and updates the verification timestamp.
+// MarkActive marks the subscription as active after the hub accepted a subscribe request
+// and updates the verification timestamp.
 func (s *Subscription) MarkActive() {
 	s.Status = StatusActive
+	s.FailureCount = 0
 	now := time.Now()
 	s.LastVerifiedAt = &now
 	s.UpdatedAt = now
 }
 
-// MarkFailed marks the subscription as failed.
+// MarkLeaseRequested records that a subscribe request is about to be sent to the hub, so
+// the hub's verification callback may confirm its lease. It must be saved before the
+// request is sent, since the hub may verify before it responds.
+func (s *Subscription) MarkLeaseRequested() {
+	now := time.Now()
+	s.LeaseRequestedAt = &now
+}
+
+// MarkFailed marks the subscription as failed and counts the failure.
 func (s *Subscription) MarkFailed() {
 	s.Status = StatusFailed
+	s.FailureCount++
+	s.UpdatedAt = time.Now()
+}
+
+// MarkAbandoned marks the subscription as abandoned so it is no longer renewed.
+func (s *Subscription) MarkAbandoned() {
+	s.Status = StatusAbandoned
 	s.UpdatedAt = time.Now()
 }
 
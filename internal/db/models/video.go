@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Video represents a YouTube video that we're tracking.
 type Video struct {
@@ -13,6 +16,14 @@ type Video struct {
 	LastUpdatedAt time.Time `db:"last_updated_at" json:"last_updated_at"`
 	CreatedAt     time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+
+	// Set when the availability re-check finds the video removed from YouTube
+	UnavailableAt         *time.Time `db:"unavailable_at" json:"unavailable_at,omitempty"`
+	UnavailableReason     *string    `db:"unavailable_reason" json:"unavailable_reason,omitempty"`
+	AvailabilityCheckedAt *time.Time `db:"availability_checked_at" json:"availability_checked_at,omitempty"`
+
+	// Set when the video is soft-deleted; it is then hidden from lists but keeps its history
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
 // NewVideo creates a new Video with the given information.
@@ -39,3 +50,21 @@ func (v *Video) Update(title, videoURL string, publishedAt time.Time) {
 	v.LastUpdatedAt = time.Now()
 	v.UpdatedAt = time.Now()
 }
+
+// IngestionLag returns how long after publication the video was first seen. It is negative
+// for videos seen before their publish time, such as scheduled premieres.
+func (v *Video) IngestionLag() time.Duration {
+	return v.FirstSeenAt.Sub(v.PublishedAt)
+}
+
+// MarshalJSON adds the computed ingestion_lag_seconds to the stored fields
+func (v Video) MarshalJSON() ([]byte, error) {
+	type video Video
+	return json.Marshal(struct {
+		video
+		IngestionLagSeconds int64 `json:"ingestion_lag_seconds"`
+	}{
+		video:               video(v),
+		IngestionLagSeconds: int64(v.IngestionLag() / time.Second),
+	})
+}
@@ -3,6 +3,51 @@ package models
 import (
 	"database/sql"
 	"time"
+	"unicode/utf8"
+)
+
+// ProcessingErrorCode categorizes why processing a webhook event failed.
+type ProcessingErrorCode string
+
+const (
+	// ErrorCodeParse means the raw XML could not be parsed as an Atom feed.
+	ErrorCodeParse ProcessingErrorCode = "parse_error"
+	// ErrorCodeDedupConflict means a projection write collided with an existing row.
+	ErrorCodeDedupConflict ProcessingErrorCode = "dedup_conflict"
+	// ErrorCodeEnqueueFailed means projections were written but the enrichment job could not be enqueued.
+	ErrorCodeEnqueueFailed ProcessingErrorCode = "enqueue_failed"
+	// ErrorCodeDB means a database operation failed while building projections.
+	ErrorCodeDB ProcessingErrorCode = "db_error"
+	// ErrorCodeTruncatedXML means the stored raw XML was truncated, so the event could not be
+	// re-parsed when it was processed from the database.
+	ErrorCodeTruncatedXML ProcessingErrorCode = "truncated_xml"
+)
+
+// ProcessingErrorCodes lists all valid processing error codes.
+var ProcessingErrorCodes = []ProcessingErrorCode{
+	ErrorCodeParse,
+	ErrorCodeDedupConflict,
+	ErrorCodeEnqueueFailed,
+	ErrorCodeDB,
+	ErrorCodeTruncatedXML,
+}
+
+// IsValid reports whether c is one of the known processing error codes.
+func (c ProcessingErrorCode) IsValid() bool {
+	for _, code := range ProcessingErrorCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook event sources
+const (
+	// EventSourceWebhook marks a notification delivered by the hub.
+	EventSourceWebhook = "webhook"
+	// EventSourcePoll marks a notification built from a polled channel feed.
+	EventSourcePoll = "poll"
 )
 
 // WebhookEvent represents a raw webhook notification event from YouTube PubSubHubbub.
@@ -10,13 +55,16 @@ import (
 type WebhookEvent struct {
 	ID              int64          `db:"id" json:"id"`
 	RawXML          string         `db:"raw_xml" json:"raw_xml"`
+	RawXMLTruncated bool           `db:"raw_xml_truncated" json:"raw_xml_truncated"` // raw_xml is a prefix of the received body
 	ContentHash     string         `db:"content_hash" json:"content_hash"`
 	ReceivedAt      time.Time      `db:"received_at" json:"received_at"`
 	Processed       bool           `db:"processed" json:"processed"`
 	ProcessedAt     sql.NullTime   `db:"processed_at" json:"processed_at,omitempty"`
 	ProcessingError sql.NullString `db:"processing_error" json:"processing_error,omitempty"`
+	ErrorCode       sql.NullString `db:"error_code" json:"error_code,omitempty"`
 	VideoID         sql.NullString `db:"video_id" json:"video_id,omitempty"`
 	ChannelID       sql.NullString `db:"channel_id" json:"channel_id,omitempty"`
+	Source          string         `db:"source" json:"source"` // EventSourceWebhook or EventSourcePoll
 	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
 }
 
@@ -31,10 +79,26 @@ func NewWebhookEvent(rawXML, contentHash, videoID, channelID string) *WebhookEve
 		Processed:   false,
 		VideoID:     sqlNullString(videoID),
 		ChannelID:   sqlNullString(channelID),
+		Source:      EventSourceWebhook,
 		CreatedAt:   now,
 	}
 }
 
+// TruncateRawXML cuts RawXML to at most maxSize bytes, on a UTF-8 character boundary, and
+// sets RawXMLTruncated if it did. ContentHash is left as is. maxSize <= 0 means no limit.
+func (w *WebhookEvent) TruncateRawXML(maxSize int) {
+	if maxSize <= 0 || len(w.RawXML) <= maxSize {
+		return
+	}
+
+	cut := maxSize
+	for cut > 0 && !utf8.RuneStart(w.RawXML[cut]) {
+		cut--
+	}
+	w.RawXML = w.RawXML[:cut]
+	w.RawXMLTruncated = true
+}
+
 // MarkProcessed updates the event as processed with an optional error message.
 func (w *WebhookEvent) MarkProcessed(processingError string) {
 	w.Processed = true
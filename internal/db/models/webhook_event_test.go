@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookEvent_TruncateRawXML(t *testing.T) {
+	t.Run("oversized body is cut and flagged", func(t *testing.T) {
+		event := NewWebhookEvent("<feed>"+strings.Repeat("a", 100)+"</feed>", "hash", "", "")
+		event.TruncateRawXML(32)
+
+		assert.Len(t, event.RawXML, 32)
+		assert.True(t, strings.HasPrefix(event.RawXML, "<feed>aaa"))
+		assert.True(t, event.RawXMLTruncated)
+		assert.Equal(t, "hash", event.ContentHash)
+	})
+
+	t.Run("cut does not split a multibyte character", func(t *testing.T) {
+		event := NewWebhookEvent("<title>"+strings.Repeat("é", 20)+"</title>", "hash", "", "")
+		event.TruncateRawXML(10) // "<title>" is 7 bytes; byte 10 falls inside the second "é"
+
+		assert.Equal(t, "<title>é", event.RawXML)
+		assert.True(t, utf8.ValidString(event.RawXML))
+		assert.True(t, event.RawXMLTruncated)
+	})
+
+	t.Run("body within the cap or no cap is kept", func(t *testing.T) {
+		for _, maxSize := range []int{0, 64} {
+			event := NewWebhookEvent("<feed></feed>", "hash", "", "")
+			event.TruncateRawXML(maxSize)
+
+			assert.Equal(t, "<feed></feed>", event.RawXML)
+			assert.False(t, event.RawXMLTruncated)
+		}
+	})
+}
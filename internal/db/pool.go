@@ -0,0 +1,85 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Environment variables that override connection pool sizing
+const (
+	EnvMaxConns        = "DB_MAX_CONNS"
+	EnvMinConns        = "DB_MIN_CONNS"
+	EnvMaxConnLifetime = "DB_MAX_CONN_LIFETIME"
+	EnvMaxConnIdleTime = "DB_MAX_CONN_IDLE_TIME"
+)
+
+// ApplyPoolEnv overrides the pool sizing in poolConfig with any values set in the
+// DB_MAX_CONNS, DB_MIN_CONNS, DB_MAX_CONN_LIFETIME and DB_MAX_CONN_IDLE_TIME environment
+// variables. Values already in poolConfig are kept as defaults for unset variables.
+// It returns an error if a value cannot be parsed or MinConns exceeds MaxConns.
+func ApplyPoolEnv(poolConfig *pgxpool.Config) error {
+	if err := envInt32(EnvMaxConns, &poolConfig.MaxConns); err != nil {
+		return err
+	}
+	if err := envInt32(EnvMinConns, &poolConfig.MinConns); err != nil {
+		return err
+	}
+	if err := envDuration(EnvMaxConnLifetime, &poolConfig.MaxConnLifetime); err != nil {
+		return err
+	}
+	if err := envDuration(EnvMaxConnIdleTime, &poolConfig.MaxConnIdleTime); err != nil {
+		return err
+	}
+
+	if poolConfig.MaxConns < 1 {
+		return fmt.Errorf("%s must be at least 1, got %d", EnvMaxConns, poolConfig.MaxConns)
+	}
+	if poolConfig.MinConns < 0 {
+		return fmt.Errorf("%s must not be negative, got %d", EnvMinConns, poolConfig.MinConns)
+	}
+	if poolConfig.MinConns > poolConfig.MaxConns {
+		return fmt.Errorf("%s (%d) must not exceed %s (%d)",
+			EnvMinConns, poolConfig.MinConns, EnvMaxConns, poolConfig.MaxConns)
+	}
+
+	return nil
+}
+
+// envInt32 sets *dst from the environment variable key if it is set
+func envInt32(key string, dst *int32) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+
+	*dst = int32(n)
+	return nil
+}
+
+// envDuration sets *dst from the environment variable key (e.g. "30m") if it is set
+func envDuration(key string, dst *time.Duration) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("%s must be positive, got %s", key, value)
+	}
+
+	*dst = d
+	return nil
+}
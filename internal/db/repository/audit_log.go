@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditLogRepository defines operations for the audit trail of mutating API operations
+type AuditLogRepository interface {
+	// Record stores an audit entry, setting its ID and CreatedAt
+	Record(ctx context.Context, entry *model.AuditEntry) error
+
+	// List retrieves audit entries matching the filters, newest first, with the total count
+	List(ctx context.Context, filters *AuditLogFilters) ([]*model.AuditEntry, int, error)
+}
+
+// AuditLogFilters contains filter options for listing audit entries
+type AuditLogFilters struct {
+	Limit        int
+	Offset       int
+	APIKeyID     string
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Since        *time.Time
+}
+
+type auditLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditLogRepository creates a new AuditLogRepository
+func NewAuditLogRepository(pool *pgxpool.Pool) AuditLogRepository {
+	return &auditLogRepository{pool: pool}
+}
+
+func (r *auditLogRepository) Record(ctx context.Context, entry *model.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (api_key_id, actor, action, resource_type, resource_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		entry.APIKeyID,
+		entry.Actor,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return db.WrapError(err, "record audit entry")
+	}
+
+	return nil
+}
+
+func (r *auditLogRepository) List(ctx context.Context, filters *AuditLogFilters) ([]*model.AuditEntry, int, error) {
+	args := []interface{}{}
+	argPos := 1
+	whereClauses := []string{}
+
+	addFilter := func(column string, value interface{}) {
+		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", column, argPos))
+		args = append(args, value)
+		argPos++
+	}
+
+	if filters.APIKeyID != "" {
+		addFilter("api_key_id", filters.APIKeyID)
+	}
+	if filters.Actor != "" {
+		addFilter("actor", filters.Actor)
+	}
+	if filters.Action != "" {
+		addFilter("action", filters.Action)
+	}
+	if filters.ResourceType != "" {
+		addFilter("resource_type", filters.ResourceType)
+	}
+	if filters.ResourceID != "" {
+		addFilter("resource_id", filters.ResourceID)
+	}
+	if filters.Since != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("created_at >= $%d", argPos))
+		args = append(args, *filters.Since)
+		argPos++
+	}
+
+	whereClause := ""
+	if len(whereClauses) > 0 {
+		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM audit_log %s", whereClause)
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, db.WrapError(err, "count audit entries")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, api_key_id, actor, action, resource_type, resource_id, before, after, created_at
+		FROM audit_log
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+
+	args = append(args, filters.Limit, filters.Offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, db.WrapError(err, "list audit entries")
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		entry := &model.AuditEntry{}
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.APIKeyID,
+			&entry.Actor,
+			&entry.Action,
+			&entry.ResourceType,
+			&entry.ResourceID,
+			&entry.Before,
+			&entry.After,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, 0, db.WrapError(err, "scan audit entry")
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, db.WrapError(err, "iterate audit entries")
+	}
+
+	return entries, total, nil
+}
+
+// nullableJSON maps an empty document to SQL NULL
+func nullableJSON(doc []byte) interface{} {
+	if len(doc) == 0 {
+		return nil
+	}
+	return doc
+}
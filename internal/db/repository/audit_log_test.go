@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogRepository_RecordAndList(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewAuditLogRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	keyID := "abc123def456"
+	update := &model.AuditEntry{
+		APIKeyID:     &keyID,
+		Action:       model.AuditActionUpdate,
+		ResourceType: "channel",
+		ResourceID:   "UC123",
+		Before:       json.RawMessage(`{"title":"Old"}`),
+		After:        json.RawMessage(`{"title":"New"}`),
+	}
+	require.NoError(t, repo.Record(ctx, update))
+	assert.NotZero(t, update.ID)
+	assert.False(t, update.CreatedAt.IsZero())
+
+	require.NoError(t, repo.Record(ctx, &model.AuditEntry{
+		APIKeyID:     &keyID,
+		Action:       model.AuditActionDelete,
+		ResourceType: "video",
+		ResourceID:   "video123",
+		Before:       json.RawMessage(`{"title":"Video"}`),
+	}))
+
+	entries, total, err := repo.List(ctx, &AuditLogFilters{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "video", entries[0].ResourceType, "newest first")
+	assert.Nil(t, entries[0].After)
+
+	entries, total, err = repo.List(ctx, &AuditLogFilters{Limit: 10, ResourceType: "channel", ResourceID: "UC123"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, entries, 1)
+	assert.Equal(t, model.AuditActionUpdate, entries[0].Action)
+	assert.JSONEq(t, `{"title":"Old"}`, string(entries[0].Before))
+	assert.JSONEq(t, `{"title":"New"}`, string(entries[0].After))
+	assert.Nil(t, entries[0].Actor)
+}
@@ -156,7 +156,11 @@ func (r *blockedVideoRepository) GetAllBlockedVideoIDs(ctx context.Context) ([]s
 	defer rows.Close()
 
 	var videoIDs []string
-	for rows.Next() {
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
+
 		var videoID string
 		if err := rows.Scan(&videoID); err != nil {
 			return nil, fmt.Errorf("failed to scan video ID: %w", err)
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
@@ -14,7 +15,8 @@ import (
 
 // ChannelRepository defines operations for managing channels.
 type ChannelRepository interface {
-	// UpsertChannel creates a new channel or updates an existing one.
+	// UpsertChannel creates a new channel or updates an existing one. A non-empty title that
+	// differs from a stored non-empty title is recorded in the channel's name history.
 	UpsertChannel(ctx context.Context, channel *models.Channel) error
 
 	// Create creates a new channel.
@@ -40,6 +42,18 @@ type ChannelRepository interface {
 
 	// GetChannelsByLastUpdated retrieves channels that have been updated since the given time.
 	GetChannelsByLastUpdated(ctx context.Context, since time.Time, limit int) ([]*models.Channel, error)
+
+	// BackfillTitle sets the title of a channel stored without one. It reports whether the
+	// channel was updated; channels that already have a title are left unchanged.
+	BackfillTitle(ctx context.Context, channelID, title string) (bool, error)
+
+	// RecordTitleChange replaces the title of a channel whose stored title differs from title,
+	// keeping the previous one in channel_name_history. It returns the recorded change, or nil
+	// when the title is unchanged, the channel is stored without a title, or it is unknown.
+	RecordTitleChange(ctx context.Context, channelID, title string) (*models.ChannelNameChange, error)
+
+	// GetNameHistory returns a channel's title changes, most recent first.
+	GetNameHistory(ctx context.Context, channelID string) ([]*models.ChannelNameChange, error)
 }
 
 // ChannelFilters contains filter options for listing channels.
@@ -49,6 +63,10 @@ type ChannelFilters struct {
 	Title    string
 	OrderBy  string
 	OrderDir string
+
+	// SubscriptionStatus limits the list to channels whose subscription has this status
+	// and fills in each channel's subscription expiry.
+	SubscriptionStatus string
 }
 
 type channelRepository struct {
@@ -61,7 +79,15 @@ func NewChannelRepository(pool *pgxpool.Pool) ChannelRepository {
 }
 
 func (r *channelRepository) UpsertChannel(ctx context.Context, channel *models.Channel) error {
+	// The row lock keeps a concurrent enrichment's RecordTitleChange from recording the same change
 	query := `
+		WITH stored AS (
+			SELECT title FROM channels WHERE channel_id = $1 FOR UPDATE
+		), renamed AS (
+			INSERT INTO channel_name_history (channel_id, old_title, new_title)
+			SELECT $1, stored.title, $2 FROM stored
+			WHERE stored.title != '' AND $2 != '' AND stored.title != $2
+		)
 		INSERT INTO channels (channel_id, title, channel_url, first_seen_at, last_updated_at, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (channel_id) DO UPDATE
@@ -238,14 +264,26 @@ func (r *channelRepository) List(ctx context.Context, filters *ChannelFilters) (
 	args := []interface{}{}
 	argPos := 1
 
-	whereClause := ""
+	fromClause := "channels c"
+	conditions := []string{}
+	if filters.SubscriptionStatus != "" {
+		fromClause = "channels c JOIN pubsub_subscriptions s ON s.channel_id = c.channel_id"
+		conditions = append(conditions, fmt.Sprintf("s.status = $%d", argPos))
+		args = append(args, filters.SubscriptionStatus)
+		argPos++
+	}
 	if filters.Title != "" {
-		whereClause = fmt.Sprintf("WHERE title ILIKE $%d", argPos)
+		conditions = append(conditions, fmt.Sprintf("c.title ILIKE $%d", argPos))
 		args = append(args, "%"+filters.Title+"%")
 		argPos++
 	}
 
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM channels %s", whereClause)
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", fromClause, whereClause)
 	var total int
 	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
@@ -262,13 +300,18 @@ func (r *channelRepository) List(ctx context.Context, filters *ChannelFilters) (
 		orderDir = filters.OrderDir
 	}
 
+	expiresColumn := "NULL::timestamptz"
+	if filters.SubscriptionStatus != "" {
+		expiresColumn = "s.expires_at"
+	}
+
 	query := fmt.Sprintf(`
-		SELECT channel_id, title, channel_url, first_seen_at, last_updated_at, created_at, updated_at
-		FROM channels
+		SELECT c.channel_id, c.title, c.channel_url, c.first_seen_at, c.last_updated_at, c.created_at, c.updated_at, %s
+		FROM %s
 		%s
-		ORDER BY %s %s
+		ORDER BY c.%s %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, orderBy, orderDir, argPos, argPos+1)
+	`, expiresColumn, fromClause, whereClause, orderBy, orderDir, argPos, argPos+1)
 
 	args = append(args, filters.Limit, filters.Offset)
 
@@ -278,14 +321,118 @@ func (r *channelRepository) List(ctx context.Context, filters *ChannelFilters) (
 	}
 	defer rows.Close()
 
-	channels, err := scanChannels(rows)
-	if err != nil {
-		return nil, 0, err
+	var channels []*models.Channel
+	for rows.Next() {
+		channel := &models.Channel{}
+		err := rows.Scan(
+			&channel.ChannelID,
+			&channel.Title,
+			&channel.ChannelURL,
+			&channel.FirstSeenAt,
+			&channel.LastUpdatedAt,
+			&channel.CreatedAt,
+			&channel.UpdatedAt,
+			&channel.SubscriptionExpiresAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan channel: %w", err)
+		}
+		channels = append(channels, channel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate channels: %w", err)
 	}
 
 	return channels, total, nil
 }
 
+func (r *channelRepository) BackfillTitle(ctx context.Context, channelID, title string) (bool, error) {
+	if title == "" {
+		return false, nil
+	}
+
+	query := `
+		UPDATE channels
+		SET title = $2, updated_at = NOW()
+		WHERE channel_id = $1 AND title = ''
+	`
+
+	result, err := r.pool.Exec(ctx, query, channelID, title)
+	if err != nil {
+		return false, db.WrapError(err, "backfill channel title")
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *channelRepository) RecordTitleChange(ctx context.Context, channelID, title string) (*models.ChannelNameChange, error) {
+	if title == "" {
+		return nil, nil
+	}
+
+	// The row lock keeps concurrent enrichments of the channel from recording the same change twice
+	query := `
+		WITH stored AS (
+			SELECT channel_id, title FROM channels WHERE channel_id = $1 FOR UPDATE
+		), renamed AS (
+			UPDATE channels c
+			SET title = $2, updated_at = NOW()
+			FROM stored
+			WHERE c.channel_id = stored.channel_id AND stored.title != '' AND stored.title != $2
+			RETURNING stored.title AS old_title
+		)
+		INSERT INTO channel_name_history (channel_id, old_title, new_title)
+		SELECT $1, old_title, $2 FROM renamed
+		RETURNING id, channel_id, old_title, new_title, changed_at
+	`
+
+	change := &models.ChannelNameChange{}
+	err := r.pool.QueryRow(ctx, query, channelID, title).Scan(
+		&change.ID,
+		&change.ChannelID,
+		&change.OldTitle,
+		&change.NewTitle,
+		&change.ChangedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, db.WrapError(err, "record channel title change")
+	}
+
+	return change, nil
+}
+
+func (r *channelRepository) GetNameHistory(ctx context.Context, channelID string) ([]*models.ChannelNameChange, error) {
+	query := `
+		SELECT id, channel_id, old_title, new_title, changed_at
+		FROM channel_name_history
+		WHERE channel_id = $1
+		ORDER BY changed_at DESC, id DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, channelID)
+	if err != nil {
+		return nil, db.WrapError(err, "get channel name history")
+	}
+	defer rows.Close()
+
+	var changes []*models.ChannelNameChange
+	for rows.Next() {
+		change := &models.ChannelNameChange{}
+		if err := rows.Scan(&change.ID, &change.ChannelID, &change.OldTitle, &change.NewTitle, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan channel name change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate channel name history: %w", err)
+	}
+
+	return changes, nil
+}
+
 // Helper function to scan multiple channels from query results
 func scanChannels(rows pgx.Rows) ([]*models.Channel, error) {
 	var channels []*models.Channel
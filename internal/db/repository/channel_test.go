@@ -225,3 +225,178 @@ func TestChannelRepository_GetChannelsByLastUpdated(t *testing.T) {
 		assert.Len(t, channels, 3)
 	})
 }
+
+func TestChannelRepository_BackfillTitle(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewChannelRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCuntitled", "", "https://youtube.com/channel/UCuntitled")))
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCtitled", "Existing Title", "https://youtube.com/channel/UCtitled")))
+
+	t.Run("sets a missing title", func(t *testing.T) {
+		updated, err := repo.BackfillTitle(ctx, "UCuntitled", "API Title")
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		channel, err := repo.GetChannelByID(ctx, "UCuntitled")
+		require.NoError(t, err)
+		assert.Equal(t, "API Title", channel.Title)
+	})
+
+	t.Run("keeps an existing title", func(t *testing.T) {
+		updated, err := repo.BackfillTitle(ctx, "UCtitled", "API Title")
+		require.NoError(t, err)
+		assert.False(t, updated)
+
+		channel, err := repo.GetChannelByID(ctx, "UCtitled")
+		require.NoError(t, err)
+		assert.Equal(t, "Existing Title", channel.Title)
+	})
+
+	t.Run("unknown channel is not an error", func(t *testing.T) {
+		updated, err := repo.BackfillTitle(ctx, "UCmissing", "API Title")
+		require.NoError(t, err)
+		assert.False(t, updated)
+	})
+}
+
+func TestChannelRepository_RecordTitleChange(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewChannelRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCrenamed", "Old Title", "https://youtube.com/channel/UCrenamed")))
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCuntitled", "", "https://youtube.com/channel/UCuntitled")))
+
+	t.Run("title change creates a history row and updates the channel", func(t *testing.T) {
+		change, err := repo.RecordTitleChange(ctx, "UCrenamed", "New Title")
+		require.NoError(t, err)
+		require.NotNil(t, change)
+		assert.Equal(t, "UCrenamed", change.ChannelID)
+		assert.Equal(t, "Old Title", change.OldTitle)
+		assert.Equal(t, "New Title", change.NewTitle)
+		assert.False(t, change.ChangedAt.IsZero())
+
+		channel, err := repo.GetChannelByID(ctx, "UCrenamed")
+		require.NoError(t, err)
+		assert.Equal(t, "New Title", channel.Title)
+
+		history, err := repo.GetNameHistory(ctx, "UCrenamed")
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, change.ID, history[0].ID)
+		assert.Equal(t, "Old Title", history[0].OldTitle)
+	})
+
+	t.Run("unchanged title is not recorded", func(t *testing.T) {
+		change, err := repo.RecordTitleChange(ctx, "UCrenamed", "New Title")
+		require.NoError(t, err)
+		assert.Nil(t, change)
+
+		history, err := repo.GetNameHistory(ctx, "UCrenamed")
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("untitled and unknown channels are not renamed", func(t *testing.T) {
+		change, err := repo.RecordTitleChange(ctx, "UCuntitled", "API Title")
+		require.NoError(t, err)
+		assert.Nil(t, change)
+
+		channel, err := repo.GetChannelByID(ctx, "UCuntitled")
+		require.NoError(t, err)
+		assert.Equal(t, "", channel.Title)
+
+		change, err = repo.RecordTitleChange(ctx, "UCmissing", "API Title")
+		require.NoError(t, err)
+		assert.Nil(t, change)
+	})
+}
+
+func TestChannelRepository_UpsertChannel_RecordsTitleChange(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewChannelRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	url := "https://youtube.com/channel/UCwebhook"
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCwebhook", "", url)))
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCwebhook", "Old Title", url)))
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCwebhook", "Old Title", url)))
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCwebhook", "", url)))
+
+	history, err := repo.GetNameHistory(ctx, "UCwebhook")
+	require.NoError(t, err)
+	assert.Empty(t, history, "filling a missing title or repeating it is not a rename")
+
+	require.NoError(t, repo.UpsertChannel(ctx, models.NewChannel("UCwebhook", "New Title", url)))
+
+	channel, err := repo.GetChannelByID(ctx, "UCwebhook")
+	require.NoError(t, err)
+	assert.Equal(t, "New Title", channel.Title)
+
+	history, err = repo.GetNameHistory(ctx, "UCwebhook")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "Old Title", history[0].OldTitle)
+	assert.Equal(t, "New Title", history[0].NewTitle)
+
+	// Enrichment reporting the same title afterwards does not record it again
+	change, err := repo.RecordTitleChange(ctx, "UCwebhook", "New Title")
+	require.NoError(t, err)
+	assert.Nil(t, change)
+}
+
+func TestChannelRepository_List_SubscriptionStatus(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	channelRepo := NewChannelRepository(td.Pool)
+	subRepo := NewSubscriptionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	for _, id := range []string{"UCactive", "UCexpired", "UCuntracked"} {
+		require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel(id, id, "https://youtube.com/channel/"+id)))
+	}
+
+	active := models.NewSubscription("UCactive", 86400)
+	active.Status = models.StatusActive
+	require.NoError(t, subRepo.Create(ctx, active))
+
+	expired := models.NewSubscription("UCexpired", 86400)
+	expired.Status = models.StatusExpired
+	require.NoError(t, subRepo.Create(ctx, expired))
+
+	t.Run("active subscriptions only", func(t *testing.T) {
+		channels, total, err := channelRepo.List(ctx, &ChannelFilters{Limit: 10, SubscriptionStatus: models.StatusActive})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, channels, 1)
+		assert.Equal(t, "UCactive", channels[0].ChannelID)
+		require.NotNil(t, channels[0].SubscriptionExpiresAt)
+		assert.WithinDuration(t, active.ExpiresAt, *channels[0].SubscriptionExpiresAt, time.Second)
+	})
+
+	t.Run("no status lists every channel without expiry", func(t *testing.T) {
+		channels, total, err := channelRepo.List(ctx, &ChannelFilters{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		for _, channel := range channels {
+			assert.Nil(t, channel.SubscriptionExpiresAt)
+		}
+	})
+}
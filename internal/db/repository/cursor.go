@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeKeysetCursor encodes a (timestamp, id) keyset position as an opaque URL-safe string.
+// Timestamps keep microsecond precision, matching PostgreSQL.
+func encodeKeysetCursor(at time.Time, id int64) string {
+	raw := fmt.Sprintf("%d:%d", at.UnixMicro(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeKeysetCursor decodes a position encoded by encodeKeysetCursor
+func decodeKeysetCursor(encoded string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	at, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	return time.UnixMicro(at).UTC(), rowID, nil
+}
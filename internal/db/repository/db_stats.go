@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MonitoredTables are the tables reported by GetTableStats, the ones that grow fastest
+var MonitoredTables = []string{
+	"videos",
+	"video_api_enrichments",
+	"sponsor_detection_jobs",
+	"webhook_events",
+}
+
+// DBStatsRepository reports database size statistics for monitoring
+type DBStatsRepository interface {
+	// GetTableStats returns approximate row counts and total sizes for MonitoredTables
+	GetTableStats(ctx context.Context) ([]*model.TableStats, error)
+}
+
+type dbStatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDBStatsRepository creates a new DBStatsRepository
+func NewDBStatsRepository(pool *pgxpool.Pool) DBStatsRepository {
+	return &dbStatsRepository{pool: pool}
+}
+
+func (r *dbStatsRepository) GetTableStats(ctx context.Context) ([]*model.TableStats, error) {
+	// Row counts come from the statistics collector, falling back to the planner's
+	// estimate, so the query stays cheap on large tables. Tables missing from the
+	// schema are skipped.
+	query := `
+		SELECT t.name,
+			GREATEST(COALESCE(s.n_live_tup, 0), c.reltuples::bigint, 0) AS approx_row_count,
+			pg_total_relation_size(c.oid) AS total_size_bytes
+		FROM unnest($1::text[]) WITH ORDINALITY AS t(name, ord)
+		JOIN pg_class c ON c.oid = to_regclass(t.name)
+		LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+		ORDER BY t.ord
+	`
+
+	rows, err := r.pool.Query(ctx, query, MonitoredTables)
+	if err != nil {
+		return nil, db.WrapError(err, "get table stats")
+	}
+	defer rows.Close()
+
+	var stats []*model.TableStats
+	for rows.Next() {
+		s := &model.TableStats{}
+		if err := rows.Scan(&s.TableName, &s.ApproxRowCount, &s.TotalSizeBytes); err != nil {
+			return nil, db.WrapError(err, "scan table stats")
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "iterate table stats")
+	}
+
+	return stats, nil
+}
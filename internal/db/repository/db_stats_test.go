@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBStatsRepository_GetTableStats(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewDBStatsRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	require.NoError(t, NewChannelRepository(td.Pool).UpsertChannel(ctx, models.NewChannel("UCstats", "Stats Channel", "https://youtube.com/channel/UCstats")))
+	require.NoError(t, NewVideoRepository(td.Pool).UpsertVideo(ctx, models.NewVideo("stats1", "UCstats", "Video", "https://youtube.com/watch?v=stats1", time.Now())))
+
+	stats, err := repo.GetTableStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, len(MonitoredTables))
+
+	for i, s := range stats {
+		assert.Equal(t, MonitoredTables[i], s.TableName)
+		assert.GreaterOrEqual(t, s.ApproxRowCount, int64(0))
+		assert.Positive(t, s.TotalSizeBytes, "%s should report its on-disk size", s.TableName)
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
@@ -37,15 +38,59 @@ type EnrichmentRepository interface {
 
 	// GetBatchLatestEnrichments retrieves the most recent enrichment for multiple videos
 	GetBatchLatestEnrichments(ctx context.Context, videoIDs []string) (map[string]*model.VideoEnrichment, error)
+
+	// GetEnrichmentAsOf retrieves the most recent enrichment for a video taken at or before asOf
+	GetEnrichmentAsOf(ctx context.Context, videoID string, asOf time.Time) (*model.VideoEnrichment, error)
+
+	// ExportEnrichments retrieves up to limit enrichments created at or after since, in
+	// (created_at, id) order, starting after the given cursor when it is not nil. Enrichments
+	// created within ExportSafetyLag are left for a later call.
+	ExportEnrichments(ctx context.Context, since time.Time, after *EnrichmentCursor, limit int) ([]*model.VideoEnrichment, error)
+}
+
+// EnrichmentCursor is a position in the enrichment export, ordered by (created_at, id)
+type EnrichmentCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// NewEnrichmentCursor returns the cursor positioned at enrichment, for exporting the enrichments after it
+func NewEnrichmentCursor(enrichment *model.VideoEnrichment) *EnrichmentCursor {
+	return &EnrichmentCursor{CreatedAt: enrichment.CreatedAt, ID: enrichment.ID}
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c *EnrichmentCursor) Encode() string {
+	return encodeKeysetCursor(c.CreatedAt, c.ID)
+}
+
+// ParseEnrichmentCursor decodes a cursor produced by Encode
+func ParseEnrichmentCursor(encoded string) (*EnrichmentCursor, error) {
+	createdAt, id, err := decodeKeysetCursor(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return &EnrichmentCursor{CreatedAt: createdAt, ID: id}, nil
 }
 
 type enrichmentRepository struct {
-	pool *pgxpool.Pool
+	pool                *pgxpool.Pool
+	compressRawResponse bool
+	dropRawResponse     bool
 }
 
 // NewEnrichmentRepository creates a new EnrichmentRepository
 func NewEnrichmentRepository(pool *pgxpool.Pool) EnrichmentRepository {
-	return &enrichmentRepository{pool: pool}
+	return NewEnrichmentRepositoryWithConfig(pool, EnrichmentRepositoryConfig{})
+}
+
+// NewEnrichmentRepositoryWithConfig creates a new EnrichmentRepository with custom settings
+func NewEnrichmentRepositoryWithConfig(pool *pgxpool.Pool, config EnrichmentRepositoryConfig) EnrichmentRepository {
+	return &enrichmentRepository{
+		pool:                pool,
+		compressRawResponse: config.CompressRawResponse,
+		dropRawResponse:     config.DropRawResponse,
+	}
 }
 
 func (r *enrichmentRepository) CreateEnrichment(ctx context.Context, enrichment *model.VideoEnrichment) error {
@@ -59,35 +104,48 @@ func (r *enrichmentRepository) CreateEnrichment(ctx context.Context, enrichment
 			thumbnail_standard_url, thumbnail_standard_width, thumbnail_standard_height,
 			thumbnail_maxres_url, thumbnail_maxres_width, thumbnail_maxres_height,
 			view_count, like_count, dislike_count, favorite_count, comment_count,
+			comments_disabled,
 			category_id, tags, default_language, default_audio_language, topic_categories,
+			inferred_language,
 			privacy_status, license, embeddable, public_stats_viewable,
 			made_for_kids, self_declared_made_for_kids,
 			upload_status, failure_reason, rejection_reason,
 			live_broadcast_content, scheduled_start_time, actual_start_time,
-			actual_end_time, concurrent_viewers,
+			actual_end_time, concurrent_viewers, is_premiere,
 			location_description, location_latitude, location_longitude,
-			content_rating, channel_title,
-			enriched_at, api_response_etag, quota_cost, api_parts_requested, raw_api_response,
+			content_rating, channel_title, channel_subscriber_count_at_enrichment,
+			enriched_at, api_response_etag, quota_cost, api_parts_requested,
+			raw_api_response, raw_api_response_gzip,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8,
 			$9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
 			$24, $25, $26, $27, $28,
-			$29, $30, $31, $32, $33,
-			$34, $35, $36, $37, $38, $39,
-			$40, $41, $42,
-			$43, $44, $45, $46, $47,
-			$48, $49, $50,
-			$51, $52,
-			$53, $54, $55, $56, $57,
-			$58, $59
+			$29,
+			$30, $31, $32, $33, $34,
+			$35,
+			$36, $37, $38, $39, $40, $41,
+			$42, $43, $44,
+			$45, $46, $47, $48, $49, $50,
+			$51, $52, $53,
+			$54, $55, $56,
+			$57, $58, $59, $60,
+			$61, $62,
+			$63, $64
 		)
 		RETURNING id, enriched_at, created_at, updated_at
 	`
 
 	// Convert JSONB fields to JSON (TEXT[] arrays are passed directly to pgx)
 	contentRatingJSON, _ := json.Marshal(enrichment.ContentRating)
-	rawAPIResponseJSON, _ := json.Marshal(enrichment.RawAPIResponse)
+	var rawAPIResponseJSON, rawAPIResponseGzip []byte
+	var err error
+	if !r.dropRawResponse {
+		rawAPIResponseJSON, rawAPIResponseGzip, err = encodeRawResponse(enrichment.RawAPIResponse, r.compressRawResponse)
+		if err != nil {
+			return db.WrapError(err, "create enrichment")
+		}
+	}
 
 	now := time.Now()
 	enrichedAt := now
@@ -95,7 +153,7 @@ func (r *enrichmentRepository) CreateEnrichment(ctx context.Context, enrichment
 		enrichedAt = enrichment.EnrichedAt
 	}
 
-	err := r.pool.QueryRow(ctx, query,
+	err = r.pool.QueryRow(ctx, query,
 		enrichment.VideoID,
 		enrichment.Description,
 		enrichment.Duration,
@@ -113,9 +171,11 @@ func (r *enrichmentRepository) CreateEnrichment(ctx context.Context, enrichment
 		// Engagement
 		enrichment.ViewCount, enrichment.LikeCount, enrichment.DislikeCount,
 		enrichment.FavoriteCount, enrichment.CommentCount,
+		enrichment.CommentsDisabled,
 		// Categorization
 		enrichment.CategoryID, enrichment.Tags, enrichment.DefaultLanguage,
 		enrichment.DefaultAudioLanguage, enrichment.TopicCategories,
+		enrichment.InferredLanguage,
 		// Content classification
 		enrichment.PrivacyStatus, enrichment.License, enrichment.Embeddable,
 		enrichment.PublicStatsViewable, enrichment.MadeForKids, enrichment.SelfDeclaredMadeForKids,
@@ -123,14 +183,14 @@ func (r *enrichmentRepository) CreateEnrichment(ctx context.Context, enrichment
 		enrichment.UploadStatus, enrichment.FailureReason, enrichment.RejectionReason,
 		// Live streaming
 		enrichment.LiveBroadcastContent, enrichment.ScheduledStartTime, enrichment.ActualStartTime,
-		enrichment.ActualEndTime, enrichment.ConcurrentViewers,
+		enrichment.ActualEndTime, enrichment.ConcurrentViewers, enrichment.IsPremiere,
 		// Location
 		enrichment.LocationDescription, enrichment.LocationLatitude, enrichment.LocationLongitude,
 		// Content rating and channel
-		contentRatingJSON, enrichment.ChannelTitle,
+		contentRatingJSON, enrichment.ChannelTitle, enrichment.ChannelSubscriberCountAtEnrichment,
 		// API metadata
 		enrichedAt, enrichment.APIResponseEtag, enrichment.QuotaCost,
-		enrichment.APIPartsRequested, rawAPIResponseJSON,
+		enrichment.APIPartsRequested, rawAPIResponseJSON, rawAPIResponseGzip,
 		// Timestamps
 		now, now,
 	).Scan(
@@ -147,37 +207,36 @@ func (r *enrichmentRepository) CreateEnrichment(ctx context.Context, enrichment
 	return nil
 }
 
-func (r *enrichmentRepository) GetLatestEnrichment(ctx context.Context, videoID string) (*model.VideoEnrichment, error) {
-	query := `
-		SELECT
-			id, video_id, description, duration, dimension, definition, caption,
-			licensed_content, projection,
-			thumbnail_default_url, thumbnail_default_width, thumbnail_default_height,
-			thumbnail_medium_url, thumbnail_medium_width, thumbnail_medium_height,
-			thumbnail_high_url, thumbnail_high_width, thumbnail_high_height,
-			thumbnail_standard_url, thumbnail_standard_width, thumbnail_standard_height,
-			thumbnail_maxres_url, thumbnail_maxres_width, thumbnail_maxres_height,
-			view_count, like_count, dislike_count, favorite_count, comment_count,
-			category_id, tags, default_language, default_audio_language, topic_categories,
-			privacy_status, license, embeddable, public_stats_viewable,
-			made_for_kids, self_declared_made_for_kids,
-			upload_status, failure_reason, rejection_reason,
-			live_broadcast_content, scheduled_start_time, actual_start_time,
-			actual_end_time, concurrent_viewers,
-			location_description, location_latitude, location_longitude,
-			content_rating, channel_title,
-			enriched_at, api_response_etag, quota_cost, api_parts_requested, raw_api_response,
-			created_at, updated_at
-		FROM video_api_enrichments
-		WHERE video_id = $1
-		ORDER BY enriched_at DESC
-		LIMIT 1
-	`
-
+// videoEnrichmentColumns lists the video_api_enrichments columns read by scanVideoEnrichment, in scan order
+const videoEnrichmentColumns = `
+	id, video_id, description, duration, dimension, definition, caption,
+	licensed_content, projection,
+	thumbnail_default_url, thumbnail_default_width, thumbnail_default_height,
+	thumbnail_medium_url, thumbnail_medium_width, thumbnail_medium_height,
+	thumbnail_high_url, thumbnail_high_width, thumbnail_high_height,
+	thumbnail_standard_url, thumbnail_standard_width, thumbnail_standard_height,
+	thumbnail_maxres_url, thumbnail_maxres_width, thumbnail_maxres_height,
+	view_count, like_count, dislike_count, favorite_count, comment_count,
+	comments_disabled,
+	category_id, tags, default_language, default_audio_language, topic_categories,
+	inferred_language,
+	privacy_status, license, embeddable, public_stats_viewable,
+	made_for_kids, self_declared_made_for_kids,
+	upload_status, failure_reason, rejection_reason,
+	live_broadcast_content, scheduled_start_time, actual_start_time,
+	actual_end_time, concurrent_viewers, is_premiere,
+	location_description, location_latitude, location_longitude,
+	content_rating, channel_title, channel_subscriber_count_at_enrichment,
+	enriched_at, api_response_etag, quota_cost, api_parts_requested,
+	raw_api_response, raw_api_response_gzip,
+	created_at, updated_at`
+
+// scanVideoEnrichment scans a row selected with videoEnrichmentColumns
+func scanVideoEnrichment(row pgx.Row) (*model.VideoEnrichment, error) {
 	enrichment := &model.VideoEnrichment{}
-	var contentRatingJSON, rawAPIResponseJSON []byte
+	var contentRatingJSON, rawAPIResponseJSON, rawAPIResponseGzip []byte
 
-	err := r.pool.QueryRow(ctx, query, videoID).Scan(
+	err := row.Scan(
 		&enrichment.ID, &enrichment.VideoID,
 		&enrichment.Description, &enrichment.Duration, &enrichment.Dimension,
 		&enrichment.Definition, &enrichment.Caption, &enrichment.LicensedContent, &enrichment.Projection,
@@ -190,9 +249,11 @@ func (r *enrichmentRepository) GetLatestEnrichment(ctx context.Context, videoID
 		// Engagement
 		&enrichment.ViewCount, &enrichment.LikeCount, &enrichment.DislikeCount,
 		&enrichment.FavoriteCount, &enrichment.CommentCount,
+		&enrichment.CommentsDisabled,
 		// Categorization
 		&enrichment.CategoryID, &enrichment.Tags, &enrichment.DefaultLanguage,
 		&enrichment.DefaultAudioLanguage, &enrichment.TopicCategories,
+		&enrichment.InferredLanguage,
 		// Content classification
 		&enrichment.PrivacyStatus, &enrichment.License, &enrichment.Embeddable,
 		&enrichment.PublicStatsViewable, &enrichment.MadeForKids, &enrichment.SelfDeclaredMadeForKids,
@@ -200,18 +261,38 @@ func (r *enrichmentRepository) GetLatestEnrichment(ctx context.Context, videoID
 		&enrichment.UploadStatus, &enrichment.FailureReason, &enrichment.RejectionReason,
 		// Live streaming
 		&enrichment.LiveBroadcastContent, &enrichment.ScheduledStartTime, &enrichment.ActualStartTime,
-		&enrichment.ActualEndTime, &enrichment.ConcurrentViewers,
+		&enrichment.ActualEndTime, &enrichment.ConcurrentViewers, &enrichment.IsPremiere,
 		// Location
 		&enrichment.LocationDescription, &enrichment.LocationLatitude, &enrichment.LocationLongitude,
 		// Content rating and channel
-		&contentRatingJSON, &enrichment.ChannelTitle,
+		&contentRatingJSON, &enrichment.ChannelTitle, &enrichment.ChannelSubscriberCountAtEnrichment,
 		// API metadata
 		&enrichment.EnrichedAt, &enrichment.APIResponseEtag, &enrichment.QuotaCost,
-		&enrichment.APIPartsRequested, &rawAPIResponseJSON,
+		&enrichment.APIPartsRequested, &rawAPIResponseJSON, &rawAPIResponseGzip,
 		// Timestamps
 		&enrichment.CreatedAt, &enrichment.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal JSONB fields (TEXT[] arrays are scanned directly by pgx)
+	json.Unmarshal(contentRatingJSON, &enrichment.ContentRating)
+	decodeRawResponse(rawAPIResponseJSON, rawAPIResponseGzip, &enrichment.RawAPIResponse)
 
+	return enrichment, nil
+}
+
+func (r *enrichmentRepository) GetLatestEnrichment(ctx context.Context, videoID string) (*model.VideoEnrichment, error) {
+	query := `
+		SELECT` + videoEnrichmentColumns + `
+		FROM video_api_enrichments
+		WHERE video_id = $1
+		ORDER BY enriched_at DESC
+		LIMIT 1
+	`
+
+	enrichment, err := scanVideoEnrichment(r.pool.QueryRow(ctx, query, videoID))
 	if err == pgx.ErrNoRows {
 		return nil, db.ErrNotFound
 	}
@@ -219,13 +300,76 @@ func (r *enrichmentRepository) GetLatestEnrichment(ctx context.Context, videoID
 		return nil, db.WrapError(err, "get latest enrichment")
 	}
 
-	// Unmarshal JSONB fields (TEXT[] arrays are scanned directly by pgx)
-	json.Unmarshal(contentRatingJSON, &enrichment.ContentRating)
-	json.Unmarshal(rawAPIResponseJSON, &enrichment.RawAPIResponse)
+	return enrichment, nil
+}
+
+func (r *enrichmentRepository) GetEnrichmentAsOf(ctx context.Context, videoID string, asOf time.Time) (*model.VideoEnrichment, error) {
+	query := `
+		SELECT` + videoEnrichmentColumns + `
+		FROM video_api_enrichments
+		WHERE video_id = $1 AND enriched_at <= $2
+		ORDER BY enriched_at DESC
+		LIMIT 1
+	`
+
+	enrichment, err := scanVideoEnrichment(r.pool.QueryRow(ctx, query, videoID, asOf))
+	if err == pgx.ErrNoRows {
+		return nil, db.ErrNotFound
+	}
+	if err != nil {
+		return nil, db.WrapError(err, "get enrichment as of")
+	}
 
 	return enrichment, nil
 }
 
+// ExportSafetyLag holds the enrichment export back from the newest rows. created_at is set when a
+// row is inserted rather than when its transaction commits, so a row may become visible after rows
+// with a later created_at were exported and the cursor moved past it. Inserts commit well within
+// the lag, so by the time a row is old enough to export, every row before it is visible too.
+const ExportSafetyLag = time.Minute
+
+func (r *enrichmentRepository) ExportEnrichments(ctx context.Context, since time.Time, after *EnrichmentCursor, limit int) ([]*model.VideoEnrichment, error) {
+	query := `
+		SELECT` + videoEnrichmentColumns + `
+		FROM video_api_enrichments
+		WHERE created_at >= $1 AND created_at < NOW() - make_interval(secs => $2)
+	`
+	args := []interface{}{since, ExportSafetyLag.Seconds()}
+
+	if after != nil {
+		query += ` AND (created_at, id) > ($3, $4)`
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at, id LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, db.WrapError(err, "export enrichments")
+	}
+	defer rows.Close()
+
+	enrichments := []*model.VideoEnrichment{}
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
+
+		enrichment, err := scanVideoEnrichment(rows)
+		if err != nil {
+			return nil, db.WrapError(err, "scan exported enrichment")
+		}
+		enrichments = append(enrichments, enrichment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "export enrichments")
+	}
+
+	return enrichments, nil
+}
+
 func (r *enrichmentRepository) GetEnrichmentHistory(ctx context.Context, videoID string, limit int) ([]*model.VideoEnrichment, error) {
 	if limit <= 0 {
 		limit = 10
@@ -250,7 +394,11 @@ func (r *enrichmentRepository) GetEnrichmentHistory(ctx context.Context, videoID
 	defer rows.Close()
 
 	var enrichments []*model.VideoEnrichment
-	for rows.Next() {
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
+
 		e := &model.VideoEnrichment{}
 		err := rows.Scan(
 			&e.ID, &e.VideoID, &e.EnrichedAt, &e.QuotaCost,
@@ -359,31 +507,10 @@ func (r *enrichmentRepository) GetBatchLatestEnrichments(ctx context.Context, vi
 	}
 
 	query := `
-		WITH latest_enrichments AS (
-			SELECT DISTINCT ON (video_id)
-				id, video_id, description, duration, dimension, definition, caption,
-				licensed_content, projection,
-				thumbnail_default_url, thumbnail_default_width, thumbnail_default_height,
-				thumbnail_medium_url, thumbnail_medium_width, thumbnail_medium_height,
-				thumbnail_high_url, thumbnail_high_width, thumbnail_high_height,
-				thumbnail_standard_url, thumbnail_standard_width, thumbnail_standard_height,
-				thumbnail_maxres_url, thumbnail_maxres_width, thumbnail_maxres_height,
-				view_count, like_count, dislike_count, favorite_count, comment_count,
-				category_id, tags, default_language, default_audio_language, topic_categories,
-				privacy_status, license, embeddable, public_stats_viewable,
-				made_for_kids, self_declared_made_for_kids,
-				upload_status, failure_reason, rejection_reason,
-				live_broadcast_content, scheduled_start_time, actual_start_time,
-				actual_end_time, concurrent_viewers,
-				location_description, location_latitude, location_longitude,
-				content_rating, channel_title,
-				enriched_at, api_response_etag, quota_cost, api_parts_requested, raw_api_response,
-				created_at, updated_at
-			FROM video_api_enrichments
-			WHERE video_id = ANY($1)
-			ORDER BY video_id, enriched_at DESC
-		)
-		SELECT * FROM latest_enrichments
+		SELECT DISTINCT ON (video_id)` + videoEnrichmentColumns + `
+		FROM video_api_enrichments
+		WHERE video_id = ANY($1)
+		ORDER BY video_id, enriched_at DESC
 	`
 
 	rows, err := r.pool.Query(ctx, query, videoIDs)
@@ -393,53 +520,16 @@ func (r *enrichmentRepository) GetBatchLatestEnrichments(ctx context.Context, vi
 	defer rows.Close()
 
 	enrichments := make(map[string]*model.VideoEnrichment)
-	for rows.Next() {
-		enrichment := &model.VideoEnrichment{}
-		var contentRatingJSON, rawAPIResponseJSON []byte
-
-		err := rows.Scan(
-			&enrichment.ID, &enrichment.VideoID,
-			&enrichment.Description, &enrichment.Duration, &enrichment.Dimension,
-			&enrichment.Definition, &enrichment.Caption, &enrichment.LicensedContent, &enrichment.Projection,
-			// Thumbnails
-			&enrichment.ThumbnailDefaultURL, &enrichment.ThumbnailDefaultWidth, &enrichment.ThumbnailDefaultHeight,
-			&enrichment.ThumbnailMediumURL, &enrichment.ThumbnailMediumWidth, &enrichment.ThumbnailMediumHeight,
-			&enrichment.ThumbnailHighURL, &enrichment.ThumbnailHighWidth, &enrichment.ThumbnailHighHeight,
-			&enrichment.ThumbnailStandardURL, &enrichment.ThumbnailStandardWidth, &enrichment.ThumbnailStandardHeight,
-			&enrichment.ThumbnailMaxresURL, &enrichment.ThumbnailMaxresWidth, &enrichment.ThumbnailMaxresHeight,
-			// Engagement
-			&enrichment.ViewCount, &enrichment.LikeCount, &enrichment.DislikeCount,
-			&enrichment.FavoriteCount, &enrichment.CommentCount,
-			// Categorization
-			&enrichment.CategoryID, &enrichment.Tags, &enrichment.DefaultLanguage,
-			&enrichment.DefaultAudioLanguage, &enrichment.TopicCategories,
-			// Content classification
-			&enrichment.PrivacyStatus, &enrichment.License, &enrichment.Embeddable,
-			&enrichment.PublicStatsViewable, &enrichment.MadeForKids, &enrichment.SelfDeclaredMadeForKids,
-			// Upload details
-			&enrichment.UploadStatus, &enrichment.FailureReason, &enrichment.RejectionReason,
-			// Live streaming
-			&enrichment.LiveBroadcastContent, &enrichment.ScheduledStartTime, &enrichment.ActualStartTime,
-			&enrichment.ActualEndTime, &enrichment.ConcurrentViewers,
-			// Location
-			&enrichment.LocationDescription, &enrichment.LocationLatitude, &enrichment.LocationLongitude,
-			// Content rating and channel
-			&contentRatingJSON, &enrichment.ChannelTitle,
-			// API metadata
-			&enrichment.EnrichedAt, &enrichment.APIResponseEtag, &enrichment.QuotaCost,
-			&enrichment.APIPartsRequested, &rawAPIResponseJSON,
-			// Timestamps
-			&enrichment.CreatedAt, &enrichment.UpdatedAt,
-		)
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
 
+		enrichment, err := scanVideoEnrichment(rows)
 		if err != nil {
 			return nil, db.WrapError(err, "scan batch enrichment")
 		}
 
-		// Unmarshal JSONB fields (TEXT[] arrays are scanned directly by pgx)
-		json.Unmarshal(contentRatingJSON, &enrichment.ContentRating)
-		json.Unmarshal(rawAPIResponseJSON, &enrichment.RawAPIResponse)
-
 		enrichments[enrichment.VideoID] = enrichment
 	}
 
@@ -462,12 +552,23 @@ type ChannelEnrichmentRepository interface {
 }
 
 type channelEnrichmentRepository struct {
-	pool *pgxpool.Pool
+	pool                *pgxpool.Pool
+	compressRawResponse bool
+	dropRawResponse     bool
 }
 
 // NewChannelEnrichmentRepository creates a new ChannelEnrichmentRepository
 func NewChannelEnrichmentRepository(pool *pgxpool.Pool) ChannelEnrichmentRepository {
-	return &channelEnrichmentRepository{pool: pool}
+	return NewChannelEnrichmentRepositoryWithConfig(pool, EnrichmentRepositoryConfig{})
+}
+
+// NewChannelEnrichmentRepositoryWithConfig creates a new ChannelEnrichmentRepository with custom settings
+func NewChannelEnrichmentRepositoryWithConfig(pool *pgxpool.Pool, config EnrichmentRepositoryConfig) ChannelEnrichmentRepository {
+	return &channelEnrichmentRepository{
+		pool:                pool,
+		compressRawResponse: config.CompressRawResponse,
+		dropRawResponse:     config.DropRawResponse,
+	}
 }
 
 func (r *channelEnrichmentRepository) Create(ctx context.Context, enrichment *model.ChannelEnrichment) error {
@@ -480,7 +581,8 @@ func (r *channelEnrichmentRepository) Create(ctx context.Context, enrichment *mo
 			related_playlists_likes, related_playlists_uploads, related_playlists_favorites,
 			topic_categories,
 			privacy_status, is_linked, long_uploads_status, made_for_kids,
-			enriched_at, api_response_etag, quota_cost, api_parts_requested, raw_api_response,
+			enriched_at, api_response_etag, quota_cost, api_parts_requested,
+			raw_api_response, raw_api_response_gzip,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5,
@@ -490,14 +592,22 @@ func (r *channelEnrichmentRepository) Create(ctx context.Context, enrichment *mo
 			$15, $16, $17,
 			$18,
 			$19, $20, $21, $22,
-			$23, $24, $25, $26, $27,
-			$28, $29
+			$23, $24, $25, $26,
+			$27, $28,
+			$29, $30
 		)
 		RETURNING id, enriched_at, created_at, updated_at
 	`
 
 	// Convert JSONB fields to JSON (TEXT[] arrays are passed directly to pgx)
-	rawAPIResponseJSON, _ := json.Marshal(enrichment.RawAPIResponse)
+	var rawAPIResponseJSON, rawAPIResponseGzip []byte
+	var err error
+	if !r.dropRawResponse {
+		rawAPIResponseJSON, rawAPIResponseGzip, err = encodeRawResponse(enrichment.RawAPIResponse, r.compressRawResponse)
+		if err != nil {
+			return db.WrapError(err, "create channel enrichment")
+		}
+	}
 
 	now := time.Now()
 	enrichedAt := now
@@ -505,7 +615,7 @@ func (r *channelEnrichmentRepository) Create(ctx context.Context, enrichment *mo
 		enrichedAt = enrichment.EnrichedAt
 	}
 
-	err := r.pool.QueryRow(ctx, query,
+	err = r.pool.QueryRow(ctx, query,
 		enrichment.ChannelID,
 		enrichment.Description,
 		enrichment.CustomURL,
@@ -540,6 +650,7 @@ func (r *channelEnrichmentRepository) Create(ctx context.Context, enrichment *mo
 		enrichment.QuotaCost,
 		enrichment.APIPartsRequested,
 		rawAPIResponseJSON,
+		rawAPIResponseGzip,
 		// Timestamps
 		now, now,
 	).Scan(
@@ -566,7 +677,8 @@ func (r *channelEnrichmentRepository) GetLatest(ctx context.Context, channelID s
 			related_playlists_likes, related_playlists_uploads, related_playlists_favorites,
 			topic_categories,
 			privacy_status, is_linked, long_uploads_status, made_for_kids,
-			enriched_at, api_response_etag, quota_cost, api_parts_requested, raw_api_response,
+			enriched_at, api_response_etag, quota_cost, api_parts_requested,
+			raw_api_response, raw_api_response_gzip,
 			created_at, updated_at
 		FROM channel_api_enrichments
 		WHERE channel_id = $1
@@ -575,7 +687,7 @@ func (r *channelEnrichmentRepository) GetLatest(ctx context.Context, channelID s
 	`
 
 	enrichment := &model.ChannelEnrichment{}
-	var rawAPIResponseJSON []byte
+	var rawAPIResponseJSON, rawAPIResponseGzip []byte
 
 	err := r.pool.QueryRow(ctx, query, channelID).Scan(
 		&enrichment.ID,
@@ -613,6 +725,7 @@ func (r *channelEnrichmentRepository) GetLatest(ctx context.Context, channelID s
 		&enrichment.QuotaCost,
 		&enrichment.APIPartsRequested,
 		&rawAPIResponseJSON,
+		&rawAPIResponseGzip,
 		// Timestamps
 		&enrichment.CreatedAt,
 		&enrichment.UpdatedAt,
@@ -626,7 +739,7 @@ func (r *channelEnrichmentRepository) GetLatest(ctx context.Context, channelID s
 	}
 
 	// Unmarshal JSONB fields (TEXT[] arrays are scanned directly by pgx)
-	json.Unmarshal(rawAPIResponseJSON, &enrichment.RawAPIResponse)
+	decodeRawResponse(rawAPIResponseJSON, rawAPIResponseGzip, &enrichment.RawAPIResponse)
 
 	return enrichment, nil
 }
@@ -654,7 +767,11 @@ func (r *channelEnrichmentRepository) GetHistory(ctx context.Context, channelID
 	defer rows.Close()
 
 	var enrichments []*model.ChannelEnrichment
-	for rows.Next() {
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
+
 		e := &model.ChannelEnrichment{}
 		err := rows.Scan(
 			&e.ID,
@@ -690,7 +807,8 @@ func (r *channelEnrichmentRepository) GetBatchLatest(ctx context.Context, channe
 				related_playlists_likes, related_playlists_uploads, related_playlists_favorites,
 				topic_categories,
 				privacy_status, is_linked, long_uploads_status, made_for_kids,
-				enriched_at, api_response_etag, quota_cost, api_parts_requested, raw_api_response,
+				enriched_at, api_response_etag, quota_cost, api_parts_requested,
+				raw_api_response, raw_api_response_gzip,
 				created_at, updated_at
 			FROM channel_api_enrichments
 			WHERE channel_id = ANY($1)
@@ -706,9 +824,13 @@ func (r *channelEnrichmentRepository) GetBatchLatest(ctx context.Context, channe
 	defer rows.Close()
 
 	enrichments := make(map[string]*model.ChannelEnrichment)
-	for rows.Next() {
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
+
 		enrichment := &model.ChannelEnrichment{}
-		var rawAPIResponseJSON []byte
+		var rawAPIResponseJSON, rawAPIResponseGzip []byte
 
 		err := rows.Scan(
 			&enrichment.ID,
@@ -746,6 +868,7 @@ func (r *channelEnrichmentRepository) GetBatchLatest(ctx context.Context, channe
 			&enrichment.QuotaCost,
 			&enrichment.APIPartsRequested,
 			&rawAPIResponseJSON,
+			&rawAPIResponseGzip,
 			// Timestamps
 			&enrichment.CreatedAt,
 			&enrichment.UpdatedAt,
@@ -756,7 +879,7 @@ func (r *channelEnrichmentRepository) GetBatchLatest(ctx context.Context, channe
 		}
 
 		// Unmarshal JSONB fields (TEXT[] arrays are scanned directly by pgx)
-		json.Unmarshal(rawAPIResponseJSON, &enrichment.RawAPIResponse)
+		decodeRawResponse(rawAPIResponseJSON, rawAPIResponseGzip, &enrichment.RawAPIResponse)
 
 		enrichments[enrichment.ChannelID] = enrichment
 	}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrJobNotPending is returned when cancelling a job that has already started or finished
+var ErrJobNotPending = errors.New("job is not pending")
+
 // JobFilters contains filters for listing enrichment jobs
 type JobFilters struct {
 	Status string
@@ -43,7 +47,17 @@ type EnrichmentJobRepository interface {
 	// MarkJobFailed marks a job as failed with error message
 	MarkJobFailed(ctx context.Context, id int64, errorMsg string, stackTrace *string) error
 
-	// IncrementAttempts increments job attempt count
+	// MarkJobDeferred marks a job as deferred, recording why and when it will be retried
+	MarkJobDeferred(ctx context.Context, id int64, reason string, nextRetryAt time.Time) error
+
+	// MarkJobSkipped marks a job as skipped, recording why it was not processed
+	MarkJobSkipped(ctx context.Context, id int64, reason string) error
+
+	// CancelPendingJob marks a pending job as cancelled.
+	// It returns ErrJobNotPending if the job is no longer pending.
+	CancelPendingJob(ctx context.Context, id int64) error
+
+	// IncrementAttempts counts one more run of the job's task, including asynq retries
 	IncrementAttempts(ctx context.Context, id int64) error
 
 	// GetPendingJobs retrieves pending jobs
@@ -114,7 +128,7 @@ func (r *enrichmentJobRepository) GetJobByID(ctx context.Context, id int64) (*mo
 		SELECT id, asynq_task_id, job_type, video_id, status, priority,
 		       scheduled_at, started_at, completed_at,
 		       attempts, max_attempts, next_retry_at,
-		       error_message, error_stack_trace, metadata,
+		       error_message, error_stack_trace, defer_reason, metadata,
 		       created_at, updated_at
 		FROM enrichment_jobs
 		WHERE id = $1
@@ -128,7 +142,7 @@ func (r *enrichmentJobRepository) GetJobByID(ctx context.Context, id int64) (*mo
 		&job.Status, &job.Priority,
 		&job.ScheduledAt, &job.StartedAt, &job.CompletedAt,
 		&job.Attempts, &job.MaxAttempts, &job.NextRetryAt,
-		&job.ErrorMessage, &job.ErrorStackTrace, &metadataJSON,
+		&job.ErrorMessage, &job.ErrorStackTrace, &job.DeferReason, &metadataJSON,
 		&job.CreatedAt, &job.UpdatedAt,
 	)
 
@@ -151,7 +165,7 @@ func (r *enrichmentJobRepository) GetJobByAsynqID(ctx context.Context, asynqTask
 		SELECT id, asynq_task_id, job_type, video_id, status, priority,
 		       scheduled_at, started_at, completed_at,
 		       attempts, max_attempts, next_retry_at,
-		       error_message, error_stack_trace, metadata,
+		       error_message, error_stack_trace, defer_reason, metadata,
 		       created_at, updated_at
 		FROM enrichment_jobs
 		WHERE asynq_task_id = $1
@@ -165,7 +179,7 @@ func (r *enrichmentJobRepository) GetJobByAsynqID(ctx context.Context, asynqTask
 		&job.Status, &job.Priority,
 		&job.ScheduledAt, &job.StartedAt, &job.CompletedAt,
 		&job.Attempts, &job.MaxAttempts, &job.NextRetryAt,
-		&job.ErrorMessage, &job.ErrorStackTrace, &metadataJSON,
+		&job.ErrorMessage, &job.ErrorStackTrace, &job.DeferReason, &metadataJSON,
 		&job.CreatedAt, &job.UpdatedAt,
 	)
 
@@ -247,6 +261,62 @@ func (r *enrichmentJobRepository) MarkJobFailed(ctx context.Context, id int64, e
 	return nil
 }
 
+func (r *enrichmentJobRepository) MarkJobDeferred(ctx context.Context, id int64, reason string, nextRetryAt time.Time) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'deferred',
+		    completed_at = NOW(),
+		    defer_reason = $2,
+		    next_retry_at = $3,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.pool.Exec(ctx, query, id, reason, nextRetryAt)
+	if err != nil {
+		return db.WrapError(err, "mark job deferred")
+	}
+
+	return nil
+}
+
+func (r *enrichmentJobRepository) MarkJobSkipped(ctx context.Context, id int64, reason string) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'skipped',
+		    completed_at = NOW(),
+		    defer_reason = $2,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.pool.Exec(ctx, query, id, reason)
+	if err != nil {
+		return db.WrapError(err, "mark job skipped")
+	}
+
+	return nil
+}
+
+func (r *enrichmentJobRepository) CancelPendingJob(ctx context.Context, id int64) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'cancelled', completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`
+
+	result, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return db.WrapError(err, "cancel pending job")
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("cancel pending job %d: %w", id, ErrJobNotPending)
+	}
+
+	return nil
+}
+
 func (r *enrichmentJobRepository) IncrementAttempts(ctx context.Context, id int64) error {
 	query := `
 		UPDATE enrichment_jobs
@@ -374,7 +444,7 @@ func (r *enrichmentJobRepository) ListJobs(ctx context.Context, filters JobFilte
 		SELECT id, asynq_task_id, job_type, video_id, status, priority,
 		       scheduled_at, started_at, completed_at,
 		       attempts, max_attempts, next_retry_at,
-		       error_message, error_stack_trace, metadata,
+		       error_message, error_stack_trace, defer_reason, metadata,
 		       created_at, updated_at
 		FROM enrichment_jobs
 	` + whereClause
@@ -405,7 +475,7 @@ func (r *enrichmentJobRepository) ListJobs(ctx context.Context, filters JobFilte
 			&job.Status, &job.Priority,
 			&job.ScheduledAt, &job.StartedAt, &job.CompletedAt,
 			&job.Attempts, &job.MaxAttempts, &job.NextRetryAt,
-			&job.ErrorMessage, &job.ErrorStackTrace, &metadataJSON,
+			&job.ErrorMessage, &job.ErrorStackTrace, &job.DeferReason, &metadataJSON,
 			&job.CreatedAt, &job.UpdatedAt,
 		)
 		if err != nil {
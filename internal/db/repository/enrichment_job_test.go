@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichmentJobRepository_IncrementAttempts(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewEnrichmentJobRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	require.NoError(t, NewChannelRepository(td.Pool).UpsertChannel(ctx, models.NewChannel("UCjobs", "Jobs Channel", "https://youtube.com/channel/UCjobs")))
+	require.NoError(t, NewVideoRepository(td.Pool).UpsertVideo(ctx, models.NewVideo("video1", "UCjobs", "Video", "https://youtube.com/watch?v=video1", time.Now())))
+
+	taskID := "task-abc"
+	job := &model.EnrichmentJob{
+		AsynqTaskID: &taskID,
+		JobType:     "enrichment:video",
+		VideoID:     "video1",
+		Status:      "pending",
+		ScheduledAt: time.Now(),
+		MaxAttempts: 3,
+	}
+	require.NoError(t, repo.CreateJob(ctx, job))
+
+	// Each run marks the job processing and counts the attempt, past max_attempts too
+	for i := 0; i < 4; i++ {
+		require.NoError(t, repo.MarkJobProcessing(ctx, job.ID))
+		require.NoError(t, repo.IncrementAttempts(ctx, job.ID))
+	}
+
+	stored, err := repo.GetJobByID(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, stored.Attempts)
+	assert.Equal(t, 3, stored.MaxAttempts)
+	assert.Equal(t, "processing", stored.Status)
+
+	byTask, err := repo.GetJobByAsynqID(ctx, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, byTask.Attempts)
+}
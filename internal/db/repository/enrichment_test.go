@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichmentRepository_ExportEnrichments_HoldsBackRecentRows(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	ctx := context.Background()
+	td.TruncateTables(t)
+
+	require.NoError(t, NewChannelRepository(td.Pool).UpsertChannel(ctx, models.NewChannel("UCexport", "Export", "https://youtube.com/channel/UCexport")))
+	require.NoError(t, NewVideoRepository(td.Pool).UpsertVideo(ctx, models.NewVideo("export123", "UCexport", "Export", "https://youtube.com/watch?v=export123", time.Now())))
+
+	repo := NewEnrichmentRepository(td.Pool)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "export123"}))
+	}
+
+	// Two enrichments are old enough to export; the newest is still inside the safety lag
+	_, err := td.Pool.Exec(ctx, `
+		UPDATE video_api_enrichments SET created_at = NOW() - INTERVAL '1 hour' + id * INTERVAL '1 second'
+		WHERE id IN (SELECT id FROM video_api_enrichments ORDER BY id LIMIT 2)
+	`)
+	require.NoError(t, err)
+
+	since := time.Now().Add(-2 * time.Hour)
+	exported, err := repo.ExportEnrichments(ctx, since, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, exported, 2)
+	assert.Less(t, exported[0].ID, exported[1].ID)
+
+	more, err := repo.ExportEnrichments(ctx, since, NewEnrichmentCursor(exported[1]), 10)
+	require.NoError(t, err)
+	assert.Empty(t, more, "the recent enrichment is left for a later sync")
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LiveViewerRepository defines operations for livestream concurrent-viewer samples
+type LiveViewerRepository interface {
+	// GetLiveVideoIDs returns videos whose latest enrichment is live and that have not been
+	// sampled as no longer live since, oldest sample first
+	GetLiveVideoIDs(ctx context.Context, limit int) ([]string, error)
+
+	// SaveSamples records a batch of samples; a zero SampledAt is set to the current time
+	SaveSamples(ctx context.Context, samples []*model.LiveViewerSample) error
+
+	// GetSamplesByVideoID retrieves a video's samples, oldest first
+	GetSamplesByVideoID(ctx context.Context, videoID string) ([]*model.LiveViewerSample, error)
+}
+
+type liveViewerRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewLiveViewerRepository creates a new LiveViewerRepository
+func NewLiveViewerRepository(pool *pgxpool.Pool) LiveViewerRepository {
+	return &liveViewerRepository{pool: pool}
+}
+
+func (r *liveViewerRepository) GetLiveVideoIDs(ctx context.Context, limit int) ([]string, error) {
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (video_id) video_id, live_broadcast_content, enriched_at
+			FROM video_api_enrichments
+			ORDER BY video_id, enriched_at DESC
+		)
+		SELECT l.video_id
+		FROM latest l
+		LEFT JOIN LATERAL (
+			SELECT MAX(s.sampled_at) AS last_sampled_at
+			FROM video_live_viewer_samples s
+			WHERE s.video_id = l.video_id
+		) samples ON true
+		WHERE l.live_broadcast_content = 'live'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM video_live_viewer_samples s
+		      WHERE s.video_id = l.video_id
+		        AND s.sampled_at >= l.enriched_at
+		        AND s.live_broadcast_content IS DISTINCT FROM 'live'
+		  )
+		ORDER BY samples.last_sampled_at ASC NULLS FIRST, l.video_id
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, db.WrapError(err, "get live video ids")
+	}
+	defer rows.Close()
+
+	var videoIDs []string
+	for rows.Next() {
+		var videoID string
+		if err := rows.Scan(&videoID); err != nil {
+			return nil, db.WrapError(err, "scan live video id")
+		}
+		videoIDs = append(videoIDs, videoID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "iterate live video ids")
+	}
+
+	return videoIDs, nil
+}
+
+func (r *liveViewerRepository) SaveSamples(ctx context.Context, samples []*model.LiveViewerSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	videoIDs := make([]string, len(samples))
+	contents := make([]*string, len(samples))
+	viewers := make([]*int64, len(samples))
+	sampledAt := make([]time.Time, len(samples))
+	for i, sample := range samples {
+		if sample.SampledAt.IsZero() {
+			sample.SampledAt = time.Now()
+		}
+		videoIDs[i] = sample.VideoID
+		contents[i] = sample.LiveBroadcastContent
+		viewers[i] = sample.ConcurrentViewers
+		sampledAt[i] = sample.SampledAt
+	}
+
+	query := `
+		INSERT INTO video_live_viewer_samples (video_id, live_broadcast_content, concurrent_viewers, sampled_at)
+		SELECT video_id, live_broadcast_content, concurrent_viewers, sampled_at
+		FROM unnest($1::text[], $2::text[], $3::bigint[], $4::timestamptz[])
+			AS s(video_id, live_broadcast_content, concurrent_viewers, sampled_at)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, videoIDs, contents, viewers, sampledAt); err != nil {
+		return db.WrapError(err, "save live viewer samples")
+	}
+
+	return nil
+}
+
+func (r *liveViewerRepository) GetSamplesByVideoID(ctx context.Context, videoID string) ([]*model.LiveViewerSample, error) {
+	query := `
+		SELECT id, video_id, live_broadcast_content, concurrent_viewers, sampled_at
+		FROM video_live_viewer_samples
+		WHERE video_id = $1
+		ORDER BY sampled_at ASC, id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, videoID)
+	if err != nil {
+		return nil, db.WrapError(err, "get live viewer samples")
+	}
+	defer rows.Close()
+
+	var samples []*model.LiveViewerSample
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
+
+		sample := &model.LiveViewerSample{}
+		if err := rows.Scan(
+			&sample.ID,
+			&sample.VideoID,
+			&sample.LiveBroadcastContent,
+			&sample.ConcurrentViewers,
+			&sample.SampledAt,
+		); err != nil {
+			return nil, db.WrapError(err, "scan live viewer sample")
+		}
+		samples = append(samples, sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "iterate live viewer samples")
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveViewerRepository_GetLiveVideoIDs(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewLiveViewerRepository(td.Pool)
+	enrichmentRepo := NewEnrichmentRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	require.NoError(t, NewChannelRepository(td.Pool).UpsertChannel(ctx, models.NewChannel("UClive", "Live Channel", "https://youtube.com/channel/UClive")))
+	for videoID, content := range map[string]string{"live1": "live", "vod1": "none"} {
+		require.NoError(t, NewVideoRepository(td.Pool).UpsertVideo(ctx, models.NewVideo(videoID, "UClive", "Video", "https://youtube.com/watch?v="+videoID, time.Now())))
+		content := content
+		require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: videoID, LiveBroadcastContent: &content}))
+	}
+
+	videoIDs, err := repo.GetLiveVideoIDs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"live1"}, videoIDs)
+
+	live := "live"
+	viewers := int64(42)
+	require.NoError(t, repo.SaveSamples(ctx, []*model.LiveViewerSample{{VideoID: "live1", LiveBroadcastContent: &live, ConcurrentViewers: &viewers}}))
+
+	videoIDs, err = repo.GetLiveVideoIDs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"live1"}, videoIDs, "a live sample keeps the video polled")
+
+	ended := "none"
+	require.NoError(t, repo.SaveSamples(ctx, []*model.LiveViewerSample{{VideoID: "live1", LiveBroadcastContent: &ended}}))
+
+	videoIDs, err = repo.GetLiveVideoIDs(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, videoIDs, "an ended sample stops polling")
+
+	samples, err := repo.GetSamplesByVideoID(ctx, "live1")
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, int64(42), *samples[0].ConcurrentViewers)
+	assert.Nil(t, samples[1].ConcurrentViewers)
+}
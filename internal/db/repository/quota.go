@@ -18,6 +18,10 @@ type QuotaRepository interface {
 	// IncrementQuota increments today's quota usage
 	IncrementQuota(ctx context.Context, quotaCost int, operationType string) error
 
+	// IncrementQuotaForDate records calls of an operation costing quotaCost in total
+	// against the given quota day
+	IncrementQuotaForDate(ctx context.Context, date time.Time, quotaCost, calls int, operationType string) error
+
 	// GetQuotaForDate retrieves quota usage for a specific date
 	GetQuotaForDate(ctx context.Context, date time.Time) (*model.APIQuotaUsage, error)
 
@@ -38,7 +42,12 @@ func NewQuotaRepository(pool *pgxpool.Pool) QuotaRepository {
 }
 
 func (r *quotaRepository) GetTodaysQuota(ctx context.Context) (*model.QuotaInfo, error) {
-	query := `SELECT * FROM get_todays_quota_usage()`
+	// The day boundary comes from the database, whose CURRENT_DATE the usage is recorded under
+	query := `
+		SELECT q.quota_used, q.quota_limit, q.quota_remaining, q.operations_count,
+		       CURRENT_DATE, (CURRENT_DATE + 1)::timestamptz
+		FROM get_todays_quota_usage() q
+	`
 
 	info := &model.QuotaInfo{}
 	err := r.pool.QueryRow(ctx, query).Scan(
@@ -46,6 +55,8 @@ func (r *quotaRepository) GetTodaysQuota(ctx context.Context) (*model.QuotaInfo,
 		&info.QuotaLimit,
 		&info.QuotaRemaining,
 		&info.OperationsCount,
+		&info.Date,
+		&info.ResetsAt,
 	)
 
 	if err != nil {
@@ -69,6 +80,20 @@ func (r *quotaRepository) IncrementQuota(ctx context.Context, quotaCost int, ope
 	return nil
 }
 
+func (r *quotaRepository) IncrementQuotaForDate(ctx context.Context, date time.Time, quotaCost, calls int, operationType string) error {
+	if operationType == "" {
+		operationType = "other"
+	}
+
+	query := `SELECT increment_quota_usage_on($1, $2, $3, $4)`
+	_, err := r.pool.Exec(ctx, query, date.Format("2006-01-02"), quotaCost, operationType, calls)
+	if err != nil {
+		return db.WrapError(err, "increment quota for date")
+	}
+
+	return nil
+}
+
 func (r *quotaRepository) GetQuotaForDate(ctx context.Context, date time.Time) (*model.APIQuotaUsage, error) {
 	query := `
 		SELECT id, date, quota_used, quota_limit, operations_count,
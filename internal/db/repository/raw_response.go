@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// EnrichmentRepositoryConfig holds optional settings for the video and channel enrichment repositories
+type EnrichmentRepositoryConfig struct {
+	CompressRawResponse bool // Store raw_api_response gzip-compressed in raw_api_response_gzip
+	DropRawResponse     bool // Write NULL to both raw response columns; structured columns are still stored
+}
+
+// encodeRawResponse marshals a raw API response for storage. When compress is set the
+// JSON is gzipped and returned as the second value, leaving the JSONB value nil.
+func encodeRawResponse(raw map[string]interface{}, compress bool) (jsonValue []byte, gzipValue []byte, err error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal raw api response: %w", err)
+	}
+
+	if !compress {
+		return data, nil, nil
+	}
+
+	gzipValue, err = compressRawResponse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, gzipValue, nil
+}
+
+// decodeRawResponse reads a raw API response stored in either form. The compressed
+// column takes precedence; rows written before compression was enabled only have JSONB.
+func decodeRawResponse(jsonValue, gzipValue []byte, dst *map[string]interface{}) error {
+	data := jsonValue
+	if gzipValue != nil {
+		var err error
+		data, err = decompressRawResponse(gzipValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, dst)
+}
+
+// compressRawResponse gzips raw response JSON
+func compressRawResponse(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("compress raw api response: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress raw api response: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompressRawResponse reverses compressRawResponse
+func decompressRawResponse(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompress raw api response: %w", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decompress raw api response: %w", err)
+	}
+
+	return out, nil
+}
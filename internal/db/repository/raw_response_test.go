@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleRawResponse() map[string]interface{} {
+	return map[string]interface{}{
+		"kind": "youtube#video",
+		"id":   "video123",
+		"snippet": map[string]interface{}{
+			"title":       "Test Video",
+			"description": strings.Repeat("This video is sponsored by Example. ", 200),
+			"tags":        []interface{}{"a", "b", "c"},
+		},
+		"statistics": map[string]interface{}{
+			"viewCount": "1000",
+		},
+	}
+}
+
+func TestCompressRawResponse_RoundTrip(t *testing.T) {
+	original, err := json.Marshal(sampleRawResponse())
+	require.NoError(t, err)
+
+	compressed, err := compressRawResponse(original)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(original), "repetitive JSON should compress")
+
+	decompressed, err := decompressRawResponse(compressed)
+	require.NoError(t, err)
+	assert.Equal(t, original, decompressed)
+}
+
+func TestEncodeDecodeRawResponse(t *testing.T) {
+	raw := sampleRawResponse()
+
+	t.Run("compressed", func(t *testing.T) {
+		jsonValue, gzipValue, err := encodeRawResponse(raw, true)
+		require.NoError(t, err)
+		assert.Nil(t, jsonValue)
+		require.NotNil(t, gzipValue)
+
+		var decoded map[string]interface{}
+		require.NoError(t, decodeRawResponse(jsonValue, gzipValue, &decoded))
+		assert.Equal(t, raw, decoded)
+	})
+
+	t.Run("uncompressed", func(t *testing.T) {
+		jsonValue, gzipValue, err := encodeRawResponse(raw, false)
+		require.NoError(t, err)
+		assert.Nil(t, gzipValue)
+
+		var decoded map[string]interface{}
+		require.NoError(t, decodeRawResponse(jsonValue, gzipValue, &decoded))
+		assert.Equal(t, raw, decoded)
+	})
+
+	t.Run("neither column set", func(t *testing.T) {
+		var decoded map[string]interface{}
+		require.NoError(t, decodeRawResponse(nil, nil, &decoded))
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("corrupt compressed value", func(t *testing.T) {
+		var decoded map[string]interface{}
+		assert.Error(t, decodeRawResponse(nil, []byte("not gzip"), &decoded))
+	})
+}
+
+func TestEnrichmentRepository_CompressedRawResponse(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	ctx := context.Background()
+	td.TruncateTables(t)
+
+	channelRepo := NewChannelRepository(td.Pool)
+	videoRepo := NewVideoRepository(td.Pool)
+	require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel("UC123", "Test Channel", "https://youtube.com/channel/UC123")))
+	for _, id := range []string{"plain123", "gzip123"} {
+		video := models.NewVideo(id, "UC123", "Test Video", "https://youtube.com/watch?v="+id, time.Now())
+		require.NoError(t, videoRepo.UpsertVideo(ctx, video))
+	}
+
+	raw := sampleRawResponse()
+
+	// A row written before compression was enabled must stay readable
+	plainRepo := NewEnrichmentRepository(td.Pool)
+	require.NoError(t, plainRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "plain123", RawAPIResponse: raw}))
+
+	compressedRepo := NewEnrichmentRepositoryWithConfig(td.Pool, EnrichmentRepositoryConfig{CompressRawResponse: true})
+	require.NoError(t, compressedRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "gzip123", RawAPIResponse: raw}))
+
+	var jsonIsNull, gzipIsNull bool
+	err := td.Pool.QueryRow(ctx,
+		`SELECT raw_api_response IS NULL, raw_api_response_gzip IS NULL FROM video_api_enrichments WHERE video_id = 'gzip123'`,
+	).Scan(&jsonIsNull, &gzipIsNull)
+	require.NoError(t, err)
+	assert.True(t, jsonIsNull, "compressed rows should not also store uncompressed JSON")
+	assert.False(t, gzipIsNull)
+
+	for _, videoID := range []string{"plain123", "gzip123"} {
+		// Either repository reads both storage forms
+		for _, repo := range []EnrichmentRepository{plainRepo, compressedRepo} {
+			enrichment, err := repo.GetLatestEnrichment(ctx, videoID)
+			require.NoError(t, err)
+			assert.Equal(t, raw, enrichment.RawAPIResponse, videoID)
+		}
+	}
+}
+
+func TestEnrichmentRepository_DropRawResponse(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	ctx := context.Background()
+	td.TruncateTables(t)
+
+	channelRepo := NewChannelRepository(td.Pool)
+	videoRepo := NewVideoRepository(td.Pool)
+	require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel("UC123", "Test Channel", "https://youtube.com/channel/UC123")))
+	video := models.NewVideo("video123", "UC123", "Test Video", "https://youtube.com/watch?v=video123", time.Now())
+	require.NoError(t, videoRepo.UpsertVideo(ctx, video))
+
+	// Dropping wins over compression: neither column is written
+	repo := NewEnrichmentRepositoryWithConfig(td.Pool, EnrichmentRepositoryConfig{CompressRawResponse: true, DropRawResponse: true})
+	description := "structured fields are kept"
+	require.NoError(t, repo.CreateEnrichment(ctx, &model.VideoEnrichment{
+		VideoID:        "video123",
+		Description:    &description,
+		RawAPIResponse: sampleRawResponse(),
+	}))
+
+	var jsonIsNull, gzipIsNull bool
+	err := td.Pool.QueryRow(ctx,
+		`SELECT raw_api_response IS NULL, raw_api_response_gzip IS NULL FROM video_api_enrichments WHERE video_id = 'video123'`,
+	).Scan(&jsonIsNull, &gzipIsNull)
+	require.NoError(t, err)
+	assert.True(t, jsonIsNull)
+	assert.True(t, gzipIsNull)
+
+	enrichment, err := repo.GetLatestEnrichment(ctx, "video123")
+	require.NoError(t, err)
+	assert.Nil(t, enrichment.RawAPIResponse)
+	require.NotNil(t, enrichment.Description)
+	assert.Equal(t, description, *enrichment.Description)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "context"
+
+// rowsCheckInterval is how many rows the longer scan loops read between context checks
+const rowsCheckInterval = 100
+
+// checkRowsContext returns ctx's error on every rowsCheckInterval-th row (starting with the
+// first), so a loop over a large result set stops soon after its request is cancelled
+func checkRowsContext(ctx context.Context, row int) error {
+	if row%rowsCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckRowsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := checkRowsContext(ctx, 0); err != nil {
+		t.Fatalf("expected no error before cancellation, got %v", err)
+	}
+
+	cancel()
+
+	for _, row := range []int{0, rowsCheckInterval, 5 * rowsCheckInterval} {
+		if err := checkRowsContext(ctx, row); !errors.Is(err, context.Canceled) {
+			t.Errorf("row %d: expected context.Canceled, got %v", row, err)
+		}
+	}
+	for _, row := range []int{1, rowsCheckInterval - 1, rowsCheckInterval + 1} {
+		if err := checkRowsContext(ctx, row); err != nil {
+			t.Errorf("row %d: expected no check between intervals, got %v", row, err)
+		}
+	}
+
+	// A loop over a large result set stops within one interval of the cancellation
+	loopCtx, loopCancel := context.WithCancel(context.Background())
+	defer loopCancel()
+	scanned := 0
+	var err error
+	for row := 0; row < 1_000_000; row++ {
+		if err = checkRowsContext(loopCtx, row); err != nil {
+			break
+		}
+		scanned++
+		if scanned == 10 {
+			loopCancel()
+		}
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if scanned != rowsCheckInterval {
+		t.Errorf("expected the loop to stop at row %d, stopped after %d rows", rowsCheckInterval, scanned)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
@@ -16,6 +19,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrInvalidSponsorName is returned when a sponsor name is empty after normalization
+var ErrInvalidSponsorName = errors.New("sponsor name is empty after normalization")
+
 // SponsorDetectionRepository defines operations for managing sponsor detection data
 type SponsorDetectionRepository interface {
 	// Prompt operations
@@ -29,35 +35,100 @@ type SponsorDetectionRepository interface {
 	CreateSponsor(ctx context.Context, sponsor *models.Sponsor) error
 	UpdateSponsorLastSeen(ctx context.Context, sponsorID uuid.UUID, timestamp time.Time) error
 	IncrementSponsorVideoCount(ctx context.Context, sponsorID uuid.UUID) error
-	ListSponsors(ctx context.Context, sortBy string, order string, category string, limit, offset int) ([]*models.Sponsor, error)
+	ListSponsors(ctx context.Context, sortBy string, order string, category, format string, limit, offset int) ([]*models.Sponsor, error)
 	GetSponsorByID(ctx context.Context, sponsorID uuid.UUID) (*models.Sponsor, error)
+	CheckSponsorVideoCount(ctx context.Context, sponsorID uuid.UUID, fix bool) (*models.SponsorVideoCountCheck, error)
 
 	// Detection job operations
 	CreateDetectionJob(ctx context.Context, job *models.SponsorDetectionJob) error
 	UpdateDetectionJobStatus(ctx context.Context, jobID uuid.UUID, status string, errorMsg *string) error
+	UpdateDetectionJobModel(ctx context.Context, jobID uuid.UUID, llmModel string) error
 	CompleteDetectionJob(ctx context.Context, jobID uuid.UUID, promptID *uuid.UUID, llmResponse string, processingTimeMs, sponsorCount int) error
 	GetDetectionJobsByVideoID(ctx context.Context, videoID string) ([]*models.SponsorDetectionJob, error)
+	ListDetectionJobs(ctx context.Context, filters DetectionJobFilters) ([]*models.SponsorDetectionJob, int, error)
 	GetLatestDetectionJobForVideo(ctx context.Context, videoID string) (*models.SponsorDetectionJob, error)
 	GetDetectionJobByID(ctx context.Context, jobID uuid.UUID) (*models.SponsorDetectionJob, error)
+	GetVideosWithoutDetection(ctx context.Context, limit int) ([]*models.UndetectedVideo, error)
 
 	// Video-sponsor relationship operations
 	CreateVideoSponsor(ctx context.Context, videoSponsor *models.VideoSponsor) error
-	GetVideoSponsorsWithDetails(ctx context.Context, videoID string) ([]*models.VideoSponsorDetail, error)
-	GetSponsorVideos(ctx context.Context, sponsorID uuid.UUID, limit, offset int) ([]*models.VideoSponsor, error)
+	GetVideoSponsorsWithDetails(ctx context.Context, videoID string, filters VideoSponsorFilters) ([]*models.VideoSponsorDetail, error)
+	GetDistinctVideoSponsors(ctx context.Context, videoID string, filters VideoSponsorFilters) ([]*models.VideoSponsorDetail, error)
+	GetSponsorVideos(ctx context.Context, sponsorID uuid.UUID, limit, offset int) ([]*models.SponsorVideoDetail, error)
 	GetVideoSponsorsByJobID(ctx context.Context, jobID uuid.UUID) ([]*models.VideoSponsor, error)
+	GetRecentVideoSponsors(ctx context.Context, limit int) ([]*models.RecentSponsorDetection, error)
 	GetSponsorsByChannelID(ctx context.Context, channelID string, limit, offset int) ([]*models.Sponsor, error)
+	GetChannelSponsorTrend(ctx context.Context, channelID, interval, format string, since, until *time.Time) ([]*models.SponsorTrendBucket, error)
+	GetChannelSponsorReport(ctx context.Context, channelID string) ([]*models.ChannelSponsorReportEntry, error)
+	StreamChannelSponsorReport(ctx context.Context, channelID string, fn func(*models.ChannelSponsorReportEntry) error) error
+	GetConfidenceHistogram(ctx context.Context, buckets int, llmModel, format string, since, until *time.Time) ([]*models.ConfidenceBucket, error)
 
 	// Composite transaction operation
 	SaveDetectionResults(ctx context.Context, jobID uuid.UUID, videoID string, promptID *uuid.UUID, llmResults []models.LLMSponsorResult, llmRawResponse string, processingTimeMs int) error
+	ReapplyDetectionResults(ctx context.Context, jobID uuid.UUID, videoID string, llmResults []models.LLMSponsorResult) (int, error)
+}
+
+// VideoSponsorFilters narrows the sponsors returned for a video
+type VideoSponsorFilters struct {
+	MinConfidence float64 // only relationships with at least this confidence
+	Limit         int     // at most this many rows, highest confidence first (0 = no limit)
+}
+
+// DetectionJobFilters narrows and pages the detection jobs returned by ListDetectionJobs
+type DetectionJobFilters struct {
+	VideoID string // only jobs for this video (empty = all videos)
+	Status  string // only jobs with this status (empty = any status)
+	Limit   int
+	Offset  int
+}
+
+// DefaultMaxEvidenceLength is the default maximum number of characters stored in video_sponsors.evidence
+const DefaultMaxEvidenceLength = 500
+
+// SponsorDetectionRepositoryConfig holds optional settings for the sponsor detection repository
+type SponsorDetectionRepositoryConfig struct {
+	MaxEvidenceLength int // Maximum evidence length in characters (default: 500)
+
+	// CommitChunkSize splits SaveDetectionResults into transactions of at most this many
+	// sponsor results, followed by a final transaction that completes the job. This shortens
+	// how long sponsor row locks are held for videos with many sponsors, at the cost of
+	// atomicity: if a later chunk fails, earlier chunks stay committed while the job is not
+	// marked completed. Retrying is safe because video_sponsors inserts are idempotent.
+	// Zero (the default) saves everything in a single transaction.
+	CommitChunkSize int
+
+	// Denylist names sponsors that SaveDetectionResults and ReapplyDetectionResults never
+	// store; nil stores every detected sponsor. The list may be replaced while in use.
+	Denylist *models.SponsorDenylist
 }
 
 type sponsorDetectionRepository struct {
-	pool *pgxpool.Pool
+	pool              *pgxpool.Pool
+	maxEvidenceLength int
+	commitChunkSize   int
+	denylist          *models.SponsorDenylist
 }
 
 // NewSponsorDetectionRepository creates a new SponsorDetectionRepository
 func NewSponsorDetectionRepository(pool *pgxpool.Pool) SponsorDetectionRepository {
-	return &sponsorDetectionRepository{pool: pool}
+	return NewSponsorDetectionRepositoryWithConfig(pool, SponsorDetectionRepositoryConfig{})
+}
+
+// NewSponsorDetectionRepositoryWithConfig creates a new SponsorDetectionRepository with custom settings
+func NewSponsorDetectionRepositoryWithConfig(pool *pgxpool.Pool, config SponsorDetectionRepositoryConfig) SponsorDetectionRepository {
+	if config.MaxEvidenceLength <= 0 {
+		config.MaxEvidenceLength = DefaultMaxEvidenceLength
+	}
+	if config.CommitChunkSize < 0 {
+		config.CommitChunkSize = 0
+	}
+
+	return &sponsorDetectionRepository{
+		pool:              pool,
+		maxEvidenceLength: config.MaxEvidenceLength,
+		commitChunkSize:   config.CommitChunkSize,
+		denylist:          config.Denylist,
+	}
 }
 
 // GetOrCreatePrompt gets an existing prompt by hash or creates a new one
@@ -94,9 +165,17 @@ func (r *sponsorDetectionRepository) GetOrCreatePrompt(ctx context.Context, prom
 	}
 
 	// Prompt doesn't exist, create it
-	insertQuery := `
+	return r.insertPrompt(ctx, promptText, promptHash, version, description)
+}
+
+// insertPrompt inserts a prompt, or returns the existing row if a concurrent caller
+// inserted the same prompt hash after the lookup in GetOrCreatePrompt. The no-op
+// DO UPDATE makes RETURNING yield the existing row instead of a unique violation.
+func (r *sponsorDetectionRepository) insertPrompt(ctx context.Context, promptText, promptHash, version, description string) (*models.SponsorDetectionPrompt, error) {
+	query := `
 		INSERT INTO sponsor_detection_prompts (prompt_text, prompt_hash, version, description, usage_count, created_at)
 		VALUES ($1, $2, $3, $4, 0, NOW())
+		ON CONFLICT (prompt_hash) DO UPDATE SET prompt_hash = EXCLUDED.prompt_hash
 		RETURNING id, prompt_text, prompt_hash, version, description, usage_count, created_at
 	`
 
@@ -108,7 +187,8 @@ func (r *sponsorDetectionRepository) GetOrCreatePrompt(ctx context.Context, prom
 		descriptionPtr = &description
 	}
 
-	err = r.pool.QueryRow(ctx, insertQuery, promptText, promptHash, versionPtr, descriptionPtr).Scan(
+	var prompt models.SponsorDetectionPrompt
+	err := r.pool.QueryRow(ctx, query, promptText, promptHash, versionPtr, descriptionPtr).Scan(
 		&prompt.ID,
 		&prompt.PromptText,
 		&prompt.PromptHash,
@@ -242,8 +322,16 @@ func (r *sponsorDetectionRepository) GetSponsorByNormalizedName(ctx context.Cont
 	return &sponsor, nil
 }
 
-// CreateSponsor creates a new sponsor
+// CreateSponsor creates a new sponsor. The name is cleaned and normalized_name is derived
+// from it with models.NormalizeSponsorName, so any NormalizedName set by the caller is replaced.
 func (r *sponsorDetectionRepository) CreateSponsor(ctx context.Context, sponsor *models.Sponsor) error {
+	name := models.CleanSponsorName(sponsor.Name)
+	if name == "" {
+		return ErrInvalidSponsorName
+	}
+	sponsor.Name = name
+	sponsor.NormalizedName = strings.ToLower(name)
+
 	query := `
 		INSERT INTO sponsors (name, normalized_name, category, website_url, description,
 		                      first_seen_at, last_seen_at, video_count, created_at, updated_at)
@@ -315,8 +403,10 @@ func (r *sponsorDetectionRepository) IncrementSponsorVideoCount(ctx context.Cont
 	return nil
 }
 
-// ListSponsors retrieves sponsors with pagination, sorting, and optional category filter
-func (r *sponsorDetectionRepository) ListSponsors(ctx context.Context, sortBy string, order string, category string, limit, offset int) ([]*models.Sponsor, error) {
+// ListSponsors retrieves sponsors with pagination, sorting, and optional category and format filters.
+// When format is set only sponsors with videos of that format are returned, and video_count counts
+// only those videos.
+func (r *sponsorDetectionRepository) ListSponsors(ctx context.Context, sortBy string, order string, category, format string, limit, offset int) ([]*models.Sponsor, error) {
 	// Validate and build sort field
 	validSortFields := map[string]string{
 		"video_count": "video_count",
@@ -338,30 +428,50 @@ func (r *sponsorDetectionRepository) ListSponsors(ctx context.Context, sortBy st
 		orderDirection = "DESC"
 	}
 
-	// Build query with optional category filter
-	var query string
+	// Build query with optional category and format filters
+	var conditions []string
 	var args []interface{}
 	if category != "" {
-		query = fmt.Sprintf(`
-			SELECT id, name, normalized_name, category, website_url, description,
-			       first_seen_at, last_seen_at, video_count, created_at, updated_at
-			FROM sponsors
-			WHERE category = $1
-			ORDER BY %s %s
-			LIMIT $2 OFFSET $3
-		`, sortField, orderDirection)
-		args = []interface{}{category, limit, offset}
-	} else {
-		query = fmt.Sprintf(`
-			SELECT id, name, normalized_name, category, website_url, description,
-			       first_seen_at, last_seen_at, video_count, created_at, updated_at
-			FROM sponsors
-			ORDER BY %s %s
-			LIMIT $1 OFFSET $2
-		`, sortField, orderDirection)
-		args = []interface{}{limit, offset}
+		args = append(args, category)
+		conditions = append(conditions, fmt.Sprintf("s.category = $%d", len(args)))
 	}
 
+	videoCount := "s.video_count"
+	if format != "" {
+		args = append(args, format)
+		videoCount = fmt.Sprintf(`(
+			SELECT COUNT(DISTINCT vs.video_id)
+			FROM video_sponsors vs
+			JOIN video_formats f ON f.video_id = vs.video_id
+			WHERE vs.sponsor_id = s.id AND f.format = $%d
+		)`, len(args))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	outerWhere := ""
+	if format != "" {
+		outerWhere = "WHERE video_count > 0"
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT id, name, normalized_name, category, website_url, description,
+		       first_seen_at, last_seen_at, video_count, created_at, updated_at
+		FROM (
+			SELECT s.id, s.name, s.normalized_name, s.category, s.website_url, s.description,
+			       s.first_seen_at, s.last_seen_at, %s AS video_count, s.created_at, s.updated_at
+			FROM sponsors s
+			%s
+		) sponsors
+		%s
+		ORDER BY %s %s
+		LIMIT $%d OFFSET $%d
+	`, videoCount, where, outerWhere, sortField, orderDirection, len(args)-1, len(args))
+
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, db.WrapError(err, "list sponsors")
@@ -369,7 +479,11 @@ func (r *sponsorDetectionRepository) ListSponsors(ctx context.Context, sortBy st
 	defer rows.Close()
 
 	var sponsors []*models.Sponsor
-	for rows.Next() {
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return nil, err
+		}
+
 		var sponsor models.Sponsor
 		err := rows.Scan(
 			&sponsor.ID,
@@ -393,6 +507,51 @@ func (r *sponsorDetectionRepository) ListSponsors(ctx context.Context, sortBy st
 	return sponsors, nil
 }
 
+// CheckSponsorVideoCount compares a sponsor's stored video_count with the live count of
+// distinct videos in video_sponsors. When fix is true a discrepancy is corrected.
+// Returns nil if the sponsor does not exist.
+func (r *sponsorDetectionRepository) CheckSponsorVideoCount(ctx context.Context, sponsorID uuid.UUID, fix bool) (*models.SponsorVideoCountCheck, error) {
+	query := `
+		SELECT s.video_count,
+		       (SELECT COUNT(DISTINCT vs.video_id) FROM video_sponsors vs WHERE vs.sponsor_id = s.id)
+		FROM sponsors s
+		WHERE s.id = $1
+	`
+
+	check := &models.SponsorVideoCountCheck{SponsorID: sponsorID}
+	err := r.pool.QueryRow(ctx, query, sponsorID).Scan(&check.StoredCount, &check.ActualCount)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, db.WrapError(err, "check sponsor video count")
+	}
+
+	check.Consistent = check.StoredCount == check.ActualCount
+	if check.Consistent || !fix {
+		return check, nil
+	}
+
+	// Recount in the UPDATE itself so a detection saved since the check is not lost
+	fixQuery := `
+		UPDATE sponsors
+		SET video_count = (
+			SELECT COUNT(DISTINCT video_id)
+			FROM video_sponsors
+			WHERE sponsor_id = sponsors.id
+		), updated_at = NOW()
+		WHERE id = $1
+		RETURNING video_count
+	`
+
+	if err := r.pool.QueryRow(ctx, fixQuery, sponsorID).Scan(&check.ActualCount); err != nil {
+		return nil, db.WrapError(err, "fix sponsor video count")
+	}
+	check.Fixed = true
+
+	return check, nil
+}
+
 // GetSponsorByID retrieves a sponsor by ID
 func (r *sponsorDetectionRepository) GetSponsorByID(ctx context.Context, sponsorID uuid.UUID) (*models.Sponsor, error) {
 	query := `
@@ -471,6 +630,23 @@ func (r *sponsorDetectionRepository) UpdateDetectionJobStatus(ctx context.Contex
 	return nil
 }
 
+// UpdateDetectionJobModel records the LLM model a job is run with, for jobs whose task
+// overrides the model the job was created with
+func (r *sponsorDetectionRepository) UpdateDetectionJobModel(ctx context.Context, jobID uuid.UUID, llmModel string) error {
+	query := `
+		UPDATE sponsor_detection_jobs
+		SET llm_model = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err := r.pool.Exec(ctx, query, llmModel, jobID)
+	if err != nil {
+		return db.WrapError(err, "update detection job model")
+	}
+
+	return nil
+}
+
 // CompleteDetectionJob marks a job as completed with results
 func (r *sponsorDetectionRepository) CompleteDetectionJob(ctx context.Context, jobID uuid.UUID, promptID *uuid.UUID, llmResponse string, processingTimeMs, sponsorCount int) error {
 	query := `
@@ -536,6 +712,77 @@ func (r *sponsorDetectionRepository) GetDetectionJobsByVideoID(ctx context.Conte
 	return jobs, nil
 }
 
+// ListDetectionJobs retrieves a page of detection jobs, newest first, together with the
+// total number of jobs matching the filters
+func (r *sponsorDetectionRepository) ListDetectionJobs(ctx context.Context, filters DetectionJobFilters) ([]*models.SponsorDetectionJob, int, error) {
+	var conditions []string
+	var args []interface{}
+	if filters.VideoID != "" {
+		args = append(args, filters.VideoID)
+		conditions = append(conditions, fmt.Sprintf("video_id = $%d", len(args)))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM sponsor_detection_jobs " + whereClause
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, db.WrapError(err, "count detection jobs")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, video_id, prompt_id, llm_model, llm_response_raw,
+		       sponsors_detected_count, processing_time_ms, status, error_message,
+		       detected_at, created_at, updated_at
+		FROM sponsor_detection_jobs
+		%s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, filters.Limit, filters.Offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, db.WrapError(err, "list detection jobs")
+	}
+	defer rows.Close()
+
+	jobs := []*models.SponsorDetectionJob{}
+	for rows.Next() {
+		var job models.SponsorDetectionJob
+		err := rows.Scan(
+			&job.ID,
+			&job.VideoID,
+			&job.PromptID,
+			&job.LLMModel,
+			&job.LLMResponseRaw,
+			&job.SponsorsDetectedCount,
+			&job.ProcessingTimeMs,
+			&job.Status,
+			&job.ErrorMessage,
+			&job.DetectedAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, db.WrapError(err, "scan detection job")
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, db.WrapError(err, "iterate detection jobs")
+	}
+
+	return jobs, total, nil
+}
+
 // GetLatestDetectionJobForVideo retrieves the most recent detection job for a video
 func (r *sponsorDetectionRepository) GetLatestDetectionJobForVideo(ctx context.Context, videoID string) (*models.SponsorDetectionJob, error) {
 	query := `
@@ -612,6 +859,51 @@ func (r *sponsorDetectionRepository) GetDetectionJobByID(ctx context.Context, jo
 	return &job, nil
 }
 
+// GetVideosWithoutDetection returns enriched videos whose latest enrichment has a description
+// but that have never had a sponsor detection job, most recently enriched first.
+func (r *sponsorDetectionRepository) GetVideosWithoutDetection(ctx context.Context, limit int) ([]*models.UndetectedVideo, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT video_id, title, description
+		FROM (
+			SELECT DISTINCT ON (e.video_id) e.video_id, v.title, e.description, e.enriched_at
+			FROM video_api_enrichments e
+			JOIN videos v ON v.video_id = e.video_id
+			WHERE NOT EXISTS (
+				SELECT 1 FROM sponsor_detection_jobs j WHERE j.video_id = e.video_id
+			)
+			ORDER BY e.video_id, e.enriched_at DESC
+		) latest
+		WHERE description IS NOT NULL AND description <> ''
+		ORDER BY enriched_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, db.WrapError(err, "get videos without detection")
+	}
+	defer rows.Close()
+
+	var videos []*models.UndetectedVideo
+	for rows.Next() {
+		var v models.UndetectedVideo
+		if err := rows.Scan(&v.VideoID, &v.Title, &v.Description); err != nil {
+			return nil, db.WrapError(err, "scan video without detection")
+		}
+		videos = append(videos, &v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "iterate videos without detection")
+	}
+
+	return videos, nil
+}
+
 // CreateVideoSponsor creates a video-sponsor relationship
 func (r *sponsorDetectionRepository) CreateVideoSponsor(ctx context.Context, videoSponsor *models.VideoSponsor) error {
 	query := `
@@ -646,21 +938,53 @@ func (r *sponsorDetectionRepository) CreateVideoSponsor(ctx context.Context, vid
 	return nil
 }
 
-// GetVideoSponsorsWithDetails retrieves all sponsors for a video with sponsor details (JOIN)
-func (r *sponsorDetectionRepository) GetVideoSponsorsWithDetails(ctx context.Context, videoID string) ([]*models.VideoSponsorDetail, error) {
+// GetVideoSponsorsWithDetails retrieves the sponsors for a video with sponsor details (JOIN),
+// highest confidence first
+func (r *sponsorDetectionRepository) GetVideoSponsorsWithDetails(ctx context.Context, videoID string, filters VideoSponsorFilters) ([]*models.VideoSponsorDetail, error) {
 	query := `
 		SELECT vs.id, vs.video_id, vs.sponsor_id, vs.detection_job_id,
 		       vs.confidence, vs.evidence, vs.detected_at, vs.created_at, vs.updated_at,
 		       s.name AS sponsor_name, s.category AS sponsor_category
 		FROM video_sponsors vs
 		JOIN sponsors s ON vs.sponsor_id = s.id
-		WHERE vs.video_id = $1
+		WHERE vs.video_id = $1 AND vs.confidence >= $2
 		ORDER BY vs.confidence DESC, vs.detected_at DESC
+		LIMIT NULLIF($3, 0)
 	`
 
-	rows, err := r.pool.Query(ctx, query, videoID)
+	return r.queryVideoSponsorDetails(ctx, "get video sponsors with details", query, videoID, filters.MinConfidence, filters.Limit)
+}
+
+// GetDistinctVideoSponsors retrieves one relationship per sponsor for a video. Re-running
+// detection adds rows for the same video and sponsor under each new job; of those, the row
+// with the highest confidence is returned, the most recent one on ties.
+func (r *sponsorDetectionRepository) GetDistinctVideoSponsors(ctx context.Context, videoID string, filters VideoSponsorFilters) ([]*models.VideoSponsorDetail, error) {
+	query := `
+		SELECT id, video_id, sponsor_id, detection_job_id,
+		       confidence, evidence, detected_at, created_at, updated_at,
+		       sponsor_name, sponsor_category
+		FROM (
+			SELECT DISTINCT ON (vs.sponsor_id)
+			       vs.id, vs.video_id, vs.sponsor_id, vs.detection_job_id,
+			       vs.confidence, vs.evidence, vs.detected_at, vs.created_at, vs.updated_at,
+			       s.name AS sponsor_name, s.category AS sponsor_category
+			FROM video_sponsors vs
+			JOIN sponsors s ON vs.sponsor_id = s.id
+			WHERE vs.video_id = $1 AND vs.confidence >= $2
+			ORDER BY vs.sponsor_id, vs.confidence DESC, vs.detected_at DESC
+		) distinct_sponsors
+		ORDER BY confidence DESC, detected_at DESC
+		LIMIT NULLIF($3, 0)
+	`
+
+	return r.queryVideoSponsorDetails(ctx, "get distinct video sponsors", query, videoID, filters.MinConfidence, filters.Limit)
+}
+
+// queryVideoSponsorDetails runs a query selecting video sponsor detail columns and scans the rows
+func (r *sponsorDetectionRepository) queryVideoSponsorDetails(ctx context.Context, op, query string, args ...interface{}) ([]*models.VideoSponsorDetail, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, db.WrapError(err, "get video sponsors with details")
+		return nil, db.WrapError(err, op)
 	}
 	defer rows.Close()
 
@@ -689,14 +1013,75 @@ func (r *sponsorDetectionRepository) GetVideoSponsorsWithDetails(ctx context.Con
 	return details, nil
 }
 
-// GetSponsorVideos retrieves all videos for a sponsor
-func (r *sponsorDetectionRepository) GetSponsorVideos(ctx context.Context, sponsorID uuid.UUID, limit, offset int) ([]*models.VideoSponsor, error) {
+// GetRecentVideoSponsors retrieves the most recently detected video-sponsor relationships
+// across all videos, with sponsor and video details (JOIN)
+func (r *sponsorDetectionRepository) GetRecentVideoSponsors(ctx context.Context, limit int) ([]*models.RecentSponsorDetection, error) {
 	query := `
-		SELECT id, video_id, sponsor_id, detection_job_id, confidence, evidence,
-		       detected_at, created_at, updated_at
-		FROM video_sponsors
-		WHERE sponsor_id = $1
-		ORDER BY detected_at DESC
+		SELECT vs.id, vs.video_id, vs.sponsor_id, vs.detection_job_id,
+		       vs.confidence, vs.evidence, vs.detected_at, vs.created_at, vs.updated_at,
+		       s.name AS sponsor_name, s.category AS sponsor_category,
+		       v.title AS video_title, v.channel_id AS video_channel_id,
+		       COALESCE(NULLIF(c.title, ''), v.channel_id) AS channel_title
+		FROM video_sponsors vs
+		JOIN sponsors s ON vs.sponsor_id = s.id
+		JOIN videos v ON vs.video_id = v.video_id
+		LEFT JOIN channels c ON v.channel_id = c.channel_id
+		ORDER BY vs.detected_at DESC, vs.created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, db.WrapError(err, "get recent video sponsors")
+	}
+	defer rows.Close()
+
+	var detections []*models.RecentSponsorDetection
+	for rows.Next() {
+		var detection models.RecentSponsorDetection
+		err := rows.Scan(
+			&detection.ID,
+			&detection.VideoID,
+			&detection.SponsorID,
+			&detection.DetectionJobID,
+			&detection.Confidence,
+			&detection.Evidence,
+			&detection.DetectedAt,
+			&detection.CreatedAt,
+			&detection.UpdatedAt,
+			&detection.SponsorName,
+			&detection.SponsorCategory,
+			&detection.VideoTitle,
+			&detection.VideoChannelID,
+			&detection.ChannelTitle,
+		)
+		if err != nil {
+			return nil, db.WrapError(err, "scan recent sponsor detection")
+		}
+		detections = append(detections, &detection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "iterate recent sponsor detections")
+	}
+
+	return detections, nil
+}
+
+// GetSponsorVideos retrieves a page of a sponsor's videos with their video details in one query.
+// The LEFT JOIN keeps relationships whose video row is missing; their video fields are nil.
+// A channel without a title is shown by its channel ID.
+func (r *sponsorDetectionRepository) GetSponsorVideos(ctx context.Context, sponsorID uuid.UUID, limit, offset int) ([]*models.SponsorVideoDetail, error) {
+	query := `
+		SELECT vs.id, vs.video_id, vs.sponsor_id, vs.detection_job_id, vs.confidence, vs.evidence,
+		       vs.detected_at, vs.created_at, vs.updated_at,
+		       v.title AS video_title, v.video_url, v.channel_id AS video_channel_id,
+		       v.published_at AS video_published_at,
+		       COALESCE(NULLIF(c.title, ''), v.channel_id) AS channel_title
+		FROM video_sponsors vs
+		LEFT JOIN videos v ON vs.video_id = v.video_id
+		LEFT JOIN channels c ON v.channel_id = c.channel_id
+		WHERE vs.sponsor_id = $1
+		ORDER BY vs.detected_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
@@ -706,9 +1091,9 @@ func (r *sponsorDetectionRepository) GetSponsorVideos(ctx context.Context, spons
 	}
 	defer rows.Close()
 
-	var videoSponsors []*models.VideoSponsor
+	var videoSponsors []*models.SponsorVideoDetail
 	for rows.Next() {
-		var vs models.VideoSponsor
+		var vs models.SponsorVideoDetail
 		err := rows.Scan(
 			&vs.ID,
 			&vs.VideoID,
@@ -719,9 +1104,14 @@ func (r *sponsorDetectionRepository) GetSponsorVideos(ctx context.Context, spons
 			&vs.DetectedAt,
 			&vs.CreatedAt,
 			&vs.UpdatedAt,
+			&vs.VideoTitle,
+			&vs.VideoURL,
+			&vs.VideoChannelID,
+			&vs.VideoPublishedAt,
+			&vs.ChannelTitle,
 		)
 		if err != nil {
-			return nil, db.WrapError(err, "scan video sponsor")
+			return nil, db.WrapError(err, "scan sponsor video")
 		}
 		videoSponsors = append(videoSponsors, &vs)
 	}
@@ -768,7 +1158,8 @@ func (r *sponsorDetectionRepository) GetVideoSponsorsByJobID(ctx context.Context
 	return videoSponsors, nil
 }
 
-// SaveDetectionResults atomically saves all detection results in a transaction
+// SaveDetectionResults saves all detection results and marks the job completed.
+// By default this happens in a single transaction; see SponsorDetectionRepositoryConfig.CommitChunkSize.
 func (r *sponsorDetectionRepository) SaveDetectionResults(
 	ctx context.Context,
 	jobID uuid.UUID,
@@ -778,20 +1169,206 @@ func (r *sponsorDetectionRepository) SaveDetectionResults(
 	llmRawResponse string,
 	processingTimeMs int,
 ) error {
-	// Start transaction
-	tx, err := r.pool.Begin(ctx)
+	now := time.Now()
+	llmResults = r.withoutDenylisted(videoID, llmResults)
+	sponsorCount := len(llmResults)
+
+	if r.commitChunkSize > 0 && len(llmResults) > r.commitChunkSize {
+		if err := r.saveVideoSponsorsInChunks(ctx, jobID, videoID, llmResults, now); err != nil {
+			return err
+		}
+		// Sponsors are committed; the final transaction only completes the job
+		llmResults = nil
+	}
+
+	return db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		// Process each LLM result
+		if err := r.saveVideoSponsorsInTx(ctx, tx, jobID, videoID, llmResults, now); err != nil {
+			return err
+		}
+
+		// Update detection job as completed
+		updateJobQuery := `
+			UPDATE sponsor_detection_jobs
+			SET status = 'completed',
+			    prompt_id = $1,
+			    llm_response_raw = $2,
+			    processing_time_ms = $3,
+			    sponsors_detected_count = $4,
+			    detected_at = $5,
+			    updated_at = NOW()
+			WHERE id = $6
+		`
+
+		_, err := tx.Exec(ctx, updateJobQuery,
+			promptID,
+			llmRawResponse,
+			processingTimeMs,
+			sponsorCount,
+			now,
+			jobID,
+		)
+		if err != nil {
+			return db.WrapError(err, "update detection job in transaction")
+		}
+
+		// Increment prompt usage count if promptID is provided
+		if promptID != nil {
+			incrementPromptQuery := `
+				UPDATE sponsor_detection_prompts
+				SET usage_count = usage_count + 1
+				WHERE id = $1
+			`
+
+			if _, err := tx.Exec(ctx, incrementPromptQuery, *promptID); err != nil {
+				return db.WrapError(err, "increment prompt usage in transaction")
+			}
+		}
+
+		return nil
+	})
+}
+
+// saveVideoSponsorsInChunks saves the results in independent transactions of commitChunkSize results each
+func (r *sponsorDetectionRepository) saveVideoSponsorsInChunks(
+	ctx context.Context,
+	jobID uuid.UUID,
+	videoID string,
+	llmResults []models.LLMSponsorResult,
+	now time.Time,
+) error {
+	for start := 0; start < len(llmResults); start += r.commitChunkSize {
+		end := start + r.commitChunkSize
+		if end > len(llmResults) {
+			end = len(llmResults)
+		}
+
+		err := db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+			return r.saveVideoSponsorsInTx(ctx, tx, jobID, videoID, llmResults[start:end], now)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReapplyDetectionResults replaces a job's video_sponsors rows with the given results in a transaction.
+// It returns the number of results stored, which excludes denylisted sponsors.
+func (r *sponsorDetectionRepository) ReapplyDetectionResults(
+	ctx context.Context,
+	jobID uuid.UUID,
+	videoID string,
+	llmResults []models.LLMSponsorResult,
+) (int, error) {
+	llmResults = r.withoutDenylisted(videoID, llmResults)
+
+	err := db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		// Remove the job's existing relationships, remembering which sponsors were affected
+		deleteQuery := `
+			DELETE FROM video_sponsors
+			WHERE detection_job_id = $1
+			RETURNING sponsor_id
+		`
+
+		rows, err := tx.Query(ctx, deleteQuery, jobID)
+		if err != nil {
+			return db.WrapError(err, "delete video sponsors in transaction")
+		}
+
+		var removedSponsorIDs []uuid.UUID
+		for rows.Next() {
+			var sponsorID uuid.UUID
+			if err := rows.Scan(&sponsorID); err != nil {
+				rows.Close()
+				return db.WrapError(err, "scan removed sponsor id")
+			}
+			removedSponsorIDs = append(removedSponsorIDs, sponsorID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return db.WrapError(err, "delete video sponsors in transaction")
+		}
+
+		if err := r.saveVideoSponsorsInTx(ctx, tx, jobID, videoID, llmResults, time.Now()); err != nil {
+			return err
+		}
+
+		// Sponsors that were dropped from this job may now be linked to fewer videos
+		if len(removedSponsorIDs) > 0 {
+			recountQuery := `
+				UPDATE sponsors
+				SET video_count = (
+					SELECT COUNT(DISTINCT video_id)
+					FROM video_sponsors
+					WHERE sponsor_id = sponsors.id
+				)
+				WHERE id = ANY($1)
+			`
+
+			_, err := tx.Exec(ctx, recountQuery, removedSponsorIDs)
+			if err != nil {
+				return db.WrapError(err, "recount sponsor video counts in transaction")
+			}
+		}
+
+		updateJobQuery := `
+			UPDATE sponsor_detection_jobs
+			SET sponsors_detected_count = $1,
+			    updated_at = NOW()
+			WHERE id = $2
+		`
+
+		if _, err := tx.Exec(ctx, updateJobQuery, len(llmResults), jobID); err != nil {
+			return db.WrapError(err, "update detection job in transaction")
+		}
+
+		return nil
+	})
 	if err != nil {
-		return db.WrapError(err, "begin transaction")
+		return 0, err
 	}
-	defer tx.Rollback(ctx)
 
-	now := time.Now()
-	sponsorCount := len(llmResults)
+	return len(llmResults), nil
+}
 
-	// Process each LLM result
+// withoutDenylisted returns the results whose sponsor name is not denylisted, logging each one skipped
+func (r *sponsorDetectionRepository) withoutDenylisted(videoID string, llmResults []models.LLMSponsorResult) []models.LLMSponsorResult {
+	if r.denylist.Len() == 0 {
+		return llmResults
+	}
+
+	kept := make([]models.LLMSponsorResult, 0, len(llmResults))
 	for _, result := range llmResults {
-		// Normalize sponsor name (lowercase for matching)
-		normalizedName := strings.ToLower(strings.TrimSpace(result.Name))
+		if r.denylist.Contains(result.Name) {
+			log.Printf("[SponsorDetection] Skipping denylisted sponsor %q for video %s", result.Name, videoID)
+			continue
+		}
+		kept = append(kept, result)
+	}
+	return kept
+}
+
+// saveVideoSponsorsInTx upserts sponsors and links them to the video for a detection job
+func (r *sponsorDetectionRepository) saveVideoSponsorsInTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	jobID uuid.UUID,
+	videoID string,
+	llmResults []models.LLMSponsorResult,
+	now time.Time,
+) error {
+	var err error
+
+	for _, result := range llmResults {
+		// Clean the raw LLM name for display and lowercase it for matching;
+		// names that are nothing but punctuation or emojis are dropped
+		name := models.CleanSponsorName(result.Name)
+		if name == "" {
+			continue
+		}
+		normalizedName := strings.ToLower(name)
 
 		// Get or create sponsor
 		var sponsorID uuid.UUID
@@ -826,7 +1403,7 @@ func (r *sponsorDetectionRepository) SaveDetectionResults(
 				RETURNING id
 			`
 
-			err = tx.QueryRow(ctx, createSponsorQuery, result.Name, normalizedName, now, now).Scan(&sponsorID)
+			err = tx.QueryRow(ctx, createSponsorQuery, name, normalizedName, now, now).Scan(&sponsorID)
 			if err != nil {
 				return db.WrapError(err, "create sponsor in transaction")
 			}
@@ -860,7 +1437,7 @@ func (r *sponsorDetectionRepository) SaveDetectionResults(
 			sponsorID,
 			jobID,
 			result.Confidence,
-			result.Evidence,
+			truncateEvidence(result.Evidence, r.maxEvidenceLength),
 			now,
 		)
 
@@ -887,53 +1464,20 @@ func (r *sponsorDetectionRepository) SaveDetectionResults(
 		}
 	}
 
-	// Update detection job as completed
-	updateJobQuery := `
-		UPDATE sponsor_detection_jobs
-		SET status = 'completed',
-		    prompt_id = $1,
-		    llm_response_raw = $2,
-		    processing_time_ms = $3,
-		    sponsors_detected_count = $4,
-		    detected_at = $5,
-		    updated_at = NOW()
-		WHERE id = $6
-	`
-
-	_, err = tx.Exec(ctx, updateJobQuery,
-		promptID,
-		llmRawResponse,
-		processingTimeMs,
-		sponsorCount,
-		now,
-		jobID,
-	)
-
-	if err != nil {
-		return db.WrapError(err, "update detection job in transaction")
-	}
-
-	// Increment prompt usage count if promptID is provided
-	if promptID != nil {
-		incrementPromptQuery := `
-			UPDATE sponsor_detection_prompts
-			SET usage_count = usage_count + 1
-			WHERE id = $1
-		`
+	return nil
+}
 
-		_, err = tx.Exec(ctx, incrementPromptQuery, *promptID)
-		if err != nil {
-			return db.WrapError(err, "increment prompt usage in transaction")
-		}
-	}
+// truncateEvidence makes evidence valid UTF-8 and caps it at maxLength characters, adding an ellipsis when cut
+func truncateEvidence(evidence string, maxLength int) string {
+	evidence = strings.ToValidUTF8(evidence, "")
 
-	// Commit transaction
-	err = tx.Commit(ctx)
-	if err != nil {
-		return db.WrapError(err, "commit transaction")
+	if maxLength <= 0 || utf8.RuneCountInString(evidence) <= maxLength {
+		return evidence
 	}
 
-	return nil
+	const ellipsis = "…"
+	runes := []rune(evidence)
+	return string(runes[:maxLength-1]) + ellipsis
 }
 
 // GetSponsorsByChannelID retrieves all sponsors that appear in videos from a specific channel
@@ -979,3 +1523,167 @@ func (r *sponsorDetectionRepository) GetSponsorsByChannelID(ctx context.Context,
 
 	return sponsors, nil
 }
+
+// GetChannelSponsorReport aggregates every sponsor seen on a channel's videos with the number of
+// videos it sponsored and the publish dates of the first and last of them, most frequent first.
+func (r *sponsorDetectionRepository) GetChannelSponsorReport(ctx context.Context, channelID string) ([]*models.ChannelSponsorReportEntry, error) {
+	var entries []*models.ChannelSponsorReportEntry
+	err := r.StreamChannelSponsorReport(ctx, channelID, func(entry *models.ChannelSponsorReportEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// StreamChannelSponsorReport calls fn for each entry of the channel sponsor report as it is read,
+// in the same order as GetChannelSponsorReport, without holding the whole report in memory.
+// An error returned by fn stops the iteration and is returned as is.
+func (r *sponsorDetectionRepository) StreamChannelSponsorReport(ctx context.Context, channelID string, fn func(*models.ChannelSponsorReportEntry) error) error {
+	query := `
+		SELECT s.id, s.name, s.category,
+		       COUNT(DISTINCT vs.video_id) AS video_count,
+		       MIN(v.published_at) AS first_seen_at,
+		       MAX(v.published_at) AS last_seen_at
+		FROM video_sponsors vs
+		JOIN videos v ON vs.video_id = v.video_id
+		JOIN sponsors s ON vs.sponsor_id = s.id
+		WHERE v.channel_id = $1
+		GROUP BY s.id, s.name, s.category
+		ORDER BY video_count DESC, s.name ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, channelID)
+	if err != nil {
+		return db.WrapError(err, "get channel sponsor report")
+	}
+	defer rows.Close()
+
+	for row := 0; rows.Next(); row++ {
+		if err := checkRowsContext(ctx, row); err != nil {
+			return err
+		}
+
+		var entry models.ChannelSponsorReportEntry
+		err := rows.Scan(
+			&entry.SponsorID,
+			&entry.SponsorName,
+			&entry.SponsorCategory,
+			&entry.VideoCount,
+			&entry.FirstSeenAt,
+			&entry.LastSeenAt,
+		)
+		if err != nil {
+			return db.WrapError(err, "scan channel sponsor report entry")
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return db.WrapError(err, "iterate channel sponsor report")
+	}
+
+	return nil
+}
+
+// GetChannelSponsorTrend counts distinct sponsors and sponsored videos for a channel, bucketed by
+// video publish date. interval is a date_trunc unit such as "month" or "week"; buckets are in UTC.
+// format limits the count to Shorts or long-form videos when non-empty.
+func (r *sponsorDetectionRepository) GetChannelSponsorTrend(ctx context.Context, channelID, interval, format string, since, until *time.Time) ([]*models.SponsorTrendBucket, error) {
+	query := `
+		SELECT date_trunc($2, v.published_at AT TIME ZONE 'UTC') AS period,
+		       COUNT(DISTINCT vs.sponsor_id) AS sponsor_count,
+		       COUNT(DISTINCT vs.video_id) AS sponsored_video_count
+		FROM video_sponsors vs
+		JOIN videos v ON vs.video_id = v.video_id
+		WHERE v.channel_id = $1
+		  AND ($3::timestamptz IS NULL OR v.published_at >= $3)
+		  AND ($4::timestamptz IS NULL OR v.published_at < $4)
+		  AND ($5 = '' OR EXISTS (
+		      SELECT 1 FROM video_formats f WHERE f.video_id = vs.video_id AND f.format = $5
+		  ))
+		GROUP BY period
+		ORDER BY period ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, channelID, interval, since, until, format)
+	if err != nil {
+		return nil, db.WrapError(err, "get channel sponsor trend")
+	}
+	defer rows.Close()
+
+	var buckets []*models.SponsorTrendBucket
+	for rows.Next() {
+		var bucket models.SponsorTrendBucket
+		err := rows.Scan(
+			&bucket.Period,
+			&bucket.SponsorCount,
+			&bucket.SponsoredVideoCount,
+		)
+		if err != nil {
+			return nil, db.WrapError(err, "scan sponsor trend bucket")
+		}
+		bucket.Period = bucket.Period.UTC()
+		buckets = append(buckets, &bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "iterate sponsor trend buckets")
+	}
+
+	return buckets, nil
+}
+
+// GetConfidenceHistogram counts video sponsor detections in equal-width confidence buckets
+// spanning 0 to 1. Every bucket is returned, including empty ones. llmModel filters by the
+// model of the detection job when non-empty; since and until bound the detection time; format
+// limits detections to Shorts or long-form videos when non-empty.
+func (r *sponsorDetectionRepository) GetConfidenceHistogram(ctx context.Context, buckets int, llmModel, format string, since, until *time.Time) ([]*models.ConfidenceBucket, error) {
+	// width_bucket puts a confidence of exactly 1 in bucket n+1, so it is folded into the last bucket
+	query := `
+		SELECT LEAST(width_bucket(vs.confidence, 0, 1, $1), $1) AS bucket,
+		       COUNT(*) AS detection_count
+		FROM video_sponsors vs
+		JOIN sponsor_detection_jobs j ON vs.detection_job_id = j.id
+		WHERE ($2 = '' OR j.llm_model = $2)
+		  AND ($3::timestamptz IS NULL OR vs.detected_at >= $3)
+		  AND ($4::timestamptz IS NULL OR vs.detected_at < $4)
+		  AND ($5 = '' OR EXISTS (
+		      SELECT 1 FROM video_formats f WHERE f.video_id = vs.video_id AND f.format = $5
+		  ))
+		GROUP BY bucket
+	`
+
+	rows, err := r.pool.Query(ctx, query, buckets, llmModel, since, until, format)
+	if err != nil {
+		return nil, db.WrapError(err, "get confidence histogram")
+	}
+	defer rows.Close()
+
+	histogram := make([]*models.ConfidenceBucket, buckets)
+	for i := range histogram {
+		histogram[i] = &models.ConfidenceBucket{
+			Min: float64(i) / float64(buckets),
+			Max: float64(i+1) / float64(buckets),
+		}
+	}
+
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, db.WrapError(err, "scan confidence bucket")
+		}
+		if bucket >= 1 && bucket <= buckets {
+			histogram[bucket-1].Count = count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, db.WrapError(err, "iterate confidence buckets")
+	}
+
+	return histogram, nil
+}
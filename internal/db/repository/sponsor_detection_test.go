@@ -0,0 +1,899 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createSponsorTestVideo creates a channel and video and returns a pending detection job for the video
+func createSponsorTestVideo(t *testing.T, ctx context.Context, td *testutil.TestDatabase, repo SponsorDetectionRepository, channelID, videoID string, publishedAt time.Time) *models.SponsorDetectionJob {
+	t.Helper()
+
+	channel := models.NewChannel(channelID, "Test Channel", "https://youtube.com/channel/"+channelID)
+	require.NoError(t, NewChannelRepository(td.Pool).UpsertChannel(ctx, channel))
+
+	video := models.NewVideo(videoID, channelID, "Test Video", "https://youtube.com/watch?v="+videoID, publishedAt)
+	require.NoError(t, NewVideoRepository(td.Pool).UpsertVideo(ctx, video))
+
+	job := &models.SponsorDetectionJob{
+		VideoID:  videoID,
+		LLMModel: "test-model",
+		Status:   "pending",
+	}
+	require.NoError(t, repo.CreateDetectionJob(ctx, job))
+
+	return job
+}
+
+func TestSponsorDetectionRepository_GetOrCreatePrompt(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	t.Run("insert conflict returns the existing prompt", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		existing, err := repo.GetOrCreatePrompt(ctx, "Find sponsors in this video", "v1", "first")
+		require.NoError(t, err)
+
+		// Simulate losing the race: the lookup missed, but another caller has
+		// inserted the same prompt hash before this insert runs
+		sdr := repo.(*sponsorDetectionRepository)
+		prompt, err := sdr.insertPrompt(ctx, "Find sponsors in this video", existing.PromptHash, "v2", "second")
+		require.NoError(t, err)
+
+		assert.Equal(t, existing.ID, prompt.ID)
+		require.NotNil(t, prompt.Version)
+		assert.Equal(t, "v1", *prompt.Version)
+	})
+
+	t.Run("concurrent callers get the same prompt", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		const callers = 10
+		ids := make(chan string, callers)
+		errs := make(chan error, callers)
+
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				prompt, err := repo.GetOrCreatePrompt(ctx, "Concurrent prompt", "v1", "")
+				if err != nil {
+					errs <- err
+					return
+				}
+				ids <- prompt.ID.String()
+			}()
+		}
+		wg.Wait()
+		close(ids)
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		var first string
+		for id := range ids {
+			if first == "" {
+				first = id
+			}
+			assert.Equal(t, first, id)
+		}
+	})
+}
+
+func TestSponsorDetectionRepository_SaveDetectionResults(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	t.Run("truncates long evidence and keeps it valid UTF-8", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		job := createSponsorTestVideo(t, ctx, td, repo, "UC123", "video123", time.Now())
+
+		// Multi-byte characters make sure truncation happens on rune boundaries
+		longEvidence := strings.Repeat("é", 5000)
+		results := []models.LLMSponsorResult{
+			{Name: "NordVPN", Confidence: 0.9, Evidence: longEvidence},
+		}
+
+		err := repo.SaveDetectionResults(ctx, job.ID, "video123", nil, results, `{"sponsors":[]}`, 100)
+		require.NoError(t, err)
+
+		videoSponsors, err := repo.GetVideoSponsorsByJobID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Len(t, videoSponsors, 1)
+
+		evidence := videoSponsors[0].Evidence
+		assert.True(t, utf8.ValidString(evidence))
+		assert.Equal(t, DefaultMaxEvidenceLength, utf8.RuneCountInString(evidence))
+		assert.True(t, strings.HasSuffix(evidence, "…"))
+	})
+
+	t.Run("normalized names match existing sponsors", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		existing := &models.Sponsor{Name: "NordVPN"}
+		require.NoError(t, repo.CreateSponsor(ctx, existing))
+		assert.Equal(t, "nordvpn", existing.NormalizedName)
+
+		job := createSponsorTestVideo(t, ctx, td, repo, "UC123", "video123", time.Now())
+		results := []models.LLMSponsorResult{
+			{Name: "NordVPN (Sponsor)  ", Confidence: 0.9, Evidence: "sponsored by NordVPN"},
+			{Name: " 🎉 ", Confidence: 0.5, Evidence: "just an emoji"},
+		}
+
+		err := repo.SaveDetectionResults(ctx, job.ID, "video123", nil, results, `{"sponsors":[]}`, 100)
+		require.NoError(t, err)
+
+		videoSponsors, err := repo.GetVideoSponsorsByJobID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Len(t, videoSponsors, 1)
+		assert.Equal(t, existing.ID, videoSponsors[0].SponsorID)
+	})
+
+	t.Run("denylisted sponsors are not stored", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		denylistRepo := NewSponsorDetectionRepositoryWithConfig(td.Pool, SponsorDetectionRepositoryConfig{
+			Denylist: models.NewSponsorDenylist([]string{"YouTube", "Patreon"}),
+		})
+
+		job := createSponsorTestVideo(t, ctx, td, denylistRepo, "UC123", "video123", time.Now())
+		results := []models.LLMSponsorResult{
+			{Name: "NordVPN", Confidence: 0.9, Evidence: "sponsored by NordVPN"},
+			{Name: "patreon (sponsor)", Confidence: 0.7, Evidence: "support me on Patreon"},
+			{Name: "YouTube", Confidence: 0.6, Evidence: "subscribe on YouTube"},
+		}
+
+		err := denylistRepo.SaveDetectionResults(ctx, job.ID, "video123", nil, results, `{"sponsors":[]}`, 100)
+		require.NoError(t, err)
+
+		videoSponsors, err := denylistRepo.GetVideoSponsorsByJobID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Len(t, videoSponsors, 1)
+
+		sponsor, err := denylistRepo.GetSponsorByID(ctx, videoSponsors[0].SponsorID)
+		require.NoError(t, err)
+		assert.Equal(t, "nordvpn", sponsor.NormalizedName)
+
+		_, err = denylistRepo.GetSponsorByNormalizedName(ctx, "patreon")
+		assert.True(t, db.IsNotFound(err), "denylisted sponsors are not created")
+
+		completed, err := denylistRepo.GetDetectionJobByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, completed.SponsorsDetectedCount)
+	})
+}
+
+func TestSponsorDetectionRepository_SaveDetectionResults_Chunked(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	ctx := context.Background()
+
+	results := make([]models.LLMSponsorResult, 0, 7)
+	for i := 0; i < 7; i++ {
+		results = append(results, models.LLMSponsorResult{
+			Name:       fmt.Sprintf("Sponsor %d", i),
+			Confidence: 0.5 + float64(i)/20,
+			Evidence:   fmt.Sprintf("evidence %d", i),
+		})
+	}
+
+	// saveAndSnapshot saves results with the given repository and returns the
+	// resulting sponsors, video_sponsors and job, keyed without generated IDs
+	saveAndSnapshot := func(t *testing.T, repo SponsorDetectionRepository) ([]string, *models.SponsorDetectionJob) {
+		td.TruncateTables(t)
+
+		job := createSponsorTestVideo(t, ctx, td, repo, "UC123", "video123", time.Now())
+		require.NoError(t, repo.SaveDetectionResults(ctx, job.ID, "video123", nil, results, `{"sponsors":[]}`, 100))
+
+		videoSponsors, err := repo.GetVideoSponsorsByJobID(ctx, job.ID)
+		require.NoError(t, err)
+
+		rows := make([]string, 0, len(videoSponsors))
+		for _, vs := range videoSponsors {
+			sponsor, err := repo.GetSponsorByID(ctx, vs.SponsorID)
+			require.NoError(t, err)
+			rows = append(rows, fmt.Sprintf("%s|%d|%.2f|%s", sponsor.NormalizedName, sponsor.VideoCount, vs.Confidence, vs.Evidence))
+		}
+
+		completed, err := repo.GetDetectionJobByID(ctx, job.ID)
+		require.NoError(t, err)
+
+		return rows, completed
+	}
+
+	atomicRows, atomicJob := saveAndSnapshot(t, NewSponsorDetectionRepository(td.Pool))
+	chunkedRows, chunkedJob := saveAndSnapshot(t, NewSponsorDetectionRepositoryWithConfig(td.Pool, SponsorDetectionRepositoryConfig{CommitChunkSize: 3}))
+
+	require.Len(t, atomicRows, len(results))
+	assert.Equal(t, atomicRows, chunkedRows)
+
+	assert.Equal(t, "completed", chunkedJob.Status)
+	assert.Equal(t, atomicJob.Status, chunkedJob.Status)
+	assert.Equal(t, atomicJob.SponsorsDetectedCount, chunkedJob.SponsorsDetectedCount)
+}
+
+func TestSponsorDetectionRepository_CheckSponsorVideoCount(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	job := createSponsorTestVideo(t, ctx, td, repo, "UC123", "video123", time.Now())
+	results := []models.LLMSponsorResult{{Name: "NordVPN", Confidence: 0.9, Evidence: "sponsored by NordVPN"}}
+	require.NoError(t, repo.SaveDetectionResults(ctx, job.ID, "video123", nil, results, `{"sponsors":[]}`, 100))
+
+	sponsor, err := repo.GetSponsorByNormalizedName(ctx, "nordvpn")
+	require.NoError(t, err)
+	require.NotNil(t, sponsor)
+
+	// Simulate drift
+	_, err = td.Pool.Exec(ctx, "UPDATE sponsors SET video_count = 5 WHERE id = $1", sponsor.ID)
+	require.NoError(t, err)
+
+	t.Run("detects a wrong count without fixing it", func(t *testing.T) {
+		check, err := repo.CheckSponsorVideoCount(ctx, sponsor.ID, false)
+		require.NoError(t, err)
+		require.NotNil(t, check)
+		assert.Equal(t, 5, check.StoredCount)
+		assert.Equal(t, 1, check.ActualCount)
+		assert.False(t, check.Consistent)
+		assert.False(t, check.Fixed)
+
+		stored, err := repo.GetSponsorByID(ctx, sponsor.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, stored.VideoCount)
+	})
+
+	t.Run("fixes a wrong count", func(t *testing.T) {
+		check, err := repo.CheckSponsorVideoCount(ctx, sponsor.ID, true)
+		require.NoError(t, err)
+		require.NotNil(t, check)
+		assert.False(t, check.Consistent)
+		assert.True(t, check.Fixed)
+
+		stored, err := repo.GetSponsorByID(ctx, sponsor.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored.VideoCount)
+
+		check, err = repo.CheckSponsorVideoCount(ctx, sponsor.ID, false)
+		require.NoError(t, err)
+		assert.True(t, check.Consistent)
+	})
+
+	t.Run("unknown sponsor returns nil", func(t *testing.T) {
+		check, err := repo.CheckSponsorVideoCount(ctx, uuid.New(), false)
+		require.NoError(t, err)
+		assert.Nil(t, check)
+	})
+}
+
+func TestSponsorDetectionRepository_GetVideosWithoutDetection(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	enrichmentRepo := NewEnrichmentRepository(td.Pool)
+	videoRepo := NewVideoRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	// Already has a detection job
+	createSponsorTestVideo(t, ctx, td, repo, "UC123", "detected", time.Now())
+
+	description := "This video is sponsored by Acme"
+	empty := ""
+	for _, videoID := range []string{"undetected", "nodescription", "notenriched"} {
+		video := models.NewVideo(videoID, "UC123", "Video "+videoID, "https://youtube.com/watch?v="+videoID, time.Now())
+		require.NoError(t, videoRepo.UpsertVideo(ctx, video))
+	}
+
+	require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "detected", Description: &description}))
+	require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "undetected", Description: &description}))
+	require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "nodescription", Description: &empty}))
+
+	videos, err := repo.GetVideosWithoutDetection(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, videos, 1)
+	assert.Equal(t, "undetected", videos[0].VideoID)
+	assert.Equal(t, "Video undetected", videos[0].Title)
+	assert.Equal(t, description, videos[0].Description)
+}
+
+func TestTruncateEvidence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		evidence  string
+		maxLength int
+		want      string
+	}{
+		{
+			name:      "short evidence is unchanged",
+			evidence:  "sponsored by NordVPN",
+			maxLength: 500,
+			want:      "sponsored by NordVPN",
+		},
+		{
+			name:      "long evidence is cut with an ellipsis",
+			evidence:  "abcdefghij",
+			maxLength: 5,
+			want:      "abcd…",
+		},
+		{
+			name:      "invalid UTF-8 is dropped",
+			evidence:  "code\xffSAVE",
+			maxLength: 500,
+			want:      "codeSAVE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, truncateEvidence(tt.evidence, tt.maxLength))
+		})
+	}
+
+	t.Run("5000 character evidence", func(t *testing.T) {
+		t.Parallel()
+
+		got := truncateEvidence(strings.Repeat("ü", 5000), DefaultMaxEvidenceLength)
+		assert.True(t, utf8.ValidString(got))
+		assert.Equal(t, DefaultMaxEvidenceLength, utf8.RuneCountInString(got))
+	})
+}
+
+func TestSponsorDetectionRepository_GetChannelSponsorTrend(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	videos := []struct {
+		videoID     string
+		publishedAt time.Time
+		sponsors    []string
+	}{
+		{"video-jan-1", time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC), []string{"NordVPN", "Skillshare"}},
+		{"video-jan-2", time.Date(2025, 1, 31, 23, 30, 0, 0, time.UTC), []string{"NordVPN"}},
+		{"video-feb-1", time.Date(2025, 2, 1, 0, 15, 0, 0, time.UTC), nil},
+		{"video-mar-1", time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC), []string{"Squarespace"}},
+	}
+
+	for _, v := range videos {
+		job := createSponsorTestVideo(t, ctx, td, repo, "UCtrend", v.videoID, v.publishedAt)
+
+		results := make([]models.LLMSponsorResult, 0, len(v.sponsors))
+		for _, name := range v.sponsors {
+			results = append(results, models.LLMSponsorResult{Name: name, Confidence: 0.9, Evidence: "sponsored by " + name})
+		}
+		require.NoError(t, repo.SaveDetectionResults(ctx, job.ID, v.videoID, nil, results, `{"sponsors":[]}`, 10))
+	}
+
+	t.Run("buckets by published month", func(t *testing.T) {
+		buckets, err := repo.GetChannelSponsorTrend(ctx, "UCtrend", "month", "", nil, nil)
+		require.NoError(t, err)
+		require.Len(t, buckets, 2)
+
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), buckets[0].Period)
+		assert.Equal(t, 2, buckets[0].SponsorCount)
+		assert.Equal(t, 2, buckets[0].SponsoredVideoCount)
+
+		assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), buckets[1].Period)
+		assert.Equal(t, 1, buckets[1].SponsorCount)
+		assert.Equal(t, 1, buckets[1].SponsoredVideoCount)
+	})
+
+	t.Run("respects the date range", func(t *testing.T) {
+		since := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		until := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+		buckets, err := repo.GetChannelSponsorTrend(ctx, "UCtrend", "month", "", &since, &until)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), buckets[0].Period)
+	})
+
+	t.Run("other channels are excluded", func(t *testing.T) {
+		buckets, err := repo.GetChannelSponsorTrend(ctx, "UCother", "month", "", nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, buckets)
+	})
+}
+
+func TestSponsorDetectionRepository_ListSponsors_Cancelled(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	_, err := td.Pool.Exec(ctx, `
+		INSERT INTO sponsors (name, normalized_name)
+		SELECT 'Sponsor ' || i, 'sponsor ' || i FROM generate_series(1, 5000) AS i
+	`)
+	require.NoError(t, err)
+
+	sponsors, err := repo.ListSponsors(ctx, "name", "asc", "", "", 5000, 0)
+	require.NoError(t, err)
+	require.Len(t, sponsors, 5000)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	start := time.Now()
+	sponsors, err = repo.ListSponsors(cancelled, "name", "asc", "", "", 5000, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, sponsors)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSponsorDetectionRepository_GetChannelSponsorReport(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	videos := []struct {
+		channelID   string
+		videoID     string
+		publishedAt time.Time
+		sponsors    []string
+	}{
+		{"UCreport", "video-1", time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC), []string{"NordVPN", "Skillshare"}},
+		{"UCreport", "video-2", time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC), []string{"NordVPN"}},
+		{"UCreport", "video-3", time.Date(2025, 3, 20, 8, 0, 0, 0, time.UTC), []string{"NordVPN"}},
+		{"UCother", "video-4", time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC), []string{"Skillshare"}},
+	}
+
+	for _, v := range videos {
+		job := createSponsorTestVideo(t, ctx, td, repo, v.channelID, v.videoID, v.publishedAt)
+
+		results := make([]models.LLMSponsorResult, 0, len(v.sponsors))
+		for _, name := range v.sponsors {
+			results = append(results, models.LLMSponsorResult{Name: name, Confidence: 0.9, Evidence: "sponsored by " + name})
+		}
+		require.NoError(t, repo.SaveDetectionResults(ctx, job.ID, v.videoID, nil, results, `{"sponsors":[]}`, 10))
+	}
+
+	entries, err := repo.GetChannelSponsorReport(ctx, "UCreport")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "NordVPN", entries[0].SponsorName)
+	assert.Equal(t, 3, entries[0].VideoCount)
+	assert.True(t, entries[0].FirstSeenAt.Equal(videos[0].publishedAt))
+	assert.True(t, entries[0].LastSeenAt.Equal(videos[2].publishedAt))
+
+	// Skillshare's video on the other channel is not counted
+	assert.Equal(t, "Skillshare", entries[1].SponsorName)
+	assert.Equal(t, 1, entries[1].VideoCount)
+	assert.True(t, entries[1].FirstSeenAt.Equal(videos[0].publishedAt))
+	assert.True(t, entries[1].LastSeenAt.Equal(videos[0].publishedAt))
+
+	// Streaming yields the same entries in order and stops at the callback's error
+	var streamed []string
+	stop := errors.New("stop")
+	err = repo.StreamChannelSponsorReport(ctx, "UCreport", func(entry *models.ChannelSponsorReportEntry) error {
+		streamed = append(streamed, entry.SponsorName)
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, []string{"NordVPN"}, streamed)
+}
+
+func TestSponsorDetectionRepository_GetConfidenceHistogram(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	videos := []struct {
+		videoID     string
+		llmModel    string
+		confidences []float64
+	}{
+		{"video-conf-1", "test-model", []float64{0.05, 0.55, 0.95}},
+		{"video-conf-2", "test-model", []float64{0.5, 0.99, 1.0}},
+		{"video-conf-3", "other-model", []float64{0.0, 0.52}},
+	}
+
+	for _, v := range videos {
+		job := createSponsorTestVideo(t, ctx, td, repo, "UCconfidence", v.videoID, time.Now())
+		_, err := td.Pool.Exec(ctx, "UPDATE sponsor_detection_jobs SET llm_model = $1 WHERE id = $2", v.llmModel, job.ID)
+		require.NoError(t, err)
+
+		results := make([]models.LLMSponsorResult, 0, len(v.confidences))
+		for i, confidence := range v.confidences {
+			name := fmt.Sprintf("Sponsor %d", i)
+			results = append(results, models.LLMSponsorResult{Name: name, Confidence: confidence, Evidence: "sponsored by " + name})
+		}
+		require.NoError(t, repo.SaveDetectionResults(ctx, job.ID, v.videoID, nil, results, `{"sponsors":[]}`, 10))
+	}
+
+	counts := func(histogram []*models.ConfidenceBucket) []int {
+		out := make([]int, len(histogram))
+		for i, bucket := range histogram {
+			out[i] = bucket.Count
+		}
+		return out
+	}
+
+	t.Run("counts detections per decile", func(t *testing.T) {
+		histogram, err := repo.GetConfidenceHistogram(ctx, 10, "", "", nil, nil)
+		require.NoError(t, err)
+		require.Len(t, histogram, 10)
+
+		// A confidence of exactly 1.0 falls in the last bucket
+		assert.Equal(t, []int{2, 0, 0, 0, 0, 3, 0, 0, 0, 3}, counts(histogram))
+		assert.InDelta(t, 0.5, histogram[5].Min, 1e-9)
+		assert.InDelta(t, 0.6, histogram[5].Max, 1e-9)
+	})
+
+	t.Run("filters by model", func(t *testing.T) {
+		histogram, err := repo.GetConfidenceHistogram(ctx, 10, "other-model", "", nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 0, 0, 0, 0, 1, 0, 0, 0, 0}, counts(histogram))
+	})
+
+	t.Run("supports other bucket counts", func(t *testing.T) {
+		histogram, err := repo.GetConfidenceHistogram(ctx, 4, "test-model", "", nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 0, 2, 3}, counts(histogram))
+	})
+
+	t.Run("respects the date range", func(t *testing.T) {
+		until := time.Now().Add(-24 * time.Hour)
+
+		histogram, err := repo.GetConfidenceHistogram(ctx, 10, "", "", nil, &until)
+		require.NoError(t, err)
+		require.Len(t, histogram, 10)
+		assert.Equal(t, make([]int, 10), counts(histogram))
+	})
+}
+
+func TestSponsorDetectionRepository_FormatFilter(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	enrichmentRepo := NewEnrichmentRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	publishedAt := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	videos := []struct {
+		videoID  string
+		duration string
+		sponsors []string
+	}{
+		{"video-short-1", "PT45S", []string{"NordVPN"}},
+		{"video-short-2", "PT3M", []string{"NordVPN", "Raid Shadow Legends"}},
+		{"video-long-1", "PT12M30S", []string{"NordVPN", "Squarespace"}},
+		{"video-live-1", "P0D", []string{"Squarespace"}},
+	}
+
+	for _, v := range videos {
+		job := createSponsorTestVideo(t, ctx, td, repo, "UCformat", v.videoID, publishedAt)
+		duration := v.duration
+		require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: v.videoID, Duration: &duration}))
+
+		results := make([]models.LLMSponsorResult, 0, len(v.sponsors))
+		for _, name := range v.sponsors {
+			results = append(results, models.LLMSponsorResult{Name: name, Confidence: 0.9, Evidence: "sponsored by " + name})
+		}
+		require.NoError(t, repo.SaveDetectionResults(ctx, job.ID, v.videoID, nil, results, `{"sponsors":[]}`, 10))
+	}
+
+	sponsorCounts := func(format string) map[string]int {
+		sponsors, err := repo.ListSponsors(ctx, "name", "asc", "", format, 100, 0)
+		require.NoError(t, err)
+
+		counts := make(map[string]int, len(sponsors))
+		for _, sponsor := range sponsors {
+			counts[sponsor.Name] = sponsor.VideoCount
+		}
+		return counts
+	}
+
+	t.Run("list sponsors partitions by format", func(t *testing.T) {
+		assert.Equal(t, map[string]int{"NordVPN": 2, "Raid Shadow Legends": 1}, sponsorCounts(models.VideoFormatShort))
+		assert.Equal(t, map[string]int{"NordVPN": 1, "Squarespace": 1}, sponsorCounts(models.VideoFormatLong))
+		assert.Equal(t, map[string]int{"NordVPN": 3, "Raid Shadow Legends": 1, "Squarespace": 2}, sponsorCounts(""))
+	})
+
+	t.Run("channel trend partitions by format", func(t *testing.T) {
+		short, err := repo.GetChannelSponsorTrend(ctx, "UCformat", "month", models.VideoFormatShort, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, short, 1)
+		assert.Equal(t, 2, short[0].SponsorCount)
+		assert.Equal(t, 2, short[0].SponsoredVideoCount)
+
+		long, err := repo.GetChannelSponsorTrend(ctx, "UCformat", "month", models.VideoFormatLong, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, long, 1)
+		assert.Equal(t, 2, long[0].SponsorCount)
+		assert.Equal(t, 1, long[0].SponsoredVideoCount)
+	})
+
+	t.Run("confidence histogram partitions by format", func(t *testing.T) {
+		total := func(format string) int {
+			histogram, err := repo.GetConfidenceHistogram(ctx, 10, "", format, nil, nil)
+			require.NoError(t, err)
+
+			sum := 0
+			for _, bucket := range histogram {
+				sum += bucket.Count
+			}
+			return sum
+		}
+
+		// The zero-length live video has no format, so it only appears unfiltered
+		assert.Equal(t, 3, total(models.VideoFormatShort))
+		assert.Equal(t, 2, total(models.VideoFormatLong))
+		assert.Equal(t, 6, total(""))
+	})
+}
+
+func TestSponsorDetectionRepository_GetDistinctVideoSponsors(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	firstJob := createSponsorTestVideo(t, ctx, td, repo, "UCdistinct", "video-distinct", time.Now())
+	require.NoError(t, repo.SaveDetectionResults(ctx, firstJob.ID, "video-distinct", nil, []models.LLMSponsorResult{
+		{Name: "NordVPN", Confidence: 0.7, Evidence: "first run"},
+		{Name: "Squarespace", Confidence: 0.9, Evidence: "first run"},
+	}, `{"sponsors":[]}`, 10))
+
+	// Re-running detection adds rows for the same pairs under a new job
+	secondJob := &models.SponsorDetectionJob{VideoID: "video-distinct", LLMModel: "test-model", Status: "pending"}
+	require.NoError(t, repo.CreateDetectionJob(ctx, secondJob))
+	require.NoError(t, repo.SaveDetectionResults(ctx, secondJob.ID, "video-distinct", nil, []models.LLMSponsorResult{
+		{Name: "NordVPN", Confidence: 0.95, Evidence: "second run"},
+		{Name: "Squarespace", Confidence: 0.6, Evidence: "second run"},
+	}, `{"sponsors":[]}`, 10))
+
+	all, err := repo.GetVideoSponsorsWithDetails(ctx, "video-distinct", VideoSponsorFilters{})
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	distinct, err := repo.GetDistinctVideoSponsors(ctx, "video-distinct", VideoSponsorFilters{})
+	require.NoError(t, err)
+	require.Len(t, distinct, 2)
+
+	// One row per sponsor, the highest-confidence one, ordered by confidence
+	assert.Equal(t, "NordVPN", distinct[0].SponsorName)
+	assert.InDelta(t, 0.95, distinct[0].Confidence, 1e-9)
+	assert.Equal(t, secondJob.ID, distinct[0].DetectionJobID)
+
+	assert.Equal(t, "Squarespace", distinct[1].SponsorName)
+	assert.InDelta(t, 0.9, distinct[1].Confidence, 1e-9)
+	assert.Equal(t, firstJob.ID, distinct[1].DetectionJobID)
+
+	// Filters drop low-confidence rows and cap the result, highest confidence first
+	filtered, err := repo.GetVideoSponsorsWithDetails(ctx, "video-distinct", VideoSponsorFilters{MinConfidence: 0.8, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.InDelta(t, 0.95, filtered[0].Confidence, 1e-9)
+
+	filtered, err = repo.GetDistinctVideoSponsors(ctx, "video-distinct", VideoSponsorFilters{MinConfidence: 0.92})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "NordVPN", filtered[0].SponsorName)
+}
+
+func TestSponsorDetectionRepository_GetRecentVideoSponsors(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	category := "vpn"
+	sponsor := &models.Sponsor{Name: "NordVPN", NormalizedName: "nordvpn", Category: &category}
+	require.NoError(t, repo.CreateSponsor(ctx, sponsor))
+
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	detections := []struct {
+		videoID    string
+		detectedAt time.Time
+	}{
+		{"video-recent-old", base},
+		{"video-recent-new", base.Add(2 * time.Hour)},
+		{"video-recent-mid", base.Add(time.Hour)},
+	}
+
+	for _, d := range detections {
+		job := createSponsorTestVideo(t, ctx, td, repo, "UCrecent", d.videoID, base)
+		require.NoError(t, repo.CreateVideoSponsor(ctx, &models.VideoSponsor{
+			VideoID:        d.videoID,
+			SponsorID:      sponsor.ID,
+			DetectionJobID: job.ID,
+			Confidence:     0.9,
+			Evidence:       "sponsored by NordVPN",
+			DetectedAt:     d.detectedAt,
+		}))
+	}
+
+	t.Run("orders by detection time, newest first", func(t *testing.T) {
+		recent, err := repo.GetRecentVideoSponsors(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, recent, 3)
+
+		assert.Equal(t, "video-recent-new", recent[0].VideoID)
+		assert.Equal(t, "video-recent-mid", recent[1].VideoID)
+		assert.Equal(t, "video-recent-old", recent[2].VideoID)
+	})
+
+	t.Run("includes sponsor and video details", func(t *testing.T) {
+		recent, err := repo.GetRecentVideoSponsors(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, recent, 1)
+
+		assert.Equal(t, "NordVPN", recent[0].SponsorName)
+		require.NotNil(t, recent[0].SponsorCategory)
+		assert.Equal(t, "vpn", *recent[0].SponsorCategory)
+		assert.Equal(t, "Test Video", recent[0].VideoTitle)
+		assert.Equal(t, "UCrecent", recent[0].VideoChannelID)
+		assert.Equal(t, "Test Channel", recent[0].ChannelTitle)
+		assert.Equal(t, "sponsored by NordVPN", recent[0].Evidence)
+	})
+}
+
+func TestSponsorDetectionRepository_GetSponsorVideos(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	sponsor := &models.Sponsor{Name: "NordVPN", NormalizedName: "nordvpn"}
+	require.NoError(t, repo.CreateSponsor(ctx, sponsor))
+
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, videoID := range []string{"video-sv-1", "video-sv-2", "video-sv-3"} {
+		job := createSponsorTestVideo(t, ctx, td, repo, "UCsponsorvideos", videoID, base)
+		require.NoError(t, repo.CreateVideoSponsor(ctx, &models.VideoSponsor{
+			VideoID:        videoID,
+			SponsorID:      sponsor.ID,
+			DetectionJobID: job.ID,
+			Confidence:     0.9,
+			Evidence:       "sponsored by NordVPN",
+			DetectedAt:     base.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+
+	t.Run("joins video details into each row", func(t *testing.T) {
+		videos, err := repo.GetSponsorVideos(ctx, sponsor.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, videos, 3)
+
+		// Newest detection first
+		assert.Equal(t, "video-sv-3", videos[0].VideoID)
+		for _, v := range videos {
+			require.NotNil(t, v.VideoTitle)
+			assert.Equal(t, "Test Video", *v.VideoTitle)
+			require.NotNil(t, v.VideoURL)
+			assert.Equal(t, "https://youtube.com/watch?v="+v.VideoID, *v.VideoURL)
+			require.NotNil(t, v.VideoChannelID)
+			assert.Equal(t, "UCsponsorvideos", *v.VideoChannelID)
+			require.NotNil(t, v.VideoPublishedAt)
+			assert.True(t, base.Equal(*v.VideoPublishedAt))
+		}
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		page, err := repo.GetSponsorVideos(ctx, sponsor.ID, 2, 2)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "video-sv-1", page[0].VideoID)
+	})
+}
+
+func TestSponsorDetectionRepository_TitlelessChannelDisplayName(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSponsorDetectionRepository(td.Pool)
+	channelRepo := NewChannelRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	// A channel first seen in a webhook without an author name is stored with an empty title
+	require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel("UCuntitled", "", "https://youtube.com/channel/UCuntitled")))
+	video := models.NewVideo("video-untitled", "UCuntitled", "Test Video", "https://youtube.com/watch?v=video-untitled", time.Now())
+	require.NoError(t, NewVideoRepository(td.Pool).UpsertVideo(ctx, video))
+
+	job := &models.SponsorDetectionJob{VideoID: "video-untitled", LLMModel: "test-model", Status: "pending"}
+	require.NoError(t, repo.CreateDetectionJob(ctx, job))
+
+	sponsor := &models.Sponsor{Name: "NordVPN", NormalizedName: "nordvpn"}
+	require.NoError(t, repo.CreateSponsor(ctx, sponsor))
+	require.NoError(t, repo.CreateVideoSponsor(ctx, &models.VideoSponsor{
+		VideoID:        "video-untitled",
+		SponsorID:      sponsor.ID,
+		DetectionJobID: job.ID,
+		Confidence:     0.9,
+		Evidence:       "sponsored by NordVPN",
+		DetectedAt:     time.Now(),
+	}))
+
+	t.Run("falls back to the channel ID", func(t *testing.T) {
+		videos, err := repo.GetSponsorVideos(ctx, sponsor.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, videos, 1)
+		require.NotNil(t, videos[0].ChannelTitle)
+		assert.Equal(t, "UCuntitled", *videos[0].ChannelTitle)
+
+		recent, err := repo.GetRecentVideoSponsors(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, recent, 1)
+		assert.Equal(t, "UCuntitled", recent[0].ChannelTitle)
+	})
+
+	t.Run("uses the backfilled title", func(t *testing.T) {
+		updated, err := channelRepo.BackfillTitle(ctx, "UCuntitled", "Backfilled Channel")
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		videos, err := repo.GetSponsorVideos(ctx, sponsor.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, videos, 1)
+		require.NotNil(t, videos[0].ChannelTitle)
+		assert.Equal(t, "Backfilled Channel", *videos[0].ChannelTitle)
+	})
+}
@@ -26,10 +26,16 @@ type SubscriptionRepository interface {
 	// Update updates an existing subscription.
 	Update(ctx context.Context, sub *models.Subscription) error
 
+	// Activate saves sub as active after the hub accepted its subscribe request. If the hub's
+	// verification already confirmed a lease for the request (see ConfirmLease), the confirmed
+	// lease and expiry are kept instead of sub's, and sub is updated to match them.
+	Activate(ctx context.Context, sub *models.Subscription) error
+
 	// Delete deletes a subscription by ID.
 	Delete(ctx context.Context, id int64) error
 
-	// GetExpiringSoon retrieves subscriptions that will expire soon.
+	// GetExpiringSoon retrieves active and failed subscriptions that will expire soon.
+	// Abandoned subscriptions are excluded.
 	GetExpiringSoon(ctx context.Context, limit int) ([]*models.Subscription, error)
 
 	// GetByStatus retrieves subscriptions by status.
@@ -37,6 +43,13 @@ type SubscriptionRepository interface {
 
 	// List retrieves subscriptions with filters and pagination.
 	List(ctx context.Context, filters *SubscriptionFilters) ([]*models.Subscription, int, error)
+
+	// ConfirmLease records the lease a hub granted when it verified a subscription to topicURL:
+	// pending and active subscriptions to the topic with a subscribe request sent within
+	// LeaseConfirmWindow become active and expire leaseSeconds from now. Other subscriptions
+	// are left unchanged, since the verification callback is unauthenticated. It returns the
+	// number of subscriptions updated.
+	ConfirmLease(ctx context.Context, topicURL string, leaseSeconds int) (int64, error)
 }
 
 // SubscriptionFilters contains filter options for listing subscriptions.
@@ -48,6 +61,10 @@ type SubscriptionFilters struct {
 	ExpiresBefore *time.Time
 }
 
+// LeaseConfirmWindow is how long after a subscribe request the hub's verification may
+// confirm its lease.
+const LeaseConfirmWindow = time.Hour
+
 type subscriptionRepository struct {
 	pool *pgxpool.Pool
 }
@@ -61,9 +78,9 @@ func (r *subscriptionRepository) Create(ctx context.Context, sub *models.Subscri
 	query := `
 		INSERT INTO pubsub_subscriptions (
 			channel_id, topic_url, hub_url, lease_seconds,
-			expires_at, status, created_at, updated_at
+			expires_at, status, failure_count, lease_requested_at, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -74,6 +91,8 @@ func (r *subscriptionRepository) Create(ctx context.Context, sub *models.Subscri
 		sub.LeaseSeconds,
 		sub.ExpiresAt,
 		sub.Status,
+		sub.FailureCount,
+		sub.LeaseRequestedAt,
 		sub.CreatedAt,
 		sub.UpdatedAt,
 	).Scan(
@@ -92,7 +111,7 @@ func (r *subscriptionRepository) Create(ctx context.Context, sub *models.Subscri
 func (r *subscriptionRepository) GetByID(ctx context.Context, id int64) (*models.Subscription, error) {
 	query := `
 		SELECT id, channel_id, topic_url, hub_url, lease_seconds,
-		       expires_at, status, last_verified_at, created_at, updated_at
+		       expires_at, status, failure_count, last_verified_at, created_at, updated_at
 		FROM pubsub_subscriptions
 		WHERE id = $1
 	`
@@ -106,6 +125,7 @@ func (r *subscriptionRepository) GetByID(ctx context.Context, id int64) (*models
 		&sub.LeaseSeconds,
 		&sub.ExpiresAt,
 		&sub.Status,
+		&sub.FailureCount,
 		&sub.LastVerifiedAt,
 		&sub.CreatedAt,
 		&sub.UpdatedAt,
@@ -121,7 +141,7 @@ func (r *subscriptionRepository) GetByID(ctx context.Context, id int64) (*models
 func (r *subscriptionRepository) GetByChannelID(ctx context.Context, channelID string) ([]*models.Subscription, error) {
 	query := `
 		SELECT id, channel_id, topic_url, hub_url, lease_seconds,
-		       expires_at, status, last_verified_at, created_at, updated_at
+		       expires_at, status, failure_count, last_verified_at, created_at, updated_at
 		FROM pubsub_subscriptions
 		WHERE channel_id = $1
 		ORDER BY created_at DESC
@@ -145,8 +165,10 @@ func (r *subscriptionRepository) Update(ctx context.Context, sub *models.Subscri
 		    lease_seconds = $4,
 		    expires_at = $5,
 		    status = $6,
-		    last_verified_at = $7
-		WHERE id = $8
+		    failure_count = $7,
+		    last_verified_at = $8,
+		    lease_requested_at = COALESCE($9, lease_requested_at)
+		WHERE id = $10
 		RETURNING updated_at
 	`
 
@@ -157,7 +179,9 @@ func (r *subscriptionRepository) Update(ctx context.Context, sub *models.Subscri
 		sub.LeaseSeconds,
 		sub.ExpiresAt,
 		sub.Status,
+		sub.FailureCount,
 		sub.LastVerifiedAt,
+		sub.LeaseRequestedAt,
 		sub.ID,
 	).Scan(&sub.UpdatedAt)
 
@@ -168,6 +192,58 @@ func (r *subscriptionRepository) Update(ctx context.Context, sub *models.Subscri
 	return nil
 }
 
+func (r *subscriptionRepository) Activate(ctx context.Context, sub *models.Subscription) error {
+	// ConfirmLease clears lease_requested_at, so a NULL means the hub has already verified
+	query := `
+		UPDATE pubsub_subscriptions
+		SET lease_seconds = CASE WHEN lease_requested_at IS NULL THEN lease_seconds ELSE $1 END,
+		    expires_at = CASE WHEN lease_requested_at IS NULL THEN expires_at ELSE $2 END,
+		    status = $3,
+		    failure_count = $4,
+		    last_verified_at = $5
+		WHERE id = $6
+		RETURNING lease_seconds, expires_at, updated_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		sub.LeaseSeconds,
+		sub.ExpiresAt,
+		sub.Status,
+		sub.FailureCount,
+		sub.LastVerifiedAt,
+		sub.ID,
+	).Scan(&sub.LeaseSeconds, &sub.ExpiresAt, &sub.UpdatedAt)
+
+	if err != nil {
+		return db.WrapError(err, "activate subscription")
+	}
+
+	return nil
+}
+
+func (r *subscriptionRepository) ConfirmLease(ctx context.Context, topicURL string, leaseSeconds int) (int64, error) {
+	query := `
+		UPDATE pubsub_subscriptions
+		SET lease_seconds = $2,
+		    expires_at = NOW() + make_interval(secs => $2),
+		    status = $3,
+		    last_verified_at = NOW(),
+		    lease_requested_at = NULL,
+		    updated_at = NOW()
+		WHERE topic_url = $1
+		  AND status IN ($3, $4)
+		  AND lease_requested_at > NOW() - make_interval(secs => $5)
+	`
+
+	result, err := r.pool.Exec(ctx, query, topicURL, leaseSeconds, models.StatusActive, models.StatusPending,
+		LeaseConfirmWindow.Seconds())
+	if err != nil {
+		return 0, db.WrapError(err, "confirm subscription lease")
+	}
+
+	return result.RowsAffected(), nil
+}
+
 func (r *subscriptionRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM pubsub_subscriptions WHERE id = $1`
 
@@ -186,14 +262,14 @@ func (r *subscriptionRepository) Delete(ctx context.Context, id int64) error {
 func (r *subscriptionRepository) GetExpiringSoon(ctx context.Context, limit int) ([]*models.Subscription, error) {
 	query := `
 		SELECT id, channel_id, topic_url, hub_url, lease_seconds,
-		       expires_at, status, last_verified_at, created_at, updated_at
+		       expires_at, status, failure_count, last_verified_at, created_at, updated_at
 		FROM pubsub_subscriptions
-		WHERE status = $1 AND expires_at <= NOW() + INTERVAL '24 hours'
+		WHERE status IN ($1, $2) AND expires_at <= NOW() + INTERVAL '24 hours'
 		ORDER BY expires_at ASC
-		LIMIT $2
+		LIMIT $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, models.StatusActive, limit)
+	rows, err := r.pool.Query(ctx, query, models.StatusActive, models.StatusFailed, limit)
 	if err != nil {
 		return nil, db.WrapError(err, "get expiring subscriptions")
 	}
@@ -205,7 +281,7 @@ func (r *subscriptionRepository) GetExpiringSoon(ctx context.Context, limit int)
 func (r *subscriptionRepository) GetByStatus(ctx context.Context, status string, limit int) ([]*models.Subscription, error) {
 	query := `
 		SELECT id, channel_id, topic_url, hub_url, lease_seconds,
-		       expires_at, status, last_verified_at, created_at, updated_at
+		       expires_at, status, failure_count, last_verified_at, created_at, updated_at
 		FROM pubsub_subscriptions
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -261,7 +337,7 @@ func (r *subscriptionRepository) List(ctx context.Context, filters *Subscription
 
 	query := fmt.Sprintf(`
 		SELECT id, channel_id, topic_url, hub_url, lease_seconds,
-		       expires_at, status, last_verified_at, created_at, updated_at
+		       expires_at, status, failure_count, last_verified_at, created_at, updated_at
 		FROM pubsub_subscriptions
 		%s
 		ORDER BY created_at DESC
@@ -298,6 +374,7 @@ func scanSubscriptions(rows pgx.Rows) ([]*models.Subscription, error) {
 			&sub.LeaseSeconds,
 			&sub.ExpiresAt,
 			&sub.Status,
+			&sub.FailureCount,
 			&sub.LastVerifiedAt,
 			&sub.CreatedAt,
 			&sub.UpdatedAt,
@@ -159,6 +159,60 @@ func TestSubscriptionRepository_Update(t *testing.T) {
 	})
 }
 
+func TestSubscriptionRepository_Activate(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSubscriptionRepository(td.Pool)
+	ctx := context.Background()
+
+	t.Run("stores the requested lease while verification is pending", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		sub := models.NewSubscription("UCactivate", 432000)
+		sub.MarkLeaseRequested()
+		require.NoError(t, repo.Create(ctx, sub))
+
+		sub.MarkActive()
+		sub.UpdateExpiry(432000)
+		require.NoError(t, repo.Activate(ctx, sub))
+
+		retrieved, err := repo.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusActive, retrieved.Status)
+		assert.Equal(t, 432000, retrieved.LeaseSeconds)
+
+		// The request is still pending, so the hub's verification can confirm it
+		updated, err := repo.ConfirmLease(ctx, sub.TopicURL, 86400)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+	})
+
+	t.Run("keeps a lease the hub already confirmed", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		sub := models.NewSubscription("UCconfirmedfirst", 432000)
+		sub.MarkLeaseRequested()
+		require.NoError(t, repo.Create(ctx, sub))
+
+		updated, err := repo.ConfirmLease(ctx, sub.TopicURL, 86400)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), updated)
+
+		sub.MarkActive()
+		sub.UpdateExpiry(432000)
+		require.NoError(t, repo.Activate(ctx, sub))
+
+		assert.Equal(t, 86400, sub.LeaseSeconds)
+		assert.WithinDuration(t, time.Now().Add(86400*time.Second), sub.ExpiresAt, time.Minute)
+
+		retrieved, err := repo.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusActive, retrieved.Status)
+		assert.Equal(t, 86400, retrieved.LeaseSeconds)
+	})
+}
+
 func TestSubscriptionRepository_Delete(t *testing.T) {
 	td := testutil.SetupTestDatabase(t)
 	defer td.Cleanup(t)
@@ -240,6 +294,25 @@ func TestSubscriptionRepository_GetExpiringSoon(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, subscriptions, 3)
 	})
+
+	t.Run("retries failed subscriptions but skips abandoned ones", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		failed := models.NewSubscription("UCfailed", 3600)
+		failed.MarkFailed()
+		require.NoError(t, repo.Create(ctx, failed))
+
+		abandoned := models.NewSubscription("UCabandoned", 3600)
+		abandoned.MarkFailed()
+		abandoned.MarkAbandoned()
+		require.NoError(t, repo.Create(ctx, abandoned))
+
+		subscriptions, err := repo.GetExpiringSoon(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		assert.Equal(t, failed.ID, subscriptions[0].ID)
+		assert.Equal(t, 1, subscriptions[0].FailureCount)
+	})
 }
 
 func TestSubscriptionRepository_GetByStatus(t *testing.T) {
@@ -302,3 +375,68 @@ func TestSubscriptionRepository_GetByStatus(t *testing.T) {
 		assert.Len(t, subscriptions, 3)
 	})
 }
+
+func TestSubscriptionRepository_ConfirmLease(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewSubscriptionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	pending := models.NewSubscription("UCconfirm", 432000)
+	pending.MarkLeaseRequested()
+	require.NoError(t, repo.Create(ctx, pending))
+
+	abandoned := models.NewSubscription("UCabandoned", 432000)
+	abandoned.MarkLeaseRequested()
+	abandoned.Status = models.StatusAbandoned
+	require.NoError(t, repo.Create(ctx, abandoned))
+
+	failed := models.NewSubscription("UCfailed", 432000)
+	failed.MarkLeaseRequested()
+	failed.Status = models.StatusFailed
+	require.NoError(t, repo.Create(ctx, failed))
+
+	unrequested := models.NewSubscription("UCunrequested", 432000)
+	unrequested.Status = models.StatusActive
+	require.NoError(t, repo.Create(ctx, unrequested))
+
+	stale := models.NewSubscription("UCstale", 432000)
+	staleAt := time.Now().Add(-2 * LeaseConfirmWindow)
+	stale.LeaseRequestedAt = &staleAt
+	stale.Status = models.StatusActive
+	require.NoError(t, repo.Create(ctx, stale))
+
+	updated, err := repo.ConfirmLease(ctx, pending.TopicURL, 86400)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	confirmed, err := repo.GetByID(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusActive, confirmed.Status)
+	assert.Equal(t, 86400, confirmed.LeaseSeconds)
+	assert.WithinDuration(t, time.Now().Add(86400*time.Second), confirmed.ExpiresAt, time.Minute)
+	assert.NotNil(t, confirmed.LastVerifiedAt)
+
+	updated, err = repo.ConfirmLease(ctx, abandoned.TopicURL, 86400)
+	require.NoError(t, err)
+	assert.Zero(t, updated, "abandoned subscriptions are not revived")
+
+	for _, sub := range []*models.Subscription{failed, unrequested, stale} {
+		updated, err = repo.ConfirmLease(ctx, sub.TopicURL, 1)
+		require.NoError(t, err)
+		assert.Zero(t, updated, "%s has no pending subscribe request", sub.ChannelID)
+
+		unchanged, err := repo.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sub.Status, unchanged.Status)
+		assert.Equal(t, 432000, unchanged.LeaseSeconds)
+	}
+
+	// The confirmation consumes the pending request, so a replayed verification is ignored
+	updated, err = repo.ConfirmLease(ctx, pending.TopicURL, 1)
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TrendingRepository defines operations for trending chart snapshots
+type TrendingRepository interface {
+	// SaveChartSnapshot records the tracked videos among chartVideoIDs (in chart order) for a region and day.
+	// Untracked videos are ignored, and a video already recorded for that region and day is left unchanged.
+	// Returns the number of snapshots created.
+	SaveChartSnapshot(ctx context.Context, regionCode string, snapshotDate time.Time, chartVideoIDs []string) (int, error)
+
+	// GetSnapshotsByVideoID retrieves a video's trending snapshots, newest first
+	GetSnapshotsByVideoID(ctx context.Context, videoID string) ([]*model.TrendingSnapshot, error)
+}
+
+type trendingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTrendingRepository creates a new TrendingRepository
+func NewTrendingRepository(pool *pgxpool.Pool) TrendingRepository {
+	return &trendingRepository{pool: pool}
+}
+
+func (r *trendingRepository) SaveChartSnapshot(ctx context.Context, regionCode string, snapshotDate time.Time, chartVideoIDs []string) (int, error) {
+	if len(chartVideoIDs) == 0 {
+		return 0, nil
+	}
+
+	// WITH ORDINALITY yields each video's 1-based chart position
+	query := `
+		INSERT INTO video_trending_snapshots (video_id, region_code, snapshot_date, chart_rank)
+		SELECT v.video_id, $2, $3::date, chart.rank
+		FROM unnest($1::text[]) WITH ORDINALITY AS chart(video_id, rank)
+		JOIN videos v ON v.video_id = chart.video_id
+		ON CONFLICT (video_id, region_code, snapshot_date) DO NOTHING
+	`
+
+	cmdTag, err := r.pool.Exec(ctx, query, chartVideoIDs, regionCode, snapshotDate)
+	if err != nil {
+		return 0, db.WrapError(err, "save chart snapshot")
+	}
+
+	return int(cmdTag.RowsAffected()), nil
+}
+
+func (r *trendingRepository) GetSnapshotsByVideoID(ctx context.Context, videoID string) ([]*model.TrendingSnapshot, error) {
+	query := `
+		SELECT id, video_id, region_code, snapshot_date, chart_rank, created_at
+		FROM video_trending_snapshots
+		WHERE video_id = $1
+		ORDER BY snapshot_date DESC, region_code
+	`
+
+	rows, err := r.pool.Query(ctx, query, videoID)
+	if err != nil {
+		return nil, db.WrapError(err, "get trending snapshots")
+	}
+	defer rows.Close()
+
+	var snapshots []*model.TrendingSnapshot
+	for rows.Next() {
+		snapshot := &model.TrendingSnapshot{}
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.VideoID,
+			&snapshot.RegionCode,
+			&snapshot.SnapshotDate,
+			&snapshot.ChartRank,
+			&snapshot.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan trending snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trending snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrendingRepository_SaveChartSnapshot(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewTrendingRepository(td.Pool)
+	videoRepo := NewVideoRepository(td.Pool)
+	channelRepo := NewChannelRepository(td.Pool)
+	ctx := context.Background()
+
+	today := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("records tracked videos on the chart", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel("UC123", "Test Channel", "https://youtube.com/channel/UC123")))
+		require.NoError(t, videoRepo.UpsertVideo(ctx, models.NewVideo("tracked1", "UC123", "Tracked", "https://youtube.com/watch?v=tracked1", today)))
+
+		created, err := repo.SaveChartSnapshot(ctx, "US", today, []string{"other1", "tracked1", "other2"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, created, "only tracked videos are snapshotted")
+
+		snapshots, err := repo.GetSnapshotsByVideoID(ctx, "tracked1")
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		assert.Equal(t, "US", snapshots[0].RegionCode)
+		assert.Equal(t, 2, snapshots[0].ChartRank)
+		assert.True(t, snapshots[0].SnapshotDate.Equal(today))
+	})
+
+	t.Run("keeps one snapshot per region and day", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel("UC123", "Test Channel", "https://youtube.com/channel/UC123")))
+		require.NoError(t, videoRepo.UpsertVideo(ctx, models.NewVideo("tracked1", "UC123", "Tracked", "https://youtube.com/watch?v=tracked1", today)))
+
+		_, err := repo.SaveChartSnapshot(ctx, "US", today, []string{"tracked1"})
+		require.NoError(t, err)
+		created, err := repo.SaveChartSnapshot(ctx, "US", today, []string{"other1", "tracked1"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, created)
+
+		created, err = repo.SaveChartSnapshot(ctx, "GB", today, []string{"tracked1"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, created)
+
+		snapshots, err := repo.GetSnapshotsByVideoID(ctx, "tracked1")
+		require.NoError(t, err)
+		assert.Len(t, snapshots, 2)
+	})
+}
@@ -24,12 +24,20 @@ type VideoRepository interface {
 	// Update updates an existing video.
 	Update(ctx context.Context, video *models.Video) error
 
-	// Delete deletes a video by ID.
+	// Delete deletes a video by ID, along with its enrichments and sponsor relationships.
 	Delete(ctx context.Context, videoID string) error
 
-	// GetVideoByID retrieves a single video by ID.
+	// SoftDelete marks a video deleted, hiding it from lists while keeping its history.
+	// A video that is already soft-deleted keeps its original deletion time.
+	SoftDelete(ctx context.Context, videoID string, deletedAt time.Time) error
+
+	// GetVideoByID retrieves a single video by ID, including a soft-deleted one.
 	GetVideoByID(ctx context.Context, videoID string) (*models.Video, error)
 
+	// GetVideosByIDs retrieves the videos with the given IDs, including soft-deleted ones,
+	// keyed by video ID. IDs with no video are absent from the map.
+	GetVideosByIDs(ctx context.Context, videoIDs []string) (map[string]*models.Video, error)
+
 	// GetVideosByChannelID retrieves all videos for a specific channel.
 	GetVideosByChannelID(ctx context.Context, channelID string, limit int) ([]*models.Video, error)
 
@@ -41,6 +49,16 @@ type VideoRepository interface {
 
 	// GetVideosByPublishedDate retrieves videos published since the given time.
 	GetVideosByPublishedDate(ctx context.Context, since time.Time, limit int) ([]*models.Video, error)
+
+	// GetVideosForAvailabilityCheck retrieves available videos published before publishedBefore
+	// whose availability was not checked since checkedBefore, least recently checked first.
+	GetVideosForAvailabilityCheck(ctx context.Context, publishedBefore, checkedBefore time.Time, limit int) ([]*models.Video, error)
+
+	// MarkAvailabilityChecked records that the videos were confirmed to still exist.
+	MarkAvailabilityChecked(ctx context.Context, videoIDs []string, checkedAt time.Time) error
+
+	// MarkUnavailable records that a video was removed or is otherwise unavailable.
+	MarkUnavailable(ctx context.Context, videoID, reason string, unavailableAt time.Time) error
 }
 
 // VideoFilters contains filter options for listing videos.
@@ -51,6 +69,8 @@ type VideoFilters struct {
 	Title           string
 	PublishedAfter  *time.Time
 	PublishedBefore *time.Time
+	IsPremiere      *bool // matches videos any enrichment flagged as a premiere, so they stay premieres after airing
+	IncludeDeleted  bool  // include soft-deleted videos
 	OrderBy         string
 	OrderDir        string
 }
@@ -103,24 +123,12 @@ func (r *videoRepository) UpsertVideo(ctx context.Context, video *models.Video)
 
 func (r *videoRepository) GetVideoByID(ctx context.Context, videoID string) (*models.Video, error) {
 	query := `
-		SELECT video_id, channel_id, title, video_url, published_at, first_seen_at, last_updated_at, created_at, updated_at
+		SELECT ` + videoColumns + `
 		FROM videos
 		WHERE video_id = $1
 	`
 
-	video := &models.Video{}
-	err := r.pool.QueryRow(ctx, query, videoID).Scan(
-		&video.VideoID,
-		&video.ChannelID,
-		&video.Title,
-		&video.VideoURL,
-		&video.PublishedAt,
-		&video.FirstSeenAt,
-		&video.LastUpdatedAt,
-		&video.CreatedAt,
-		&video.UpdatedAt,
-	)
-
+	video, err := scanVideo(r.pool.QueryRow(ctx, query, videoID))
 	if err != nil {
 		return nil, db.WrapError(err, "get video by id")
 	}
@@ -128,11 +136,40 @@ func (r *videoRepository) GetVideoByID(ctx context.Context, videoID string) (*mo
 	return video, nil
 }
 
+func (r *videoRepository) GetVideosByIDs(ctx context.Context, videoIDs []string) (map[string]*models.Video, error) {
+	videos := make(map[string]*models.Video, len(videoIDs))
+	if len(videoIDs) == 0 {
+		return videos, nil
+	}
+
+	query := `
+		SELECT ` + videoColumns + `
+		FROM videos
+		WHERE video_id = ANY($1)
+	`
+
+	rows, err := r.pool.Query(ctx, query, videoIDs)
+	if err != nil {
+		return nil, db.WrapError(err, "get videos by ids")
+	}
+	defer rows.Close()
+
+	list, err := scanVideos(rows)
+	if err != nil {
+		return nil, db.WrapError(err, "get videos by ids")
+	}
+	for _, video := range list {
+		videos[video.VideoID] = video
+	}
+
+	return videos, nil
+}
+
 func (r *videoRepository) GetVideosByChannelID(ctx context.Context, channelID string, limit int) ([]*models.Video, error) {
 	query := `
-		SELECT video_id, channel_id, title, video_url, published_at, first_seen_at, last_updated_at, created_at, updated_at
+		SELECT ` + videoColumns + `
 		FROM videos
-		WHERE channel_id = $1
+		WHERE channel_id = $1 AND deleted_at IS NULL
 		ORDER BY published_at DESC
 		LIMIT $2
 	`
@@ -148,8 +185,9 @@ func (r *videoRepository) GetVideosByChannelID(ctx context.Context, channelID st
 
 func (r *videoRepository) ListVideos(ctx context.Context, limit, offset int) ([]*models.Video, error) {
 	query := `
-		SELECT video_id, channel_id, title, video_url, published_at, first_seen_at, last_updated_at, created_at, updated_at
+		SELECT ` + videoColumns + `
 		FROM videos
+		WHERE deleted_at IS NULL
 		ORDER BY published_at DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -165,9 +203,9 @@ func (r *videoRepository) ListVideos(ctx context.Context, limit, offset int) ([]
 
 func (r *videoRepository) GetVideosByPublishedDate(ctx context.Context, since time.Time, limit int) ([]*models.Video, error) {
 	query := `
-		SELECT video_id, channel_id, title, video_url, published_at, first_seen_at, last_updated_at, created_at, updated_at
+		SELECT ` + videoColumns + `
 		FROM videos
-		WHERE published_at >= $1
+		WHERE published_at >= $1 AND deleted_at IS NULL
 		ORDER BY published_at DESC
 		LIMIT $2
 	`
@@ -181,6 +219,63 @@ func (r *videoRepository) GetVideosByPublishedDate(ctx context.Context, since ti
 	return scanVideos(rows)
 }
 
+func (r *videoRepository) GetVideosForAvailabilityCheck(ctx context.Context, publishedBefore, checkedBefore time.Time, limit int) ([]*models.Video, error) {
+	query := `
+		SELECT ` + videoColumns + `
+		FROM videos
+		WHERE unavailable_at IS NULL
+		  AND deleted_at IS NULL
+		  AND published_at < $1
+		  AND (availability_checked_at IS NULL OR availability_checked_at < $2)
+		ORDER BY availability_checked_at NULLS FIRST, published_at
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, publishedBefore, checkedBefore, limit)
+	if err != nil {
+		return nil, db.WrapError(err, "get videos for availability check")
+	}
+	defer rows.Close()
+
+	return scanVideos(rows)
+}
+
+func (r *videoRepository) MarkAvailabilityChecked(ctx context.Context, videoIDs []string, checkedAt time.Time) error {
+	if len(videoIDs) == 0 {
+		return nil
+	}
+
+	query := `UPDATE videos SET availability_checked_at = $1 WHERE video_id = ANY($2)`
+
+	if _, err := r.pool.Exec(ctx, query, checkedAt, videoIDs); err != nil {
+		return db.WrapError(err, "mark availability checked")
+	}
+
+	return nil
+}
+
+func (r *videoRepository) MarkUnavailable(ctx context.Context, videoID, reason string, unavailableAt time.Time) error {
+	query := `
+		UPDATE videos
+		SET unavailable_at = $1,
+		    unavailable_reason = $2,
+		    availability_checked_at = $1,
+		    updated_at = NOW()
+		WHERE video_id = $3
+	`
+
+	result, err := r.pool.Exec(ctx, query, unavailableAt, reason, videoID)
+	if err != nil {
+		return db.WrapError(err, "mark video unavailable")
+	}
+
+	if result.RowsAffected() == 0 {
+		return db.WrapError(pgx.ErrNoRows, "mark video unavailable")
+	}
+
+	return nil
+}
+
 func (r *videoRepository) Create(ctx context.Context, video *models.Video) error {
 	query := `
 		INSERT INTO videos (video_id, channel_id, title, video_url, published_at, first_seen_at, last_updated_at, created_at, updated_at)
@@ -262,11 +357,35 @@ func (r *videoRepository) Delete(ctx context.Context, videoID string) error {
 	return nil
 }
 
+func (r *videoRepository) SoftDelete(ctx context.Context, videoID string, deletedAt time.Time) error {
+	query := `
+		UPDATE videos
+		SET deleted_at = COALESCE(deleted_at, $1),
+		    updated_at = NOW()
+		WHERE video_id = $2
+	`
+
+	result, err := r.pool.Exec(ctx, query, deletedAt, videoID)
+	if err != nil {
+		return db.WrapError(err, "soft delete video")
+	}
+
+	if result.RowsAffected() == 0 {
+		return db.WrapError(pgx.ErrNoRows, "soft delete video")
+	}
+
+	return nil
+}
+
 func (r *videoRepository) List(ctx context.Context, filters *VideoFilters) ([]*models.Video, int, error) {
 	args := []interface{}{}
 	argPos := 1
 	whereClauses := []string{}
 
+	if !filters.IncludeDeleted {
+		whereClauses = append(whereClauses, "deleted_at IS NULL")
+	}
+
 	if filters.ChannelID != "" {
 		whereClauses = append(whereClauses, fmt.Sprintf("channel_id = $%d", argPos))
 		args = append(args, filters.ChannelID)
@@ -291,6 +410,17 @@ func (r *videoRepository) List(ctx context.Context, filters *VideoFilters) ([]*m
 		argPos++
 	}
 
+	if filters.IsPremiere != nil {
+		// A premiere is only flagged while it is upcoming, so any flagged enrichment counts.
+		// Videos without an enrichment count as not premieres.
+		whereClauses = append(whereClauses, fmt.Sprintf(`COALESCE((
+			SELECT bool_or(e.is_premiere) FROM video_api_enrichments e
+			WHERE e.video_id = videos.video_id
+		), FALSE) = $%d`, argPos))
+		args = append(args, *filters.IsPremiere)
+		argPos++
+	}
+
 	whereClause := ""
 	if len(whereClauses) > 0 {
 		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
@@ -314,7 +444,7 @@ func (r *videoRepository) List(ctx context.Context, filters *VideoFilters) ([]*m
 	}
 
 	query := fmt.Sprintf(`
-		SELECT video_id, channel_id, title, video_url, published_at, first_seen_at, last_updated_at, created_at, updated_at
+		SELECT `+videoColumns+`
 		FROM videos
 		%s
 		ORDER BY %s %s
@@ -337,22 +467,39 @@ func (r *videoRepository) List(ctx context.Context, filters *VideoFilters) ([]*m
 	return videos, total, nil
 }
 
+// videoColumns lists the videos columns read by scanVideo, in scan order
+const videoColumns = `video_id, channel_id, title, video_url, published_at, first_seen_at, last_updated_at, created_at, updated_at,
+		unavailable_at, unavailable_reason, availability_checked_at, deleted_at`
+
+// scanVideo scans a row selected with videoColumns
+func scanVideo(row pgx.Row) (*models.Video, error) {
+	video := &models.Video{}
+	err := row.Scan(
+		&video.VideoID,
+		&video.ChannelID,
+		&video.Title,
+		&video.VideoURL,
+		&video.PublishedAt,
+		&video.FirstSeenAt,
+		&video.LastUpdatedAt,
+		&video.CreatedAt,
+		&video.UpdatedAt,
+		&video.UnavailableAt,
+		&video.UnavailableReason,
+		&video.AvailabilityCheckedAt,
+		&video.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return video, nil
+}
+
 func scanVideos(rows pgx.Rows) ([]*models.Video, error) {
 	var videos []*models.Video
 
 	for rows.Next() {
-		video := &models.Video{}
-		err := rows.Scan(
-			&video.VideoID,
-			&video.ChannelID,
-			&video.Title,
-			&video.VideoURL,
-			&video.PublishedAt,
-			&video.FirstSeenAt,
-			&video.LastUpdatedAt,
-			&video.CreatedAt,
-			&video.UpdatedAt,
-		)
+		video, err := scanVideo(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan video: %w", err)
 		}
@@ -8,6 +8,7 @@ import (
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -130,6 +131,35 @@ func TestVideoRepository_GetVideoByID(t *testing.T) {
 	})
 }
 
+func TestVideoRepository_GetVideosByIDs(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	videoRepo := NewVideoRepository(td.Pool)
+	channelRepo := NewChannelRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel("UC123", "Test Channel", "https://youtube.com/channel/UC123")))
+	for _, videoID := range []string{"video1", "video2"} {
+		video := models.NewVideo(videoID, "UC123", "Test Video", "https://youtube.com/watch?v="+videoID, time.Now())
+		require.NoError(t, videoRepo.UpsertVideo(ctx, video))
+	}
+	require.NoError(t, videoRepo.SoftDelete(ctx, "video2", time.Now()))
+
+	videos, err := videoRepo.GetVideosByIDs(ctx, []string{"video1", "video2", "missing"})
+	require.NoError(t, err)
+	require.Len(t, videos, 2)
+	assert.Equal(t, "UC123", videos["video1"].ChannelID)
+	assert.NotNil(t, videos["video2"].DeletedAt, "soft-deleted videos are included")
+	assert.NotContains(t, videos, "missing")
+
+	empty, err := videoRepo.GetVideosByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
 func TestVideoRepository_GetVideosByChannelID(t *testing.T) {
 	td := testutil.SetupTestDatabase(t)
 	defer td.Cleanup(t)
@@ -245,6 +275,94 @@ func TestVideoRepository_ListVideos(t *testing.T) {
 	})
 }
 
+func TestVideoRepository_ListFiltersPremieres(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	videoRepo := NewVideoRepository(td.Pool)
+	channelRepo := NewChannelRepository(td.Pool)
+	enrichmentRepo := NewEnrichmentRepository(td.Pool)
+	ctx := context.Background()
+
+	channel := models.NewChannel("UC123", "Channel", "https://youtube.com/channel/UC123")
+	require.NoError(t, channelRepo.UpsertChannel(ctx, channel))
+	for _, videoID := range []string{"premiere", "aired", "unenriched"} {
+		video := models.NewVideo(videoID, "UC123", videoID, "https://youtube.com/watch?v="+videoID, time.Now())
+		require.NoError(t, videoRepo.UpsertVideo(ctx, video))
+	}
+
+	// "aired" was a premiere at its first enrichment; after airing it is no longer flagged
+	// but is still a premiere
+	now := time.Now()
+	require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "premiere", IsPremiere: true, EnrichedAt: now}))
+	require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "aired", IsPremiere: true, EnrichedAt: now.Add(-time.Hour)}))
+	require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "aired", EnrichedAt: now}))
+
+	isPremiere, notPremiere := true, false
+	premieres, total, err := videoRepo.List(ctx, &VideoFilters{Limit: 10, IsPremiere: &isPremiere, OrderBy: "video_id", OrderDir: "asc"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, premieres, 2)
+	assert.Equal(t, "aired", premieres[0].VideoID)
+	assert.Equal(t, "premiere", premieres[1].VideoID)
+
+	others, total, err := videoRepo.List(ctx, &VideoFilters{Limit: 10, IsPremiere: &notPremiere})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, others, 1)
+	assert.Equal(t, "unenriched", others[0].VideoID)
+}
+
+func TestVideoRepository_SoftDelete(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	videoRepo := NewVideoRepository(td.Pool)
+	channelRepo := NewChannelRepository(td.Pool)
+	enrichmentRepo := NewEnrichmentRepository(td.Pool)
+	ctx := context.Background()
+
+	channel := models.NewChannel("UC123", "Channel", "https://youtube.com/channel/UC123")
+	require.NoError(t, channelRepo.UpsertChannel(ctx, channel))
+	for _, videoID := range []string{"deleted", "kept"} {
+		video := models.NewVideo(videoID, "UC123", videoID, "https://youtube.com/watch?v="+videoID, time.Now())
+		require.NoError(t, videoRepo.UpsertVideo(ctx, video))
+	}
+	require.NoError(t, enrichmentRepo.CreateEnrichment(ctx, &model.VideoEnrichment{VideoID: "deleted", EnrichedAt: time.Now()}))
+
+	deletedAt := time.Now().Add(-time.Minute).Truncate(time.Microsecond)
+	require.NoError(t, videoRepo.SoftDelete(ctx, "deleted", deletedAt))
+	// A second soft delete keeps the original time
+	require.NoError(t, videoRepo.SoftDelete(ctx, "deleted", time.Now()))
+
+	videos, total, err := videoRepo.List(ctx, &VideoFilters{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, videos, 1)
+	assert.Equal(t, "kept", videos[0].VideoID)
+
+	byChannel, err := videoRepo.GetVideosByChannelID(ctx, "UC123", 10)
+	require.NoError(t, err)
+	assert.Len(t, byChannel, 1)
+
+	_, total, err = videoRepo.List(ctx, &VideoFilters{Limit: 10, IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	video, err := videoRepo.GetVideoByID(ctx, "deleted")
+	require.NoError(t, err)
+	require.NotNil(t, video.DeletedAt)
+	assert.True(t, deletedAt.Equal(*video.DeletedAt))
+
+	// History is kept
+	enrichment, err := enrichmentRepo.GetLatestEnrichment(ctx, "deleted")
+	require.NoError(t, err)
+	assert.Equal(t, "deleted", enrichment.VideoID)
+
+	err = videoRepo.SoftDelete(ctx, "missing", time.Now())
+	assert.True(t, db.IsNotFound(err))
+}
+
 func TestVideoRepository_GetVideosByPublishedDate(t *testing.T) {
 	td := testutil.SetupTestDatabase(t)
 	defer td.Cleanup(t)
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
@@ -14,7 +17,8 @@ import (
 // WebhookEventRepository defines operations for managing webhook events.
 // Note: webhook_events table is immutable - only inserts and marking as processed are allowed.
 type WebhookEventRepository interface {
-	// CreateWebhookEvent inserts a new webhook event.
+	// CreateWebhookEvent inserts a new webhook event. Its source is taken from ctx, see
+	// WithEventSource.
 	CreateWebhookEvent(ctx context.Context, rawXML, videoID, channelID string) (*models.WebhookEvent, error)
 
 	// Create inserts a new webhook event (for API).
@@ -27,6 +31,14 @@ type WebhookEventRepository interface {
 	// This is the only update operation allowed on webhook events.
 	MarkEventProcessed(ctx context.Context, eventID int64, processingError string) error
 
+	// MarkEventFailed marks a webhook event as processed with a categorized error.
+	MarkEventFailed(ctx context.Context, eventID int64, code models.ProcessingErrorCode, processingError string) error
+
+	// MarkEventsProcessed marks unprocessed webhook events as processed in batches.
+	// Events are selected by ID, by received_at before receivedBefore, or both; at least one is required.
+	// Returns the number of events updated.
+	MarkEventsProcessed(ctx context.Context, ids []int64, receivedBefore *time.Time) (int64, error)
+
 	// UpdateProcessingStatus updates only the processing-related fields of a webhook event.
 	UpdateProcessingStatus(ctx context.Context, eventID int64, processed bool, processingError string) error
 
@@ -38,6 +50,13 @@ type WebhookEventRepository interface {
 
 	// List retrieves webhook events with filters and pagination.
 	List(ctx context.Context, filters *WebhookEventFilters) ([]*models.WebhookEvent, int, error)
+
+	// GetEventsMissingVideoID retrieves events with a NULL video_id and an ID greater than afterID, ordered by ID.
+	GetEventsMissingVideoID(ctx context.Context, afterID int64, limit int) ([]*models.WebhookEvent, error)
+
+	// SetEventLinkage fills in a webhook event's video_id and channel_id where they are NULL.
+	// Values that are already set are left unchanged.
+	SetEventLinkage(ctx context.Context, eventID int64, videoID, channelID string) error
 }
 
 // WebhookEventFilters contains filter options for listing webhook events.
@@ -47,36 +66,104 @@ type WebhookEventFilters struct {
 	Processed *bool
 	VideoID   string
 	ChannelID string
+	ErrorCode models.ProcessingErrorCode
 	OrderBy   string
 	OrderDir  string
+
+	// After switches to keyset pagination: only events past this position in (received_at, id)
+	// order are returned, in OrderDir direction, and Offset is ignored. Unlike offsets, positions
+	// stay stable while new events arrive. OrderBy must be received_at (or empty).
+	After *WebhookEventCursor
+}
+
+// WebhookEventCursor is a position in the webhook event list ordered by (received_at, id)
+type WebhookEventCursor struct {
+	ReceivedAt time.Time
+	ID         int64
+}
+
+// NewWebhookEventCursor returns the cursor positioned at event, for fetching the events after it
+func NewWebhookEventCursor(event *models.WebhookEvent) *WebhookEventCursor {
+	return &WebhookEventCursor{ReceivedAt: event.ReceivedAt, ID: event.ID}
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c *WebhookEventCursor) Encode() string {
+	return encodeKeysetCursor(c.ReceivedAt, c.ID)
+}
+
+// ParseWebhookEventCursor decodes a cursor produced by Encode
+func ParseWebhookEventCursor(encoded string) (*WebhookEventCursor, error) {
+	receivedAt, id, err := decodeKeysetCursor(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookEventCursor{ReceivedAt: receivedAt, ID: id}, nil
+}
+
+type eventSourceKey struct{}
+
+// WithEventSource returns a context under which CreateWebhookEvent records events as coming
+// from source, e.g. models.EventSourcePoll.
+func WithEventSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, eventSourceKey{}, source)
+}
+
+// EventSourceFromContext returns the event source set with WithEventSource, or
+// models.EventSourceWebhook when none is set.
+func EventSourceFromContext(ctx context.Context) string {
+	if source, ok := ctx.Value(eventSourceKey{}).(string); ok && source != "" {
+		return source
+	}
+	return models.EventSourceWebhook
+}
+
+// DefaultMaxRawXMLSize is the suggested cap on stored raw_xml; notifications are a few KB
+const DefaultMaxRawXMLSize = 64 << 10
+
+// WebhookEventRepositoryConfig holds optional settings for the webhook event repository
+type WebhookEventRepositoryConfig struct {
+	// MaxRawXMLSize caps the bytes of raw_xml stored per event; longer bodies are stored
+	// truncated with raw_xml_truncated set, and hashed in full (0 = no limit)
+	MaxRawXMLSize int
 }
 
 type webhookEventRepository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	config WebhookEventRepositoryConfig
 }
 
 // NewWebhookEventRepository creates a new WebhookEventRepository.
 func NewWebhookEventRepository(pool *pgxpool.Pool) WebhookEventRepository {
-	return &webhookEventRepository{pool: pool}
+	return NewWebhookEventRepositoryWithConfig(pool, WebhookEventRepositoryConfig{})
+}
+
+// NewWebhookEventRepositoryWithConfig creates a new WebhookEventRepository with the given options.
+func NewWebhookEventRepositoryWithConfig(pool *pgxpool.Pool, config WebhookEventRepositoryConfig) WebhookEventRepository {
+	return &webhookEventRepository{pool: pool, config: config}
 }
 
 func (r *webhookEventRepository) CreateWebhookEvent(ctx context.Context, rawXML, videoID, channelID string) (*models.WebhookEvent, error) {
 	contentHash := db.GenerateContentHash(rawXML)
 	event := models.NewWebhookEvent(rawXML, contentHash, videoID, channelID)
+	event.Source = EventSourceFromContext(ctx)
+	event.TruncateRawXML(r.config.MaxRawXMLSize)
 
 	query := `
-		INSERT INTO webhook_events (raw_xml, content_hash, received_at, processed, video_id, channel_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO webhook_events (raw_xml, raw_xml_truncated, content_hash, received_at, processed, video_id, channel_id, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, received_at, created_at
 	`
 
 	err := r.pool.QueryRow(ctx, query,
 		event.RawXML,
+		event.RawXMLTruncated,
 		event.ContentHash,
 		event.ReceivedAt,
 		event.Processed,
 		event.VideoID,
 		event.ChannelID,
+		event.Source,
 		event.CreatedAt,
 	).Scan(&event.ID, &event.ReceivedAt, &event.CreatedAt)
 
@@ -89,8 +176,7 @@ func (r *webhookEventRepository) CreateWebhookEvent(ctx context.Context, rawXML,
 
 func (r *webhookEventRepository) GetUnprocessedEvents(ctx context.Context, limit int) ([]*models.WebhookEvent, error) {
 	query := `
-		SELECT id, raw_xml, content_hash, received_at, processed, processed_at,
-		       processing_error, video_id, channel_id, created_at
+		SELECT ` + webhookEventColumns + `
 		FROM webhook_events
 		WHERE NOT processed
 		ORDER BY received_at ASC
@@ -111,7 +197,8 @@ func (r *webhookEventRepository) MarkEventProcessed(ctx context.Context, eventID
 		UPDATE webhook_events
 		SET processed = true,
 		    processed_at = NOW(),
-		    processing_error = CASE WHEN $2 != '' THEN $2 ELSE NULL END
+		    processing_error = CASE WHEN $2 != '' THEN $2 ELSE NULL END,
+		    error_code = NULL
 		WHERE id = $1
 	`
 
@@ -127,28 +214,79 @@ func (r *webhookEventRepository) MarkEventProcessed(ctx context.Context, eventID
 	return nil
 }
 
+func (r *webhookEventRepository) MarkEventFailed(ctx context.Context, eventID int64, code models.ProcessingErrorCode, processingError string) error {
+	query := `
+		UPDATE webhook_events
+		SET processed = true,
+		    processed_at = NOW(),
+		    processing_error = NULLIF($2, ''),
+		    error_code = $3
+		WHERE id = $1
+	`
+
+	cmdTag, err := r.pool.Exec(ctx, query, eventID, processingError, string(code))
+	if err != nil {
+		return db.WrapError(err, "mark event failed")
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return db.WrapError(pgx.ErrNoRows, "mark event failed")
+	}
+
+	return nil
+}
+
+// markProcessedBatchSize bounds how many rows a single bulk mark-processed UPDATE touches
+const markProcessedBatchSize = 1000
+
+func (r *webhookEventRepository) MarkEventsProcessed(ctx context.Context, ids []int64, receivedBefore *time.Time) (int64, error) {
+	if len(ids) == 0 && receivedBefore == nil {
+		return 0, errors.New("mark events processed: ids or receivedBefore is required")
+	}
+
+	// A nil slice encodes as NULL so the ID filter is skipped when only receivedBefore is given
+	var idFilter []int64
+	if len(ids) > 0 {
+		idFilter = ids
+	}
+
+	query := `
+		UPDATE webhook_events
+		SET processed = true,
+		    processed_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM webhook_events
+			WHERE NOT processed
+			  AND ($1::bigint[] IS NULL OR id = ANY($1))
+			  AND ($2::timestamptz IS NULL OR received_at < $2)
+			ORDER BY id
+			LIMIT $3
+		)
+	`
+
+	var total int64
+	for {
+		cmdTag, err := r.pool.Exec(ctx, query, idFilter, receivedBefore, markProcessedBatchSize)
+		if err != nil {
+			return total, db.WrapError(err, "mark events processed")
+		}
+
+		total += cmdTag.RowsAffected()
+		if cmdTag.RowsAffected() < markProcessedBatchSize {
+			return total, nil
+		}
+	}
+}
+
 func (r *webhookEventRepository) GetEventByID(ctx context.Context, eventID int64) (*models.WebhookEvent, error) {
 	query := `
-		SELECT id, raw_xml, content_hash, received_at, processed, processed_at,
-		       processing_error, video_id, channel_id, created_at
+		SELECT ` + webhookEventColumns + `
 		FROM webhook_events
 		WHERE id = $1
 	`
 
-	event := &models.WebhookEvent{}
-	err := r.pool.QueryRow(ctx, query, eventID).Scan(
-		&event.ID,
-		&event.RawXML,
-		&event.ContentHash,
-		&event.ReceivedAt,
-		&event.Processed,
-		&event.ProcessedAt,
-		&event.ProcessingError,
-		&event.VideoID,
-		&event.ChannelID,
-		&event.CreatedAt,
-	)
-
+	event, err := scanWebhookEvent(r.pool.QueryRow(ctx, query, eventID))
 	if err != nil {
 		return nil, db.WrapError(err, "get event by id")
 	}
@@ -158,8 +296,7 @@ func (r *webhookEventRepository) GetEventByID(ctx context.Context, eventID int64
 
 func (r *webhookEventRepository) GetEventsByVideoID(ctx context.Context, videoID string) ([]*models.WebhookEvent, error) {
 	query := `
-		SELECT id, raw_xml, content_hash, received_at, processed, processed_at,
-		       processing_error, video_id, channel_id, created_at
+		SELECT ` + webhookEventColumns + `
 		FROM webhook_events
 		WHERE video_id = $1
 		ORDER BY received_at DESC
@@ -178,20 +315,26 @@ func (r *webhookEventRepository) Create(ctx context.Context, event *models.Webho
 	if event.ContentHash == "" {
 		event.ContentHash = db.GenerateContentHash(event.RawXML)
 	}
+	if event.Source == "" {
+		event.Source = models.EventSourceWebhook
+	}
+	event.TruncateRawXML(r.config.MaxRawXMLSize)
 
 	query := `
-		INSERT INTO webhook_events (raw_xml, content_hash, received_at, processed, video_id, channel_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO webhook_events (raw_xml, raw_xml_truncated, content_hash, received_at, processed, video_id, channel_id, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, received_at, created_at
 	`
 
 	err := r.pool.QueryRow(ctx, query,
 		event.RawXML,
+		event.RawXMLTruncated,
 		event.ContentHash,
 		event.ReceivedAt,
 		event.Processed,
 		event.VideoID,
 		event.ChannelID,
+		event.Source,
 		event.CreatedAt,
 	).Scan(&event.ID, &event.ReceivedAt, &event.CreatedAt)
 
@@ -207,7 +350,8 @@ func (r *webhookEventRepository) UpdateProcessingStatus(ctx context.Context, eve
 		UPDATE webhook_events
 		SET processed = $1,
 		    processed_at = CASE WHEN $1 = true THEN NOW() ELSE processed_at END,
-		    processing_error = CASE WHEN $2 != '' THEN $2 ELSE NULL END
+		    processing_error = CASE WHEN $2 != '' THEN $2 ELSE NULL END,
+		    error_code = CASE WHEN $2 != '' THEN error_code ELSE NULL END
 		WHERE id = $3
 	`
 
@@ -248,6 +392,12 @@ func (r *webhookEventRepository) List(ctx context.Context, filters *WebhookEvent
 		argPos++
 	}
 
+	if filters.ErrorCode != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("error_code = $%d", argPos))
+		args = append(args, string(filters.ErrorCode))
+		argPos++
+	}
+
 	if len(whereClauses) > 0 {
 		whereClause = "WHERE " + whereClauses[0]
 		for i := 1; i < len(whereClauses); i++ {
@@ -272,16 +422,43 @@ func (r *webhookEventRepository) List(ctx context.Context, filters *WebhookEvent
 		return nil, 0, db.WrapError(err, "count webhook events")
 	}
 
+	// Events received at the same instant are ordered by ID so pages never overlap or skip
+	orderClause := fmt.Sprintf("%s %s", orderBy, orderDir)
+	if orderBy == "received_at" {
+		orderClause += fmt.Sprintf(", id %s", orderDir)
+	}
+
+	offset := filters.Offset
+	if filters.After != nil {
+		if orderBy != "received_at" {
+			return nil, 0, fmt.Errorf("cursor pagination requires ordering by received_at, got %q", orderBy)
+		}
+
+		comparison := "<"
+		if strings.EqualFold(orderDir, "ASC") {
+			comparison = ">"
+		}
+
+		keysetClause := fmt.Sprintf("(received_at, id) %s ($%d, $%d)", comparison, argPos, argPos+1)
+		if whereClause == "" {
+			whereClause = "WHERE " + keysetClause
+		} else {
+			whereClause += " AND " + keysetClause
+		}
+		args = append(args, filters.After.ReceivedAt, filters.After.ID)
+		argPos += 2
+		offset = 0
+	}
+
 	query := fmt.Sprintf(`
-		SELECT id, raw_xml, content_hash, received_at, processed, processed_at,
-		       processing_error, video_id, channel_id, created_at
+		SELECT `+webhookEventColumns+`
 		FROM webhook_events
 		%s
-		ORDER BY %s %s
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, orderBy, orderDir, argPos, argPos+1)
+	`, whereClause, orderClause, argPos, argPos+1)
 
-	args = append(args, filters.Limit, filters.Offset)
+	args = append(args, filters.Limit, offset)
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -297,24 +474,78 @@ func (r *webhookEventRepository) List(ctx context.Context, filters *WebhookEvent
 	return events, total, nil
 }
 
+func (r *webhookEventRepository) GetEventsMissingVideoID(ctx context.Context, afterID int64, limit int) ([]*models.WebhookEvent, error) {
+	query := `
+		SELECT ` + webhookEventColumns + `
+		FROM webhook_events
+		WHERE video_id IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, db.WrapError(err, "get events missing video id")
+	}
+	defer rows.Close()
+
+	return scanWebhookEvents(rows)
+}
+
+func (r *webhookEventRepository) SetEventLinkage(ctx context.Context, eventID int64, videoID, channelID string) error {
+	query := `
+		UPDATE webhook_events
+		SET video_id = COALESCE(video_id, NULLIF($2, '')),
+		    channel_id = COALESCE(channel_id, NULLIF($3, ''))
+		WHERE id = $1
+	`
+
+	cmdTag, err := r.pool.Exec(ctx, query, eventID, videoID, channelID)
+	if err != nil {
+		return db.WrapError(err, "set event linkage")
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return db.ErrNotFound
+	}
+
+	return nil
+}
+
+// webhookEventColumns lists the webhook_events columns read by scanWebhookEvent, in scan order
+const webhookEventColumns = `id, raw_xml, raw_xml_truncated, content_hash, received_at, processed, processed_at,
+		       processing_error, error_code, video_id, channel_id, source, created_at`
+
+// scanWebhookEvent scans a row selected with webhookEventColumns
+func scanWebhookEvent(row pgx.Row) (*models.WebhookEvent, error) {
+	event := &models.WebhookEvent{}
+	err := row.Scan(
+		&event.ID,
+		&event.RawXML,
+		&event.RawXMLTruncated,
+		&event.ContentHash,
+		&event.ReceivedAt,
+		&event.Processed,
+		&event.ProcessedAt,
+		&event.ProcessingError,
+		&event.ErrorCode,
+		&event.VideoID,
+		&event.ChannelID,
+		&event.Source,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
 // Helper function to scan multiple webhook events from query results
 func scanWebhookEvents(rows pgx.Rows) ([]*models.WebhookEvent, error) {
 	var events []*models.WebhookEvent
 
 	for rows.Next() {
-		event := &models.WebhookEvent{}
-		err := rows.Scan(
-			&event.ID,
-			&event.RawXML,
-			&event.ContentHash,
-			&event.ReceivedAt,
-			&event.Processed,
-			&event.ProcessedAt,
-			&event.ProcessingError,
-			&event.VideoID,
-			&event.ChannelID,
-			&event.CreatedAt,
-		)
+		event, err := scanWebhookEvent(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan webhook event: %w", err)
 		}
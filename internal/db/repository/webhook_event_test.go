@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -50,6 +53,53 @@ func TestWebhookEventRepository_CreateWebhookEvent(t *testing.T) {
 	})
 }
 
+func TestWebhookEventRepository_CreateWebhookEvent_MaxRawXMLSize(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewWebhookEventRepositoryWithConfig(td.Pool, WebhookEventRepositoryConfig{MaxRawXMLSize: 64})
+	ctx := context.Background()
+
+	rawXML := `<feed xmlns="http://www.w3.org/2005/Atom">` + strings.Repeat("<entry/>", 100) + `</feed>`
+	event, err := repo.CreateWebhookEvent(ctx, rawXML, "testVideoID", "testChannelID")
+	require.NoError(t, err)
+	assert.Equal(t, db.GenerateContentHash(rawXML), event.ContentHash, "the hash covers the full body")
+
+	stored, err := repo.GetEventByID(ctx, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rawXML[:64], stored.RawXML)
+	assert.True(t, stored.RawXMLTruncated)
+
+	// A repeat delivery of the same oversized body is still a duplicate
+	_, err = repo.CreateWebhookEvent(ctx, rawXML, "testVideoID", "testChannelID")
+	assert.True(t, db.IsDuplicateKey(err))
+
+	small, err := repo.CreateWebhookEvent(ctx, "<feed/>", "otherVideoID", "testChannelID")
+	require.NoError(t, err)
+	assert.False(t, small.RawXMLTruncated)
+}
+
+func TestWebhookEventRepository_CreateWebhookEvent_Source(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewWebhookEventRepository(td.Pool)
+	ctx := context.Background()
+
+	delivered, err := repo.CreateWebhookEvent(ctx, "<feed>delivered</feed>", "deliveredID", "testChannelID")
+	require.NoError(t, err)
+	polled, err := repo.CreateWebhookEvent(WithEventSource(ctx, models.EventSourcePoll), "<feed>polled</feed>", "polledID", "testChannelID")
+	require.NoError(t, err)
+
+	stored, err := repo.GetEventByID(ctx, delivered.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventSourceWebhook, stored.Source)
+
+	stored, err = repo.GetEventByID(ctx, polled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventSourcePoll, stored.Source)
+}
+
 func TestWebhookEventRepository_GetUnprocessedEvents(t *testing.T) {
 	td := testutil.SetupTestDatabase(t)
 	defer td.Cleanup(t)
@@ -151,6 +201,95 @@ func TestWebhookEventRepository_MarkEventProcessed(t *testing.T) {
 		require.Error(t, err)
 		assert.True(t, db.IsNotFound(err))
 	})
+
+	t.Run("marks event as failed with error code", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		failed, err := repo.CreateWebhookEvent(ctx, "<feed>broken</feed>", "", "")
+		require.NoError(t, err)
+		ok, err := repo.CreateWebhookEvent(ctx, "<feed>ok</feed>", "video1", "channel1")
+		require.NoError(t, err)
+
+		require.NoError(t, repo.MarkEventFailed(ctx, failed.ID, models.ErrorCodeParse, "parse atom feed: EOF"))
+		require.NoError(t, repo.MarkEventProcessed(ctx, ok.ID, ""))
+
+		retrieved, err := repo.GetEventByID(ctx, failed.ID)
+		require.NoError(t, err)
+		assert.True(t, retrieved.Processed)
+		assert.Equal(t, "parse_error", retrieved.ErrorCode.String)
+		assert.Equal(t, "parse atom feed: EOF", retrieved.ProcessingError.String)
+
+		events, total, err := repo.List(ctx, &WebhookEventFilters{Limit: 10, ErrorCode: models.ErrorCodeParse})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, events, 1)
+		assert.Equal(t, failed.ID, events[0].ID)
+	})
+}
+
+func TestWebhookEventRepository_MarkEventsProcessed(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewWebhookEventRepository(td.Pool)
+	ctx := context.Background()
+
+	t.Run("marks selected events by ID", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		event1, err := repo.CreateWebhookEvent(ctx, "<feed>1</feed>", "video1", "channel1")
+		require.NoError(t, err)
+		event2, err := repo.CreateWebhookEvent(ctx, "<feed>2</feed>", "video2", "channel1")
+		require.NoError(t, err)
+		event3, err := repo.CreateWebhookEvent(ctx, "<feed>3</feed>", "video3", "channel1")
+		require.NoError(t, err)
+
+		// Already processed events are not counted again
+		require.NoError(t, repo.MarkEventProcessed(ctx, event2.ID, ""))
+
+		updated, err := repo.MarkEventsProcessed(ctx, []int64{event1.ID, event2.ID}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		retrieved, err := repo.GetEventByID(ctx, event1.ID)
+		require.NoError(t, err)
+		assert.True(t, retrieved.Processed)
+		assert.True(t, retrieved.ProcessedAt.Valid)
+
+		retrieved, err = repo.GetEventByID(ctx, event3.ID)
+		require.NoError(t, err)
+		assert.False(t, retrieved.Processed)
+	})
+
+	t.Run("marks events received before a timestamp", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.CreateWebhookEvent(ctx, "<feed>old"+string(rune('0'+i))+"</feed>", "video", "channel")
+			require.NoError(t, err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		cutoff := time.Now()
+		time.Sleep(10 * time.Millisecond)
+
+		newer, err := repo.CreateWebhookEvent(ctx, "<feed>new</feed>", "video", "channel")
+		require.NoError(t, err)
+
+		updated, err := repo.MarkEventsProcessed(ctx, nil, &cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), updated)
+
+		unprocessed, err := repo.GetUnprocessedEvents(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, unprocessed, 1)
+		assert.Equal(t, newer.ID, unprocessed[0].ID)
+	})
+
+	t.Run("requires a selector", func(t *testing.T) {
+		_, err := repo.MarkEventsProcessed(ctx, nil, nil)
+		require.Error(t, err)
+	})
 }
 
 func TestWebhookEventRepository_GetEventByID(t *testing.T) {
@@ -214,6 +353,56 @@ func TestWebhookEventRepository_GetEventsByVideoID(t *testing.T) {
 	})
 }
 
+func TestWebhookEventRepository_SetEventLinkage(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewWebhookEventRepository(td.Pool)
+	ctx := context.Background()
+
+	t.Run("backfills missing linkage once", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		unlinked, err := repo.CreateWebhookEvent(ctx, "<feed>unlinked</feed>", "", "")
+		require.NoError(t, err)
+		_, err = repo.CreateWebhookEvent(ctx, "<feed>linked</feed>", "video1", "channel1")
+		require.NoError(t, err)
+
+		events, err := repo.GetEventsMissingVideoID(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, unlinked.ID, events[0].ID)
+
+		err = repo.SetEventLinkage(ctx, unlinked.ID, "video2", "channel2")
+		require.NoError(t, err)
+
+		event, err := repo.GetEventByID(ctx, unlinked.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "video2", event.VideoID.String)
+		assert.Equal(t, "channel2", event.ChannelID.String)
+
+		// Existing linkage is never overwritten
+		err = repo.SetEventLinkage(ctx, unlinked.ID, "video3", "channel3")
+		require.NoError(t, err)
+
+		event, err = repo.GetEventByID(ctx, unlinked.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "video2", event.VideoID.String)
+		assert.Equal(t, "channel2", event.ChannelID.String)
+
+		events, err = repo.GetEventsMissingVideoID(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("returns not found for missing event", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		err := repo.SetEventLinkage(ctx, 99999, "video1", "channel1")
+		assert.ErrorIs(t, err, db.ErrNotFound)
+	})
+}
+
 func TestWebhookEventRepository_ImmutabilityProtection(t *testing.T) {
 	td := testutil.SetupTestDatabase(t)
 	defer td.Cleanup(t)
@@ -271,3 +460,91 @@ func TestWebhookEventRepository_ContentHashGeneration(t *testing.T) {
 	assert.Equal(t, hash1, hash3, "same content should have same hash")
 	assert.Len(t, hash1, 64, "SHA-256 hash should be 64 characters")
 }
+
+func TestWebhookEventRepository_ListCursor(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	repo := NewWebhookEventRepository(td.Pool)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	createAt := func(t *testing.T, n int, receivedAt time.Time) *models.WebhookEvent {
+		t.Helper()
+		event := models.NewWebhookEvent(fmt.Sprintf("<feed>%d</feed>", n), "", "video1", "channel1")
+		event.ReceivedAt = receivedAt
+		require.NoError(t, repo.Create(ctx, event))
+		return event
+	}
+
+	t.Run("iterates forward without gaps or repeats while events arrive", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		// Events 2 and 3 share a timestamp, so the ID tiebreak decides their order
+		var want []int64
+		for i, offset := range []time.Duration{0, time.Second, 2 * time.Second, 2 * time.Second, 3 * time.Second} {
+			want = append(want, createAt(t, i, base.Add(offset)).ID)
+		}
+
+		var got []int64
+		var after *WebhookEventCursor
+		for page := 0; ; page++ {
+			events, total, err := repo.List(ctx, &WebhookEventFilters{Limit: 2, OrderDir: "ASC", After: after})
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, total, len(want))
+			if len(events) == 0 {
+				break
+			}
+			for _, event := range events {
+				got = append(got, event.ID)
+			}
+
+			// Newly received events sort after everything already listed, and events
+			// backfilled before the cursor do not shift later pages
+			createAt(t, 100+page, time.Now().Add(time.Hour))
+			createAt(t, 200+page, base.Add(-time.Minute))
+
+			after, err = ParseWebhookEventCursor(NewWebhookEventCursor(events[len(events)-1]).Encode())
+			require.NoError(t, err)
+
+			if len(got) >= len(want) {
+				break
+			}
+		}
+
+		// The last page may already reach the events that arrived during iteration
+		require.GreaterOrEqual(t, len(got), len(want))
+		assert.Equal(t, want, got[:len(want)])
+	})
+
+	t.Run("descending cursor continues into older events", func(t *testing.T) {
+		td.TruncateTables(t)
+
+		older := createAt(t, 1, base)
+		newer := createAt(t, 2, base.Add(time.Second))
+
+		events, _, err := repo.List(ctx, &WebhookEventFilters{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, newer.ID, events[0].ID)
+
+		events, _, err = repo.List(ctx, &WebhookEventFilters{Limit: 1, Offset: 5, After: NewWebhookEventCursor(events[0])})
+		require.NoError(t, err)
+		require.Len(t, events, 1, "offset is ignored with a cursor")
+		assert.Equal(t, older.ID, events[0].ID)
+	})
+}
+
+func TestParseWebhookEventCursor(t *testing.T) {
+	cursor := &WebhookEventCursor{ReceivedAt: time.Date(2025, 11, 18, 10, 30, 0, 123456000, time.UTC), ID: 42}
+
+	parsed, err := ParseWebhookEventCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.ReceivedAt.Equal(parsed.ReceivedAt))
+	assert.Equal(t, int64(42), parsed.ID)
+
+	for _, invalid := range []string{"not base64!", "bm9jb2xvbg", "YWJjOjEy"} {
+		_, err := ParseWebhookEventCursor(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCursor, invalid)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -52,8 +53,7 @@ func SetupTestDatabase(t *testing.T) *TestDatabase {
 	require.NoError(t, err)
 
 	// Run migrations
-	migrationsPath, err := filepath.Abs("../../../migrations")
-	require.NoError(t, err)
+	migrationsPath := migrationsDir()
 
 	m, err := migrate.New(
 		fmt.Sprintf("file://%s", migrationsPath),
@@ -104,7 +104,8 @@ func (td *TestDatabase) TruncateTables(t *testing.T) {
 
 	// Truncate all tables
 	_, err = td.Pool.Exec(ctx, `
-		TRUNCATE TABLE video_updates, videos, channels, webhook_events, pubsub_subscriptions RESTART IDENTITY CASCADE;
+		TRUNCATE TABLE video_sponsors, sponsor_detection_jobs, sponsors, sponsor_detection_prompts,
+			video_updates, videos, channels, webhook_events, pubsub_subscriptions, audit_log RESTART IDENTITY CASCADE;
 	`)
 	require.NoError(t, err)
 
@@ -112,3 +113,10 @@ func (td *TestDatabase) TruncateTables(t *testing.T) {
 	_, err = td.Pool.Exec(ctx, "SET session_replication_role = DEFAULT;")
 	require.NoError(t, err)
 }
+
+// migrationsDir locates the repository's migrations relative to this file, so packages at any
+// depth can set up a test database
+func migrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "migrations")
+}
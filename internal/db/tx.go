@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TxBeginner starts transactions. It is implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx
+// (the latter starting a savepoint).
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithTx runs fn in a transaction that is committed if fn returns nil and rolled back if
+// it returns an error or panics. fn's error is returned unchanged; begin and commit
+// failures are wrapped with WrapError. A panic is re-raised after the rollback.
+func WithTx(ctx context.Context, beginner TxBeginner, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return WrapError(err, "begin transaction")
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			// The rollback error is dropped in favour of the error that caused it
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return WrapError(err, "commit transaction")
+	}
+
+	return nil
+}
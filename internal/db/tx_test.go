@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTx records whether it was committed or rolled back
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (f *fakeTx) Commit(ctx context.Context) error {
+	if f.commitErr != nil {
+		return f.commitErr
+	}
+	f.committed = true
+	return nil
+}
+
+func (f *fakeTx) Rollback(ctx context.Context) error {
+	f.rolledBack = true
+	return nil
+}
+
+type fakeBeginner struct {
+	tx  *fakeTx
+	err error
+}
+
+func (f *fakeBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.tx, nil
+}
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits on success", func(t *testing.T) {
+		beginner := &fakeBeginner{tx: &fakeTx{}}
+
+		var got pgx.Tx
+		err := WithTx(ctx, beginner, func(tx pgx.Tx) error {
+			got = tx
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Same(t, beginner.tx, got)
+		assert.True(t, beginner.tx.committed)
+		assert.False(t, beginner.tx.rolledBack)
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		beginner := &fakeBeginner{tx: &fakeTx{}}
+		fnErr := errors.New("insert failed")
+
+		err := WithTx(ctx, beginner, func(tx pgx.Tx) error {
+			return fnErr
+		})
+
+		assert.Same(t, fnErr, err)
+		assert.False(t, beginner.tx.committed)
+		assert.True(t, beginner.tx.rolledBack)
+	})
+
+	t.Run("rolls back and re-panics on panic", func(t *testing.T) {
+		beginner := &fakeBeginner{tx: &fakeTx{}}
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = WithTx(ctx, beginner, func(tx pgx.Tx) error {
+				panic("boom")
+			})
+		})
+		assert.False(t, beginner.tx.committed)
+		assert.True(t, beginner.tx.rolledBack)
+	})
+
+	t.Run("rolls back when commit fails", func(t *testing.T) {
+		beginner := &fakeBeginner{tx: &fakeTx{commitErr: errors.New("connection reset")}}
+
+		err := WithTx(ctx, beginner, func(tx pgx.Tx) error {
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "commit transaction")
+		assert.True(t, beginner.tx.rolledBack)
+	})
+
+	t.Run("does not call fn when begin fails", func(t *testing.T) {
+		beginner := &fakeBeginner{err: errors.New("pool closed")}
+
+		called := false
+		err := WithTx(ctx, beginner, func(tx pgx.Tx) error {
+			called = true
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "begin transaction")
+		assert.False(t, called)
+	})
+}
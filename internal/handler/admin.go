@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// DBStatsResponse is the response for GET /api/v1/admin/db-stats
+type DBStatsResponse struct {
+	Tables         []*model.TableStats `json:"tables"`
+	TotalSizeBytes int64               `json:"total_size_bytes"`
+}
+
+// AdminHandler serves operator endpoints under /api/v1/admin
+type AdminHandler struct {
+	statsRepo repository.DBStatsRepository
+	auditRepo repository.AuditLogRepository
+	logger    *slog.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(statsRepo repository.DBStatsRepository, logger *slog.Logger) *AdminHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AdminHandler{
+		statsRepo: statsRepo,
+		logger:    logger,
+	}
+}
+
+// SetAuditLogRepository enables GET /api/v1/admin/audit
+func (h *AdminHandler) SetAuditLogRepository(auditRepo repository.AuditLogRepository) {
+	h.auditRepo = auditRepo
+}
+
+// ServeHTTP handles GET /api/v1/admin/db-stats and GET /api/v1/admin/audit requests
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin"), "/")
+
+	if path != "db-stats" && (path != "audit" || h.auditRepo == nil) {
+		sendError(w, http.StatusNotFound, "not found", "", nil)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	if path == "audit" {
+		h.handleListAudit(w, r)
+		return
+	}
+
+	h.handleDBStats(w, r)
+}
+
+func (h *AdminHandler) handleDBStats(w http.ResponseWriter, r *http.Request) {
+	tables, err := h.statsRepo.GetTableStats(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get table stats", "error", err)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve database stats", nil)
+		return
+	}
+
+	if tables == nil {
+		tables = []*model.TableStats{}
+	}
+
+	response := DBStatsResponse{Tables: tables}
+	for _, t := range tables {
+		response.TotalSizeBytes += t.TotalSizeBytes
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
+
+// handleListAudit lists audit log entries, newest first
+func (h *AdminHandler) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	limit := parseLimit(r)
+	offset := parseOffset(r)
+
+	since, err := parseTimestamp(r, "since")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	query := r.URL.Query()
+	filters := &repository.AuditLogFilters{
+		Limit:        limit,
+		Offset:       offset,
+		APIKeyID:     query.Get("api_key_id"),
+		Actor:        query.Get("actor"),
+		Action:       query.Get("action"),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+		Since:        since,
+	}
+
+	entries, total, err := h.auditRepo.List(r.Context(), filters)
+	if err != nil {
+		h.logger.Error("failed to list audit entries", "error", err)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to list audit entries", nil)
+		return
+	}
+
+	if entries == nil {
+		entries = []*model.AuditEntry{}
+	}
+
+	response := map[string]interface{}{
+		"items":  entries,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+type mockDBStatsRepo struct {
+	stats []*model.TableStats
+	err   error
+}
+
+func (m *mockDBStatsRepo) GetTableStats(ctx context.Context) ([]*model.TableStats, error) {
+	return m.stats, m.err
+}
+
+func TestAdminHandler_DBStats(t *testing.T) {
+	repo := &mockDBStatsRepo{stats: []*model.TableStats{
+		{TableName: "videos", ApproxRowCount: 10, TotalSizeBytes: 8192},
+		{TableName: "webhook_events", ApproxRowCount: 20, TotalSizeBytes: 16384},
+	}}
+	h := NewAdminHandler(repo, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/db-stats", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response DBStatsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Tables) != 2 || response.Tables[1].TableName != "webhook_events" {
+		t.Errorf("unexpected tables: %+v", response.Tables)
+	}
+	if response.TotalSizeBytes != 24576 {
+		t.Errorf("expected total size 24576, got %d", response.TotalSizeBytes)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/db-stats", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for POST, got %d", rec.Code)
+	}
+
+	repo.err = errors.New("connection refused")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/db-stats", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 on repository error, got %d", rec.Code)
+	}
+}
+
+// mockAuditLogRepo stores audit entries in memory, newest last
+type mockAuditLogRepo struct {
+	entries []*model.AuditEntry
+	filters *repository.AuditLogFilters
+}
+
+func (m *mockAuditLogRepo) Record(ctx context.Context, entry *model.AuditEntry) error {
+	entry.ID = int64(len(m.entries) + 1)
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *mockAuditLogRepo) List(ctx context.Context, filters *repository.AuditLogFilters) ([]*model.AuditEntry, int, error) {
+	m.filters = filters
+	return m.entries, len(m.entries), nil
+}
+
+func TestAdminHandler_Audit(t *testing.T) {
+	h := NewAdminHandler(&mockDBStatsRepo{}, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 without an audit repository, got %d", rec.Code)
+	}
+
+	auditRepo := &mockAuditLogRepo{entries: []*model.AuditEntry{
+		{ID: 1, Action: model.AuditActionDelete, ResourceType: "video", ResourceID: "video123"},
+	}}
+	h.SetAuditLogRepository(auditRepo)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?resource_type=video&actor=alice&limit=10", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Items []*model.AuditEntry `json:"items"`
+		Total int                 `json:"total"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Total != 1 || len(response.Items) != 1 || response.Items[0].ResourceID != "video123" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if auditRepo.filters.ResourceType != "video" || auditRepo.filters.Actor != "alice" || auditRepo.filters.Limit != 10 {
+		t.Errorf("unexpected filters: %+v", auditRepo.filters)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?since=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid since, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/middleware"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// HeaderActor optionally names the person or system behind a (possibly shared) API key.
+// It is recorded in the audit log next to the API key ID.
+const HeaderActor = "X-Actor"
+
+// maxActorLength matches the audit_log.actor column, which counts characters
+const maxActorLength = 255
+
+// Audit log resource types
+const (
+	auditResourceChannel      = "channel"
+	auditResourceVideo        = "video"
+	auditResourceSubscription = "subscription"
+	auditResourceSponsor      = "sponsor"
+
+	auditResourceSponsorDetectionJob = "sponsor_detection_job"
+)
+
+// auditTrail records mutating API operations. The zero value records nothing.
+type auditTrail struct {
+	repo   repository.AuditLogRepository
+	logger *slog.Logger
+}
+
+// enabled reports whether entries are recorded, so handlers can skip fetching
+// a resource's previous state when nobody will read it
+func (a auditTrail) enabled() bool {
+	return a.repo != nil
+}
+
+// auditActor identifies who made a change: the API key and the X-Actor header of the request
+type auditActor struct {
+	apiKeyID *string
+	actor    *string
+}
+
+// actorFromRequest returns the actor of r, to record changes made after r has been answered,
+// such as those of background jobs
+func actorFromRequest(r *http.Request) auditActor {
+	var a auditActor
+	if keyID := middleware.APIKeyIDFromContext(r.Context()); keyID != "" {
+		a.apiKeyID = &keyID
+	}
+	if actor := r.Header.Get(HeaderActor); actor != "" {
+		actor = truncateActor(actor)
+		a.actor = &actor
+	}
+	return a
+}
+
+// truncateActor cuts an X-Actor value to maxActorLength characters. Invalid UTF-8, which the
+// database would reject, is replaced first, so the cut always falls on a character boundary.
+func truncateActor(actor string) string {
+	actor = strings.ToValidUTF8(actor, "\uFFFD")
+
+	chars := 0
+	for i := range actor {
+		if chars == maxActorLength {
+			return actor[:i]
+		}
+		chars++
+	}
+	return actor
+}
+
+// record stores an audit entry for a successful mutation. before and after are the resource
+// as the API returns it; pass nil for a create's before or a delete's after. Failures are
+// logged and never fail the request.
+func (a auditTrail) record(r *http.Request, action, resourceType, resourceID string, before, after interface{}) {
+	a.recordFor(r.Context(), actorFromRequest(r), action, resourceType, resourceID, before, after)
+}
+
+// recordFor is record for a change made on behalf of actor outside its request.
+func (a auditTrail) recordFor(ctx context.Context, actor auditActor, action, resourceType, resourceID string, before, after interface{}) {
+	if a.repo == nil {
+		return
+	}
+
+	entry := &model.AuditEntry{
+		APIKeyID:     actor.apiKeyID,
+		Actor:        actor.actor,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       a.snapshot(before),
+		After:        a.snapshot(after),
+	}
+
+	if err := a.repo.Record(ctx, entry); err != nil {
+		a.logger.Error("failed to record audit entry",
+			"error", err,
+			"action", action,
+			"resource_type", resourceType,
+			"resource_id", resourceID,
+		)
+	}
+}
+
+// snapshot marshals a resource for the audit log, returning nil for nil resources
+func (a auditTrail) snapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	doc, err := json.Marshal(v)
+	if err != nil {
+		a.logger.Warn("failed to marshal audit snapshot", "error", err)
+		return nil
+	}
+	if bytes.Equal(doc, []byte("null")) {
+		return nil
+	}
+
+	return doc
+}
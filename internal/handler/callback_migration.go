@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// Callback migration job statuses
+const (
+	MigrationStatusRunning   = "running"
+	MigrationStatusCompleted = "completed"
+	MigrationStatusFailed    = "failed"
+	MigrationStatusCancelled = "cancelled"
+)
+
+const (
+	// migrationPageSize is how many subscriptions a callback migration lists at a time
+	migrationPageSize = 500
+
+	// maxRetainedMigrations is how many finished migration jobs are kept for status lookups
+	maxRetainedMigrations = 20
+)
+
+// migrationStatuses are the subscription statuses a callback migration moves. Abandoned
+// and expired subscriptions are no longer renewed, so they are left on the old callback.
+var migrationStatuses = []string{models.StatusActive, models.StatusPending}
+
+// MigrationResult is the outcome of migrating one subscription.
+type MigrationResult struct {
+	SubscriptionID   int64  `json:"subscription_id"`
+	ChannelID        string `json:"channel_id"`
+	Success          bool   `json:"success"`
+	UnsubscribeError string `json:"unsubscribe_error,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// CallbackMigration is a callback migration job. Jobs run in the server process and are
+// kept in memory, so a job's status is lost when the server restarts.
+type CallbackMigration struct {
+	ID             string            `json:"job_id"`
+	Status         string            `json:"status"`
+	OldCallbackURL string            `json:"old_callback_url"`
+	NewCallbackURL string            `json:"new_callback_url"`
+	DryRun         bool              `json:"dry_run"`
+	Total          int               `json:"total"`
+	Processed      int               `json:"processed"`
+	Successful     int               `json:"successful"`
+	Failed         int               `json:"failed"`
+	Error          string            `json:"error,omitempty"`
+	Results        []MigrationResult `json:"results"`
+	StartedAt      time.Time         `json:"started_at"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
+}
+
+// callbackMigrations runs callback migrations in the background and keeps their progress.
+type callbackMigrations struct {
+	mu   sync.Mutex
+	jobs map[string]*CallbackMigration
+	// finished lists finished job IDs, oldest first, for pruning
+	finished []string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newCallbackMigrations() *callbackMigrations {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &callbackMigrations{
+		jobs:   make(map[string]*CallbackMigration),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// get returns a copy of the job with the given ID.
+func (m *callbackMigrations) get(id string) (CallbackMigration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return CallbackMigration{}, false
+	}
+	snapshot := *job
+	snapshot.Results = append([]MigrationResult(nil), job.Results...)
+	return snapshot, true
+}
+
+// start registers a job and runs fn for it on a background goroutine. fn reports progress
+// through update and returns the job's error, if any.
+func (m *callbackMigrations) start(job *CallbackMigration, fn func(ctx context.Context, update func(func(*CallbackMigration))) error) {
+	job.ID = uuid.NewString()
+	job.Status = MigrationStatusRunning
+	job.StartedAt = time.Now()
+	job.Results = []MigrationResult{}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	update := func(apply func(*CallbackMigration)) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		apply(job)
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		err := fn(m.ctx, update)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		now := time.Now()
+		job.CompletedAt = &now
+		switch {
+		case err == nil:
+			job.Status = MigrationStatusCompleted
+		case m.ctx.Err() != nil:
+			job.Status = MigrationStatusCancelled
+			job.Error = err.Error()
+		default:
+			job.Status = MigrationStatusFailed
+			job.Error = err.Error()
+		}
+
+		m.finished = append(m.finished, job.ID)
+		for len(m.finished) > maxRetainedMigrations {
+			delete(m.jobs, m.finished[0])
+			m.finished = m.finished[1:]
+		}
+	}()
+}
+
+// shutdown cancels running jobs and waits for them to stop, or for ctx to end.
+func (m *callbackMigrations) shutdown(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// listMigratableSubscriptions pages through every active and pending subscription. All rows
+// are listed before any is migrated, since migrating a row can change its status and shift
+// the later pages.
+func listMigratableSubscriptions(ctx context.Context, repo repository.SubscriptionRepository) ([]*models.Subscription, error) {
+	var subscriptions []*models.Subscription
+
+	for _, status := range migrationStatuses {
+		for offset := 0; ; offset += migrationPageSize {
+			page, _, err := repo.List(ctx, &repository.SubscriptionFilters{
+				Status: status,
+				Limit:  migrationPageSize,
+				Offset: offset,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("list %s subscriptions: %w", status, err)
+			}
+			subscriptions = append(subscriptions, page...)
+			if len(page) < migrationPageSize {
+				break
+			}
+		}
+	}
+
+	return subscriptions, nil
+}
+
+// migrateSubscription unsubscribes the old callback for sub and subscribes the new one,
+// then stores the outcome on the subscription and records it in the audit log for actor.
+func (h *SubscriptionCRUDHandler) migrateSubscription(ctx context.Context, sub *models.Subscription, oldCallbackURL string, actor auditActor) MigrationResult {
+	before := *sub
+	result := MigrationResult{
+		SubscriptionID: sub.ID,
+		ChannelID:      sub.ChannelID,
+	}
+
+	// Record the request before sending it, since the hub may verify before it responds
+	sub.MarkLeaseRequested()
+	if err := h.repo.Update(ctx, sub); err != nil {
+		h.logger.Error("failed to record lease request",
+			"subscription_id", sub.ID,
+			"error", err,
+		)
+		result.Error = fmt.Sprintf("update failed: %v", err)
+		return result
+	}
+
+	// The old callback is usually unreachable, so a failed unsubscribe is
+	// reported but does not prevent subscribing the new callback
+	unsubReq := &service.SubscribeRequest{
+		HubURL:      sub.HubURL,
+		TopicURL:    sub.TopicURL,
+		CallbackURL: oldCallbackURL,
+	}
+	if _, err := h.hubService.Unsubscribe(ctx, unsubReq); err != nil {
+		h.logger.Warn("failed to unsubscribe old callback (continuing with subscribe)",
+			"subscription_id", sub.ID,
+			"channel_id", sub.ChannelID,
+			"error", err,
+		)
+		result.UnsubscribeError = err.Error()
+	}
+
+	subReq := &service.SubscribeRequest{
+		HubURL:       sub.HubURL,
+		TopicURL:     sub.TopicURL,
+		CallbackURL:  h.webhookURL,
+		LeaseSeconds: sub.LeaseSeconds,
+		Secret:       &h.webhookSecret,
+	}
+
+	var updateErr error
+	hubResp, err := h.hubService.Subscribe(ctx, subReq)
+	switch {
+	case err != nil:
+		h.logger.Error("failed to subscribe new callback",
+			"subscription_id", sub.ID,
+			"channel_id", sub.ChannelID,
+			"error", err,
+		)
+		result.Error = err.Error()
+		sub.MarkFailed()
+		updateErr = h.repo.Update(ctx, sub)
+	case hubResp.Accepted:
+		sub.MarkActive()
+		sub.UpdateExpiry(sub.LeaseSeconds)
+		result.Success = true
+		updateErr = h.repo.Activate(ctx, sub)
+	default:
+		result.Error = "hub rejected subscription"
+		sub.MarkFailed()
+		updateErr = h.repo.Update(ctx, sub)
+	}
+
+	if updateErr != nil {
+		h.logger.Error("failed to update subscription",
+			"subscription_id", sub.ID,
+			"error", updateErr,
+		)
+		result.Error = fmt.Sprintf("update failed: %v", updateErr)
+		result.Success = false
+		return result
+	}
+
+	h.audit.recordFor(ctx, actor, model.AuditActionMigrateCallback, auditResourceSubscription,
+		strconv.FormatInt(sub.ID, 10), &before, sub)
+
+	return result
+}
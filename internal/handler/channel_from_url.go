@@ -1,21 +1,35 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 	"ad-tracker/youtube-webhook-ingestion/internal/service"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
 )
 
+// ChannelURLResolver resolves a YouTube URL into a stored channel
+type ChannelURLResolver interface {
+	ResolveChannelFromURL(ctx context.Context, req service.ResolveChannelFromURLRequest) (*service.ResolveChannelFromURLResponse, error)
+}
+
 // ChannelFromURLHandler handles creating channels from YouTube URLs
 type ChannelFromURLHandler struct {
-	resolverService *service.ChannelResolverService
+	resolverService ChannelURLResolver
+	audit           auditTrail
 	logger          *slog.Logger
 }
 
 // NewChannelFromURLHandler creates a new ChannelFromURLHandler
-func NewChannelFromURLHandler(resolverService *service.ChannelResolverService, logger *slog.Logger) *ChannelFromURLHandler {
+func NewChannelFromURLHandler(resolverService ChannelURLResolver, logger *slog.Logger) *ChannelFromURLHandler {
 	if logger == nil {
 		logger = slog.Default()
 	}
@@ -25,9 +39,18 @@ func NewChannelFromURLHandler(resolverService *service.ChannelResolverService, l
 	}
 }
 
+// SetAuditLog records the channels and subscriptions a resolution creates or updates in the
+// audit log
+func (h *ChannelFromURLHandler) SetAuditLog(repo repository.AuditLogRepository) {
+	h.audit = auditTrail{repo: repo, logger: h.logger}
+}
+
 // CreateChannelFromURLRequest represents the request to create a channel from a URL
 type CreateChannelFromURLRequest struct {
 	URL string `json:"url"`
+
+	// ChannelID confirms one of the candidates returned for an ambiguous URL
+	ChannelID string `json:"channel_id,omitempty"`
 }
 
 // CreateChannelFromURLResponse represents the response from creating a channel from URL
@@ -37,6 +60,11 @@ type CreateChannelFromURLResponse struct {
 	Enrichment   interface{} `json:"enrichment,omitempty"`
 	WasExisting  bool        `json:"was_existing"`
 	Message      string      `json:"message,omitempty"`
+
+	// RequiresConfirmation is set when the URL matched several channels.
+	// The caller should resubmit with channel_id set to one of the candidates.
+	RequiresConfirmation bool                       `json:"requires_confirmation,omitempty"`
+	Candidates           []youtube.ChannelCandidate `json:"candidates,omitempty"`
 }
 
 // HandleCreateFromURL handles POST /api/v1/channels/from-url
@@ -54,40 +82,71 @@ func (h *ChannelFromURLHandler) HandleCreateFromURL(w http.ResponseWriter, r *ht
 		return
 	}
 
-	// Validate URL
-	if req.URL == "" {
+	// Validate URL (a confirmed channel_id can stand in for it)
+	if req.URL == "" && req.ChannelID == "" {
 		sendError(w, http.StatusBadRequest, "invalid_request", "URL is required", nil)
 		return
 	}
 
-	h.logger.Info("Creating channel from URL", "url", req.URL)
+	h.logger.Info("Creating channel from URL", "url", req.URL, "channel_id", req.ChannelID)
 
 	// Resolve channel from URL
 	serviceReq := service.ResolveChannelFromURLRequest{
-		URL: req.URL,
+		URL:       req.URL,
+		ChannelID: req.ChannelID,
 	}
 
 	result, err := h.resolverService.ResolveChannelFromURL(r.Context(), serviceReq)
 	if err != nil {
-		h.logger.Error("Failed to resolve channel from URL", "error", err, "url", req.URL)
-
-		// Determine appropriate error message
-		errMsg := "Failed to resolve channel from URL"
-		statusCode := http.StatusInternalServerError
-
-		// Check for specific error types
-		if err.Error() == "channel not found" || err.Error() == "not a YouTube URL" {
-			statusCode = http.StatusNotFound
-			errMsg = err.Error()
-		} else if err.Error() == "unsupported YouTube URL format" {
-			statusCode = http.StatusBadRequest
-			errMsg = "Unsupported YouTube URL format"
+		// Several channels matched; let the caller choose and confirm
+		var ambiguousErr *youtube.AmbiguousChannelError
+		if errors.As(err, &ambiguousErr) {
+			h.logger.Info("Channel URL is ambiguous", "url", req.URL, "candidates", len(ambiguousErr.Candidates))
+			sendJSON(w, http.StatusOK, CreateChannelFromURLResponse{
+				RequiresConfirmation: true,
+				Candidates:           ambiguousErr.Candidates,
+				Message:              "Multiple channels match this URL; resubmit with channel_id to confirm one",
+			})
+			return
+		}
+
+		// Lookups are limited separately from other endpoints as each may spend search quota
+		var rateLimitErr *service.ResolveRateLimitError
+		if errors.As(err, &rateLimitErr) {
+			seconds := int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			h.logger.Warn("Channel URL resolution rate limit exceeded", "retry_after_seconds", seconds)
+			sendError(w, http.StatusTooManyRequests, "rate_limited", "Too many channel URL resolutions; try again later", map[string]interface{}{
+				"retry_after_seconds": seconds,
+			})
+			return
+		}
+
+		// The identifier that was actually looked up: a confirmed channel_id skips the URL
+		identifier := req.URL
+		if req.ChannelID != "" {
+			identifier = req.ChannelID
+		}
+		details := map[string]interface{}{
+			"url":        req.URL,
+			"identifier": identifier,
+			"error":      err.Error(),
 		}
 
-		sendError(w, statusCode, "resolution_failed", errMsg, map[string]interface{}{
-			"url":   req.URL,
-			"error": err.Error(),
-		})
+		switch {
+		case errors.Is(err, youtube.ErrChannelNotFound):
+			h.logger.Info("Channel not found", "identifier", identifier, "error", err)
+			sendError(w, http.StatusNotFound, "channel_not_found", "No YouTube channel found for "+identifier, details)
+		case errors.Is(err, youtube.ErrInvalidChannelURL):
+			h.logger.Info("Invalid channel URL", "url", req.URL, "error", err)
+			sendError(w, http.StatusBadRequest, "invalid_url", "Not a supported YouTube channel URL", details)
+		case errors.Is(err, quota.ErrQuotaExhausted):
+			h.logger.Warn("YouTube API quota exhausted", "url", req.URL, "error", err)
+			sendError(w, http.StatusServiceUnavailable, "quota_exhausted", "YouTube API quota exhausted; try again later", details)
+		default:
+			h.logger.Error("Failed to resolve channel from URL", "error", err, "url", req.URL)
+			sendError(w, http.StatusInternalServerError, "resolution_failed", "Failed to resolve channel from URL", details)
+		}
 		return
 	}
 
@@ -99,12 +158,22 @@ func (h *ChannelFromURLHandler) HandleCreateFromURL(w http.ResponseWriter, r *ht
 		WasExisting:  result.WasExisting,
 	}
 
+	channelAction := model.AuditActionCreate
 	if result.WasExisting {
 		response.Message = "Channel already exists and was returned"
+		channelAction = model.AuditActionUpdate
 	} else {
 		response.Message = "Channel created successfully"
 	}
 
+	// The resolver refreshes an existing channel from YouTube without returning its
+	// previous state, so updates are recorded without a before snapshot
+	h.audit.record(r, channelAction, auditResourceChannel, result.Channel.ChannelID, nil, result.Channel)
+	if result.Subscription != nil {
+		h.audit.record(r, model.AuditActionCreate, auditResourceSubscription,
+			strconv.FormatInt(result.Subscription.ID, 10), nil, result.Subscription)
+	}
+
 	h.logger.Info("Channel resolved successfully",
 		"channel_id", result.Channel.ChannelID,
 		"was_existing", result.WasExisting,
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/service"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
+)
+
+// fakeChannelResolver returns a fixed result or error
+type fakeChannelResolver struct {
+	result *service.ResolveChannelFromURLResponse
+	err    error
+	calls  int
+}
+
+func (f *fakeChannelResolver) ResolveChannelFromURL(ctx context.Context, req service.ResolveChannelFromURLRequest) (*service.ResolveChannelFromURLResponse, error) {
+	f.calls++
+	return f.result, f.err
+}
+
+func TestChannelFromURLHandler_ResolutionErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		wantStatus     int
+		wantError      string
+		wantIdentifier string
+	}{
+		{
+			name:           "channel not found",
+			body:           `{"url": "https://www.youtube.com/@nosuchchannel"}`,
+			err:            fmt.Errorf("failed to resolve channel from YouTube: %w", fmt.Errorf("%w for handle: @nosuchchannel", youtube.ErrChannelNotFound)),
+			wantStatus:     http.StatusNotFound,
+			wantError:      "channel_not_found",
+			wantIdentifier: "https://www.youtube.com/@nosuchchannel",
+		},
+		{
+			name:           "confirmed channel ID not found",
+			body:           `{"url": "https://www.youtube.com/c/Gaming", "channel_id": "UCzzzzzzzzzzzzzzzzzzzzzz"}`,
+			err:            fmt.Errorf("failed to resolve channel from YouTube: %w", youtube.ErrChannelNotFound),
+			wantStatus:     http.StatusNotFound,
+			wantError:      "channel_not_found",
+			wantIdentifier: "UCzzzzzzzzzzzzzzzzzzzzzz",
+		},
+		{
+			name:           "not a YouTube URL",
+			body:           `{"url": "https://vimeo.com/staffpicks"}`,
+			err:            fmt.Errorf("failed to resolve channel from YouTube: %w", fmt.Errorf("%w: not a YouTube URL: vimeo.com", youtube.ErrInvalidChannelURL)),
+			wantStatus:     http.StatusBadRequest,
+			wantError:      "invalid_url",
+			wantIdentifier: "https://vimeo.com/staffpicks",
+		},
+		{
+			name:           "unsupported URL format",
+			body:           `{"url": "https://www.youtube.com/playlist?list=PL123"}`,
+			err:            fmt.Errorf("failed to resolve channel from YouTube: %w", fmt.Errorf("%w: unsupported YouTube URL format", youtube.ErrInvalidChannelURL)),
+			wantStatus:     http.StatusBadRequest,
+			wantError:      "invalid_url",
+			wantIdentifier: "https://www.youtube.com/playlist?list=PL123",
+		},
+		{
+			name:           "YouTube API failure",
+			body:           `{"url": "https://www.youtube.com/@somechannel"}`,
+			err:            fmt.Errorf("failed to resolve channel from YouTube: %w", errors.New("googleapi: Error 403: quotaExceeded")),
+			wantStatus:     http.StatusInternalServerError,
+			wantError:      "resolution_failed",
+			wantIdentifier: "https://www.youtube.com/@somechannel",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewChannelFromURLHandler(&fakeChannelResolver{err: tt.err}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/channels/from-url", bytes.NewBufferString(tt.body))
+			resp := httptest.NewRecorder()
+			handler.HandleCreateFromURL(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, resp.Code, resp.Body.String())
+			}
+
+			var errResp ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errResp.Error != tt.wantError {
+				t.Errorf("expected error %q, got %q", tt.wantError, errResp.Error)
+			}
+			if errResp.Details["identifier"] != tt.wantIdentifier {
+				t.Errorf("expected identifier %q, got %v", tt.wantIdentifier, errResp.Details["identifier"])
+			}
+		})
+	}
+}
+
+func TestChannelFromURLHandler_Success(t *testing.T) {
+	resolver := &fakeChannelResolver{
+		result: &service.ResolveChannelFromURLResponse{
+			Channel: &models.Channel{ChannelID: "UCaaaaaaaaaaaaaaaaaaaaaa", Title: "Cooking"},
+		},
+	}
+	handler := NewChannelFromURLHandler(resolver, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/channels/from-url", bytes.NewBufferString(`{"url": "https://www.youtube.com/@cooking"}`))
+	resp := httptest.NewRecorder()
+	handler.HandleCreateFromURL(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+}
+
+func TestChannelFromURLHandler_RateLimited(t *testing.T) {
+	resolver := &fakeChannelResolver{
+		err: fmt.Errorf("failed to resolve channel from YouTube: %w", &service.ResolveRateLimitError{RetryAfter: 29500 * time.Millisecond}),
+	}
+	handler := NewChannelFromURLHandler(resolver, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/channels/from-url", bytes.NewBufferString(`{"url": "https://www.youtube.com/c/Cooking"}`))
+	resp := httptest.NewRecorder()
+	handler.HandleCreateFromURL(resp, req)
+
+	if resp.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusTooManyRequests, resp.Code, resp.Body.String())
+	}
+	if got := resp.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("expected Retry-After %q, got %q", "30", got)
+	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if errResp.Error != "rate_limited" {
+		t.Errorf("expected error %q, got %q", "rate_limited", errResp.Error)
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChannelHandler_SubscriptionStatusFilter runs the channel list endpoint against the real
+// repository so the subscription join is exercised by Postgres rather than a mock
+func TestChannelHandler_SubscriptionStatusFilter(t *testing.T) {
+	td := testutil.SetupTestDatabase(t)
+	defer td.Cleanup(t)
+
+	channelRepo := repository.NewChannelRepository(td.Pool)
+	subRepo := repository.NewSubscriptionRepository(td.Pool)
+	ctx := context.Background()
+
+	td.TruncateTables(t)
+
+	for _, id := range []string{"UCactive", "UCexpired", "UCuntracked"} {
+		require.NoError(t, channelRepo.UpsertChannel(ctx, models.NewChannel(id, id, "https://youtube.com/channel/"+id)))
+	}
+
+	active := models.NewSubscription("UCactive", 86400)
+	active.Status = models.StatusActive
+	require.NoError(t, subRepo.Create(ctx, active))
+
+	expired := models.NewSubscription("UCexpired", 86400)
+	expired.Status = models.StatusExpired
+	require.NoError(t, subRepo.Create(ctx, expired))
+
+	h := NewChannelHandler(channelRepo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/channels?subscription_status=active", nil)
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var body struct {
+		Items []struct {
+			ChannelID             string  `json:"channel_id"`
+			SubscriptionExpiresAt *string `json:"subscription_expires_at"`
+		} `json:"items"`
+		Total int `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+
+	assert.Equal(t, 1, body.Total)
+	require.Len(t, body.Items, 1)
+	assert.Equal(t, "UCactive", body.Items[0].ChannelID)
+	assert.NotNil(t, body.Items[0].SubscriptionExpiresAt)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 )
 
 const (
@@ -111,6 +113,38 @@ func parseTimestamp(r *http.Request, key string) (*time.Time, error) {
 	return &t, nil
 }
 
+// Columns each list endpoint may be sorted by. order_by is interpolated into the
+// ORDER BY clause, so only these names may reach the repository.
+var (
+	webhookEventOrderColumns = []string{"received_at", "processed_at", "created_at", "id", "video_id", "channel_id"}
+	channelOrderColumns      = []string{"last_updated_at", "first_seen_at", "created_at", "updated_at", "title", "channel_id"}
+	videoOrderColumns        = []string{"published_at", "first_seen_at", "last_updated_at", "created_at", "updated_at", "title", "video_id", "channel_id"}
+	videoUpdateOrderColumns  = []string{"created_at", "published_at", "feed_updated_at", "id", "video_id", "channel_id", "update_type"}
+)
+
+// parseOrderBy returns the order_by query parameter, or defaultColumn when it is absent.
+// It returns an error if the column is not in allowed.
+func parseOrderBy(r *http.Request, defaultColumn string, allowed []string) (string, error) {
+	orderBy := r.URL.Query().Get("order_by")
+	if orderBy == "" {
+		return defaultColumn, nil
+	}
+
+	if !slices.Contains(allowed, orderBy) {
+		return "", fmt.Errorf("invalid order_by %q (must be one of: %s)", orderBy, strings.Join(allowed, ", "))
+	}
+
+	return orderBy, nil
+}
+
+// sendOrderByError sends a 400 response for a disallowed order_by column.
+func sendOrderByError(w http.ResponseWriter, err error, allowed []string) {
+	sendError(w, http.StatusBadRequest, "validation failed", err.Error(), map[string]interface{}{
+		"field":   "order_by",
+		"allowed": allowed,
+	})
+}
+
 func getOrderDir(r *http.Request) string {
 	orderDir := strings.ToUpper(r.URL.Query().Get("order"))
 	if orderDir != "ASC" && orderDir != "DESC" {
@@ -151,10 +185,29 @@ type UpdateWebhookEventRequest struct {
 	ProcessingError *string `json:"processing_error,omitempty"`
 }
 
+// MarkWebhookEventsProcessedRequest represents a bulk request to mark webhook events as processed.
+// Events can be selected by ID, by received_at, or both.
+type MarkWebhookEventsProcessedRequest struct {
+	IDs            []int64    `json:"ids,omitempty"`
+	ReceivedBefore *time.Time `json:"received_before,omitempty"`
+}
+
+// maxMarkProcessedIDs limits the number of explicit IDs accepted by a single bulk request.
+const maxMarkProcessedIDs = 10000
+
 // ServeHTTP routes webhook event requests.
 func (h *WebhookEventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/webhook-events")
 
+	if path == "/mark-processed" {
+		if r.Method != http.MethodPost {
+			sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+			return
+		}
+		h.handleMarkProcessed(w, r)
+		return
+	}
+
 	if path == "" || path == "/" {
 		switch r.Method {
 		case http.MethodPost:
@@ -262,18 +315,47 @@ func (h *WebhookEventHandler) handleList(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	errorCode := models.ProcessingErrorCode(r.URL.Query().Get("error_code"))
+	if errorCode != "" && !errorCode.IsValid() {
+		sendError(w, http.StatusBadRequest, "validation failed",
+			fmt.Sprintf("invalid error_code %q", errorCode),
+			map[string]interface{}{"valid_values": models.ProcessingErrorCodes})
+		return
+	}
+
+	orderBy, err := parseOrderBy(r, "received_at", webhookEventOrderColumns)
+	if err != nil {
+		sendOrderByError(w, err, webhookEventOrderColumns)
+		return
+	}
+
+	var after *repository.WebhookEventCursor
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		after, err = repository.ParseWebhookEventCursor(cursor)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "validation failed", "invalid cursor", nil)
+			return
+		}
+		if orderBy != "received_at" {
+			sendError(w, http.StatusBadRequest, "validation failed", "cursor requires order_by=received_at", nil)
+			return
+		}
+		if offset > 0 {
+			sendError(w, http.StatusBadRequest, "validation failed", "cursor cannot be combined with offset", nil)
+			return
+		}
+	}
+
 	filters := &repository.WebhookEventFilters{
 		Limit:     limit,
 		Offset:    offset,
 		Processed: processed,
 		VideoID:   r.URL.Query().Get("video_id"),
 		ChannelID: r.URL.Query().Get("channel_id"),
-		OrderBy:   r.URL.Query().Get("order_by"),
+		ErrorCode: errorCode,
+		OrderBy:   orderBy,
 		OrderDir:  getOrderDir(r),
-	}
-
-	if filters.OrderBy == "" {
-		filters.OrderBy = "received_at"
+		After:     after,
 	}
 
 	events, total, err := h.repo.List(r.Context(), filters)
@@ -283,11 +365,19 @@ func (h *WebhookEventHandler) handleList(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// A full page ordered by received_at can be continued from its last event
+	var nextCursor *string
+	if orderBy == "received_at" && limit > 0 && len(events) == limit {
+		encoded := repository.NewWebhookEventCursor(events[len(events)-1]).Encode()
+		nextCursor = &encoded
+	}
+
 	response := map[string]interface{}{
-		"items":  events,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
+		"items":       events,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+		"next_cursor": nextCursor,
 	}
 
 	sendJSON(w, http.StatusOK, response)
@@ -330,6 +420,37 @@ func (h *WebhookEventHandler) handleUpdate(w http.ResponseWriter, r *http.Reques
 	sendJSON(w, http.StatusOK, event)
 }
 
+func (h *WebhookEventHandler) handleMarkProcessed(w http.ResponseWriter, r *http.Request) {
+	var req MarkWebhookEventsProcessedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid request body", err.Error(), nil)
+		return
+	}
+
+	if len(req.IDs) == 0 && req.ReceivedBefore == nil {
+		sendError(w, http.StatusBadRequest, "validation failed", "ids or received_before is required", nil)
+		return
+	}
+
+	if len(req.IDs) > maxMarkProcessedIDs {
+		sendError(w, http.StatusBadRequest, "validation failed", fmt.Sprintf("at most %d ids may be provided", maxMarkProcessedIDs), nil)
+		return
+	}
+
+	updated, err := h.repo.MarkEventsProcessed(r.Context(), req.IDs, req.ReceivedBefore)
+	if err != nil {
+		h.logger.Error("failed to bulk mark webhook events processed", "error", err, "updated", updated)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to mark webhook events as processed", nil)
+		return
+	}
+
+	h.logger.Info("bulk marked webhook events processed", "updated", updated, "ids", len(req.IDs))
+
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"updated": updated,
+	})
+}
+
 func (h *WebhookEventHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 	sendError(w, http.StatusForbidden, "Forbidden", "Deleting webhook events is not allowed - events are immutable", nil)
 }
@@ -337,6 +458,7 @@ func (h *WebhookEventHandler) handleDelete(w http.ResponseWriter, r *http.Reques
 // ChannelHandler handles CRUD operations for channels.
 type ChannelHandler struct {
 	repo   repository.ChannelRepository
+	audit  auditTrail
 	logger *slog.Logger
 }
 
@@ -351,6 +473,11 @@ func NewChannelHandler(repo repository.ChannelRepository, logger *slog.Logger) *
 	}
 }
 
+// SetAuditLog records channel creates, updates and deletes in the audit log
+func (h *ChannelHandler) SetAuditLog(repo repository.AuditLogRepository) {
+	h.audit = auditTrail{repo: repo, logger: h.logger}
+}
+
 // CreateChannelRequest represents the request to create a channel.
 type CreateChannelRequest struct {
 	ChannelID  string `json:"channel_id"`
@@ -364,6 +491,12 @@ type UpdateChannelRequest struct {
 	ChannelURL string `json:"channel_url"`
 }
 
+// PatchChannelRequest represents a partial channel update; omitted fields are left unchanged.
+type PatchChannelRequest struct {
+	Title      *string `json:"title"`
+	ChannelURL *string `json:"channel_url"`
+}
+
 // ServeHTTP routes channel requests.
 func (h *ChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/channels")
@@ -388,6 +521,8 @@ func (h *ChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.handleGet(w, r, channelID)
 		case http.MethodPut:
 			h.handleUpdate(w, r, channelID)
+		case http.MethodPatch:
+			h.handlePatch(w, r, channelID)
 		case http.MethodDelete:
 			h.handleDelete(w, r, channelID)
 		default:
@@ -438,6 +573,8 @@ func (h *ChannelHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit.record(r, model.AuditActionCreate, auditResourceChannel, channel.ChannelID, nil, channel)
+
 	sendJSON(w, http.StatusCreated, channel)
 }
 
@@ -456,20 +593,65 @@ func (h *ChannelHandler) handleGet(w http.ResponseWriter, r *http.Request, chann
 	sendJSON(w, http.StatusOK, channel)
 }
 
+// HandleGetNameHistory handles GET /api/v1/channels/{id}/name-history
+// It returns the channel's recorded title changes, most recent first.
+func (h *ChannelHandler) HandleGetNameHistory(w http.ResponseWriter, r *http.Request, channelID string) {
+	if r.Method != http.MethodGet {
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	history, err := h.repo.GetNameHistory(r.Context(), channelID)
+	if err != nil {
+		h.logger.Error("failed to get channel name history", "error", err, "channel_id", channelID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve channel name history", nil)
+		return
+	}
+
+	if history == nil {
+		history = []*models.ChannelNameChange{}
+	}
+
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"channel_id": channelID,
+		"items":      history,
+		"total":      len(history),
+	})
+}
+
+// validSubscriptionStatuses are the values accepted by the subscription_status filter on channel lists
+var validSubscriptionStatuses = map[string]bool{
+	models.StatusPending:   true,
+	models.StatusActive:    true,
+	models.StatusExpired:   true,
+	models.StatusFailed:    true,
+	models.StatusAbandoned: true,
+}
+
 func (h *ChannelHandler) handleList(w http.ResponseWriter, r *http.Request) {
 	limit := parseLimit(r)
 	offset := parseOffset(r)
 
-	filters := &repository.ChannelFilters{
-		Limit:    limit,
-		Offset:   offset,
-		Title:    r.URL.Query().Get("title"),
-		OrderBy:  r.URL.Query().Get("order_by"),
-		OrderDir: getOrderDir(r),
+	orderBy, err := parseOrderBy(r, "last_updated_at", channelOrderColumns)
+	if err != nil {
+		sendOrderByError(w, err, channelOrderColumns)
+		return
 	}
 
-	if filters.OrderBy == "" {
-		filters.OrderBy = "last_updated_at"
+	subscriptionStatus := r.URL.Query().Get("subscription_status")
+	if subscriptionStatus != "" && !validSubscriptionStatuses[subscriptionStatus] {
+		sendError(w, http.StatusBadRequest, "validation failed",
+			"invalid subscription_status value (valid: pending, active, expired, failed, abandoned)", nil)
+		return
+	}
+
+	filters := &repository.ChannelFilters{
+		Limit:              limit,
+		Offset:             offset,
+		Title:              r.URL.Query().Get("title"),
+		OrderBy:            orderBy,
+		OrderDir:           getOrderDir(r),
+		SubscriptionStatus: subscriptionStatus,
 	}
 
 	channels, total, err := h.repo.List(r.Context(), filters)
@@ -512,6 +694,8 @@ func (h *ChannelHandler) handleUpdate(w http.ResponseWriter, r *http.Request, ch
 		ChannelURL: req.ChannelURL,
 	}
 
+	before := h.auditSnapshot(r, channelID)
+
 	if err := h.repo.Update(r.Context(), channel); err != nil {
 		if db.IsNotFound(err) {
 			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("channel with id '%s' not found", channelID), nil)
@@ -522,10 +706,66 @@ func (h *ChannelHandler) handleUpdate(w http.ResponseWriter, r *http.Request, ch
 		return
 	}
 
+	h.audit.record(r, model.AuditActionUpdate, auditResourceChannel, channelID, before, channel)
+
 	sendJSON(w, http.StatusOK, channel)
 }
 
+// handlePatch applies only the fields present in the request to an existing channel
+func (h *ChannelHandler) handlePatch(w http.ResponseWriter, r *http.Request, channelID string) {
+	var req PatchChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid request body", err.Error(), nil)
+		return
+	}
+
+	if req.Title != nil && *req.Title == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "title must not be empty", nil)
+		return
+	}
+
+	if req.ChannelURL != nil && *req.ChannelURL == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "channel_url must not be empty", nil)
+		return
+	}
+
+	existing, err := h.repo.GetChannelByID(r.Context(), channelID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("channel with id '%s' not found", channelID), nil)
+			return
+		}
+		h.logger.Error("failed to get channel", "error", err, "channel_id", channelID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve channel", nil)
+		return
+	}
+
+	channel := *existing
+	if req.Title != nil {
+		channel.Title = *req.Title
+	}
+	if req.ChannelURL != nil {
+		channel.ChannelURL = *req.ChannelURL
+	}
+
+	if err := h.repo.Update(r.Context(), &channel); err != nil {
+		if db.IsNotFound(err) {
+			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("channel with id '%s' not found", channelID), nil)
+			return
+		}
+		h.logger.Error("failed to update channel", "error", err, "channel_id", channelID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to update channel", nil)
+		return
+	}
+
+	h.audit.record(r, model.AuditActionUpdate, auditResourceChannel, channelID, existing, &channel)
+
+	sendJSON(w, http.StatusOK, &channel)
+}
+
 func (h *ChannelHandler) handleDelete(w http.ResponseWriter, r *http.Request, channelID string) {
+	before := h.auditSnapshot(r, channelID)
+
 	if err := h.repo.Delete(r.Context(), channelID); err != nil {
 		if db.IsNotFound(err) {
 			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("channel with id '%s' not found", channelID), nil)
@@ -536,5 +776,25 @@ func (h *ChannelHandler) handleDelete(w http.ResponseWriter, r *http.Request, ch
 		return
 	}
 
+	h.audit.record(r, model.AuditActionDelete, auditResourceChannel, channelID, before, nil)
+
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// auditSnapshot fetches a channel's current state for the audit log before it is replaced
+// or deleted. Returns nil when auditing is disabled or the lookup fails.
+func (h *ChannelHandler) auditSnapshot(r *http.Request, channelID string) *models.Channel {
+	if !h.audit.enabled() {
+		return nil
+	}
+
+	channel, err := h.repo.GetChannelByID(r.Context(), channelID)
+	if err != nil {
+		if !db.IsNotFound(err) {
+			h.logger.Warn("failed to get channel for audit log", "error", err, "channel_id", channelID)
+		}
+		return nil
+	}
+
+	return channel
+}
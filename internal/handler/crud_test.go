@@ -5,14 +5,19 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/middleware"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 )
 
 // Mock webhook event repository
@@ -68,6 +73,10 @@ func (m *mockWebhookEventRepo) List(ctx context.Context, filters *repository.Web
 			include = false
 		}
 
+		if filters.ErrorCode != "" && event.ErrorCode.String != string(filters.ErrorCode) {
+			include = false
+		}
+
 		if include {
 			results = append(results, event)
 		}
@@ -103,7 +112,11 @@ func (m *mockWebhookEventRepo) UpdateProcessingStatus(ctx context.Context, event
 }
 
 func (m *mockWebhookEventRepo) CreateWebhookEvent(ctx context.Context, rawXML, videoID, channelID string) (*models.WebhookEvent, error) {
-	return nil, nil
+	event := models.NewWebhookEvent(rawXML, db.GenerateContentHash(rawXML), videoID, channelID)
+	if err := m.Create(ctx, event); err != nil {
+		return nil, err
+	}
+	return event, nil
 }
 
 func (m *mockWebhookEventRepo) GetUnprocessedEvents(ctx context.Context, limit int) ([]*models.WebhookEvent, error) {
@@ -114,6 +127,51 @@ func (m *mockWebhookEventRepo) MarkEventProcessed(ctx context.Context, eventID i
 	return nil
 }
 
+func (m *mockWebhookEventRepo) MarkEventFailed(ctx context.Context, eventID int64, code models.ProcessingErrorCode, processingError string) error {
+	event, ok := m.events[eventID]
+	if !ok {
+		return db.ErrNotFound
+	}
+
+	event.MarkProcessed(processingError)
+	event.ErrorCode = sql.NullString{String: string(code), Valid: true}
+	return nil
+}
+
+func (m *mockWebhookEventRepo) MarkEventsProcessed(ctx context.Context, ids []int64, receivedBefore *time.Time) (int64, error) {
+	wanted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var updated int64
+	for _, event := range m.events {
+		if event.Processed {
+			continue
+		}
+		if len(ids) > 0 && !wanted[event.ID] {
+			continue
+		}
+		if receivedBefore != nil && !event.ReceivedAt.Before(*receivedBefore) {
+			continue
+		}
+
+		event.Processed = true
+		event.ProcessedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		updated++
+	}
+
+	return updated, nil
+}
+
+func (m *mockWebhookEventRepo) GetEventsMissingVideoID(ctx context.Context, afterID int64, limit int) ([]*models.WebhookEvent, error) {
+	return nil, nil
+}
+
+func (m *mockWebhookEventRepo) SetEventLinkage(ctx context.Context, eventID int64, videoID, channelID string) error {
+	return nil
+}
+
 func (m *mockWebhookEventRepo) GetEventsByVideoID(ctx context.Context, videoID string) ([]*models.WebhookEvent, error) {
 	return nil, nil
 }
@@ -327,6 +385,58 @@ func TestWebhookEventHandler_List(t *testing.T) {
 	}
 }
 
+func TestWebhookEventHandler_ListCursor(t *testing.T) {
+	repo := newMockWebhookEventRepo()
+	handler := NewWebhookEventHandler(repo, nil)
+
+	for i := 0; i < 3; i++ {
+		repo.Create(context.Background(), &models.WebhookEvent{
+			RawXML:      "<feed>test</feed>",
+			ContentHash: string(rune('a'+i)) + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abc",
+		})
+	}
+
+	list := func(query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhook-events"+query, nil)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp, result
+	}
+
+	resp, result := list("?limit=2")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+	nextCursor, ok := result["next_cursor"].(string)
+	if !ok || nextCursor == "" {
+		t.Fatalf("expected next_cursor on a full page, got %v", result["next_cursor"])
+	}
+	if _, err := repository.ParseWebhookEventCursor(nextCursor); err != nil {
+		t.Errorf("next_cursor does not parse: %v", err)
+	}
+
+	resp, result = list("?limit=10")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+	if result["next_cursor"] != nil {
+		t.Errorf("expected no next_cursor on the last page, got %v", result["next_cursor"])
+	}
+
+	for _, query := range []string{
+		"?cursor=not-a-cursor",
+		"?cursor=" + nextCursor + "&order_by=id",
+		"?cursor=" + nextCursor + "&offset=10",
+	} {
+		if resp, _ := list(query); resp.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, resp.Code)
+		}
+	}
+}
+
 func TestWebhookEventHandler_Update(t *testing.T) {
 	repo := newMockWebhookEventRepo()
 	handler := NewWebhookEventHandler(repo, nil)
@@ -367,6 +477,100 @@ func TestWebhookEventHandler_Update(t *testing.T) {
 	}
 }
 
+func TestWebhookEventHandler_MarkProcessed(t *testing.T) {
+	cutoff := time.Now()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCount  float64
+	}{
+		{
+			name:           "by ids",
+			body:           `{"ids": [1, 3, 4]}`,
+			expectedStatus: http.StatusOK,
+			expectedCount:  2,
+		},
+		{
+			name:           "by received_before",
+			body:           `{"received_before": "` + cutoff.Format(time.RFC3339Nano) + `"}`,
+			expectedStatus: http.StatusOK,
+			expectedCount:  2,
+		},
+		{
+			name:           "ids and received_before combined",
+			body:           `{"ids": [1, 3], "received_before": "` + cutoff.Format(time.RFC3339Nano) + `"}`,
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "missing selector",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"ids": "all"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockWebhookEventRepo()
+			handler := NewWebhookEventHandler(repo, nil)
+
+			// Events 1, 2 and 4 were received before the cutoff, event 3 after; event 4 is already processed
+			receivedAt := []time.Time{cutoff.Add(-3 * time.Hour), cutoff.Add(-2 * time.Hour), cutoff.Add(time.Hour), cutoff.Add(-time.Hour)}
+			for i, at := range receivedAt {
+				event := &models.WebhookEvent{
+					RawXML:      fmt.Sprintf("<feed>%d</feed>", i),
+					ContentHash: fmt.Sprintf("hash%d", i),
+				}
+				repo.Create(context.Background(), event)
+				event.ReceivedAt = at
+			}
+			repo.events[4].Processed = true
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/webhook-events/mark-processed", strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, resp.Code)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if result["updated"] != tt.expectedCount {
+				t.Errorf("expected %v updated events, got %v", tt.expectedCount, result["updated"])
+			}
+		})
+	}
+
+	t.Run("method not allowed", func(t *testing.T) {
+		handler := NewWebhookEventHandler(newMockWebhookEventRepo(), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhook-events/mark-processed", nil)
+		resp := httptest.NewRecorder()
+
+		handler.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, resp.Code)
+		}
+	})
+}
+
 func TestWebhookEventHandler_Delete(t *testing.T) {
 	repo := newMockWebhookEventRepo()
 	handler := NewWebhookEventHandler(repo, nil)
@@ -392,12 +596,16 @@ func TestWebhookEventHandler_Delete(t *testing.T) {
 
 // Mock channel repository for testing
 type mockChannelRepo struct {
-	channels map[string]*models.Channel
+	channels      map[string]*models.Channel
+	subscriptions map[string]*models.Subscription
+	nameHistory   map[string][]*models.ChannelNameChange
 }
 
 func newMockChannelRepo() *mockChannelRepo {
 	return &mockChannelRepo{
-		channels: make(map[string]*models.Channel),
+		channels:      make(map[string]*models.Channel),
+		subscriptions: make(map[string]*models.Subscription),
+		nameHistory:   make(map[string][]*models.ChannelNameChange),
 	}
 }
 
@@ -440,6 +648,15 @@ func (m *mockChannelRepo) Delete(ctx context.Context, channelID string) error {
 func (m *mockChannelRepo) List(ctx context.Context, filters *repository.ChannelFilters) ([]*models.Channel, int, error) {
 	var results []*models.Channel
 	for _, channel := range m.channels {
+		if filters.SubscriptionStatus != "" {
+			sub, ok := m.subscriptions[channel.ChannelID]
+			if !ok || sub.Status != filters.SubscriptionStatus {
+				continue
+			}
+			withExpiry := *channel
+			withExpiry.SubscriptionExpiresAt = &sub.ExpiresAt
+			channel = &withExpiry
+		}
 		results = append(results, channel)
 	}
 
@@ -467,6 +684,118 @@ func (m *mockChannelRepo) GetChannelsByLastUpdated(ctx context.Context, since ti
 	return nil, nil
 }
 
+func (m *mockChannelRepo) BackfillTitle(ctx context.Context, channelID, title string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockChannelRepo) RecordTitleChange(ctx context.Context, channelID, title string) (*models.ChannelNameChange, error) {
+	return nil, nil
+}
+
+func (m *mockChannelRepo) GetNameHistory(ctx context.Context, channelID string) ([]*models.ChannelNameChange, error) {
+	return m.nameHistory[channelID], nil
+}
+
+func TestChannelHandler_GetNameHistory(t *testing.T) {
+	repo := newMockChannelRepo()
+	handler := NewChannelHandler(repo, nil)
+
+	changedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.nameHistory["UCrenamed"] = []*models.ChannelNameChange{
+		{ID: 2, ChannelID: "UCrenamed", OldTitle: "Middle Title", NewTitle: "New Title", ChangedAt: changedAt},
+		{ID: 1, ChannelID: "UCrenamed", OldTitle: "Old Title", NewTitle: "Middle Title", ChangedAt: changedAt.Add(-24 * time.Hour)},
+	}
+
+	t.Run("lists changes", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		handler.HandleGetNameHistory(resp, httptest.NewRequest(http.MethodGet, "/api/v1/channels/UCrenamed/name-history", nil), "UCrenamed")
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d. Body: %s", resp.Code, resp.Body.String())
+		}
+
+		var body struct {
+			ChannelID string                      `json:"channel_id"`
+			Items     []*models.ChannelNameChange `json:"items"`
+			Total     int                         `json:"total"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if body.ChannelID != "UCrenamed" || body.Total != 2 || len(body.Items) != 2 {
+			t.Fatalf("expected 2 changes for UCrenamed, got %+v", body)
+		}
+		if body.Items[0].NewTitle != "New Title" || body.Items[1].OldTitle != "Old Title" {
+			t.Errorf("unexpected history order: %+v, %+v", body.Items[0], body.Items[1])
+		}
+	})
+
+	t.Run("never renamed channel has an empty history", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		handler.HandleGetNameHistory(resp, httptest.NewRequest(http.MethodGet, "/api/v1/channels/UCother/name-history", nil), "UCother")
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.Code)
+		}
+		if !strings.Contains(resp.Body.String(), `"items":[]`) {
+			t.Errorf("expected empty items, got %s", resp.Body.String())
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		handler.HandleGetNameHistory(resp, httptest.NewRequest(http.MethodPost, "/api/v1/channels/UCrenamed/name-history", nil), "UCrenamed")
+
+		if resp.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", resp.Code)
+		}
+	})
+}
+
+func TestListHandlers_OrderBy(t *testing.T) {
+	handlers := map[string]http.Handler{
+		"/api/v1/webhook-events": NewWebhookEventHandler(newMockWebhookEventRepo(), nil),
+		"/api/v1/channels":       NewChannelHandler(newMockChannelRepo(), nil),
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		query          string
+		expectedStatus int
+	}{
+		{name: "webhook events default", path: "/api/v1/webhook-events", query: "", expectedStatus: http.StatusOK},
+		{name: "webhook events allowed column", path: "/api/v1/webhook-events", query: "?order_by=processed_at&order=asc", expectedStatus: http.StatusOK},
+		{name: "webhook events disallowed column", path: "/api/v1/webhook-events", query: "?order_by=raw_xml", expectedStatus: http.StatusBadRequest},
+		{name: "webhook events injection attempt", path: "/api/v1/webhook-events", query: "?order_by=" + url.QueryEscape("received_at; DROP TABLE webhook_events"), expectedStatus: http.StatusBadRequest},
+		{name: "channels allowed column", path: "/api/v1/channels", query: "?order_by=title", expectedStatus: http.StatusOK},
+		{name: "channels disallowed column", path: "/api/v1/channels", query: "?order_by=nonexistent", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			handlers[tt.path].ServeHTTP(resp, req)
+
+			if resp.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, resp.Code, resp.Body.String())
+			}
+
+			if tt.expectedStatus == http.StatusBadRequest {
+				var errResp ErrorResponse
+				if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if errResp.Details["field"] != "order_by" {
+					t.Errorf("expected order_by validation error, got %+v", errResp)
+				}
+			}
+		})
+	}
+}
+
 func TestChannelHandler_Create(t *testing.T) {
 	repo := newMockChannelRepo()
 	handler := NewChannelHandler(repo, nil)
@@ -527,3 +856,336 @@ func TestChannelHandler_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestChannelHandler_List_SubscriptionStatus(t *testing.T) {
+	repo := newMockChannelRepo()
+	handler := NewChannelHandler(repo, nil)
+
+	expiresAt := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	for _, id := range []string{"UCactive", "UCexpired", "UCuntracked"} {
+		repo.channels[id] = &models.Channel{ChannelID: id, Title: id}
+	}
+	repo.subscriptions["UCactive"] = &models.Subscription{ChannelID: "UCactive", Status: models.StatusActive, ExpiresAt: expiresAt}
+	repo.subscriptions["UCexpired"] = &models.Subscription{ChannelID: "UCexpired", Status: models.StatusExpired, ExpiresAt: expiresAt}
+
+	t.Run("active only", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/channels?subscription_status=active", nil)
+		resp := httptest.NewRecorder()
+
+		handler.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d. Body: %s", resp.Code, resp.Body.String())
+		}
+
+		var body struct {
+			Items []struct {
+				ChannelID             string     `json:"channel_id"`
+				SubscriptionExpiresAt *time.Time `json:"subscription_expires_at"`
+			} `json:"items"`
+			Total int `json:"total"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if body.Total != 1 || len(body.Items) != 1 {
+			t.Fatalf("expected 1 channel, got total %d with %d items", body.Total, len(body.Items))
+		}
+		if body.Items[0].ChannelID != "UCactive" {
+			t.Errorf("expected UCactive, got %s", body.Items[0].ChannelID)
+		}
+		if got := body.Items[0].SubscriptionExpiresAt; got == nil || !got.Equal(expiresAt) {
+			t.Errorf("expected subscription_expires_at %v, got %v", expiresAt, got)
+		}
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/channels?subscription_status=bogus", nil)
+		resp := httptest.NewRecorder()
+
+		handler.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", resp.Code)
+		}
+	})
+}
+
+func TestChannelHandler_Patch(t *testing.T) {
+	repo := newMockChannelRepo()
+	handler := NewChannelHandler(repo, nil)
+
+	firstSeen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.channels["UCtest123456789012345678"] = &models.Channel{
+		ChannelID:   "UCtest123456789012345678",
+		Title:       "Old Title",
+		ChannelURL:  "https://www.youtube.com/channel/UCtest123456789012345678",
+		FirstSeenAt: firstSeen,
+		CreatedAt:   firstSeen,
+	}
+
+	patch := func(channelID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/channels/"+channelID, strings.NewReader(body))
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("title only leaves the URL unchanged", func(t *testing.T) {
+		resp := patch("UCtest123456789012345678", `{"title": "New Title"}`)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+
+		var channel models.Channel
+		if err := json.NewDecoder(resp.Body).Decode(&channel); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if channel.Title != "New Title" {
+			t.Errorf("expected title %q, got %q", "New Title", channel.Title)
+		}
+		if channel.ChannelURL != "https://www.youtube.com/channel/UCtest123456789012345678" {
+			t.Errorf("expected channel_url to be unchanged, got %q", channel.ChannelURL)
+		}
+		if !channel.FirstSeenAt.Equal(firstSeen) || !channel.CreatedAt.Equal(firstSeen) {
+			t.Errorf("expected first_seen_at and created_at to be preserved, got %v and %v", channel.FirstSeenAt, channel.CreatedAt)
+		}
+	})
+
+	t.Run("empty title is rejected", func(t *testing.T) {
+		resp := patch("UCtest123456789012345678", `{"title": ""}`)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		resp := patch("UCmissing12345678901234567", `{"title": "New Title"}`)
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+}
+
+func TestChannelHandler_Update_RecordsAudit(t *testing.T) {
+	repo := newMockChannelRepo()
+	auditRepo := &mockAuditLogRepo{}
+	handler := NewChannelHandler(repo, nil)
+	handler.SetAuditLog(auditRepo)
+
+	repo.channels["UCtest123456789012345678"] = &models.Channel{
+		ChannelID:  "UCtest123456789012345678",
+		Title:      "Old Title",
+		ChannelURL: "https://www.youtube.com/channel/UCtest123456789012345678",
+	}
+
+	body := `{"title": "New Title", "channel_url": "https://www.youtube.com/@new"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/channels/UCtest123456789012345678", strings.NewReader(body))
+	req.Header.Set("X-API-Key", "test-key")
+	req.Header.Set(HeaderActor, "alice")
+	resp := httptest.NewRecorder()
+	middleware.NewAPIKeyAuth([]string{"test-key"}, nil).Middleware(handler).ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	if len(auditRepo.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(auditRepo.entries))
+	}
+	entry := auditRepo.entries[0]
+	if entry.Action != model.AuditActionUpdate || entry.ResourceType != "channel" || entry.ResourceID != "UCtest123456789012345678" {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+	if entry.Actor == nil || *entry.Actor != "alice" {
+		t.Errorf("expected actor alice, got %v", entry.Actor)
+	}
+	if entry.APIKeyID == nil || *entry.APIKeyID != middleware.APIKeyID("test-key") {
+		t.Errorf("expected API key ID of test-key, got %v", entry.APIKeyID)
+	}
+
+	var before, after models.Channel
+	if err := json.Unmarshal(entry.Before, &before); err != nil {
+		t.Fatalf("failed to decode before: %v", err)
+	}
+	if err := json.Unmarshal(entry.After, &after); err != nil {
+		t.Fatalf("failed to decode after: %v", err)
+	}
+	if before.Title != "Old Title" || after.Title != "New Title" {
+		t.Errorf("expected title Old Title -> New Title, got %q -> %q", before.Title, after.Title)
+	}
+
+	// Failed updates are not audited
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/channels/UCmissing", strings.NewReader(body))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if len(auditRepo.entries) != 1 {
+		t.Errorf("expected no audit entry for a failed update, got %d entries", len(auditRepo.entries))
+	}
+}
+
+func TestTruncateActor_CutsOnCharacterBoundary(t *testing.T) {
+	long := strings.Repeat("é", maxActorLength+10)
+	if got := truncateActor(long); got != strings.Repeat("é", maxActorLength) {
+		t.Errorf("expected %d characters, got %d bytes: %q", maxActorLength, len(got), got)
+	}
+
+	if got := truncateActor("alice"); got != "alice" {
+		t.Errorf("expected a short actor to be kept, got %q", got)
+	}
+
+	if got := truncateActor("bob\xff"); got != "bob\uFFFD" {
+		t.Errorf("expected invalid UTF-8 to be replaced, got %q", got)
+	}
+}
+
+func TestVideoHandler_Patch(t *testing.T) {
+	repo := newMockVideoRepo()
+	handler := NewVideoHandler(repo, nil)
+
+	publishedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	firstSeen := publishedAt.Add(time.Hour)
+	repo.videos["dQw4w9WgXcQ"] = &models.Video{
+		VideoID:     "dQw4w9WgXcQ",
+		ChannelID:   "UCtest123456789012345678",
+		Title:       "Old Title",
+		VideoURL:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		PublishedAt: publishedAt,
+		FirstSeenAt: firstSeen,
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/videos/dQw4w9WgXcQ", strings.NewReader(`{"title": "New Title"}`))
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	var video models.Video
+	if err := json.NewDecoder(resp.Body).Decode(&video); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if video.Title != "New Title" {
+		t.Errorf("expected title %q, got %q", "New Title", video.Title)
+	}
+	if video.VideoURL != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+		t.Errorf("expected video_url to be unchanged, got %q", video.VideoURL)
+	}
+	if !video.PublishedAt.Equal(publishedAt) || !video.FirstSeenAt.Equal(firstSeen) {
+		t.Errorf("expected published_at and first_seen_at to be preserved, got %v and %v", video.PublishedAt, video.FirstSeenAt)
+	}
+	if stored := repo.videos["dQw4w9WgXcQ"]; stored.Title != "New Title" {
+		t.Errorf("expected stored title to be updated, got %q", stored.Title)
+	}
+}
+
+func TestVideoHandler_SoftDelete(t *testing.T) {
+	repo := newMockVideoRepo()
+	handler := NewVideoHandler(repo, nil)
+
+	repo.videos["deletedVid1"] = &models.Video{VideoID: "deletedVid1", ChannelID: "UCtest123456789012345678", Title: "Deleted"}
+	repo.videos["keptVideo01"] = &models.Video{VideoID: "keptVideo01", ChannelID: "UCtest123456789012345678", Title: "Kept"}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/videos/deletedVid1", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d. Body: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+
+	// Hidden from the default list
+	listVideoIDs := func(query string) []string {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/videos"+query, nil)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		var response struct {
+			Items []models.Video `json:"items"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		var ids []string
+		for _, video := range response.Items {
+			ids = append(ids, video.VideoID)
+		}
+		return ids
+	}
+	if ids := listVideoIDs(""); len(ids) != 1 || ids[0] != "keptVideo01" {
+		t.Errorf("expected only keptVideo01 in the list, got %v", ids)
+	}
+	if ids := listVideoIDs("?include_deleted=true"); len(ids) != 2 {
+		t.Errorf("expected both videos with include_deleted, got %v", ids)
+	}
+
+	// Still retrievable by ID, flagged as deleted
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/videos/deletedVid1", nil)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	var video models.Video
+	if err := json.NewDecoder(resp.Body).Decode(&video); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if video.DeletedAt == nil {
+		t.Error("expected deleted_at to be set on a soft-deleted video")
+	}
+
+	// soft=false removes the video
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/videos/deletedVid1?soft=false", nil)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d. Body: %s", http.StatusNoContent, resp.Code, resp.Body.String())
+	}
+	if _, ok := repo.videos["deletedVid1"]; ok {
+		t.Error("expected a hard delete to remove the video")
+	}
+}
+
+func TestVideoHandler_List_IngestionLag(t *testing.T) {
+	repo := newMockVideoRepo()
+	handler := NewVideoHandler(repo, nil)
+
+	publishedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo.videos["promptVideo"] = &models.Video{VideoID: "promptVideo", PublishedAt: publishedAt, FirstSeenAt: publishedAt.Add(90 * time.Second)}
+	repo.videos["lateVideo01"] = &models.Video{VideoID: "lateVideo01", PublishedAt: publishedAt, FirstSeenAt: publishedAt.Add(2 * time.Hour)}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/videos?order_by=first_seen_at&order=asc", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+	if repo.lastFilters.OrderBy != "first_seen_at" || repo.lastFilters.OrderDir != "ASC" {
+		t.Errorf("expected order by first_seen_at ASC, got %s %s", repo.lastFilters.OrderBy, repo.lastFilters.OrderDir)
+	}
+
+	var response struct {
+		Items []struct {
+			VideoID             string    `json:"video_id"`
+			FirstSeenAt         time.Time `json:"first_seen_at"`
+			IngestionLagSeconds int64     `json:"ingestion_lag_seconds"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Items) != 2 {
+		t.Fatalf("expected 2 videos, got %d", len(response.Items))
+	}
+	if response.Items[0].VideoID != "promptVideo" || response.Items[1].VideoID != "lateVideo01" {
+		t.Errorf("expected videos in first_seen_at order, got %s, %s", response.Items[0].VideoID, response.Items[1].VideoID)
+	}
+	if response.Items[0].IngestionLagSeconds != 90 {
+		t.Errorf("expected ingestion lag 90s, got %d", response.Items[0].IngestionLagSeconds)
+	}
+	if response.Items[1].IngestionLagSeconds != 7200 {
+		t.Errorf("expected ingestion lag 7200s, got %d", response.Items[1].IngestionLagSeconds)
+	}
+}
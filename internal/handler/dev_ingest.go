@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/parser"
+	"ad-tracker/youtube-webhook-ingestion/internal/service"
+)
+
+// DevIngestRequest describes a video notification without its Atom XML.
+type DevIngestRequest struct {
+	VideoID   string `json:"video_id"`
+	ChannelID string `json:"channel_id"`
+	Title     string `json:"title"`
+	// PublishedAt defaults to the time of the request
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// DevIngestResponse reports the notification that was processed.
+type DevIngestResponse struct {
+	Status    string `json:"status"`
+	VideoID   string `json:"video_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// DevIngestHandler lets developers ingest a video notification from JSON, without crafting
+// and signing Atom XML. The request is rendered as a hub notification and handed to the
+// same EventProcessor as the webhook, skipping only signature verification. It must only
+// be routed in development.
+type DevIngestHandler struct {
+	processor service.EventProcessor
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewDevIngestHandler creates a dev ingest handler
+func NewDevIngestHandler(processor service.EventProcessor, logger *slog.Logger) *DevIngestHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DevIngestHandler{
+		processor: processor,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// ServeHTTP handles POST /api/v1/dev/ingest
+func (h *DevIngestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "only POST is supported", nil)
+		return
+	}
+
+	var req DevIngestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON in request body", nil)
+		return
+	}
+
+	if req.VideoID == "" || req.ChannelID == "" || req.Title == "" {
+		sendError(w, http.StatusBadRequest, "invalid_request", "video_id, channel_id and title are required", nil)
+		return
+	}
+
+	notification, err := h.notificationXML(&req)
+	if err != nil {
+		h.logger.Error("failed to render dev notification", "error", err, "video_id", req.VideoID)
+		sendError(w, http.StatusInternalServerError, "render_failed", "Failed to render notification", nil)
+		return
+	}
+
+	if err := h.processor.ProcessEvent(r.Context(), notification); err != nil {
+		h.logger.Error("failed to process dev notification", "error", err, "video_id", req.VideoID)
+		sendError(w, http.StatusInternalServerError, "processing_failed", "Failed to process event", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info("processed dev notification", "video_id", req.VideoID, "channel_id", req.ChannelID)
+	sendJSON(w, http.StatusOK, DevIngestResponse{
+		Status:    "processed",
+		VideoID:   req.VideoID,
+		ChannelID: req.ChannelID,
+	})
+}
+
+// notificationXML renders the request as the Atom feed the hub would deliver for it. The
+// updated time is the time of the request, so repeating a request is a new notification
+// rather than a duplicate.
+func (h *DevIngestHandler) notificationXML(req *DevIngestRequest) (string, error) {
+	now := h.now().UTC().Truncate(time.Second)
+
+	publishedAt := now
+	if req.PublishedAt != nil {
+		publishedAt = req.PublishedAt.UTC()
+	}
+
+	video := &parser.VideoData{
+		VideoID:     req.VideoID,
+		ChannelID:   req.ChannelID,
+		Title:       req.Title,
+		VideoURL:    fmt.Sprintf("https://www.youtube.com/watch?v=%s", req.VideoID),
+		PublishedAt: publishedAt,
+		UpdatedAt:   now,
+	}
+	return video.NotificationXML()
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevIngestHandler_MatchesXMLNotification(t *testing.T) {
+	xmlNotification := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <id>yt:video:dev123</id>
+    <yt:videoId>dev123</yt:videoId>
+    <yt:channelId>UCdev</yt:channelId>
+    <title>Dev Video</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=dev123"/>
+    <published>2025-01-15T10:00:00+00:00</published>
+    <updated>2025-01-15T11:00:00+00:00</updated>
+  </entry>
+</feed>`
+
+	var processed string
+	processor := new(mockProcessor)
+	processor.On("ProcessEvent", mock.Anything, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { processed = args.String(1) }).
+		Return(nil)
+
+	h := NewDevIngestHandler(processor, nil)
+	h.now = func() time.Time { return time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC) }
+
+	body := `{"video_id":"dev123","channel_id":"UCdev","title":"Dev Video","published_at":"2025-01-15T10:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/dev/ingest", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	processor.AssertExpectations(t)
+
+	// The processor builds the video, channel and video update rows from the parsed
+	// notification, so equal parses produce the same rows
+	want, err := parser.ParseAtomFeed(xmlNotification)
+	require.NoError(t, err)
+	got, err := parser.ParseAtomFeed(processed)
+	require.NoError(t, err)
+
+	assert.Equal(t, want.VideoID, got.VideoID)
+	assert.Equal(t, want.ChannelID, got.ChannelID)
+	assert.Equal(t, want.Title, got.Title)
+	assert.Equal(t, want.VideoURL, got.VideoURL)
+	assert.True(t, want.PublishedAt.Equal(got.PublishedAt), "published_at: want %v, got %v", want.PublishedAt, got.PublishedAt)
+	assert.True(t, want.UpdatedAt.Equal(got.UpdatedAt), "updated_at: want %v, got %v", want.UpdatedAt, got.UpdatedAt)
+	assert.Equal(t, want.AuthorName, got.AuthorName)
+	assert.False(t, got.IsDeleted)
+}
+
+func TestDevIngestHandler_InvalidRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{name: "wrong method", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "invalid JSON", method: http.MethodPost, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "missing title", method: http.MethodPost, body: `{"video_id":"dev123","channel_id":"UCdev"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := new(mockProcessor)
+			h := NewDevIngestHandler(processor, nil)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/dev/ingest", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			processor.AssertNotCalled(t, "ProcessEvent", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -3,12 +3,17 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"reflect"
 	"strings"
+	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 )
 
 // EnrichmentHandler handles operations for video enrichments
@@ -17,9 +22,18 @@ type EnrichmentHandler struct {
 	channelRepo     repository.ChannelEnrichmentRepository
 	videoLookupRepo repository.VideoRepository
 	queueClient     QueueClient
+	categoryNames   CategoryNameResolver
+	categoryRegion  string
+	dedupWindow     time.Duration
+	staleAfter      time.Duration
 	logger          *slog.Logger
 }
 
+// CategoryNameResolver resolves a video category ID to its human-readable name in a region
+type CategoryNameResolver interface {
+	CategoryName(ctx context.Context, regionCode, categoryID string) (string, error)
+}
+
 // QueueClient interface for enqueueing enrichment tasks
 type QueueClient interface {
 	EnqueueChannelEnrichment(ctx context.Context, channelID string) error
@@ -40,15 +54,59 @@ func NewEnrichmentHandler(
 		videoRepo:       videoRepo,
 		channelRepo:     channelRepo,
 		videoLookupRepo: videoLookupRepo,
+		staleAfter:      DefaultEnrichmentStaleAfter,
 		logger:          logger,
 	}
 }
 
+// DefaultEnrichmentStaleAfter is the age past which a served video enrichment is flagged is_stale
+const DefaultEnrichmentStaleAfter = 7 * 24 * time.Hour
+
 // SetQueueClient sets the queue client for enqueueing enrichment tasks
 func (h *EnrichmentHandler) SetQueueClient(queueClient QueueClient) {
 	h.queueClient = queueClient
 }
 
+// SetDedupWindow sets the default window within which a manual video enrichment request is
+// skipped because the video was already enriched (0 = always enqueue)
+func (h *EnrichmentHandler) SetDedupWindow(window time.Duration) {
+	h.dedupWindow = window
+}
+
+// SetStaleAfter sets the age past which a served video enrichment is flagged is_stale
+func (h *EnrichmentHandler) SetStaleAfter(staleAfter time.Duration) {
+	if staleAfter > 0 {
+		h.staleAfter = staleAfter
+	}
+}
+
+// SetCategoryNameResolver enables category_name in video enrichment responses,
+// resolving category IDs with the categories of the given region
+func (h *EnrichmentHandler) SetCategoryNameResolver(resolver CategoryNameResolver, regionCode string) {
+	h.categoryNames = resolver
+	h.categoryRegion = regionCode
+}
+
+// resolveCategoryName sets the enrichment's category name when a resolver is configured.
+// A failed lookup is logged and leaves the name unset rather than failing the request.
+func (h *EnrichmentHandler) resolveCategoryName(ctx context.Context, enrichment *model.VideoEnrichment) {
+	if h.categoryNames == nil || enrichment == nil || enrichment.CategoryID == nil {
+		return
+	}
+
+	name, err := h.categoryNames.CategoryName(ctx, h.categoryRegion, *enrichment.CategoryID)
+	if err != nil {
+		h.logger.Warn("Failed to resolve category name",
+			"category_id", *enrichment.CategoryID,
+			"region", h.categoryRegion,
+			"error", err)
+		return
+	}
+	if name != "" {
+		enrichment.CategoryName = &name
+	}
+}
+
 // BatchEnrichmentRequest represents a request for multiple enrichments
 type BatchEnrichmentRequest struct {
 	IDs []string `json:"ids"`
@@ -59,6 +117,10 @@ func (h *EnrichmentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/enrichments")
 
 	switch {
+	case path == "/videos/enqueue":
+		// POST /videos/enqueue
+		h.enqueueVideoEnrichmentBatch(w, r)
+		return
 	case strings.HasPrefix(path, "/videos/"):
 		// Handle both GET /videos/{id} and POST /videos/{id}/enqueue
 		pathAfterVideos := strings.TrimPrefix(path, "/videos/")
@@ -72,6 +134,10 @@ func (h *EnrichmentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// POST /videos/{id}/enqueue
 			h.enqueueVideoEnrichment(w, r, parts[0])
 			return
+		} else if len(parts) == 2 && parts[0] != "" && parts[1] == "compare" {
+			// GET /videos/{id}/compare?from=<ts>&to=<ts>
+			h.compareVideoEnrichments(w, r, parts[0])
+			return
 		}
 	case path == "/videos/batch" && r.Method == http.MethodPost:
 		h.getBatchVideoEnrichments(w, r)
@@ -93,6 +159,10 @@ func (h *EnrichmentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case path == "/channels/batch" && r.Method == http.MethodPost:
 		h.getBatchChannelEnrichments(w, r)
 		return
+	case path == "/export":
+		// GET /export?since=<ts>&cursor=<cursor>&limit=<n>
+		h.exportVideoEnrichments(w, r)
+		return
 	}
 
 	http.NotFound(w, r)
@@ -105,6 +175,12 @@ func (h *EnrichmentHandler) getVideoEnrichment(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	fields, err := parseFields(r, reflect.TypeOf(model.VideoEnrichment{}))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	enrichment, err := h.videoRepo.GetLatestEnrichment(r.Context(), videoID)
 	if err == db.ErrNotFound {
 		http.Error(w, "Enrichment not found", http.StatusNotFound)
@@ -118,8 +194,147 @@ func (h *EnrichmentHandler) getVideoEnrichment(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	h.resolveCategoryName(r.Context(), enrichment)
+	enrichment.SetFreshness(time.Now(), h.staleAfter)
+
+	writeFields(w, enrichment, fields)
+}
+
+// compareVideoEnrichments compares a video's enrichments as of two points in time.
+// Each side uses the latest enrichment taken at or before its timestamp.
+func (h *EnrichmentHandler) compareVideoEnrichments(w http.ResponseWriter, r *http.Request, videoID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from, err := parseTimestamp(r, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimestamp(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from == nil || to == nil {
+		http.Error(w, "from and to are required", http.StatusBadRequest)
+		return
+	}
+	if from.After(*to) {
+		http.Error(w, "from must not be after to", http.StatusBadRequest)
+		return
+	}
+
+	toEnrichment, err := h.videoRepo.GetEnrichmentAsOf(r.Context(), videoID, *to)
+	if err == db.ErrNotFound {
+		http.Error(w, "No enrichment found before to", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get video enrichment",
+			"video_id", videoID,
+			"as_of", to,
+			"error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// The video may not have been enriched yet at the from timestamp; compare against nothing
+	fromEnrichment, err := h.videoRepo.GetEnrichmentAsOf(r.Context(), videoID, *from)
+	if err != nil && err != db.ErrNotFound {
+		h.logger.Error("Failed to get video enrichment",
+			"video_id", videoID,
+			"as_of", from,
+			"error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	comparison := model.CompareVideoEnrichments(videoID, fromEnrichment, toEnrichment)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":       from,
+		"to":         to,
+		"comparison": comparison,
+	})
+}
+
+// enrichmentExportResponse is one page of an incremental enrichment export
+type enrichmentExportResponse struct {
+	Items      []*model.VideoEnrichment `json:"items"`
+	Count      int                      `json:"count"`
+	Limit      int                      `json:"limit"`
+	Since      *time.Time               `json:"since"`
+	NextCursor *string                  `json:"next_cursor"`
+	HasMore    bool                     `json:"has_more"`
+}
+
+// exportVideoEnrichments returns video enrichments created at or after since, oldest first, for
+// incremental exports. next_cursor is the high watermark: passing it back as cursor resumes
+// after the last enrichment returned, and it is kept unchanged when nothing new was found.
+// Enrichments newer than repository.ExportSafetyLag are only returned by a later request.
+func (h *EnrichmentHandler) exportVideoEnrichments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	since, err := parseTimestamp(r, "since")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var after *repository.EnrichmentCursor
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		after, err = repository.ParseEnrichmentCursor(cursor)
+		if err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+	}
+	if since == nil && after == nil {
+		http.Error(w, "since or cursor is required", http.StatusBadRequest)
+		return
+	}
+
+	var sinceTime time.Time
+	if since != nil {
+		sinceTime = *since
+	}
+
+	limit := parseLimit(r)
+	enrichments, err := h.videoRepo.ExportEnrichments(r.Context(), sinceTime, after, limit)
+	if err != nil {
+		h.logger.Error("Failed to export video enrichments",
+			"since", since,
+			"cursor", cursor,
+			"error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var nextCursor *string
+	if len(enrichments) > 0 {
+		encoded := repository.NewEnrichmentCursor(enrichments[len(enrichments)-1]).Encode()
+		nextCursor = &encoded
+	} else if cursor != "" {
+		nextCursor = &cursor
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(enrichment)
+	json.NewEncoder(w).Encode(enrichmentExportResponse{
+		Items:      enrichments,
+		Count:      len(enrichments),
+		Limit:      limit,
+		Since:      since,
+		NextCursor: nextCursor,
+		HasMore:    len(enrichments) == limit,
+	})
 }
 
 // getBatchVideoEnrichments returns enrichments for multiple videos
@@ -144,6 +359,12 @@ func (h *EnrichmentHandler) getBatchVideoEnrichments(w http.ResponseWriter, r *h
 		return
 	}
 
+	now := time.Now()
+	for _, enrichment := range enrichments {
+		h.resolveCategoryName(r.Context(), enrichment)
+		enrichment.SetFreshness(now, h.staleAfter)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(enrichments)
 }
@@ -155,6 +376,12 @@ func (h *EnrichmentHandler) getChannelEnrichment(w http.ResponseWriter, r *http.
 		return
 	}
 
+	fields, err := parseFields(r, reflect.TypeOf(model.ChannelEnrichment{}))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	enrichment, err := h.channelRepo.GetLatest(r.Context(), channelID)
 	if err == db.ErrNotFound {
 		http.Error(w, "Enrichment not found", http.StatusNotFound)
@@ -168,8 +395,137 @@ func (h *EnrichmentHandler) getChannelEnrichment(w http.ResponseWriter, r *http.
 		return
 	}
 
+	writeFields(w, enrichment, fields)
+}
+
+// parseFields returns the field names listed in the fields query parameter, or nil when it is
+// absent. Every name must be a JSON field of t.
+func parseFields(r *http.Request, t reflect.Type) ([]string, error) {
+	param := r.URL.Query().Get("fields")
+	if param == "" {
+		return nil, nil
+	}
+
+	known := structSchema(t)["properties"].(map[string]interface{})
+	var fields []string
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown field: %s", name)
+		}
+		fields = append(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("fields must list at least one field")
+	}
+	return fields, nil
+}
+
+// writeFields writes v as JSON, keeping only the given fields when there are any.
+// Requested fields that v omits when empty are left out.
+func writeFields(w http.ResponseWriter, v interface{}, fields []string) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(enrichment)
+	if fields == nil {
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	data, err := json.Marshal(v)
+	var all map[string]json.RawMessage
+	if err == nil {
+		err = json.Unmarshal(data, &all)
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	subset := make(map[string]json.RawMessage, len(fields))
+	for _, name := range fields {
+		if value, ok := all[name]; ok {
+			subset[name] = value
+		}
+	}
+	json.NewEncoder(w).Encode(subset)
+}
+
+// HandleGetChannelGrowth handles GET /api/v1/channels/{id}/growth
+// It returns the channel's enrichments oldest first with subscriber and view deltas and per-day velocity.
+func (h *EnrichmentHandler) HandleGetChannelGrowth(w http.ResponseWriter, r *http.Request, channelID string) {
+	if r.Method != http.MethodGet {
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	limit := parseLimit(r)
+
+	// GetHistory returns the newest enrichments, so the growth series covers the most recent period
+	history, err := h.channelRepo.GetHistory(r.Context(), channelID, limit)
+	if err != nil {
+		h.logger.Error("failed to get channel enrichment history", "error", err, "channel_id", channelID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve channel enrichment history", nil)
+		return
+	}
+
+	points := model.ComputeChannelGrowth(history)
+
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"channel_id": channelID,
+		"items":      points,
+		"total":      len(points),
+		"limit":      limit,
+	})
+}
+
+// MaxBatchLatestChannels caps the channel IDs accepted by POST /api/v1/channels/batch-latest
+const MaxBatchLatestChannels = 50
+
+// HandleBatchLatestChannelEnrichments handles POST /api/v1/channels/batch-latest
+// It returns the latest enrichment of each requested channel, keyed by channel ID.
+// Channels that were never enriched are omitted.
+func (h *EnrichmentHandler) HandleBatchLatestChannelEnrichments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	var req BatchEnrichmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid request body", err.Error(), nil)
+		return
+	}
+
+	channelIDs := make([]string, 0, len(req.IDs))
+	seen := make(map[string]bool, len(req.IDs))
+	for _, channelID := range req.IDs {
+		channelID = strings.TrimSpace(channelID)
+		if channelID == "" || seen[channelID] {
+			continue
+		}
+		seen[channelID] = true
+		channelIDs = append(channelIDs, channelID)
+	}
+
+	if len(channelIDs) == 0 {
+		sendError(w, http.StatusBadRequest, "validation failed", "ids is required", nil)
+		return
+	}
+	if len(channelIDs) > MaxBatchLatestChannels {
+		sendError(w, http.StatusBadRequest, "validation failed", fmt.Sprintf("at most %d ids are allowed", MaxBatchLatestChannels), nil)
+		return
+	}
+
+	enrichments, err := h.channelRepo.GetBatchLatest(r.Context(), channelIDs)
+	if err != nil {
+		h.logger.Error("failed to get batch latest channel enrichments", "error", err, "channel_ids", channelIDs)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve channel enrichments", nil)
+		return
+	}
+
+	sendJSON(w, http.StatusOK, enrichments)
 }
 
 // getBatchChannelEnrichments returns enrichments for multiple channels
@@ -248,6 +604,38 @@ func (h *EnrichmentHandler) enqueueVideoEnrichment(w http.ResponseWriter, r *htt
 		return
 	}
 
+	window, err := h.requestDedupWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Skip videos enriched within the dedup window unless forced
+	if window > 0 {
+		latest, err := h.videoRepo.GetLatestEnrichment(r.Context(), videoID)
+		if err != nil && !db.IsNotFound(err) {
+			h.logger.Error("Failed to get latest enrichment",
+				"video_id", videoID,
+				"error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if latest != nil && time.Since(latest.EnrichedAt) < window {
+			h.logger.Info("Video enrichment skipped within dedup window",
+				"video_id", videoID,
+				"enriched_at", latest.EnrichedAt,
+				"dedup_window", window)
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{
+				"status":      "skipped",
+				"video_id":    videoID,
+				"enriched_at": latest.EnrichedAt.Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
 	// Look up the video to get its channel_id
 	video, err := h.videoLookupRepo.GetVideoByID(r.Context(), videoID)
 	if err == db.ErrNotFound {
@@ -285,3 +673,151 @@ func (h *EnrichmentHandler) enqueueVideoEnrichment(w http.ResponseWriter, r *htt
 		"video_id": videoID,
 	})
 }
+
+// requestDedupWindow returns the dedup window for a manual video enrichment request: the
+// dedup_window query parameter or the configured default, and 0 when force=true
+func (h *EnrichmentHandler) requestDedupWindow(r *http.Request) (time.Duration, error) {
+	force, err := parseBool(r, "force")
+	if err != nil {
+		return 0, err
+	}
+	if force != nil && *force {
+		return 0, nil
+	}
+
+	window := h.dedupWindow
+	if raw := r.URL.Query().Get("dedup_window"); raw != "" {
+		window, err = time.ParseDuration(raw)
+		if err != nil || window < 0 {
+			return 0, errors.New("Invalid dedup_window (expected a non-negative duration such as 24h)")
+		}
+	}
+	return window, nil
+}
+
+// maxBatchEnqueueIDs caps the number of videos in one batch enqueue request, so its
+// enqueue calls fit within the API request timeout
+const maxBatchEnqueueIDs = 100
+
+// batchEnqueueResponse summarizes a batch video enrichment request
+type batchEnqueueResponse struct {
+	Requested int `json:"requested"` // distinct non-empty IDs in the request
+	Enqueued  int `json:"enqueued"`
+
+	// Skipped counts videos enriched within the dedup window
+	Skipped  int      `json:"skipped"`
+	NotFound []string `json:"not_found"`
+	Failed   []string `json:"failed"`
+}
+
+// enqueueVideoEnrichmentBatch enqueues enrichment of several videos. Duplicate IDs and videos
+// enriched within the dedup window are dropped first; when nothing is left the request is a
+// successful no-op.
+func (h *EnrichmentHandler) enqueueVideoEnrichmentBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.queueClient == nil {
+		h.logger.Error("Queue client not configured")
+		http.Error(w, "Enrichment queue not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	window, err := h.requestDedupWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req BatchEnrichmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	videoIDs := make([]string, 0, len(req.IDs))
+	seen := make(map[string]bool, len(req.IDs))
+	for _, videoID := range req.IDs {
+		videoID = strings.TrimSpace(videoID)
+		if videoID == "" || seen[videoID] {
+			continue
+		}
+		seen[videoID] = true
+		videoIDs = append(videoIDs, videoID)
+	}
+	if len(videoIDs) > maxBatchEnqueueIDs {
+		http.Error(w, fmt.Sprintf("Too many IDs (max %d)", maxBatchEnqueueIDs), http.StatusBadRequest)
+		return
+	}
+
+	resp := batchEnqueueResponse{
+		Requested: len(videoIDs),
+		NotFound:  []string{},
+		Failed:    []string{},
+	}
+
+	if window > 0 && len(videoIDs) > 0 {
+		latest, err := h.videoRepo.GetBatchLatestEnrichments(r.Context(), videoIDs)
+		if err != nil {
+			h.logger.Error("Failed to get latest enrichments",
+				"video_ids", videoIDs,
+				"error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		pending := videoIDs[:0]
+		for _, videoID := range videoIDs {
+			if enrichment, ok := latest[videoID]; ok && enrichment != nil && time.Since(enrichment.EnrichedAt) < window {
+				resp.Skipped++
+				continue
+			}
+			pending = append(pending, videoID)
+		}
+		videoIDs = pending
+	}
+
+	videos, err := h.videoLookupRepo.GetVideosByIDs(r.Context(), videoIDs)
+	if err != nil {
+		h.logger.Error("Failed to look up videos",
+			"video_ids", videoIDs,
+			"error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	for _, videoID := range videoIDs {
+		video, ok := videos[videoID]
+		if !ok {
+			resp.NotFound = append(resp.NotFound, videoID)
+			continue
+		}
+
+		if err := h.queueClient.EnqueueVideoEnrichment(r.Context(), videoID, video.ChannelID, 0); err != nil {
+			h.logger.Error("Failed to enqueue video enrichment",
+				"video_id", videoID,
+				"channel_id", video.ChannelID,
+				"error", err)
+			resp.Failed = append(resp.Failed, videoID)
+			continue
+		}
+		resp.Enqueued++
+	}
+
+	h.logger.Info("Batch video enrichment processed",
+		"requested", resp.Requested,
+		"enqueued", resp.Enqueued,
+		"skipped", resp.Skipped,
+		"not_found", len(resp.NotFound),
+		"failed", len(resp.Failed))
+
+	status := http.StatusOK
+	if resp.Enqueued > 0 {
+		status = http.StatusAccepted
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -1,21 +1,33 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/queue"
 )
 
 const (
 	maxJobLimit = 500
 )
 
+// TaskCanceller removes queued tasks so cancelled jobs never run
+type TaskCanceller interface {
+	CancelTask(ctx context.Context, jobType, taskID string) error
+}
+
 // EnrichmentJobHandler handles operations for enrichment jobs
 type EnrichmentJobHandler struct {
-	repo   repository.EnrichmentJobRepository
-	logger *slog.Logger
+	repo          repository.EnrichmentJobRepository
+	taskCanceller TaskCanceller
+	logger        *slog.Logger
 }
 
 // NewEnrichmentJobHandler creates a new EnrichmentJobHandler
@@ -29,14 +41,95 @@ func NewEnrichmentJobHandler(repo repository.EnrichmentJobRepository, logger *sl
 	}
 }
 
-// ServeHTTP handles GET /api/v1/jobs requests
+// SetTaskCanceller sets the queue used to delete tasks of cancelled jobs
+func (h *EnrichmentJobHandler) SetTaskCanceller(taskCanceller TaskCanceller) {
+	h.taskCanceller = taskCanceller
+}
+
+// ServeHTTP handles GET /api/v1/jobs and DELETE /api/v1/jobs/{id} requests
 func (h *EnrichmentJobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/jobs"), "/")
+
+	if path == "" {
+		if r.Method != http.MethodGet {
+			sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+			return
+		}
+		h.handleList(w, r)
+		return
+	}
+
+	if strings.Contains(path, "/") {
+		sendError(w, http.StatusNotFound, "not found", "", nil)
+		return
+	}
+
+	if r.Method != http.MethodDelete {
 		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
 		return
 	}
 
-	h.handleList(w, r)
+	id, err := strconv.ParseInt(path, 10, 64)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "invalid job ID", "job ID must be a valid integer", nil)
+		return
+	}
+
+	h.handleCancel(w, r, id)
+}
+
+// handleCancel handles DELETE /api/v1/jobs/{id}. Only pending jobs can be cancelled;
+// their queued task is deleted before the job is marked cancelled.
+func (h *EnrichmentJobHandler) handleCancel(w http.ResponseWriter, r *http.Request, id int64) {
+	job, err := h.repo.GetJobByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("job %d not found", id), nil)
+			return
+		}
+		h.logger.Error("failed to get enrichment job", "error", err, "job_id", id)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve job", nil)
+		return
+	}
+
+	if job.Status != "pending" {
+		sendError(w, http.StatusConflict, "conflict", "only pending jobs can be cancelled",
+			map[string]interface{}{"status": job.Status})
+		return
+	}
+
+	if job.AsynqTaskID != nil {
+		if h.taskCanceller == nil {
+			sendError(w, http.StatusServiceUnavailable, "service unavailable", "job queue is not configured", nil)
+			return
+		}
+
+		if err := h.taskCanceller.CancelTask(r.Context(), job.JobType, *job.AsynqTaskID); err != nil {
+			if errors.Is(err, queue.ErrTaskActive) {
+				sendError(w, http.StatusConflict, "conflict", "job is already being processed",
+					map[string]interface{}{"status": "processing"})
+				return
+			}
+			h.logger.Error("failed to cancel queued task", "error", err, "job_id", id, "task_id", *job.AsynqTaskID)
+			sendError(w, http.StatusInternalServerError, "internal server error", "failed to cancel queued task", nil)
+			return
+		}
+	}
+
+	if err := h.repo.CancelPendingJob(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrJobNotPending) {
+			sendError(w, http.StatusConflict, "conflict", "job started before it could be cancelled", nil)
+			return
+		}
+		h.logger.Error("failed to cancel enrichment job", "error", err, "job_id", id)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to cancel job", nil)
+		return
+	}
+
+	h.logger.Info("cancelled enrichment job", "job_id", id, "job_type", job.JobType, "video_id", job.VideoID)
+
+	job.Status = "cancelled"
+	sendJSON(w, http.StatusOK, job)
 }
 
 func (h *EnrichmentJobHandler) handleList(w http.ResponseWriter, r *http.Request) {
@@ -8,8 +8,10 @@ import (
 	"testing"
 	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/queue"
 )
 
 // Mock enrichment job repository
@@ -33,7 +35,12 @@ func (m *mockEnrichmentJobRepo) CreateJob(ctx context.Context, job *model.Enrich
 }
 
 func (m *mockEnrichmentJobRepo) GetJobByID(ctx context.Context, id int64) (*model.EnrichmentJob, error) {
-	return nil, nil
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+	return nil, db.ErrNotFound
 }
 
 func (m *mockEnrichmentJobRepo) GetJobByAsynqID(ctx context.Context, asynqTaskID string) (*model.EnrichmentJob, error) {
@@ -56,6 +63,24 @@ func (m *mockEnrichmentJobRepo) MarkJobFailed(ctx context.Context, id int64, err
 	return nil
 }
 
+func (m *mockEnrichmentJobRepo) MarkJobDeferred(ctx context.Context, id int64, reason string, nextRetryAt time.Time) error {
+	return nil
+}
+
+func (m *mockEnrichmentJobRepo) MarkJobSkipped(ctx context.Context, id int64, reason string) error {
+	return nil
+}
+
+func (m *mockEnrichmentJobRepo) CancelPendingJob(ctx context.Context, id int64) error {
+	for _, job := range m.jobs {
+		if job.ID == id && job.Status == "pending" {
+			job.Status = "cancelled"
+			return nil
+		}
+	}
+	return repository.ErrJobNotPending
+}
+
 func (m *mockEnrichmentJobRepo) IncrementAttempts(ctx context.Context, id int64) error {
 	return nil
 }
@@ -341,6 +366,111 @@ func TestEnrichmentJobHandler_MethodNotAllowed(t *testing.T) {
 	}
 }
 
+// mockTaskCanceller records cancelled task IDs
+type mockTaskCanceller struct {
+	cancelled []string
+	err       error
+}
+
+func (m *mockTaskCanceller) CancelTask(ctx context.Context, jobType, taskID string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.cancelled = append(m.cancelled, taskID)
+	return nil
+}
+
+func TestEnrichmentJobHandler_Cancel(t *testing.T) {
+	taskID := func(s string) *string { return &s }
+
+	newHandler := func(canceller *mockTaskCanceller) (*EnrichmentJobHandler, *mockEnrichmentJobRepo) {
+		repo := newMockEnrichmentJobRepo()
+		repo.CreateJob(context.Background(), &model.EnrichmentJob{JobType: queue.TypeEnrichVideo, VideoID: "video1", Status: "pending", AsynqTaskID: taskID("task-pending")})
+		repo.CreateJob(context.Background(), &model.EnrichmentJob{JobType: queue.TypeEnrichVideo, VideoID: "video2", Status: "completed", AsynqTaskID: taskID("task-completed")})
+
+		handler := NewEnrichmentJobHandler(repo, nil)
+		handler.SetTaskCanceller(canceller)
+		return handler, repo
+	}
+
+	cancel := func(handler *EnrichmentJobHandler, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/jobs/"+id, nil)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("cancels a pending job and its queued task", func(t *testing.T) {
+		canceller := &mockTaskCanceller{}
+		handler, repo := newHandler(canceller)
+
+		resp := cancel(handler, "1")
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+
+		var job model.EnrichmentJob
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if job.Status != "cancelled" {
+			t.Errorf("expected status cancelled in response, got %s", job.Status)
+		}
+		if repo.jobs[0].Status != "cancelled" {
+			t.Errorf("expected job to be marked cancelled, got %s", repo.jobs[0].Status)
+		}
+		if len(canceller.cancelled) != 1 || canceller.cancelled[0] != "task-pending" {
+			t.Errorf("expected task-pending to be deleted from the queue, got %v", canceller.cancelled)
+		}
+	})
+
+	t.Run("rejects cancelling a completed job", func(t *testing.T) {
+		canceller := &mockTaskCanceller{}
+		handler, repo := newHandler(canceller)
+
+		resp := cancel(handler, "2")
+		if resp.Code != http.StatusConflict {
+			t.Fatalf("expected status %d, got %d", http.StatusConflict, resp.Code)
+		}
+		if repo.jobs[1].Status != "completed" {
+			t.Errorf("expected job to stay completed, got %s", repo.jobs[1].Status)
+		}
+		if len(canceller.cancelled) != 0 {
+			t.Errorf("expected no queued task to be deleted, got %v", canceller.cancelled)
+		}
+	})
+
+	t.Run("rejects cancelling a task a worker already picked up", func(t *testing.T) {
+		handler, repo := newHandler(&mockTaskCanceller{err: queue.ErrTaskActive})
+
+		resp := cancel(handler, "1")
+		if resp.Code != http.StatusConflict {
+			t.Fatalf("expected status %d, got %d", http.StatusConflict, resp.Code)
+		}
+		if repo.jobs[0].Status != "pending" {
+			t.Errorf("expected job to stay pending, got %s", repo.jobs[0].Status)
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		handler, _ := newHandler(&mockTaskCanceller{})
+
+		resp := cancel(handler, "99")
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+
+	t.Run("invalid job ID", func(t *testing.T) {
+		handler, _ := newHandler(&mockTaskCanceller{})
+
+		resp := cancel(handler, "abc")
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+}
+
 func TestEnrichmentJobHandler_EmptyResults(t *testing.T) {
 	repo := newMockEnrichmentJobRepo()
 	handler := NewEnrichmentJobHandler(repo, nil)
@@ -0,0 +1,788 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// Mock video enrichment repository
+type mockEnrichmentRepo struct {
+	enrichments []*model.VideoEnrichment
+}
+
+func (m *mockEnrichmentRepo) CreateEnrichment(ctx context.Context, enrichment *model.VideoEnrichment) error {
+	m.enrichments = append(m.enrichments, enrichment)
+	return nil
+}
+
+func (m *mockEnrichmentRepo) GetLatestEnrichment(ctx context.Context, videoID string) (*model.VideoEnrichment, error) {
+	return m.GetEnrichmentAsOf(ctx, videoID, time.Now())
+}
+
+func (m *mockEnrichmentRepo) GetEnrichmentHistory(ctx context.Context, videoID string, limit int) ([]*model.VideoEnrichment, error) {
+	return nil, nil
+}
+
+func (m *mockEnrichmentRepo) GetUnenrichedVideos(ctx context.Context, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockEnrichmentRepo) GetVideosNeedingReenrichment(ctx context.Context, olderThan time.Duration, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockEnrichmentRepo) GetEnrichmentCount(ctx context.Context) (int64, error) {
+	return int64(len(m.enrichments)), nil
+}
+
+func (m *mockEnrichmentRepo) GetEnrichedVideoCount(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockEnrichmentRepo) GetBatchLatestEnrichments(ctx context.Context, videoIDs []string) (map[string]*model.VideoEnrichment, error) {
+	results := make(map[string]*model.VideoEnrichment)
+	for _, videoID := range videoIDs {
+		if latest, err := m.GetLatestEnrichment(ctx, videoID); err == nil {
+			results[videoID] = latest
+		}
+	}
+	return results, nil
+}
+
+func (m *mockEnrichmentRepo) GetEnrichmentAsOf(ctx context.Context, videoID string, asOf time.Time) (*model.VideoEnrichment, error) {
+	var latest *model.VideoEnrichment
+	for _, e := range m.enrichments {
+		if e.VideoID != videoID || e.EnrichedAt.After(asOf) {
+			continue
+		}
+		if latest == nil || e.EnrichedAt.After(latest.EnrichedAt) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return nil, db.ErrNotFound
+	}
+	return latest, nil
+}
+
+func (m *mockEnrichmentRepo) ExportEnrichments(ctx context.Context, since time.Time, after *repository.EnrichmentCursor, limit int) ([]*model.VideoEnrichment, error) {
+	results := []*model.VideoEnrichment{}
+	for _, e := range m.enrichments {
+		if e.CreatedAt.Before(since) {
+			continue
+		}
+		if after != nil && (e.CreatedAt.Before(after.CreatedAt) || (e.CreatedAt.Equal(after.CreatedAt) && e.ID <= after.ID)) {
+			continue
+		}
+		results = append(results, e)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].CreatedAt.Equal(results[j].CreatedAt) {
+			return results[i].CreatedAt.Before(results[j].CreatedAt)
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Mock channel enrichment repository
+type mockChannelEnrichmentRepo struct {
+	enrichments []*model.ChannelEnrichment
+}
+
+func (m *mockChannelEnrichmentRepo) Create(ctx context.Context, enrichment *model.ChannelEnrichment) error {
+	m.enrichments = append(m.enrichments, enrichment)
+	return nil
+}
+
+func (m *mockChannelEnrichmentRepo) GetLatest(ctx context.Context, channelID string) (*model.ChannelEnrichment, error) {
+	history, _ := m.GetHistory(ctx, channelID, 1)
+	if len(history) == 0 {
+		return nil, db.ErrNotFound
+	}
+	return history[0], nil
+}
+
+func (m *mockChannelEnrichmentRepo) GetHistory(ctx context.Context, channelID string, limit int) ([]*model.ChannelEnrichment, error) {
+	var history []*model.ChannelEnrichment
+	for _, e := range m.enrichments {
+		if e.ChannelID == channelID {
+			history = append(history, e)
+		}
+	}
+	// Newest first, like the repository
+	sort.Slice(history, func(i, j int) bool { return history[i].EnrichedAt.After(history[j].EnrichedAt) })
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
+func (m *mockChannelEnrichmentRepo) GetBatchLatest(ctx context.Context, channelIDs []string) (map[string]*model.ChannelEnrichment, error) {
+	results := make(map[string]*model.ChannelEnrichment)
+	for _, channelID := range channelIDs {
+		if latest, err := m.GetLatest(ctx, channelID); err == nil {
+			results[channelID] = latest
+		}
+	}
+	return results, nil
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestEnrichmentHandler_CompareVideoEnrichments(t *testing.T) {
+	day1 := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+
+	public := "public"
+	unlisted := "unlisted"
+
+	repo := &mockEnrichmentRepo{
+		enrichments: []*model.VideoEnrichment{
+			{VideoID: "video123", EnrichedAt: day1, ViewCount: int64Ptr(100), LikeCount: int64Ptr(10), PrivacyStatus: &unlisted, Tags: []string{"a"}},
+			{VideoID: "video123", EnrichedAt: day2, ViewCount: int64Ptr(250), LikeCount: int64Ptr(12), PrivacyStatus: &unlisted, Tags: []string{"a"}},
+			{VideoID: "video123", EnrichedAt: day3, ViewCount: int64Ptr(1000), LikeCount: int64Ptr(40), PrivacyStatus: &public, Tags: []string{"a", "b"}},
+		},
+	}
+	handler := NewEnrichmentHandler(repo, nil, nil, nil)
+
+	compare := func(from, to time.Time) *httptest.ResponseRecorder {
+		query := url.Values{}
+		query.Set("from", from.Format(time.RFC3339))
+		query.Set("to", to.Format(time.RFC3339))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/enrichments/videos/video123/compare?"+query.Encode(), nil)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("compares nearest enrichments before each timestamp", func(t *testing.T) {
+		// from lands between day1 and day2, so day1 is used; to lands after day3
+		resp := compare(day1.Add(time.Hour), day3.Add(time.Hour))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+
+		var result struct {
+			Comparison model.VideoEnrichmentComparison `json:"comparison"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		comparison := result.Comparison
+
+		if comparison.FromEnrichedAt == nil || !comparison.FromEnrichedAt.Equal(day1) {
+			t.Errorf("expected from enrichment at %v, got %v", day1, comparison.FromEnrichedAt)
+		}
+		if comparison.ToEnrichedAt == nil || !comparison.ToEnrichedAt.Equal(day3) {
+			t.Errorf("expected to enrichment at %v, got %v", day3, comparison.ToEnrichedAt)
+		}
+
+		views := comparison.Metrics["view_count"]
+		if views.Delta == nil || *views.Delta != 900 {
+			t.Errorf("expected view_count delta 900, got %v", views.Delta)
+		}
+		likes := comparison.Metrics["like_count"]
+		if likes.Delta == nil || *likes.Delta != 30 {
+			t.Errorf("expected like_count delta 30, got %v", likes.Delta)
+		}
+
+		if _, ok := comparison.Changes["privacy_status"]; !ok {
+			t.Error("expected privacy_status change")
+		}
+		if _, ok := comparison.Changes["tags"]; !ok {
+			t.Error("expected tags change")
+		}
+		if _, ok := comparison.Changes["description"]; ok {
+			t.Error("did not expect unchanged description in changes")
+		}
+	})
+
+	t.Run("from side without prior enrichment", func(t *testing.T) {
+		resp := compare(day1.Add(-time.Hour), day2)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+		}
+
+		var result struct {
+			Comparison model.VideoEnrichmentComparison `json:"comparison"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		comparison := result.Comparison
+
+		if comparison.FromEnrichedAt != nil {
+			t.Errorf("expected no from enrichment, got %v", comparison.FromEnrichedAt)
+		}
+		views := comparison.Metrics["view_count"]
+		if views.From != nil || views.Delta != nil {
+			t.Errorf("expected no from value or delta, got from=%v delta=%v", views.From, views.Delta)
+		}
+		if views.To == nil || *views.To != 250 {
+			t.Errorf("expected to view_count 250, got %v", views.To)
+		}
+		if len(comparison.Changes) != 0 {
+			t.Errorf("expected no field changes, got %v", comparison.Changes)
+		}
+	})
+
+	t.Run("no enrichment before to", func(t *testing.T) {
+		resp := compare(day1.Add(-2*time.Hour), day1.Add(-time.Hour))
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+
+	t.Run("from after to", func(t *testing.T) {
+		resp := compare(day3, day1)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+
+	t.Run("missing timestamps", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/enrichments/videos/video123/compare", nil)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+}
+
+func TestEnrichmentHandler_GetChannelGrowth(t *testing.T) {
+	day1 := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	day3 := day1.Add(48 * time.Hour)
+
+	channelRepo := &mockChannelEnrichmentRepo{
+		enrichments: []*model.ChannelEnrichment{
+			{ChannelID: "UCgrowth", EnrichedAt: day3, SubscriberCount: int64Ptr(1300), ViewCount: int64Ptr(50000)},
+			{ChannelID: "UCgrowth", EnrichedAt: day1, SubscriberCount: int64Ptr(1000), ViewCount: int64Ptr(40000)},
+			{ChannelID: "UCother", EnrichedAt: day1, SubscriberCount: int64Ptr(5)},
+		},
+	}
+	handler := NewEnrichmentHandler(nil, channelRepo, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/channels/UCgrowth/growth", nil)
+	resp := httptest.NewRecorder()
+	handler.HandleGetChannelGrowth(resp, req, "UCgrowth")
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	var result struct {
+		Items []model.ChannelGrowthPoint `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(result.Items) != 2 {
+		t.Fatalf("expected 2 growth points, got %d", len(result.Items))
+	}
+
+	first, second := result.Items[0], result.Items[1]
+	if !first.EnrichedAt.Equal(day1) || !second.EnrichedAt.Equal(day3) {
+		t.Errorf("expected points ordered oldest first, got %v then %v", first.EnrichedAt, second.EnrichedAt)
+	}
+	if first.SubscriberDelta != nil {
+		t.Errorf("expected no delta for the first point, got %d", *first.SubscriberDelta)
+	}
+	if second.SubscriberDelta == nil || *second.SubscriberDelta != 300 {
+		t.Errorf("expected subscriber delta 300, got %v", second.SubscriberDelta)
+	}
+	if second.ViewDelta == nil || *second.ViewDelta != 10000 {
+		t.Errorf("expected view delta 10000, got %v", second.ViewDelta)
+	}
+	if second.SubscribersPerDay == nil || *second.SubscribersPerDay != 150 {
+		t.Errorf("expected 150 subscribers per day, got %v", second.SubscribersPerDay)
+	}
+	if second.VideoDelta != nil {
+		t.Errorf("expected no video delta when video counts are unknown, got %d", *second.VideoDelta)
+	}
+}
+
+func TestEnrichmentHandler_BatchLatestChannelEnrichments(t *testing.T) {
+	older := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+
+	channelRepo := &mockChannelEnrichmentRepo{
+		enrichments: []*model.ChannelEnrichment{
+			{ChannelID: "UCone", EnrichedAt: older, SubscriberCount: int64Ptr(100)},
+			{ChannelID: "UCone", EnrichedAt: newer, SubscriberCount: int64Ptr(150)},
+			{ChannelID: "UCtwo", EnrichedAt: older, SubscriberCount: int64Ptr(20)},
+		},
+	}
+	handler := NewEnrichmentHandler(nil, channelRepo, nil, nil)
+
+	body := `{"ids": ["UCone", "UCunenriched", "UCtwo"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/channels/batch-latest", strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	handler.HandleBatchLatestChannelEnrichments(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	var result map[string]model.ChannelEnrichment
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("expected only the 2 enriched channels, got %v", result)
+	}
+	if _, ok := result["UCunenriched"]; ok {
+		t.Errorf("expected unenriched channel to be omitted")
+	}
+	if got := result["UCone"].SubscriberCount; got == nil || *got != 150 {
+		t.Errorf("expected the latest UCone enrichment with 150 subscribers, got %v", got)
+	}
+	if got := result["UCtwo"].SubscriberCount; got == nil || *got != 20 {
+		t.Errorf("expected UCtwo enrichment with 20 subscribers, got %v", got)
+	}
+}
+
+func TestEnrichmentHandler_BatchLatestChannelEnrichments_Validation(t *testing.T) {
+	tooMany := make([]string, MaxBatchLatestChannels+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("UC%d", i)
+	}
+	tooManyBody, _ := json.Marshal(BatchEnrichmentRequest{IDs: tooMany})
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{name: "no ids", method: http.MethodPost, body: `{"ids": []}`, wantStatus: http.StatusBadRequest},
+		{name: "too many ids", method: http.MethodPost, body: string(tooManyBody), wantStatus: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPost, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodGet, body: "", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewEnrichmentHandler(nil, &mockChannelEnrichmentRepo{}, nil, nil)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/channels/batch-latest", strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+			handler.HandleBatchLatestChannelEnrichments(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, resp.Code, resp.Body.String())
+			}
+		})
+	}
+}
+
+// stubCategoryNames resolves category IDs from a fixed table
+type stubCategoryNames map[string]string
+
+func (s stubCategoryNames) CategoryName(ctx context.Context, regionCode, categoryID string) (string, error) {
+	return s[categoryID], nil
+}
+
+func TestEnrichmentHandler_GetVideoEnrichment_CategoryName(t *testing.T) {
+	categoryID := "28"
+	repo := &mockEnrichmentRepo{
+		enrichments: []*model.VideoEnrichment{
+			{VideoID: "video123", EnrichedAt: time.Now().Add(-time.Hour), CategoryID: &categoryID},
+		},
+	}
+	handler := NewEnrichmentHandler(repo, nil, nil, nil)
+	handler.SetCategoryNameResolver(stubCategoryNames{"28": "Science & Technology"}, "US")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/enrichments/videos/video123", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	var enrichment model.VideoEnrichment
+	if err := json.NewDecoder(resp.Body).Decode(&enrichment); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if enrichment.CategoryName == nil || *enrichment.CategoryName != "Science & Technology" {
+		t.Errorf("expected category name %q, got %v", "Science & Technology", enrichment.CategoryName)
+	}
+}
+
+func TestEnrichmentHandler_GetVideoEnrichment_Freshness(t *testing.T) {
+	tests := []struct {
+		name       string
+		enrichedAt time.Time
+		wantStale  bool
+	}{
+		{name: "week-old enrichment is stale", enrichedAt: time.Now().Add(-7 * 24 * time.Hour), wantStale: true},
+		{name: "hour-old enrichment is fresh", enrichedAt: time.Now().Add(-time.Hour), wantStale: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockEnrichmentRepo{
+				enrichments: []*model.VideoEnrichment{{VideoID: "video123", EnrichedAt: tt.enrichedAt}},
+			}
+			handler := NewEnrichmentHandler(repo, nil, nil, nil)
+			handler.SetStaleAfter(24 * time.Hour)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/enrichments/videos/video123", nil)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+			}
+
+			var enrichment model.VideoEnrichment
+			if err := json.NewDecoder(resp.Body).Decode(&enrichment); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if enrichment.IsStale == nil || *enrichment.IsStale != tt.wantStale {
+				t.Errorf("expected is_stale %v, got %v", tt.wantStale, enrichment.IsStale)
+			}
+			wantAge := int64(time.Since(tt.enrichedAt) / time.Second)
+			if enrichment.EnrichmentAgeSeconds == nil || *enrichment.EnrichmentAgeSeconds < wantAge-5 || *enrichment.EnrichmentAgeSeconds > wantAge {
+				t.Errorf("expected enrichment_age_seconds about %d, got %v", wantAge, enrichment.EnrichmentAgeSeconds)
+			}
+		})
+	}
+}
+
+func TestEnrichmentHandler_GetVideoEnrichment_Fields(t *testing.T) {
+	viewCount, likeCount, duration := int64(1000), int64(50), "PT4M13S"
+	description := "A long description"
+	repo := &mockEnrichmentRepo{
+		enrichments: []*model.VideoEnrichment{{
+			VideoID:     "video123",
+			ViewCount:   &viewCount,
+			LikeCount:   &likeCount,
+			Duration:    &duration,
+			Description: &description,
+			EnrichedAt:  time.Now(),
+		}},
+	}
+	handler := NewEnrichmentHandler(repo, nil, nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantFields []string
+	}{
+		{name: "subset", query: "?fields=view_count,like_count,duration", wantStatus: http.StatusOK,
+			wantFields: []string{"duration", "like_count", "view_count"}},
+		{name: "computed field", query: "?fields=video_id,best_thumbnail_url", wantStatus: http.StatusOK,
+			wantFields: []string{"best_thumbnail_url", "video_id"}},
+		{name: "unknown field", query: "?fields=view_count,subscriber_count", wantStatus: http.StatusBadRequest},
+		{name: "no field names", query: "?fields=,", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/enrichments/videos/video123"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, resp.Code, resp.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var got []string
+			for name := range body {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("expected fields %v, got %v", tt.wantFields, got)
+			}
+		})
+	}
+}
+
+// mockEnrichmentQueue records enqueued enrichment tasks
+type mockEnrichmentQueue struct {
+	videoIDs []string
+}
+
+func (m *mockEnrichmentQueue) EnqueueChannelEnrichment(ctx context.Context, channelID string) error {
+	return nil
+}
+
+func (m *mockEnrichmentQueue) EnqueueVideoEnrichment(ctx context.Context, videoID, channelID string, priority int) error {
+	m.videoIDs = append(m.videoIDs, videoID)
+	return nil
+}
+
+func TestEnrichmentHandler_EnqueueVideoEnrichment_DedupWindow(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantEnqueued bool
+	}{
+		{name: "enriched before 1 day window", query: "?dedup_window=24h", wantStatus: http.StatusAccepted, wantEnqueued: true},
+		{name: "enriched within 7 day window", query: "?dedup_window=168h", wantStatus: http.StatusOK, wantEnqueued: false},
+		{name: "forced within 7 day window", query: "?dedup_window=168h&force=true", wantStatus: http.StatusAccepted, wantEnqueued: true},
+		{name: "default window", query: "", wantStatus: http.StatusOK, wantEnqueued: false},
+		{name: "invalid window", query: "?dedup_window=soon", wantStatus: http.StatusBadRequest, wantEnqueued: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockEnrichmentRepo{
+				enrichments: []*model.VideoEnrichment{
+					{VideoID: "video123", EnrichedAt: time.Now().Add(-48 * time.Hour)},
+				},
+			}
+			videoRepo := newMockVideoRepo()
+			videoRepo.videos["video123"] = &models.Video{VideoID: "video123", ChannelID: "UC123"}
+			queue := &mockEnrichmentQueue{}
+
+			handler := NewEnrichmentHandler(repo, nil, videoRepo, nil)
+			handler.SetQueueClient(queue)
+			handler.SetDedupWindow(7 * 24 * time.Hour)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/enrichments/videos/video123/enqueue"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, resp.Code, resp.Body.String())
+			}
+			if enqueued := len(queue.videoIDs) == 1; enqueued != tt.wantEnqueued {
+				t.Errorf("expected enqueued=%v, got %v", tt.wantEnqueued, queue.videoIDs)
+			}
+		})
+	}
+}
+
+func TestEnrichmentHandler_EnqueueVideoEnrichmentBatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		query        string
+		wantStatus   int
+		wantResponse batchEnqueueResponse
+		wantEnqueued []string
+	}{
+		{
+			name:         "all already enriched",
+			body:         `{"ids": ["video1", "video2", "video1"]}`,
+			wantStatus:   http.StatusOK,
+			wantResponse: batchEnqueueResponse{Requested: 2, Skipped: 2, NotFound: []string{}, Failed: []string{}},
+		},
+		{
+			name:         "empty after dropping blank IDs",
+			body:         `{"ids": ["", " "]}`,
+			wantStatus:   http.StatusOK,
+			wantResponse: batchEnqueueResponse{NotFound: []string{}, Failed: []string{}},
+		},
+		{
+			name:         "mixed",
+			body:         `{"ids": ["video1", "video3", "missing"]}`,
+			wantStatus:   http.StatusAccepted,
+			wantResponse: batchEnqueueResponse{Requested: 3, Enqueued: 1, Skipped: 1, NotFound: []string{"missing"}, Failed: []string{}},
+			wantEnqueued: []string{"video3"},
+		},
+		{
+			name:         "forced",
+			body:         `{"ids": ["video1", "video2"]}`,
+			query:        "?force=true",
+			wantStatus:   http.StatusAccepted,
+			wantResponse: batchEnqueueResponse{Requested: 2, Enqueued: 2, NotFound: []string{}, Failed: []string{}},
+			wantEnqueued: []string{"video1", "video2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockEnrichmentRepo{
+				enrichments: []*model.VideoEnrichment{
+					{VideoID: "video1", EnrichedAt: time.Now().Add(-time.Hour)},
+					{VideoID: "video2", EnrichedAt: time.Now().Add(-2 * time.Hour)},
+				},
+			}
+			videoRepo := newMockVideoRepo()
+			for _, videoID := range []string{"video1", "video2", "video3"} {
+				videoRepo.videos[videoID] = &models.Video{VideoID: videoID, ChannelID: "UC123"}
+			}
+			queue := &mockEnrichmentQueue{}
+
+			handler := NewEnrichmentHandler(repo, nil, videoRepo, nil)
+			handler.SetQueueClient(queue)
+			handler.SetDedupWindow(24 * time.Hour)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/enrichments/videos/enqueue"+tt.query, strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, resp.Code, resp.Body.String())
+			}
+
+			var got batchEnqueueResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantResponse) {
+				t.Errorf("expected response %+v, got %+v", tt.wantResponse, got)
+			}
+			if !reflect.DeepEqual(queue.videoIDs, tt.wantEnqueued) {
+				t.Errorf("expected enqueued %v, got %v", tt.wantEnqueued, queue.videoIDs)
+			}
+		})
+	}
+}
+
+func TestEnrichmentHandler_EnqueueVideoEnrichmentBatch_TooManyIDs(t *testing.T) {
+	ids := make([]string, maxBatchEnqueueIDs+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("video%d", i)
+	}
+	body, err := json.Marshal(BatchEnrichmentRequest{IDs: ids})
+	if err != nil {
+		t.Fatalf("failed to encode request: %v", err)
+	}
+
+	queue := &mockEnrichmentQueue{}
+	handler := NewEnrichmentHandler(&mockEnrichmentRepo{}, nil, newMockVideoRepo(), nil)
+	handler.SetQueueClient(queue)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/enrichments/videos/enqueue", strings.NewReader(string(body)))
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, resp.Code, resp.Body.String())
+	}
+	if len(queue.videoIDs) != 0 {
+		t.Errorf("expected nothing enqueued, got %v", queue.videoIDs)
+	}
+}
+
+func TestEnrichmentHandler_ExportVideoEnrichments(t *testing.T) {
+	since := time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC)
+
+	repo := &mockEnrichmentRepo{}
+	for i, offset := range []time.Duration{-2 * time.Hour, -time.Second, 0, time.Hour, 2 * time.Hour} {
+		repo.enrichments = append(repo.enrichments, &model.VideoEnrichment{
+			ID:        int64(i + 1),
+			VideoID:   "video" + string(rune('a'+i)),
+			CreatedAt: since.Add(offset),
+		})
+	}
+	handler := NewEnrichmentHandler(repo, &mockChannelEnrichmentRepo{}, nil, nil)
+
+	type exportResponse struct {
+		Items      []model.VideoEnrichment `json:"items"`
+		NextCursor *string                 `json:"next_cursor"`
+		HasMore    bool                    `json:"has_more"`
+	}
+	export := func(t *testing.T, query url.Values) (int, exportResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/enrichments/export?"+query.Encode(), nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var resp exportResponse
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return rr.Code, resp
+	}
+	videoIDs := func(items []model.VideoEnrichment) []string {
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.VideoID)
+		}
+		return ids
+	}
+
+	t.Run("returns only enrichments created since the timestamp", func(t *testing.T) {
+		status, resp := export(t, url.Values{"since": {since.Format(time.RFC3339)}})
+		if status != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", status)
+		}
+
+		want := []string{"videoc", "videod", "videoe"}
+		if got := videoIDs(resp.Items); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+		if resp.NextCursor == nil {
+			t.Fatal("expected a high-watermark cursor")
+		}
+	})
+
+	t.Run("cursor resumes after the high watermark", func(t *testing.T) {
+		_, first := export(t, url.Values{"since": {since.Format(time.RFC3339)}, "limit": {"2"}})
+		if got := videoIDs(first.Items); !reflect.DeepEqual(got, []string{"videoc", "videod"}) {
+			t.Fatalf("unexpected first page %v", got)
+		}
+		if !first.HasMore || first.NextCursor == nil {
+			t.Fatal("expected a full first page with a cursor")
+		}
+
+		_, second := export(t, url.Values{"since": {since.Format(time.RFC3339)}, "limit": {"2"}, "cursor": {*first.NextCursor}})
+		if got := videoIDs(second.Items); !reflect.DeepEqual(got, []string{"videoe"}) {
+			t.Fatalf("unexpected second page %v", got)
+		}
+
+		// Nothing new: the watermark is handed back unchanged
+		_, third := export(t, url.Values{"cursor": {*second.NextCursor}})
+		if len(third.Items) != 0 {
+			t.Fatalf("expected no items, got %v", videoIDs(third.Items))
+		}
+		if third.NextCursor == nil || *third.NextCursor != *second.NextCursor {
+			t.Errorf("expected cursor %s to be kept, got %v", *second.NextCursor, third.NextCursor)
+		}
+	})
+
+	t.Run("rejects bad input", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{},
+			{"since": {"yesterday"}},
+			{"cursor": {"not-a-cursor"}},
+		} {
+			if status, _ := export(t, query); status != http.StatusBadRequest {
+				t.Errorf("%v: expected status 400, got %d", query, status)
+			}
+		}
+	})
+}
@@ -0,0 +1,489 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// apiParam describes a path or query parameter of an API operation
+type apiParam struct {
+	Name        string
+	In          string // "path" or "query"
+	Type        string // OpenAPI scalar type
+	Format      string
+	Description string
+	Enum        []string
+}
+
+// apiOperation describes one REST endpoint for the OpenAPI spec. Request and response
+// bodies are Go values whose types are reflected into schemas, so the spec follows the
+// structs the handlers actually decode and encode.
+type apiOperation struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Params   []apiParam
+	Request  interface{}
+	Status   int
+	Response interface{}
+}
+
+// Common parameters shared by list endpoints
+var (
+	limitParam  = apiParam{Name: "limit", In: "query", Type: "integer", Description: "Maximum number of items (default 50, max 1000)"}
+	offsetParam = apiParam{Name: "offset", In: "query", Type: "integer", Description: "Number of items to skip"}
+	orderParam  = apiParam{Name: "order", In: "query", Type: "string", Enum: []string{"asc", "desc"}, Description: "Sort direction (default desc)"}
+	formatParam = apiParam{Name: "format", In: "query", Type: "string", Enum: []string{"short", "long"}, Description: "Only Shorts (up to 180s) or long-form videos"}
+	fieldsParam = apiParam{Name: "fields", In: "query", Type: "string", Description: "Comma-separated field names to return instead of the full object; unknown names are rejected with 400"}
+)
+
+func pathParam(name, description string) apiParam {
+	return apiParam{Name: name, In: "path", Type: "string", Description: description}
+}
+
+func queryParam(name, description string) apiParam {
+	return apiParam{Name: name, In: "query", Type: "string", Description: description}
+}
+
+func timeParam(name, description string) apiParam {
+	return apiParam{Name: name, In: "query", Type: "string", Format: "date-time", Description: description + " (RFC3339)"}
+}
+
+func orderByParam(columns []string) apiParam {
+	return apiParam{Name: "order_by", In: "query", Type: "string", Enum: columns, Description: "Column to sort by"}
+}
+
+// listResponse is the paginated envelope returned by list endpoints
+type listResponse[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// enqueueResponse is returned by the manual enrichment endpoints
+type enqueueResponse struct {
+	Status    string `json:"status"`
+	VideoID   string `json:"video_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+
+	// EnrichedAt is set when a video enrichment was skipped because of the dedup window
+	EnrichedAt string `json:"enriched_at,omitempty"`
+}
+
+// apiOperations lists the documented REST endpoints. Keep in sync with the routes
+// registered in cmd/server and the ServeHTTP methods in this package.
+var apiOperations = []apiOperation{
+	// Channels
+	{Method: http.MethodGet, Path: "/api/v1/channels", Tag: "channels", Summary: "List channels",
+		Params: []apiParam{limitParam, offsetParam, queryParam("title", "Filter by title substring"),
+			queryParam("subscription_status", "Only channels whose subscription has this status, with subscription_expires_at set"), orderByParam(channelOrderColumns), orderParam},
+		Status: http.StatusOK, Response: listResponse[models.Channel]{}},
+	{Method: http.MethodPost, Path: "/api/v1/channels", Tag: "channels", Summary: "Create a channel",
+		Request: CreateChannelRequest{}, Status: http.StatusCreated, Response: models.Channel{}},
+	{Method: http.MethodGet, Path: "/api/v1/channels/{channel_id}", Tag: "channels", Summary: "Get a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID")}, Status: http.StatusOK, Response: models.Channel{}},
+	{Method: http.MethodPut, Path: "/api/v1/channels/{channel_id}", Tag: "channels", Summary: "Update a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID")}, Request: UpdateChannelRequest{}, Status: http.StatusOK, Response: models.Channel{}},
+	{Method: http.MethodPatch, Path: "/api/v1/channels/{channel_id}", Tag: "channels", Summary: "Partially update a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID")}, Request: PatchChannelRequest{}, Status: http.StatusOK, Response: models.Channel{}},
+	{Method: http.MethodGet, Path: "/api/v1/channels/{channel_id}/name-history", Tag: "channels", Summary: "Title changes recorded for a channel, most recent first",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID")}, Status: http.StatusOK, Response: listResponse[models.ChannelNameChange]{}},
+	{Method: http.MethodDelete, Path: "/api/v1/channels/{channel_id}", Tag: "channels", Summary: "Delete a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID")}, Status: http.StatusNoContent},
+
+	{Method: http.MethodPost, Path: "/api/v1/channels/from-url", Tag: "channels", Summary: "Create a channel from a YouTube URL",
+		Request: CreateChannelFromURLRequest{}, Status: http.StatusOK, Response: CreateChannelFromURLResponse{}},
+
+	// Videos
+	{Method: http.MethodGet, Path: "/api/v1/videos", Tag: "videos", Summary: "List videos",
+		Params: []apiParam{limitParam, offsetParam, queryParam("channel_id", "Filter by channel"), queryParam("title", "Filter by title substring"),
+			timeParam("published_after", "Only videos published after this time"), timeParam("published_before", "Only videos published before this time"),
+			{Name: "is_premiere", In: "query", Type: "boolean", Description: "Filter by whether any enrichment flagged the video as a premiere, including premieres that have since aired"},
+			{Name: "include_deleted", In: "query", Type: "boolean", Description: "Include soft-deleted videos"},
+			orderByParam(videoOrderColumns), orderParam},
+		Status: http.StatusOK, Response: listResponse[models.Video]{}},
+	{Method: http.MethodPost, Path: "/api/v1/videos", Tag: "videos", Summary: "Create a video",
+		Request: CreateVideoRequest{}, Status: http.StatusCreated, Response: models.Video{}},
+	{Method: http.MethodGet, Path: "/api/v1/videos/{video_id}", Tag: "videos", Summary: "Get a video; a soft-deleted video has deleted_at set",
+		Params: []apiParam{pathParam("video_id", "YouTube video ID")}, Status: http.StatusOK, Response: models.Video{}},
+	{Method: http.MethodPut, Path: "/api/v1/videos/{video_id}", Tag: "videos", Summary: "Update a video",
+		Params: []apiParam{pathParam("video_id", "YouTube video ID")}, Request: UpdateVideoRequest{}, Status: http.StatusOK, Response: models.Video{}},
+	{Method: http.MethodPatch, Path: "/api/v1/videos/{video_id}", Tag: "videos", Summary: "Partially update a video",
+		Params: []apiParam{pathParam("video_id", "YouTube video ID")}, Request: PatchVideoRequest{}, Status: http.StatusOK, Response: models.Video{}},
+	{Method: http.MethodDelete, Path: "/api/v1/videos/{video_id}", Tag: "videos", Summary: "Soft-delete a video, or remove it with its history when soft=false",
+		Params: []apiParam{pathParam("video_id", "YouTube video ID"),
+			{Name: "soft", In: "query", Type: "boolean", Description: "Mark the video deleted and keep its enrichments and sponsors (default: true)"},
+		}, Status: http.StatusNoContent},
+
+	// Subscriptions
+	{Method: http.MethodGet, Path: "/api/v1/subscriptions", Tag: "subscriptions", Summary: "List subscriptions",
+		Params: []apiParam{limitParam, offsetParam, queryParam("channel_id", "Filter by channel"), queryParam("status", "Filter by status"),
+			timeParam("expires_before", "Only subscriptions expiring before this time")},
+		Status: http.StatusOK, Response: listResponse[models.Subscription]{}},
+	{Method: http.MethodPost, Path: "/api/v1/subscriptions", Tag: "subscriptions", Summary: "Subscribe to a channel; 409 without contacting the hub when the channel already has a subscription",
+		Request: CreateSubscriptionRequest{}, Status: http.StatusCreated, Response: models.Subscription{}},
+	{Method: http.MethodGet, Path: "/api/v1/subscriptions/{id}", Tag: "subscriptions", Summary: "Get a subscription",
+		Params: []apiParam{pathParam("id", "Subscription ID")}, Status: http.StatusOK, Response: models.Subscription{}},
+	{Method: http.MethodPut, Path: "/api/v1/subscriptions/{id}", Tag: "subscriptions", Summary: "Update a subscription",
+		Params: []apiParam{pathParam("id", "Subscription ID")}, Request: UpdateSubscriptionRequest{}, Status: http.StatusOK, Response: models.Subscription{}},
+	{Method: http.MethodDelete, Path: "/api/v1/subscriptions/{id}", Tag: "subscriptions", Summary: "Delete a subscription",
+		Params: []apiParam{pathParam("id", "Subscription ID"), {Name: "unsubscribe", In: "query", Type: "boolean", Description: "Also unsubscribe from the hub"}},
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/subscriptions/abandoned", Tag: "subscriptions", Summary: "List subscriptions abandoned after repeated renewal failures",
+		Params: []apiParam{limitParam, offsetParam}, Status: http.StatusOK, Response: listResponse[models.Subscription]{}},
+	{Method: http.MethodPost, Path: "/api/v1/subscriptions/renew-all", Tag: "subscriptions", Summary: "Renew all active subscriptions",
+		Status: http.StatusOK, Response: map[string]interface{}{}},
+	{Method: http.MethodPost, Path: "/api/v1/subscriptions/migrate-callback", Tag: "subscriptions", Summary: "Move subscriptions to the configured callback URL",
+		Request: MigrateCallbackRequest{}, Status: http.StatusAccepted, Response: CallbackMigration{}},
+	{Method: http.MethodGet, Path: "/api/v1/subscriptions/migrate-callback/{job_id}", Tag: "subscriptions", Summary: "Get the progress of a callback migration",
+		Params: []apiParam{pathParam("job_id", "Migration job ID")}, Status: http.StatusOK, Response: CallbackMigration{}},
+	{Method: http.MethodPost, Path: "/api/v1/webhook-test", Tag: "subscriptions", Summary: "Send a signed test notification to the configured callback URL",
+		Status: http.StatusOK, Response: WebhookTestResult{}},
+
+	// Sponsors
+	{Method: http.MethodGet, Path: "/api/v1/sponsors", Tag: "sponsors", Summary: "List sponsors",
+		Params: []apiParam{limitParam, offsetParam, queryParam("category", "Filter by category"),
+			{Name: "sort_by", In: "query", Type: "string", Enum: []string{"video_count", "name", "last_seen", "created"}, Description: "Sort field (default video_count)"}, orderParam, formatParam},
+		Status: http.StatusOK, Response: listResponse[models.Sponsor]{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsors/recent", Tag: "sponsors", Summary: "Most recent sponsor detections",
+		Params: []apiParam{limitParam}, Status: http.StatusOK, Response: listResponse[models.RecentSponsorDetection]{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsors/confidence-histogram", Tag: "sponsors", Summary: "Distribution of detection confidence",
+		Params: []apiParam{{Name: "buckets", In: "query", Type: "integer", Description: "Number of buckets (1-100, default 10)"},
+			queryParam("llm_model", "Only detections by this model"), timeParam("since", "Only detections at or after this time"), timeParam("until", "Only detections before this time"), formatParam},
+		Status: http.StatusOK, Response: listResponse[models.ConfidenceBucket]{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsors/{id}", Tag: "sponsors", Summary: "Get a sponsor",
+		Params: []apiParam{pathParam("id", "Sponsor UUID")}, Status: http.StatusOK, Response: models.Sponsor{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsors/{id}/videos", Tag: "sponsors", Summary: "Videos featuring a sponsor",
+		Params: []apiParam{pathParam("id", "Sponsor UUID"), limitParam, offsetParam}, Status: http.StatusOK, Response: listResponse[map[string]interface{}]{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsors/{id}/integrity", Tag: "sponsors", Summary: "Compare a sponsor's video_count with video_sponsors",
+		Params: []apiParam{pathParam("id", "Sponsor UUID")}, Status: http.StatusOK, Response: models.SponsorVideoCountCheck{}},
+	{Method: http.MethodPost, Path: "/api/v1/sponsors/{id}/integrity", Tag: "sponsors", Summary: "Correct a sponsor's video_count from video_sponsors",
+		Params: []apiParam{pathParam("id", "Sponsor UUID")}, Status: http.StatusOK, Response: models.SponsorVideoCountCheck{}},
+	{Method: http.MethodGet, Path: "/api/v1/videos/{video_id}/sponsors", Tag: "sponsors", Summary: "Sponsors detected in a video",
+		Params: []apiParam{pathParam("video_id", "YouTube video ID"),
+			{Name: "distinct", In: "query", Type: "boolean", Description: "One row per sponsor across detection runs (highest confidence)"},
+			{Name: "min_confidence", In: "query", Type: "number", Description: "Only sponsors detected with at least this confidence (0-1)"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Maximum number of sponsors, highest confidence first (capped by MAX_SPONSORS_PER_VIDEO, default 50)"}},
+		Status: http.StatusOK, Response: listResponse[map[string]interface{}]{}},
+	{Method: http.MethodGet, Path: "/api/v1/channels/{channel_id}/sponsors", Tag: "sponsors", Summary: "Sponsors seen on a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID"), limitParam, offsetParam}, Status: http.StatusOK, Response: listResponse[models.Sponsor]{}},
+	{Method: http.MethodGet, Path: "/api/v1/channels/{channel_id}/sponsor-trend", Tag: "sponsors", Summary: "Sponsor counts per period for a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID"),
+			{Name: "interval", In: "query", Type: "string", Enum: []string{"week", "month", "year"}, Description: "Bucket size (default month)"},
+			timeParam("since", "Only detections at or after this time"), timeParam("until", "Only detections before this time"), formatParam},
+		Status: http.StatusOK, Response: listResponse[models.SponsorTrendBucket]{}},
+	{Method: http.MethodGet, Path: "/api/v1/channels/{channel_id}/sponsor-report", Tag: "sponsors", Summary: "Every sponsor seen on a channel with video counts and first/last seen dates; format=csv downloads it as CSV",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID"),
+			{Name: "format", In: "query", Type: "string", Enum: []string{"json", "csv"}, Description: "Response format (default json)"}},
+		Status: http.StatusOK, Response: listResponse[models.ChannelSponsorReportEntry]{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsor-detection-jobs", Tag: "sponsors", Summary: "List sponsor detection jobs",
+		Params: []apiParam{limitParam, offsetParam, queryParam("video_id", "Filter by video"),
+			{Name: "status", In: "query", Type: "string", Enum: []string{"pending", "completed", "failed", "skipped"}, Description: "Filter by status"}},
+		Status: http.StatusOK, Response: listResponse[models.SponsorDetectionJob]{}},
+	{Method: http.MethodPost, Path: "/api/v1/sponsor-detection-jobs", Tag: "sponsors", Summary: "Run sponsor detection for a stored video with the given LLM model",
+		Request: CreateDetectionJobRequest{}, Status: http.StatusAccepted, Response: models.SponsorDetectionJob{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsor-detection-jobs/{id}", Tag: "sponsors", Summary: "Get a sponsor detection job",
+		Params: []apiParam{pathParam("id", "Detection job UUID")}, Status: http.StatusOK, Response: DetectionJobDetail{}},
+	{Method: http.MethodGet, Path: "/api/v1/sponsor-detection-jobs/{id}/raw", Tag: "sponsors", Summary: "Stored raw LLM response of a job and the prompt it used",
+		Params: []apiParam{pathParam("id", "Detection job UUID")}, Status: http.StatusOK, Response: DetectionJobRawResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/sponsor-detection-jobs/{id}/reapply", Tag: "sponsors", Summary: "Regenerate a job's sponsors from its stored LLM response",
+		Params: []apiParam{pathParam("id", "Detection job UUID")}, Status: http.StatusOK, Response: map[string]interface{}{}},
+	{Method: http.MethodPost, Path: "/api/v1/sponsor-prompts/preview", Tag: "sponsors", Summary: "Render the sponsor detection prompt for a title and description, or a stored video, without calling the LLM",
+		Request: PromptPreviewRequest{}, Status: http.StatusOK, Response: PromptPreviewResponse{}},
+
+	// Enrichments
+	{Method: http.MethodGet, Path: "/api/v1/enrichments/videos/{video_id}", Tag: "enrichments", Summary: "Latest enrichment for a video, with its age and an is_stale flag",
+		Params: []apiParam{pathParam("video_id", "YouTube video ID"), fieldsParam}, Status: http.StatusOK, Response: model.VideoEnrichment{}},
+	{Method: http.MethodGet, Path: "/api/v1/enrichments/videos/{video_id}/compare", Tag: "enrichments", Summary: "Compare a video's enrichments at two times",
+		Params: []apiParam{pathParam("video_id", "YouTube video ID"), timeParam("from", "Earlier time"), timeParam("to", "Later time")},
+		Status: http.StatusOK, Response: struct {
+			From       time.Time                       `json:"from"`
+			To         time.Time                       `json:"to"`
+			Comparison model.VideoEnrichmentComparison `json:"comparison"`
+		}{}},
+	{Method: http.MethodPost, Path: "/api/v1/enrichments/videos/{video_id}/enqueue", Tag: "enrichments", Summary: "Enqueue enrichment of a video",
+		Params: []apiParam{
+			pathParam("video_id", "YouTube video ID"),
+			queryParam("dedup_window", "Skip (status skipped, 200) if the video was enriched within this duration, e.g. 24h; defaults to ENRICHMENT_DEDUP_WINDOW"),
+			{Name: "force", In: "query", Type: "boolean", Description: "Enqueue even if the video was enriched within the dedup window"},
+		}, Status: http.StatusAccepted, Response: enqueueResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/enrichments/videos/enqueue", Tag: "enrichments", Summary: "Enqueue enrichment of up to 100 distinct videos; duplicates and videos enriched within the dedup window are dropped, and an empty remainder is a no-op (200)",
+		Params: []apiParam{
+			queryParam("dedup_window", "Skip videos enriched within this duration, e.g. 24h; defaults to ENRICHMENT_DEDUP_WINDOW"),
+			{Name: "force", In: "query", Type: "boolean", Description: "Enqueue even videos enriched within the dedup window"},
+		}, Request: BatchEnrichmentRequest{}, Status: http.StatusAccepted, Response: batchEnqueueResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/enrichments/videos/batch", Tag: "enrichments", Summary: "Latest enrichments for several videos, keyed by video ID, each with its age and an is_stale flag",
+		Request: BatchEnrichmentRequest{}, Status: http.StatusOK, Response: map[string]model.VideoEnrichment{}},
+	{Method: http.MethodGet, Path: "/api/v1/enrichments/export", Tag: "enrichments", Summary: "Video enrichments created since a time, oldest first, for incremental exports",
+		Params: []apiParam{
+			timeParam("since", "Only enrichments created at or after this time; required unless cursor is given"),
+			queryParam("cursor", "next_cursor of the previous page or sync; resumes after it"),
+			limitParam,
+		}, Status: http.StatusOK, Response: enrichmentExportResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/enrichments/channels/{channel_id}", Tag: "enrichments", Summary: "Latest enrichment for a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID"), fieldsParam}, Status: http.StatusOK, Response: model.ChannelEnrichment{}},
+	{Method: http.MethodPost, Path: "/api/v1/channels/batch-latest", Tag: "enrichments", Summary: "Latest enrichments for up to 50 channels, keyed by channel ID; unenriched channels are omitted",
+		Request: BatchEnrichmentRequest{}, Status: http.StatusOK, Response: map[string]model.ChannelEnrichment{}},
+	{Method: http.MethodGet, Path: "/api/v1/channels/{channel_id}/growth", Tag: "enrichments", Summary: "Subscriber and view growth between successive channel enrichments",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID"), limitParam}, Status: http.StatusOK, Response: listResponse[model.ChannelGrowthPoint]{}},
+	{Method: http.MethodPost, Path: "/api/v1/enrichments/channels/{channel_id}/enqueue", Tag: "enrichments", Summary: "Enqueue enrichment of a channel",
+		Params: []apiParam{pathParam("channel_id", "YouTube channel ID")}, Status: http.StatusAccepted, Response: enqueueResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/enrichments/channels/batch", Tag: "enrichments", Summary: "Latest enrichments for several channels, keyed by channel ID",
+		Request: BatchEnrichmentRequest{}, Status: http.StatusOK, Response: map[string]model.ChannelEnrichment{}},
+
+	// Jobs
+	{Method: http.MethodGet, Path: "/api/v1/jobs", Tag: "jobs", Summary: "List enrichment jobs",
+		Params: []apiParam{{Name: "limit", In: "query", Type: "integer", Description: "Maximum number of items (default 100, max 500)"}, offsetParam,
+			queryParam("status", "Filter by status")},
+		Status: http.StatusOK, Response: listResponse[model.EnrichmentJob]{}},
+	{Method: http.MethodDelete, Path: "/api/v1/jobs/{id}", Tag: "jobs", Summary: "Cancel a pending enrichment job",
+		Params: []apiParam{pathParam("id", "Enrichment job ID")}, Status: http.StatusOK, Response: model.EnrichmentJob{}},
+
+	// Admin
+	{Method: http.MethodGet, Path: "/api/v1/admin/db-stats", Tag: "admin", Summary: "Approximate row counts and sizes of the largest tables",
+		Status: http.StatusOK, Response: DBStatsResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/audit", Tag: "admin", Summary: "List audit log entries for mutating API operations, newest first",
+		Params: []apiParam{limitParam, offsetParam,
+			queryParam("resource_type", "Filter by resource type (channel, video, subscription, sponsor, sponsor_detection_job)"),
+			queryParam("resource_id", "Filter by resource ID"),
+			queryParam("action", "Filter by action (create, update, delete, renew, migrate_callback, reapply)"),
+			queryParam("api_key_id", "Filter by API key ID"),
+			queryParam("actor", "Filter by X-Actor header value"),
+			{Name: "since", In: "query", Type: "string", Description: "Only entries at or after this RFC3339 timestamp"}},
+		Status: http.StatusOK, Response: listResponse[model.AuditEntry]{}},
+}
+
+// extraProperties lists JSON fields added by custom MarshalJSON methods, which reflection cannot see
+var extraProperties = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(model.VideoEnrichment{}): {
+		"best_thumbnail_url": map[string]interface{}{"type": "string", "nullable": true},
+	},
+	reflect.TypeOf(models.Channel{}): {
+		"display_name": map[string]interface{}{"type": "string"},
+	},
+	reflect.TypeOf(models.Video{}): {
+		"ingestion_lag_seconds": map[string]interface{}{"type": "integer"},
+	},
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// BuildOpenAPISpec returns the OpenAPI 3 document for the REST API
+func BuildOpenAPISpec(version string) map[string]interface{} {
+	paths := make(map[string]interface{})
+	for _, op := range apiOperations {
+		item, ok := paths[op.Path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = op.spec()
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "YouTube Webhook Ingestion API",
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"ApiKeyAuth": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"BearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+			"schemas": map[string]interface{}{
+				"Error": schemaFor(reflect.TypeOf(ErrorResponse{})),
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"ApiKeyAuth": []string{}},
+			map[string]interface{}{"BearerAuth": []string{}},
+		},
+	}
+}
+
+func (op apiOperation) spec() map[string]interface{} {
+	operation := map[string]interface{}{
+		"tags":    []string{op.Tag},
+		"summary": op.Summary,
+	}
+
+	if len(op.Params) > 0 {
+		params := make([]interface{}, 0, len(op.Params))
+		for _, p := range op.Params {
+			schema := map[string]interface{}{"type": p.Type}
+			if p.Format != "" {
+				schema["format"] = p.Format
+			}
+			if len(p.Enum) > 0 {
+				schema["enum"] = p.Enum
+			}
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.In == "path",
+				"description": p.Description,
+				"schema":      schema,
+			})
+		}
+		operation["parameters"] = params
+	}
+
+	if op.Request != nil {
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemaFor(reflect.TypeOf(op.Request))},
+			},
+		}
+	}
+
+	success := map[string]interface{}{"description": http.StatusText(op.Status)}
+	if op.Response != nil {
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schemaFor(reflect.TypeOf(op.Response))},
+		}
+	}
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"}},
+		},
+	}
+	operation["responses"] = map[string]interface{}{
+		strconv.Itoa(op.Status): success,
+		"default":               errorResponse,
+	}
+
+	return operation
+}
+
+// schemaFor reflects a Go type into an OpenAPI schema using its json tags
+func schemaFor(t reflect.Type) map[string]interface{} {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema map[string]interface{}
+	switch {
+	case t == timeType:
+		schema = map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == uuidType:
+		schema = map[string]interface{}{"type": "string", "format": "uuid"}
+	default:
+		switch t.Kind() {
+		case reflect.String:
+			schema = map[string]interface{}{"type": "string"}
+		case reflect.Bool:
+			schema = map[string]interface{}{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			schema = map[string]interface{}{"type": "integer"}
+		case reflect.Float32, reflect.Float64:
+			schema = map[string]interface{}{"type": "number"}
+		case reflect.Slice, reflect.Array:
+			schema = map[string]interface{}{"type": "array", "items": schemaFor(t.Elem())}
+		case reflect.Map:
+			schema = map[string]interface{}{"type": "object"}
+			if t.Elem().Kind() != reflect.Interface {
+				schema["additionalProperties"] = schemaFor(t.Elem())
+			}
+		case reflect.Struct:
+			schema = structSchema(t)
+		default:
+			schema = map[string]interface{}{}
+		}
+	}
+
+	if nullable {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	addStructProperties(t, properties)
+	for name, prop := range extraProperties[t] {
+		properties[name] = prop
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func addStructProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			addStructProperties(embedded, properties)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaFor(field.Type)
+	}
+}
+
+// OpenAPIHandler serves the OpenAPI spec as JSON
+type OpenAPIHandler struct {
+	once sync.Once
+	spec []byte
+	err  error
+
+	version string
+}
+
+// NewOpenAPIHandler creates a handler serving the spec for the given API version.
+func NewOpenAPIHandler(version string) *OpenAPIHandler {
+	return &OpenAPIHandler{version: version}
+}
+
+// ServeHTTP handles GET /openapi.json
+func (h *OpenAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	h.once.Do(func() {
+		h.spec, h.err = json.Marshal(BuildOpenAPISpec(h.version))
+	})
+	if h.err != nil {
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to build OpenAPI spec", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.spec)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIHandler_ServesSpec(t *testing.T) {
+	handler := NewOpenAPIHandler("test")
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json content type, got %q", ct)
+	}
+
+	var spec struct {
+		OpenAPI string                                       `json:"openapi"`
+		Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("expected OpenAPI 3 spec, got version %q", spec.OpenAPI)
+	}
+
+	sponsors, ok := spec.Paths["/api/v1/sponsors"]
+	if !ok {
+		t.Fatal("expected spec to include /api/v1/sponsors")
+	}
+	if _, ok := sponsors["get"]; !ok {
+		t.Error("expected GET operation on /api/v1/sponsors")
+	}
+
+	// Every templated path segment must be declared as a path parameter
+	templateParam := regexp.MustCompile(`\{([^}]+)\}`)
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			declared := make(map[string]bool)
+			params, _ := operation["parameters"].([]interface{})
+			for _, p := range params {
+				param := p.(map[string]interface{})
+				if param["in"] == "path" {
+					declared[param["name"].(string)] = true
+				}
+			}
+			for _, match := range templateParam.FindAllStringSubmatch(path, -1) {
+				if !declared[match[1]] {
+					t.Errorf("%s %s: path parameter %q is not declared", method, path, match[1])
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPIHandler_MethodNotAllowed(t *testing.T) {
+	handler := NewOpenAPIHandler("test")
+
+	req := httptest.NewRequest(http.MethodPost, "/openapi.json", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, resp.Code)
+	}
+}
@@ -1,13 +1,22 @@
 package handler
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/queue"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/ollama"
 
 	"github.com/google/uuid"
 )
@@ -15,14 +24,13 @@ import (
 // SponsorHandler handles REST API operations for sponsors and sponsor detection.
 type SponsorHandler struct {
 	sponsorRepo repository.SponsorDetectionRepository
-	videoRepo   repository.VideoRepository
+	audit       auditTrail
 	logger      *slog.Logger
 }
 
 // NewSponsorHandler creates a new SponsorHandler.
 func NewSponsorHandler(
 	sponsorRepo repository.SponsorDetectionRepository,
-	videoRepo repository.VideoRepository,
 	logger *slog.Logger,
 ) *SponsorHandler {
 	if logger == nil {
@@ -30,11 +38,15 @@ func NewSponsorHandler(
 	}
 	return &SponsorHandler{
 		sponsorRepo: sponsorRepo,
-		videoRepo:   videoRepo,
 		logger:      logger,
 	}
 }
 
+// SetAuditLog records sponsor corrections in the audit log
+func (h *SponsorHandler) SetAuditLog(repo repository.AuditLogRepository) {
+	h.audit = auditTrail{repo: repo, logger: h.logger}
+}
+
 // ServeHTTP routes sponsor-related requests.
 func (h *SponsorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/sponsors")
@@ -49,8 +61,30 @@ func (h *SponsorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// GET /api/v1/sponsors/recent
+	if path == "/recent" {
+		if r.Method == http.MethodGet {
+			h.handleGetRecentDetections(w, r)
+			return
+		}
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	// GET /api/v1/sponsors/confidence-histogram
+	if path == "/confidence-histogram" {
+		if r.Method == http.MethodGet {
+			h.handleGetConfidenceHistogram(w, r)
+			return
+		}
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
 	// GET /api/v1/sponsors/{id}
 	// GET /api/v1/sponsors/{id}/videos
+	// GET /api/v1/sponsors/{id}/integrity
+	// POST /api/v1/sponsors/{id}/integrity
 	if strings.HasPrefix(path, "/") {
 		parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
 		sponsorID := parts[0]
@@ -72,6 +106,20 @@ func (h *SponsorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// GET /api/v1/sponsors/{id}/integrity
+		// POST /api/v1/sponsors/{id}/integrity
+		if len(parts) == 2 && parts[1] == "integrity" {
+			switch r.Method {
+			case http.MethodGet:
+				h.handleCheckSponsorIntegrity(w, r, sponsorUUID)
+			case http.MethodPost:
+				h.handleFixSponsorIntegrity(w, r, sponsorUUID)
+			default:
+				sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+			}
+			return
+		}
+
 		// GET /api/v1/sponsors/{id}
 		if len(parts) == 1 {
 			if r.Method == http.MethodGet {
@@ -130,8 +178,14 @@ func (h *SponsorHandler) handleListSponsors(w http.ResponseWriter, r *http.Reque
 	// Get optional category filter
 	category := r.URL.Query().Get("category")
 
+	format, err := parseVideoFormat(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
 	// Fetch sponsors from repository with filtering and sorting handled at database level
-	sponsors, err := h.sponsorRepo.ListSponsors(r.Context(), sortBy, order, category, limit, offset)
+	sponsors, err := h.sponsorRepo.ListSponsors(r.Context(), sortBy, order, category, format, limit, offset)
 	if err != nil {
 		h.logger.Error("failed to list sponsors", "error", err)
 		sendError(w, http.StatusInternalServerError, "internal server error", "failed to list sponsors", nil)
@@ -148,6 +202,99 @@ func (h *SponsorHandler) handleListSponsors(w http.ResponseWriter, r *http.Reque
 	sendJSON(w, http.StatusOK, response)
 }
 
+// parseVideoFormat reads the optional format query parameter (short or long)
+func parseVideoFormat(r *http.Request) (string, error) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if !models.IsValidVideoFormat(format) {
+		return "", fmt.Errorf("invalid format value (valid: %s, %s)", models.VideoFormatShort, models.VideoFormatLong)
+	}
+	return format, nil
+}
+
+// handleGetRecentDetections handles GET /api/v1/sponsors/recent
+func (h *SponsorHandler) handleGetRecentDetections(w http.ResponseWriter, r *http.Request) {
+	limit := parseLimit(r)
+
+	detections, err := h.sponsorRepo.GetRecentVideoSponsors(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("failed to get recent sponsor detections", "error", err)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve recent sponsor detections", nil)
+		return
+	}
+
+	if detections == nil {
+		detections = []*models.RecentSponsorDetection{}
+	}
+
+	response := map[string]interface{}{
+		"items": detections,
+		"total": len(detections),
+		"limit": limit,
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
+
+// Bounds for the buckets parameter of the confidence histogram
+const (
+	defaultConfidenceBuckets = 10
+	maxConfidenceBuckets     = 100
+)
+
+// handleGetConfidenceHistogram handles GET /api/v1/sponsors/confidence-histogram
+func (h *SponsorHandler) handleGetConfidenceHistogram(w http.ResponseWriter, r *http.Request) {
+	buckets := defaultConfidenceBuckets
+	if val := r.URL.Query().Get("buckets"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 || n > maxConfidenceBuckets {
+			sendError(w, http.StatusBadRequest, "validation failed",
+				fmt.Sprintf("invalid buckets value (must be between 1 and %d)", maxConfidenceBuckets), nil)
+			return
+		}
+		buckets = n
+	}
+
+	since, err := parseTimestamp(r, "since")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	until, err := parseTimestamp(r, "until")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	format, err := parseVideoFormat(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	llmModel := r.URL.Query().Get("llm_model")
+
+	histogram, err := h.sponsorRepo.GetConfidenceHistogram(r.Context(), buckets, llmModel, format, since, until)
+	if err != nil {
+		h.logger.Error("failed to get confidence histogram", "error", err)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve confidence histogram", nil)
+		return
+	}
+
+	total := 0
+	for _, bucket := range histogram {
+		total += bucket.Count
+	}
+
+	response := map[string]interface{}{
+		"items":   histogram,
+		"buckets": buckets,
+		"total":   total,
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
+
 // handleGetSponsor handles GET /api/v1/sponsors/{id}
 func (h *SponsorHandler) handleGetSponsor(w http.ResponseWriter, r *http.Request, sponsorID uuid.UUID) {
 	sponsor, err := h.sponsorRepo.GetSponsorByID(r.Context(), sponsorID)
@@ -165,6 +312,54 @@ func (h *SponsorHandler) handleGetSponsor(w http.ResponseWriter, r *http.Request
 	sendJSON(w, http.StatusOK, sponsor)
 }
 
+// handleCheckSponsorIntegrity handles GET /api/v1/sponsors/{id}/integrity
+// It compares the stored video_count with video_sponsors without changing either.
+func (h *SponsorHandler) handleCheckSponsorIntegrity(w http.ResponseWriter, r *http.Request, sponsorID uuid.UUID) {
+	// Corrections used to be made by GET with fix=true; reject it rather than silently only checking
+	if r.URL.Query().Has("fix") {
+		sendError(w, http.StatusBadRequest, "validation failed", "fix is not supported on GET; use POST to correct video_count", nil)
+		return
+	}
+
+	h.checkSponsorIntegrity(w, r, sponsorID, false)
+}
+
+// handleFixSponsorIntegrity handles POST /api/v1/sponsors/{id}/integrity
+// It compares the stored video_count with video_sponsors and corrects it when they differ.
+func (h *SponsorHandler) handleFixSponsorIntegrity(w http.ResponseWriter, r *http.Request, sponsorID uuid.UUID) {
+	h.checkSponsorIntegrity(w, r, sponsorID, true)
+}
+
+// checkSponsorIntegrity compares a sponsor's video_count with video_sponsors, correcting
+// and auditing it when fix is set
+func (h *SponsorHandler) checkSponsorIntegrity(w http.ResponseWriter, r *http.Request, sponsorID uuid.UUID, fix bool) {
+	check, err := h.sponsorRepo.CheckSponsorVideoCount(r.Context(), sponsorID, fix)
+	if err != nil {
+		h.logger.Error("failed to check sponsor video count", "error", err, "sponsor_id", sponsorID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to check sponsor video count", nil)
+		return
+	}
+
+	if check == nil {
+		sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("sponsor with id '%s' not found", sponsorID), nil)
+		return
+	}
+
+	if check.Fixed {
+		h.logger.Info("corrected sponsor video count",
+			"sponsor_id", sponsorID,
+			"stored_count", check.StoredCount,
+			"actual_count", check.ActualCount,
+		)
+		h.audit.record(r, model.AuditActionUpdate, auditResourceSponsor, sponsorID.String(),
+			map[string]int{"video_count": check.StoredCount},
+			map[string]int{"video_count": check.ActualCount},
+		)
+	}
+
+	sendJSON(w, http.StatusOK, check)
+}
+
 // handleGetSponsorVideos handles GET /api/v1/sponsors/{id}/videos
 func (h *SponsorHandler) handleGetSponsorVideos(w http.ResponseWriter, r *http.Request, sponsorID uuid.UUID) {
 	limit := parseLimit(r)
@@ -183,7 +378,7 @@ func (h *SponsorHandler) handleGetSponsorVideos(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Get video-sponsor relationships
+	// Get video-sponsor relationships joined with their video details
 	videoSponsors, err := h.sponsorRepo.GetSponsorVideos(r.Context(), sponsorID, limit, offset)
 	if err != nil {
 		h.logger.Error("failed to get sponsor videos", "error", err, "sponsor_id", sponsorID)
@@ -191,38 +386,11 @@ func (h *SponsorHandler) handleGetSponsorVideos(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Enrich with video details and sponsor info
 	videoSponsorDetails := make([]map[string]interface{}, 0, len(videoSponsors))
 	for _, vs := range videoSponsors {
-		// Get video details
-		video, err := h.videoRepo.GetVideoByID(r.Context(), vs.VideoID)
-		if err != nil {
-			h.logger.Warn("failed to get video details for sponsor video",
-				"error", err,
-				"video_id", vs.VideoID,
-				"sponsor_id", sponsorID,
-			)
-			// Continue with basic info if video lookup fails
-			videoSponsorDetails = append(videoSponsorDetails, map[string]interface{}{
-				"id":               vs.ID,
-				"video_id":         vs.VideoID,
-				"sponsor_id":       vs.SponsorID,
-				"sponsor_name":     sponsor.Name,
-				"sponsor_category": sponsor.Category,
-				"confidence":       vs.Confidence,
-				"evidence":         vs.Evidence,
-				"detected_at":      vs.DetectedAt,
-			})
-			continue
-		}
-
 		detail := map[string]interface{}{
 			"id":               vs.ID,
 			"video_id":         vs.VideoID,
-			"video_title":      video.Title,
-			"video_url":        video.VideoURL,
-			"channel_id":       video.ChannelID,
-			"published_at":     video.PublishedAt,
 			"sponsor_id":       vs.SponsorID,
 			"sponsor_name":     sponsor.Name,
 			"sponsor_category": sponsor.Category,
@@ -230,6 +398,17 @@ func (h *SponsorHandler) handleGetSponsorVideos(w http.ResponseWriter, r *http.R
 			"evidence":         vs.Evidence,
 			"detected_at":      vs.DetectedAt,
 		}
+
+		// Video details are left out when the video row is missing
+		if vs.VideoTitle != nil {
+			detail["video_title"] = *vs.VideoTitle
+			detail["video_url"] = *vs.VideoURL
+			detail["channel_id"] = *vs.VideoChannelID
+			detail["published_at"] = *vs.VideoPublishedAt
+		}
+		if vs.ChannelTitle != nil {
+			detail["channel_title"] = *vs.ChannelTitle
+		}
 		videoSponsorDetails = append(videoSponsorDetails, detail)
 	}
 
@@ -243,9 +422,13 @@ func (h *SponsorHandler) handleGetSponsorVideos(w http.ResponseWriter, r *http.R
 	sendJSON(w, http.StatusOK, response)
 }
 
+// DefaultMaxSponsorsPerVideo caps how many sponsors GET /api/v1/videos/{id}/sponsors returns
+const DefaultMaxSponsorsPerVideo = 50
+
 // VideoSponsorHandler handles REST API operations for video-sponsor relationships.
 type VideoSponsorHandler struct {
 	sponsorRepo repository.SponsorDetectionRepository
+	maxSponsors int
 	logger      *slog.Logger
 }
 
@@ -259,13 +442,56 @@ func NewVideoSponsorHandler(
 	}
 	return &VideoSponsorHandler{
 		sponsorRepo: sponsorRepo,
+		maxSponsors: DefaultMaxSponsorsPerVideo,
 		logger:      logger,
 	}
 }
 
+// SetMaxSponsors caps how many sponsors are returned per video. A cap of zero or
+// less keeps the default.
+func (h *VideoSponsorHandler) SetMaxSponsors(max int) {
+	if max <= 0 {
+		max = DefaultMaxSponsorsPerVideo
+	}
+	h.maxSponsors = max
+}
+
 // HandleGetVideoSponsors handles GET /api/v1/videos/{id}/sponsors
 func (h *VideoSponsorHandler) HandleGetVideoSponsors(w http.ResponseWriter, r *http.Request, videoID string) {
-	videoSponsorDetails, err := h.sponsorRepo.GetVideoSponsorsWithDetails(r.Context(), videoID)
+	distinct, err := parseBool(r, "distinct")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	filters := repository.VideoSponsorFilters{Limit: h.maxSponsors}
+
+	if val := r.URL.Query().Get("min_confidence"); val != "" {
+		minConfidence, err := strconv.ParseFloat(val, 64)
+		if err != nil || minConfidence < 0 || minConfidence > 1 {
+			sendError(w, http.StatusBadRequest, "validation failed", "min_confidence must be a number between 0 and 1", nil)
+			return
+		}
+		filters.MinConfidence = minConfidence
+	}
+
+	// limit may lower the cap, not raise it
+	if val := r.URL.Query().Get("limit"); val != "" {
+		limit, err := strconv.Atoi(val)
+		if err != nil || limit <= 0 {
+			sendError(w, http.StatusBadRequest, "validation failed", "limit must be a positive integer", nil)
+			return
+		}
+		filters.Limit = min(limit, h.maxSponsors)
+	}
+
+	// distinct collapses rows from repeated detection runs to one per sponsor
+	var videoSponsorDetails []*models.VideoSponsorDetail
+	if distinct != nil && *distinct {
+		videoSponsorDetails, err = h.sponsorRepo.GetDistinctVideoSponsors(r.Context(), videoID, filters)
+	} else {
+		videoSponsorDetails, err = h.sponsorRepo.GetVideoSponsorsWithDetails(r.Context(), videoID, filters)
+	}
 	if err != nil {
 		h.logger.Error("failed to get video sponsors", "error", err, "video_id", videoID)
 		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve video sponsors", nil)
@@ -275,6 +501,7 @@ func (h *VideoSponsorHandler) HandleGetVideoSponsors(w http.ResponseWriter, r *h
 	response := map[string]interface{}{
 		"items": videoSponsorDetails,
 		"total": len(videoSponsorDetails),
+		"limit": filters.Limit,
 	}
 
 	sendJSON(w, http.StatusOK, response)
@@ -326,10 +553,186 @@ func (h *ChannelSponsorHandler) HandleGetChannelSponsors(w http.ResponseWriter,
 	sendJSON(w, http.StatusOK, response)
 }
 
+// HandleGetChannelSponsorTrend handles GET /api/v1/channels/{id}/sponsor-trend
+func (h *ChannelSponsorHandler) HandleGetChannelSponsorTrend(w http.ResponseWriter, r *http.Request, channelID string) {
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "month"
+	}
+
+	// Validate interval parameter (passed to date_trunc, so only known units are allowed)
+	validIntervals := map[string]bool{
+		"week":  true,
+		"month": true,
+		"year":  true,
+	}
+	if !validIntervals[interval] {
+		sendError(w, http.StatusBadRequest, "validation failed",
+			"invalid interval value (valid: week, month, year)", nil)
+		return
+	}
+
+	since, err := parseTimestamp(r, "since")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	until, err := parseTimestamp(r, "until")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	format, err := parseVideoFormat(r)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	buckets, err := h.sponsorRepo.GetChannelSponsorTrend(r.Context(), channelID, interval, format, since, until)
+	if err != nil {
+		h.logger.Error("failed to get channel sponsor trend", "error", err, "channel_id", channelID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve channel sponsor trend", nil)
+		return
+	}
+
+	if buckets == nil {
+		buckets = []*models.SponsorTrendBucket{}
+	}
+
+	response := map[string]interface{}{
+		"channel_id": channelID,
+		"interval":   interval,
+		"items":      buckets,
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
+
+// HandleGetChannelSponsorReport handles GET /api/v1/channels/{id}/sponsor-report
+// It returns every sponsor seen on the channel's videos as JSON, or as a CSV download with format=csv.
+func (h *ChannelSponsorHandler) HandleGetChannelSponsorReport(w http.ResponseWriter, r *http.Request, channelID string) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		sendError(w, http.StatusBadRequest, "validation failed", "invalid format value (valid: json, csv)", nil)
+		return
+	}
+
+	if format == "csv" {
+		h.writeSponsorReportCSV(w, r, channelID)
+		return
+	}
+
+	entries, err := h.sponsorRepo.GetChannelSponsorReport(r.Context(), channelID)
+	if err != nil {
+		h.logger.Error("failed to get channel sponsor report", "error", err, "channel_id", channelID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve channel sponsor report", nil)
+		return
+	}
+
+	if entries == nil {
+		entries = []*models.ChannelSponsorReportEntry{}
+	}
+
+	response := map[string]interface{}{
+		"channel_id": channelID,
+		"items":      entries,
+		"total":      len(entries),
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
+
+// sponsorReportFlushRows is how many CSV rows are buffered before they are flushed to the client
+const sponsorReportFlushRows = 100
+
+// writeSponsorReportCSV streams the report as CSV while it is read from the database, flushing every
+// sponsorReportFlushRows rows. Headers are only sent with the first row, so a query that fails
+// before producing one still gets an error response. A failure after that, including the
+// request timeout cancelling the query, aborts the connection so the client cannot mistake the
+// rows it got for a complete report.
+func (h *ChannelSponsorHandler) writeSponsorReportCSV(w http.ResponseWriter, r *http.Request, channelID string) {
+	flusher, _ := w.(http.Flusher)
+	cw := csv.NewWriter(w)
+	rows := 0
+
+	writeHeader := func() {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-sponsor-report.csv"`, channelID))
+		w.WriteHeader(http.StatusOK)
+		cw.Write([]string{"sponsor_id", "sponsor_name", "sponsor_category", "video_count", "first_seen_at", "last_seen_at"})
+	}
+
+	err := h.sponsorRepo.StreamChannelSponsorReport(r.Context(), channelID, func(entry *models.ChannelSponsorReportEntry) error {
+		if rows == 0 {
+			writeHeader()
+		}
+
+		category := ""
+		if entry.SponsorCategory != nil {
+			category = *entry.SponsorCategory
+		}
+		cw.Write([]string{
+			entry.SponsorID.String(),
+			entry.SponsorName,
+			category,
+			strconv.Itoa(entry.VideoCount),
+			entry.FirstSeenAt.UTC().Format(time.RFC3339),
+			entry.LastSeenAt.UTC().Format(time.RFC3339),
+		})
+
+		rows++
+		if rows%sponsorReportFlushRows == 0 {
+			cw.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return cw.Error()
+	})
+	if err != nil {
+		h.logger.Error("failed to stream channel sponsor report", "error", err, "channel_id", channelID, "rows_written", rows)
+		if rows == 0 {
+			sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve channel sponsor report", nil)
+			return
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	if rows == 0 {
+		writeHeader()
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		h.logger.Error("failed to write channel sponsor report", "error", err, "channel_id", channelID)
+		panic(http.ErrAbortHandler)
+	}
+}
+
 // SponsorDetectionJobHandler handles REST API operations for sponsor detection jobs.
 type SponsorDetectionJobHandler struct {
-	sponsorRepo repository.SponsorDetectionRepository
-	logger      *slog.Logger
+	sponsorRepo    repository.SponsorDetectionRepository
+	enqueuer       SponsorDetectionEnqueuer
+	videoRepo      repository.VideoRepository
+	enrichmentRepo repository.EnrichmentRepository
+	audit          auditTrail
+	logger         *slog.Logger
+}
+
+// SponsorDetectionEnqueuer enqueues sponsor detection tasks with a chosen LLM model
+type SponsorDetectionEnqueuer interface {
+	EnqueueSponsorDetectionWithModel(ctx context.Context, videoID, title, description, detectionJobID, llmModel, source string, priority int) error
+}
+
+// CreateDetectionJobRequest is the body of POST /api/v1/sponsor-detection-jobs
+type CreateDetectionJobRequest struct {
+	VideoID string `json:"video_id"`
+	// Model is the LLM model the detection runs with
+	Model string `json:"model"`
 }
 
 // NewSponsorDetectionJobHandler creates a new SponsorDetectionJobHandler.
@@ -346,23 +749,49 @@ func NewSponsorDetectionJobHandler(
 	}
 }
 
+// SetDetectionEnqueuer enables POST /api/v1/sponsor-detection-jobs, which runs sponsor
+// detection for a stored video. The video and enrichment repositories supply the title
+// and description sent to the LLM.
+func (h *SponsorDetectionJobHandler) SetDetectionEnqueuer(
+	enqueuer SponsorDetectionEnqueuer,
+	videoRepo repository.VideoRepository,
+	enrichmentRepo repository.EnrichmentRepository,
+) {
+	h.enqueuer = enqueuer
+	h.videoRepo = videoRepo
+	h.enrichmentRepo = enrichmentRepo
+}
+
+// SetAuditLog records created and reapplied detection jobs in the audit log
+func (h *SponsorDetectionJobHandler) SetAuditLog(repo repository.AuditLogRepository) {
+	h.audit = auditTrail{repo: repo, logger: h.logger}
+}
+
 // ServeHTTP routes sponsor detection job requests.
 func (h *SponsorDetectionJobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/sponsor-detection-jobs")
 
 	// GET /api/v1/sponsor-detection-jobs
+	// POST /api/v1/sponsor-detection-jobs
 	if path == "" || path == "/" {
-		if r.Method == http.MethodGet {
+		switch r.Method {
+		case http.MethodGet:
 			h.handleListJobs(w, r)
 			return
+		case http.MethodPost:
+			h.handleCreateJob(w, r)
+			return
 		}
 		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
 		return
 	}
 
 	// GET /api/v1/sponsor-detection-jobs/{id}
+	// GET /api/v1/sponsor-detection-jobs/{id}/raw
+	// POST /api/v1/sponsor-detection-jobs/{id}/reapply
 	if strings.HasPrefix(path, "/") {
-		jobID := strings.TrimPrefix(path, "/")
+		parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+		jobID := parts[0]
 
 		// Parse and validate job ID
 		jobUUID, err := uuid.Parse(jobID)
@@ -371,17 +800,122 @@ func (h *SponsorDetectionJobHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 			return
 		}
 
-		if r.Method == http.MethodGet {
-			h.handleGetJob(w, r, jobUUID)
+		// POST /api/v1/sponsor-detection-jobs/{id}/reapply
+		if len(parts) == 2 && parts[1] == "reapply" {
+			if r.Method == http.MethodPost {
+				h.handleReapplyJob(w, r, jobUUID)
+				return
+			}
+			sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+			return
+		}
+
+		// GET /api/v1/sponsor-detection-jobs/{id}/raw
+		if len(parts) == 2 && parts[1] == "raw" {
+			if r.Method == http.MethodGet {
+				h.handleGetRawResponse(w, r, jobUUID)
+				return
+			}
+			sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+			return
+		}
+
+		// GET /api/v1/sponsor-detection-jobs/{id}
+		if len(parts) == 1 {
+			if r.Method == http.MethodGet {
+				h.handleGetJob(w, r, jobUUID)
+				return
+			}
+			sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
 			return
 		}
-		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
-		return
 	}
 
 	sendError(w, http.StatusNotFound, "not found", "", nil)
 }
 
+// handleCreateJob handles POST /api/v1/sponsor-detection-jobs
+func (h *SponsorDetectionJobHandler) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	if h.enqueuer == nil {
+		sendError(w, http.StatusServiceUnavailable, "service unavailable", "sponsor detection queue not available", nil)
+		return
+	}
+
+	var req CreateDetectionJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid request body", err.Error(), nil)
+		return
+	}
+	req.VideoID = strings.TrimSpace(req.VideoID)
+	req.Model = strings.TrimSpace(req.Model)
+	if req.VideoID == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "video_id is required", nil)
+		return
+	}
+	if req.Model == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "model is required", nil)
+		return
+	}
+	if err := models.ValidateLLMModelName(req.Model); err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	video, err := h.videoRepo.GetVideoByID(r.Context(), req.VideoID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("video with id '%s' not found", req.VideoID), nil)
+			return
+		}
+		h.logger.Error("failed to get video for detection", "error", err, "video_id", req.VideoID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve video", nil)
+		return
+	}
+
+	enrichment, err := h.enrichmentRepo.GetLatestEnrichment(r.Context(), req.VideoID)
+	if err != nil && !db.IsNotFound(err) {
+		h.logger.Error("failed to get enrichment for detection", "error", err, "video_id", req.VideoID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve video enrichment", nil)
+		return
+	}
+	if enrichment == nil || enrichment.Description == nil || *enrichment.Description == "" {
+		sendError(w, http.StatusConflict, "conflict", "video has no enriched description to analyze", nil)
+		return
+	}
+
+	job := &models.SponsorDetectionJob{
+		VideoID:  req.VideoID,
+		LLMModel: req.Model,
+		Status:   "pending",
+	}
+	if err := h.sponsorRepo.CreateDetectionJob(r.Context(), job); err != nil {
+		h.logger.Error("failed to create detection job", "error", err, "video_id", req.VideoID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to create detection job", nil)
+		return
+	}
+
+	if err := h.enqueuer.EnqueueSponsorDetectionWithModel(r.Context(), req.VideoID, video.Title, *enrichment.Description,
+		job.ID.String(), req.Model, queue.SponsorDetectionSourceAPI, 0); err != nil {
+		h.logger.Error("failed to enqueue sponsor detection", "error", err, "job_id", job.ID, "video_id", req.VideoID)
+		errMsg := "failed to enqueue: " + err.Error()
+		if updateErr := h.sponsorRepo.UpdateDetectionJobStatus(r.Context(), job.ID, "failed", &errMsg); updateErr != nil {
+			h.logger.Error("failed to mark detection job failed", "error", updateErr, "job_id", job.ID)
+		}
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to enqueue sponsor detection", nil)
+		return
+	}
+
+	h.logger.Info("enqueued sponsor detection",
+		"job_id", job.ID,
+		"video_id", req.VideoID,
+		"model", req.Model,
+	)
+
+	h.audit.record(r, model.AuditActionCreate, auditResourceSponsorDetectionJob, job.ID.String(), nil, job)
+
+	sendJSON(w, http.StatusAccepted, job)
+}
+
 // handleListJobs handles GET /api/v1/sponsor-detection-jobs
 func (h *SponsorDetectionJobHandler) handleListJobs(w http.ResponseWriter, r *http.Request) {
 	limit := parseLimit(r)
@@ -406,50 +940,21 @@ func (h *SponsorDetectionJobHandler) handleListJobs(w http.ResponseWriter, r *ht
 		}
 	}
 
-	var jobs []*models.SponsorDetectionJob
-	var err error
-
-	// If video_id filter is provided, use GetDetectionJobsByVideoID
-	if videoID != "" {
-		jobs, err = h.sponsorRepo.GetDetectionJobsByVideoID(r.Context(), videoID)
-		if err != nil {
-			h.logger.Error("failed to get detection jobs by video ID", "error", err, "video_id", videoID)
-			sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve detection jobs", nil)
-			return
-		}
-
-		// Apply status filter if specified
-		if status != "" {
-			filteredJobs := make([]*models.SponsorDetectionJob, 0)
-			for _, job := range jobs {
-				if job.Status == status {
-					filteredJobs = append(filteredJobs, job)
-				}
-			}
-			jobs = filteredJobs
-		}
-
-		// Apply pagination manually for filtered results
-		start := offset
-		end := offset + limit
-		if start > len(jobs) {
-			jobs = []*models.SponsorDetectionJob{}
-		} else {
-			if end > len(jobs) {
-				end = len(jobs)
-			}
-			jobs = jobs[start:end]
-		}
-	} else {
-		// For now, without a generic List method, we'll return an error or empty list
-		// This would require a new repository method to support full listing with filters
-		h.logger.Warn("listing all detection jobs without video_id filter is not implemented")
-		jobs = []*models.SponsorDetectionJob{}
+	jobs, total, err := h.sponsorRepo.ListDetectionJobs(r.Context(), repository.DetectionJobFilters{
+		VideoID: videoID,
+		Status:  status,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		h.logger.Error("failed to list detection jobs", "error", err, "video_id", videoID, "status", status)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve detection jobs", nil)
+		return
 	}
 
 	response := map[string]interface{}{
 		"items":  jobs,
-		"total":  len(jobs),
+		"total":  total,
 		"limit":  limit,
 		"offset": offset,
 	}
@@ -457,6 +962,12 @@ func (h *SponsorDetectionJobHandler) handleListJobs(w http.ResponseWriter, r *ht
 	sendJSON(w, http.StatusOK, response)
 }
 
+// DetectionJobDetail is a detection job together with the video_sponsors rows it produced.
+type DetectionJobDetail struct {
+	*models.SponsorDetectionJob
+	Sponsors []*models.VideoSponsor `json:"sponsors"`
+}
+
 // handleGetJob handles GET /api/v1/sponsor-detection-jobs/{id}
 func (h *SponsorDetectionJobHandler) handleGetJob(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) {
 	job, err := h.sponsorRepo.GetDetectionJobByID(r.Context(), jobID)
@@ -471,5 +982,131 @@ func (h *SponsorDetectionJobHandler) handleGetJob(w http.ResponseWriter, r *http
 		return
 	}
 
-	sendJSON(w, http.StatusOK, job)
+	sponsors, err := h.sponsorRepo.GetVideoSponsorsByJobID(r.Context(), jobID)
+	if err != nil {
+		h.logger.Error("failed to get video sponsors for detection job", "error", err, "job_id", jobID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve detection job sponsors", nil)
+		return
+	}
+	if sponsors == nil {
+		sponsors = []*models.VideoSponsor{}
+	}
+
+	sendJSON(w, http.StatusOK, DetectionJobDetail{SponsorDetectionJob: job, Sponsors: sponsors})
+}
+
+// DetectionJobRawResponse is the stored LLM output of a detection job and the prompt that produced it.
+type DetectionJobRawResponse struct {
+	JobID          uuid.UUID  `json:"job_id"`
+	VideoID        string     `json:"video_id"`
+	Status         string     `json:"status"`
+	LLMModel       string     `json:"llm_model"`
+	LLMResponseRaw string     `json:"llm_response_raw"`
+	PromptID       *uuid.UUID `json:"prompt_id,omitempty"`
+	PromptVersion  *string    `json:"prompt_version,omitempty"`
+	PromptText     *string    `json:"prompt_text,omitempty"` // nil if the job has no prompt or it was deleted
+}
+
+// handleGetRawResponse handles GET /api/v1/sponsor-detection-jobs/{id}/raw
+// Jobs that have not stored a response yet (pending, skipped, or failed before the LLM answered) return 404.
+func (h *SponsorDetectionJobHandler) handleGetRawResponse(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) {
+	job, err := h.sponsorRepo.GetDetectionJobByID(r.Context(), jobID)
+	if err != nil {
+		h.logger.Error("failed to get detection job", "error", err, "job_id", jobID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve detection job", nil)
+		return
+	}
+
+	if job == nil {
+		sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("detection job with id '%s' not found", jobID), nil)
+		return
+	}
+
+	if job.LLMResponseRaw == nil {
+		sendError(w, http.StatusNotFound, "not found",
+			fmt.Sprintf("detection job has no stored LLM response (job status is '%s')", job.Status), nil)
+		return
+	}
+
+	response := DetectionJobRawResponse{
+		JobID:          job.ID,
+		VideoID:        job.VideoID,
+		Status:         job.Status,
+		LLMModel:       job.LLMModel,
+		LLMResponseRaw: *job.LLMResponseRaw,
+		PromptID:       job.PromptID,
+	}
+
+	if job.PromptID != nil {
+		prompt, err := h.sponsorRepo.GetPromptByID(r.Context(), *job.PromptID)
+		if err != nil {
+			h.logger.Error("failed to get detection prompt", "error", err, "job_id", jobID, "prompt_id", *job.PromptID)
+			sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve detection prompt", nil)
+			return
+		}
+		if prompt != nil {
+			response.PromptText = &prompt.PromptText
+			response.PromptVersion = prompt.Version
+		}
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
+
+// handleReapplyJob handles POST /api/v1/sponsor-detection-jobs/{id}/reapply
+// It re-parses the job's stored LLM response and regenerates its video_sponsors rows
+// without calling the LLM again.
+func (h *SponsorDetectionJobHandler) handleReapplyJob(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) {
+	job, err := h.sponsorRepo.GetDetectionJobByID(r.Context(), jobID)
+	if err != nil {
+		h.logger.Error("failed to get detection job", "error", err, "job_id", jobID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve detection job", nil)
+		return
+	}
+
+	if job == nil {
+		sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("detection job with id '%s' not found", jobID), nil)
+		return
+	}
+
+	if job.Status != "completed" {
+		sendError(w, http.StatusConflict, "conflict",
+			fmt.Sprintf("only completed jobs can be reapplied (job status is '%s')", job.Status), nil)
+		return
+	}
+
+	if job.LLMResponseRaw == nil || strings.TrimSpace(*job.LLMResponseRaw) == "" {
+		sendError(w, http.StatusConflict, "conflict", "detection job has no stored LLM response", nil)
+		return
+	}
+
+	analysis, err := ollama.ParseAnalysisResponse(*job.LLMResponseRaw)
+	if err != nil {
+		h.logger.Warn("failed to parse stored LLM response", "error", err, "job_id", jobID)
+		sendError(w, http.StatusUnprocessableEntity, "unprocessable entity", "stored LLM response could not be parsed", nil)
+		return
+	}
+
+	storedCount, err := h.sponsorRepo.ReapplyDetectionResults(r.Context(), jobID, job.VideoID, analysis.Sponsors)
+	if err != nil {
+		h.logger.Error("failed to reapply detection results", "error", err, "job_id", jobID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to reapply detection results", nil)
+		return
+	}
+
+	h.logger.Info("reapplied detection results",
+		"job_id", jobID,
+		"video_id", job.VideoID,
+		"sponsors_count", storedCount,
+	)
+
+	response := map[string]interface{}{
+		"job_id":                  jobID,
+		"video_id":                job.VideoID,
+		"sponsors_detected_count": storedCount,
+	}
+
+	h.audit.record(r, model.AuditActionReapply, auditResourceSponsorDetectionJob, jobID.String(), nil, response)
+
+	sendJSON(w, http.StatusOK, response)
 }
@@ -1,16 +1,26 @@
 package handler
 
 import (
+	"bufio"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/middleware"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 
 	"github.com/google/uuid"
 )
@@ -22,6 +32,13 @@ type mockSponsorDetectionRepo struct {
 	detectionJobs      map[uuid.UUID]*models.SponsorDetectionJob
 	videoSponsorsByVid map[string][]*models.VideoSponsorDetail
 	channelSponsors    map[string][]*models.Sponsor
+	reappliedResults   map[uuid.UUID][]models.LLMSponsorResult
+	denylist           map[string]bool // sponsor names ReapplyDetectionResults does not store
+	channelTrends      map[string][]*models.SponsorTrendBucket
+	channelReports     map[string][]*models.ChannelSponsorReportEntry
+	recentDetections   []*models.RecentSponsorDetection
+	prompts            map[uuid.UUID]*models.SponsorDetectionPrompt
+	videos             map[string]*models.Video // joined into GetSponsorVideos results
 }
 
 func newMockSponsorDetectionRepo() *mockSponsorDetectionRepo {
@@ -31,6 +48,11 @@ func newMockSponsorDetectionRepo() *mockSponsorDetectionRepo {
 		detectionJobs:      make(map[uuid.UUID]*models.SponsorDetectionJob),
 		videoSponsorsByVid: make(map[string][]*models.VideoSponsorDetail),
 		channelSponsors:    make(map[string][]*models.Sponsor),
+		reappliedResults:   make(map[uuid.UUID][]models.LLMSponsorResult),
+		channelTrends:      make(map[string][]*models.SponsorTrendBucket),
+		channelReports:     make(map[string][]*models.ChannelSponsorReportEntry),
+		prompts:            make(map[uuid.UUID]*models.SponsorDetectionPrompt),
+		videos:             make(map[string]*models.Video),
 	}
 }
 
@@ -43,7 +65,7 @@ func (m *mockSponsorDetectionRepo) IncrementPromptUsageCount(ctx context.Context
 }
 
 func (m *mockSponsorDetectionRepo) GetPromptByID(ctx context.Context, promptID uuid.UUID) (*models.SponsorDetectionPrompt, error) {
-	return nil, nil
+	return m.prompts[promptID], nil
 }
 
 func (m *mockSponsorDetectionRepo) ListPrompts(ctx context.Context, limit, offset int) ([]*models.SponsorDetectionPrompt, error) {
@@ -66,7 +88,7 @@ func (m *mockSponsorDetectionRepo) IncrementSponsorVideoCount(ctx context.Contex
 	return nil
 }
 
-func (m *mockSponsorDetectionRepo) ListSponsors(ctx context.Context, sortBy string, order string, category string, limit, offset int) ([]*models.Sponsor, error) {
+func (m *mockSponsorDetectionRepo) ListSponsors(ctx context.Context, sortBy string, order string, category, format string, limit, offset int) ([]*models.Sponsor, error) {
 	var results []*models.Sponsor
 	for _, sponsor := range m.sponsors {
 		// Apply category filter if specified
@@ -100,10 +122,20 @@ func (m *mockSponsorDetectionRepo) GetSponsorByID(ctx context.Context, sponsorID
 }
 
 func (m *mockSponsorDetectionRepo) CreateDetectionJob(ctx context.Context, job *models.SponsorDetectionJob) error {
+	job.ID = uuid.New()
+	m.detectionJobs[job.ID] = job
 	return nil
 }
 
 func (m *mockSponsorDetectionRepo) UpdateDetectionJobStatus(ctx context.Context, jobID uuid.UUID, status string, errorMsg *string) error {
+	if job, ok := m.detectionJobs[jobID]; ok {
+		job.Status = status
+		job.ErrorMessage = errorMsg
+	}
+	return nil
+}
+
+func (m *mockSponsorDetectionRepo) UpdateDetectionJobModel(ctx context.Context, jobID uuid.UUID, llmModel string) error {
 	return nil
 }
 
@@ -121,6 +153,25 @@ func (m *mockSponsorDetectionRepo) GetDetectionJobsByVideoID(ctx context.Context
 	return results, nil
 }
 
+func (m *mockSponsorDetectionRepo) ListDetectionJobs(ctx context.Context, filters repository.DetectionJobFilters) ([]*models.SponsorDetectionJob, int, error) {
+	var results []*models.SponsorDetectionJob
+	for _, job := range m.detectionJobs {
+		if filters.VideoID != "" && job.VideoID != filters.VideoID {
+			continue
+		}
+		if filters.Status != "" && job.Status != filters.Status {
+			continue
+		}
+		results = append(results, job)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.After(results[j].CreatedAt) })
+
+	total := len(results)
+	start := min(filters.Offset, total)
+	end := min(start+filters.Limit, total)
+	return results[start:end], total, nil
+}
+
 func (m *mockSponsorDetectionRepo) GetLatestDetectionJobForVideo(ctx context.Context, videoID string) (*models.SponsorDetectionJob, error) {
 	return nil, nil
 }
@@ -133,31 +184,102 @@ func (m *mockSponsorDetectionRepo) GetDetectionJobByID(ctx context.Context, jobI
 	return job, nil
 }
 
+func (m *mockSponsorDetectionRepo) GetVideosWithoutDetection(ctx context.Context, limit int) ([]*models.UndetectedVideo, error) {
+	return nil, nil
+}
+
+func (m *mockSponsorDetectionRepo) CheckSponsorVideoCount(ctx context.Context, sponsorID uuid.UUID, fix bool) (*models.SponsorVideoCountCheck, error) {
+	sponsor, ok := m.sponsors[sponsorID]
+	if !ok {
+		return nil, nil
+	}
+
+	videos := make(map[string]bool)
+	for _, vs := range m.videoSponsors {
+		if vs.SponsorID == sponsorID {
+			videos[vs.VideoID] = true
+		}
+	}
+
+	check := &models.SponsorVideoCountCheck{
+		SponsorID:   sponsorID,
+		StoredCount: sponsor.VideoCount,
+		ActualCount: len(videos),
+	}
+	check.Consistent = check.StoredCount == check.ActualCount
+	if !check.Consistent && fix {
+		sponsor.VideoCount = check.ActualCount
+		check.Fixed = true
+	}
+	return check, nil
+}
+
 func (m *mockSponsorDetectionRepo) CreateVideoSponsor(ctx context.Context, videoSponsor *models.VideoSponsor) error {
 	return nil
 }
 
-func (m *mockSponsorDetectionRepo) GetVideoSponsorsWithDetails(ctx context.Context, videoID string) ([]*models.VideoSponsorDetail, error) {
-	details, ok := m.videoSponsorsByVid[videoID]
-	if !ok {
-		return []*models.VideoSponsorDetail{}, nil
+func (m *mockSponsorDetectionRepo) GetVideoSponsorsWithDetails(ctx context.Context, videoID string, filters repository.VideoSponsorFilters) ([]*models.VideoSponsorDetail, error) {
+	return filterVideoSponsorDetails(m.videoSponsorsByVid[videoID], filters), nil
+}
+
+// filterVideoSponsorDetails applies the repository's confidence filter, ordering and limit
+func filterVideoSponsorDetails(details []*models.VideoSponsorDetail, filters repository.VideoSponsorFilters) []*models.VideoSponsorDetail {
+	results := []*models.VideoSponsorDetail{}
+	for _, detail := range details {
+		if detail.Confidence >= filters.MinConfidence {
+			results = append(results, detail)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Confidence > results[j].Confidence })
+	if filters.Limit > 0 && len(results) > filters.Limit {
+		results = results[:filters.Limit]
+	}
+	return results
+}
+
+func (m *mockSponsorDetectionRepo) GetDistinctVideoSponsors(ctx context.Context, videoID string, filters repository.VideoSponsorFilters) ([]*models.VideoSponsorDetail, error) {
+	best := make(map[uuid.UUID]*models.VideoSponsorDetail)
+	var order []uuid.UUID
+	for _, detail := range m.videoSponsorsByVid[videoID] {
+		current, ok := best[detail.SponsorID]
+		if !ok {
+			order = append(order, detail.SponsorID)
+		}
+		if !ok || detail.Confidence > current.Confidence {
+			best[detail.SponsorID] = detail
+		}
 	}
-	return details, nil
+
+	results := []*models.VideoSponsorDetail{}
+	for _, sponsorID := range order {
+		results = append(results, best[sponsorID])
+	}
+	return filterVideoSponsorDetails(results, filters), nil
 }
 
-func (m *mockSponsorDetectionRepo) GetSponsorVideos(ctx context.Context, sponsorID uuid.UUID, limit, offset int) ([]*models.VideoSponsor, error) {
-	var results []*models.VideoSponsor
+func (m *mockSponsorDetectionRepo) GetSponsorVideos(ctx context.Context, sponsorID uuid.UUID, limit, offset int) ([]*models.SponsorVideoDetail, error) {
+	var results []*models.SponsorVideoDetail
 	for _, vs := range m.videoSponsors {
-		if vs.SponsorID == sponsorID {
-			results = append(results, vs)
+		if vs.SponsorID != sponsorID {
+			continue
+		}
+		detail := &models.SponsorVideoDetail{VideoSponsor: *vs}
+		if video, ok := m.videos[vs.VideoID]; ok {
+			detail.VideoTitle = &video.Title
+			detail.VideoURL = &video.VideoURL
+			detail.VideoChannelID = &video.ChannelID
+			detail.VideoPublishedAt = &video.PublishedAt
+			channelTitle := video.ChannelID // the mock has no channel titles, as for a titleless channel
+			detail.ChannelTitle = &channelTitle
 		}
+		results = append(results, detail)
 	}
 
 	// Simple pagination
 	start := offset
 	end := offset + limit
 	if start > len(results) {
-		return []*models.VideoSponsor{}, nil
+		return []*models.SponsorVideoDetail{}, nil
 	}
 	if end > len(results) {
 		end = len(results)
@@ -167,7 +289,13 @@ func (m *mockSponsorDetectionRepo) GetSponsorVideos(ctx context.Context, sponsor
 }
 
 func (m *mockSponsorDetectionRepo) GetVideoSponsorsByJobID(ctx context.Context, jobID uuid.UUID) ([]*models.VideoSponsor, error) {
-	return nil, nil
+	var result []*models.VideoSponsor
+	for _, vs := range m.videoSponsors {
+		if vs.DetectionJobID == jobID {
+			result = append(result, vs)
+		}
+	}
+	return result, nil
 }
 
 func (m *mockSponsorDetectionRepo) GetSponsorsByChannelID(ctx context.Context, channelID string, limit, offset int) ([]*models.Sponsor, error) {
@@ -189,13 +317,58 @@ func (m *mockSponsorDetectionRepo) GetSponsorsByChannelID(ctx context.Context, c
 	return sponsors[start:end], nil
 }
 
+func (m *mockSponsorDetectionRepo) GetChannelSponsorTrend(ctx context.Context, channelID, interval, format string, since, until *time.Time) ([]*models.SponsorTrendBucket, error) {
+	return m.channelTrends[channelID], nil
+}
+
+func (m *mockSponsorDetectionRepo) GetChannelSponsorReport(ctx context.Context, channelID string) ([]*models.ChannelSponsorReportEntry, error) {
+	return m.channelReports[channelID], nil
+}
+
+func (m *mockSponsorDetectionRepo) StreamChannelSponsorReport(ctx context.Context, channelID string, fn func(*models.ChannelSponsorReportEntry) error) error {
+	for _, entry := range m.channelReports[channelID] {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockSponsorDetectionRepo) GetRecentVideoSponsors(ctx context.Context, limit int) ([]*models.RecentSponsorDetection, error) {
+	detections := m.recentDetections
+	if len(detections) > limit {
+		detections = detections[:limit]
+	}
+	return detections, nil
+}
+
+func (m *mockSponsorDetectionRepo) GetConfidenceHistogram(ctx context.Context, buckets int, llmModel, format string, since, until *time.Time) ([]*models.ConfidenceBucket, error) {
+	histogram := make([]*models.ConfidenceBucket, buckets)
+	for i := range histogram {
+		histogram[i] = &models.ConfidenceBucket{Min: float64(i) / float64(buckets), Max: float64(i+1) / float64(buckets)}
+	}
+	return histogram, nil
+}
+
 func (m *mockSponsorDetectionRepo) SaveDetectionResults(ctx context.Context, jobID uuid.UUID, videoID string, promptID *uuid.UUID, llmResults []models.LLMSponsorResult, llmRawResponse string, processingTimeMs int) error {
 	return nil
 }
 
+func (m *mockSponsorDetectionRepo) ReapplyDetectionResults(ctx context.Context, jobID uuid.UUID, videoID string, llmResults []models.LLMSponsorResult) (int, error) {
+	m.reappliedResults[jobID] = llmResults
+	stored := 0
+	for _, result := range llmResults {
+		if !m.denylist[result.Name] {
+			stored++
+		}
+	}
+	return stored, nil
+}
+
 // Mock video repository for testing
 type mockVideoRepo struct {
-	videos map[string]*models.Video
+	videos      map[string]*models.Video
+	lastFilters *repository.VideoFilters
 }
 
 func newMockVideoRepo() *mockVideoRepo {
@@ -216,15 +389,61 @@ func (m *mockVideoRepo) GetVideoByID(ctx context.Context, videoID string) (*mode
 	return video, nil
 }
 
+func (m *mockVideoRepo) GetVideosByIDs(ctx context.Context, videoIDs []string) (map[string]*models.Video, error) {
+	videos := make(map[string]*models.Video)
+	for _, videoID := range videoIDs {
+		if video, ok := m.videos[videoID]; ok {
+			videos[videoID] = video
+		}
+	}
+	return videos, nil
+}
+
 func (m *mockVideoRepo) List(ctx context.Context, filters *repository.VideoFilters) ([]*models.Video, int, error) {
-	return nil, 0, nil
+	m.lastFilters = filters
+	var videos []*models.Video
+	for _, video := range m.videos {
+		if video.DeletedAt != nil && !filters.IncludeDeleted {
+			continue
+		}
+		videos = append(videos, video)
+	}
+	sort.Slice(videos, func(i, j int) bool { return videos[i].VideoID < videos[j].VideoID })
+	if filters.OrderBy == "first_seen_at" {
+		sort.SliceStable(videos, func(i, j int) bool { return videos[i].FirstSeenAt.Before(videos[j].FirstSeenAt) })
+		if filters.OrderDir != "ASC" {
+			for i, j := 0, len(videos)-1; i < j; i, j = i+1, j-1 {
+				videos[i], videos[j] = videos[j], videos[i]
+			}
+		}
+	}
+	return videos, len(videos), nil
 }
 
 func (m *mockVideoRepo) Update(ctx context.Context, video *models.Video) error {
+	if _, ok := m.videos[video.VideoID]; !ok {
+		return db.ErrNotFound
+	}
+	m.videos[video.VideoID] = video
 	return nil
 }
 
 func (m *mockVideoRepo) Delete(ctx context.Context, videoID string) error {
+	if _, ok := m.videos[videoID]; !ok {
+		return db.ErrNotFound
+	}
+	delete(m.videos, videoID)
+	return nil
+}
+
+func (m *mockVideoRepo) SoftDelete(ctx context.Context, videoID string, deletedAt time.Time) error {
+	video, ok := m.videos[videoID]
+	if !ok {
+		return db.ErrNotFound
+	}
+	if video.DeletedAt == nil {
+		video.DeletedAt = &deletedAt
+	}
 	return nil
 }
 
@@ -244,11 +463,22 @@ func (m *mockVideoRepo) ListVideos(ctx context.Context, limit, offset int) ([]*m
 	return nil, nil
 }
 
+func (m *mockVideoRepo) GetVideosForAvailabilityCheck(ctx context.Context, publishedBefore, checkedBefore time.Time, limit int) ([]*models.Video, error) {
+	return nil, nil
+}
+
+func (m *mockVideoRepo) MarkAvailabilityChecked(ctx context.Context, videoIDs []string, checkedAt time.Time) error {
+	return nil
+}
+
+func (m *mockVideoRepo) MarkUnavailable(ctx context.Context, videoID, reason string, unavailableAt time.Time) error {
+	return nil
+}
+
 // Tests for SponsorHandler
 
 func TestSponsorHandler_ListSponsors(t *testing.T) {
 	repo := newMockSponsorDetectionRepo()
-	videoRepo := newMockVideoRepo()
 
 	// Add test data
 	sponsor1 := &models.Sponsor{
@@ -271,7 +501,7 @@ func TestSponsorHandler_ListSponsors(t *testing.T) {
 	repo.sponsors[sponsor1.ID] = sponsor1
 	repo.sponsors[sponsor2.ID] = sponsor2
 
-	handler := NewSponsorHandler(repo, videoRepo, nil)
+	handler := NewSponsorHandler(repo, nil)
 
 	tests := []struct {
 		name           string
@@ -329,6 +559,11 @@ func TestSponsorHandler_ListSponsors(t *testing.T) {
 			queryParams:    "?order=invalid",
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "invalid format parameter",
+			queryParams:    "?format=vertical",
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -351,7 +586,6 @@ func TestSponsorHandler_ListSponsors(t *testing.T) {
 
 func TestSponsorHandler_GetSponsor(t *testing.T) {
 	repo := newMockSponsorDetectionRepo()
-	videoRepo := newMockVideoRepo()
 
 	sponsorID := uuid.New()
 	sponsor := &models.Sponsor{
@@ -364,7 +598,7 @@ func TestSponsorHandler_GetSponsor(t *testing.T) {
 	}
 	repo.sponsors[sponsorID] = sponsor
 
-	handler := NewSponsorHandler(repo, videoRepo, nil)
+	handler := NewSponsorHandler(repo, nil)
 
 	tests := []struct {
 		name           string
@@ -421,9 +655,181 @@ func TestSponsorHandler_GetSponsor(t *testing.T) {
 	}
 }
 
+func TestSponsorHandler_Integrity(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+
+	sponsorID := uuid.New()
+	repo.sponsors[sponsorID] = &models.Sponsor{ID: sponsorID, Name: "NordVPN", NormalizedName: "nordvpn", VideoCount: 3}
+	videoSponsorID := uuid.New()
+	repo.videoSponsors[videoSponsorID] = &models.VideoSponsor{ID: videoSponsorID, VideoID: "video1", SponsorID: sponsorID}
+
+	handler := NewSponsorHandler(repo, nil)
+
+	integrity := func(method, query string) (*httptest.ResponseRecorder, models.SponsorVideoCountCheck) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/v1/sponsors/"+sponsorID.String()+"/integrity"+query, nil)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+
+		var check models.SponsorVideoCountCheck
+		if resp.Code == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&check); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return resp, check
+	}
+
+	// GET only checks
+	resp, check := integrity(http.MethodGet, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if check.Consistent || check.Fixed || check.StoredCount != 3 || check.ActualCount != 1 {
+		t.Errorf("unexpected check result: %+v", check)
+	}
+	if repo.sponsors[sponsorID].VideoCount != 3 {
+		t.Errorf("GET must not change video_count, got %d", repo.sponsors[sponsorID].VideoCount)
+	}
+
+	// The old GET repair is rejected rather than silently ignored
+	if resp, _ := integrity(http.MethodGet, "?fix=true"); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for GET with fix, got %d", http.StatusBadRequest, resp.Code)
+	}
+	if repo.sponsors[sponsorID].VideoCount != 3 {
+		t.Errorf("GET with fix must not change video_count, got %d", repo.sponsors[sponsorID].VideoCount)
+	}
+
+	// POST corrects the count
+	resp, check = integrity(http.MethodPost, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+	}
+	if !check.Fixed {
+		t.Errorf("expected POST to fix the count: %+v", check)
+	}
+	if repo.sponsors[sponsorID].VideoCount != 1 {
+		t.Errorf("expected video_count 1 after POST, got %d", repo.sponsors[sponsorID].VideoCount)
+	}
+
+	if resp, _ := integrity(http.MethodDelete, ""); resp.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d for DELETE, got %d", http.StatusMethodNotAllowed, resp.Code)
+	}
+}
+
+func TestSponsorHandler_GetRecentDetections(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		repo.recentDetections = append(repo.recentDetections, &models.RecentSponsorDetection{
+			VideoSponsorDetail: models.VideoSponsorDetail{
+				VideoSponsor: models.VideoSponsor{
+					ID:         uuid.New(),
+					VideoID:    fmt.Sprintf("video%d", i),
+					DetectedAt: now.Add(-time.Duration(i) * time.Minute),
+				},
+				SponsorName: "NordVPN",
+			},
+			VideoTitle: fmt.Sprintf("Video %d", i),
+		})
+	}
+
+	handler := NewSponsorHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sponsors/recent?limit=2", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	var response struct {
+		Items []map[string]interface{} `json:"items"`
+		Limit int                      `json:"limit"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(response.Items) != 2 {
+		t.Fatalf("expected 2 detections, got %d", len(response.Items))
+	}
+	if response.Limit != 2 {
+		t.Errorf("expected limit 2, got %d", response.Limit)
+	}
+	if response.Items[0]["video_title"] != "Video 0" || response.Items[0]["sponsor_name"] != "NordVPN" {
+		t.Errorf("expected joined video and sponsor fields, got %v", response.Items[0])
+	}
+}
+
+func TestSponsorHandler_GetConfidenceHistogram(t *testing.T) {
+	handler := NewSponsorHandler(newMockSponsorDetectionRepo(), nil)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		expectedStatus int
+		expectedItems  int
+	}{
+		{
+			name:           "default deciles",
+			queryParams:    "",
+			expectedStatus: http.StatusOK,
+			expectedItems:  10,
+		},
+		{
+			name:           "custom bucket count with model filter",
+			queryParams:    "?buckets=4&llm_model=llama3:8b",
+			expectedStatus: http.StatusOK,
+			expectedItems:  4,
+		},
+		{
+			name:           "too many buckets",
+			queryParams:    "?buckets=1000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid until timestamp",
+			queryParams:    "?until=tomorrow",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sponsors/confidence-histogram"+tt.queryParams, nil)
+			resp := httptest.NewRecorder()
+
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, resp.Code, resp.Body.String())
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			items, ok := response["items"].([]interface{})
+			if !ok {
+				t.Fatal("items field missing or invalid")
+			}
+
+			if len(items) != tt.expectedItems {
+				t.Errorf("expected %d buckets, got %d", tt.expectedItems, len(items))
+			}
+		})
+	}
+}
+
 func TestSponsorHandler_GetSponsorVideos(t *testing.T) {
 	repo := newMockSponsorDetectionRepo()
-	videoRepo := newMockVideoRepo()
 
 	sponsorID := uuid.New()
 	sponsor := &models.Sponsor{
@@ -459,9 +865,9 @@ func TestSponsorHandler_GetSponsorVideos(t *testing.T) {
 		VideoURL:    "https://youtube.com/watch?v=video1",
 		PublishedAt: time.Now(),
 	}
-	videoRepo.videos["video1"] = video1
+	repo.videos["video1"] = video1
 
-	handler := NewSponsorHandler(repo, videoRepo, nil)
+	handler := NewSponsorHandler(repo, nil)
 
 	tests := []struct {
 		name           string
@@ -492,6 +898,15 @@ func TestSponsorHandler_GetSponsorVideos(t *testing.T) {
 				if firstItem["video_id"] != "video1" {
 					t.Errorf("expected video_id 'video1', got '%v'", firstItem["video_id"])
 				}
+				if firstItem["video_title"] != "Test Video 1" {
+					t.Errorf("expected video_title 'Test Video 1', got '%v'", firstItem["video_title"])
+				}
+				if firstItem["video_url"] != "https://youtube.com/watch?v=video1" {
+					t.Errorf("expected video_url from the joined video, got '%v'", firstItem["video_url"])
+				}
+				if firstItem["channel_title"] != "UCtest123" {
+					t.Errorf("expected channel_title to fall back to the channel ID, got '%v'", firstItem["channel_title"])
+				}
 			},
 		},
 		{
@@ -570,6 +985,74 @@ func TestVideoSponsorHandler_GetVideoSponsors(t *testing.T) {
 	}
 }
 
+func TestVideoSponsorHandler_GetVideoSponsors_MinConfidence(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+
+	videoID := "compilation-video"
+	for i, confidence := range []float64{0.4, 0.95, 0.6, 0.85} {
+		repo.videoSponsorsByVid[videoID] = append(repo.videoSponsorsByVid[videoID], &models.VideoSponsorDetail{
+			VideoSponsor: models.VideoSponsor{
+				ID:         uuid.New(),
+				VideoID:    videoID,
+				SponsorID:  uuid.New(),
+				Confidence: confidence,
+			},
+			SponsorName: fmt.Sprintf("Sponsor %d", i),
+		})
+	}
+
+	handler := NewVideoSponsorHandler(repo, nil)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/videos/"+videoID+"/sponsors?"+query, nil)
+		resp := httptest.NewRecorder()
+		handler.HandleGetVideoSponsors(resp, req, videoID)
+		return resp
+	}
+
+	t.Run("excludes low-confidence rows", func(t *testing.T) {
+		resp := get("min_confidence=0.8")
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+
+		var response struct {
+			Items []models.VideoSponsorDetail `json:"items"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		if len(response.Items) != 2 {
+			t.Fatalf("expected 2 sponsors at or above 0.8, got %d", len(response.Items))
+		}
+		if response.Items[0].Confidence != 0.95 || response.Items[1].Confidence != 0.85 {
+			t.Errorf("expected confidences [0.95 0.85], got [%v %v]", response.Items[0].Confidence, response.Items[1].Confidence)
+		}
+	})
+
+	t.Run("caps the number of sponsors", func(t *testing.T) {
+		handler.SetMaxSponsors(3)
+		defer handler.SetMaxSponsors(0)
+
+		var response struct {
+			Items []models.VideoSponsorDetail `json:"items"`
+		}
+		if err := json.NewDecoder(get("limit=10").Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(response.Items) != 3 {
+			t.Errorf("expected the cap of 3 sponsors, got %d", len(response.Items))
+		}
+	})
+
+	t.Run("rejects an out-of-range min_confidence", func(t *testing.T) {
+		if resp := get("min_confidence=1.5"); resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+}
+
 func TestChannelSponsorHandler_GetChannelSponsors(t *testing.T) {
 	repo := newMockSponsorDetectionRepo()
 	videoRepo := newMockVideoRepo()
@@ -611,52 +1094,295 @@ func TestChannelSponsorHandler_GetChannelSponsors(t *testing.T) {
 	}
 }
 
-func TestSponsorDetectionJobHandler_GetJob(t *testing.T) {
+func TestChannelSponsorHandler_GetChannelSponsorReport(t *testing.T) {
 	repo := newMockSponsorDetectionRepo()
 
-	jobID := uuid.New()
-	job := &models.SponsorDetectionJob{
-		ID:                    jobID,
-		VideoID:               "test-video",
-		LLMModel:              "ollama:llama3.2",
-		Status:                "completed",
-		SponsorsDetectedCount: 2,
-		CreatedAt:             time.Now(),
-		UpdatedAt:             time.Now(),
+	category := "vpn"
+	nordID, skillshareID := uuid.New(), uuid.New()
+	repo.channelReports["UCtest123"] = []*models.ChannelSponsorReportEntry{
+		{
+			SponsorID: nordID, SponsorName: "NordVPN", SponsorCategory: &category, VideoCount: 12,
+			FirstSeenAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), LastSeenAt: time.Date(2025, 1, 15, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			SponsorID: skillshareID, SponsorName: "Skillshare, Inc.", VideoCount: 3,
+			FirstSeenAt: time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC), LastSeenAt: time.Date(2024, 9, 9, 9, 0, 0, 0, time.UTC),
+		},
 	}
-	repo.detectionJobs[jobID] = job
 
-	handler := NewSponsorDetectionJobHandler(repo, nil)
+	handler := NewChannelSponsorHandler(repo, newMockVideoRepo(), nil)
 
-	tests := []struct {
-		name           string
-		jobID          string
-		expectedStatus int
-		checkResponse  func(t *testing.T, resp *httptest.ResponseRecorder)
-	}{
-		{
-			name:           "get existing job",
-			jobID:          jobID.String(),
-			expectedStatus: http.StatusOK,
-			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
-				var result models.SponsorDetectionJob
-				if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-					t.Fatalf("failed to decode response: %v", err)
-				}
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/channels/UCtest123/sponsor-report", nil)
+		resp := httptest.NewRecorder()
 
-				if result.ID != jobID {
-					t.Errorf("expected job ID %s, got %s", jobID, result.ID)
-				}
+		handler.HandleGetChannelSponsorReport(resp, req, "UCtest123")
 
-				if result.Status != "completed" {
-					t.Errorf("expected status 'completed', got '%s'", result.Status)
-				}
-			},
-		},
-		{
-			name:           "job not found",
-			jobID:          uuid.New().String(),
-			expectedStatus: http.StatusNotFound,
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+
+		var response struct {
+			ChannelID string                              `json:"channel_id"`
+			Items     []*models.ChannelSponsorReportEntry `json:"items"`
+			Total     int                                 `json:"total"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		if response.Total != 2 || len(response.Items) != 2 {
+			t.Fatalf("expected 2 sponsors, got total %d with %d items", response.Total, len(response.Items))
+		}
+		if response.Items[0].SponsorName != "NordVPN" || response.Items[0].VideoCount != 12 {
+			t.Errorf("expected NordVPN with 12 videos first, got %s with %d", response.Items[0].SponsorName, response.Items[0].VideoCount)
+		}
+		if response.Items[1].SponsorName != "Skillshare, Inc." || response.Items[1].VideoCount != 3 {
+			t.Errorf("expected Skillshare, Inc. with 3 videos second, got %s with %d", response.Items[1].SponsorName, response.Items[1].VideoCount)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/channels/UCtest123/sponsor-report?format=csv", nil)
+		resp := httptest.NewRecorder()
+
+		handler.HandleGetChannelSponsorReport(resp, req, "UCtest123")
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d. Body: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		if ct := resp.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+			t.Errorf("expected text/csv content type, got %q", ct)
+		}
+		if cd := resp.Header().Get("Content-Disposition"); !strings.Contains(cd, "UCtest123-sponsor-report.csv") {
+			t.Errorf("expected attachment filename, got %q", cd)
+		}
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to parse csv: %v", err)
+		}
+		expected := [][]string{
+			{"sponsor_id", "sponsor_name", "sponsor_category", "video_count", "first_seen_at", "last_seen_at"},
+			{nordID.String(), "NordVPN", "vpn", "12", "2024-03-01T10:00:00Z", "2025-01-15T18:30:00Z"},
+			{skillshareID.String(), "Skillshare, Inc.", "", "3", "2024-06-02T09:00:00Z", "2024-09-09T09:00:00Z"},
+		}
+		if !reflect.DeepEqual(records, expected) {
+			t.Errorf("expected csv %v, got %v", expected, records)
+		}
+	})
+
+	t.Run("unknown channel has an empty report", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/channels/UCother/sponsor-report", nil)
+		resp := httptest.NewRecorder()
+
+		handler.HandleGetChannelSponsorReport(resp, req, "UCother")
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.Code)
+		}
+		if !strings.Contains(resp.Body.String(), `"items":[]`) {
+			t.Errorf("expected empty items, got %s", resp.Body.String())
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/channels/UCtest123/sponsor-report?format=xlsx", nil)
+		resp := httptest.NewRecorder()
+
+		handler.HandleGetChannelSponsorReport(resp, req, "UCtest123")
+
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.Code)
+		}
+	})
+}
+
+// pausingReportRepo streams a sponsor report but stops after the first flushed chunk until the
+// client has read it, so the report only completes if rows reach the client while it is streaming.
+type pausingReportRepo struct {
+	*mockSponsorDetectionRepo
+	chunkRead chan struct{}
+}
+
+func (m *pausingReportRepo) StreamChannelSponsorReport(ctx context.Context, channelID string, fn func(*models.ChannelSponsorReportEntry) error) error {
+	for i, entry := range m.channelReports[channelID] {
+		if i == sponsorReportFlushRows {
+			select {
+			case <-m.chunkRead:
+			case <-time.After(5 * time.Second):
+				return fmt.Errorf("first chunk was not delivered while streaming")
+			}
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestChannelSponsorHandler_GetChannelSponsorReport_StreamsThroughMiddleware(t *testing.T) {
+	repo := &pausingReportRepo{mockSponsorDetectionRepo: newMockSponsorDetectionRepo(), chunkRead: make(chan struct{})}
+	for i := 0; i < sponsorReportFlushRows+50; i++ {
+		repo.channelReports["UCtest123"] = append(repo.channelReports["UCtest123"], &models.ChannelSponsorReportEntry{
+			SponsorID: uuid.New(), SponsorName: fmt.Sprintf("Sponsor %d", i), VideoCount: 1,
+			FirstSeenAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), LastSeenAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		})
+	}
+
+	h := NewChannelSponsorHandler(repo, newMockVideoRepo(), nil)
+	chain := middleware.NewGzip(1, nil).Middleware(middleware.NewRequestTimeout(time.Minute, nil).Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.HandleGetChannelSponsorReport(w, r, "UCtest123")
+		})))
+
+	server := httptest.NewServer(chain)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/channels/UCtest123/sponsor-report?format=csv", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected text/csv content type, got %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	lines := 0
+	for ; lines <= sponsorReportFlushRows; lines++ {
+		if _, err := reader.ReadString('\n'); err != nil {
+			t.Fatalf("failed to read streamed row %d: %v", lines, err)
+		}
+	}
+	close(repo.chunkRead)
+
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse csv: %v", err)
+	}
+	if got := lines - 1 + len(records); got != sponsorReportFlushRows+50 {
+		t.Errorf("expected %d rows, got %d", sponsorReportFlushRows+50, got)
+	}
+}
+
+// failingReportRepo streams a few chunks of a sponsor report and then fails, as a query
+// cancelled by the request timeout does
+type failingReportRepo struct {
+	*mockSponsorDetectionRepo
+}
+
+func (m *failingReportRepo) StreamChannelSponsorReport(ctx context.Context, channelID string, fn func(*models.ChannelSponsorReportEntry) error) error {
+	for i := 0; i < 2*sponsorReportFlushRows; i++ {
+		entry := &models.ChannelSponsorReportEntry{SponsorID: uuid.New(), SponsorName: fmt.Sprintf("Sponsor %d", i), VideoCount: 1}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return context.DeadlineExceeded
+}
+
+func TestChannelSponsorHandler_GetChannelSponsorReport_AbortsOnMidStreamError(t *testing.T) {
+	h := NewChannelSponsorHandler(&failingReportRepo{mockSponsorDetectionRepo: newMockSponsorDetectionRepo()}, newMockVideoRepo(), nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.HandleGetChannelSponsorReport(w, r, "UCtest123")
+	}))
+	defer server.Close()
+	// The server logs the aborted handler; keep it out of the test output
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+
+	resp, err := http.Get(server.URL + "/api/v1/channels/UCtest123/sponsor-report?format=csv")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the streamed status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Error("expected reading a report that failed mid-stream to fail, not to end cleanly")
+	}
+}
+
+func TestSponsorDetectionJobHandler_GetJob(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+
+	jobID := uuid.New()
+	job := &models.SponsorDetectionJob{
+		ID:                    jobID,
+		VideoID:               "test-video",
+		LLMModel:              "ollama:llama3.2",
+		Status:                "completed",
+		SponsorsDetectedCount: 2,
+		CreatedAt:             time.Now(),
+		UpdatedAt:             time.Now(),
+	}
+	repo.detectionJobs[jobID] = job
+
+	sponsorID := uuid.New()
+	videoSponsorID := uuid.New()
+	repo.videoSponsors[videoSponsorID] = &models.VideoSponsor{
+		ID:             videoSponsorID,
+		VideoID:        "test-video",
+		SponsorID:      sponsorID,
+		DetectionJobID: jobID,
+		Confidence:     0.9,
+		Evidence:       "sponsored by Acme",
+	}
+	// A sponsor from another job must not be included
+	otherID := uuid.New()
+	repo.videoSponsors[otherID] = &models.VideoSponsor{ID: otherID, VideoID: "test-video", DetectionJobID: uuid.New()}
+
+	handler := NewSponsorDetectionJobHandler(repo, nil)
+
+	tests := []struct {
+		name           string
+		jobID          string
+		expectedStatus int
+		checkResponse  func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "get existing job",
+			jobID:          jobID.String(),
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				var result DetectionJobDetail
+				if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+
+				if result.ID != jobID {
+					t.Errorf("expected job ID %s, got %s", jobID, result.ID)
+				}
+
+				if result.Status != "completed" {
+					t.Errorf("expected status 'completed', got '%s'", result.Status)
+				}
+
+				if len(result.Sponsors) != 1 {
+					t.Fatalf("expected 1 sponsor, got %d", len(result.Sponsors))
+				}
+
+				if result.Sponsors[0].ID != videoSponsorID || result.Sponsors[0].SponsorID != sponsorID {
+					t.Errorf("expected video sponsor %s for sponsor %s, got %+v", videoSponsorID, sponsorID, result.Sponsors[0])
+				}
+			},
+		},
+		{
+			name:           "job not found",
+			jobID:          uuid.New().String(),
+			expectedStatus: http.StatusNotFound,
 		},
 		{
 			name:           "invalid job ID format",
@@ -770,3 +1496,489 @@ func TestSponsorDetectionJobHandler_ListJobs(t *testing.T) {
 		})
 	}
 }
+
+func TestSponsorDetectionJobHandler_ListJobs_Total(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		id := uuid.New()
+		repo.detectionJobs[id] = &models.SponsorDetectionJob{
+			ID: id, VideoID: "test-video", Status: "completed", CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	failedID := uuid.New()
+	repo.detectionJobs[failedID] = &models.SponsorDetectionJob{ID: failedID, VideoID: "test-video", Status: "failed", CreatedAt: now}
+	otherID := uuid.New()
+	repo.detectionJobs[otherID] = &models.SponsorDetectionJob{ID: otherID, VideoID: "other-video", Status: "completed", CreatedAt: now}
+
+	handler := NewSponsorDetectionJobHandler(repo, nil)
+
+	tests := []struct {
+		name          string
+		queryParams   string
+		expectedItems int
+		expectedTotal int
+	}{
+		{name: "first page", queryParams: "?video_id=test-video&status=completed&limit=2", expectedItems: 2, expectedTotal: 5},
+		{name: "last page", queryParams: "?video_id=test-video&status=completed&limit=2&offset=4", expectedItems: 1, expectedTotal: 5},
+		{name: "offset past the end", queryParams: "?video_id=test-video&status=completed&limit=2&offset=10", expectedItems: 0, expectedTotal: 5},
+		{name: "all statuses", queryParams: "?video_id=test-video&limit=2", expectedItems: 2, expectedTotal: 6},
+		{name: "all videos", queryParams: "?status=completed&limit=2", expectedItems: 2, expectedTotal: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sponsor-detection-jobs"+tt.queryParams, nil)
+			resp := httptest.NewRecorder()
+
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d. Body: %s", resp.Code, resp.Body.String())
+			}
+
+			var response struct {
+				Items []models.SponsorDetectionJob `json:"items"`
+				Total int                          `json:"total"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if len(response.Items) != tt.expectedItems {
+				t.Errorf("expected %d items, got %d", tt.expectedItems, len(response.Items))
+			}
+			if response.Total != tt.expectedTotal {
+				t.Errorf("expected total %d, got %d", tt.expectedTotal, response.Total)
+			}
+		})
+	}
+}
+
+func TestSponsorDetectionJobHandler_ReapplyJob(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+
+	rawResponse := `{"sponsors": [{"name": "NordVPN", "confidence": 0.95, "evidence": "use code CREATOR"}, {"name": "Squarespace", "confidence": 1.4, "evidence": "sponsored by Squarespace"}]}`
+	completedJobID := uuid.New()
+	repo.detectionJobs[completedJobID] = &models.SponsorDetectionJob{
+		ID:             completedJobID,
+		VideoID:        "test-video",
+		LLMModel:       "ollama:llama3.2",
+		LLMResponseRaw: &rawResponse,
+		Status:         "completed",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	pendingJobID := uuid.New()
+	repo.detectionJobs[pendingJobID] = &models.SponsorDetectionJob{
+		ID:        pendingJobID,
+		VideoID:   "test-video",
+		LLMModel:  "ollama:llama3.2",
+		Status:    "pending",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	handler := NewSponsorDetectionJobHandler(repo, nil)
+	auditRepo := &mockAuditLogRepo{}
+	handler.SetAuditLog(auditRepo)
+
+	tests := []struct {
+		name           string
+		jobID          string
+		method         string
+		expectedStatus int
+		checkResponse  func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "reapply completed job",
+			jobID:          completedJobID.String(),
+			method:         http.MethodPost,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+
+				if response["sponsors_detected_count"] != float64(2) {
+					t.Errorf("expected sponsors_detected_count 2, got %v", response["sponsors_detected_count"])
+				}
+
+				results, ok := repo.reappliedResults[completedJobID]
+				if !ok {
+					t.Fatal("expected results to be reapplied")
+				}
+				if len(results) != 2 {
+					t.Fatalf("expected 2 reapplied results, got %d", len(results))
+				}
+				if results[0].Name != "NordVPN" {
+					t.Errorf("expected first sponsor 'NordVPN', got '%s'", results[0].Name)
+				}
+				if results[1].Confidence != 1 {
+					t.Errorf("expected confidence to be clamped to 1, got %v", results[1].Confidence)
+				}
+			},
+		},
+		{
+			name:           "reapply non-completed job",
+			jobID:          pendingJobID.String(),
+			method:         http.MethodPost,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "job not found",
+			jobID:          uuid.New().String(),
+			method:         http.MethodPost,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "method not allowed",
+			jobID:          completedJobID.String(),
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/sponsor-detection-jobs/"+tt.jobID+"/reapply", nil)
+			resp := httptest.NewRecorder()
+
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.Code)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, resp)
+			}
+		})
+	}
+
+	if _, ok := repo.reappliedResults[pendingJobID]; ok {
+		t.Error("expected pending job not to be reapplied")
+	}
+
+	if len(auditRepo.entries) != 1 {
+		t.Fatalf("expected only the successful reapply to be audited, got %d entries", len(auditRepo.entries))
+	}
+	entry := auditRepo.entries[0]
+	if entry.Action != model.AuditActionReapply || entry.ResourceType != "sponsor_detection_job" || entry.ResourceID != completedJobID.String() {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+}
+
+func TestSponsorDetectionJobHandler_ReapplyJob_ReportsStoredCount(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+	repo.denylist = map[string]bool{"YouTube Premium": true}
+
+	rawResponse := `{"sponsors": [{"name": "NordVPN", "confidence": 0.95, "evidence": "use code CREATOR"}, {"name": "YouTube Premium", "confidence": 0.9, "evidence": "try YouTube Premium"}]}`
+	jobID := uuid.New()
+	repo.detectionJobs[jobID] = &models.SponsorDetectionJob{
+		ID:             jobID,
+		VideoID:        "test-video",
+		LLMModel:       "ollama:llama3.2",
+		LLMResponseRaw: &rawResponse,
+		Status:         "completed",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	handler := NewSponsorDetectionJobHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sponsor-detection-jobs/"+jobID.String()+"/reapply", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response["sponsors_detected_count"] != float64(1) {
+		t.Errorf("expected the denylisted sponsor to be excluded from sponsors_detected_count, got %v", response["sponsors_detected_count"])
+	}
+}
+
+func TestSponsorDetectionJobHandler_GetRawResponse(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+
+	promptID := uuid.New()
+	version := "v1.0"
+	repo.prompts[promptID] = &models.SponsorDetectionPrompt{ID: promptID, PromptText: "Find sponsors in: Test Video", Version: &version}
+
+	rawResponse := `{"sponsors":[{"name":"NordVPN","confidence":0.95,"evidence":"sponsored by NordVPN"}]}`
+	completedJobID := uuid.New()
+	repo.detectionJobs[completedJobID] = &models.SponsorDetectionJob{
+		ID:             completedJobID,
+		VideoID:        "test-video",
+		LLMModel:       "ollama:llama3.2",
+		PromptID:       &promptID,
+		LLMResponseRaw: &rawResponse,
+		Status:         "completed",
+	}
+
+	pendingJobID := uuid.New()
+	repo.detectionJobs[pendingJobID] = &models.SponsorDetectionJob{
+		ID:       pendingJobID,
+		VideoID:  "test-video",
+		LLMModel: "ollama:llama3.2",
+		Status:   "pending",
+	}
+
+	handler := NewSponsorDetectionJobHandler(repo, nil)
+
+	tests := []struct {
+		name           string
+		jobID          string
+		method         string
+		expectedStatus int
+		checkResponse  func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "completed job returns raw response and prompt",
+			jobID:          completedJobID.String(),
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				var response DetectionJobRawResponse
+				if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+
+				if response.LLMResponseRaw != rawResponse {
+					t.Errorf("expected raw response %q, got %q", rawResponse, response.LLMResponseRaw)
+				}
+				if response.PromptText == nil || *response.PromptText != "Find sponsors in: Test Video" {
+					t.Errorf("expected prompt text to be returned, got %v", response.PromptText)
+				}
+				if response.PromptVersion == nil || *response.PromptVersion != "v1.0" {
+					t.Errorf("expected prompt version v1.0, got %v", response.PromptVersion)
+				}
+				if response.LLMModel != "ollama:llama3.2" {
+					t.Errorf("expected llm_model 'ollama:llama3.2', got '%s'", response.LLMModel)
+				}
+			},
+		},
+		{
+			name:           "pending job has no stored response",
+			jobID:          pendingJobID.String(),
+			method:         http.MethodGet,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "job not found",
+			jobID:          uuid.New().String(),
+			method:         http.MethodGet,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "method not allowed",
+			jobID:          completedJobID.String(),
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/sponsor-detection-jobs/"+tt.jobID+"/raw", nil)
+			resp := httptest.NewRecorder()
+
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.Code)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, resp)
+			}
+		})
+	}
+}
+
+func TestChannelSponsorHandler_GetChannelSponsorTrend(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+	videoRepo := newMockVideoRepo()
+
+	channelID := "UCtest123"
+	repo.channelTrends[channelID] = []*models.SponsorTrendBucket{
+		{Period: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), SponsorCount: 2, SponsoredVideoCount: 3},
+		{Period: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), SponsorCount: 1, SponsoredVideoCount: 1},
+	}
+
+	handler := NewChannelSponsorHandler(repo, videoRepo, nil)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		expectedStatus int
+		expectedItems  int
+	}{
+		{
+			name:           "default monthly interval",
+			queryParams:    "",
+			expectedStatus: http.StatusOK,
+			expectedItems:  2,
+		},
+		{
+			name:           "invalid interval",
+			queryParams:    "?interval=hour",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid since timestamp",
+			queryParams:    "?since=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/channels/"+channelID+"/sponsor-trend"+tt.queryParams, nil)
+			resp := httptest.NewRecorder()
+
+			handler.HandleGetChannelSponsorTrend(resp, req, channelID)
+
+			if resp.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.Code)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if response["interval"] != "month" {
+				t.Errorf("expected interval 'month', got '%v'", response["interval"])
+			}
+
+			items, ok := response["items"].([]interface{})
+			if !ok {
+				t.Fatal("items field missing or invalid")
+			}
+
+			if len(items) != tt.expectedItems {
+				t.Errorf("expected %d buckets, got %d", tt.expectedItems, len(items))
+			}
+		})
+	}
+}
+
+type enqueuedDetection struct {
+	videoID, title, description, jobID, model, source string
+}
+
+type fakeDetectionEnqueuer struct {
+	enqueued []enqueuedDetection
+	err      error
+}
+
+func (f *fakeDetectionEnqueuer) EnqueueSponsorDetectionWithModel(ctx context.Context, videoID, title, description, detectionJobID, llmModel, source string, priority int) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.enqueued = append(f.enqueued, enqueuedDetection{videoID, title, description, detectionJobID, llmModel, source})
+	return nil
+}
+
+func TestSponsorDetectionJobHandler_CreateJob(t *testing.T) {
+	repo := newMockSponsorDetectionRepo()
+	videoRepo := newMockVideoRepo()
+	enrichmentRepo := &mockEnrichmentRepo{}
+	videoRepo.videos["video123"] = &models.Video{VideoID: "video123", Title: "Desk Tour"}
+	videoRepo.videos["unenriched"] = &models.Video{VideoID: "unenriched", Title: "No Enrichment Yet"}
+	description := "Thanks to NordVPN for sponsoring this video"
+	enrichmentRepo.enrichments = append(enrichmentRepo.enrichments, &model.VideoEnrichment{
+		VideoID:     "video123",
+		Description: &description,
+		EnrichedAt:  time.Now(),
+	})
+
+	createJob := func(h *SponsorDetectionJobHandler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sponsor-detection-jobs", strings.NewReader(body))
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("unavailable without a queue", func(t *testing.T) {
+		h := NewSponsorDetectionJobHandler(repo, nil)
+		if resp := createJob(h, `{"video_id":"video123","model":"llama3.1:8b"}`); resp.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", resp.Code)
+		}
+	})
+
+	enqueuer := &fakeDetectionEnqueuer{}
+	h := NewSponsorDetectionJobHandler(repo, nil)
+	h.SetDetectionEnqueuer(enqueuer, videoRepo, enrichmentRepo)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "missing model", body: `{"video_id":"video123"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid model", body: `{"video_id":"video123","model":"bad model!"}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing video_id", body: `{"model":"llama3.1:8b"}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown video", body: `{"video_id":"missing","model":"llama3.1:8b"}`, expectedStatus: http.StatusNotFound},
+		{name: "video without description", body: `{"video_id":"unenriched","model":"llama3.1:8b"}`, expectedStatus: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := createJob(h, tt.body); resp.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d. Body: %s", tt.expectedStatus, resp.Code, resp.Body.String())
+			}
+		})
+	}
+	if len(enqueuer.enqueued) != 0 {
+		t.Fatalf("expected rejected requests to enqueue nothing, got %d", len(enqueuer.enqueued))
+	}
+
+	resp := createJob(h, `{"video_id":"video123","model":"llama3.1:8b"}`)
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d. Body: %s", resp.Code, resp.Body.String())
+	}
+	var job models.SponsorDetectionJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if job.Status != "pending" || job.LLMModel != "llama3.1:8b" || job.VideoID != "video123" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+
+	want := enqueuedDetection{"video123", "Desk Tour", description, job.ID.String(), "llama3.1:8b", "api"}
+	if len(enqueuer.enqueued) != 1 || enqueuer.enqueued[0] != want {
+		t.Errorf("expected %+v to be enqueued, got %+v", want, enqueuer.enqueued)
+	}
+
+	t.Run("enqueue failure marks the job failed", func(t *testing.T) {
+		enqueuer.err = fmt.Errorf("redis down")
+		defer func() { enqueuer.err = nil }()
+
+		if resp := createJob(h, `{"video_id":"video123","model":"llama3.1:8b"}`); resp.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status 500, got %d", resp.Code)
+		}
+		failed := 0
+		for _, j := range repo.detectionJobs {
+			if j.Status == "failed" {
+				failed++
+			}
+		}
+		if failed != 1 {
+			t.Errorf("expected the unqueued job to be marked failed, got %d failed jobs", failed)
+		}
+	})
+}
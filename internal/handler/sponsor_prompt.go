@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+)
+
+// PromptRenderer renders the sponsor detection prompt sent to the LLM
+type PromptRenderer interface {
+	GetPromptText(title, description string) string
+}
+
+// SponsorPromptHandler serves tooling for sponsor detection prompts
+type SponsorPromptHandler struct {
+	renderer       PromptRenderer
+	videoRepo      repository.VideoRepository
+	enrichmentRepo repository.EnrichmentRepository
+	logger         *slog.Logger
+}
+
+// NewSponsorPromptHandler creates a new SponsorPromptHandler. The video and enrichment
+// repositories are used to preview the prompt for a stored video.
+func NewSponsorPromptHandler(
+	renderer PromptRenderer,
+	videoRepo repository.VideoRepository,
+	enrichmentRepo repository.EnrichmentRepository,
+	logger *slog.Logger,
+) *SponsorPromptHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SponsorPromptHandler{
+		renderer:       renderer,
+		videoRepo:      videoRepo,
+		enrichmentRepo: enrichmentRepo,
+		logger:         logger,
+	}
+}
+
+// PromptPreviewRequest is the body of POST /api/v1/sponsor-prompts/preview.
+// Either video_id or title must be set.
+type PromptPreviewRequest struct {
+	// VideoID previews the prompt for a stored video, using its title and the
+	// description from its latest enrichment
+	VideoID     string `json:"video_id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PromptPreviewResponse is the rendered prompt and the inputs it was built from
+type PromptPreviewResponse struct {
+	VideoID     string `json:"video_id,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	PromptText  string `json:"prompt_text"`
+}
+
+// ServeHTTP routes sponsor prompt requests.
+func (h *SponsorPromptHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/sponsor-prompts")
+
+	// POST /api/v1/sponsor-prompts/preview
+	if path == "/preview" {
+		if r.Method == http.MethodPost {
+			h.handlePreview(w, r)
+			return
+		}
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	sendError(w, http.StatusNotFound, "not found", "", nil)
+}
+
+// handlePreview renders the sponsor detection prompt without calling the LLM
+func (h *SponsorPromptHandler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	var req PromptPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid request body", err.Error(), nil)
+		return
+	}
+
+	if req.VideoID == "" && req.Title == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "video_id or title is required", nil)
+		return
+	}
+	if req.VideoID != "" && (req.Title != "" || req.Description != "") {
+		sendError(w, http.StatusBadRequest, "validation failed", "video_id cannot be combined with title or description", nil)
+		return
+	}
+
+	resp := PromptPreviewResponse{
+		VideoID:     req.VideoID,
+		Title:       req.Title,
+		Description: req.Description,
+	}
+
+	if req.VideoID != "" {
+		video, err := h.videoRepo.GetVideoByID(r.Context(), req.VideoID)
+		if err != nil {
+			if db.IsNotFound(err) {
+				sendError(w, http.StatusNotFound, "video not found", "", nil)
+				return
+			}
+			h.logger.Error("failed to get video for prompt preview", "video_id", req.VideoID, "error", err)
+			sendError(w, http.StatusInternalServerError, "failed to get video", "", nil)
+			return
+		}
+		resp.Title = video.Title
+
+		// A video that has not been enriched yet is previewed with an empty description,
+		// as sponsor detection only runs after enrichment
+		enrichment, err := h.enrichmentRepo.GetLatestEnrichment(r.Context(), req.VideoID)
+		if err != nil && !db.IsNotFound(err) {
+			h.logger.Error("failed to get enrichment for prompt preview", "video_id", req.VideoID, "error", err)
+			sendError(w, http.StatusInternalServerError, "failed to get video enrichment", "", nil)
+			return
+		}
+		if enrichment != nil && enrichment.Description != nil {
+			resp.Description = *enrichment.Description
+		}
+	}
+
+	resp.PromptText = h.renderer.GetPromptText(resp.Title, resp.Description)
+	sendJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/ollama"
+)
+
+func newTestSponsorPromptHandler() (*SponsorPromptHandler, *mockVideoRepo, *mockEnrichmentRepo) {
+	videoRepo := newMockVideoRepo()
+	enrichmentRepo := &mockEnrichmentRepo{}
+	h := NewSponsorPromptHandler(ollama.NewClient(ollama.Config{}), videoRepo, enrichmentRepo, nil)
+	return h, videoRepo, enrichmentRepo
+}
+
+func previewPrompt(t *testing.T, h *SponsorPromptHandler, body string) (*httptest.ResponseRecorder, PromptPreviewResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sponsor-prompts/preview", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var resp PromptPreviewResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestSponsorPromptHandler_Preview(t *testing.T) {
+	h, _, _ := newTestSponsorPromptHandler()
+
+	w, resp := previewPrompt(t, h, `{"title":"My Desk Setup Tour","description":"Thanks to NordVPN for sponsoring this video! Use code DESK20"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if !strings.Contains(resp.PromptText, "My Desk Setup Tour") {
+		t.Errorf("Expected prompt to include the title, got %q", resp.PromptText)
+	}
+	if !strings.Contains(resp.PromptText, "Thanks to NordVPN for sponsoring this video! Use code DESK20") {
+		t.Errorf("Expected prompt to include the description, got %q", resp.PromptText)
+	}
+	if want := ollama.NewClient(ollama.Config{}).GetPromptText(resp.Title, resp.Description); resp.PromptText != want {
+		t.Error("Expected the preview to match the prompt sent for detection")
+	}
+}
+
+func TestSponsorPromptHandler_PreviewVideo(t *testing.T) {
+	h, videoRepo, enrichmentRepo := newTestSponsorPromptHandler()
+	videoRepo.videos["video123"] = &models.Video{VideoID: "video123", Title: "Stored Title"}
+	description := "Stored description with a promo code"
+	enrichmentRepo.enrichments = append(enrichmentRepo.enrichments, &model.VideoEnrichment{
+		VideoID:     "video123",
+		Description: &description,
+		EnrichedAt:  time.Now().Add(-time.Hour),
+	})
+	videoRepo.videos["unenriched"] = &models.Video{VideoID: "unenriched", Title: "No Enrichment Yet"}
+
+	w, resp := previewPrompt(t, h, `{"video_id":"video123"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Title != "Stored Title" || resp.Description != description {
+		t.Errorf("Expected stored title and description, got %q / %q", resp.Title, resp.Description)
+	}
+	if !strings.Contains(resp.PromptText, "Stored Title") || !strings.Contains(resp.PromptText, description) {
+		t.Errorf("Expected prompt to include the stored title and description, got %q", resp.PromptText)
+	}
+
+	w, resp = previewPrompt(t, h, `{"video_id":"unenriched"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp.Description != "" {
+		t.Errorf("Expected empty description for an unenriched video, got %q", resp.Description)
+	}
+
+	w, _ = previewPrompt(t, h, `{"video_id":"missing"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown video, got %d", w.Code)
+	}
+}
+
+func TestSponsorPromptHandler_PreviewValidation(t *testing.T) {
+	h, _, _ := newTestSponsorPromptHandler()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid JSON", `{`},
+		{"no title or video", `{"description":"only a description"}`},
+		{"video and title", `{"video_id":"video123","title":"Title"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := previewPrompt(t, h, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sponsor-prompts/preview", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", w.Code)
+	}
+}
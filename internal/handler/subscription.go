@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -19,12 +21,58 @@ var (
 	YouTubeChannelIDRegex = regexp.MustCompile(`^UC[a-zA-Z0-9_-]{22}$`)
 )
 
+const (
+	// DefaultMinLeaseSeconds is the shortest lease a create request may ask for. The hub does
+	// not reliably honor shorter leases, and they expire before the renewer gets to them.
+	DefaultMinLeaseSeconds = 300
+
+	// maxLeaseSeconds is the longest lease the hub grants (10 days)
+	maxLeaseSeconds = 864000
+)
+
+// validateLeaseSeconds checks a requested lease against the hub's limits. Zero is always
+// accepted and means the default lease.
+func validateLeaseSeconds(leaseSeconds, minLeaseSeconds int) error {
+	if leaseSeconds < 0 {
+		return errors.New("lease_seconds must be non-negative")
+	}
+
+	if leaseSeconds == 0 {
+		return nil
+	}
+
+	if leaseSeconds < minLeaseSeconds {
+		return fmt.Errorf("lease_seconds must be at least %d, or 0 for the default lease", minLeaseSeconds)
+	}
+
+	if leaseSeconds > maxLeaseSeconds {
+		return errors.New("lease_seconds cannot exceed 864000 (10 days)")
+	}
+
+	return nil
+}
+
+// findExistingSubscription returns the channel's stored subscription, or nil if it has none.
+// Subscriptions are unique per channel (the callback URL is not stored), so create requests
+// check this before subscribing with the hub rather than failing on insert afterwards.
+func findExistingSubscription(ctx context.Context, repo repository.SubscriptionRepository, channelID string) (*models.Subscription, error) {
+	subs, err := repo.GetByChannelID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, nil
+	}
+	return subs[0], nil
+}
+
 // SubscriptionHandler handles HTTP requests for managing PubSubHubbub subscriptions.
 type SubscriptionHandler struct {
 	repo          repository.SubscriptionRepository
 	hubService    service.PubSubHub
 	webhookSecret string
 	webhookURL    string
+	minLease      int
 	logger        *slog.Logger
 }
 
@@ -44,10 +92,16 @@ func NewSubscriptionHandler(
 		hubService:    hubService,
 		webhookSecret: webhookSecret,
 		webhookURL:    webhookURL,
+		minLease:      DefaultMinLeaseSeconds,
 		logger:        logger,
 	}
 }
 
+// SetMinLeaseSeconds sets the shortest non-zero lease a create request may ask for
+func (h *SubscriptionHandler) SetMinLeaseSeconds(seconds int) {
+	h.minLease = seconds
+}
+
 // CreateSubscriptionRequest represents the request body for creating a subscription.
 type CreateSubscriptionRequest struct {
 	ChannelID    string `json:"channel_id"`
@@ -81,6 +135,22 @@ func (h *SubscriptionHandler) handleCreate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Reject duplicates before the hub round-trip
+	existing, err := findExistingSubscription(r.Context(), h.repo, req.ChannelID)
+	if err != nil {
+		h.logger.Error("failed to check for an existing subscription",
+			"error", err,
+			"channel_id", req.ChannelID,
+		)
+		h.sendError(w, http.StatusInternalServerError, "failed to check existing subscriptions", err.Error())
+		return
+	}
+	if existing != nil {
+		h.sendError(w, http.StatusConflict, "subscription already exists",
+			fmt.Sprintf("channel already has subscription %d (status: %s)", existing.ID, existing.Status))
+		return
+	}
+
 	// Set default lease seconds if not provided
 	if req.LeaseSeconds == 0 {
 		req.LeaseSeconds = 432000 // 5 days default
@@ -89,6 +159,24 @@ func (h *SubscriptionHandler) handleCreate(w http.ResponseWriter, r *http.Reques
 	// Create subscription model
 	sub := models.NewSubscription(req.ChannelID, req.LeaseSeconds)
 
+	// Save the request before sending it, since the hub may verify before it responds
+	sub.MarkLeaseRequested()
+	if err := h.repo.Create(r.Context(), sub); err != nil {
+		h.logger.Error("failed to save subscription to database",
+			"error", err,
+			"channel_id", req.ChannelID,
+		)
+
+		// Check if duplicate
+		if db.IsDuplicateKey(err) {
+			h.sendError(w, http.StatusConflict, "subscription already exists", "a subscription for this channel already exists")
+			return
+		}
+
+		h.sendError(w, http.StatusInternalServerError, "failed to save subscription", err.Error())
+		return
+	}
+
 	// Subscribe via PubSubHubbub
 	hubReq := &service.SubscribeRequest{
 		HubURL:       sub.HubURL,
@@ -110,6 +198,14 @@ func (h *SubscriptionHandler) handleCreate(w http.ResponseWriter, r *http.Reques
 			"channel_id", req.ChannelID,
 		)
 
+		// The subscription was never established, so do not keep its row
+		if deleteErr := h.repo.Delete(r.Context(), sub.ID); deleteErr != nil {
+			h.logger.Error("failed to delete unsubscribed subscription",
+				"error", deleteErr,
+				"subscription_id", sub.ID,
+			)
+		}
+
 		// Determine status code based on error type
 		statusCode := http.StatusInternalServerError
 		if errors.Is(err, service.ErrSubscriptionFailed) {
@@ -123,23 +219,16 @@ func (h *SubscriptionHandler) handleCreate(w http.ResponseWriter, r *http.Reques
 	// If subscription was accepted, mark as active
 	if hubResp.Accepted {
 		sub.MarkActive()
+		err = h.repo.Activate(r.Context(), sub)
 	} else {
 		sub.MarkFailed()
+		err = h.repo.Update(r.Context(), sub)
 	}
-
-	// Save subscription to database
-	if err := h.repo.Create(r.Context(), sub); err != nil {
+	if err != nil {
 		h.logger.Error("failed to save subscription to database",
 			"error", err,
 			"channel_id", req.ChannelID,
 		)
-
-		// Check if duplicate
-		if db.IsDuplicateKey(err) {
-			h.sendError(w, http.StatusConflict, "subscription already exists", "a subscription for this channel already exists")
-			return
-		}
-
 		h.sendError(w, http.StatusInternalServerError, "failed to save subscription", err.Error())
 		return
 	}
@@ -167,16 +256,7 @@ func (h *SubscriptionHandler) validateCreateRequest(req *CreateSubscriptionReque
 		return errors.New("invalid channel_id format (must start with 'UC' followed by 22 characters)")
 	}
 
-	if req.LeaseSeconds < 0 {
-		return errors.New("lease_seconds must be non-negative")
-	}
-
-	// Validate lease seconds range (YouTube typically requires between 1 hour and 10 days)
-	if req.LeaseSeconds > 864000 { // 10 days
-		return errors.New("lease_seconds cannot exceed 864000 (10 days)")
-	}
-
-	return nil
+	return validateLeaseSeconds(req.LeaseSeconds, h.minLease)
 }
 
 // sendError sends a JSON error response.
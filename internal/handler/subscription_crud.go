@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 	"ad-tracker/youtube-webhook-ingestion/internal/service"
 )
 
@@ -22,6 +24,9 @@ type SubscriptionCRUDHandler struct {
 	hubService    service.PubSubHub
 	webhookSecret string
 	webhookURL    string
+	minLease      int
+	audit         auditTrail
+	migrations    *callbackMigrations
 	logger        *slog.Logger
 }
 
@@ -41,10 +46,33 @@ func NewSubscriptionCRUDHandler(
 		hubService:    hubService,
 		webhookSecret: webhookSecret,
 		webhookURL:    webhookURL,
+		minLease:      DefaultMinLeaseSeconds,
+		migrations:    newCallbackMigrations(),
 		logger:        logger,
 	}
 }
 
+// Shutdown cancels running callback migrations and waits for them to stop, or for ctx to end.
+func (h *SubscriptionCRUDHandler) Shutdown(ctx context.Context) error {
+	return h.migrations.shutdown(ctx)
+}
+
+// SetMinLeaseSeconds sets the shortest non-zero lease a create request may ask for
+func (h *SubscriptionCRUDHandler) SetMinLeaseSeconds(seconds int) {
+	h.minLease = seconds
+}
+
+// SetAuditLog records subscription creates, updates and deletes in the audit log
+func (h *SubscriptionCRUDHandler) SetAuditLog(repo repository.AuditLogRepository) {
+	h.audit = auditTrail{repo: repo, logger: h.logger}
+}
+
+// MigrateCallbackRequest represents the request to move subscriptions off an old callback URL.
+type MigrateCallbackRequest struct {
+	OldCallbackURL string `json:"old_callback_url"`
+	DryRun         bool   `json:"dry_run"`
+}
+
 // UpdateSubscriptionRequest represents the request to update a subscription.
 type UpdateSubscriptionRequest struct {
 	LeaseSeconds   *int    `json:"lease_seconds,omitempty"`
@@ -79,6 +107,36 @@ func (h *SubscriptionCRUDHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Handle /abandoned endpoint
+	if path == "/abandoned" {
+		if r.Method == http.MethodGet {
+			h.handleListAbandoned(w, r)
+			return
+		}
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	// Handle /migrate-callback endpoint
+	if path == "/migrate-callback" {
+		if r.Method == http.MethodPost {
+			h.handleMigrateCallback(w, r)
+			return
+		}
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
+	// Handle /migrate-callback/{job_id} endpoint
+	if jobID, ok := strings.CutPrefix(path, "/migrate-callback/"); ok {
+		if r.Method == http.MethodGet {
+			h.handleGetMigration(w, r, jobID)
+			return
+		}
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "", nil)
+		return
+	}
+
 	if strings.HasPrefix(path, "/") {
 		idStr := strings.TrimPrefix(path, "/")
 		id, err := strconv.ParseInt(idStr, 10, 64)
@@ -117,12 +175,47 @@ func (h *SubscriptionCRUDHandler) handleCreate(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Reject duplicates before the hub round-trip
+	existing, err := findExistingSubscription(r.Context(), h.repo, req.ChannelID)
+	if err != nil {
+		h.logger.Error("failed to check for an existing subscription",
+			"error", err,
+			"channel_id", req.ChannelID,
+		)
+		sendError(w, http.StatusInternalServerError, "failed to check existing subscriptions", err.Error(), nil)
+		return
+	}
+	if existing != nil {
+		sendError(w, http.StatusConflict, "subscription already exists", "a subscription for this channel already exists", map[string]interface{}{
+			"subscription_id": existing.ID,
+			"status":          existing.Status,
+		})
+		return
+	}
+
 	if req.LeaseSeconds == 0 {
 		req.LeaseSeconds = 432000
 	}
 
 	sub := models.NewSubscription(req.ChannelID, req.LeaseSeconds)
 
+	// Save the request before sending it, since the hub may verify before it responds
+	sub.MarkLeaseRequested()
+	if err := h.repo.Create(r.Context(), sub); err != nil {
+		h.logger.Error("failed to save subscription to database",
+			"error", err,
+			"channel_id", req.ChannelID,
+		)
+
+		if db.IsDuplicateKey(err) {
+			sendError(w, http.StatusConflict, "subscription already exists", "a subscription for this channel already exists", nil)
+			return
+		}
+
+		sendError(w, http.StatusInternalServerError, "failed to save subscription", err.Error(), nil)
+		return
+	}
+
 	hubReq := &service.SubscribeRequest{
 		HubURL:       sub.HubURL,
 		TopicURL:     sub.TopicURL,
@@ -143,6 +236,14 @@ func (h *SubscriptionCRUDHandler) handleCreate(w http.ResponseWriter, r *http.Re
 			"channel_id", req.ChannelID,
 		)
 
+		// The subscription was never established, so do not keep its row
+		if deleteErr := h.repo.Delete(r.Context(), sub.ID); deleteErr != nil {
+			h.logger.Error("failed to delete unsubscribed subscription",
+				"error", deleteErr,
+				"subscription_id", sub.ID,
+			)
+		}
+
 		statusCode := http.StatusInternalServerError
 		if errors.Is(err, service.ErrSubscriptionFailed) {
 			statusCode = http.StatusBadRequest
@@ -154,21 +255,16 @@ func (h *SubscriptionCRUDHandler) handleCreate(w http.ResponseWriter, r *http.Re
 
 	if hubResp.Accepted {
 		sub.MarkActive()
+		err = h.repo.Activate(r.Context(), sub)
 	} else {
 		sub.MarkFailed()
+		err = h.repo.Update(r.Context(), sub)
 	}
-
-	if err := h.repo.Create(r.Context(), sub); err != nil {
+	if err != nil {
 		h.logger.Error("failed to save subscription to database",
 			"error", err,
 			"channel_id", req.ChannelID,
 		)
-
-		if db.IsDuplicateKey(err) {
-			sendError(w, http.StatusConflict, "subscription already exists", "a subscription for this channel already exists", nil)
-			return
-		}
-
 		sendError(w, http.StatusInternalServerError, "failed to save subscription", err.Error(), nil)
 		return
 	}
@@ -179,6 +275,8 @@ func (h *SubscriptionCRUDHandler) handleCreate(w http.ResponseWriter, r *http.Re
 		"status", sub.Status,
 	)
 
+	h.audit.record(r, model.AuditActionCreate, auditResourceSubscription, strconv.FormatInt(sub.ID, 10), nil, sub)
+
 	sendJSON(w, http.StatusCreated, sub)
 }
 
@@ -232,6 +330,34 @@ func (h *SubscriptionCRUDHandler) handleList(w http.ResponseWriter, r *http.Requ
 	sendJSON(w, http.StatusOK, response)
 }
 
+// handleListAbandoned lists subscriptions the renewer gave up on, for review
+func (h *SubscriptionCRUDHandler) handleListAbandoned(w http.ResponseWriter, r *http.Request) {
+	limit := parseLimit(r)
+	offset := parseOffset(r)
+
+	filters := &repository.SubscriptionFilters{
+		Limit:  limit,
+		Offset: offset,
+		Status: models.StatusAbandoned,
+	}
+
+	subscriptions, total, err := h.repo.List(r.Context(), filters)
+	if err != nil {
+		h.logger.Error("failed to list abandoned subscriptions", "error", err)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to list subscriptions", nil)
+		return
+	}
+
+	response := map[string]interface{}{
+		"items":  subscriptions,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	sendJSON(w, http.StatusOK, response)
+}
+
 func (h *SubscriptionCRUDHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id int64) {
 	var req UpdateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -249,6 +375,7 @@ func (h *SubscriptionCRUDHandler) handleUpdate(w http.ResponseWriter, r *http.Re
 		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve subscription", nil)
 		return
 	}
+	before := *sub
 
 	if req.LeaseSeconds != nil {
 		sub.LeaseSeconds = *req.LeaseSeconds
@@ -256,6 +383,10 @@ func (h *SubscriptionCRUDHandler) handleUpdate(w http.ResponseWriter, r *http.Re
 
 	if req.Status != nil {
 		sub.Status = *req.Status
+		// Reactivating an abandoned or failed subscription gives it a fresh failure budget
+		if sub.Status == models.StatusActive || sub.Status == models.StatusPending {
+			sub.FailureCount = 0
+		}
 	}
 
 	if req.ExpiresAt != nil {
@@ -282,34 +413,39 @@ func (h *SubscriptionCRUDHandler) handleUpdate(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	h.audit.record(r, model.AuditActionUpdate, auditResourceSubscription, strconv.FormatInt(id, 10), &before, sub)
+
 	sendJSON(w, http.StatusOK, sub)
 }
 
 func (h *SubscriptionCRUDHandler) handleDelete(w http.ResponseWriter, r *http.Request, id int64) {
 	unsubscribe, _ := parseBool(r, "unsubscribe")
+	unsubscribeFromHub := unsubscribe != nil && *unsubscribe
 
-	if unsubscribe != nil && *unsubscribe {
-		sub, err := h.repo.GetByID(r.Context(), id)
+	var sub *models.Subscription
+	if unsubscribeFromHub || h.audit.enabled() {
+		var err error
+		sub, err = h.repo.GetByID(r.Context(), id)
 		if err != nil && !db.IsNotFound(err) {
-			h.logger.Error("failed to get subscription for unsubscribe", "error", err, "id", id)
+			h.logger.Error("failed to get subscription before deletion", "error", err, "id", id)
 		}
+	}
 
-		if err == nil {
-			unsubReq := &service.SubscribeRequest{
-				HubURL:      sub.HubURL,
-				TopicURL:    sub.TopicURL,
-				CallbackURL: h.webhookURL,
-			}
+	if unsubscribeFromHub && sub != nil {
+		unsubReq := &service.SubscribeRequest{
+			HubURL:      sub.HubURL,
+			TopicURL:    sub.TopicURL,
+			CallbackURL: h.webhookURL,
+		}
 
-			_, err := h.hubService.Unsubscribe(r.Context(), unsubReq)
-			if err != nil {
-				h.logger.Warn("failed to unsubscribe from hub (continuing with deletion)",
-					"error", err,
-					"subscription_id", id,
-				)
-			} else {
-				h.logger.Info("successfully unsubscribed from hub", "subscription_id", id)
-			}
+		_, err := h.hubService.Unsubscribe(r.Context(), unsubReq)
+		if err != nil {
+			h.logger.Warn("failed to unsubscribe from hub (continuing with deletion)",
+				"error", err,
+				"subscription_id", id,
+			)
+		} else {
+			h.logger.Info("successfully unsubscribed from hub", "subscription_id", id)
 		}
 	}
 
@@ -323,6 +459,8 @@ func (h *SubscriptionCRUDHandler) handleDelete(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	h.audit.record(r, model.AuditActionDelete, auditResourceSubscription, strconv.FormatInt(id, 10), sub, nil)
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -335,15 +473,7 @@ func (h *SubscriptionCRUDHandler) validateCreateRequest(req *CreateSubscriptionR
 		return errors.New("invalid channel_id format (must start with 'UC' followed by 22 characters)")
 	}
 
-	if req.LeaseSeconds < 0 {
-		return errors.New("lease_seconds must be non-negative")
-	}
-
-	if req.LeaseSeconds > 864000 {
-		return errors.New("lease_seconds cannot exceed 864000 (10 days)")
-	}
-
-	return nil
+	return validateLeaseSeconds(req.LeaseSeconds, h.minLease)
 }
 
 // handleRenewAll forces renewal of all active subscriptions.
@@ -383,6 +513,21 @@ func (h *SubscriptionCRUDHandler) handleRenewAll(w http.ResponseWriter, r *http.
 			SubscriptionID: sub.ID,
 			ChannelID:      sub.ChannelID,
 		}
+		before := *sub
+		saved := true
+
+		// Record the request before sending it, since the hub may verify before it responds
+		sub.MarkLeaseRequested()
+		if err := h.repo.Update(r.Context(), sub); err != nil {
+			h.logger.Error("failed to record lease request",
+				"subscription_id", sub.ID,
+				"error", err,
+			)
+			result.Error = fmt.Sprintf("update failed: %v", err)
+			failureCount++
+			results = append(results, result)
+			continue
+		}
 
 		// Create subscription request
 		hubReq := &service.SubscribeRequest{
@@ -411,12 +556,15 @@ func (h *SubscriptionCRUDHandler) handleRenewAll(w http.ResponseWriter, r *http.
 					"subscription_id", sub.ID,
 					"error", updateErr,
 				)
+				saved = false
 			}
 		} else {
 			// Update subscription based on response
+			var updateErr error
 			if hubResp.Accepted {
 				sub.MarkActive()
 				sub.UpdateExpiry(sub.LeaseSeconds)
+				updateErr = h.repo.Activate(r.Context(), sub)
 				result.Success = true
 				successCount++
 
@@ -427,17 +575,19 @@ func (h *SubscriptionCRUDHandler) handleRenewAll(w http.ResponseWriter, r *http.
 				)
 			} else {
 				sub.MarkFailed()
+				updateErr = h.repo.Update(r.Context(), sub)
 				result.Error = "hub rejected subscription"
 				failureCount++
 			}
 
-			// Save updated subscription
-			if updateErr := h.repo.Update(r.Context(), sub); updateErr != nil {
+			// Report a failed save
+			if updateErr != nil {
 				h.logger.Error("failed to update subscription",
 					"subscription_id", sub.ID,
 					"error", updateErr,
 				)
 				result.Error = fmt.Sprintf("update failed: %v", updateErr)
+				saved = false
 				if result.Success {
 					successCount--
 					failureCount++
@@ -446,6 +596,9 @@ func (h *SubscriptionCRUDHandler) handleRenewAll(w http.ResponseWriter, r *http.
 			}
 		}
 
+		if saved {
+			h.audit.record(r, model.AuditActionRenew, auditResourceSubscription, strconv.FormatInt(sub.ID, 10), &before, sub)
+		}
 		results = append(results, result)
 	}
 
@@ -464,3 +617,102 @@ func (h *SubscriptionCRUDHandler) handleRenewAll(w http.ResponseWriter, r *http.
 
 	sendJSON(w, http.StatusOK, response)
 }
+
+// handleMigrateCallback moves the active and pending subscriptions from an old callback URL
+// to the configured webhook URL. The migration runs in the background and the response
+// carries its job ID; each channel is unsubscribed from the old callback and then
+// subscribed with the new one, and failures are recorded per subscription without
+// stopping the run. A dry run only lists the subscriptions and answers inline.
+func (h *SubscriptionCRUDHandler) handleMigrateCallback(w http.ResponseWriter, r *http.Request) {
+	var req MigrateCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid request body", err.Error(), nil)
+		return
+	}
+
+	if req.OldCallbackURL == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "old_callback_url is required", nil)
+		return
+	}
+	if req.OldCallbackURL == h.webhookURL {
+		sendError(w, http.StatusBadRequest, "validation failed", "old_callback_url is the current webhook URL", nil)
+		return
+	}
+
+	job := &CallbackMigration{
+		OldCallbackURL: req.OldCallbackURL,
+		NewCallbackURL: h.webhookURL,
+		DryRun:         req.DryRun,
+	}
+
+	if req.DryRun {
+		subscriptions, err := listMigratableSubscriptions(r.Context(), h.repo)
+		if err != nil {
+			h.logger.Error("failed to list subscriptions for callback migration", "error", err)
+			sendError(w, http.StatusInternalServerError, "internal server error", "failed to list subscriptions", nil)
+			return
+		}
+
+		job.Status = MigrationStatusCompleted
+		job.Total = len(subscriptions)
+		job.Processed = len(subscriptions)
+		job.Successful = len(subscriptions)
+		job.Results = make([]MigrationResult, 0, len(subscriptions))
+		for _, sub := range subscriptions {
+			job.Results = append(job.Results, MigrationResult{SubscriptionID: sub.ID, ChannelID: sub.ChannelID, Success: true})
+		}
+		sendJSON(w, http.StatusOK, job)
+		return
+	}
+
+	actor := actorFromRequest(r)
+	h.migrations.start(job, func(ctx context.Context, update func(func(*CallbackMigration))) error {
+		subscriptions, err := listMigratableSubscriptions(ctx, h.repo)
+		if err != nil {
+			h.logger.Error("failed to list subscriptions for callback migration", "error", err, "job_id", job.ID)
+			return err
+		}
+		update(func(j *CallbackMigration) { j.Total = len(subscriptions) })
+
+		h.logger.Info("migrating subscription callbacks",
+			"job_id", job.ID,
+			"old_callback_url", req.OldCallbackURL,
+			"new_callback_url", h.webhookURL,
+			"count", len(subscriptions),
+		)
+
+		for _, sub := range subscriptions {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			result := h.migrateSubscription(ctx, sub, req.OldCallbackURL, actor)
+			update(func(j *CallbackMigration) {
+				j.Processed++
+				if result.Success {
+					j.Successful++
+				} else {
+					j.Failed++
+				}
+				j.Results = append(j.Results, result)
+			})
+		}
+
+		h.logger.Info("callback migration completed", "job_id", job.ID, "total", len(subscriptions))
+		return nil
+	})
+
+	snapshot, _ := h.migrations.get(job.ID)
+	sendJSON(w, http.StatusAccepted, snapshot)
+}
+
+// handleGetMigration returns the progress of a callback migration job.
+func (h *SubscriptionCRUDHandler) handleGetMigration(w http.ResponseWriter, r *http.Request, jobID string) {
+	job, ok := h.migrations.get(jobID)
+	if !ok {
+		sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("callback migration job '%s' not found", jobID), nil)
+		return
+	}
+
+	sendJSON(w, http.StatusOK, job)
+}
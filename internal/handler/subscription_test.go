@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
@@ -54,6 +55,11 @@ func (m *mockSubscriptionRepository) Update(ctx context.Context, sub *models.Sub
 	return args.Error(0)
 }
 
+func (m *mockSubscriptionRepository) Activate(ctx context.Context, sub *models.Subscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
 func (m *mockSubscriptionRepository) Delete(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -83,6 +89,11 @@ func (m *mockSubscriptionRepository) List(ctx context.Context, filters *reposito
 	return args.Get(0).([]*models.Subscription), args.Int(1), args.Error(2)
 }
 
+func (m *mockSubscriptionRepository) ConfirmLease(ctx context.Context, topicURL string, leaseSeconds int) (int64, error) {
+	args := m.Called(ctx, topicURL, leaseSeconds)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // Mock PubSubHub service
 type mockPubSubHubService struct {
 	mock.Mock
@@ -121,11 +132,17 @@ func TestSubscriptionHandler_HandleCreate_Success(t *testing.T) {
 		Accepted:   true,
 		StatusCode: http.StatusAccepted,
 	}
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 	hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
 		return req.LeaseSeconds == reqBody.LeaseSeconds
 	})).Return(hubResp, nil)
 
 	repo.On("Create", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ChannelID == reqBody.ChannelID &&
+			sub.Status == models.StatusPending &&
+			sub.LeaseRequestedAt != nil
+	})).Return(nil)
+	repo.On("Activate", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.ChannelID == reqBody.ChannelID &&
 			sub.Status == models.StatusActive
 	})).Return(nil)
@@ -164,9 +181,11 @@ func TestSubscriptionHandler_HandleCreate_WithSecret(t *testing.T) {
 		Accepted:   true,
 		StatusCode: http.StatusAccepted,
 	}
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(hubResp, nil)
 
 	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
 	rec := httptest.NewRecorder()
@@ -195,11 +214,13 @@ func TestSubscriptionHandler_HandleCreate_DefaultLeaseSeconds(t *testing.T) {
 		Accepted:   true,
 		StatusCode: http.StatusAccepted,
 	}
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 	hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
 		return req.LeaseSeconds == 432000
 	})).Return(hubResp, nil)
 
 	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
 	rec := httptest.NewRecorder()
@@ -267,6 +288,14 @@ func TestSubscriptionHandler_HandleCreate_ValidationErrors(t *testing.T) {
 			},
 			errMsg: "lease_seconds cannot exceed 864000",
 		},
+		{
+			name: "lease_seconds below hub minimum",
+			reqBody: CreateSubscriptionRequest{
+				ChannelID:    "UCxxxxxxxxxxxxxxxxxxxxxx",
+				LeaseSeconds: 60,
+			},
+			errMsg: "lease_seconds must be at least 300, or 0 for the default lease",
+		},
 	}
 
 	for _, tt := range tests {
@@ -294,6 +323,57 @@ func TestSubscriptionHandler_HandleCreate_ValidationErrors(t *testing.T) {
 	}
 }
 
+func TestSubscriptionCRUDHandler_HandleCreate_MinLeaseSeconds(t *testing.T) {
+	t.Parallel()
+
+	create := func(t *testing.T, handler *SubscriptionCRUDHandler, leaseSeconds int) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(CreateSubscriptionRequest{ChannelID: "UCxxxxxxxxxxxxxxxxxxxxxx", LeaseSeconds: leaseSeconds})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("rejects a lease below the configured minimum", func(t *testing.T) {
+		t.Parallel()
+
+		repo := new(mockSubscriptionRepository)
+		hubService := new(mockPubSubHubService)
+		handler := NewSubscriptionCRUDHandler(repo, hubService, "", "https://example.com/webhook", nil)
+		handler.SetMinLeaseSeconds(3600)
+
+		rec := create(t, handler, 1800)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var response ErrorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, "lease_seconds must be at least 3600, or 0 for the default lease", response.Message)
+		hubService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	})
+
+	t.Run("accepts zero as the default lease", func(t *testing.T) {
+		t.Parallel()
+
+		repo := new(mockSubscriptionRepository)
+		hubService := new(mockPubSubHubService)
+		handler := NewSubscriptionCRUDHandler(repo, hubService, "", "https://example.com/webhook", nil)
+		handler.SetMinLeaseSeconds(3600)
+
+		repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
+		hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
+			return req.LeaseSeconds == 432000
+		})).Return(&service.SubscribeResponse{Accepted: true, StatusCode: http.StatusAccepted}, nil)
+		repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
+
+		rec := create(t, handler, 0)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		hubService.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
+
 func TestSubscriptionHandler_HandleCreate_HubSubscriptionFailed(t *testing.T) {
 	t.Parallel()
 
@@ -306,8 +386,11 @@ func TestSubscriptionHandler_HandleCreate_HubSubscriptionFailed(t *testing.T) {
 	}
 	body, _ := json.Marshal(reqBody)
 
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
 	hubService.On("Subscribe", mock.Anything, mock.Anything).
 		Return(nil, service.ErrSubscriptionFailed)
+	repo.On("Delete", mock.Anything, int64(1)).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
 	rec := httptest.NewRecorder()
@@ -322,7 +405,7 @@ func TestSubscriptionHandler_HandleCreate_HubSubscriptionFailed(t *testing.T) {
 	assert.Equal(t, "failed to subscribe to hub", response.Error)
 
 	hubService.AssertExpectations(t)
-	repo.AssertNotCalled(t, "Create")
+	repo.AssertExpectations(t)
 }
 
 func TestSubscriptionHandler_HandleCreate_DatabaseError(t *testing.T) {
@@ -337,11 +420,7 @@ func TestSubscriptionHandler_HandleCreate_DatabaseError(t *testing.T) {
 	}
 	body, _ := json.Marshal(reqBody)
 
-	hubResp := &service.SubscribeResponse{
-		Accepted:   true,
-		StatusCode: http.StatusAccepted,
-	}
-	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(hubResp, nil)
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 
 	dbErr := errors.New("database connection failed")
 	repo.On("Create", mock.Anything, mock.Anything).Return(dbErr)
@@ -358,7 +437,7 @@ func TestSubscriptionHandler_HandleCreate_DatabaseError(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "failed to save subscription", response.Error)
 
-	hubService.AssertExpectations(t)
+	hubService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
 	repo.AssertExpectations(t)
 }
 
@@ -374,13 +453,9 @@ func TestSubscriptionHandler_HandleCreate_DuplicateSubscription(t *testing.T) {
 	}
 	body, _ := json.Marshal(reqBody)
 
-	hubResp := &service.SubscribeResponse{
-		Accepted:   true,
-		StatusCode: http.StatusAccepted,
-	}
-	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(hubResp, nil)
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 
-	// Simulate duplicate key error using a proper pgconn error
+	// A subscription created concurrently after the existence check fails on insert
 	duplicateErr := fmt.Errorf("create subscription: %w (constraint: uq_channel_callback)", db.ErrDuplicateKey)
 	repo.On("Create", mock.Anything, mock.Anything).Return(duplicateErr)
 
@@ -396,7 +471,37 @@ func TestSubscriptionHandler_HandleCreate_DuplicateSubscription(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "subscription already exists", response.Error)
 
-	hubService.AssertExpectations(t)
+	hubService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestSubscriptionCRUDHandler_HandleCreate_ExistingSubscription(t *testing.T) {
+	t.Parallel()
+
+	repo := new(mockSubscriptionRepository)
+	hubService := new(mockPubSubHubService)
+	handler := NewSubscriptionCRUDHandler(repo, hubService, "", "https://example.com/webhook", nil)
+
+	existing := models.NewSubscription("UCxxxxxxxxxxxxxxxxxxxxxx", 432000)
+	existing.ID = 42
+	existing.MarkActive()
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{existing}, nil)
+
+	body, _ := json.Marshal(CreateSubscriptionRequest{ChannelID: "UCxxxxxxxxxxxxxxxxxxxxxx"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	var response ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "subscription already exists", response.Error)
+	assert.Equal(t, float64(42), response.Details["subscription_id"])
+
+	hubService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 	repo.AssertExpectations(t)
 }
 
@@ -523,11 +628,13 @@ func TestSubscriptionHandler_HandleCreate_AutomaticWebhookSecret(t *testing.T) {
 		Accepted:   true,
 		StatusCode: http.StatusAccepted,
 	}
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 	hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
 		return req.Secret != nil && *req.Secret == webhookSecret
 	})).Return(hubResp, nil)
 
 	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
 	rec := httptest.NewRecorder()
@@ -557,11 +664,13 @@ func TestSubscriptionHandler_HandleCreate_ExplicitSecretOverridesConfigured(t *t
 		Accepted:   true,
 		StatusCode: http.StatusAccepted,
 	}
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 	hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
 		return req.Secret != nil && *req.Secret == webhookSecret
 	})).Return(hubResp, nil)
 
 	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
 	rec := httptest.NewRecorder()
@@ -591,11 +700,13 @@ func TestSubscriptionHandler_HandleCreate_EmptySecretUsesConfigured(t *testing.T
 		Accepted:   true,
 		StatusCode: http.StatusAccepted,
 	}
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 	hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
 		return req.Secret != nil && *req.Secret == webhookSecret
 	})).Return(hubResp, nil)
 
 	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
 	rec := httptest.NewRecorder()
@@ -625,9 +736,11 @@ func TestSubscriptionHandler_HandleCreate_NoSecretWhenConfiguredIsEmpty(t *testi
 		Accepted:   true,
 		StatusCode: http.StatusAccepted,
 	}
+	repo.On("GetByChannelID", mock.Anything, "UCxxxxxxxxxxxxxxxxxxxxxx").Return([]*models.Subscription{}, nil)
 	hubService.On("Subscribe", mock.Anything, mock.Anything).Return(hubResp, nil)
 
 	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Activate", mock.Anything, mock.Anything).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewReader(body))
 	rec := httptest.NewRecorder()
@@ -638,3 +751,219 @@ func TestSubscriptionHandler_HandleCreate_NoSecretWhenConfiguredIsEmpty(t *testi
 	hubService.AssertExpectations(t)
 	repo.AssertExpectations(t)
 }
+
+func TestSubscriptionCRUDHandler_MigrateCallback(t *testing.T) {
+	t.Parallel()
+
+	const oldURL = "https://old.example.com/webhook"
+	const newURL = "https://new.example.com/webhook"
+
+	newSubs := func() []*models.Subscription {
+		return []*models.Subscription{
+			{ID: 1, ChannelID: "UCaaaaaaaaaaaaaaaaaaaaaa", HubURL: "https://hub.example.com", TopicURL: "https://topic.example.com/a", LeaseSeconds: 432000, Status: models.StatusActive},
+			{ID: 2, ChannelID: "UCbbbbbbbbbbbbbbbbbbbbbb", HubURL: "https://hub.example.com", TopicURL: "https://topic.example.com/b", LeaseSeconds: 432000, Status: models.StatusPending},
+		}
+	}
+
+	// listByStatus serves active and pending subscriptions from subs, one page per status
+	listByStatus := func(repo *mockSubscriptionRepository, subs []*models.Subscription) {
+		for _, status := range []string{models.StatusActive, models.StatusPending} {
+			var page []*models.Subscription
+			for _, sub := range subs {
+				if sub.Status == status {
+					page = append(page, sub)
+				}
+			}
+			repo.On("List", mock.Anything, mock.MatchedBy(func(f *repository.SubscriptionFilters) bool {
+				return f.Status == status && f.Offset == 0
+			})).Return(page, len(page), nil).Once()
+		}
+	}
+
+	migrate := func(handler *SubscriptionCRUDHandler, reqBody MigrateCallbackRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions/migrate-callback", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// waitForJob polls the job's status endpoint until the job has finished
+	waitForJob := func(t *testing.T, handler *SubscriptionCRUDHandler, rec *httptest.ResponseRecorder) CallbackMigration {
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		var started CallbackMigration
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&started))
+		require.NotEmpty(t, started.ID)
+
+		var job CallbackMigration
+		require.Eventually(t, func() bool {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/migrate-callback/"+started.ID, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&job) != nil {
+				return false
+			}
+			return job.Status != MigrationStatusRunning
+		}, time.Second, 5*time.Millisecond)
+		return job
+	}
+
+	t.Run("unsubscribes old and subscribes new callback", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+		hubService := new(mockPubSubHubService)
+		handler := NewSubscriptionCRUDHandler(repo, hubService, "secret", newURL, nil)
+
+		subs := newSubs()
+		listByStatus(repo, subs)
+
+		accepted := &service.SubscribeResponse{Accepted: true, StatusCode: http.StatusAccepted}
+		for _, sub := range subs {
+			topic := sub.TopicURL
+			hubService.On("Unsubscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
+				return req.TopicURL == topic && req.CallbackURL == oldURL
+			})).Return(accepted, nil).Once()
+			hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
+				return req.TopicURL == topic && req.CallbackURL == newURL && req.Secret != nil && *req.Secret == "secret"
+			})).Return(accepted, nil).Once()
+		}
+		repo.On("Update", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+			return sub.LeaseRequestedAt != nil
+		})).Return(nil).Twice()
+		repo.On("Activate", mock.Anything, mock.MatchedBy(func(sub *models.Subscription) bool {
+			return sub.Status == models.StatusActive && sub.LastVerifiedAt != nil
+		})).Return(nil).Twice()
+
+		job := waitForJob(t, handler, migrate(handler, MigrateCallbackRequest{OldCallbackURL: oldURL}))
+		assert.Equal(t, MigrationStatusCompleted, job.Status)
+		assert.Equal(t, 2, job.Total)
+		assert.Equal(t, 2, job.Successful)
+		assert.Equal(t, 0, job.Failed)
+
+		for _, sub := range subs {
+			assert.Equal(t, models.StatusActive, sub.Status)
+		}
+		hubService.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("pages through every subscription", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+		hubService := new(mockPubSubHubService)
+		handler := NewSubscriptionCRUDHandler(repo, hubService, "", newURL, nil)
+
+		full := make([]*models.Subscription, migrationPageSize)
+		for i := range full {
+			full[i] = &models.Subscription{ID: int64(i + 1), Status: models.StatusActive}
+		}
+		rest := []*models.Subscription{{ID: int64(migrationPageSize + 1), Status: models.StatusActive}}
+		repo.On("List", mock.Anything, mock.MatchedBy(func(f *repository.SubscriptionFilters) bool {
+			return f.Status == models.StatusActive && f.Offset == 0
+		})).Return(full, len(full)+1, nil).Once()
+		repo.On("List", mock.Anything, mock.MatchedBy(func(f *repository.SubscriptionFilters) bool {
+			return f.Status == models.StatusActive && f.Offset == migrationPageSize
+		})).Return(rest, len(full)+1, nil).Once()
+		repo.On("List", mock.Anything, mock.MatchedBy(func(f *repository.SubscriptionFilters) bool {
+			return f.Status == models.StatusPending
+		})).Return([]*models.Subscription{}, 0, nil).Once()
+
+		rec := migrate(handler, MigrateCallbackRequest{OldCallbackURL: oldURL, DryRun: true})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var job CallbackMigration
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+		assert.Equal(t, migrationPageSize+1, job.Total)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("continues past failures", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+		hubService := new(mockPubSubHubService)
+		handler := NewSubscriptionCRUDHandler(repo, hubService, "", newURL, nil)
+
+		subs := newSubs()
+		listByStatus(repo, subs)
+
+		accepted := &service.SubscribeResponse{Accepted: true, StatusCode: http.StatusAccepted}
+		hubService.On("Unsubscribe", mock.Anything, mock.Anything).Return(nil, errors.New("callback unreachable"))
+		hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
+			return req.TopicURL == subs[0].TopicURL
+		})).Return(nil, errors.New("hub unavailable")).Once()
+		hubService.On("Subscribe", mock.Anything, mock.MatchedBy(func(req *service.SubscribeRequest) bool {
+			return req.TopicURL == subs[1].TopicURL
+		})).Return(accepted, nil).Once()
+		repo.On("Update", mock.Anything, mock.Anything).Return(nil).Times(3)
+		repo.On("Activate", mock.Anything, mock.Anything).Return(nil).Once()
+
+		job := waitForJob(t, handler, migrate(handler, MigrateCallbackRequest{OldCallbackURL: oldURL}))
+		assert.Equal(t, MigrationStatusCompleted, job.Status)
+		assert.Equal(t, 1, job.Successful)
+		assert.Equal(t, 1, job.Failed)
+		require.Len(t, job.Results, 2)
+		assert.False(t, job.Results[0].Success)
+		assert.NotEmpty(t, job.Results[0].Error)
+		assert.True(t, job.Results[1].Success)
+		assert.NotEmpty(t, job.Results[1].UnsubscribeError)
+
+		assert.Equal(t, models.StatusFailed, subs[0].Status)
+		assert.Equal(t, models.StatusActive, subs[1].Status)
+	})
+
+	t.Run("dry run makes no changes", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+		hubService := new(mockPubSubHubService)
+		handler := NewSubscriptionCRUDHandler(repo, hubService, "", newURL, nil)
+
+		listByStatus(repo, newSubs())
+
+		rec := migrate(handler, MigrateCallbackRequest{OldCallbackURL: oldURL, DryRun: true})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var job CallbackMigration
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+		assert.Equal(t, 2, job.Total)
+
+		hubService.AssertNotCalled(t, "Unsubscribe", mock.Anything, mock.Anything)
+		hubService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("shutdown cancels a running migration", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+		hubService := new(mockPubSubHubService)
+		handler := NewSubscriptionCRUDHandler(repo, hubService, "", newURL, nil)
+
+		listByStatus(repo, newSubs())
+		unsubscribing := make(chan struct{})
+		hubService.On("Unsubscribe", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			close(unsubscribing)
+			<-args.Get(0).(context.Context).Done()
+		}).Return(nil, context.Canceled).Once()
+		hubService.On("Subscribe", mock.Anything, mock.Anything).Return(nil, context.Canceled).Once()
+		repo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+		repo.On("Update", mock.Anything, mock.Anything).Return(context.Canceled).Once()
+
+		rec := migrate(handler, MigrateCallbackRequest{OldCallbackURL: oldURL})
+		<-unsubscribing
+		require.NoError(t, handler.Shutdown(context.Background()))
+
+		job := waitForJob(t, handler, rec)
+		assert.Equal(t, MigrationStatusCancelled, job.Status)
+		assert.Equal(t, 1, job.Processed, "no subscription is started after shutdown")
+	})
+
+	t.Run("old callback required", func(t *testing.T) {
+		handler := NewSubscriptionCRUDHandler(new(mockSubscriptionRepository), new(mockPubSubHubService), "", newURL, nil)
+
+		assert.Equal(t, http.StatusBadRequest, migrate(handler, MigrateCallbackRequest{}).Code)
+		assert.Equal(t, http.StatusBadRequest, migrate(handler, MigrateCallbackRequest{OldCallbackURL: newURL}).Code)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		handler := NewSubscriptionCRUDHandler(new(mockSubscriptionRepository), new(mockPubSubHubService), "", newURL, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/migrate-callback/missing", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -12,11 +12,13 @@ import (
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
 )
 
 // VideoHandler handles CRUD operations for videos.
 type VideoHandler struct {
 	repo   repository.VideoRepository
+	audit  auditTrail
 	logger *slog.Logger
 }
 
@@ -31,6 +33,11 @@ func NewVideoHandler(repo repository.VideoRepository, logger *slog.Logger) *Vide
 	}
 }
 
+// SetAuditLog records video creates, updates and deletes in the audit log
+func (h *VideoHandler) SetAuditLog(repo repository.AuditLogRepository) {
+	h.audit = auditTrail{repo: repo, logger: h.logger}
+}
+
 // CreateVideoRequest represents the request to create a video.
 type CreateVideoRequest struct {
 	VideoID     string `json:"video_id"`
@@ -47,6 +54,13 @@ type UpdateVideoRequest struct {
 	PublishedAt string `json:"published_at"`
 }
 
+// PatchVideoRequest represents a partial video update; omitted fields are left unchanged.
+type PatchVideoRequest struct {
+	Title       *string `json:"title"`
+	VideoURL    *string `json:"video_url"`
+	PublishedAt *string `json:"published_at"`
+}
+
 // ServeHTTP routes video requests.
 func (h *VideoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/videos")
@@ -71,6 +85,8 @@ func (h *VideoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.handleGet(w, r, videoID)
 		case http.MethodPut:
 			h.handleUpdate(w, r, videoID)
+		case http.MethodPatch:
+			h.handlePatch(w, r, videoID)
 		case http.MethodDelete:
 			h.handleDelete(w, r, videoID)
 		default:
@@ -139,6 +155,8 @@ func (h *VideoHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit.record(r, model.AuditActionCreate, auditResourceVideo, video.VideoID, nil, video)
+
 	sendJSON(w, http.StatusCreated, video)
 }
 
@@ -173,6 +191,24 @@ func (h *VideoHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	isPremiere, err := parseBool(r, "is_premiere")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	includeDeleted, err := parseBool(r, "include_deleted")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	orderBy, err := parseOrderBy(r, "published_at", videoOrderColumns)
+	if err != nil {
+		sendOrderByError(w, err, videoOrderColumns)
+		return
+	}
+
 	filters := &repository.VideoFilters{
 		Limit:           limit,
 		Offset:          offset,
@@ -180,14 +216,12 @@ func (h *VideoHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		Title:           r.URL.Query().Get("title"),
 		PublishedAfter:  publishedAfter,
 		PublishedBefore: publishedBefore,
-		OrderBy:         r.URL.Query().Get("order_by"),
+		IsPremiere:      isPremiere,
+		IncludeDeleted:  includeDeleted != nil && *includeDeleted,
+		OrderBy:         orderBy,
 		OrderDir:        getOrderDir(r),
 	}
 
-	if filters.OrderBy == "" {
-		filters.OrderBy = "published_at"
-	}
-
 	videos, total, err := h.repo.List(r.Context(), filters)
 	if err != nil {
 		h.logger.Error("failed to list videos", "error", err)
@@ -240,6 +274,8 @@ func (h *VideoHandler) handleUpdate(w http.ResponseWriter, r *http.Request, vide
 		PublishedAt: publishedAt,
 	}
 
+	before := h.auditSnapshot(r, videoID)
+
 	if err := h.repo.Update(r.Context(), video); err != nil {
 		if db.IsNotFound(err) {
 			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("video with id '%s' not found", videoID), nil)
@@ -250,11 +286,93 @@ func (h *VideoHandler) handleUpdate(w http.ResponseWriter, r *http.Request, vide
 		return
 	}
 
+	h.audit.record(r, model.AuditActionUpdate, auditResourceVideo, videoID, before, video)
+
 	sendJSON(w, http.StatusOK, video)
 }
 
+// handlePatch applies only the fields present in the request to an existing video
+func (h *VideoHandler) handlePatch(w http.ResponseWriter, r *http.Request, videoID string) {
+	var req PatchVideoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid request body", err.Error(), nil)
+		return
+	}
+
+	if req.Title != nil && *req.Title == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "title must not be empty", nil)
+		return
+	}
+
+	if req.VideoURL != nil && *req.VideoURL == "" {
+		sendError(w, http.StatusBadRequest, "validation failed", "video_url must not be empty", nil)
+		return
+	}
+
+	var publishedAt time.Time
+	if req.PublishedAt != nil {
+		var err error
+		publishedAt, err = time.Parse(time.RFC3339, *req.PublishedAt)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "validation failed", "published_at must be in RFC3339 format", nil)
+			return
+		}
+	}
+
+	existing, err := h.repo.GetVideoByID(r.Context(), videoID)
+	if err != nil {
+		if db.IsNotFound(err) {
+			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("video with id '%s' not found", videoID), nil)
+			return
+		}
+		h.logger.Error("failed to get video", "error", err, "video_id", videoID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to retrieve video", nil)
+		return
+	}
+
+	video := *existing
+	if req.Title != nil {
+		video.Title = *req.Title
+	}
+	if req.VideoURL != nil {
+		video.VideoURL = *req.VideoURL
+	}
+	if req.PublishedAt != nil {
+		video.PublishedAt = publishedAt
+	}
+
+	if err := h.repo.Update(r.Context(), &video); err != nil {
+		if db.IsNotFound(err) {
+			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("video with id '%s' not found", videoID), nil)
+			return
+		}
+		h.logger.Error("failed to update video", "error", err, "video_id", videoID)
+		sendError(w, http.StatusInternalServerError, "internal server error", "failed to update video", nil)
+		return
+	}
+
+	h.audit.record(r, model.AuditActionUpdate, auditResourceVideo, videoID, existing, &video)
+
+	sendJSON(w, http.StatusOK, &video)
+}
+
+// handleDelete soft-deletes a video by default, keeping its enrichment history and sponsor
+// relationships; ?soft=false removes it and everything that references it
 func (h *VideoHandler) handleDelete(w http.ResponseWriter, r *http.Request, videoID string) {
-	if err := h.repo.Delete(r.Context(), videoID); err != nil {
+	soft, err := parseBool(r, "soft")
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "validation failed", err.Error(), nil)
+		return
+	}
+
+	before := h.auditSnapshot(r, videoID)
+
+	if soft == nil || *soft {
+		err = h.repo.SoftDelete(r.Context(), videoID, time.Now())
+	} else {
+		err = h.repo.Delete(r.Context(), videoID)
+	}
+	if err != nil {
 		if db.IsNotFound(err) {
 			sendError(w, http.StatusNotFound, "not found", fmt.Sprintf("video with id '%s' not found", videoID), nil)
 			return
@@ -264,9 +382,29 @@ func (h *VideoHandler) handleDelete(w http.ResponseWriter, r *http.Request, vide
 		return
 	}
 
+	h.audit.record(r, model.AuditActionDelete, auditResourceVideo, videoID, before, nil)
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// auditSnapshot fetches a video's current state for the audit log before it is replaced
+// or deleted. Returns nil when auditing is disabled or the lookup fails.
+func (h *VideoHandler) auditSnapshot(r *http.Request, videoID string) *models.Video {
+	if !h.audit.enabled() {
+		return nil
+	}
+
+	video, err := h.repo.GetVideoByID(r.Context(), videoID)
+	if err != nil {
+		if !db.IsNotFound(err) {
+			h.logger.Warn("failed to get video for audit log", "error", err, "video_id", videoID)
+		}
+		return nil
+	}
+
+	return video
+}
+
 // VideoUpdateHandler handles CRUD operations for video updates.
 type VideoUpdateHandler struct {
 	repo   repository.VideoUpdateRepository
@@ -441,6 +579,12 @@ func (h *VideoUpdateHandler) handleList(w http.ResponseWriter, r *http.Request)
 		webhookEventID = id
 	}
 
+	orderBy, err := parseOrderBy(r, "created_at", videoUpdateOrderColumns)
+	if err != nil {
+		sendOrderByError(w, err, videoUpdateOrderColumns)
+		return
+	}
+
 	filters := &repository.VideoUpdateFilters{
 		Limit:          limit,
 		Offset:         offset,
@@ -448,14 +592,10 @@ func (h *VideoUpdateHandler) handleList(w http.ResponseWriter, r *http.Request)
 		ChannelID:      r.URL.Query().Get("channel_id"),
 		WebhookEventID: webhookEventID,
 		UpdateType:     r.URL.Query().Get("update_type"),
-		OrderBy:        r.URL.Query().Get("order_by"),
+		OrderBy:        orderBy,
 		OrderDir:       getOrderDir(r),
 	}
 
-	if filters.OrderBy == "" {
-		filters.OrderBy = "created_at"
-	}
-
 	updates, total, err := h.repo.List(r.Context(), filters)
 	if err != nil {
 		h.logger.Error("failed to list video updates", "error", err)
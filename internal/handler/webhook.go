@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
@@ -8,20 +9,46 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/parser"
 	"ad-tracker/youtube-webhook-ingestion/internal/service"
 )
 
 // WebhookHandler handles YouTube PubSubHubbub webhook requests.
 type WebhookHandler struct {
-	processor    service.EventProcessor
-	blockedCache *service.BlockedVideoCache
-	secret       string
-	logger       *slog.Logger
+	processor       service.EventProcessor
+	blockedCache    *service.BlockedVideoCache
+	secret          string
+	previousSecrets []string
+	logger          *slog.Logger
+
+	// subscriptionRepo, when set, stores the lease a hub grants in its verification request
+	subscriptionRepo repository.SubscriptionRepository
+
+	// Fast-ack mode: events are stored inline and their projections built by background workers
+	asyncProcessor service.AsyncEventProcessor
+	pending        chan *service.StoredEvent
+	workers        sync.WaitGroup
+
+	// pendingMu guards sends on pending against Shutdown closing it; closed is set under it
+	pendingMu sync.RWMutex
+	closed    bool
+	// stopWorkers cancels the events the workers are processing when Shutdown runs out of time
+	workerCtx   context.Context
+	stopWorkers context.CancelFunc
 }
 
+// fastAckProcessTimeout bounds background processing of a single stored event
+const fastAckProcessTimeout = 30 * time.Second
+
+// recoveryBatchSize is how many unprocessed events the fast-ack recovery sweep loads at a time
+const recoveryBatchSize = 100
+
 // NewWebhookHandler creates a new webhook handler with the given processor and secret.
 // The secret is required and used for HMAC signature verification of all webhook notifications.
 // The blockedCache is optional - if nil, no video blocking will occur.
@@ -37,6 +64,156 @@ func NewWebhookHandler(processor service.EventProcessor, blockedCache *service.B
 	}
 }
 
+// SetPreviousSecrets sets secrets that were replaced by the current one but are still
+// accepted when verifying signatures. Subscriptions keep the secret they were created
+// with until they are renewed, so old secrets must be accepted until every
+// subscription has been renewed with the current one.
+func (h *WebhookHandler) SetPreviousSecrets(secrets []string) {
+	h.previousSecrets = secrets
+}
+
+// SetSubscriptionRepository makes subscription verification requests record the lease the hub
+// granted. The hub's response to a subscribe request carries no lease; it is only sent as
+// hub.lease_seconds when the hub verifies the subscription with this callback.
+func (h *WebhookHandler) SetSubscriptionRepository(repo repository.SubscriptionRepository) {
+	h.subscriptionRepo = repo
+}
+
+// EnableFastAck switches notifications to fast-ack mode: the raw event is persisted and the
+// hub acknowledged with 202 Accepted, while projections and enrichment enqueueing run on
+// workers background goroutines. Up to queueSize stored events wait for a worker; when the
+// queue is full the event is processed inline. Events stored before this call and never
+// processed, e.g. ones queued when a previous process stopped, are recovered by a background
+// sweep, since hub redeliveries of them are discarded as duplicates. Call Shutdown to drain
+// the workers.
+func (h *WebhookHandler) EnableFastAck(processor service.AsyncEventProcessor, workers, queueSize int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	h.asyncProcessor = processor
+	h.pending = make(chan *service.StoredEvent, queueSize)
+	h.workerCtx, h.stopWorkers = context.WithCancel(context.Background())
+	startedAt := time.Now()
+
+	for i := 0; i < workers; i++ {
+		h.workers.Add(1)
+		go func() {
+			defer h.workers.Done()
+			for event := range h.pending {
+				h.processStored(h.workerCtx, event)
+			}
+		}()
+	}
+
+	h.workers.Add(1)
+	go h.recoverUnprocessed(startedAt)
+}
+
+// recoverUnprocessed processes the events received before startedAt that were never processed,
+// oldest first. It stops early on Shutdown; the remaining events are picked up on the next start.
+func (h *WebhookHandler) recoverUnprocessed(startedAt time.Time) {
+	defer h.workers.Done()
+
+	seen := make(map[int64]bool)
+	recovered := 0
+	defer func() {
+		if recovered > 0 {
+			h.logger.Info("recovered unprocessed webhook events", "events", recovered)
+		}
+	}()
+
+	for {
+		if h.isClosed() {
+			return
+		}
+
+		events, more, err := h.asyncProcessor.UnprocessedEvents(h.workerCtx, recoveryBatchSize)
+		if err != nil {
+			h.logger.Error("failed to load unprocessed webhook events", "error", err)
+			return
+		}
+
+		progressed := len(events) == 0
+		for _, event := range events {
+			// Later events belong to this process and are already queued for the workers
+			if !event.Event.ReceivedAt.Before(startedAt) {
+				return
+			}
+			// An event whose processing could not be recorded comes back; skip it this run
+			if seen[event.Event.ID] {
+				continue
+			}
+			if h.isClosed() {
+				return
+			}
+
+			seen[event.Event.ID] = true
+			progressed = true
+			h.processStored(h.workerCtx, event)
+			recovered++
+		}
+
+		if !more || !progressed {
+			return
+		}
+	}
+}
+
+// isClosed reports whether Shutdown has started.
+func (h *WebhookHandler) isClosed() bool {
+	h.pendingMu.RLock()
+	defer h.pendingMu.RUnlock()
+	return h.closed
+}
+
+// Shutdown stops the fast-ack workers after they finish the queued events. Notifications
+// still arriving afterwards are processed inline. If ctx ends first, the events being
+// processed are cancelled and Shutdown waits for the workers to return before reporting
+// ctx's error, so no worker outlives it.
+func (h *WebhookHandler) Shutdown(ctx context.Context) error {
+	if h.pending == nil {
+		return nil
+	}
+
+	h.pendingMu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.pending)
+	}
+	h.pendingMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		h.stopWorkers()
+		return nil
+	case <-ctx.Done():
+		h.stopWorkers()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// processStored builds projections for an event stored in fast-ack mode. Failures are
+// recorded on the event by the processor, and the event stays in webhook_events either way.
+func (h *WebhookHandler) processStored(parent context.Context, event *service.StoredEvent) {
+	ctx, cancel := context.WithTimeout(parent, fastAckProcessTimeout)
+	defer cancel()
+
+	if err := h.asyncProcessor.ProcessStoredEvent(ctx, event); err != nil {
+		h.logger.Error("failed to process stored event", "error", err, "event_id", event.Event.ID)
+	}
+}
+
 // ServeHTTP handles both subscription verification (GET) and notification (POST) requests.
 func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -66,12 +243,53 @@ func (h *WebhookHandler) handleVerification(w http.ResponseWriter, r *http.Reque
 		"hub.lease_seconds", r.URL.Query().Get("hub.lease_seconds"),
 	)
 
+	if r.URL.Query().Get("hub.mode") == "subscribe" {
+		h.confirmLease(r)
+	}
+
 	// Return the challenge to confirm subscription
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(challenge))
 }
 
+// confirmLease stores the lease granted in a subscribe verification request. The request is
+// unauthenticated, so the repository only applies it to subscriptions with a recently sent
+// subscribe request. Failures are logged without failing the verification, since the
+// subscription itself is still wanted.
+func (h *WebhookHandler) confirmLease(r *http.Request) {
+	if h.subscriptionRepo == nil {
+		return
+	}
+
+	topic := r.URL.Query().Get("hub.topic")
+	raw := r.URL.Query().Get("hub.lease_seconds")
+	leaseSeconds, err := strconv.Atoi(strings.TrimSpace(raw))
+	if topic == "" || err != nil || leaseSeconds <= 0 {
+		h.logger.Warn("verification request without a valid lease, keeping the requested lease",
+			"hub.topic", topic,
+			"hub.lease_seconds", raw,
+		)
+		return
+	}
+
+	updated, err := h.subscriptionRepo.ConfirmLease(r.Context(), topic, leaseSeconds)
+	if err != nil {
+		h.logger.Error("failed to store confirmed subscription lease", "error", err, "hub.topic", topic)
+		return
+	}
+	if updated == 0 {
+		h.logger.Warn("verification request without a pending subscribe request, ignoring the lease", "hub.topic", topic)
+		return
+	}
+
+	h.logger.Info("subscription lease confirmed",
+		"hub.topic", topic,
+		"lease_seconds", leaseSeconds,
+		"subscriptions", updated,
+	)
+}
+
 // handleNotification handles POST requests containing Atom feed notifications.
 func (h *WebhookHandler) handleNotification(w http.ResponseWriter, r *http.Request) {
 	// Read the request body
@@ -90,6 +308,13 @@ func (h *WebhookHandler) handleNotification(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Test notifications from the webhook-test endpoint only check routing and the signature
+	if r.Header.Get(webhookTestHeader) != "" {
+		h.logger.Info("received webhook test notification")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	// Check if video is blocked (if cache is available)
 	if h.blockedCache != nil {
 		videoData, err := parser.ParseAtomFeed(string(body))
@@ -116,6 +341,11 @@ func (h *WebhookHandler) handleNotification(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	if h.asyncProcessor != nil {
+		h.acknowledgeAndQueue(w, r, string(body))
+		return
+	}
+
 	// Process the event
 	if err := h.processor.ProcessEvent(r.Context(), string(body)); err != nil {
 		h.logger.Error("failed to process event", "error", err)
@@ -127,8 +357,52 @@ func (h *WebhookHandler) handleNotification(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusOK)
 }
 
+// acknowledgeAndQueue persists the event and hands it to the fast-ack workers.
+// Only a failure to store the event is reported to the hub, so it retries the delivery.
+func (h *WebhookHandler) acknowledgeAndQueue(w http.ResponseWriter, r *http.Request, rawXML string) {
+	event, err := h.asyncProcessor.StoreEvent(r.Context(), rawXML)
+	if err != nil {
+		h.logger.Error("failed to store event", "error", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	// Duplicates and deleted-video notifications need no further processing
+	if event == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if !h.enqueueStored(event) {
+		h.logger.Warn("fast-ack queue full or stopped, processing inline", "event_id", event.Event.ID)
+		h.processStored(context.Background(), event)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// enqueueStored hands a stored event to the fast-ack workers without blocking. It reports
+// false when the queue is full or Shutdown has closed it.
+func (h *WebhookHandler) enqueueStored(event *service.StoredEvent) bool {
+	h.pendingMu.RLock()
+	defer h.pendingMu.RUnlock()
+
+	if h.closed {
+		return false
+	}
+
+	select {
+	case h.pending <- event:
+		h.logger.Info("stored webhook notification, processing asynchronously", "event_id", event.Event.ID)
+		return true
+	default:
+		return false
+	}
+}
+
 // verifySignature verifies the X-Hub-Signature header using HMAC-SHA1.
 // The signature format is "sha1={hex-encoded-signature}".
+// The signature may be made with the current secret or any previous secret.
 func (h *WebhookHandler) verifySignature(r *http.Request, body []byte) error {
 	signature := r.Header.Get("X-Hub-Signature")
 	if signature == "" {
@@ -141,15 +415,33 @@ func (h *WebhookHandler) verifySignature(r *http.Request, body []byte) error {
 	}
 	expectedSig := strings.TrimPrefix(signature, "sha1=")
 
-	// Compute HMAC-SHA1 of the body
-	mac := hmac.New(sha1.New, []byte(h.secret))
-	mac.Write(body)
-	computedSig := hex.EncodeToString(mac.Sum(nil))
+	if signatureMatches(h.secret, body, expectedSig) {
+		return nil
+	}
 
-	// Compare signatures using constant-time comparison
-	if !hmac.Equal([]byte(computedSig), []byte(expectedSig)) {
-		return fmt.Errorf("signature mismatch")
+	for i, secret := range h.previousSecrets {
+		if signatureMatches(secret, body, expectedSig) {
+			h.logger.Info("notification signed with a previous webhook secret",
+				"previous_secret_index", i,
+			)
+			return nil
+		}
 	}
 
-	return nil
+	return fmt.Errorf("signature mismatch")
+}
+
+// signatureMatches reports whether expectedSig is the hex HMAC-SHA1 of body under secret.
+func signatureMatches(secret string, body []byte, expectedSig string) bool {
+	computedSig := computeSignature(secret, body)
+
+	// Compare signatures using constant-time comparison
+	return hmac.Equal([]byte(computedSig), []byte(expectedSig))
+}
+
+// computeSignature returns the hex HMAC-SHA1 of body under secret.
+func computeSignature(secret string, body []byte) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
 }
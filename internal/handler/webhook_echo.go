@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// webhookTestHeader marks a notification sent by the webhook-test endpoint. The webhook
+// handler acknowledges such notifications once the signature is verified, without storing
+// or processing them.
+const webhookTestHeader = "X-Webhook-Test"
+
+// webhookTestTimeout bounds the round trip to the callback URL
+const webhookTestTimeout = 10 * time.Second
+
+// maxWebhookTestResponseBody caps how much of the callback response is included in the report
+const maxWebhookTestResponseBody = 1024
+
+// webhookTestFeed is the sample notification posted to the callback URL
+const webhookTestFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <id>yt:video:webhooktest</id>
+    <yt:videoId>webhooktest</yt:videoId>
+    <yt:channelId>UCwebhooktest</yt:channelId>
+    <title>Webhook test notification</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=webhooktest"/>
+    <published>%[1]s</published>
+    <updated>%[1]s</updated>
+  </entry>
+</feed>`
+
+// WebhookTestResult reports the round trip of a signed test notification to the callback URL.
+type WebhookTestResult struct {
+	CallbackURL string `json:"callback_url"`
+	// Reachable is true when the callback URL returned any HTTP response
+	Reachable  bool  `json:"reachable"`
+	StatusCode int   `json:"status_code,omitempty"`
+	DurationMs int64 `json:"duration_ms"`
+	// SignatureValid is true for a 2xx response and false for 401 Unauthorized;
+	// any other outcome leaves it unknown (null)
+	SignatureValid *bool  `json:"signature_valid"`
+	ResponseBody   string `json:"response_body,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// WebhookTestHandler sends a signed sample notification to the configured callback URL so
+// operators can check that the public URL routes to this server and passes signature checks.
+type WebhookTestHandler struct {
+	secret      string
+	callbackURL string
+	client      *http.Client
+	logger      *slog.Logger
+}
+
+// NewWebhookTestHandler creates a webhook test handler. client may be nil to use a default client.
+func NewWebhookTestHandler(secret, callbackURL string, client *http.Client, logger *slog.Logger) *WebhookTestHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if client == nil {
+		client = &http.Client{Timeout: webhookTestTimeout}
+	}
+	return &WebhookTestHandler{
+		secret:      secret,
+		callbackURL: callbackURL,
+		client:      client,
+		logger:      logger,
+	}
+}
+
+// ServeHTTP handles POST /api/v1/webhook-test
+func (h *WebhookTestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed", "only POST is supported", nil)
+		return
+	}
+
+	result := h.sendTestNotification(r.Context())
+	h.logger.Info("webhook test completed",
+		"callback_url", result.CallbackURL,
+		"reachable", result.Reachable,
+		"status_code", result.StatusCode,
+		"duration_ms", result.DurationMs,
+	)
+
+	sendJSON(w, http.StatusOK, result)
+}
+
+// sendTestNotification posts a sample Atom notification signed with the current secret
+func (h *WebhookTestHandler) sendTestNotification(ctx context.Context) *WebhookTestResult {
+	result := &WebhookTestResult{CallbackURL: h.callbackURL}
+
+	body := []byte(fmt.Sprintf(webhookTestFeed, time.Now().UTC().Format(time.RFC3339)))
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid callback URL: %v", err)
+		return result
+	}
+	req.Header.Set("Content-Type", "application/atom+xml")
+	req.Header.Set("X-Hub-Signature", "sha1="+computeSignature(h.secret, body))
+	req.Header.Set(webhookTestHeader, "1")
+
+	start := time.Now()
+	resp, err := h.client.Do(req)
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.Reachable = true
+	result.StatusCode = resp.StatusCode
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookTestResponseBody))
+	result.ResponseBody = string(respBody)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		valid := true
+		result.SignatureValid = &valid
+	case resp.StatusCode == http.StatusUnauthorized:
+		valid := false
+		result.SignatureValid = &valid
+		result.Error = "callback rejected the signature; check that WEBHOOK_SECRET matches the receiving server"
+	default:
+		result.Error = fmt.Sprintf("callback returned unexpected status %d", resp.StatusCode)
+	}
+
+	return result
+}
@@ -5,13 +5,18 @@ import (
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/queue"
+	"ad-tracker/youtube-webhook-ingestion/internal/service"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -63,6 +68,59 @@ func TestWebhookHandler_HandleVerification_Success(t *testing.T) {
 	assert.Equal(t, challenge, rec.Body.String())
 }
 
+func TestWebhookHandler_HandleVerification_ConfirmsLease(t *testing.T) {
+	t.Parallel()
+
+	topic := "https://www.youtube.com/xml/feeds/videos.xml?channel_id=UCtest123"
+	verify := func(t *testing.T, repo *mockSubscriptionRepository, query url.Values) *httptest.ResponseRecorder {
+		handler := NewWebhookHandler(new(mockProcessor), nil, "", nil)
+		handler.SetSubscriptionRepository(repo)
+
+		query.Set("hub.challenge", "challenge-123")
+		query.Set("hub.topic", topic)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook?"+query.Encode(), nil))
+		return rec
+	}
+
+	t.Run("subscribe stores the granted lease", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+		repo.On("ConfirmLease", mock.Anything, topic, 86400).Return(int64(1), nil)
+
+		rec := verify(t, repo, url.Values{"hub.mode": {"subscribe"}, "hub.lease_seconds": {"86400"}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "challenge-123", rec.Body.String())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("storage failure still verifies", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+		repo.On("ConfirmLease", mock.Anything, topic, 86400).Return(int64(0), errors.New("connection refused"))
+
+		rec := verify(t, repo, url.Values{"hub.mode": {"subscribe"}, "hub.lease_seconds": {"86400"}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "challenge-123", rec.Body.String())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unsubscribe and invalid leases are not stored", func(t *testing.T) {
+		repo := new(mockSubscriptionRepository)
+
+		for _, query := range []url.Values{
+			{"hub.mode": {"unsubscribe"}, "hub.lease_seconds": {"86400"}},
+			{"hub.mode": {"subscribe"}},
+			{"hub.mode": {"subscribe"}, "hub.lease_seconds": {"soon"}},
+			{"hub.mode": {"subscribe"}, "hub.lease_seconds": {"0"}},
+		} {
+			rec := verify(t, repo, query)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+		repo.AssertNotCalled(t, "ConfirmLease", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestWebhookHandler_HandleVerification_MissingChallenge(t *testing.T) {
 	t.Parallel()
 
@@ -334,3 +392,397 @@ func TestWebhookHandler_VerifySignature(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhookHandler_HandleNotification_SecretRotation(t *testing.T) {
+	t.Parallel()
+
+	atomXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>test123</yt:videoId>
+    <yt:channelId>UCtest</yt:channelId>
+    <title>Test Video</title>
+  </entry>
+</feed>`
+
+	sign := func(secret string) string {
+		mac := hmac.New(sha1.New, []byte(secret))
+		mac.Write([]byte(atomXML))
+		return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name       string
+		signature  string
+		wantStatus int
+	}{
+		{
+			name:       "signed with the current secret",
+			signature:  sign("new-secret"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "signed with a previous secret during rotation",
+			signature:  sign("old-secret"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "signed with an older previous secret",
+			signature:  sign("oldest-secret"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "signed with an unknown secret",
+			signature:  sign("bogus-secret"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "bogus signature",
+			signature:  "sha1=0000000000000000000000000000000000000000",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			processor := new(mockProcessor)
+			processor.On("ProcessEvent", mock.Anything, atomXML).Return(nil)
+
+			handler := NewWebhookHandler(processor, nil, "new-secret", nil)
+			handler.SetPreviousSecrets([]string{"old-secret", "oldest-secret"})
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(atomXML))
+			req.Header.Set("X-Hub-Signature", tt.signature)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				processor.AssertCalled(t, "ProcessEvent", mock.Anything, atomXML)
+			} else {
+				processor.AssertNotCalled(t, "ProcessEvent", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_HandleNotification_IngestOnly(t *testing.T) {
+	t.Parallel()
+
+	eventRepo := newMockWebhookEventRepo()
+	jobRepo := newMockEnrichmentJobRepo()
+
+	processor := service.NewIngestOnlyProcessor(eventRepo)
+
+	// A configured queue client must still never be used in ingest-only mode
+	queueClient, err := queue.NewClient("redis://127.0.0.1:1", jobRepo)
+	require.NoError(t, err)
+	defer queueClient.Close()
+	processor.SetQueueClient(queueClient)
+
+	secret := "test-secret"
+	handler := NewWebhookHandler(processor, nil, secret, nil)
+
+	atomXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>ingest123</yt:videoId>
+    <yt:channelId>UCingest</yt:channelId>
+    <title>Archived Video</title>
+    <published>2025-01-15T10:00:00+00:00</published>
+    <updated>2025-01-15T11:00:00+00:00</updated>
+  </entry>
+</feed>`
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(atomXML))
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(atomXML))
+	req.Header.Set("X-Hub-Signature", signature)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	require.Len(t, eventRepo.events, 1)
+	for _, event := range eventRepo.events {
+		assert.Equal(t, atomXML, event.RawXML)
+		assert.Equal(t, "ingest123", event.VideoID.String)
+		assert.Equal(t, "UCingest", event.ChannelID.String)
+		assert.False(t, event.Processed, "event should be left for batch processing")
+	}
+
+	assert.Empty(t, jobRepo.jobs, "no enrichment job should be enqueued")
+}
+
+// blockingAsyncProcessor stores events synchronously and blocks background processing until released
+type blockingAsyncProcessor struct {
+	mockProcessor
+	eventRepo   *mockWebhookEventRepo
+	release     chan struct{}
+	processed   chan int64
+	unprocessed []*service.StoredEvent
+}
+
+func (p *blockingAsyncProcessor) StoreEvent(ctx context.Context, rawXML string) (*service.StoredEvent, error) {
+	event, err := p.eventRepo.CreateWebhookEvent(ctx, rawXML, "fastack123", "UCfastack")
+	if err != nil {
+		return nil, err
+	}
+	return &service.StoredEvent{Event: event}, nil
+}
+
+func (p *blockingAsyncProcessor) ProcessStoredEvent(ctx context.Context, stored *service.StoredEvent) error {
+	<-p.release
+	p.processed <- stored.Event.ID
+	return nil
+}
+
+// UnprocessedEvents returns the events in unprocessed once, as the repository would after
+// they have been processed
+func (p *blockingAsyncProcessor) UnprocessedEvents(ctx context.Context, limit int) ([]*service.StoredEvent, bool, error) {
+	events := p.unprocessed
+	p.unprocessed = nil
+	return events, false, nil
+}
+
+func TestWebhookHandler_HandleNotification_FastAck(t *testing.T) {
+	t.Parallel()
+
+	processor := &blockingAsyncProcessor{
+		eventRepo: newMockWebhookEventRepo(),
+		release:   make(chan struct{}),
+		processed: make(chan int64, 1),
+	}
+
+	secret := "test-secret"
+	handler := NewWebhookHandler(processor, nil, secret, nil)
+	handler.EnableFastAck(processor, 1, 10)
+
+	atomXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>fastack123</yt:videoId>
+    <yt:channelId>UCfastack</yt:channelId>
+    <title>Fast Ack Video</title>
+    <published>2025-01-15T10:00:00+00:00</published>
+    <updated>2025-01-15T11:00:00+00:00</updated>
+  </entry>
+</feed>`
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(atomXML))
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(atomXML))
+	req.Header.Set("X-Hub-Signature", signature)
+	rec := httptest.NewRecorder()
+
+	// Processing is still blocked, so the response must not wait for it
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	require.Len(t, processor.eventRepo.events, 1)
+	var eventID int64
+	for id, event := range processor.eventRepo.events {
+		eventID = id
+		assert.Equal(t, atomXML, event.RawXML)
+		assert.False(t, event.Processed, "event should be stored before processing completes")
+	}
+
+	select {
+	case <-processor.processed:
+		t.Fatal("event processed before being released")
+	default:
+	}
+
+	close(processor.release)
+	require.NoError(t, handler.Shutdown(context.Background()))
+
+	select {
+	case id := <-processor.processed:
+		assert.Equal(t, eventID, id)
+	default:
+		t.Fatal("stored event was not processed by the fast-ack worker")
+	}
+
+	// A notification arriving after Shutdown is processed inline instead of being sent on the closed queue
+	processor.eventRepo = newMockWebhookEventRepo()
+	req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(atomXML))
+	req.Header.Set("X-Hub-Signature", signature)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	select {
+	case <-processor.processed:
+	default:
+		t.Fatal("notification after shutdown was not processed inline")
+	}
+}
+
+func TestWebhookHandler_FastAck_RecoversUnprocessedEvents(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	processor := &blockingAsyncProcessor{
+		eventRepo: newMockWebhookEventRepo(),
+		release:   make(chan struct{}),
+		processed: make(chan int64, 3),
+		unprocessed: []*service.StoredEvent{
+			{Event: &models.WebhookEvent{ID: 1, ReceivedAt: now.Add(-time.Hour)}},
+			{Event: &models.WebhookEvent{ID: 2, ReceivedAt: now.Add(-time.Minute)}},
+			// Received after fast-ack started, so already queued for the workers
+			{Event: &models.WebhookEvent{ID: 3, ReceivedAt: now.Add(time.Hour)}},
+		},
+	}
+	close(processor.release)
+
+	handler := NewWebhookHandler(processor, nil, "test-secret", nil)
+	handler.EnableFastAck(processor, 1, 10)
+
+	var recovered []int64
+	for range 2 {
+		select {
+		case id := <-processor.processed:
+			recovered = append(recovered, id)
+		case <-time.After(time.Second):
+			t.Fatal("unprocessed event was not recovered")
+		}
+	}
+	require.NoError(t, handler.Shutdown(context.Background()))
+
+	assert.Equal(t, []int64{1, 2}, recovered)
+	assert.Empty(t, processor.processed, "events received after startup are left to the workers")
+}
+
+// cancellableAsyncProcessor blocks processing until its context is cancelled
+type cancellableAsyncProcessor struct {
+	blockingAsyncProcessor
+	started chan struct{}
+}
+
+func (p *cancellableAsyncProcessor) ProcessStoredEvent(ctx context.Context, stored *service.StoredEvent) error {
+	p.started <- struct{}{}
+	<-ctx.Done()
+	p.processed <- stored.Event.ID
+	return ctx.Err()
+}
+
+func TestWebhookHandler_Shutdown_HonoursContext(t *testing.T) {
+	t.Parallel()
+
+	processor := &cancellableAsyncProcessor{
+		blockingAsyncProcessor: blockingAsyncProcessor{eventRepo: newMockWebhookEventRepo(), processed: make(chan int64, 2)},
+		started:                make(chan struct{}, 2),
+	}
+
+	secret := "test-secret"
+	handler := NewWebhookHandler(processor, nil, secret, nil)
+	handler.EnableFastAck(processor, 1, 10)
+
+	atomXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>fastack123</yt:videoId>
+    <yt:channelId>UCfastack</yt:channelId>
+    <title>Fast Ack Video</title>
+    <published>2025-01-15T10:00:00+00:00</published>
+    <updated>2025-01-15T11:00:00+00:00</updated>
+  </entry>
+</feed>`
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(atomXML))
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(atomXML))
+	req.Header.Set("X-Hub-Signature", signature)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	<-processor.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, handler.Shutdown(ctx), context.DeadlineExceeded)
+
+	// The worker was cancelled and had returned by the time Shutdown did
+	select {
+	case <-processor.processed:
+	default:
+		t.Fatal("Shutdown returned before the worker stopped")
+	}
+}
+
+func TestWebhookTestHandler_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	secret := "test-secret"
+
+	run := func(t *testing.T, receiver http.Handler) WebhookTestResult {
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		handler := NewWebhookTestHandler(secret, server.URL+"/webhook", nil, nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhook-test", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var result WebhookTestResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, server.URL+"/webhook", result.CallbackURL)
+		return result
+	}
+
+	t.Run("receiver with matching secret", func(t *testing.T) {
+		// The test notification must be acknowledged without being processed
+		processor := new(mockProcessor)
+		result := run(t, NewWebhookHandler(processor, nil, secret, nil))
+
+		assert.True(t, result.Reachable)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		require.NotNil(t, result.SignatureValid)
+		assert.True(t, *result.SignatureValid)
+		assert.Empty(t, result.Error)
+		processor.AssertNotCalled(t, "ProcessEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("receiver with a different secret", func(t *testing.T) {
+		result := run(t, NewWebhookHandler(new(mockProcessor), nil, "other-secret", nil))
+
+		assert.True(t, result.Reachable)
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+		require.NotNil(t, result.SignatureValid)
+		assert.False(t, *result.SignatureValid)
+		assert.NotEmpty(t, result.Error)
+	})
+
+	t.Run("callback not routed to a webhook handler", func(t *testing.T) {
+		result := run(t, http.NotFoundHandler())
+
+		assert.True(t, result.Reachable)
+		assert.Equal(t, http.StatusNotFound, result.StatusCode)
+		assert.Nil(t, result.SignatureValid)
+		assert.Contains(t, result.Error, "404")
+	})
+
+	t.Run("unreachable callback", func(t *testing.T) {
+		handler := NewWebhookTestHandler(secret, "http://127.0.0.1:1/webhook", nil, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/webhook-test", nil))
+
+		var result WebhookTestResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.False(t, result.Reachable)
+		assert.Nil(t, result.SignatureValid)
+		assert.NotEmpty(t, result.Error)
+	})
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -15,6 +18,24 @@ const (
 	unauthorizedError = "Unauthorized"
 )
 
+type contextKey int
+
+const apiKeyIDKey contextKey = iota
+
+// APIKeyID returns a stable, non-secret identifier for an API key: the first 12 hex
+// characters of its SHA-256 hash. Suitable for logs and audit records.
+func APIKeyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// APIKeyIDFromContext returns the ID of the API key that authenticated the request,
+// or "" if the request did not pass through the API key middleware.
+func APIKeyIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(apiKeyIDKey).(string)
+	return id
+}
+
 // APIKeyAuth provides API key authentication middleware.
 type APIKeyAuth struct {
 	apiKeys map[string]bool
@@ -65,8 +86,9 @@ func (a *APIKeyAuth) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// API key is valid, continue to next handler
-		next.ServeHTTP(w, r)
+		// API key is valid, continue to next handler with its ID in the context
+		ctx := context.WithValue(r.Context(), apiKeyIDKey, APIKeyID(apiKey))
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
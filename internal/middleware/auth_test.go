@@ -127,6 +127,26 @@ func TestAPIKeyAuth_Middleware_Success(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuth_Middleware_SetsAPIKeyID(t *testing.T) {
+	t.Parallel()
+
+	auth := NewAPIKeyAuth([]string{"valid-key-123"}, nil)
+
+	var keyID string
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID = APIKeyIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(headerAPIKey, "valid-key-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, APIKeyID("valid-key-123"), keyID)
+	assert.Len(t, keyID, 12)
+	assert.NotContains(t, keyID, "valid")
+	assert.Empty(t, APIKeyIDFromContext(req.Context()))
+}
+
 func TestAPIKeyAuth_Middleware_Unauthorized(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Gzip compresses JSON responses for clients that accept gzip.
+// Responses smaller than the minimum size are sent as is, since the gzip
+// framing outweighs the savings on small bodies.
+type Gzip struct {
+	minSize int
+	logger  *slog.Logger
+}
+
+// NewGzip creates a new gzip compression middleware.
+// A minimum size of zero or less disables the middleware.
+func NewGzip(minSize int, logger *slog.Logger) *Gzip {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Gzip{
+		minSize: minSize,
+		logger:  logger,
+	}
+}
+
+// Middleware returns an HTTP middleware that gzip-encodes JSON responses of at
+// least the minimum size when the request's Accept-Encoding allows gzip.
+//
+// The response is buffered until the handler returns so its size is known
+// before choosing an encoding. A handler that flushes streams its response
+// uncompressed, since the size cannot be known in advance.
+func (g *Gzip) Middleware(next http.Handler) http.Handler {
+	if g.minSize <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bw := &bufferedResponseWriter{dst: w, header: make(http.Header)}
+		next.ServeHTTP(bw, r)
+
+		if bw.flushed || !g.shouldCompress(bw) {
+			bw.flushTo(w)
+			return
+		}
+
+		for key, values := range bw.header {
+			w.Header()[key] = values
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+
+		statusCode := bw.statusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+		w.WriteHeader(statusCode)
+
+		gz := gzip.NewWriter(w)
+		if _, err := gz.Write(bw.body.Bytes()); err != nil {
+			g.logger.Error("failed to write gzip response", "path", r.URL.Path, "error", err)
+			return
+		}
+		if err := gz.Close(); err != nil {
+			g.logger.Error("failed to finish gzip response", "path", r.URL.Path, "error", err)
+		}
+	})
+}
+
+// shouldCompress reports whether a buffered response is a JSON body large
+// enough to compress that has not already been encoded.
+func (g *Gzip) shouldCompress(bw *bufferedResponseWriter) bool {
+	if bw.body.Len() < g.minSize || bw.header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(bw.header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip,
+// honouring an explicit q=0 rejection.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			return true
+		}
+	}
+	return false
+}
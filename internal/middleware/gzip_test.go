@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeListHandler mirrors a list endpoint returning many items
+func largeListHandler(items int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		videos := make([]map[string]string, items)
+		for i := range videos {
+			videos[i] = map[string]string{"video_id": fmt.Sprintf("video%06d", i), "title": "A sample video title"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": videos, "total": items})
+	})
+}
+
+func TestGzip(t *testing.T) {
+	t.Parallel()
+
+	t.Run("large list response is gzip-encoded when accepted", func(t *testing.T) {
+		t.Parallel()
+
+		handler := NewGzip(1024, nil).Middleware(largeListHandler(500))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/videos", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &response))
+		assert.Equal(t, float64(500), response["total"])
+	})
+
+	t.Run("large list response is plain when gzip is not accepted", func(t *testing.T) {
+		t.Parallel()
+
+		handler := NewGzip(1024, nil).Middleware(largeListHandler(500))
+
+		for _, acceptEncoding := range []string{"", "deflate", "gzip;q=0"} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/videos", nil)
+			if acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code, acceptEncoding)
+			assert.Empty(t, rec.Header().Get("Content-Encoding"), acceptEncoding)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response), acceptEncoding)
+			assert.Equal(t, float64(500), response["total"])
+		}
+	})
+
+	t.Run("small response is not compressed", func(t *testing.T) {
+		t.Parallel()
+
+		handler := NewGzip(1024, nil).Middleware(largeListHandler(1))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/videos", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Contains(t, rec.Body.String(), "video000000")
+	})
+
+	t.Run("non-JSON response is not compressed", func(t *testing.T) {
+		t.Parallel()
+
+		handler := NewGzip(16, nil).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("plain text that is longer than the threshold"))
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "plain text that is longer than the threshold", rec.Body.String())
+	})
+}
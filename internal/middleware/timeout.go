@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const timeoutError = "timeout"
+
+// RequestTimeout bounds how long a request's database operations may run.
+// Handlers pass r.Context() to repositories, so the deadline set here cancels
+// queries that are still running when it passes, and a client disconnect
+// cancels them as well.
+type RequestTimeout struct {
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewRequestTimeout creates a new request timeout middleware.
+// A timeout of zero or less disables the middleware.
+func NewRequestTimeout(timeout time.Duration, logger *slog.Logger) *RequestTimeout {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &RequestTimeout{
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Middleware returns an HTTP middleware that attaches a deadline to the request context.
+//
+// The response is buffered until the handler returns. If the deadline passed and
+// the handler responded with a server error (typically the cancelled query's error),
+// the response is replaced with 504 Gateway Timeout so clients can tell a slow
+// operation from a failure. A handler that calls Flush streams its response
+// instead, and once the headers are sent it can no longer be replaced.
+func (t *RequestTimeout) Middleware(next http.Handler) http.Handler {
+	if t.timeout <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), t.timeout)
+		defer cancel()
+
+		bw := &bufferedResponseWriter{dst: w, header: make(http.Header)}
+		next.ServeHTTP(bw, r.WithContext(ctx))
+
+		if bw.flushed {
+			// The handler streamed its response, so the status is already on the wire
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				t.logger.Warn("streamed request timed out",
+					"path", r.URL.Path,
+					"method", r.Method,
+					"timeout", t.timeout.String(),
+				)
+			}
+			return
+		}
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && (bw.statusCode == 0 || bw.statusCode >= http.StatusInternalServerError) {
+			t.logger.Warn("request timed out",
+				"path", r.URL.Path,
+				"method", r.Method,
+				"timeout", t.timeout.String(),
+				"handler_status", bw.statusCode,
+			)
+			t.sendTimeout(w)
+			return
+		}
+
+		bw.flushTo(w)
+	})
+}
+
+// sendTimeout sends a 504 Gateway Timeout response.
+func (t *RequestTimeout) sendTimeout(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusGatewayTimeout)
+
+	response := map[string]string{
+		"error":   timeoutError,
+		"message": "The request did not complete within " + t.timeout.String() + "; try again or narrow the query",
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		t.logger.Error("failed to encode timeout response", "error", err)
+	}
+}
+
+// bufferedResponseWriter holds a response until the handler has returned, or
+// until the handler calls Flush. The first Flush commits the status and headers
+// to dst and every later write goes straight through, so streaming handlers keep
+// streaming behind the middleware.
+type bufferedResponseWriter struct {
+	dst        http.ResponseWriter
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	flushed    bool
+}
+
+func (bw *bufferedResponseWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedResponseWriter) WriteHeader(code int) {
+	if bw.statusCode == 0 {
+		bw.statusCode = code
+	}
+}
+
+func (bw *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if bw.statusCode == 0 {
+		bw.statusCode = http.StatusOK
+	}
+	if bw.flushed {
+		return bw.dst.Write(p)
+	}
+	return bw.body.Write(p)
+}
+
+// Flush commits the response to dst and flushes it to the client.
+func (bw *bufferedResponseWriter) Flush() {
+	if !bw.flushed {
+		bw.flushTo(bw.dst)
+		bw.body.Reset()
+		bw.flushed = true
+	}
+	if flusher, ok := bw.dst.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the destination writer for http.ResponseController.
+func (bw *bufferedResponseWriter) Unwrap() http.ResponseWriter {
+	return bw.dst
+}
+
+// flushTo writes the buffered response to w.
+func (bw *bufferedResponseWriter) flushTo(w http.ResponseWriter) {
+	if bw.flushed {
+		return
+	}
+
+	for key, values := range bw.header {
+		w.Header()[key] = values
+	}
+
+	statusCode := bw.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+	w.Write(bw.body.Bytes())
+}
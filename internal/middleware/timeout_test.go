@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowRepo simulates a repository query that runs until its context is cancelled
+type slowRepo struct {
+	delay     time.Duration
+	cancelled bool
+}
+
+func (s *slowRepo) List(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		s.cancelled = true
+		return ctx.Err()
+	}
+}
+
+// listHandler mirrors the repo handlers: repository errors become 500 responses
+func listHandler(repo *slowRepo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := repo.List(r.Context()); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal_error", "message": err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	})
+}
+
+func TestRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("slow repository call is cancelled and returns 504", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &slowRepo{delay: 5 * time.Second}
+		handler := NewRequestTimeout(20*time.Millisecond, nil).Middleware(listHandler(repo))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/videos", nil)
+		rec := httptest.NewRecorder()
+
+		start := time.Now()
+		handler.ServeHTTP(rec, req)
+
+		assert.Less(t, time.Since(start), time.Second, "slow call should be cut off at the timeout")
+		assert.True(t, repo.cancelled, "repository context should be cancelled")
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+
+		var response map[string]string
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, timeoutError, response["error"])
+		assert.Contains(t, response["message"], "20ms")
+	})
+
+	t.Run("fast request passes through unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &slowRepo{delay: 0}
+		handler := NewRequestTimeout(time.Second, nil).Middleware(listHandler(repo))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/videos", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.False(t, repo.cancelled)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"status": "ok"}`, rec.Body.String())
+	})
+
+	t.Run("non-server-error response after deadline is kept", func(t *testing.T) {
+		t.Parallel()
+
+		handler := NewRequestTimeout(10*time.Millisecond, nil).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			w.WriteHeader(http.StatusNotFound)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/videos/missing", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("flush streams the response and commits the headers", func(t *testing.T) {
+		t.Parallel()
+
+		var streamedBeforeReturn string
+		rec := httptest.NewRecorder()
+		handler := NewRequestTimeout(10*time.Millisecond, nil).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("a,b\n"))
+			w.(http.Flusher).Flush()
+			streamedBeforeReturn = rec.Body.String()
+
+			<-r.Context().Done()
+			w.Write([]byte("1,2\n"))
+		}))
+
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/report", nil))
+
+		assert.Equal(t, "a,b\n", streamedBeforeReturn)
+		assert.True(t, rec.Flushed)
+		assert.Equal(t, http.StatusOK, rec.Code, "a streamed response is not replaced after the deadline")
+		assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "a,b\n1,2\n", rec.Body.String())
+	})
+
+	t.Run("zero timeout disables the middleware", func(t *testing.T) {
+		t.Parallel()
+
+		var hasDeadline bool
+		handler := NewRequestTimeout(0, nil).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, hasDeadline = r.Context().Deadline()
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.False(t, hasDeadline)
+	})
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit log actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+
+	// AuditActionRenew records a subscription renewed by POST /subscriptions/renew-all
+	AuditActionRenew = "renew"
+	// AuditActionMigrateCallback records a subscription moved to a new callback URL
+	AuditActionMigrateCallback = "migrate_callback"
+	// AuditActionReapply records a detection job whose sponsors were regenerated from its
+	// stored LLM response
+	AuditActionReapply = "reapply"
+)
+
+// AuditEntry records one mutating API operation and who performed it
+type AuditEntry struct {
+	ID           int64           `json:"id"`
+	APIKeyID     *string         `json:"api_key_id"` // short hash of the API key, never the key itself
+	Actor        *string         `json:"actor"`      // X-Actor header, if the caller sent one
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
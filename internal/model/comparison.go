@@ -0,0 +1,191 @@
+package model
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// MetricComparison compares a numeric metric between two enrichments
+type MetricComparison struct {
+	From  *int64 `json:"from"`
+	To    *int64 `json:"to"`
+	Delta *int64 `json:"delta"` // nil unless both sides are known
+}
+
+// FieldChange records a non-numeric field whose value differs between two enrichments
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// VideoEnrichmentComparison is a field-by-field comparison of two enrichments of the same video
+type VideoEnrichmentComparison struct {
+	VideoID        string                      `json:"video_id"`
+	FromEnrichedAt *time.Time                  `json:"from_enriched_at"` // nil when there is no enrichment on that side
+	ToEnrichedAt   *time.Time                  `json:"to_enriched_at"`
+	Metrics        map[string]MetricComparison `json:"metrics"`
+	Changes        map[string]FieldChange      `json:"changes"` // only fields that differ; empty if either side is missing
+}
+
+// CompareVideoEnrichments compares two enrichments of a video. Either side may be nil.
+func CompareVideoEnrichments(videoID string, from, to *VideoEnrichment) *VideoEnrichmentComparison {
+	comparison := &VideoEnrichmentComparison{
+		VideoID: videoID,
+		Metrics: make(map[string]MetricComparison),
+		Changes: make(map[string]FieldChange),
+	}
+
+	if from != nil {
+		comparison.FromEnrichedAt = &from.EnrichedAt
+	}
+	if to != nil {
+		comparison.ToEnrichedAt = &to.EnrichedAt
+	}
+
+	metrics := map[string]func(*VideoEnrichment) *int64{
+		"view_count":         func(e *VideoEnrichment) *int64 { return e.ViewCount },
+		"like_count":         func(e *VideoEnrichment) *int64 { return e.LikeCount },
+		"dislike_count":      func(e *VideoEnrichment) *int64 { return e.DislikeCount },
+		"favorite_count":     func(e *VideoEnrichment) *int64 { return e.FavoriteCount },
+		"comment_count":      func(e *VideoEnrichment) *int64 { return e.CommentCount },
+		"concurrent_viewers": func(e *VideoEnrichment) *int64 { return e.ConcurrentViewers },
+	}
+
+	for name, get := range metrics {
+		var metric MetricComparison
+		if from != nil {
+			metric.From = get(from)
+		}
+		if to != nil {
+			metric.To = get(to)
+		}
+		if metric.From != nil && metric.To != nil {
+			delta := *metric.To - *metric.From
+			metric.Delta = &delta
+		}
+		comparison.Metrics[name] = metric
+	}
+
+	if from == nil || to == nil {
+		return comparison
+	}
+
+	compareString := func(name string, a, b *string) {
+		if !ptrEqual(a, b) {
+			comparison.Changes[name] = FieldChange{From: a, To: b}
+		}
+	}
+	compareBool := func(name string, a, b *bool) {
+		if !ptrEqual(a, b) {
+			comparison.Changes[name] = FieldChange{From: a, To: b}
+		}
+	}
+
+	compareString("description", from.Description, to.Description)
+	compareString("duration", from.Duration, to.Duration)
+	compareString("definition", from.Definition, to.Definition)
+	compareString("caption", from.Caption, to.Caption)
+	compareString("category_id", from.CategoryID, to.CategoryID)
+	compareString("default_language", from.DefaultLanguage, to.DefaultLanguage)
+	compareString("default_audio_language", from.DefaultAudioLanguage, to.DefaultAudioLanguage)
+	compareString("inferred_language", from.InferredLanguage, to.InferredLanguage)
+	compareString("privacy_status", from.PrivacyStatus, to.PrivacyStatus)
+	compareString("license", from.License, to.License)
+	compareString("upload_status", from.UploadStatus, to.UploadStatus)
+	compareString("live_broadcast_content", from.LiveBroadcastContent, to.LiveBroadcastContent)
+	compareString("channel_title", from.ChannelTitle, to.ChannelTitle)
+	compareString("thumbnail_high_url", from.ThumbnailHighURL, to.ThumbnailHighURL)
+	compareBool("embeddable", from.Embeddable, to.Embeddable)
+	compareBool("public_stats_viewable", from.PublicStatsViewable, to.PublicStatsViewable)
+	compareBool("made_for_kids", from.MadeForKids, to.MadeForKids)
+	compareBool("comments_disabled", from.CommentsDisabled, to.CommentsDisabled)
+	if from.IsPremiere != to.IsPremiere {
+		comparison.Changes["is_premiere"] = FieldChange{From: from.IsPremiere, To: to.IsPremiere}
+	}
+
+	if !slices.Equal(from.Tags, to.Tags) {
+		comparison.Changes["tags"] = FieldChange{From: from.Tags, To: to.Tags}
+	}
+	if !slices.Equal(from.TopicCategories, to.TopicCategories) {
+		comparison.Changes["topic_categories"] = FieldChange{From: from.TopicCategories, To: to.TopicCategories}
+	}
+
+	return comparison
+}
+
+// ChannelGrowthPoint is one channel enrichment with its change since the previous one.
+// Deltas and per-day velocities are nil for the first point and when either count is unknown.
+type ChannelGrowthPoint struct {
+	EnrichedAt      time.Time `json:"enriched_at"`
+	SubscriberCount *int64    `json:"subscriber_count"`
+	ViewCount       *int64    `json:"view_count"`
+	VideoCount      *int64    `json:"video_count"`
+
+	DaysSincePrevious *float64 `json:"days_since_previous"`
+	SubscriberDelta   *int64   `json:"subscriber_delta"`
+	ViewDelta         *int64   `json:"view_delta"`
+	VideoDelta        *int64   `json:"video_delta"`
+	SubscribersPerDay *float64 `json:"subscribers_per_day"`
+	ViewsPerDay       *float64 `json:"views_per_day"`
+}
+
+// ComputeChannelGrowth orders a channel's enrichments oldest first and computes the change between successive ones
+func ComputeChannelGrowth(enrichments []*ChannelEnrichment) []ChannelGrowthPoint {
+	sorted := slices.Clone(enrichments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EnrichedAt.Before(sorted[j].EnrichedAt)
+	})
+
+	points := make([]ChannelGrowthPoint, 0, len(sorted))
+	for i, e := range sorted {
+		point := ChannelGrowthPoint{
+			EnrichedAt:      e.EnrichedAt,
+			SubscriberCount: e.SubscriberCount,
+			ViewCount:       e.ViewCount,
+			VideoCount:      e.VideoCount,
+		}
+
+		if i > 0 {
+			prev := sorted[i-1]
+			days := e.EnrichedAt.Sub(prev.EnrichedAt).Hours() / 24
+			point.DaysSincePrevious = &days
+
+			point.SubscriberDelta = int64Delta(prev.SubscriberCount, e.SubscriberCount)
+			point.ViewDelta = int64Delta(prev.ViewCount, e.ViewCount)
+			point.VideoDelta = int64Delta(prev.VideoCount, e.VideoCount)
+			point.SubscribersPerDay = perDay(point.SubscriberDelta, days)
+			point.ViewsPerDay = perDay(point.ViewDelta, days)
+		}
+
+		points = append(points, point)
+	}
+
+	return points
+}
+
+// int64Delta returns to - from, or nil if either side is unknown
+func int64Delta(from, to *int64) *int64 {
+	if from == nil || to == nil {
+		return nil
+	}
+	delta := *to - *from
+	return &delta
+}
+
+// perDay divides a delta by the elapsed days, or returns nil if either is unusable
+func perDay(delta *int64, days float64) *float64 {
+	if delta == nil || days <= 0 {
+		return nil
+	}
+	velocity := float64(*delta) / days
+	return &velocity
+}
+
+// ptrEqual reports whether two optional values are both nil or hold equal values
+func ptrEqual[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,8 @@
+package model
+
+// TableStats is the approximate size of a database table, for capacity planning
+type TableStats struct {
+	TableName      string `json:"table_name"`
+	ApproxRowCount int64  `json:"approx_row_count"` // from planner statistics, not an exact COUNT(*)
+	TotalSizeBytes int64  `json:"total_size_bytes"` // table, indexes and TOAST
+}
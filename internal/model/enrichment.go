@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // VideoEnrichment represents comprehensive YouTube API v3 data for a video
 type VideoEnrichment struct {
@@ -38,15 +41,24 @@ type VideoEnrichment struct {
 	LikeCount     *int64 `json:"like_count"`
 	DislikeCount  *int64 `json:"dislike_count"`
 	FavoriteCount *int64 `json:"favorite_count"`
-	CommentCount  *int64 `json:"comment_count"`
+	CommentCount  *int64 `json:"comment_count"` // nil when comments are disabled
+
+	// CommentsDisabled is true when YouTube omitted the comment count because
+	// comments are turned off; nil when statistics were not fetched
+	CommentsDisabled *bool `json:"comments_disabled"`
 
 	// Categorization
 	CategoryID           *string  `json:"category_id"`
+	CategoryName         *string  `json:"category_name,omitempty"` // resolved when served; not stored
 	Tags                 []string `json:"tags"`
 	DefaultLanguage      *string  `json:"default_language"`       // BCP-47 language code
 	DefaultAudioLanguage *string  `json:"default_audio_language"` // BCP-47 language code
 	TopicCategories      []string `json:"topic_categories"`       // Wikipedia URLs
 
+	// InferredLanguage is derived from the video's caption tracks when the API reports neither
+	// default language; it is never reported by the API itself
+	InferredLanguage *string `json:"inferred_language"`
+
 	// Content classification
 	PrivacyStatus           *string `json:"privacy_status"` // "public", "unlisted", "private"
 	License                 *string `json:"license"`        // "youtube" or "creativeCommon"
@@ -67,6 +79,10 @@ type VideoEnrichment struct {
 	ActualEndTime        *time.Time `json:"actual_end_time"`
 	ConcurrentViewers    *int64     `json:"concurrent_viewers"`
 
+	// IsPremiere marks a scheduled premiere of an uploaded video, as opposed to an upcoming
+	// live stream; computed at enrichment time by DetectPremiere
+	IsPremiere bool `json:"is_premiere"`
+
 	// Location data
 	LocationDescription *string  `json:"location_description"`
 	LocationLatitude    *float64 `json:"location_latitude"`
@@ -78,12 +94,20 @@ type VideoEnrichment struct {
 	// Channel info (at enrichment time)
 	ChannelTitle *string `json:"channel_title"`
 
+	// ChannelSubscriberCountAtEnrichment is the subscriber count from the channel's latest
+	// enrichment when this video was enriched; nil if the channel has no enrichment
+	ChannelSubscriberCountAtEnrichment *int64 `json:"channel_subscriber_count_at_enrichment"`
+
 	// API metadata
 	EnrichedAt        time.Time `json:"enriched_at"`
 	APIResponseEtag   *string   `json:"api_response_etag"`
 	QuotaCost         int       `json:"quota_cost"`
 	APIPartsRequested []string  `json:"api_parts_requested"`
 
+	// Freshness of enriched_at, computed when served by SetFreshness; not stored
+	EnrichmentAgeSeconds *int64 `json:"enrichment_age_seconds,omitempty"`
+	IsStale              *bool  `json:"is_stale,omitempty"`
+
 	// Raw API response (for debugging and future schema evolution)
 	RawAPIResponse map[string]interface{} `json:"raw_api_response"`
 
@@ -92,22 +116,76 @@ type VideoEnrichment struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// BestThumbnailURL returns the highest-resolution thumbnail URL that YouTube provided,
+// or nil if the video has no thumbnails. Not every video has standard or maxres thumbnails.
+func (e *VideoEnrichment) BestThumbnailURL() *string {
+	for _, url := range []*string{
+		e.ThumbnailMaxresURL,
+		e.ThumbnailStandardURL,
+		e.ThumbnailHighURL,
+		e.ThumbnailMediumURL,
+		e.ThumbnailDefaultURL,
+	} {
+		if url != nil && *url != "" {
+			return url
+		}
+	}
+	return nil
+}
+
+// DetectPremiere reports whether the enrichment describes an upcoming premiere. The API
+// reports premieres like upcoming live streams (liveBroadcastContent "upcoming" with a
+// scheduled start), but a premiere is an already uploaded video and so has a duration,
+// while an upcoming live stream has none yet ("P0D").
+func (e *VideoEnrichment) DetectPremiere() bool {
+	if e.LiveBroadcastContent == nil || *e.LiveBroadcastContent != "upcoming" || e.ScheduledStartTime == nil {
+		return false
+	}
+	return e.Duration != nil && *e.Duration != "" && *e.Duration != "P0D"
+}
+
+// SetFreshness sets the enrichment's age as of now, and marks it stale when it is older
+// than staleAfter
+func (e *VideoEnrichment) SetFreshness(now time.Time, staleAfter time.Duration) {
+	age := now.Sub(e.EnrichedAt)
+	if age < 0 {
+		age = 0
+	}
+	ageSeconds := int64(age / time.Second)
+	isStale := age > staleAfter
+	e.EnrichmentAgeSeconds = &ageSeconds
+	e.IsStale = &isStale
+}
+
+// MarshalJSON adds the computed best_thumbnail_url to the stored fields
+func (e VideoEnrichment) MarshalJSON() ([]byte, error) {
+	type videoEnrichment VideoEnrichment
+	return json.Marshal(struct {
+		videoEnrichment
+		BestThumbnailURL *string `json:"best_thumbnail_url"`
+	}{
+		videoEnrichment:  videoEnrichment(e),
+		BestThumbnailURL: e.BestThumbnailURL(),
+	})
+}
+
 // EnrichmentJob represents a job to enrich a video with YouTube API data
 type EnrichmentJob struct {
 	ID              int64                  `json:"id"`
 	AsynqTaskID     *string                `json:"asynq_task_id"`
 	JobType         string                 `json:"job_type"`
 	VideoID         string                 `json:"video_id"`
-	Status          string                 `json:"status"` // pending, processing, completed, failed, cancelled
+	Status          string                 `json:"status"` // pending, processing, completed, failed, cancelled, deferred, skipped
 	Priority        int                    `json:"priority"`
 	ScheduledAt     time.Time              `json:"scheduled_at"`
 	StartedAt       *time.Time             `json:"started_at"`
 	CompletedAt     *time.Time             `json:"completed_at"`
-	Attempts        int                    `json:"attempts"`
+	Attempts        int                    `json:"attempts"` // handler runs, including asynq retries
 	MaxAttempts     int                    `json:"max_attempts"`
 	NextRetryAt     *time.Time             `json:"next_retry_at"`
 	ErrorMessage    *string                `json:"error_message"`
 	ErrorStackTrace *string                `json:"error_stack_trace"`
+	DeferReason     *string                `json:"defer_reason"`
 	Metadata        map[string]interface{} `json:"metadata"`
 	CreatedAt       time.Time              `json:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at"`
@@ -134,6 +212,10 @@ type QuotaInfo struct {
 	QuotaLimit      int `json:"quota_limit"`
 	QuotaRemaining  int `json:"quota_remaining"`
 	OperationsCount int `json:"operations_count"`
+
+	// Date is the quota day in the database's timezone, and ResetsAt the instant it ends
+	Date     time.Time `json:"date"`
+	ResetsAt time.Time `json:"resets_at"`
 }
 
 // ChannelEnrichment represents comprehensive YouTube API v3 data for a channel
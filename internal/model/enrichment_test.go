@@ -0,0 +1,165 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestVideoEnrichment_BestThumbnailURL(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		enrichment VideoEnrichment
+		want       *string
+	}{
+		{
+			name: "maxres is preferred",
+			enrichment: VideoEnrichment{
+				ThumbnailDefaultURL: strPtr("https://i.ytimg.com/vi/abc/default.jpg"),
+				ThumbnailHighURL:    strPtr("https://i.ytimg.com/vi/abc/hqdefault.jpg"),
+				ThumbnailMaxresURL:  strPtr("https://i.ytimg.com/vi/abc/maxresdefault.jpg"),
+			},
+			want: strPtr("https://i.ytimg.com/vi/abc/maxresdefault.jpg"),
+		},
+		{
+			name: "only default and medium returns medium",
+			enrichment: VideoEnrichment{
+				ThumbnailDefaultURL: strPtr("https://i.ytimg.com/vi/abc/default.jpg"),
+				ThumbnailMediumURL:  strPtr("https://i.ytimg.com/vi/abc/mqdefault.jpg"),
+			},
+			want: strPtr("https://i.ytimg.com/vi/abc/mqdefault.jpg"),
+		},
+		{
+			name: "empty URLs are skipped",
+			enrichment: VideoEnrichment{
+				ThumbnailDefaultURL:  strPtr("https://i.ytimg.com/vi/abc/default.jpg"),
+				ThumbnailStandardURL: strPtr(""),
+			},
+			want: strPtr("https://i.ytimg.com/vi/abc/default.jpg"),
+		},
+		{
+			name:       "no thumbnails",
+			enrichment: VideoEnrichment{},
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.enrichment.BestThumbnailURL()
+			if !ptrEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", deref(tt.want), deref(got))
+			}
+		})
+	}
+}
+
+func TestVideoEnrichment_MarshalJSONIncludesBestThumbnail(t *testing.T) {
+	medium := "https://i.ytimg.com/vi/abc/mqdefault.jpg"
+	defaultURL := "https://i.ytimg.com/vi/abc/default.jpg"
+	enrichment := &VideoEnrichment{
+		VideoID:             "abc",
+		ThumbnailDefaultURL: &defaultURL,
+		ThumbnailMediumURL:  &medium,
+	}
+
+	data, err := json.Marshal(enrichment)
+	if err != nil {
+		t.Fatalf("failed to marshal enrichment: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal enrichment: %v", err)
+	}
+
+	if decoded["best_thumbnail_url"] != medium {
+		t.Errorf("expected best_thumbnail_url %q, got %v", medium, decoded["best_thumbnail_url"])
+	}
+	if decoded["video_id"] != "abc" {
+		t.Errorf("expected stored fields to be kept, got video_id %v", decoded["video_id"])
+	}
+	if decoded["thumbnail_medium_url"] != medium {
+		t.Errorf("expected thumbnail_medium_url %q, got %v", medium, decoded["thumbnail_medium_url"])
+	}
+}
+
+func TestVideoEnrichment_DetectPremiere(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	scheduled := time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		enrichment VideoEnrichment
+		want       bool
+	}{
+		{
+			name: "premiere has a duration",
+			enrichment: VideoEnrichment{
+				LiveBroadcastContent: strPtr("upcoming"),
+				ScheduledStartTime:   &scheduled,
+				Duration:             strPtr("PT12M34S"),
+			},
+			want: true,
+		},
+		{
+			name: "upcoming live stream has no duration yet",
+			enrichment: VideoEnrichment{
+				LiveBroadcastContent: strPtr("upcoming"),
+				ScheduledStartTime:   &scheduled,
+				Duration:             strPtr("P0D"),
+			},
+			want: false,
+		},
+		{
+			name: "upcoming without content details",
+			enrichment: VideoEnrichment{
+				LiveBroadcastContent: strPtr("upcoming"),
+				ScheduledStartTime:   &scheduled,
+			},
+			want: false,
+		},
+		{
+			name: "upcoming without a scheduled start",
+			enrichment: VideoEnrichment{
+				LiveBroadcastContent: strPtr("upcoming"),
+				Duration:             strPtr("PT12M34S"),
+			},
+			want: false,
+		},
+		{
+			name: "live broadcast",
+			enrichment: VideoEnrichment{
+				LiveBroadcastContent: strPtr("live"),
+				ScheduledStartTime:   &scheduled,
+				Duration:             strPtr("PT12M34S"),
+			},
+			want: false,
+		},
+		{
+			name: "regular video",
+			enrichment: VideoEnrichment{
+				LiveBroadcastContent: strPtr("none"),
+				Duration:             strPtr("PT4M13S"),
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.enrichment.DetectPremiere(); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// LiveViewerSample is one concurrent-viewer reading for a livestream
+type LiveViewerSample struct {
+	ID                   int64     `json:"id"`
+	VideoID              string    `json:"video_id"`
+	LiveBroadcastContent *string   `json:"live_broadcast_content"` // nil when the API no longer returned the video
+	ConcurrentViewers    *int64    `json:"concurrent_viewers"`
+	SampledAt            time.Time `json:"sampled_at"`
+}
+
+// LiveStatus is a video's live state as returned by videos.list
+type LiveStatus struct {
+	VideoID              string
+	LiveBroadcastContent string // "none", "upcoming" or "live"
+	ConcurrentViewers    *int64 // only reported while the stream is live
+}
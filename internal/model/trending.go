@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// TrendingSnapshot records a tracked video's position on a region's mostPopular chart on a given day
+type TrendingSnapshot struct {
+	ID           int64     `json:"id"`
+	VideoID      string    `json:"video_id"`
+	RegionCode   string    `json:"region_code"`
+	SnapshotDate time.Time `json:"snapshot_date"`
+	ChartRank    int       `json:"chart_rank"` // 1-based
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"encoding/xml"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -16,12 +17,19 @@ type AtomFeed struct {
 
 // AtomEntry represents a video entry in the Atom feed.
 type AtomEntry struct {
-	VideoID   string    `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
-	ChannelID string    `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
-	Title     string    `xml:"title"`
-	Link      AtomLink  `xml:"link"`
-	Published time.Time `xml:"published"`
-	Updated   time.Time `xml:"updated"`
+	VideoID   string      `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+	ChannelID string      `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
+	Title     string      `xml:"title"`
+	Link      AtomLink    `xml:"link"`
+	Published time.Time   `xml:"published"`
+	Updated   time.Time   `xml:"updated"`
+	Author    *AtomAuthor `xml:"author"`
+}
+
+// AtomAuthor identifies the channel that published an entry. Its name is the channel title.
+type AtomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"`
 }
 
 // AtomLink represents a link element in the Atom feed.
@@ -32,10 +40,20 @@ type AtomLink struct {
 
 // DeletedEntry represents a deleted video notification.
 type DeletedEntry struct {
-	Ref  string    `xml:"ref,attr"`
-	When time.Time `xml:"when,attr"`
+	Ref  string     `xml:"ref,attr"` // "yt:video:<video ID>"
+	When time.Time  `xml:"when,attr"`
+	By   *DeletedBy `xml:"by"`
+}
+
+// DeletedBy identifies the channel a deleted video belonged to.
+type DeletedBy struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"` // "https://www.youtube.com/channel/<channel ID>"
 }
 
+// deletedVideoRefPrefix prefixes the video ID in a deleted entry's ref attribute
+const deletedVideoRefPrefix = "yt:video:"
+
 // VideoData contains the parsed video information from an Atom feed.
 type VideoData struct {
 	VideoID     string
@@ -45,6 +63,9 @@ type VideoData struct {
 	PublishedAt time.Time
 	UpdatedAt   time.Time
 	IsDeleted   bool
+
+	// AuthorName is the channel title from the entry's author element, if present
+	AuthorName string
 }
 
 // ParseAtomFeed parses a YouTube Atom feed XML and extracts video information.
@@ -56,10 +77,18 @@ func ParseAtomFeed(rawXML string) (*VideoData, error) {
 	}
 
 	// Check if this is a deleted entry
+	// Deleted entries carry the video ID in their ref and, usually, the channel in <by>
 	if feed.Deleted != nil {
-		return &VideoData{
-			IsDeleted: true,
-		}, nil
+		data := &VideoData{IsDeleted: true}
+		if videoID, ok := strings.CutPrefix(feed.Deleted.Ref, deletedVideoRefPrefix); ok {
+			data.VideoID = videoID
+		}
+		if feed.Deleted.By != nil {
+			if _, channelID, ok := strings.Cut(feed.Deleted.By.URI, "/channel/"); ok {
+				data.ChannelID = channelID
+			}
+		}
+		return data, nil
 	}
 
 	// Validate that we have an entry
@@ -67,8 +96,60 @@ func ParseAtomFeed(rawXML string) (*VideoData, error) {
 		return nil, fmt.Errorf("atom feed missing entry element")
 	}
 
-	entry := feed.Entry
+	return entryVideoData(feed.Entry)
+}
 
+// ChannelFeed is a channel's public video feed (https://www.youtube.com/feeds/videos.xml),
+// which lists the channel's most recent uploads as entries in the notification format.
+type ChannelFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+// ParseChannelFeed parses a channel video feed and returns the entries in feed order.
+// It fails if any entry is missing a required field.
+func ParseChannelFeed(rawXML string) ([]*VideoData, error) {
+	var feed ChannelFeed
+	if err := xml.Unmarshal([]byte(rawXML), &feed); err != nil {
+		return nil, fmt.Errorf("unmarshal channel feed: %w", err)
+	}
+
+	videos := make([]*VideoData, 0, len(feed.Entries))
+	for i := range feed.Entries {
+		data, err := entryVideoData(&feed.Entries[i])
+		if err != nil {
+			return nil, fmt.Errorf("channel feed entry %d: %w", i, err)
+		}
+		videos = append(videos, data)
+	}
+
+	return videos, nil
+}
+
+// NotificationXML renders the video as a single-entry Atom feed in the format of a hub
+// notification, so it can be processed like one.
+func (v *VideoData) NotificationXML() (string, error) {
+	entry := &AtomEntry{
+		VideoID:   v.VideoID,
+		ChannelID: v.ChannelID,
+		Title:     v.Title,
+		Link:      AtomLink{Rel: "alternate", Href: v.VideoURL},
+		Published: v.PublishedAt,
+		Updated:   v.UpdatedAt,
+	}
+	if v.AuthorName != "" {
+		entry.Author = &AtomAuthor{Name: v.AuthorName}
+	}
+
+	data, err := xml.Marshal(AtomFeed{Entry: entry})
+	if err != nil {
+		return "", fmt.Errorf("marshal notification: %w", err)
+	}
+	return xml.Header + string(data), nil
+}
+
+// entryVideoData validates an entry and extracts its video information
+func entryVideoData(entry *AtomEntry) (*VideoData, error) {
 	// Validate required fields
 	if entry.VideoID == "" {
 		return nil, fmt.Errorf("atom entry missing video ID")
@@ -87,7 +168,7 @@ func ParseAtomFeed(rawXML string) (*VideoData, error) {
 		videoURL = fmt.Sprintf("https://www.youtube.com/watch?v=%s", entry.VideoID)
 	}
 
-	return &VideoData{
+	data := &VideoData{
 		VideoID:     entry.VideoID,
 		ChannelID:   entry.ChannelID,
 		Title:       entry.Title,
@@ -95,5 +176,10 @@ func ParseAtomFeed(rawXML string) (*VideoData, error) {
 		PublishedAt: entry.Published,
 		UpdatedAt:   entry.Updated,
 		IsDeleted:   false,
-	}, nil
+	}
+	if entry.Author != nil {
+		data.AuthorName = strings.TrimSpace(entry.Author.Name)
+	}
+
+	return data, nil
 }
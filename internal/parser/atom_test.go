@@ -42,6 +42,35 @@ func TestParseAtomFeed(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "author name is the channel title",
+			rawXML: `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>dQw4w9WgXcQ</yt:videoId>
+    <yt:channelId>UCuAXFkgsw1L7xaCfnd5JJOw</yt:channelId>
+    <title>Never Gonna Give You Up</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=dQw4w9WgXcQ"/>
+    <author>
+      <name>Rick Astley</name>
+      <uri>https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw</uri>
+    </author>
+    <published>2009-10-25T06:57:33+00:00</published>
+    <updated>2022-03-15T12:00:00+00:00</updated>
+  </entry>
+</feed>`,
+			want: &VideoData{
+				VideoID:     "dQw4w9WgXcQ",
+				ChannelID:   "UCuAXFkgsw1L7xaCfnd5JJOw",
+				Title:       "Never Gonna Give You Up",
+				VideoURL:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+				PublishedAt: mustParseTime("2009-10-25T06:57:33+00:00"),
+				UpdatedAt:   mustParseTime("2022-03-15T12:00:00+00:00"),
+				IsDeleted:   false,
+				AuthorName:  "Rick Astley",
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid atom feed without link element",
 			rawXML: `<?xml version="1.0" encoding="UTF-8"?>
@@ -96,6 +125,26 @@ func TestParseAtomFeed(t *testing.T) {
   <yt:deleted-entry ref="yt:video:deleted123" when="2025-01-15T12:00:00+00:00"/>
 </feed>`,
 			want: &VideoData{
+				VideoID:   "deleted123",
+				IsDeleted: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "deleted video entry with channel",
+			rawXML: `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <yt:deleted-entry ref="yt:video:deleted123" when="2025-01-15T12:00:00+00:00">
+    <link href="https://www.youtube.com/watch?v=deleted123"/>
+    <yt:by>
+      <name>Test Channel</name>
+      <uri>https://www.youtube.com/channel/UCtest</uri>
+    </yt:by>
+  </yt:deleted-entry>
+</feed>`,
+			want: &VideoData{
+				VideoID:   "deleted123",
+				ChannelID: "UCtest",
 				IsDeleted: true,
 			},
 			wantErr: false,
@@ -229,3 +278,82 @@ func mustParseTime(s string) time.Time {
 	}
 	return t
 }
+
+func TestParseChannelFeed(t *testing.T) {
+	t.Parallel()
+
+	rawXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns:media="http://search.yahoo.com/mrss/" xmlns="http://www.w3.org/2005/Atom">
+  <link rel="self" href="http://www.youtube.com/feeds/videos.xml?channel_id=UCuAXFkgsw1L7xaCfnd5JJOw"/>
+  <yt:channelId>UCuAXFkgsw1L7xaCfnd5JJOw</yt:channelId>
+  <title>Rick Astley</title>
+  <entry>
+    <id>yt:video:newVideo123</id>
+    <yt:videoId>newVideo123</yt:videoId>
+    <yt:channelId>UCuAXFkgsw1L7xaCfnd5JJOw</yt:channelId>
+    <title>New Video</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=newVideo123"/>
+    <author>
+      <name>Rick Astley</name>
+      <uri>https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw</uri>
+    </author>
+    <published>2024-05-01T10:00:00+00:00</published>
+    <updated>2024-05-01T11:00:00+00:00</updated>
+  </entry>
+  <entry>
+    <id>yt:video:dQw4w9WgXcQ</id>
+    <yt:videoId>dQw4w9WgXcQ</yt:videoId>
+    <yt:channelId>UCuAXFkgsw1L7xaCfnd5JJOw</yt:channelId>
+    <title>Never Gonna Give You Up</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=dQw4w9WgXcQ"/>
+    <published>2009-10-25T06:57:33+00:00</published>
+    <updated>2022-03-15T12:00:00+00:00</updated>
+  </entry>
+</feed>`
+
+	videos, err := ParseChannelFeed(rawXML)
+	require.NoError(t, err)
+	require.Len(t, videos, 2)
+	assert.Equal(t, "newVideo123", videos[0].VideoID)
+	assert.Equal(t, "Rick Astley", videos[0].AuthorName)
+	assert.Equal(t, "dQw4w9WgXcQ", videos[1].VideoID)
+	assert.Equal(t, mustParseTime("2009-10-25T06:57:33+00:00"), videos[1].PublishedAt)
+
+	t.Run("entry missing video ID", func(t *testing.T) {
+		_, err := ParseChannelFeed(`<feed xmlns="http://www.w3.org/2005/Atom"><entry><title>x</title></entry></feed>`)
+		assert.ErrorContains(t, err, "missing video ID")
+	})
+
+	t.Run("empty feed", func(t *testing.T) {
+		videos, err := ParseChannelFeed(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`)
+		require.NoError(t, err)
+		assert.Empty(t, videos)
+	})
+}
+
+func TestVideoData_NotificationXMLRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	video := &VideoData{
+		VideoID:     "dQw4w9WgXcQ",
+		ChannelID:   "UCuAXFkgsw1L7xaCfnd5JJOw",
+		Title:       "Never Gonna Give You Up",
+		VideoURL:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		PublishedAt: mustParseTime("2009-10-25T06:57:33+00:00"),
+		UpdatedAt:   mustParseTime("2022-03-15T12:00:00+00:00"),
+		AuthorName:  "Rick Astley",
+	}
+
+	rawXML, err := video.NotificationXML()
+	require.NoError(t, err)
+
+	parsed, err := ParseAtomFeed(rawXML)
+	require.NoError(t, err)
+	assert.Equal(t, video.VideoID, parsed.VideoID)
+	assert.Equal(t, video.ChannelID, parsed.ChannelID)
+	assert.Equal(t, video.Title, parsed.Title)
+	assert.Equal(t, video.VideoURL, parsed.VideoURL)
+	assert.True(t, video.PublishedAt.Equal(parsed.PublishedAt))
+	assert.True(t, video.UpdatedAt.Equal(parsed.UpdatedAt))
+	assert.Equal(t, video.AuthorName, parsed.AuthorName)
+}
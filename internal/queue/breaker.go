@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/service/ollama"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	// llmUnavailableBaseDelay is the first retry delay after the LLM server could not be reached
+	llmUnavailableBaseDelay = 30 * time.Second
+	// llmUnavailableMaxDelay caps the retry delay while the LLM server stays unreachable
+	llmUnavailableMaxDelay = 10 * time.Minute
+)
+
+// retryDelay spaces out retries of tasks that failed because Ollama was unreachable,
+// doubling from llmUnavailableBaseDelay, and uses asynq's default delay for other failures
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if !ollama.IsUnavailable(err) {
+		return asynq.DefaultRetryDelayFunc(n, err, task)
+	}
+
+	delay := llmUnavailableBaseDelay
+	for i := 0; i < n && delay < llmUnavailableMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > llmUnavailableMaxDelay {
+		delay = llmUnavailableMaxDelay
+	}
+	return delay
+}
+
+// QueuePauser pauses and resumes processing of a queue
+type QueuePauser interface {
+	PauseQueue(queue string) error
+	UnpauseQueue(queue string) error
+	QueuePaused(queue string) (bool, error)
+}
+
+// CircuitBreaker pauses a queue after consecutive failures caused by an unreachable
+// dependency and resumes it after a cooldown. Once resumed, a single further failure
+// pauses the queue again; a success resets the count.
+//
+// The pause is stored in Redis but the cooldown timer lives in this process, so a
+// process that stops while the queue is paused would leave it paused for good;
+// Reconcile picks such a pause up again at startup.
+type CircuitBreaker struct {
+	queue     string
+	pauser    QueuePauser
+	threshold int
+	cooldown  time.Duration
+
+	mu          sync.Mutex
+	consecutive int
+	open        bool
+}
+
+// NewCircuitBreaker creates a breaker for queue that trips after threshold consecutive failures
+func NewCircuitBreaker(queue string, pauser QueuePauser, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		queue:     queue,
+		pauser:    pauser,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// RecordSuccess resets the consecutive failure count
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutive = 0
+}
+
+// RecordFailure counts a failure caused by an unreachable dependency and pauses the queue
+// once the threshold is reached. It reports whether this call paused the queue.
+func (b *CircuitBreaker) RecordFailure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutive++
+	if b.open || b.consecutive < b.threshold {
+		return false
+	}
+
+	if err := b.pauser.PauseQueue(b.queue); err != nil {
+		log.Printf("[Breaker] Warning: failed to pause queue %s: %v", b.queue, err)
+		return false
+	}
+
+	b.open = true
+	log.Printf("[Breaker] Paused queue %s after %d consecutive failures; resuming in %s", b.queue, b.consecutive, b.cooldown)
+	time.AfterFunc(b.cooldown, b.resume)
+	return true
+}
+
+// Reconcile takes over a pause of the queue left behind by a previous process, whose cooldown
+// timer died with it, and resumes the queue after a full cooldown. It is called once at startup.
+// A queue paused by hand is resumed the same way.
+func (b *CircuitBreaker) Reconcile() {
+	paused, err := b.pauser.QueuePaused(b.queue)
+	if err != nil {
+		log.Printf("[Breaker] Warning: failed to check whether queue %s is paused: %v", b.queue, err)
+		return
+	}
+	if !paused {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return
+	}
+
+	b.open = true
+	log.Printf("[Breaker] Queue %s is already paused; resuming in %s", b.queue, b.cooldown)
+	time.AfterFunc(b.cooldown, b.resume)
+}
+
+// Open reports whether the breaker currently has the queue paused
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// resume unpauses the queue after the cooldown
+func (b *CircuitBreaker) resume() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.pauser.UnpauseQueue(b.queue); err != nil {
+		log.Printf("[Breaker] Warning: failed to resume queue %s, retrying in %s: %v", b.queue, b.cooldown, err)
+		time.AfterFunc(b.cooldown, b.resume)
+		return
+	}
+
+	b.open = false
+	b.consecutive = b.threshold - 1
+	log.Printf("[Breaker] Resumed queue %s", b.queue)
+}
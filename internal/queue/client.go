@@ -2,19 +2,25 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/model"
 
 	"github.com/hibiken/asynq"
 )
 
+// ErrTaskActive is returned when cancelling a task that a worker is already processing
+var ErrTaskActive = errors.New("task is already being processed")
+
 // Client wraps asynq client for enqueueing tasks
 type Client struct {
 	asynqClient *asynq.Client
+	inspector   *asynq.Inspector
 	jobRepo     repository.EnrichmentJobRepository
 }
 
@@ -30,15 +36,77 @@ func NewClient(redisAddr string, jobRepo repository.EnrichmentJobRepository) (*C
 
 	return &Client{
 		asynqClient: asynqClient,
+		inspector:   asynq.NewInspector(redisOpt),
 		jobRepo:     jobRepo,
 	}, nil
 }
 
 // Close closes the client connection
 func (c *Client) Close() error {
+	c.inspector.Close()
 	return c.asynqClient.Close()
 }
 
+// CancelTask deletes a queued task of the given job type so it never runs.
+// A task that is no longer in its queue is treated as cancelled; a task that a
+// worker has already picked up returns ErrTaskActive.
+func (c *Client) CancelTask(ctx context.Context, jobType, taskID string) error {
+	queueName := queueForTaskType(jobType)
+
+	info, err := c.inspector.GetTaskInfo(queueName, taskID)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get task info: %w", err)
+	}
+
+	if info.State == asynq.TaskStateActive {
+		return ErrTaskActive
+	}
+
+	if err := c.inspector.DeleteTask(queueName, taskID); err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	log.Printf("[Queue] Cancelled task: type=%s, task_id=%s", jobType, taskID)
+	return nil
+}
+
+// queueForTaskType returns the asynq queue that tasks of the given type are enqueued to
+func queueForTaskType(taskType string) string {
+	if taskType == TypeSponsorDetection {
+		return "sponsor_detection"
+	}
+	return "default"
+}
+
+// PauseQueue stops workers from picking up tasks from the named queue
+func (c *Client) PauseQueue(queue string) error {
+	return c.inspector.PauseQueue(queue)
+}
+
+// UnpauseQueue resumes processing of a queue paused with PauseQueue
+func (c *Client) UnpauseQueue(queue string) error {
+	return c.inspector.UnpauseQueue(queue)
+}
+
+// QueuePaused reports whether the named queue is paused. A queue that has never
+// held a task does not exist yet and is not paused.
+func (c *Client) QueuePaused(queue string) (bool, error) {
+	info, err := c.inspector.GetQueueInfo(queue)
+	if err != nil {
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return info.Paused, nil
+}
+
 // EnqueueVideoEnrichment enqueues a video enrichment task
 func (c *Client) EnqueueVideoEnrichment(ctx context.Context, videoID, channelID string, priority int) error {
 	// Create payload
@@ -94,6 +162,78 @@ func (c *Client) EnqueueVideoEnrichment(ctx context.Context, videoID, channelID
 	return nil
 }
 
+// RecordSkippedVideoEnrichment records a video enrichment that was deliberately not enqueued,
+// as a skipped job with the given reason, so it shows up in job listings and statistics.
+func (c *Client) RecordSkippedVideoEnrichment(ctx context.Context, videoID, channelID, reason string) error {
+	job := &model.EnrichmentJob{
+		JobType:     TypeEnrichVideo,
+		VideoID:     videoID,
+		Status:      "skipped",
+		ScheduledAt: time.Now(),
+		MaxAttempts: 3,
+		Metadata: map[string]interface{}{
+			"channel_id": channelID,
+			"source":     "webhook",
+		},
+	}
+
+	if err := c.jobRepo.CreateJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to record skipped job: %w", err)
+	}
+
+	if err := c.jobRepo.MarkJobSkipped(ctx, job.ID, reason); err != nil {
+		return fmt.Errorf("failed to mark job skipped: %w", err)
+	}
+
+	return nil
+}
+
+// ScheduleVideoEnrichment enqueues a video enrichment task to run at processAt.
+// It is used to re-enqueue live streams and premieres once they are expected to have ended.
+func (c *Client) ScheduleVideoEnrichment(ctx context.Context, videoID, channelID string, processAt time.Time, metadata map[string]interface{}) error {
+	payload, err := NewEnrichVideoTask(videoID, channelID, 0, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to create task payload: %w", err)
+	}
+
+	payloadBytes, err := payload.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	task := asynq.NewTask(TypeEnrichVideo, payloadBytes)
+
+	info, err := c.asynqClient.Enqueue(task,
+		asynq.MaxRetry(3),
+		asynq.Timeout(5*time.Minute),
+		asynq.Queue("default"),
+		asynq.ProcessAt(processAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Printf("[Queue] Scheduled video enrichment: video_id=%s, task_id=%s, process_at=%s",
+		videoID, info.ID, processAt.Format(time.RFC3339))
+
+	job := &model.EnrichmentJob{
+		AsynqTaskID: strPtr(info.ID),
+		JobType:     TypeEnrichVideo,
+		VideoID:     videoID,
+		Status:      "pending",
+		ScheduledAt: processAt,
+		MaxAttempts: 3,
+		Metadata:    payload.Metadata,
+	}
+
+	if err := c.jobRepo.CreateJob(ctx, job); err != nil {
+		// Log but don't fail - the asynq task is already queued
+		log.Printf("[Queue] Warning: failed to record job in database: %v", err)
+	}
+
+	return nil
+}
+
 // EnqueueVideoEnrichmentBatch enqueues multiple video enrichment tasks
 func (c *Client) EnqueueVideoEnrichmentBatch(ctx context.Context, videoIDs []string, channelID string, priority int) error {
 	for _, videoID := range videoIDs {
@@ -147,16 +287,31 @@ func (c *Client) EnqueueChannelEnrichment(ctx context.Context, channelID string)
 	return nil
 }
 
-// EnqueueSponsorDetection enqueues a sponsor detection task
+// EnqueueSponsorDetection enqueues a sponsor detection task using the worker's configured model
 func (c *Client) EnqueueSponsorDetection(ctx context.Context, videoID, title, description, detectionJobID string, priority int) error {
+	return c.EnqueueSponsorDetectionWithModel(ctx, videoID, title, description, detectionJobID, "", SponsorDetectionSourceEnrichment, priority)
+}
+
+// EnqueueSponsorDetectionWithModel enqueues a sponsor detection task that runs with the given
+// LLM model instead of the worker's configured one, e.g. to try a new model. An empty model
+// uses the configured model. The worker records the model on the detection job. source records
+// what requested the detection in the task and job metadata.
+func (c *Client) EnqueueSponsorDetectionWithModel(ctx context.Context, videoID, title, description, detectionJobID, llmModel, source string, priority int) error {
+	if llmModel != "" {
+		if err := models.ValidateLLMModelName(llmModel); err != nil {
+			return fmt.Errorf("invalid model override: %w", err)
+		}
+	}
+
 	// Create payload
 	payload, err := NewSponsorDetectionTask(videoID, title, description, detectionJobID, map[string]interface{}{
-		"source":      "enrichment_callback",
+		"source":      source,
 		"enqueued_at": time.Now().Format(time.RFC3339),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create sponsor detection task payload: %w", err)
 	}
+	payload.Model = llmModel
 
 	// Marshal payload
 	payloadBytes, err := payload.Marshal()
@@ -191,7 +346,7 @@ func (c *Client) EnqueueSponsorDetection(ctx context.Context, videoID, title, de
 		MaxAttempts: 3,
 		Metadata: map[string]interface{}{
 			"detection_job_id": detectionJobID,
-			"source":           "enrichment_callback",
+			"source":           source,
 		},
 	}
 
@@ -2,13 +2,16 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/ollama"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
 
@@ -24,12 +27,24 @@ type EnrichmentHandler struct {
 	channelEnrichmentRepo   repository.ChannelEnrichmentRepository
 	jobRepo                 repository.EnrichmentJobRepository
 	sponsorDetectionRepo    repository.SponsorDetectionRepository
+	videoRepo               repository.VideoRepository
+	channelRepo             repository.ChannelRepository
 	ollamaClient            interface{} // Will be *ollama.Client, but use interface{} to avoid circular deps
 	callbackManager         *CallbackManager
 	batchSize               int
 	sponsorDetectionEnabled bool
+	livePolicy              LiveVideoPolicy
+	liveRecheckDelay        time.Duration
+	liveScheduler           VideoEnrichmentScheduler
+	allowedPrivacyStatuses  map[string]bool // nil enriches every privacy status
+	sponsorBreaker          *CircuitBreaker
+	writeSlots              chan struct{} // bounds concurrent enrichment inserts; nil means unbounded
 }
 
+// DefaultWriteHeadroom is how many pool connections are left free of enrichment inserts
+// when the write limit is derived from the pool size
+const DefaultWriteHeadroom = 2
+
 // NewEnrichmentHandler creates a new enrichment task handler
 func NewEnrichmentHandler(
 	youtubeClient *youtube.Client,
@@ -52,6 +67,8 @@ func NewEnrichmentHandler(
 		batchSize:               batchSize,
 		callbackManager:         NewCallbackManager(),
 		sponsorDetectionEnabled: false, // Default to disabled, will be set via SetSponsorDetection
+		livePolicy:              LiveVideoPolicyEnrich,
+		liveRecheckDelay:        DefaultLiveRecheckDelay,
 	}
 }
 
@@ -60,6 +77,117 @@ func (h *EnrichmentHandler) SetCallbackManager(cm *CallbackManager) {
 	h.callbackManager = cm
 }
 
+// SetVideoRepository sets the repository used to mark videos that the YouTube API no longer
+// returns as unavailable. Without it such videos only fail their enrichment job.
+func (h *EnrichmentHandler) SetVideoRepository(videoRepo repository.VideoRepository) {
+	h.videoRepo = videoRepo
+}
+
+// SetChannelRepository sets the repository used to backfill titles of channels first seen in a
+// webhook without one, from the channel title the YouTube API reports for their videos, and to
+// record renames detected by channel enrichment
+func (h *EnrichmentHandler) SetChannelRepository(channelRepo repository.ChannelRepository) {
+	h.channelRepo = channelRepo
+}
+
+// backfillChannelTitle stores the enrichment's channel title on a channel that has none.
+// Failures are logged and otherwise ignored; the title is retried on the next enrichment.
+func (h *EnrichmentHandler) backfillChannelTitle(ctx context.Context, channelID string, enrichment *model.VideoEnrichment) {
+	if h.channelRepo == nil || channelID == "" || enrichment.ChannelTitle == nil {
+		return
+	}
+
+	updated, err := h.channelRepo.BackfillTitle(ctx, channelID, *enrichment.ChannelTitle)
+	if err != nil {
+		log.Printf("[Handler] Warning: failed to backfill title for channel %s: %v", channelID, err)
+		return
+	}
+	if updated {
+		log.Printf("[Handler] Backfilled title for channel %s: %q", channelID, *enrichment.ChannelTitle)
+	}
+}
+
+// recordChannelTitleChange replaces a stored channel title that differs from the one channels.list
+// reports, keeping the previous title in the channel's name history. Failures are logged and
+// otherwise ignored; the change is detected again on the next channel enrichment.
+func (h *EnrichmentHandler) recordChannelTitleChange(ctx context.Context, channelID, title string) {
+	if h.channelRepo == nil || channelID == "" || title == "" {
+		return
+	}
+
+	change, err := h.channelRepo.RecordTitleChange(ctx, channelID, title)
+	if err != nil {
+		log.Printf("[Handler] Warning: failed to record title change for channel %s: %v", channelID, err)
+		return
+	}
+	if change != nil {
+		log.Printf("[Handler] Channel %s renamed from %q to %q", channelID, change.OldTitle, change.NewTitle)
+	}
+}
+
+// markVideosUnavailable records requested videos that videos.list returned no item for
+func (h *EnrichmentHandler) markVideosUnavailable(ctx context.Context, videoIDs []string) {
+	if h.videoRepo == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, videoID := range videoIDs {
+		if err := h.videoRepo.MarkUnavailable(ctx, videoID, youtube.ReasonNotReturned, now); err != nil {
+			log.Printf("[Handler] Warning: failed to mark video %s unavailable: %v", videoID, err)
+			continue
+		}
+		log.Printf("[Handler] Video %s is no longer available: %s", videoID, youtube.ReasonNotReturned)
+	}
+}
+
+// SetWriteConcurrencyLimit bounds how many enrichment inserts run at once, so a backfill with
+// many workers cannot take every pool connection. Further writes wait for a free slot, or
+// until their context is done. A limit of zero or less removes the bound.
+func (h *EnrichmentHandler) SetWriteConcurrencyLimit(limit int) {
+	if limit <= 0 {
+		h.writeSlots = nil
+		return
+	}
+	h.writeSlots = make(chan struct{}, limit)
+}
+
+// acquireWriteSlot waits for a free enrichment write slot. The returned function releases it.
+func (h *EnrichmentHandler) acquireWriteSlot(ctx context.Context) (func(), error) {
+	if h.writeSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case h.writeSlots <- struct{}{}:
+		return func() { <-h.writeSlots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for an enrichment write slot: %w", ctx.Err())
+	}
+}
+
+// storeVideoEnrichment inserts a video enrichment within the write concurrency limit
+func (h *EnrichmentHandler) storeVideoEnrichment(ctx context.Context, enrichment *model.VideoEnrichment) error {
+	release, err := h.acquireWriteSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return h.enrichmentRepo.CreateEnrichment(ctx, enrichment)
+}
+
+// storeChannelEnrichment inserts a channel enrichment within the write concurrency limit
+func (h *EnrichmentHandler) storeChannelEnrichment(ctx context.Context, enrichment *model.ChannelEnrichment) error {
+	release, err := h.acquireWriteSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return h.channelEnrichmentRepo.Create(ctx, enrichment)
+}
+
 // SetSponsorDetection configures sponsor detection dependencies
 func (h *EnrichmentHandler) SetSponsorDetection(ollamaClient interface{}, sponsorDetectionRepo repository.SponsorDetectionRepository, enabled bool) {
 	h.ollamaClient = ollamaClient
@@ -67,6 +195,18 @@ func (h *EnrichmentHandler) SetSponsorDetection(ollamaClient interface{}, sponso
 	h.sponsorDetectionEnabled = enabled
 }
 
+// SetSponsorDetectionBreaker pauses the sponsor_detection queue for cooldown after threshold
+// consecutive tasks fail because Ollama is unreachable. A threshold of zero or less disables it.
+// A pause left by a previous process is resumed after cooldown.
+func (h *EnrichmentHandler) SetSponsorDetectionBreaker(pauser QueuePauser, threshold int, cooldown time.Duration) {
+	if threshold <= 0 || pauser == nil {
+		h.sponsorBreaker = nil
+		return
+	}
+	h.sponsorBreaker = NewCircuitBreaker("sponsor_detection", pauser, threshold, cooldown)
+	h.sponsorBreaker.Reconcile()
+}
+
 // ProcessTask implements asynq.HandlerFunc
 func (h *EnrichmentHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
 	// Parse payload
@@ -75,37 +215,29 @@ func (h *EnrichmentHandler) ProcessTask(ctx context.Context, task *asynq.Task) e
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	log.Printf("[Handler] Processing video enrichment: video_id=%s, task_id=%s", payload.VideoID, task.ResultWriter().TaskID())
-
-	// Get job from database
-	job, err := h.jobRepo.GetJobByAsynqID(ctx, task.ResultWriter().TaskID())
-	if err != nil {
-		log.Printf("[Handler] Warning: could not find job in database: %v", err)
-		// Continue processing even if job tracking fails
+	// A task without a video ID has nothing to fetch; FetchVideos rejects an empty batch,
+	// so treat it as done rather than failing and retrying it
+	if payload.VideoID == "" {
+		log.Printf("[Handler] Skipping video enrichment task without a video ID")
+		return nil
 	}
 
-	// Mark job as processing
-	if job != nil {
-		if err := h.jobRepo.MarkJobProcessing(ctx, job.ID); err != nil {
-			log.Printf("[Handler] Warning: failed to mark job as processing: %v", err)
-		}
-	}
+	log.Printf("[Handler] Processing video enrichment: video_id=%s, task_id=%s", payload.VideoID, task.ResultWriter().TaskID())
 
-	// Check quota availability
-	// Official cost: 1 unit per video enrichment (videos.list API call)
-	available, quotaInfo, err := h.quotaManager.CheckQuotaAvailable(ctx, 1)
-	if err != nil {
-		return fmt.Errorf("failed to check quota: %w", err)
-	}
+	// Get or create the job row and mark it as processing
+	job := h.trackVideoJob(ctx, task.ResultWriter().TaskID(), payload)
 
-	if !available {
-		log.Printf("[Handler] Quota exhausted or threshold reached: %d/%d used", quotaInfo.QuotaUsed, quotaInfo.QuotaLimit)
-		// Return non-retryable error to avoid hammering the quota
-		return fmt.Errorf("quota exhausted: %d/%d used", quotaInfo.QuotaUsed, quotaInfo.QuotaLimit)
+	// Reserve quota for the videos.list call and any captions.list call
+	// Stored enrichments are the only fetches worth inferring a language for
+	fetchOpts := youtube.FetchOptions{InferLanguage: true}
+	ctx, err = h.reserveQuota(ctx, TypeEnrichVideo, h.youtubeClient.FetchVideosQuotaUsageWithOptions(1, fetchOpts))
+	if err != nil {
+		return err
 	}
+	defer quota.Release(ctx)
 
 	// Fetch video data from YouTube API
-	enrichments, quotaCost, err := h.youtubeClient.FetchVideos(ctx, []string{payload.VideoID})
+	enrichments, missingIDs, quotaCost, err := h.youtubeClient.FetchVideosWithOptions(ctx, []string{payload.VideoID}, fetchOpts)
 	if err != nil {
 		// Record failure in job
 		if job != nil {
@@ -119,6 +251,7 @@ func (h *EnrichmentHandler) ProcessTask(ctx context.Context, task *asynq.Task) e
 		if job != nil {
 			h.jobRepo.MarkJobFailed(ctx, job.ID, errMsg, nil)
 		}
+		h.markVideosUnavailable(ctx, missingIDs)
 		return fmt.Errorf("no data returned for video %s", payload.VideoID)
 	}
 
@@ -126,7 +259,19 @@ func (h *EnrichmentHandler) ProcessTask(ctx context.Context, task *asynq.Task) e
 	enrichment := enrichments[0]
 	enrichment.QuotaCost = quotaCost
 
-	if err := h.enrichmentRepo.CreateEnrichment(ctx, enrichment); err != nil {
+	// Live streams and premieres have no final stats yet; defer or skip them per policy
+	if handled, err := h.handleLiveVideo(ctx, job, payload, enrichment); handled {
+		return err
+	}
+
+	// Videos outside the privacy allowlist are only recognised once fetched
+	if h.skipDisallowedPrivacy(ctx, job, payload, enrichment) {
+		return nil
+	}
+
+	enrichment.ChannelSubscriberCountAtEnrichment = h.channelSubscriberSnapshot(ctx, payload.ChannelID)
+
+	if err := h.storeVideoEnrichment(ctx, enrichment); err != nil {
 		// Record failure in job
 		if job != nil {
 			h.jobRepo.MarkJobFailed(ctx, job.ID, err.Error(), nil)
@@ -134,6 +279,8 @@ func (h *EnrichmentHandler) ProcessTask(ctx context.Context, task *asynq.Task) e
 		return fmt.Errorf("failed to store enrichment: %w", err)
 	}
 
+	h.backfillChannelTitle(ctx, payload.ChannelID, enrichment)
+
 	// Note: Quota tracking is now handled automatically by the YouTube client
 	// when FetchVideos() is called (via the QuotaTracker interface),
 	// so we don't need to manually record quota usage here to avoid double-counting.
@@ -155,6 +302,94 @@ func (h *EnrichmentHandler) ProcessTask(ctx context.Context, task *asynq.Task) e
 	return nil
 }
 
+// reserveQuota reserves usage for a task's calls, returning the context that carries the
+// reservation for the calls; the caller releases it once they are made. It returns an error when
+// the quota has no room for a task of the given type, counting the rejection in QuotaBlockedTasks.
+func (h *EnrichmentHandler) reserveQuota(ctx context.Context, taskType string, usage quota.Usage) (context.Context, error) {
+	ctx, reserved, err := h.quotaManager.ReserveUsage(ctx, quota.PriorityBackground, usage)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to check quota: %w", err)
+	}
+
+	if !reserved {
+		QuotaBlockedTasks.Add(taskType, 1)
+		quotaInfo, err := h.quotaManager.GetQuotaInfo(ctx)
+		if err != nil {
+			return ctx, fmt.Errorf("quota exhausted")
+		}
+		log.Printf("[Handler] Quota exhausted or threshold reached: %d/%d used", quotaInfo.QuotaUsed, quotaInfo.QuotaLimit)
+		// Return non-retryable error to avoid hammering the quota
+		return ctx, fmt.Errorf("quota exhausted: %d/%d used", quotaInfo.QuotaUsed, quotaInfo.QuotaLimit)
+	}
+
+	return ctx, nil
+}
+
+// trackVideoJob looks up the job row for a video enrichment task, creating one linked to the
+// asynq task ID if none exists (e.g. the row insert failed after enqueueing, or the task was
+// enqueued outside the client), and marks it as processing. Returns nil if tracking fails.
+func (h *EnrichmentHandler) trackVideoJob(ctx context.Context, taskID string, payload *EnrichVideoPayload) *model.EnrichmentJob {
+	job, err := h.jobRepo.GetJobByAsynqID(ctx, taskID)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			log.Printf("[Handler] Warning: could not find job in database: %v", err)
+			// Continue processing even if job tracking fails
+			return nil
+		}
+
+		metadata := map[string]interface{}{}
+		for k, v := range payload.Metadata {
+			metadata[k] = v
+		}
+		metadata["channel_id"] = payload.ChannelID
+		metadata["untracked_task"] = true
+
+		job = &model.EnrichmentJob{
+			AsynqTaskID: strPtr(taskID),
+			JobType:     TypeEnrichVideo,
+			VideoID:     payload.VideoID,
+			Status:      "pending",
+			Priority:    payload.Priority,
+			ScheduledAt: time.Now(),
+			MaxAttempts: 3,
+			Metadata:    metadata,
+		}
+		if err := h.jobRepo.CreateJob(ctx, job); err != nil {
+			log.Printf("[Handler] Warning: failed to create missing job for task %s: %v", taskID, err)
+			return nil
+		}
+		log.Printf("[Handler] Created missing job record: job_id=%d, task_id=%s", job.ID, taskID)
+	}
+
+	if err := h.jobRepo.MarkJobProcessing(ctx, job.ID); err != nil {
+		log.Printf("[Handler] Warning: failed to mark job as processing: %v", err)
+	}
+	// Count every run, as asynq retries the same job after a transient failure
+	if err := h.jobRepo.IncrementAttempts(ctx, job.ID); err != nil {
+		log.Printf("[Handler] Warning: failed to count job attempt: %v", err)
+	}
+
+	return job
+}
+
+// channelSubscriberSnapshot returns the subscriber count from the channel's latest enrichment,
+// or nil if the channel has not been enriched or the lookup fails
+func (h *EnrichmentHandler) channelSubscriberSnapshot(ctx context.Context, channelID string) *int64 {
+	if h.channelEnrichmentRepo == nil || channelID == "" {
+		return nil
+	}
+
+	channelEnrichment, err := h.channelEnrichmentRepo.GetLatest(ctx, channelID)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			log.Printf("[Handler] Warning: failed to get channel enrichment for subscriber snapshot: channel_id=%s, error=%v", channelID, err)
+		}
+		return nil
+	}
+
+	return channelEnrichment.SubscriberCount
+}
+
 // HandleEnrichVideoTask returns an asynq.HandlerFunc for video enrichment
 func (h *EnrichmentHandler) HandleEnrichVideoTask() asynq.HandlerFunc {
 	return h.ProcessTask
@@ -183,20 +418,17 @@ func (h *EnrichmentHandler) HandleEnrichChannelTask() asynq.HandlerFunc {
 			if err := h.jobRepo.MarkJobProcessing(ctx, job.ID); err != nil {
 				log.Printf("[Handler] Warning: failed to mark job as processing: %v", err)
 			}
+			if err := h.jobRepo.IncrementAttempts(ctx, job.ID); err != nil {
+				log.Printf("[Handler] Warning: failed to count job attempt: %v", err)
+			}
 		}
 
-		// Check quota availability
-		// Estimate: 1 unit per channel enrichment (channels.list API call)
-		available, quotaInfo, err := h.quotaManager.CheckQuotaAvailable(ctx, 1)
+		// Reserve quota for the channels.list call
+		ctx, err = h.reserveQuota(ctx, TypeEnrichChannel, quota.Usage{quota.OpChannelsList: h.youtubeClient.QuotaCost(quota.OpChannelsList)})
 		if err != nil {
-			return fmt.Errorf("failed to check quota: %w", err)
-		}
-
-		if !available {
-			log.Printf("[Handler] Quota exhausted or threshold reached: %d/%d used", quotaInfo.QuotaUsed, quotaInfo.QuotaLimit)
-			// Return non-retryable error to avoid hammering the quota
-			return fmt.Errorf("quota exhausted: %d/%d used", quotaInfo.QuotaUsed, quotaInfo.QuotaLimit)
+			return err
 		}
+		defer quota.Release(ctx)
 
 		// Fetch channel data from YouTube API
 		ytEnrichment, err := h.youtubeClient.GetChannelDetails(ctx, payload.ChannelID)
@@ -212,7 +444,7 @@ func (h *EnrichmentHandler) HandleEnrichChannelTask() asynq.HandlerFunc {
 		enrichment := mapYouTubeChannelEnrichmentToModel(ytEnrichment)
 
 		// Store enrichment in database
-		if err := h.channelEnrichmentRepo.Create(ctx, enrichment); err != nil {
+		if err := h.storeChannelEnrichment(ctx, enrichment); err != nil {
 			// Record failure in job
 			if job != nil {
 				h.jobRepo.MarkJobFailed(ctx, job.ID, err.Error(), nil)
@@ -220,6 +452,8 @@ func (h *EnrichmentHandler) HandleEnrichChannelTask() asynq.HandlerFunc {
 			return fmt.Errorf("failed to store channel enrichment: %w", err)
 		}
 
+		h.recordChannelTitleChange(ctx, payload.ChannelID, ytEnrichment.Title)
+
 		// Note: Quota tracking is now handled automatically by the YouTube client
 		// when GetChannelDetails() is called (via the QuotaTracker interface),
 		// so we don't need to manually record quota usage here to avoid double-counting.
@@ -236,6 +470,13 @@ func (h *EnrichmentHandler) HandleEnrichChannelTask() asynq.HandlerFunc {
 	}
 }
 
+// sponsorAnalyzer is the part of the Ollama client used by sponsor detection tasks
+type sponsorAnalyzer interface {
+	AnalyzeVideoForSponsors(ctx context.Context, title, description string) (*models.LLMAnalysisResponse, string, error)
+	AnalyzeVideoForSponsorsWithModel(ctx context.Context, model, title, description string) (*models.LLMAnalysisResponse, string, error)
+	GetPromptText(title, description string) string
+}
+
 // HandleSponsorDetectionTask handles sponsor detection tasks
 func (h *EnrichmentHandler) HandleSponsorDetectionTask() asynq.HandlerFunc {
 	return func(ctx context.Context, task *asynq.Task) error {
@@ -248,86 +489,116 @@ func (h *EnrichmentHandler) HandleSponsorDetectionTask() asynq.HandlerFunc {
 		log.Printf("[Handler] Processing sponsor detection: video_id=%s, detection_job_id=%s, task_id=%s",
 			payload.VideoID, payload.DetectionJobID, task.ResultWriter().TaskID())
 
-		// Skip if description is empty
-		if payload.Description == "" {
-			log.Printf("[Handler] Skipping sponsor detection for video %s: no description", payload.VideoID)
+		return h.detectSponsors(ctx, payload)
+	}
+}
 
-			// Mark job as skipped in database
-			if payload.DetectionJobID != "" {
-				if jobID, err := parseUUID(payload.DetectionJobID); err == nil {
-					h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, jobID, "skipped", strPtr("No description available"))
-				}
-			}
+// detectSponsors runs sponsor detection for one task payload and saves the results
+func (h *EnrichmentHandler) detectSponsors(ctx context.Context, payload *SponsorDetectionPayload) error {
+	// Skip if description is empty
+	if payload.Description == "" {
+		log.Printf("[Handler] Skipping sponsor detection for video %s: no description", payload.VideoID)
 
-			// Don't retry - this is expected for videos without descriptions
-			return nil
-		}
-
-		// Parse detection job ID
-		detectionJobID, err := parseUUID(payload.DetectionJobID)
-		if err != nil {
-			return fmt.Errorf("invalid detection job ID: %w", err)
+		// Mark job as skipped in database
+		if payload.DetectionJobID != "" {
+			if jobID, err := parseUUID(payload.DetectionJobID); err == nil {
+				h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, jobID, "skipped", strPtr("No description available"))
+			}
 		}
 
-		// Cast ollama client
-		ollamaClient, ok := h.ollamaClient.(interface {
-			AnalyzeVideoForSponsors(context.Context, string, string) (*models.LLMAnalysisResponse, string, error)
-			GetPromptText(string, string) string
-		})
-		if !ok || ollamaClient == nil {
-			errMsg := "ollama client not configured"
-			h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, detectionJobID, "failed", &errMsg)
-			return fmt.Errorf("ollama client not configured")
-		}
+		// Don't retry - this is expected for videos without descriptions
+		return nil
+	}
 
-		// Start timing
-		startTime := time.Now()
+	// Parse detection job ID
+	detectionJobID, err := parseUUID(payload.DetectionJobID)
+	if err != nil {
+		return fmt.Errorf("invalid detection job ID: %w", err)
+	}
 
-		// Get the prompt text for storage
-		promptText := ollamaClient.GetPromptText(payload.Title, payload.Description)
+	// Cast ollama client
+	ollamaClient, ok := h.ollamaClient.(sponsorAnalyzer)
+	if !ok || ollamaClient == nil {
+		errMsg := "ollama client not configured"
+		h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, detectionJobID, "failed", &errMsg)
+		return fmt.Errorf("ollama client not configured")
+	}
 
-		// Get or create prompt in database (for deduplication)
-		prompt, err := h.sponsorDetectionRepo.GetOrCreatePrompt(ctx, promptText, "v1.0", "Initial sponsor detection prompt")
-		if err != nil {
-			errMsg := fmt.Sprintf("failed to get/create prompt: %v", err)
+	// A per-task model override replaces the configured model and is recorded on the job
+	if payload.Model != "" {
+		if err := models.ValidateLLMModelName(payload.Model); err != nil {
+			errMsg := fmt.Sprintf("invalid model override: %v", err)
 			h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, detectionJobID, "failed", &errMsg)
-			return fmt.Errorf("failed to get/create prompt: %w", err)
+			return fmt.Errorf("invalid model override: %v: %w", err, asynq.SkipRetry)
 		}
-
-		// Call Ollama LLM for sponsor analysis
-		analysisResp, rawResponse, err := ollamaClient.AnalyzeVideoForSponsors(ctx, payload.Title, payload.Description)
-		if err != nil {
-			// Check if it's a timeout or connection error (should retry)
-			errMsg := err.Error()
-			h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, detectionJobID, "failed", &errMsg)
-			return fmt.Errorf("failed to analyze video for sponsors: %w", err)
+		if err := h.sponsorDetectionRepo.UpdateDetectionJobModel(ctx, detectionJobID, payload.Model); err != nil {
+			return fmt.Errorf("failed to record model override: %w", err)
 		}
+	}
 
-		// Calculate processing time
-		processingTimeMs := int(time.Since(startTime).Milliseconds())
+	// Start timing
+	startTime := time.Now()
 
-		// Save detection results atomically (creates sponsors, video_sponsors, updates job)
-		err = h.sponsorDetectionRepo.SaveDetectionResults(
-			ctx,
-			detectionJobID,
-			payload.VideoID,
-			&prompt.ID,
-			analysisResp.Sponsors,
-			rawResponse,
-			processingTimeMs,
-		)
+	// Get the prompt text for storage
+	promptText := ollamaClient.GetPromptText(payload.Title, payload.Description)
 
-		if err != nil {
-			errMsg := fmt.Sprintf("failed to save detection results: %v", err)
-			h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, detectionJobID, "failed", &errMsg)
-			return fmt.Errorf("failed to save detection results: %w", err)
+	// Get or create prompt in database (for deduplication)
+	prompt, err := h.sponsorDetectionRepo.GetOrCreatePrompt(ctx, promptText, "v1.0", "Initial sponsor detection prompt")
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to get/create prompt: %v", err)
+		h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, detectionJobID, "failed", &errMsg)
+		return fmt.Errorf("failed to get/create prompt: %w", err)
+	}
+
+	// Call Ollama LLM for sponsor analysis
+	var analysisResp *models.LLMAnalysisResponse
+	var rawResponse string
+	if payload.Model != "" {
+		analysisResp, rawResponse, err = ollamaClient.AnalyzeVideoForSponsorsWithModel(ctx, payload.Model, payload.Title, payload.Description)
+	} else {
+		analysisResp, rawResponse, err = ollamaClient.AnalyzeVideoForSponsors(ctx, payload.Title, payload.Description)
+	}
+	if err != nil {
+		// The task context may be what ended the call, so the failure is recorded without it
+		errMsg := err.Error()
+		h.sponsorDetectionRepo.UpdateDetectionJobStatus(context.WithoutCancel(ctx), detectionJobID, "failed", &errMsg)
+
+		// An unreachable server is retried after a growing delay (see retryDelay) and
+		// counts towards pausing the queue
+		if ollama.IsUnavailable(err) && h.sponsorBreaker != nil {
+			h.sponsorBreaker.RecordFailure()
 		}
+		return fmt.Errorf("failed to analyze video for sponsors: %w", err)
+	}
 
-		log.Printf("[Handler] Successfully completed sponsor detection: video_id=%s, sponsors_detected=%d, processing_time_ms=%d",
-			payload.VideoID, len(analysisResp.Sponsors), processingTimeMs)
+	if h.sponsorBreaker != nil {
+		h.sponsorBreaker.RecordSuccess()
+	}
 
-		return nil
+	// Calculate processing time
+	processingTimeMs := int(time.Since(startTime).Milliseconds())
+
+	// Save detection results atomically (creates sponsors, video_sponsors, updates job)
+	err = h.sponsorDetectionRepo.SaveDetectionResults(
+		ctx,
+		detectionJobID,
+		payload.VideoID,
+		&prompt.ID,
+		analysisResp.Sponsors,
+		rawResponse,
+		processingTimeMs,
+	)
+
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to save detection results: %v", err)
+		h.sponsorDetectionRepo.UpdateDetectionJobStatus(ctx, detectionJobID, "failed", &errMsg)
+		return fmt.Errorf("failed to save detection results: %w", err)
 	}
+
+	log.Printf("[Handler] Successfully completed sponsor detection: video_id=%s, sponsors_detected=%d, processing_time_ms=%d",
+		payload.VideoID, len(analysisResp.Sponsors), processingTimeMs)
+
+	return nil
 }
 
 // parseUUID is a helper to parse UUID strings
@@ -365,6 +636,7 @@ func NewServer(redisAddr string, concurrency int, handler *EnrichmentHandler) (*
 			Concurrency:    concurrency,
 			Queues:         queues,
 			StrictPriority: false, // Process all queues fairly
+			RetryDelayFunc: retryDelay,
 			// Error handler
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
 				log.Printf("[Server] Task failed: type=%s, error=%v", task.Type(), err)
@@ -0,0 +1,499 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/ollama"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChannelEnrichmentRepo returns the most recent enrichment it holds for a channel
+type mockChannelEnrichmentRepo struct {
+	repository.ChannelEnrichmentRepository
+	enrichments []*model.ChannelEnrichment
+}
+
+func (m *mockChannelEnrichmentRepo) GetLatest(ctx context.Context, channelID string) (*model.ChannelEnrichment, error) {
+	var latest *model.ChannelEnrichment
+	for _, e := range m.enrichments {
+		if e.ChannelID != channelID {
+			continue
+		}
+		if latest == nil || e.EnrichedAt.After(latest.EnrichedAt) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return nil, db.ErrNotFound
+	}
+	return latest, nil
+}
+
+func int64PtrTo(v int64) *int64 {
+	return &v
+}
+
+func boolPtrTo(b bool) *bool {
+	return &b
+}
+
+func TestChannelSubscriberSnapshot(t *testing.T) {
+	now := time.Now()
+	repo := &mockChannelEnrichmentRepo{
+		enrichments: []*model.ChannelEnrichment{
+			{ChannelID: "UC123", EnrichedAt: now.Add(-48 * time.Hour), SubscriberCount: int64PtrTo(1000)},
+			{ChannelID: "UC123", EnrichedAt: now.Add(-time.Hour), SubscriberCount: int64PtrTo(1500)},
+			{ChannelID: "UChidden", EnrichedAt: now, HiddenSubscriberCount: boolPtrTo(true)},
+		},
+	}
+	h := &EnrichmentHandler{channelEnrichmentRepo: repo}
+
+	t.Run("uses latest channel enrichment", func(t *testing.T) {
+		got := h.channelSubscriberSnapshot(context.Background(), "UC123")
+		if got == nil || *got != 1500 {
+			t.Errorf("expected subscriber count 1500, got %v", got)
+		}
+	})
+
+	t.Run("channel without enrichment", func(t *testing.T) {
+		if got := h.channelSubscriberSnapshot(context.Background(), "UCunknown"); got != nil {
+			t.Errorf("expected nil subscriber count, got %d", *got)
+		}
+	})
+
+	t.Run("channel hiding its subscriber count", func(t *testing.T) {
+		if got := h.channelSubscriberSnapshot(context.Background(), "UChidden"); got != nil {
+			t.Errorf("expected nil subscriber count, got %d", *got)
+		}
+	})
+}
+
+// fakeSponsorAnalyzer records the model each analysis ran with
+type fakeSponsorAnalyzer struct {
+	defaultModel string
+	usedModels   []string
+	err          error
+}
+
+func (f *fakeSponsorAnalyzer) AnalyzeVideoForSponsors(ctx context.Context, title, description string) (*models.LLMAnalysisResponse, string, error) {
+	return f.AnalyzeVideoForSponsorsWithModel(ctx, f.defaultModel, title, description)
+}
+
+func (f *fakeSponsorAnalyzer) AnalyzeVideoForSponsorsWithModel(ctx context.Context, model, title, description string) (*models.LLMAnalysisResponse, string, error) {
+	f.usedModels = append(f.usedModels, model)
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	return &models.LLMAnalysisResponse{}, `{"sponsors":[]}`, nil
+}
+
+func (f *fakeSponsorAnalyzer) GetPromptText(title, description string) string {
+	return "prompt: " + title
+}
+
+// fakeSponsorDetectionRepo keeps detection jobs in memory
+type fakeSponsorDetectionRepo struct {
+	repository.SponsorDetectionRepository
+	jobs map[uuid.UUID]*models.SponsorDetectionJob
+}
+
+func (f *fakeSponsorDetectionRepo) GetOrCreatePrompt(ctx context.Context, promptText, version, description string) (*models.SponsorDetectionPrompt, error) {
+	return &models.SponsorDetectionPrompt{ID: uuid.New(), PromptText: promptText}, nil
+}
+
+func (f *fakeSponsorDetectionRepo) UpdateDetectionJobModel(ctx context.Context, jobID uuid.UUID, llmModel string) error {
+	f.jobs[jobID].LLMModel = llmModel
+	return nil
+}
+
+func (f *fakeSponsorDetectionRepo) UpdateDetectionJobStatus(ctx context.Context, jobID uuid.UUID, status string, errorMsg *string) error {
+	f.jobs[jobID].Status = status
+	f.jobs[jobID].ErrorMessage = errorMsg
+	return nil
+}
+
+func (f *fakeSponsorDetectionRepo) SaveDetectionResults(ctx context.Context, jobID uuid.UUID, videoID string, promptID *uuid.UUID, llmResults []models.LLMSponsorResult, llmRawResponse string, processingTimeMs int) error {
+	f.jobs[jobID].Status = "completed"
+	return nil
+}
+
+func TestDetectSponsors_ModelOverride(t *testing.T) {
+	newHandler := func() (*EnrichmentHandler, *fakeSponsorAnalyzer, *fakeSponsorDetectionRepo, uuid.UUID) {
+		jobID := uuid.New()
+		repo := &fakeSponsorDetectionRepo{jobs: map[uuid.UUID]*models.SponsorDetectionJob{
+			jobID: {ID: jobID, VideoID: "video123", LLMModel: "llama3:8b", Status: "pending"},
+		}}
+		analyzer := &fakeSponsorAnalyzer{defaultModel: "llama3:8b"}
+		return &EnrichmentHandler{ollamaClient: analyzer, sponsorDetectionRepo: repo}, analyzer, repo, jobID
+	}
+
+	payload := func(jobID uuid.UUID, llmModel string) *SponsorDetectionPayload {
+		return &SponsorDetectionPayload{
+			VideoID:        "video123",
+			Title:          "Test Video",
+			Description:    "This video is sponsored by NordVPN",
+			DetectionJobID: jobID.String(),
+			Model:          llmModel,
+		}
+	}
+
+	t.Run("override is used and recorded on the job", func(t *testing.T) {
+		h, analyzer, repo, jobID := newHandler()
+
+		require.NoError(t, h.detectSponsors(context.Background(), payload(jobID, "qwen2.5:14b")))
+
+		assert.Equal(t, []string{"qwen2.5:14b"}, analyzer.usedModels)
+		assert.Equal(t, "qwen2.5:14b", repo.jobs[jobID].LLMModel)
+		assert.Equal(t, "completed", repo.jobs[jobID].Status)
+	})
+
+	t.Run("configured model without override", func(t *testing.T) {
+		h, analyzer, repo, jobID := newHandler()
+
+		require.NoError(t, h.detectSponsors(context.Background(), payload(jobID, "")))
+
+		assert.Equal(t, []string{"llama3:8b"}, analyzer.usedModels)
+		assert.Equal(t, "llama3:8b", repo.jobs[jobID].LLMModel)
+	})
+
+	t.Run("invalid override fails the job without retry", func(t *testing.T) {
+		h, analyzer, repo, jobID := newHandler()
+
+		err := h.detectSponsors(context.Background(), payload(jobID, "bad model; rm -rf"))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, asynq.SkipRetry)
+
+		assert.Empty(t, analyzer.usedModels)
+		assert.Equal(t, "failed", repo.jobs[jobID].Status)
+		assert.Equal(t, "llama3:8b", repo.jobs[jobID].LLMModel)
+	})
+}
+
+// fakeQueuePauser records pause and unpause calls
+type fakeQueuePauser struct {
+	mu       sync.Mutex
+	paused   []string
+	unpaused []string
+	// alreadyPaused reports queues as paused before any call, as after a restart
+	alreadyPaused bool
+}
+
+func (f *fakeQueuePauser) QueuePaused(queue string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.alreadyPaused || len(f.paused) > len(f.unpaused), nil
+}
+
+func (f *fakeQueuePauser) PauseQueue(queue string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = append(f.paused, queue)
+	return nil
+}
+
+func (f *fakeQueuePauser) UnpauseQueue(queue string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unpaused = append(f.unpaused, queue)
+	return nil
+}
+
+func TestRetryDelay_OllamaUnavailable(t *testing.T) {
+	task := asynq.NewTask(TypeSponsorDetection, nil)
+	unavailable := fmt.Errorf("failed to analyze video for sponsors: %w", &ollama.UnavailableError{Err: errors.New("connection refused")})
+
+	assert.Equal(t, llmUnavailableBaseDelay, retryDelay(0, unavailable, task))
+	assert.Equal(t, 2*llmUnavailableBaseDelay, retryDelay(1, unavailable, task))
+	assert.Equal(t, llmUnavailableMaxDelay, retryDelay(20, unavailable, task))
+
+	assert.False(t, ollama.IsUnavailable(errors.New("ollama API returned status 500")))
+}
+
+func TestDetectSponsors_CircuitBreaker(t *testing.T) {
+	jobID := uuid.New()
+	repo := &fakeSponsorDetectionRepo{jobs: map[uuid.UUID]*models.SponsorDetectionJob{
+		jobID: {ID: jobID, VideoID: "video123", LLMModel: "llama3:8b", Status: "pending"},
+	}}
+	analyzer := &fakeSponsorAnalyzer{defaultModel: "llama3:8b", err: &ollama.UnavailableError{Err: errors.New("connection refused")}}
+	pauser := &fakeQueuePauser{}
+
+	h := &EnrichmentHandler{ollamaClient: analyzer, sponsorDetectionRepo: repo}
+	h.SetSponsorDetectionBreaker(pauser, 3, 20*time.Millisecond)
+
+	payload := &SponsorDetectionPayload{
+		VideoID:        "video123",
+		Title:          "Test Video",
+		Description:    "This video is sponsored by NordVPN",
+		DetectionJobID: jobID.String(),
+	}
+
+	for i := 0; i < 2; i++ {
+		err := h.detectSponsors(context.Background(), payload)
+		require.Error(t, err)
+		assert.True(t, ollama.IsUnavailable(err), "connection refused should be retryable")
+		assert.NotErrorIs(t, err, asynq.SkipRetry)
+	}
+	assert.Empty(t, pauser.paused, "breaker should not trip before the threshold")
+
+	require.Error(t, h.detectSponsors(context.Background(), payload))
+	assert.Equal(t, []string{"sponsor_detection"}, pauser.paused)
+	assert.True(t, h.sponsorBreaker.Open())
+
+	require.Eventually(t, func() bool { return !h.sponsorBreaker.Open() }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"sponsor_detection"}, pauser.unpaused)
+
+	// Ollama is back: a success resets the count so a later outage needs the full threshold again
+	analyzer.err = nil
+	require.NoError(t, h.detectSponsors(context.Background(), payload))
+	analyzer.err = &ollama.UnavailableError{Err: errors.New("connection refused")}
+	require.Error(t, h.detectSponsors(context.Background(), payload))
+	assert.Len(t, pauser.paused, 1)
+}
+
+func TestCircuitBreaker_ReconcilesPauseFromPreviousProcess(t *testing.T) {
+	pauser := &fakeQueuePauser{alreadyPaused: true}
+
+	h := &EnrichmentHandler{}
+	h.SetSponsorDetectionBreaker(pauser, 3, 20*time.Millisecond)
+	assert.True(t, h.sponsorBreaker.Open(), "a pause found at startup is adopted")
+
+	require.Eventually(t, func() bool { return !h.sponsorBreaker.Open() }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"sponsor_detection"}, pauser.unpaused)
+	assert.Empty(t, pauser.paused)
+
+	running := &fakeQueuePauser{}
+	h.SetSponsorDetectionBreaker(running, 3, 20*time.Millisecond)
+	assert.False(t, h.sponsorBreaker.Open())
+}
+
+// fakeEnrichmentJobRepo stores enrichment jobs in memory keyed by ID
+type fakeEnrichmentJobRepo struct {
+	repository.EnrichmentJobRepository
+	jobs   map[int64]*model.EnrichmentJob
+	nextID int64
+}
+
+func (f *fakeEnrichmentJobRepo) GetJobByAsynqID(ctx context.Context, asynqTaskID string) (*model.EnrichmentJob, error) {
+	for _, job := range f.jobs {
+		if job.AsynqTaskID != nil && *job.AsynqTaskID == asynqTaskID {
+			return job, nil
+		}
+	}
+	return nil, db.ErrNotFound
+}
+
+func (f *fakeEnrichmentJobRepo) CreateJob(ctx context.Context, job *model.EnrichmentJob) error {
+	f.nextID++
+	job.ID = f.nextID
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeEnrichmentJobRepo) MarkJobProcessing(ctx context.Context, id int64) error {
+	f.jobs[id].Status = "processing"
+	return nil
+}
+
+func (f *fakeEnrichmentJobRepo) IncrementAttempts(ctx context.Context, id int64) error {
+	f.jobs[id].Attempts++
+	return nil
+}
+
+func (f *fakeEnrichmentJobRepo) MarkJobCompleted(ctx context.Context, id int64) error {
+	f.jobs[id].Status = "completed"
+	return nil
+}
+
+func TestTrackVideoJob_CreatesMissingJob(t *testing.T) {
+	jobRepo := &fakeEnrichmentJobRepo{jobs: map[int64]*model.EnrichmentJob{}}
+	h := &EnrichmentHandler{jobRepo: jobRepo}
+
+	payload := &EnrichVideoPayload{
+		VideoID:   "video123",
+		ChannelID: "UC123",
+		Priority:  5,
+		Metadata:  map[string]interface{}{"source": "manual"},
+	}
+
+	job := h.trackVideoJob(context.Background(), "task-abc", payload)
+	require.NotNil(t, job)
+	require.NoError(t, h.jobRepo.MarkJobCompleted(context.Background(), job.ID))
+
+	require.Len(t, jobRepo.jobs, 1)
+	stored, err := jobRepo.GetJobByAsynqID(context.Background(), "task-abc")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", stored.Status)
+	assert.Equal(t, TypeEnrichVideo, stored.JobType)
+	assert.Equal(t, "video123", stored.VideoID)
+	assert.Equal(t, 5, stored.Priority)
+	assert.Equal(t, "UC123", stored.Metadata["channel_id"])
+	assert.Equal(t, "manual", stored.Metadata["source"])
+	assert.Equal(t, true, stored.Metadata["untracked_task"])
+}
+
+func TestTrackVideoJob_UsesExistingJob(t *testing.T) {
+	existing := &model.EnrichmentJob{ID: 7, AsynqTaskID: strPtr("task-abc"), JobType: TypeEnrichVideo, VideoID: "video123", Status: "pending"}
+	jobRepo := &fakeEnrichmentJobRepo{jobs: map[int64]*model.EnrichmentJob{7: existing}, nextID: 7}
+	h := &EnrichmentHandler{jobRepo: jobRepo}
+
+	job := h.trackVideoJob(context.Background(), "task-abc", &EnrichVideoPayload{VideoID: "video123"})
+	require.NotNil(t, job)
+
+	assert.Equal(t, int64(7), job.ID)
+	assert.Equal(t, "processing", job.Status)
+	assert.Len(t, jobRepo.jobs, 1)
+}
+
+func TestTrackVideoJob_CountsAttemptsAcrossRetries(t *testing.T) {
+	existing := &model.EnrichmentJob{ID: 7, AsynqTaskID: strPtr("task-abc"), JobType: TypeEnrichVideo, VideoID: "video123", Status: "pending", MaxAttempts: 3}
+	jobRepo := &fakeEnrichmentJobRepo{jobs: map[int64]*model.EnrichmentJob{7: existing}, nextID: 7}
+	h := &EnrichmentHandler{jobRepo: jobRepo}
+	payload := &EnrichVideoPayload{VideoID: "video123"}
+
+	// asynq redelivers the same task ID on each retry
+	for i := 1; i <= 4; i++ {
+		job := h.trackVideoJob(context.Background(), "task-abc", payload)
+		require.NotNil(t, job)
+		assert.Equal(t, i, job.Attempts)
+	}
+
+	assert.Len(t, jobRepo.jobs, 1)
+	assert.Equal(t, 4, jobRepo.jobs[7].Attempts, "attempts is not capped by max_attempts")
+}
+
+// blockingEnrichmentRepo records how many CreateEnrichment calls run at once
+type blockingEnrichmentRepo struct {
+	repository.EnrichmentRepository
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	calls   int
+}
+
+func (b *blockingEnrichmentRepo) CreateEnrichment(ctx context.Context, enrichment *model.VideoEnrichment) error {
+	b.mu.Lock()
+	b.active++
+	b.calls++
+	if b.active > b.maxSeen {
+		b.maxSeen = b.active
+	}
+	b.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	b.mu.Lock()
+	b.active--
+	b.mu.Unlock()
+	return nil
+}
+
+func TestStoreVideoEnrichment_WriteConcurrencyLimit(t *testing.T) {
+	repo := &blockingEnrichmentRepo{}
+	h := &EnrichmentHandler{enrichmentRepo: repo}
+	h.SetWriteConcurrencyLimit(3)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, h.storeVideoEnrichment(context.Background(), &model.VideoEnrichment{VideoID: fmt.Sprintf("video%d", i)}))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 12, repo.calls)
+	assert.LessOrEqual(t, repo.maxSeen, 3)
+}
+
+func TestStoreVideoEnrichment_WaitingWriteRespectsContext(t *testing.T) {
+	h := &EnrichmentHandler{enrichmentRepo: &blockingEnrichmentRepo{}}
+	h.SetWriteConcurrencyLimit(1)
+
+	// Hold the only slot
+	release, err := h.acquireWriteSlot(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = h.storeVideoEnrichment(ctx, &model.VideoEnrichment{VideoID: "video123"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// fakeChannelRepo records title backfills for channels stored without a title and renames
+type fakeChannelRepo struct {
+	repository.ChannelRepository
+	titles  map[string]string
+	history []*models.ChannelNameChange
+}
+
+func (f *fakeChannelRepo) BackfillTitle(ctx context.Context, channelID, title string) (bool, error) {
+	if current, ok := f.titles[channelID]; !ok || current != "" {
+		return false, nil
+	}
+	f.titles[channelID] = title
+	return true, nil
+}
+
+func (f *fakeChannelRepo) RecordTitleChange(ctx context.Context, channelID, title string) (*models.ChannelNameChange, error) {
+	current, ok := f.titles[channelID]
+	if !ok || current == "" || current == title {
+		return nil, nil
+	}
+	f.titles[channelID] = title
+	change := &models.ChannelNameChange{ChannelID: channelID, OldTitle: current, NewTitle: title}
+	f.history = append(f.history, change)
+	return change, nil
+}
+
+func TestBackfillChannelTitle(t *testing.T) {
+	channelRepo := &fakeChannelRepo{titles: map[string]string{"UCuntitled": "", "UCtitled": "Existing Title"}}
+	h := &EnrichmentHandler{}
+	h.SetChannelRepository(channelRepo)
+
+	apiTitle := "API Title"
+	h.backfillChannelTitle(context.Background(), "UCuntitled", &model.VideoEnrichment{ChannelTitle: &apiTitle})
+	h.backfillChannelTitle(context.Background(), "UCtitled", &model.VideoEnrichment{ChannelTitle: &apiTitle})
+
+	assert.Equal(t, "API Title", channelRepo.titles["UCuntitled"])
+	assert.Equal(t, "Existing Title", channelRepo.titles["UCtitled"])
+
+	// Without a channel title in the enrichment there is nothing to backfill
+	channelRepo.titles["UCnotitle"] = ""
+	h.backfillChannelTitle(context.Background(), "UCnotitle", &model.VideoEnrichment{})
+	assert.Equal(t, "", channelRepo.titles["UCnotitle"])
+}
+
+func TestRecordChannelTitleChange(t *testing.T) {
+	channelRepo := &fakeChannelRepo{titles: map[string]string{"UCrenamed": "Old Title", "UCuntitled": ""}}
+	h := &EnrichmentHandler{}
+	h.SetChannelRepository(channelRepo)
+
+	h.recordChannelTitleChange(context.Background(), "UCrenamed", "New Title")
+
+	assert.Equal(t, "New Title", channelRepo.titles["UCrenamed"])
+	require.Len(t, channelRepo.history, 1)
+	assert.Equal(t, "UCrenamed", channelRepo.history[0].ChannelID)
+	assert.Equal(t, "Old Title", channelRepo.history[0].OldTitle)
+	assert.Equal(t, "New Title", channelRepo.history[0].NewTitle)
+
+	// An unchanged title and a channel stored without one are not renames
+	h.recordChannelTitleChange(context.Background(), "UCrenamed", "New Title")
+	h.recordChannelTitleChange(context.Background(), "UCuntitled", "API Title")
+	assert.Len(t, channelRepo.history, 1)
+}
@@ -0,0 +1,255 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// EnrichmentCallbackSignatureHeader carries the HMAC-SHA256 of the request body as "sha256=<hex>"
+const EnrichmentCallbackSignatureHeader = "X-Enrichment-Signature"
+
+// EnrichmentCompletedEvent is the event name sent in enrichment callback payloads
+const EnrichmentCompletedEvent = "video.enriched"
+
+// Defaults for HTTP enrichment callbacks
+const (
+	DefaultHTTPCallbackAttempts   = 3
+	DefaultHTTPCallbackRetryDelay = 2 * time.Second
+	DefaultHTTPCallbackTimeout    = 10 * time.Second
+	DefaultHTTPCallbackWorkers    = 4
+	DefaultHTTPCallbackQueueSize  = 1000
+)
+
+// HTTPCallbackConfig configures HTTP callbacks fired after each successful video enrichment
+type HTTPCallbackConfig struct {
+	URLs       []string
+	Secret     string        // HMAC-SHA256 signing key; empty sends the payload unsigned
+	Attempts   int           // Delivery attempts per URL (default: 3)
+	RetryDelay time.Duration // Delay before the first retry, doubled after each (default: 2s)
+	Timeout    time.Duration // Per-request timeout (default: 10s)
+	Workers    int           // Concurrent deliveries (default: 4)
+	QueueSize  int           // Deliveries waiting for a worker; more are dropped (default: 1000)
+}
+
+// EnrichmentCallbackPayload is the JSON body POSTed to enrichment callback URLs
+type EnrichmentCallbackPayload struct {
+	Event                string    `json:"event"`
+	VideoID              string    `json:"video_id"`
+	ChannelID            string    `json:"channel_id"`
+	ChannelTitle         *string   `json:"channel_title,omitempty"`
+	EnrichedAt           time.Time `json:"enriched_at"`
+	Duration             *string   `json:"duration,omitempty"`
+	ViewCount            *int64    `json:"view_count,omitempty"`
+	LikeCount            *int64    `json:"like_count,omitempty"`
+	CommentCount         *int64    `json:"comment_count,omitempty"`
+	CategoryID           *string   `json:"category_id,omitempty"`
+	PrivacyStatus        *string   `json:"privacy_status,omitempty"`
+	LiveBroadcastContent *string   `json:"live_broadcast_content,omitempty"`
+}
+
+// NewEnrichmentCallbackPayload summarizes an enrichment for callback delivery
+func NewEnrichmentCallbackPayload(videoID, channelID string, enrichment *model.VideoEnrichment) EnrichmentCallbackPayload {
+	return EnrichmentCallbackPayload{
+		Event:                EnrichmentCompletedEvent,
+		VideoID:              videoID,
+		ChannelID:            channelID,
+		ChannelTitle:         enrichment.ChannelTitle,
+		EnrichedAt:           enrichment.EnrichedAt,
+		Duration:             enrichment.Duration,
+		ViewCount:            enrichment.ViewCount,
+		LikeCount:            enrichment.LikeCount,
+		CommentCount:         enrichment.CommentCount,
+		CategoryID:           enrichment.CategoryID,
+		PrivacyStatus:        enrichment.PrivacyStatus,
+		LiveBroadcastContent: enrichment.LiveBroadcastContent,
+	}
+}
+
+// HTTPCallbackNotifier POSTs an enrichment summary to configured URLs. Delivery is best effort:
+// a fixed pool of workers delivers in the background so enrichment workers are not held up,
+// retries failed requests, and logs and drops a delivery once its attempts are used up or
+// when the queue is full. Call Shutdown to stop the workers.
+type HTTPCallbackNotifier struct {
+	config     HTTPCallbackConfig
+	httpClient *http.Client
+
+	pending chan callbackDelivery
+	workers sync.WaitGroup
+
+	// pendingMu guards sends on pending against Shutdown closing it; closed is set under it
+	pendingMu sync.RWMutex
+	closed    bool
+	// stopDeliveries cancels in-flight deliveries and their retries when Shutdown runs out of time
+	deliveryCtx    context.Context
+	stopDeliveries context.CancelFunc
+}
+
+// callbackDelivery is one payload waiting to be POSTed to one URL
+type callbackDelivery struct {
+	url     string
+	videoID string
+	body    []byte
+}
+
+// NewHTTPCallbackNotifier creates a notifier for the configured URLs and starts its workers
+func NewHTTPCallbackNotifier(config HTTPCallbackConfig) *HTTPCallbackNotifier {
+	if config.Attempts <= 0 {
+		config.Attempts = DefaultHTTPCallbackAttempts
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultHTTPCallbackRetryDelay
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultHTTPCallbackTimeout
+	}
+	if config.Workers <= 0 {
+		config.Workers = DefaultHTTPCallbackWorkers
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultHTTPCallbackQueueSize
+	}
+
+	n := &HTTPCallbackNotifier{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		pending:    make(chan callbackDelivery, config.QueueSize),
+	}
+	n.deliveryCtx, n.stopDeliveries = context.WithCancel(context.Background())
+
+	for i := 0; i < config.Workers; i++ {
+		n.workers.Add(1)
+		go func() {
+			defer n.workers.Done()
+			for d := range n.pending {
+				if err := n.deliver(n.deliveryCtx, d.url, d.body); err != nil {
+					log.Printf("[Callbacks] Giving up on HTTP callback to %s for video %s: %v", d.url, d.videoID, err)
+				}
+			}
+		}()
+	}
+
+	return n
+}
+
+// Notify is an EnrichmentCallback that queues delivery to every URL and returns without waiting
+func (n *HTTPCallbackNotifier) Notify(ctx context.Context, videoID, channelID string, enrichment *model.VideoEnrichment) error {
+	body, err := json.Marshal(NewEnrichmentCallbackPayload(videoID, channelID, enrichment))
+	if err != nil {
+		return fmt.Errorf("marshal enrichment callback payload: %w", err)
+	}
+
+	n.pendingMu.RLock()
+	defer n.pendingMu.RUnlock()
+
+	for _, url := range n.config.URLs {
+		if n.closed {
+			log.Printf("[Callbacks] Dropping HTTP callback to %s for video %s: notifier is shut down", url, videoID)
+			continue
+		}
+		select {
+		case n.pending <- callbackDelivery{url: url, videoID: videoID, body: body}:
+		default:
+			log.Printf("[Callbacks] Dropping HTTP callback to %s for video %s: delivery queue is full", url, videoID)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting deliveries and waits for the queued ones to finish. If ctx ends
+// first, in-flight deliveries and their retries are cancelled, the rest of the queue is
+// dropped, and Shutdown waits for the workers to return before reporting ctx's error.
+func (n *HTTPCallbackNotifier) Shutdown(ctx context.Context) error {
+	n.pendingMu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.pending)
+	}
+	n.pendingMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		n.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		n.stopDeliveries()
+		return nil
+	case <-ctx.Done():
+		n.stopDeliveries()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// deliver POSTs body to url, retrying with exponential backoff. Deliveries outlive the
+// enrichment task, so they use the notifier's context rather than the task's.
+func (n *HTTPCallbackNotifier) deliver(ctx context.Context, url string, body []byte) error {
+	delay := n.config.RetryDelay
+	var lastErr error
+
+	for attempt := 1; attempt <= n.config.Attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		lastErr = n.post(ctx, url, body)
+		if lastErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return lastErr
+		}
+		log.Printf("[Callbacks] HTTP callback to %s failed (attempt %d/%d): %v", url, attempt, n.config.Attempts, lastErr)
+	}
+
+	return lastErr
+}
+
+func (n *HTTPCallbackNotifier) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.config.Secret != "" {
+		req.Header.Set(EnrichmentCallbackSignatureHeader, "sha256="+SignCallbackPayload(n.config.Secret, body))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SignCallbackPayload returns the hex HMAC-SHA256 of body, as sent in EnrichmentCallbackSignatureHeader
+func SignCallbackPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,160 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callbackRecorder is a callback endpoint that rejects its first `failures` requests
+type callbackRecorder struct {
+	mu         sync.Mutex
+	failures   int
+	requests   int
+	bodies     [][]byte
+	signatures []string
+}
+
+func (c *callbackRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	if c.requests <= c.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	c.bodies = append(c.bodies, body)
+	c.signatures = append(c.signatures, r.Header.Get(EnrichmentCallbackSignatureHeader))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestHTTPCallbackNotifier_FiresAfterEnrichment(t *testing.T) {
+	recorder := &callbackRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewHTTPCallbackNotifier(HTTPCallbackConfig{URLs: []string{server.URL}, Secret: "callback-secret"})
+	manager := NewCallbackManager()
+	manager.RegisterCallback(notifier.Notify)
+
+	viewCount := int64(1234)
+	channelTitle := "Test Channel"
+	enrichedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager.TriggerCallbacks(context.Background(), "video123", "UC123", &model.VideoEnrichment{
+		VideoID:      "video123",
+		ViewCount:    &viewCount,
+		ChannelTitle: &channelTitle,
+		EnrichedAt:   enrichedAt,
+	})
+	require.NoError(t, notifier.Shutdown(context.Background()))
+
+	require.Len(t, recorder.bodies, 1)
+	body := recorder.bodies[0]
+
+	var payload EnrichmentCallbackPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, EnrichmentCompletedEvent, payload.Event)
+	assert.Equal(t, "video123", payload.VideoID)
+	assert.Equal(t, "UC123", payload.ChannelID)
+	require.NotNil(t, payload.ViewCount)
+	assert.Equal(t, viewCount, *payload.ViewCount)
+	require.NotNil(t, payload.ChannelTitle)
+	assert.Equal(t, channelTitle, *payload.ChannelTitle)
+	assert.True(t, enrichedAt.Equal(payload.EnrichedAt))
+
+	assert.Equal(t, "sha256="+SignCallbackPayload("callback-secret", body), recorder.signatures[0])
+}
+
+func TestHTTPCallbackNotifier_RetriesFailedDelivery(t *testing.T) {
+	recorder := &callbackRecorder{failures: 2}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewHTTPCallbackNotifier(HTTPCallbackConfig{URLs: []string{server.URL}, RetryDelay: time.Millisecond})
+	require.NoError(t, notifier.Notify(context.Background(), "video123", "UC123", &model.VideoEnrichment{}))
+	require.NoError(t, notifier.Shutdown(context.Background()))
+
+	assert.Equal(t, 3, recorder.requests)
+	require.Len(t, recorder.bodies, 1)
+	assert.Empty(t, recorder.signatures[0], "payloads are unsigned without a secret")
+}
+
+func TestHTTPCallbackNotifier_GivesUpAfterAttempts(t *testing.T) {
+	recorder := &callbackRecorder{failures: 10}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewHTTPCallbackNotifier(HTTPCallbackConfig{URLs: []string{server.URL}, Attempts: 2, RetryDelay: time.Millisecond})
+	require.NoError(t, notifier.Notify(context.Background(), "video123", "UC123", &model.VideoEnrichment{}))
+	require.NoError(t, notifier.Shutdown(context.Background()))
+
+	assert.Equal(t, 2, recorder.requests)
+	assert.Empty(t, recorder.bodies)
+}
+
+func TestHTTPCallbackNotifier_ShutdownCancelsRetriesAfterTimeout(t *testing.T) {
+	recorder := &callbackRecorder{failures: 10}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := NewHTTPCallbackNotifier(HTTPCallbackConfig{URLs: []string{server.URL}, Attempts: 5, RetryDelay: time.Hour})
+	require.NoError(t, notifier.Notify(context.Background(), "video123", "UC123", &model.VideoEnrichment{}))
+	require.Eventually(t, func() bool {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		return recorder.requests == 1
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, notifier.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "a delivery waiting to retry does not hold up shutdown")
+
+	// Notifications after shutdown are dropped rather than sent on a closed queue
+	require.NoError(t, notifier.Notify(context.Background(), "video456", "UC123", &model.VideoEnrichment{}))
+	assert.Equal(t, 1, recorder.requests)
+}
+
+func TestHTTPCallbackNotifier_DropsWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	var requests int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewHTTPCallbackNotifier(HTTPCallbackConfig{URLs: []string{server.URL}, Workers: 1, QueueSize: 1})
+	for i := 0; i < 5; i++ {
+		require.NoError(t, notifier.Notify(context.Background(), "video123", "UC123", &model.VideoEnrichment{}))
+		if i == 0 {
+			// Wait for the worker to pick up the first delivery, so the queue has room for one more
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return requests == 1
+			}, time.Second, 5*time.Millisecond)
+		}
+	}
+
+	close(release)
+	require.NoError(t, notifier.Shutdown(context.Background()))
+	assert.Equal(t, 2, requests, "one delivery in flight and one queued; the rest are dropped")
+}
@@ -0,0 +1,166 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// LiveVideoPolicy controls how the enricher treats live streams and premieres,
+// which have no final stats at notification time
+type LiveVideoPolicy string
+
+const (
+	// LiveVideoPolicyEnrich enriches live and upcoming videos immediately
+	LiveVideoPolicyEnrich LiveVideoPolicy = "enrich"
+	// LiveVideoPolicyDefer re-enqueues live and upcoming videos for after they are expected to end
+	LiveVideoPolicyDefer LiveVideoPolicy = "defer"
+	// LiveVideoPolicySkip drops live and upcoming videos without storing an enrichment
+	LiveVideoPolicySkip LiveVideoPolicy = "skip"
+)
+
+// DefaultLiveRecheckDelay is how long a broadcast is assumed to last when its end time is unknown
+const DefaultLiveRecheckDelay = 2 * time.Hour
+
+// maxLiveDeferrals bounds how often a video is deferred before it is enriched anyway,
+// so streams stuck in "upcoming" are not re-enqueued forever
+const maxLiveDeferrals = 5
+
+// liveDeferralsKey is the task metadata key counting previous deferrals of a video
+const liveDeferralsKey = "live_deferrals"
+
+// VideoEnrichmentScheduler schedules a video enrichment task to run later
+type VideoEnrichmentScheduler interface {
+	ScheduleVideoEnrichment(ctx context.Context, videoID, channelID string, processAt time.Time, metadata map[string]interface{}) error
+}
+
+// ParseLiveVideoPolicy validates a live video policy name
+func ParseLiveVideoPolicy(s string) (LiveVideoPolicy, error) {
+	switch policy := LiveVideoPolicy(s); policy {
+	case LiveVideoPolicyEnrich, LiveVideoPolicyDefer, LiveVideoPolicySkip:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid live video policy %q (must be enrich, defer, or skip)", s)
+	}
+}
+
+// SetLiveVideoPolicy configures how live and upcoming videos are handled.
+// The scheduler is only used by LiveVideoPolicyDefer.
+func (h *EnrichmentHandler) SetLiveVideoPolicy(policy LiveVideoPolicy, recheckDelay time.Duration, scheduler VideoEnrichmentScheduler) {
+	if recheckDelay <= 0 {
+		recheckDelay = DefaultLiveRecheckDelay
+	}
+
+	h.livePolicy = policy
+	h.liveRecheckDelay = recheckDelay
+	h.liveScheduler = scheduler
+}
+
+// handleLiveVideo applies the live video policy to a freshly fetched enrichment.
+// It returns true when the video was deferred or skipped and must not be stored now.
+func (h *EnrichmentHandler) handleLiveVideo(ctx context.Context, job *model.EnrichmentJob, payload *EnrichVideoPayload, enrichment *model.VideoEnrichment) (bool, error) {
+	if h.livePolicy == "" || h.livePolicy == LiveVideoPolicyEnrich || !isLiveOrUpcoming(enrichment) {
+		return false, nil
+	}
+
+	reason := "live_broadcast_content=" + *enrichment.LiveBroadcastContent
+
+	if h.livePolicy == LiveVideoPolicySkip {
+		log.Printf("[Handler] Skipping enrichment of live video: video_id=%s, reason=%s", payload.VideoID, reason)
+		if job != nil {
+			if err := h.jobRepo.MarkJobSkipped(ctx, job.ID, reason); err != nil {
+				log.Printf("[Handler] Warning: failed to mark job as skipped: %v", err)
+			}
+		}
+		return true, nil
+	}
+
+	deferrals := liveDeferralCount(payload.Metadata)
+	if deferrals >= maxLiveDeferrals {
+		log.Printf("[Handler] Video still live after %d deferrals, enriching anyway: video_id=%s", deferrals, payload.VideoID)
+		return false, nil
+	}
+
+	if h.liveScheduler == nil {
+		log.Printf("[Handler] Warning: no scheduler configured for live video deferral, enriching immediately: video_id=%s", payload.VideoID)
+		return false, nil
+	}
+
+	now := time.Now()
+	processAt := liveRecheckTime(enrichment, h.liveRecheckDelay, now)
+
+	metadata := make(map[string]interface{}, len(payload.Metadata)+3)
+	for k, v := range payload.Metadata {
+		metadata[k] = v
+	}
+	metadata["source"] = "live_deferral"
+	metadata["enqueued_at"] = now.Format(time.RFC3339)
+	metadata[liveDeferralsKey] = deferrals + 1
+
+	if err := h.liveScheduler.ScheduleVideoEnrichment(ctx, payload.VideoID, payload.ChannelID, processAt, metadata); err != nil {
+		if job != nil {
+			h.jobRepo.MarkJobFailed(ctx, job.ID, err.Error(), nil)
+		}
+		return true, fmt.Errorf("failed to re-enqueue live video: %w", err)
+	}
+
+	if job != nil {
+		if err := h.jobRepo.MarkJobDeferred(ctx, job.ID, reason, processAt); err != nil {
+			log.Printf("[Handler] Warning: failed to mark job as deferred: %v", err)
+		}
+	}
+
+	log.Printf("[Handler] Deferred enrichment of live video: video_id=%s, reason=%s, process_at=%s",
+		payload.VideoID, reason, processAt.Format(time.RFC3339))
+
+	return true, nil
+}
+
+// isLiveOrUpcoming reports whether the video is a broadcast that has not finished yet
+func isLiveOrUpcoming(enrichment *model.VideoEnrichment) bool {
+	if enrichment.LiveBroadcastContent == nil {
+		return false
+	}
+	switch *enrichment.LiveBroadcastContent {
+	case "live", "upcoming":
+		return true
+	default:
+		return false
+	}
+}
+
+// liveRecheckTime estimates when a live or upcoming broadcast will have ended.
+// YouTube only reports start times until a broadcast ends, so the end is taken
+// to be recheckDelay after the actual (or scheduled) start.
+func liveRecheckTime(enrichment *model.VideoEnrichment, recheckDelay time.Duration, now time.Time) time.Time {
+	var start *time.Time
+	if enrichment.ActualStartTime != nil {
+		start = enrichment.ActualStartTime
+	} else if enrichment.ScheduledStartTime != nil {
+		start = enrichment.ScheduledStartTime
+	}
+
+	if start != nil {
+		if end := start.Add(recheckDelay); end.After(now) {
+			return end
+		}
+	}
+
+	return now.Add(recheckDelay)
+}
+
+// liveDeferralCount reads how many times a task has already been deferred.
+// Numbers decode from JSON as float64.
+func liveDeferralCount(metadata map[string]interface{}) int {
+	switch v := metadata[liveDeferralsKey].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,180 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// mockJobRepo records deferred and skipped jobs
+type mockJobRepo struct {
+	repository.EnrichmentJobRepository
+	deferredReason string
+	deferredUntil  time.Time
+	skippedReason  string
+}
+
+func (m *mockJobRepo) MarkJobDeferred(ctx context.Context, id int64, reason string, nextRetryAt time.Time) error {
+	m.deferredReason = reason
+	m.deferredUntil = nextRetryAt
+	return nil
+}
+
+func (m *mockJobRepo) MarkJobSkipped(ctx context.Context, id int64, reason string) error {
+	m.skippedReason = reason
+	return nil
+}
+
+// mockScheduler records scheduled enrichment tasks
+type mockScheduler struct {
+	calls     int
+	processAt time.Time
+	metadata  map[string]interface{}
+}
+
+func (m *mockScheduler) ScheduleVideoEnrichment(ctx context.Context, videoID, channelID string, processAt time.Time, metadata map[string]interface{}) error {
+	m.calls++
+	m.processAt = processAt
+	m.metadata = metadata
+	return nil
+}
+
+func strPtrTo(s string) *string {
+	return &s
+}
+
+func TestHandleLiveVideo_UpcomingVideoIsDeferred(t *testing.T) {
+	jobRepo := &mockJobRepo{}
+	scheduler := &mockScheduler{}
+
+	h := &EnrichmentHandler{jobRepo: jobRepo}
+	h.SetLiveVideoPolicy(LiveVideoPolicyDefer, time.Hour, scheduler)
+
+	scheduledStart := time.Now().Add(3 * time.Hour).Truncate(time.Second)
+	enrichment := &model.VideoEnrichment{
+		VideoID:              "premiere123",
+		LiveBroadcastContent: strPtrTo("upcoming"),
+		ScheduledStartTime:   &scheduledStart,
+	}
+	payload := &EnrichVideoPayload{VideoID: "premiere123", ChannelID: "UC123", Metadata: map[string]interface{}{}}
+
+	handled, err := h.handleLiveVideo(context.Background(), &model.EnrichmentJob{ID: 1}, payload, enrichment)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !handled {
+		t.Fatal("expected upcoming video to be deferred rather than enriched immediately")
+	}
+
+	if scheduler.calls != 1 {
+		t.Fatalf("expected 1 scheduled task, got %d", scheduler.calls)
+	}
+	wantProcessAt := scheduledStart.Add(time.Hour)
+	if !scheduler.processAt.Equal(wantProcessAt) {
+		t.Errorf("expected task scheduled for %v, got %v", wantProcessAt, scheduler.processAt)
+	}
+	if scheduler.metadata[liveDeferralsKey] != 1 {
+		t.Errorf("expected live_deferrals=1, got %v", scheduler.metadata[liveDeferralsKey])
+	}
+
+	if jobRepo.deferredReason != "live_broadcast_content=upcoming" {
+		t.Errorf("expected defer reason to be recorded, got %q", jobRepo.deferredReason)
+	}
+	if !jobRepo.deferredUntil.Equal(wantProcessAt) {
+		t.Errorf("expected job next retry %v, got %v", wantProcessAt, jobRepo.deferredUntil)
+	}
+}
+
+func TestHandleLiveVideo_Policies(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        LiveVideoPolicy
+		broadcast     *string
+		metadata      map[string]interface{}
+		wantHandled   bool
+		wantScheduled int
+		wantSkipped   bool
+	}{
+		{
+			name:        "enrich policy enriches live videos",
+			policy:      LiveVideoPolicyEnrich,
+			broadcast:   strPtrTo("live"),
+			wantHandled: false,
+		},
+		{
+			name:        "regular video is enriched",
+			policy:      LiveVideoPolicyDefer,
+			broadcast:   strPtrTo("none"),
+			wantHandled: false,
+		},
+		{
+			name:        "missing broadcast content is enriched",
+			policy:      LiveVideoPolicyDefer,
+			broadcast:   nil,
+			wantHandled: false,
+		},
+		{
+			name:          "live video is deferred",
+			policy:        LiveVideoPolicyDefer,
+			broadcast:     strPtrTo("live"),
+			wantHandled:   true,
+			wantScheduled: 1,
+		},
+		{
+			name:        "video deferred too often is enriched anyway",
+			policy:      LiveVideoPolicyDefer,
+			broadcast:   strPtrTo("upcoming"),
+			metadata:    map[string]interface{}{liveDeferralsKey: float64(maxLiveDeferrals)},
+			wantHandled: false,
+		},
+		{
+			name:        "skip policy skips upcoming videos",
+			policy:      LiveVideoPolicySkip,
+			broadcast:   strPtrTo("upcoming"),
+			wantHandled: true,
+			wantSkipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobRepo := &mockJobRepo{}
+			scheduler := &mockScheduler{}
+
+			h := &EnrichmentHandler{jobRepo: jobRepo}
+			h.SetLiveVideoPolicy(tt.policy, time.Hour, scheduler)
+
+			enrichment := &model.VideoEnrichment{VideoID: "video123", LiveBroadcastContent: tt.broadcast}
+			payload := &EnrichVideoPayload{VideoID: "video123", ChannelID: "UC123", Metadata: tt.metadata}
+
+			handled, err := h.handleLiveVideo(context.Background(), &model.EnrichmentJob{ID: 1}, payload, enrichment)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if handled != tt.wantHandled {
+				t.Errorf("expected handled=%v, got %v", tt.wantHandled, handled)
+			}
+			if scheduler.calls != tt.wantScheduled {
+				t.Errorf("expected %d scheduled tasks, got %d", tt.wantScheduled, scheduler.calls)
+			}
+			if (jobRepo.skippedReason != "") != tt.wantSkipped {
+				t.Errorf("expected skipped=%v, got reason %q", tt.wantSkipped, jobRepo.skippedReason)
+			}
+		})
+	}
+}
+
+func TestParseLiveVideoPolicy(t *testing.T) {
+	for _, valid := range []string{"enrich", "defer", "skip"} {
+		if _, err := ParseLiveVideoPolicy(valid); err != nil {
+			t.Errorf("expected %q to be valid, got %v", valid, err)
+		}
+	}
+
+	if _, err := ParseLiveVideoPolicy("later"); err == nil {
+		t.Error("expected error for invalid policy")
+	}
+}
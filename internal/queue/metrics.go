@@ -0,0 +1,7 @@
+package queue
+
+import "expvar"
+
+// QuotaBlockedTasks counts tasks rejected because the YouTube API quota threshold was
+// reached, keyed by task type. Published through expvar as enricher_quota_blocked_tasks.
+var QuotaBlockedTasks = expvar.NewMap("enricher_quota_blocked_tasks")
@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"context"
+	"expvar"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedQuotaRepo reports a fixed quota usage for today
+type fixedQuotaRepo struct {
+	repository.QuotaRepository
+	used int
+}
+
+func (f *fixedQuotaRepo) GetTodaysQuota(ctx context.Context) (*model.QuotaInfo, error) {
+	return &model.QuotaInfo{QuotaUsed: f.used, QuotaLimit: 10000}, nil
+}
+
+func (f *fixedQuotaRepo) IncrementQuota(ctx context.Context, quotaCost int, operationType string) error {
+	return nil
+}
+
+func quotaBlockedCount(taskType string) int64 {
+	if v, ok := QuotaBlockedTasks.Get(taskType).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestReserveQuota_CountsBlockedTasks(t *testing.T) {
+	videosBefore := quotaBlockedCount(TypeEnrichVideo)
+	channelsBefore := quotaBlockedCount(TypeEnrichChannel)
+
+	exhausted := &EnrichmentHandler{
+		quotaManager:  quota.NewManager(&fixedQuotaRepo{used: 9500}, 10000, 90),
+		youtubeClient: &youtube.Client{},
+	}
+	_, err := exhausted.reserveQuota(context.Background(), TypeEnrichVideo, quota.Usage{quota.OpVideosList: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "quota exhausted")
+
+	available := &EnrichmentHandler{
+		quotaManager:  quota.NewManager(&fixedQuotaRepo{used: 100}, 10000, 90),
+		youtubeClient: &youtube.Client{},
+	}
+	_, err = available.reserveQuota(context.Background(), TypeEnrichChannel, quota.Usage{quota.OpChannelsList: 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, videosBefore+1, quotaBlockedCount(TypeEnrichVideo))
+	assert.Equal(t, channelsBefore, quotaBlockedCount(TypeEnrichChannel), "tasks that get quota are not counted")
+}
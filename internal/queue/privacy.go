@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// ParsePrivacyStatuses parses a comma-separated list of privacy statuses to enrich.
+// An empty list allows every status.
+func ParsePrivacyStatuses(s string) ([]string, error) {
+	var statuses []string
+	for _, status := range strings.Split(s, ",") {
+		status = strings.ToLower(strings.TrimSpace(status))
+		switch status {
+		case "":
+			continue
+		case "public", "unlisted", "private":
+			statuses = append(statuses, status)
+		default:
+			return nil, fmt.Errorf("invalid privacy status %q (must be public, unlisted, or private)", status)
+		}
+	}
+	return statuses, nil
+}
+
+// SetPrivacyStatusAllowlist restricts enrichment to videos whose privacy status is one of
+// statuses; an empty list enriches every video
+func (h *EnrichmentHandler) SetPrivacyStatusAllowlist(statuses []string) {
+	if len(statuses) == 0 {
+		h.allowedPrivacyStatuses = nil
+		return
+	}
+
+	h.allowedPrivacyStatuses = make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		h.allowedPrivacyStatuses[status] = true
+	}
+}
+
+// skipDisallowedPrivacy marks the job skipped when a freshly fetched video's privacy status
+// is not allowlisted. Privacy is only known after the fetch, so the quota is already spent.
+// It returns true when the enrichment must not be stored.
+func (h *EnrichmentHandler) skipDisallowedPrivacy(ctx context.Context, job *model.EnrichmentJob, payload *EnrichVideoPayload, enrichment *model.VideoEnrichment) bool {
+	if h.allowedPrivacyStatuses == nil || enrichment.PrivacyStatus == nil || h.allowedPrivacyStatuses[*enrichment.PrivacyStatus] {
+		return false
+	}
+
+	reason := "privacy_status=" + *enrichment.PrivacyStatus
+
+	log.Printf("[Handler] Skipping enrichment of video with disallowed privacy status: video_id=%s, reason=%s", payload.VideoID, reason)
+	if job != nil {
+		if err := h.jobRepo.MarkJobSkipped(ctx, job.ID, reason); err != nil {
+			log.Printf("[Handler] Warning: failed to mark job as skipped: %v", err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+func TestSkipDisallowedPrivacy_PublicOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowlist   []string
+		privacy     *string
+		wantSkipped bool
+	}{
+		{
+			name:        "private video is skipped",
+			allowlist:   []string{"public"},
+			privacy:     strPtrTo("private"),
+			wantSkipped: true,
+		},
+		{
+			name:        "unlisted video is skipped",
+			allowlist:   []string{"public"},
+			privacy:     strPtrTo("unlisted"),
+			wantSkipped: true,
+		},
+		{
+			name:      "public video is enriched",
+			allowlist: []string{"public"},
+			privacy:   strPtrTo("public"),
+		},
+		{
+			name:      "missing privacy status is enriched",
+			allowlist: []string{"public"},
+		},
+		{
+			name:    "empty allowlist enriches private videos",
+			privacy: strPtrTo("private"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobRepo := &mockJobRepo{}
+
+			h := &EnrichmentHandler{jobRepo: jobRepo}
+			h.SetPrivacyStatusAllowlist(tt.allowlist)
+
+			enrichment := &model.VideoEnrichment{VideoID: "video123", PrivacyStatus: tt.privacy}
+			payload := &EnrichVideoPayload{VideoID: "video123", ChannelID: "UC123"}
+
+			skipped := h.skipDisallowedPrivacy(context.Background(), &model.EnrichmentJob{ID: 1}, payload, enrichment)
+			if skipped != tt.wantSkipped {
+				t.Errorf("expected skipped=%v, got %v", tt.wantSkipped, skipped)
+			}
+			if tt.wantSkipped && jobRepo.skippedReason != "privacy_status="+*tt.privacy {
+				t.Errorf("expected skip reason to be recorded, got %q", jobRepo.skippedReason)
+			}
+			if !tt.wantSkipped && jobRepo.skippedReason != "" {
+				t.Errorf("expected job not to be skipped, got reason %q", jobRepo.skippedReason)
+			}
+		})
+	}
+}
+
+func TestParsePrivacyStatuses(t *testing.T) {
+	statuses, err := ParsePrivacyStatuses(" Public, unlisted ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0] != "public" || statuses[1] != "unlisted" {
+		t.Errorf("expected [public unlisted], got %v", statuses)
+	}
+
+	if statuses, err := ParsePrivacyStatuses(""); err != nil || statuses != nil {
+		t.Errorf("expected empty list to allow every status, got %v, %v", statuses, err)
+	}
+
+	if _, err := ParsePrivacyStatuses("public,secret"); err == nil {
+		t.Error("expected an error for an unknown privacy status")
+	}
+}
@@ -2,7 +2,10 @@ package queue
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+
+	"github.com/hibiken/asynq"
 )
 
 // Task types
@@ -12,8 +15,42 @@ const (
 	TypeSponsorDetection = "sponsor_detection:video"
 )
 
+// Sources recorded in sponsor detection task metadata
+const (
+	SponsorDetectionSourceEnrichment = "enrichment_callback"
+	SponsorDetectionSourceAPI        = "api"
+)
+
+// CurrentPayloadVersion is the payload version written by this binary.
+//
+// Payloads without a version field were written before versioning was added and
+// have the same shape as version 1, so they are accepted as version 1. When a
+// payload's shape changes, bump CurrentPayloadVersion and teach the Unmarshal
+// helpers to upgrade the previous version, so tasks still queued by the old
+// binary keep working during a rolling deploy. Versions newer than this binary
+// understands (e.g. after a rollback) are rejected with ErrUnsupportedPayloadVersion,
+// which also wraps asynq.SkipRetry so the task is not retried.
+const CurrentPayloadVersion = 1
+
+// ErrUnsupportedPayloadVersion is returned when a task payload has a version this binary cannot handle
+var ErrUnsupportedPayloadVersion = errors.New("unsupported task payload version")
+
+// checkPayloadVersion normalizes a decoded payload version, treating unversioned payloads as version 1
+func checkPayloadVersion(taskType string, version int) (int, error) {
+	switch {
+	case version == 0:
+		return 1, nil
+	case version < 0 || version > CurrentPayloadVersion:
+		return 0, fmt.Errorf("%w: %s payload version %d (supported up to %d): %w",
+			ErrUnsupportedPayloadVersion, taskType, version, CurrentPayloadVersion, asynq.SkipRetry)
+	default:
+		return version, nil
+	}
+}
+
 // EnrichVideoPayload is the payload for video enrichment tasks
 type EnrichVideoPayload struct {
+	Version   int                    `json:"version"`
 	VideoID   string                 `json:"video_id"`
 	ChannelID string                 `json:"channel_id"`
 	Priority  int                    `json:"priority"`
@@ -31,6 +68,7 @@ func NewEnrichVideoTask(videoID, channelID string, priority int, metadata map[st
 	}
 
 	return &EnrichVideoPayload{
+		Version:   CurrentPayloadVersion,
 		VideoID:   videoID,
 		ChannelID: channelID,
 		Priority:  priority,
@@ -49,11 +87,19 @@ func UnmarshalEnrichVideoPayload(data []byte) (*EnrichVideoPayload, error) {
 	if err := json.Unmarshal(data, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
+
+	version, err := checkPayloadVersion(TypeEnrichVideo, payload.Version)
+	if err != nil {
+		return nil, err
+	}
+	payload.Version = version
+
 	return &payload, nil
 }
 
 // EnrichChannelPayload is the payload for channel enrichment tasks
 type EnrichChannelPayload struct {
+	Version   int                    `json:"version"`
 	ChannelID string                 `json:"channel_id"`
 	Priority  int                    `json:"priority"`
 	Metadata  map[string]interface{} `json:"metadata"`
@@ -70,6 +116,7 @@ func NewEnrichChannelTask(channelID string, priority int, metadata map[string]in
 	}
 
 	return &EnrichChannelPayload{
+		Version:   CurrentPayloadVersion,
 		ChannelID: channelID,
 		Priority:  priority,
 		Metadata:  metadata,
@@ -87,16 +134,28 @@ func UnmarshalEnrichChannelPayload(data []byte) (*EnrichChannelPayload, error) {
 	if err := json.Unmarshal(data, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
+
+	version, err := checkPayloadVersion(TypeEnrichChannel, payload.Version)
+	if err != nil {
+		return nil, err
+	}
+	payload.Version = version
+
 	return &payload, nil
 }
 
 // SponsorDetectionPayload is the payload for sponsor detection tasks
 type SponsorDetectionPayload struct {
+	Version        int                    `json:"version"`
 	VideoID        string                 `json:"video_id"`
 	Title          string                 `json:"title"`
 	Description    string                 `json:"description"`
 	DetectionJobID string                 `json:"detection_job_id"` // UUID as string
 	Metadata       map[string]interface{} `json:"metadata"`
+
+	// Model overrides the configured LLM model for this task when non-empty.
+	// Workers that predate the field ignore it and use their configured model.
+	Model string `json:"model,omitempty"`
 }
 
 // NewSponsorDetectionTask creates a new sponsor detection task payload
@@ -113,6 +172,7 @@ func NewSponsorDetectionTask(videoID, title, description, detectionJobID string,
 	}
 
 	return &SponsorDetectionPayload{
+		Version:        CurrentPayloadVersion,
 		VideoID:        videoID,
 		Title:          title,
 		Description:    description,
@@ -132,5 +192,12 @@ func UnmarshalSponsorDetectionPayload(data []byte) (*SponsorDetectionPayload, er
 	if err := json.Unmarshal(data, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
+
+	version, err := checkPayloadVersion(TypeSponsorDetection, payload.Version)
+	if err != nil {
+		return nil, err
+	}
+	payload.Version = version
+
 	return &payload, nil
 }
@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestUnmarshalEnrichVideoPayload_CurrentVersion(t *testing.T) {
+	payload, err := NewEnrichVideoTask("video123", "UC123", 1, nil)
+	if err != nil {
+		t.Fatalf("failed to create payload: %v", err)
+	}
+
+	data, err := payload.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	got, err := UnmarshalEnrichVideoPayload(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Version != CurrentPayloadVersion {
+		t.Errorf("expected version %d, got %d", CurrentPayloadVersion, got.Version)
+	}
+	if got.VideoID != "video123" || got.ChannelID != "UC123" || got.Priority != 1 {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+}
+
+func TestUnmarshalPayload_Versions(t *testing.T) {
+	unmarshalers := map[string]func([]byte) (int, error){
+		TypeEnrichVideo: func(data []byte) (int, error) {
+			p, err := UnmarshalEnrichVideoPayload(data)
+			if err != nil {
+				return 0, err
+			}
+			return p.Version, nil
+		},
+		TypeEnrichChannel: func(data []byte) (int, error) {
+			p, err := UnmarshalEnrichChannelPayload(data)
+			if err != nil {
+				return 0, err
+			}
+			return p.Version, nil
+		},
+		TypeSponsorDetection: func(data []byte) (int, error) {
+			p, err := UnmarshalSponsorDetectionPayload(data)
+			if err != nil {
+				return 0, err
+			}
+			return p.Version, nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		data        string
+		wantVersion int
+		wantErr     bool
+	}{
+		{
+			name:        "unversioned payload from an older binary",
+			data:        `{"video_id": "video123", "channel_id": "UC123", "detection_job_id": "job"}`,
+			wantVersion: 1,
+		},
+		{
+			name:        "current version",
+			data:        `{"version": 1, "video_id": "video123", "channel_id": "UC123", "detection_job_id": "job"}`,
+			wantVersion: 1,
+		},
+		{
+			name:    "unknown future version",
+			data:    `{"version": 99, "video_id": "video123", "channel_id": "UC123", "detection_job_id": "job"}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative version",
+			data:    `{"version": -1, "video_id": "video123", "channel_id": "UC123", "detection_job_id": "job"}`,
+			wantErr: true,
+		},
+	}
+
+	for taskType, unmarshal := range unmarshalers {
+		for _, tt := range tests {
+			t.Run(taskType+"/"+tt.name, func(t *testing.T) {
+				version, err := unmarshal([]byte(tt.data))
+
+				if tt.wantErr {
+					if err == nil {
+						t.Fatal("expected error, got nil")
+					}
+					if !errors.Is(err, ErrUnsupportedPayloadVersion) {
+						t.Errorf("expected ErrUnsupportedPayloadVersion, got %v", err)
+					}
+					if !errors.Is(err, asynq.SkipRetry) {
+						t.Errorf("expected error to wrap asynq.SkipRetry so the task is not retried, got %v", err)
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if version != tt.wantVersion {
+					t.Errorf("expected version %d, got %d", tt.wantVersion, version)
+				}
+			})
+		}
+	}
+}
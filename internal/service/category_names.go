@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CategoryNameTTL is how long a region's category list is cached before it is fetched again
+const CategoryNameTTL = 24 * time.Hour
+
+// CategoryNameErrorTTL is how long a failed fetch is remembered, so a failing API is not
+// called again for every video
+const CategoryNameErrorTTL = time.Minute
+
+// VideoCategoryFetcher fetches a region's video categories from the YouTube API
+type VideoCategoryFetcher interface {
+	FetchVideoCategories(ctx context.Context, regionCode string) (map[string]string, error)
+}
+
+// categoryList is one region's cached category ID to name table, or the error fetching it
+type categoryList struct {
+	names     map[string]string
+	err       error
+	fetchedAt time.Time
+}
+
+// CategoryNameCache resolves video category IDs to their human-readable names.
+// Each region's categories are fetched once and kept in memory for CategoryNameTTL.
+type CategoryNameCache struct {
+	fetcher  VideoCategoryFetcher
+	ttl      time.Duration
+	errorTTL time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	regions map[string]*categoryList
+	fetches singleflight.Group // one fetch per region at a time
+}
+
+// NewCategoryNameCache creates a category name cache backed by the given fetcher
+func NewCategoryNameCache(fetcher VideoCategoryFetcher) *CategoryNameCache {
+	return &CategoryNameCache{
+		fetcher:  fetcher,
+		ttl:      CategoryNameTTL,
+		errorTTL: CategoryNameErrorTTL,
+		now:      time.Now,
+		regions:  make(map[string]*categoryList),
+	}
+}
+
+// CategoryName returns the name of a category in the given region, or "" when the region
+// has no such category. The region's list is fetched on first use and after it expires.
+// Concurrent callers share one fetch per region, made without holding the cache lock.
+func (c *CategoryNameCache) CategoryName(ctx context.Context, regionCode, categoryID string) (string, error) {
+	list := c.cached(regionCode)
+	if list == nil {
+		// The fetch is shared, so it must not be cancelled with the caller that started it
+		fetch := c.fetches.DoChan(regionCode, func() (interface{}, error) {
+			return c.fetch(context.WithoutCancel(ctx), regionCode), nil
+		})
+		select {
+		case result := <-fetch:
+			list = result.Val.(*categoryList)
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	if list.err != nil {
+		return "", list.err
+	}
+	return list.names[categoryID], nil
+}
+
+// cached returns the region's list while it is fresh, or nil
+func (c *CategoryNameCache) cached(regionCode string) *categoryList {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list, ok := c.regions[regionCode]
+	if !ok {
+		return nil
+	}
+	ttl := c.ttl
+	if list.err != nil {
+		ttl = c.errorTTL
+	}
+	if c.now().Sub(list.fetchedAt) >= ttl {
+		return nil
+	}
+	return list
+}
+
+// fetch fetches the region's list and caches it, or the error for errorTTL
+func (c *CategoryNameCache) fetch(ctx context.Context, regionCode string) *categoryList {
+	list := &categoryList{}
+	names, err := c.fetcher.FetchVideoCategories(ctx, regionCode)
+	if err != nil {
+		list.err = fmt.Errorf("fetch video categories: %w", err)
+	} else {
+		list.names = names
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list.fetchedAt = c.now()
+	c.regions[regionCode] = list
+	return list
+}
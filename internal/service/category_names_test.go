@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCategoryFetcher serves a fixed category list and counts the fetches
+type fakeCategoryFetcher struct {
+	categories map[string]string
+	err        error
+	calls      int
+}
+
+func (f *fakeCategoryFetcher) FetchVideoCategories(ctx context.Context, regionCode string) (map[string]string, error) {
+	f.calls++
+	return f.categories, f.err
+}
+
+func TestCategoryNameCache_ResolvesAndCaches(t *testing.T) {
+	fetcher := &fakeCategoryFetcher{categories: map[string]string{
+		"10": "Music",
+		"28": "Science & Technology",
+	}}
+	cache := NewCategoryNameCache(fetcher)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	name, err := cache.CategoryName(context.Background(), "US", "28")
+	require.NoError(t, err)
+	assert.Equal(t, "Science & Technology", name)
+
+	name, err = cache.CategoryName(context.Background(), "US", "99")
+	require.NoError(t, err)
+	assert.Empty(t, name)
+	assert.Equal(t, 1, fetcher.calls, "the region's list is fetched once")
+
+	// Another region has its own list
+	_, err = cache.CategoryName(context.Background(), "GB", "28")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetcher.calls)
+
+	// The list is fetched again once it is a day old
+	now = now.Add(CategoryNameTTL)
+	_, err = cache.CategoryName(context.Background(), "US", "28")
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetcher.calls)
+}
+
+func TestCategoryNameCache_FetchError(t *testing.T) {
+	fetcher := &fakeCategoryFetcher{err: errors.New("quota exceeded")}
+	cache := NewCategoryNameCache(fetcher)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	_, err := cache.CategoryName(context.Background(), "US", "28")
+	require.Error(t, err)
+
+	// A failed fetch is remembered briefly, then retried
+	_, err = cache.CategoryName(context.Background(), "US", "28")
+	require.Error(t, err)
+	assert.Equal(t, 1, fetcher.calls)
+
+	now = now.Add(CategoryNameErrorTTL)
+	fetcher.err = nil
+	fetcher.categories = map[string]string{"28": "Science & Technology"}
+	name, err := cache.CategoryName(context.Background(), "US", "28")
+	require.NoError(t, err)
+	assert.Equal(t, "Science & Technology", name)
+	assert.Equal(t, 2, fetcher.calls)
+}
+
+// blockingCategoryFetcher holds fetches until release is closed
+type blockingCategoryFetcher struct {
+	started chan string
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (f *blockingCategoryFetcher) FetchVideoCategories(ctx context.Context, regionCode string) (map[string]string, error) {
+	f.calls.Add(1)
+	f.started <- regionCode
+	<-f.release
+	return map[string]string{"10": "Music"}, nil
+}
+
+func TestCategoryNameCache_SharesFetchPerRegion(t *testing.T) {
+	fetcher := &blockingCategoryFetcher{started: make(chan string, 10), release: make(chan struct{})}
+	cache := NewCategoryNameCache(fetcher)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name, err := cache.CategoryName(context.Background(), "US", "10")
+			assert.NoError(t, err)
+			assert.Equal(t, "Music", name)
+		}()
+	}
+	<-fetcher.started
+
+	// Another region is not held up by the fetch in progress
+	go func() {
+		_, _ = cache.CategoryName(context.Background(), "GB", "10")
+	}()
+	select {
+	case region := <-fetcher.started:
+		assert.Equal(t, "GB", region)
+	case <-time.After(time.Second):
+		t.Fatal("expected another region to fetch while the first fetch is in progress")
+	}
+
+	close(fetcher.release)
+	wg.Wait()
+	assert.Equal(t, int32(2), fetcher.calls.Load(), "concurrent callers for a region share one fetch")
+}
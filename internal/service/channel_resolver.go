@@ -11,11 +11,41 @@ import (
 	"ad-tracker/youtube-webhook-ingestion/internal/model"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
 	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
+
+	"golang.org/x/time/rate"
 )
 
+// DefaultChannelResolverConcurrency is how many YouTube lookups ChannelResolverService runs at once
+const DefaultChannelResolverConcurrency = 4
+
+// DefaultChannelResolveRateLimit is how many YouTube lookups ChannelResolverService makes per
+// minute. Resolving a custom URL uses search.list, which costs about 100 quota units.
+const DefaultChannelResolveRateLimit = 10
+
+// ResolveRateLimitError is returned when a resolution needs a YouTube lookup but the rate
+// limit has none left
+type ResolveRateLimitError struct {
+	RetryAfter time.Duration // until the next lookup is allowed
+}
+
+func (e *ResolveRateLimitError) Error() string {
+	return fmt.Sprintf("channel lookup rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// channelLookup is the part of the YouTube client used to resolve channels
+type channelLookup interface {
+	ResolveChannelByURL(ctx context.Context, urlStr string) (*youtube.ChannelEnrichment, error)
+	GetChannelDetails(ctx context.Context, channelID string) (*youtube.ChannelEnrichment, error)
+	ResolveQuotaUsage(urlStr string) quota.Usage
+	QuotaCost(operationType string) int
+}
+
 // ChannelResolverService orchestrates channel resolution and enrichment
 type ChannelResolverService struct {
-	youtubeClient    *youtube.Client
+	youtubeClient    channelLookup
+	lookupSlots      chan struct{}    // bounds concurrent YouTube lookups; nil means unbounded
+	lookupLimiter    *rate.Limiter    // bounds YouTube lookups per minute; nil means unlimited
+	urlCache         *channelURLCache // recent resolutions by normalized URL; nil disables caching
 	channelRepo      repository.ChannelRepository
 	subscriptionRepo repository.SubscriptionRepository
 	enrichmentRepo   repository.ChannelEnrichmentRepository
@@ -45,12 +75,56 @@ func NewChannelResolverService(
 		pubSubHubService: pubSubHubService,
 		webhookSecret:    webhookSecret,
 		webhookURL:       webhookURL,
+		lookupSlots:      make(chan struct{}, DefaultChannelResolverConcurrency),
+		urlCache:         newChannelURLCache(DefaultChannelURLCacheSize, DefaultChannelURLCacheTTL),
+	}
+}
+
+// SetConcurrencyLimit bounds how many YouTube lookups run at once. Further resolution
+// requests wait for a free slot, or until their context is done. A limit of zero or
+// less removes the bound.
+func (s *ChannelResolverService) SetConcurrencyLimit(limit int) {
+	if limit <= 0 {
+		s.lookupSlots = nil
+		return
+	}
+	s.lookupSlots = make(chan struct{}, limit)
+}
+
+// SetRateLimit allows perMinute YouTube lookups per minute, with bursts of up to perMinute.
+// Resolutions served from the URL cache and URLs that fail to parse do not count. Further
+// lookups fail with a ResolveRateLimitError. Zero or less removes the limit.
+func (s *ChannelResolverService) SetRateLimit(perMinute int) {
+	if perMinute <= 0 {
+		s.lookupLimiter = nil
+		return
+	}
+	s.lookupLimiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute)
+}
+
+// allowLookup takes a lookup from the rate limiter, or returns a ResolveRateLimitError
+// with how long until the next one is available
+func (s *ChannelResolverService) allowLookup() error {
+	if s.lookupLimiter == nil {
+		return nil
+	}
+
+	reservation := s.lookupLimiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
 	}
+	reservation.Cancel()
+	return &ResolveRateLimitError{RetryAfter: delay}
 }
 
 // ResolveChannelFromURLRequest represents the request to resolve a channel from a URL
 type ResolveChannelFromURLRequest struct {
 	URL string
+
+	// ChannelID confirms a specific channel, typically one of the candidates
+	// returned for an ambiguous URL. When set, URL resolution is skipped.
+	ChannelID string
 }
 
 // ResolveChannelFromURLResponse represents the response from resolving a channel
@@ -66,7 +140,7 @@ func (s *ChannelResolverService) ResolveChannelFromURL(ctx context.Context, req
 	log.Printf("[ChannelResolver] Resolving channel from URL: %s", req.URL)
 
 	// Step 1: Resolve the channel via YouTube API
-	ytEnrichment, err := s.youtubeClient.ResolveChannelByURL(ctx, req.URL)
+	ytEnrichment, fresh, err := s.lookupChannel(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve channel from YouTube: %w", err)
 	}
@@ -105,12 +179,15 @@ func (s *ChannelResolverService) ResolveChannelFromURL(ctx context.Context, req
 		}
 	}
 
-	// Step 4: Store enrichment data
+	// Step 4: Store enrichment data. A cached resolution was stored when it was fetched,
+	// so storing it again would only duplicate that snapshot.
 	enrichment := s.mapYouTubeEnrichmentToModel(ytEnrichment)
-	err = s.enrichmentRepo.Create(ctx, enrichment)
-	if err != nil {
-		log.Printf("[ChannelResolver] Warning: Failed to store enrichment: %v", err)
-		// Don't fail the entire operation if enrichment storage fails
+	if fresh {
+		err = s.enrichmentRepo.Create(ctx, enrichment)
+		if err != nil {
+			log.Printf("[ChannelResolver] Warning: Failed to store enrichment: %v", err)
+			// Don't fail the entire operation if enrichment storage fails
+		}
 	}
 
 	// Note: Quota tracking is now handled automatically by the YouTube client
@@ -132,6 +209,104 @@ func (s *ChannelResolverService) ResolveChannelFromURL(ctx context.Context, req
 	}, nil
 }
 
+// lookupChannel fetches the channel for a request from YouTube, waiting for a free
+// lookup slot first so bursts of requests queue instead of hitting the API at once.
+// Channels resolved recently are served from the URL cache without an API call; fresh
+// reports whether the channel came from the API. Only requests that reach the API count
+// against the rate limit.
+func (s *ChannelResolverService) lookupChannel(ctx context.Context, req ResolveChannelFromURLRequest) (channel *youtube.ChannelEnrichment, fresh bool, err error) {
+	cacheKey := s.lookupCacheKey(req)
+	if cacheKey != "" {
+		if cached := s.urlCache.get(cacheKey); cached != nil {
+			log.Printf("[ChannelResolver] Using cached resolution for %s", cacheKey)
+			return cached, false, nil
+		}
+	}
+
+	if req.ChannelID == "" {
+		if _, err := youtube.NormalizeChannelURL(req.URL); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := s.allowLookup(); err != nil {
+		return nil, false, err
+	}
+
+	ctx, err = s.reserveQuota(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer quota.Release(ctx)
+
+	if s.lookupSlots != nil {
+		select {
+		case s.lookupSlots <- struct{}{}:
+			defer func() { <-s.lookupSlots }()
+		case <-ctx.Done():
+			return nil, false, fmt.Errorf("waiting for a lookup slot: %w", ctx.Err())
+		}
+	}
+
+	if req.ChannelID != "" {
+		channel, err = s.youtubeClient.GetChannelDetails(ctx, req.ChannelID)
+	} else {
+		channel, err = s.youtubeClient.ResolveChannelByURL(ctx, req.URL)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cacheKey != "" {
+		s.urlCache.put(cacheKey, channel)
+	}
+	return channel, true, nil
+}
+
+// reserveQuota reserves the quota a lookup spends, returning the context that carries the
+// reservation to the YouTube client; the caller releases it once the lookup is done.
+// Resolution is interactive, so it may use the quota reserved away from background enrichment.
+func (s *ChannelResolverService) reserveQuota(ctx context.Context, req ResolveChannelFromURLRequest) (context.Context, error) {
+	if s.quotaManager == nil {
+		return ctx, nil
+	}
+
+	usage := quota.Usage{quota.OpChannelsList: s.youtubeClient.QuotaCost(quota.OpChannelsList)}
+	if req.ChannelID == "" {
+		usage = s.youtubeClient.ResolveQuotaUsage(req.URL)
+	}
+
+	reservedCtx, available, err := s.quotaManager.ReserveUsage(ctx, quota.PriorityInteractive, usage)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to check quota: %w", err)
+	}
+	if !available {
+		quotaInfo, err := s.quotaManager.GetQuotaInfo(ctx)
+		if err != nil {
+			return ctx, quota.ErrQuotaExhausted
+		}
+		return ctx, fmt.Errorf("%w: %d/%d used", quota.ErrQuotaExhausted, quotaInfo.QuotaUsed, quotaInfo.QuotaLimit)
+	}
+	return reservedCtx, nil
+}
+
+// lookupCacheKey returns the URL cache key for a request, or "" when caching is
+// disabled or the URL cannot be normalized (the lookup then reports the error)
+func (s *ChannelResolverService) lookupCacheKey(req ResolveChannelFromURLRequest) string {
+	if s.urlCache == nil {
+		return ""
+	}
+	if req.ChannelID != "" {
+		return "channel:" + req.ChannelID
+	}
+
+	key, err := youtube.NormalizeChannelURL(req.URL)
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
 // createSubscription creates a PubSubHubbub subscription for a channel
 func (s *ChannelResolverService) createSubscription(ctx context.Context, channelID string) (*models.Subscription, error) {
 	topicURL := fmt.Sprintf("https://www.youtube.com/xml/feeds/videos.xml?channel_id=%s", channelID)
@@ -144,6 +319,9 @@ func (s *ChannelResolverService) createSubscription(ctx context.Context, channel
 		ExpiresAt:    time.Now().Add(432000 * time.Second),
 		Status:       "pending",
 	}
+	if s.pubSubHubService != nil {
+		subscription.MarkLeaseRequested()
+	}
 
 	// Create the subscription in the database
 	err := s.subscriptionRepo.Create(ctx, subscription)
@@ -169,10 +347,9 @@ func (s *ChannelResolverService) createSubscription(ctx context.Context, channel
 			return subscription, err
 		}
 
-		// Update status to active
-		subscription.Status = "active"
-		subscription.LastVerifiedAt = timePtr(time.Now())
-		_ = s.subscriptionRepo.Update(ctx, subscription)
+		// Update status to active, keeping any lease the hub has already confirmed
+		subscription.MarkActive()
+		_ = s.subscriptionRepo.Activate(ctx, subscription)
 	}
 
 	return subscription, nil
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowChannelLookup records how many lookups are in flight at once
+type slowChannelLookup struct {
+	delay    time.Duration
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	calls    atomic.Int32
+}
+
+func (f *slowChannelLookup) lookup(channelID string) (*youtube.ChannelEnrichment, error) {
+	f.calls.Add(1)
+	current := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+
+	for {
+		peak := f.peak.Load()
+		if current <= peak || f.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	time.Sleep(f.delay)
+	return &youtube.ChannelEnrichment{ChannelID: channelID}, nil
+}
+
+func (f *slowChannelLookup) ResolveChannelByURL(ctx context.Context, urlStr string) (*youtube.ChannelEnrichment, error) {
+	return f.lookup("UCresolved")
+}
+
+func (f *slowChannelLookup) ResolveQuotaUsage(urlStr string) quota.Usage {
+	return quota.Usage{quota.OpChannelsList: 1}
+}
+
+func (f *slowChannelLookup) QuotaCost(operationType string) int {
+	return quota.DefaultCosts.Cost(operationType)
+}
+
+func (f *slowChannelLookup) GetChannelDetails(ctx context.Context, channelID string) (*youtube.ChannelEnrichment, error) {
+	return f.lookup(channelID)
+}
+
+func TestChannelResolverService_ConcurrencyLimit(t *testing.T) {
+	lookup := &slowChannelLookup{delay: 20 * time.Millisecond}
+	s := &ChannelResolverService{youtubeClient: lookup}
+	s.SetConcurrencyLimit(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := s.lookupChannel(context.Background(), ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@example"})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(8), lookup.calls.Load(), "queued requests should all be resolved")
+	assert.LessOrEqual(t, lookup.peak.Load(), int32(2))
+	assert.Equal(t, int32(2), lookup.peak.Load(), "the limit should still allow parallel lookups")
+}
+
+func TestChannelResolverService_ConcurrencyLimitHonoursContext(t *testing.T) {
+	lookup := &slowChannelLookup{delay: 200 * time.Millisecond}
+	s := &ChannelResolverService{youtubeClient: lookup}
+	s.SetConcurrencyLimit(1)
+
+	go s.lookupChannel(context.Background(), ResolveChannelFromURLRequest{ChannelID: "UCfirst"})
+	require.Eventually(t, func() bool { return lookup.inFlight.Load() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err := s.lookupChannel(ctx, ResolveChannelFromURLRequest{ChannelID: "UCsecond"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), lookup.calls.Load(), "a request that timed out waiting should not call the API")
+}
+
+func TestChannelResolverService_CachesResolvedURLs(t *testing.T) {
+	lookup := &slowChannelLookup{}
+	s := &ChannelResolverService{youtubeClient: lookup, urlCache: newChannelURLCache(DefaultChannelURLCacheSize, time.Hour)}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.urlCache.now = func() time.Time { return now }
+
+	first, fresh, err := s.lookupChannel(context.Background(), ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@Example"})
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	// The same channel spelled differently is served from the cache
+	second, fresh, err := s.lookupChannel(context.Background(), ResolveChannelFromURLRequest{URL: "youtube.com/@example/videos"})
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.False(t, fresh)
+	assert.Equal(t, int32(1), lookup.calls.Load(), "a repeated resolution within the TTL should not call the API")
+
+	// Once the TTL has passed the URL is resolved again
+	now = now.Add(time.Hour)
+	_, _, err = s.lookupChannel(context.Background(), ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@Example"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), lookup.calls.Load())
+}
+
+func TestChannelResolverService_RateLimitCountsOnlyAPILookups(t *testing.T) {
+	lookup := &slowChannelLookup{}
+	s := &ChannelResolverService{youtubeClient: lookup, urlCache: newChannelURLCache(DefaultChannelURLCacheSize, time.Hour)}
+	s.SetRateLimit(2)
+	ctx := context.Background()
+
+	// Cache hits and URLs that fail to parse do not take a lookup
+	for i := 0; i < 3; i++ {
+		_, _, err := s.lookupChannel(ctx, ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@first"})
+		require.NoError(t, err)
+	}
+	_, _, err := s.lookupChannel(ctx, ResolveChannelFromURLRequest{URL: "https://vimeo.com/staffpicks"})
+	require.ErrorIs(t, err, youtube.ErrInvalidChannelURL)
+
+	_, _, err = s.lookupChannel(ctx, ResolveChannelFromURLRequest{ChannelID: "UCsecond"})
+	require.NoError(t, err)
+
+	_, _, err = s.lookupChannel(ctx, ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@third"})
+	var rateLimitErr *ResolveRateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+	// At 2 per minute a lookup frees up every 30 seconds
+	assert.Greater(t, rateLimitErr.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, rateLimitErr.RetryAfter, 30*time.Second)
+	assert.Equal(t, int32(2), lookup.calls.Load(), "the rejected lookup should not call the API")
+
+	// Resolutions already cached are still served
+	_, fresh, err := s.lookupChannel(ctx, ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@first"})
+	require.NoError(t, err)
+	assert.False(t, fresh)
+}
+
+func TestChannelResolverService_RateLimitDisabled(t *testing.T) {
+	lookup := &slowChannelLookup{}
+	s := &ChannelResolverService{youtubeClient: lookup}
+	s.SetRateLimit(0)
+
+	for i := 0; i < 20; i++ {
+		_, _, err := s.lookupChannel(context.Background(), ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@example"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(20), lookup.calls.Load())
+}
+
+// resolverChannelRepo keeps channels in memory
+type resolverChannelRepo struct {
+	repository.ChannelRepository
+	channels map[string]*models.Channel
+}
+
+func (r *resolverChannelRepo) GetByID(ctx context.Context, channelID string) (*models.Channel, error) {
+	if channel, ok := r.channels[channelID]; ok {
+		return channel, nil
+	}
+	return nil, db.ErrNotFound
+}
+
+func (r *resolverChannelRepo) Create(ctx context.Context, channel *models.Channel) error {
+	r.channels[channel.ChannelID] = channel
+	return nil
+}
+
+func (r *resolverChannelRepo) Update(ctx context.Context, channel *models.Channel) error {
+	r.channels[channel.ChannelID] = channel
+	return nil
+}
+
+// resolverEnrichmentRepo counts the channel enrichments stored
+type resolverEnrichmentRepo struct {
+	repository.ChannelEnrichmentRepository
+	created int
+}
+
+func (r *resolverEnrichmentRepo) Create(ctx context.Context, enrichment *model.ChannelEnrichment) error {
+	r.created++
+	return nil
+}
+
+// resolverSubscriptionRepo accepts every subscription
+type resolverSubscriptionRepo struct {
+	repository.SubscriptionRepository
+}
+
+func (r *resolverSubscriptionRepo) Create(ctx context.Context, subscription *models.Subscription) error {
+	return nil
+}
+
+func TestChannelResolverService_CachedResolutionStoresNoEnrichment(t *testing.T) {
+	enrichmentRepo := &resolverEnrichmentRepo{}
+	s := &ChannelResolverService{
+		youtubeClient:    &slowChannelLookup{},
+		urlCache:         newChannelURLCache(DefaultChannelURLCacheSize, time.Hour),
+		channelRepo:      &resolverChannelRepo{channels: map[string]*models.Channel{}},
+		subscriptionRepo: &resolverSubscriptionRepo{},
+		enrichmentRepo:   enrichmentRepo,
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := s.ResolveChannelFromURL(context.Background(), ResolveChannelFromURLRequest{URL: "https://www.youtube.com/@example"})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Enrichment)
+	}
+	assert.Equal(t, 1, enrichmentRepo.created, "a cached resolution should not store the same enrichment again")
+}
+
+func TestChannelURLCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newChannelURLCache(2, time.Hour)
+
+	cache.put("handle:a", &youtube.ChannelEnrichment{ChannelID: "UCa"})
+	cache.put("handle:b", &youtube.ChannelEnrichment{ChannelID: "UCb"})
+	require.NotNil(t, cache.get("handle:a")) // a is now more recent than b
+	cache.put("handle:c", &youtube.ChannelEnrichment{ChannelID: "UCc"})
+
+	assert.Nil(t, cache.get("handle:b"))
+	assert.NotNil(t, cache.get("handle:a"))
+	assert.NotNil(t, cache.get("handle:c"))
+}
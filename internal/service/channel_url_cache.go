@@ -0,0 +1,90 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
+)
+
+const (
+	// DefaultChannelURLCacheSize is how many resolved channel URLs ChannelResolverService remembers
+	DefaultChannelURLCacheSize = 256
+
+	// DefaultChannelURLCacheTTL is how long a resolved channel URL is reused before it is looked up again
+	DefaultChannelURLCacheTTL = time.Hour
+)
+
+// channelURLEntry is one cached resolution
+type channelURLEntry struct {
+	key       string
+	channel   *youtube.ChannelEnrichment
+	expiresAt time.Time
+}
+
+// channelURLCache is a bounded LRU of resolved channel lookups keyed by normalized URL.
+// Entries expire after the TTL; when full, the least recently used entry is evicted.
+type channelURLCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // front is the most recently used
+	entries map[string]*list.Element
+}
+
+// newChannelURLCache creates a cache holding at most size entries for ttl each
+func newChannelURLCache(size int, ttl time.Duration) *channelURLCache {
+	return &channelURLCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached channel for key, or nil when it is missing or expired
+func (c *channelURLCache) get(key string) *youtube.ChannelEnrichment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := elem.Value.(*channelURLEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.channel
+}
+
+// put stores the channel for key, evicting the least recently used entry when full
+func (c *channelURLCache) put(key string, channel *youtube.ChannelEnrichment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*channelURLEntry)
+		entry.channel = channel
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&channelURLEntry{key: key, channel: channel, expiresAt: expiresAt})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*channelURLEntry).key)
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/parser"
+)
+
+// DefaultChannelFeedURL is YouTube's public per-channel video feed
+const DefaultChannelFeedURL = "https://www.youtube.com/feeds/videos.xml"
+
+// Defaults for the channel feed poller
+const (
+	DefaultFeedPollPageSize = 500
+	DefaultFeedPollTimeout  = 15 * time.Second
+)
+
+// maxChannelFeedSize bounds a channel feed response; feeds list the latest 15 uploads
+const maxChannelFeedSize = 1 << 20
+
+// FeedPollSubscriptionLister lists subscriptions whose channels are polled
+type FeedPollSubscriptionLister interface {
+	List(ctx context.Context, filters *repository.SubscriptionFilters) ([]*models.Subscription, int, error)
+}
+
+// KnownVideoLookup reports whether a video is already stored
+type KnownVideoLookup interface {
+	GetVideoByID(ctx context.Context, videoID string) (*models.Video, error)
+}
+
+// BlockedVideoChecker reports whether notifications for a video are ignored
+type BlockedVideoChecker interface {
+	IsBlocked(ctx context.Context, videoID string) (bool, error)
+}
+
+// FeedPollerConfig configures the channel feed poller
+type FeedPollerConfig struct {
+	FeedURL    string     // default: DefaultChannelFeedURL
+	PageSize   int        // active subscriptions listed at a time (default: 500)
+	HTTPClient HTTPClient // default: a client with DefaultFeedPollTimeout
+	UserAgent  string     // sent with feed requests when set
+}
+
+// FeedPollResult summarizes one polling run
+type FeedPollResult struct {
+	Channels       int // channel feeds fetched
+	FailedChannels int // channel feeds that could not be fetched or parsed
+	Ingested       int // videos not previously known that were processed
+	Failed         int // new videos whose processing failed; retried on the next run
+}
+
+// FeedPoller is a fallback for missed webhook notifications: it periodically fetches the
+// video feed of each channel with an active subscription and processes any listed video
+// that is not stored yet as if its notification had been delivered.
+type FeedPoller struct {
+	subscriptions FeedPollSubscriptionLister
+	videos        KnownVideoLookup
+	processor     EventProcessor
+	blocked       BlockedVideoChecker // Optional
+	config        FeedPollerConfig
+}
+
+// NewFeedPoller creates a feed poller. Zero config values use the defaults.
+func NewFeedPoller(
+	subscriptions FeedPollSubscriptionLister,
+	videos KnownVideoLookup,
+	processor EventProcessor,
+	config FeedPollerConfig,
+) *FeedPoller {
+	if config.FeedURL == "" {
+		config.FeedURL = DefaultChannelFeedURL
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = DefaultFeedPollPageSize
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: DefaultFeedPollTimeout}
+	}
+
+	return &FeedPoller{
+		subscriptions: subscriptions,
+		videos:        videos,
+		processor:     processor,
+		config:        config,
+	}
+}
+
+// SetBlockedVideoChecker skips polled videos that are blocked, as the webhook endpoint does
+func (p *FeedPoller) SetBlockedVideoChecker(blocked BlockedVideoChecker) {
+	p.blocked = blocked
+}
+
+// PollAll polls the feed of every channel with an active subscription. A channel whose
+// feed fails is logged and counted, and does not stop the run.
+func (p *FeedPoller) PollAll(ctx context.Context) (*FeedPollResult, error) {
+	result := &FeedPollResult{}
+
+	channelIDs, err := p.activeChannelIDs(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, channelID := range channelIDs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		ingested, failed, err := p.PollChannel(ctx, channelID)
+		if err != nil {
+			log.Printf("[FeedPoller] Failed to poll channel %s: %v", channelID, err)
+			result.FailedChannels++
+			continue
+		}
+		result.Channels++
+		result.Ingested += ingested
+		result.Failed += failed
+	}
+
+	return result, nil
+}
+
+// activeChannelIDs pages through every active subscription and returns its channels, each
+// once. All pages are listed before polling starts, as polling takes a while and status
+// changes made meanwhile would shift the later pages.
+func (p *FeedPoller) activeChannelIDs(ctx context.Context) ([]string, error) {
+	var channelIDs []string
+	seen := make(map[string]bool)
+
+	for offset := 0; ; offset += p.config.PageSize {
+		page, _, err := p.subscriptions.List(ctx, &repository.SubscriptionFilters{
+			Status: models.StatusActive,
+			Limit:  p.config.PageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list active subscriptions: %w", err)
+		}
+		for _, sub := range page {
+			if !seen[sub.ChannelID] {
+				seen[sub.ChannelID] = true
+				channelIDs = append(channelIDs, sub.ChannelID)
+			}
+		}
+		if len(page) < p.config.PageSize {
+			return channelIDs, nil
+		}
+	}
+}
+
+// PollChannel fetches one channel's feed and processes the videos that are not stored yet,
+// recording their events as polled. It returns how many videos were ingested and how many
+// failed to process.
+func (p *FeedPoller) PollChannel(ctx context.Context, channelID string) (int, int, error) {
+	ctx = repository.WithEventSource(ctx, models.EventSourcePoll)
+
+	rawXML, err := p.fetchFeed(ctx, channelID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	videos, err := parser.ParseChannelFeed(rawXML)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse feed: %w", err)
+	}
+
+	ingested, failed := 0, 0
+	for _, video := range videos {
+		_, err := p.videos.GetVideoByID(ctx, video.VideoID)
+		if err == nil {
+			continue
+		}
+		if !db.IsNotFound(err) {
+			log.Printf("[FeedPoller] Failed to look up video %s: %v", video.VideoID, err)
+			failed++
+			continue
+		}
+
+		if p.blocked != nil {
+			isBlocked, err := p.blocked.IsBlocked(ctx, video.VideoID)
+			if err != nil {
+				// Continue processing even if the check fails, as the webhook endpoint does
+				log.Printf("[FeedPoller] Failed to check whether video %s is blocked: %v", video.VideoID, err)
+			} else if isBlocked {
+				continue
+			}
+		}
+
+		notification, err := video.NotificationXML()
+		if err != nil {
+			log.Printf("[FeedPoller] Failed to render notification for video %s: %v", video.VideoID, err)
+			failed++
+			continue
+		}
+
+		if err := p.processor.ProcessEvent(ctx, notification); err != nil {
+			log.Printf("[FeedPoller] Failed to process video %s: %v", video.VideoID, err)
+			failed++
+			continue
+		}
+
+		log.Printf("[FeedPoller] Ingested video %s of channel %s missed by webhooks", video.VideoID, channelID)
+		ingested++
+	}
+
+	return ingested, failed, nil
+}
+
+// fetchFeed downloads a channel's video feed
+func (p *FeedPoller) fetchFeed(ctx context.Context, channelID string) (string, error) {
+	feedURL := p.config.FeedURL + "?" + url.Values{"channel_id": {channelID}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	if p.config.UserAgent != "" {
+		req.Header.Set("User-Agent", p.config.UserAgent)
+	}
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch feed: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxChannelFeedSize))
+	if err != nil {
+		return "", fmt.Errorf("read feed: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// Run calls PollAll at startup and then every interval until ctx is cancelled
+func (p *FeedPoller) Run(ctx context.Context, interval time.Duration) {
+	runNowAndPeriodically(ctx, interval, func(ctx context.Context) {
+		result, err := p.PollAll(ctx)
+		if err != nil {
+			log.Printf("[FeedPoller] Poll failed: %v", err)
+			return
+		}
+		if result.Ingested > 0 || result.Failed > 0 || result.FailedChannels > 0 {
+			log.Printf("[FeedPoller] Polled %d channel feeds: %d videos ingested, %d failed, %d channels failed",
+				result.Channels, result.Ingested, result.Failed, result.FailedChannels)
+		}
+	})
+}
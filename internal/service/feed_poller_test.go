@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/parser"
+	"ad-tracker/youtube-webhook-ingestion/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSubscriptionLister struct {
+	subscriptions []*models.Subscription
+}
+
+func (f *fakeSubscriptionLister) List(ctx context.Context, filters *repository.SubscriptionFilters) ([]*models.Subscription, int, error) {
+	var matching []*models.Subscription
+	for _, sub := range f.subscriptions {
+		if sub.Status == filters.Status {
+			matching = append(matching, sub)
+		}
+	}
+	total := len(matching)
+	if filters.Offset >= total {
+		return nil, total, nil
+	}
+	matching = matching[filters.Offset:]
+	if len(matching) > filters.Limit {
+		matching = matching[:filters.Limit]
+	}
+	return matching, total, nil
+}
+
+// recordingProcessor records the notifications it is asked to process and their sources
+type recordingProcessor struct {
+	events  []string
+	sources []string
+}
+
+func (p *recordingProcessor) ProcessEvent(ctx context.Context, rawXML string) error {
+	p.events = append(p.events, rawXML)
+	p.sources = append(p.sources, repository.EventSourceFromContext(ctx))
+	return nil
+}
+
+func (p *recordingProcessor) SetQueueClient(client *queue.Client) {}
+
+const testChannelFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <yt:channelId>%[1]s</yt:channelId>
+  <title>Test Channel</title>
+  <entry>
+    <yt:videoId>newVideo</yt:videoId>
+    <yt:channelId>%[1]s</yt:channelId>
+    <title>New Video</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=newVideo"/>
+    <author><name>Test Channel</name></author>
+    <published>2024-05-01T10:00:00+00:00</published>
+    <updated>2024-05-01T11:00:00+00:00</updated>
+  </entry>
+  <entry>
+    <yt:videoId>knownVideo</yt:videoId>
+    <yt:channelId>%[1]s</yt:channelId>
+    <title>Known Video</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=knownVideo"/>
+    <published>2024-04-01T10:00:00+00:00</published>
+    <updated>2024-04-01T11:00:00+00:00</updated>
+  </entry>
+</feed>`
+
+func TestFeedPoller_IngestsOnlyUnknownVideos(t *testing.T) {
+	var requestedChannels []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		channelID := r.URL.Query().Get("channel_id")
+		requestedChannels = append(requestedChannels, channelID)
+		fmt.Fprintf(w, testChannelFeed, channelID)
+	}))
+	defer server.Close()
+
+	subscriptions := &fakeSubscriptionLister{subscriptions: []*models.Subscription{
+		{ChannelID: "UC123", Status: models.StatusActive},
+		{ChannelID: "UC123", Status: models.StatusActive}, // a second subscription to the same channel
+		{ChannelID: "UCexpired", Status: models.StatusExpired},
+	}}
+
+	videoRepo := new(mockVideoRepo)
+	videoRepo.On("GetVideoByID", mock.Anything, "knownVideo").Return(&models.Video{VideoID: "knownVideo"}, nil)
+	videoRepo.On("GetVideoByID", mock.Anything, "newVideo").Return(nil, db.ErrNotFound)
+
+	processor := &recordingProcessor{}
+	poller := NewFeedPoller(subscriptions, videoRepo, processor, FeedPollerConfig{FeedURL: server.URL})
+
+	result, err := poller.PollAll(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"UC123"}, requestedChannels, "each active channel is polled once")
+	assert.Equal(t, &FeedPollResult{Channels: 1, Ingested: 1}, result)
+
+	require.Len(t, processor.events, 1)
+	ingested, err := parser.ParseAtomFeed(processor.events[0])
+	require.NoError(t, err)
+	assert.Equal(t, "newVideo", ingested.VideoID)
+	assert.Equal(t, "UC123", ingested.ChannelID)
+	assert.Equal(t, "New Video", ingested.Title)
+	assert.Equal(t, "Test Channel", ingested.AuthorName)
+	assert.Equal(t, []string{models.EventSourcePoll}, processor.sources, "polled events are marked as polled")
+}
+
+func TestFeedPoller_PollsEveryPageOfActiveSubscriptions(t *testing.T) {
+	var requestedChannels []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		channelID := r.URL.Query().Get("channel_id")
+		requestedChannels = append(requestedChannels, channelID)
+		fmt.Fprintf(w, testChannelFeed, channelID)
+	}))
+	defer server.Close()
+
+	subscriptions := &fakeSubscriptionLister{}
+	var want []string
+	for i := 0; i < 5; i++ {
+		channelID := fmt.Sprintf("UC%d", i)
+		subscriptions.subscriptions = append(subscriptions.subscriptions, &models.Subscription{ChannelID: channelID, Status: models.StatusActive})
+		want = append(want, channelID)
+	}
+
+	videoRepo := new(mockVideoRepo)
+	videoRepo.On("GetVideoByID", mock.Anything, mock.Anything).Return(&models.Video{}, nil)
+
+	poller := NewFeedPoller(subscriptions, videoRepo, &recordingProcessor{}, FeedPollerConfig{FeedURL: server.URL, PageSize: 2})
+
+	result, err := poller.PollAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, want, requestedChannels, "channels beyond the first page are polled too")
+	assert.Equal(t, 5, result.Channels)
+}
+
+type fakeBlockedVideos map[string]bool
+
+func (f fakeBlockedVideos) IsBlocked(ctx context.Context, videoID string) (bool, error) {
+	return f[videoID], nil
+}
+
+func TestFeedPoller_SkipsBlockedVideos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, testChannelFeed, r.URL.Query().Get("channel_id"))
+	}))
+	defer server.Close()
+
+	subscriptions := &fakeSubscriptionLister{subscriptions: []*models.Subscription{
+		{ChannelID: "UC123", Status: models.StatusActive},
+	}}
+
+	videoRepo := new(mockVideoRepo)
+	videoRepo.On("GetVideoByID", mock.Anything, mock.Anything).Return(nil, db.ErrNotFound)
+
+	processor := &recordingProcessor{}
+	poller := NewFeedPoller(subscriptions, videoRepo, processor, FeedPollerConfig{FeedURL: server.URL})
+	poller.SetBlockedVideoChecker(fakeBlockedVideos{"newVideo": true})
+
+	result, err := poller.PollAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Ingested)
+	require.Len(t, processor.events, 1)
+	assert.Contains(t, processor.events[0], "knownVideo")
+}
+
+func TestFeedPoller_FailedFeedDoesNotStopRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		channelID := r.URL.Query().Get("channel_id")
+		if channelID == "UCbroken" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, testChannelFeed, channelID)
+	}))
+	defer server.Close()
+
+	subscriptions := &fakeSubscriptionLister{subscriptions: []*models.Subscription{
+		{ChannelID: "UCbroken", Status: models.StatusActive},
+		{ChannelID: "UC123", Status: models.StatusActive},
+	}}
+
+	videoRepo := new(mockVideoRepo)
+	videoRepo.On("GetVideoByID", mock.Anything, mock.Anything).Return(nil, db.ErrNotFound)
+
+	processor := &recordingProcessor{}
+	poller := NewFeedPoller(subscriptions, videoRepo, processor, FeedPollerConfig{FeedURL: server.URL})
+
+	result, err := poller.PollAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &FeedPollResult{Channels: 1, FailedChannels: 1, Ingested: 2}, result)
+	assert.Len(t, processor.events, 2)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+)
+
+// DefaultLiveViewerBatchSize is how many live videos are polled per run; videos.list accepts at most 50 IDs
+const DefaultLiveViewerBatchSize = 50
+
+// LiveStatusFetcher fetches the live state of videos from the YouTube API
+type LiveStatusFetcher interface {
+	FetchLiveStatus(ctx context.Context, videoIDs []string) ([]*model.LiveStatus, error)
+	QuotaCost(operationType string) int
+}
+
+// LiveViewerPollResult summarizes one live viewer poll
+type LiveViewerPollResult struct {
+	Polled         int  // live videos sent to videos.list
+	Sampled        int  // videos still live, with a viewer sample recorded
+	Ended          int  // videos no longer live; they are not polled again
+	QuotaExhausted bool // the poll was skipped because the quota threshold was reached
+}
+
+// LiveViewerPoller records a concurrent-viewer time series for videos whose latest enrichment
+// is live. A video is polled until the API reports it is no longer live.
+type LiveViewerPoller struct {
+	liveRepo      repository.LiveViewerRepository
+	fetcher       LiveStatusFetcher
+	quotaReserver QuotaReserver
+	batchSize     int
+	now           func() time.Time
+}
+
+// NewLiveViewerPoller creates a live viewer poller. A batchSize of zero uses the default.
+func NewLiveViewerPoller(
+	liveRepo repository.LiveViewerRepository,
+	fetcher LiveStatusFetcher,
+	quotaReserver QuotaReserver,
+	batchSize int,
+) *LiveViewerPoller {
+	if batchSize <= 0 || batchSize > DefaultLiveViewerBatchSize {
+		batchSize = DefaultLiveViewerBatchSize
+	}
+
+	return &LiveViewerPoller{
+		liveRepo:      liveRepo,
+		fetcher:       fetcher,
+		quotaReserver: quotaReserver,
+		batchSize:     batchSize,
+		now:           time.Now,
+	}
+}
+
+// PollOnce fetches the live state of one batch of live videos and records a sample for each.
+// Videos that are no longer live, or no longer returned by the API, get a final sample without
+// a viewer count, which stops them being polled.
+func (p *LiveViewerPoller) PollOnce(ctx context.Context) (*LiveViewerPollResult, error) {
+	result := &LiveViewerPollResult{}
+
+	videoIDs, err := p.liveRepo.GetLiveVideoIDs(ctx, p.batchSize)
+	if err != nil {
+		return result, fmt.Errorf("get live video ids: %w", err)
+	}
+	if len(videoIDs) == 0 {
+		return result, nil
+	}
+
+	// One videos.list call costs the same regardless of how many IDs it carries
+	usage := quota.Usage{quota.OpVideosList: p.fetcher.QuotaCost(quota.OpVideosList)}
+	ctx, available, err := p.quotaReserver.ReserveUsage(ctx, quota.PriorityBackground, usage)
+	if err != nil {
+		return result, fmt.Errorf("check quota: %w", err)
+	}
+	if !available {
+		result.QuotaExhausted = true
+		return result, nil
+	}
+	defer quota.Release(ctx)
+
+	statuses, err := p.fetcher.FetchLiveStatus(ctx, videoIDs)
+	if err != nil {
+		return result, fmt.Errorf("fetch live status: %w", err)
+	}
+	result.Polled = len(videoIDs)
+
+	byID := make(map[string]*model.LiveStatus, len(statuses))
+	for _, status := range statuses {
+		byID[status.VideoID] = status
+	}
+
+	sampledAt := p.now()
+	samples := make([]*model.LiveViewerSample, 0, len(videoIDs))
+	for _, videoID := range videoIDs {
+		sample := &model.LiveViewerSample{VideoID: videoID, SampledAt: sampledAt}
+
+		status, ok := byID[videoID]
+		if ok {
+			content := status.LiveBroadcastContent
+			sample.LiveBroadcastContent = &content
+		}
+
+		if ok && status.LiveBroadcastContent == "live" {
+			sample.ConcurrentViewers = status.ConcurrentViewers
+			result.Sampled++
+		} else {
+			result.Ended++
+		}
+		samples = append(samples, sample)
+	}
+
+	if err := p.liveRepo.SaveSamples(ctx, samples); err != nil {
+		return result, fmt.Errorf("save live viewer samples: %w", err)
+	}
+
+	return result, nil
+}
+
+// Run calls PollOnce every interval until ctx is cancelled
+func (p *LiveViewerPoller) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, func(ctx context.Context) {
+		result, err := p.PollOnce(ctx)
+		switch {
+		case err != nil:
+			log.Printf("[LiveViewers] Poll failed: %v", err)
+		case result.QuotaExhausted:
+			log.Printf("[LiveViewers] Skipping poll, quota threshold reached")
+		case result.Polled > 0:
+			log.Printf("[LiveViewers] Polled %d live videos: %d sampled, %d ended",
+				result.Polled, result.Sampled, result.Ended)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLiveViewerRepo treats videos as live until a sample records them as no longer live,
+// like the real repository
+type fakeLiveViewerRepo struct {
+	live    []string
+	samples []*model.LiveViewerSample
+}
+
+func (f *fakeLiveViewerRepo) GetLiveVideoIDs(ctx context.Context, limit int) ([]string, error) {
+	ended := make(map[string]bool)
+	for _, sample := range f.samples {
+		if sample.LiveBroadcastContent == nil || *sample.LiveBroadcastContent != "live" {
+			ended[sample.VideoID] = true
+		}
+	}
+
+	var videoIDs []string
+	for _, videoID := range f.live {
+		if !ended[videoID] && len(videoIDs) < limit {
+			videoIDs = append(videoIDs, videoID)
+		}
+	}
+	return videoIDs, nil
+}
+
+func (f *fakeLiveViewerRepo) SaveSamples(ctx context.Context, samples []*model.LiveViewerSample) error {
+	f.samples = append(f.samples, samples...)
+	return nil
+}
+
+func (f *fakeLiveViewerRepo) GetSamplesByVideoID(ctx context.Context, videoID string) ([]*model.LiveViewerSample, error) {
+	var result []*model.LiveViewerSample
+	for _, sample := range f.samples {
+		if sample.VideoID == videoID {
+			result = append(result, sample)
+		}
+	}
+	return result, nil
+}
+
+// fakeLiveStatusFetcher serves the current status of each video and records each call
+type fakeLiveStatusFetcher struct {
+	statuses map[string]*model.LiveStatus
+	calls    [][]string
+}
+
+func (f *fakeLiveStatusFetcher) QuotaCost(operationType string) int {
+	return quota.DefaultCosts.Cost(operationType)
+}
+
+func (f *fakeLiveStatusFetcher) FetchLiveStatus(ctx context.Context, videoIDs []string) ([]*model.LiveStatus, error) {
+	f.calls = append(f.calls, videoIDs)
+
+	var result []*model.LiveStatus
+	for _, videoID := range videoIDs {
+		if status, ok := f.statuses[videoID]; ok {
+			result = append(result, status)
+		}
+	}
+	return result, nil
+}
+
+func TestLiveViewerPoller_StopsWhenStreamEnds(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeLiveViewerRepo{live: []string{"stream1"}}
+	fetcher := &fakeLiveStatusFetcher{statuses: map[string]*model.LiveStatus{
+		"stream1": {VideoID: "stream1", LiveBroadcastContent: "live", ConcurrentViewers: int64Ptr(1200)},
+	}}
+	poller := NewLiveViewerPoller(repo, fetcher, &fakeQuotaReserver{available: true}, 0)
+	ctx := context.Background()
+
+	result, err := poller.PollOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Sampled)
+
+	fetcher.statuses["stream1"].ConcurrentViewers = int64Ptr(1500)
+	_, err = poller.PollOnce(ctx)
+	require.NoError(t, err)
+
+	// The stream ends
+	fetcher.statuses["stream1"] = &model.LiveStatus{VideoID: "stream1", LiveBroadcastContent: "none"}
+	result, err = poller.PollOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Sampled)
+	assert.Equal(t, 1, result.Ended)
+
+	// Nothing is fetched once the stream is no longer live
+	result, err = poller.PollOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, result.Polled)
+	assert.Len(t, fetcher.calls, 3)
+
+	samples, err := repo.GetSamplesByVideoID(ctx, "stream1")
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+	assert.Equal(t, int64(1200), *samples[0].ConcurrentViewers)
+	assert.Equal(t, int64(1500), *samples[1].ConcurrentViewers)
+	assert.Nil(t, samples[2].ConcurrentViewers)
+	assert.Equal(t, "none", *samples[2].LiveBroadcastContent)
+}
+
+func TestLiveViewerPoller_VideoNoLongerReturned(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeLiveViewerRepo{live: []string{"removed1"}}
+	fetcher := &fakeLiveStatusFetcher{statuses: map[string]*model.LiveStatus{}}
+	poller := NewLiveViewerPoller(repo, fetcher, &fakeQuotaReserver{available: true}, 0)
+	poller.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	result, err := poller.PollOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Ended)
+
+	require.Len(t, repo.samples, 1)
+	assert.Nil(t, repo.samples[0].LiveBroadcastContent)
+	assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), repo.samples[0].SampledAt)
+}
+
+func TestLiveViewerPoller_RespectsQuota(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeLiveViewerRepo{live: []string{"stream1"}}
+	fetcher := &fakeLiveStatusFetcher{}
+	poller := NewLiveViewerPoller(repo, fetcher, &fakeQuotaReserver{available: false}, 0)
+
+	result, err := poller.PollOnce(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.QuotaExhausted)
+	assert.Empty(t, fetcher.calls)
+	assert.Empty(t, repo.samples)
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
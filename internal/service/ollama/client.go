@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"syscall"
 	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
@@ -27,7 +30,7 @@ type Config struct {
 	BaseURL string        // e.g., "http://ollama.example.com:11434"
 	Model   string        // e.g., "llama3:8b"
 	APIKey  string        // Optional API key for authentication
-	Timeout time.Duration // Request timeout (default: 60 seconds)
+	Timeout time.Duration // Request timeout (default: 60 seconds); a sooner context deadline takes precedence
 }
 
 // NewClient creates a new Ollama client
@@ -41,12 +44,72 @@ func NewClient(config Config) *Client {
 		model:   config.Model,
 		apiKey:  config.APIKey,
 		timeout: config.Timeout,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
+		// The timeout is applied per call through the request context, together with the
+		// caller's deadline, rather than as an http.Client timeout
+		httpClient: &http.Client{},
 	}
 }
 
+// UnavailableError reports that the Ollama server could not be reached: the connection
+// was refused or the request timed out. Such failures are expected to clear on their own,
+// unlike a bad response from a reachable server.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("ollama unavailable: %v", e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnavailable reports whether err is, or wraps, an UnavailableError
+func IsUnavailable(err error) bool {
+	var unavailable *UnavailableError
+	return errors.As(err, &unavailable)
+}
+
+// TimeoutError reports that a call ran out of time, either the client's Timeout or the
+// deadline of the caller's context, e.g. an asynq task timeout
+type TimeoutError struct {
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("ollama request timed out: %v", e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsTimeout reports whether err is, or wraps, a TimeoutError
+func IsTimeout(err error) bool {
+	var timeout *TimeoutError
+	return errors.As(err, &timeout)
+}
+
+// classifyTransportError wraps connection-refused and timeout errors from the HTTP
+// client in an UnavailableError, with timeouts further wrapped in a TimeoutError.
+// Cancellation of the caller's context is left as is.
+func classifyTransportError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &UnavailableError{Err: &TimeoutError{Err: err}}
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return &UnavailableError{Err: err}
+	}
+
+	return err
+}
+
 // ollamaGenerateRequest represents a request to the Ollama /api/generate endpoint
 type ollamaGenerateRequest struct {
 	Model  string `json:"model"`
@@ -64,14 +127,25 @@ type ollamaGenerateResponse struct {
 }
 
 // AnalyzeVideoForSponsors sends a video title and description to the LLM for sponsor detection
-// Returns the parsed sponsor results, the raw JSON response, and any error
+// Returns the parsed sponsor results, the raw JSON response, and any error.
+// The call is abandoned when ctx is cancelled or when the client timeout or ctx deadline,
+// whichever comes first, passes; running out of time returns a TimeoutError.
 func (c *Client) AnalyzeVideoForSponsors(ctx context.Context, title, description string) (*models.LLMAnalysisResponse, string, error) {
+	return c.AnalyzeVideoForSponsorsWithModel(ctx, c.model, title, description)
+}
+
+// AnalyzeVideoForSponsorsWithModel is AnalyzeVideoForSponsors with the given model in place
+// of the configured one
+func (c *Client) AnalyzeVideoForSponsorsWithModel(ctx context.Context, model, title, description string) (*models.LLMAnalysisResponse, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
 	// Build the prompt
 	prompt := buildSponsorDetectionPrompt(title, description)
 
 	// Create request payload
 	reqPayload := ollamaGenerateRequest{
-		Model:  c.model,
+		Model:  model,
 		Prompt: prompt,
 		Format: "json",
 		Stream: false,
@@ -96,7 +170,7 @@ func (c *Client) AnalyzeVideoForSponsors(ctx context.Context, title, description
 	// Send request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("send request to Ollama: %w", err)
+		return nil, "", fmt.Errorf("send request to Ollama: %w", classifyTransportError(ctx, err))
 	}
 	defer resp.Body.Close()
 
@@ -109,7 +183,7 @@ func (c *Client) AnalyzeVideoForSponsors(ctx context.Context, title, description
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, "", fmt.Errorf("read response body: %w", err)
+		return nil, "", fmt.Errorf("read response body: %w", classifyTransportError(ctx, err))
 	}
 
 	// Parse Ollama response wrapper
@@ -121,10 +195,20 @@ func (c *Client) AnalyzeVideoForSponsors(ctx context.Context, title, description
 	// The actual LLM response is in the "response" field
 	rawLLMResponse := strings.TrimSpace(ollamaResp.Response)
 
-	// Parse the LLM's JSON response into our struct
+	analysisResp, err := ParseAnalysisResponse(rawLLMResponse)
+	if err != nil {
+		return nil, rawLLMResponse, err
+	}
+
+	return analysisResp, rawLLMResponse, nil
+}
+
+// ParseAnalysisResponse parses the raw JSON produced by the LLM into sponsor results.
+// It is also used to re-derive results from a stored llm_response_raw without calling the LLM again.
+func ParseAnalysisResponse(rawLLMResponse string) (*models.LLMAnalysisResponse, error) {
 	var analysisResp models.LLMAnalysisResponse
-	if err := json.Unmarshal([]byte(rawLLMResponse), &analysisResp); err != nil {
-		return nil, rawLLMResponse, fmt.Errorf("parse LLM JSON response: %w (raw: %s)", err, rawLLMResponse)
+	if err := json.Unmarshal([]byte(strings.TrimSpace(rawLLMResponse)), &analysisResp); err != nil {
+		return nil, fmt.Errorf("parse LLM JSON response: %w (raw: %s)", err, rawLLMResponse)
 	}
 
 	// Validate confidence scores are in range [0, 1]
@@ -137,7 +221,7 @@ func (c *Client) AnalyzeVideoForSponsors(ctx context.Context, title, description
 		}
 	}
 
-	return &analysisResp, rawLLMResponse, nil
+	return &analysisResp, nil
 }
 
 // buildSponsorDetectionPrompt constructs the prompt for sponsor detection
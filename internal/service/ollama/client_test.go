@@ -0,0 +1,124 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeVideoForSponsors_UnavailableErrors(t *testing.T) {
+	t.Run("connection refused is unavailable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		client := NewClient(Config{BaseURL: "http://" + addr, Model: "llama3:8b"})
+		_, _, err = client.AnalyzeVideoForSponsors(context.Background(), "Title", "Sponsored by Example")
+
+		require.Error(t, err)
+		assert.True(t, IsUnavailable(err))
+	})
+
+	t.Run("timeout is unavailable", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client := NewClient(Config{BaseURL: server.URL, Model: "llama3:8b", Timeout: 20 * time.Millisecond})
+		_, _, err := client.AnalyzeVideoForSponsors(context.Background(), "Title", "Sponsored by Example")
+
+		require.Error(t, err)
+		assert.True(t, IsUnavailable(err))
+	})
+
+	t.Run("error status is not unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "model not found", http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := NewClient(Config{BaseURL: server.URL, Model: "missing"})
+		_, _, err := client.AnalyzeVideoForSponsors(context.Background(), "Title", "Sponsored by Example")
+
+		require.Error(t, err)
+		assert.False(t, IsUnavailable(err))
+	})
+
+	t.Run("caller cancellation is not unavailable", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		client := NewClient(Config{BaseURL: "http://127.0.0.1:1", Model: "llama3:8b"})
+		_, _, err := client.AnalyzeVideoForSponsors(ctx, "Title", "Sponsored by Example")
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.False(t, IsUnavailable(err))
+	})
+}
+
+func TestAnalyzeVideoForSponsors_Timeouts(t *testing.T) {
+	// slowServer never answers until the test ends or the request is abandoned
+	slowServer := func(t *testing.T) *httptest.Server {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		t.Cleanup(server.Close)
+		t.Cleanup(func() { close(release) })
+		return server
+	}
+
+	t.Run("client timeout is a timeout error", func(t *testing.T) {
+		client := NewClient(Config{BaseURL: slowServer(t).URL, Model: "llama3:8b", Timeout: 20 * time.Millisecond})
+		_, _, err := client.AnalyzeVideoForSponsors(context.Background(), "Title", "Sponsored by Example")
+
+		require.Error(t, err)
+		assert.True(t, IsTimeout(err))
+		assert.True(t, IsUnavailable(err))
+	})
+
+	t.Run("context deadline before client timeout is a timeout error", func(t *testing.T) {
+		client := NewClient(Config{BaseURL: slowServer(t).URL, Model: "llama3:8b", Timeout: time.Minute})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, _, err := client.AnalyzeVideoForSponsors(ctx, "Title", "Sponsored by Example")
+
+		require.Error(t, err)
+		assert.True(t, IsTimeout(err))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("cancellation aborts the call promptly", func(t *testing.T) {
+		client := NewClient(Config{BaseURL: slowServer(t).URL, Model: "llama3:8b", Timeout: time.Minute})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, _, err := client.AnalyzeVideoForSponsors(ctx, "Title", "Sponsored by Example")
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.False(t, IsTimeout(err))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// runPeriodically calls run every interval until ctx is cancelled. The first run is one
+// interval after startup, so restarts do not spend extra API quota.
+func runPeriodically(ctx context.Context, interval time.Duration, run func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run(ctx)
+		}
+	}
+}
+
+// runNowAndPeriodically calls run once at startup and then every interval until ctx is
+// cancelled, for jobs whose first run catches up on what was missed while the process was
+// down.
+func runNowAndPeriodically(ctx context.Context, interval time.Duration, run func(ctx context.Context)) {
+	if ctx.Err() != nil {
+		return
+	}
+	run(ctx)
+	runPeriodically(ctx, interval, run)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPeriodically_WaitsForFirstInterval(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		runPeriodically(ctx, time.Hour, func(context.Context) { runs <- struct{}{} })
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected cancelling ctx to stop the runs")
+	}
+	assert.Empty(t, runs, "expected no run before the first interval")
+}
+
+func TestRunPeriodically_RunsEveryInterval(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := make(chan struct{}, 10)
+	go runPeriodically(ctx, 10*time.Millisecond, func(context.Context) { runs <- struct{}{} })
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("expected run %d", i+1)
+		}
+	}
+}
+
+func TestRunNowAndPeriodically_RunsAtStartupUntilCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		runNowAndPeriodically(ctx, time.Hour, func(context.Context) { runs <- struct{}{} })
+		close(done)
+	}()
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected a run at startup, before the first interval")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected cancelling ctx to stop the runs")
+	}
+	assert.Empty(t, runs, "expected no run after the startup run within the interval")
+}
+
+func TestRunNowAndPeriodically_CancelledContextDoesNotRun(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran := false
+	runNowAndPeriodically(ctx, time.Millisecond, func(context.Context) { ran = true })
+	assert.False(t, ran)
+}
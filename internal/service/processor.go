@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
@@ -24,13 +25,56 @@ type EventProcessor interface {
 	SetQueueClient(client *queue.Client)
 }
 
+// StoredEvent is a webhook event that has been persisted but whose projections are not built yet
+type StoredEvent struct {
+	Event     *models.WebhookEvent
+	VideoData *parser.VideoData
+}
+
+// AsyncEventProcessor is an EventProcessor whose work can be split in two, so a webhook
+// can be acknowledged once the raw event is persisted and projections built afterwards.
+type AsyncEventProcessor interface {
+	EventProcessor
+
+	// StoreEvent parses and persists the raw event. It returns a nil event with a nil error
+	// for duplicates and deleted-video notifications, which need no further processing.
+	StoreEvent(ctx context.Context, rawXML string) (*StoredEvent, error)
+
+	// ProcessStoredEvent updates projections and enqueues enrichment for a stored event.
+	ProcessStoredEvent(ctx context.Context, event *StoredEvent) error
+
+	// UnprocessedEvents loads up to limit stored events that were never processed, oldest
+	// first, such as ones still queued in fast-ack mode when a process stopped. Deleted-video
+	// notifications are marked processed and unparseable events failed instead of being
+	// returned. more reports whether the batch was full, so further events may remain.
+	UnprocessedEvents(ctx context.Context, limit int) (events []*StoredEvent, more bool, err error)
+}
+
+// SkipReasonTooOld is recorded on enrichment jobs skipped because the video is older than MaxEnrichAge
+const SkipReasonTooOld = "skipped_old"
+
+// EventProcessorConfig holds optional settings for the event processor
+type EventProcessorConfig struct {
+	// MaxEnrichAge skips enrichment of new videos published longer ago than this, e.g. the
+	// archive notifications a hub may send after subscribing. Zero enriches every new video.
+	MaxEnrichAge time.Duration
+}
+
+// videoEnqueuer is the part of the queue client the processor uses
+type videoEnqueuer interface {
+	EnqueueVideoEnrichment(ctx context.Context, videoID, channelID string, priority int) error
+	RecordSkippedVideoEnrichment(ctx context.Context, videoID, channelID, reason string) error
+}
+
 type eventProcessor struct {
 	pool             *pgxpool.Pool
 	webhookEventRepo repository.WebhookEventRepository
 	videoRepo        repository.VideoRepository
 	channelRepo      repository.ChannelRepository
 	videoUpdateRepo  repository.VideoUpdateRepository
-	queueClient      *queue.Client // Optional - for enqueueing enrichment jobs
+	queueClient      videoEnqueuer // Optional - for enqueueing enrichment jobs
+	maxEnrichAge     time.Duration
+	now              func() time.Time
 }
 
 // NewEventProcessor creates a new EventProcessor with the given repositories.
@@ -40,7 +84,23 @@ func NewEventProcessor(
 	videoRepo repository.VideoRepository,
 	channelRepo repository.ChannelRepository,
 	videoUpdateRepo repository.VideoUpdateRepository,
-) EventProcessor {
+) AsyncEventProcessor {
+	return NewEventProcessorWithConfig(pool, webhookEventRepo, videoRepo, channelRepo, videoUpdateRepo, EventProcessorConfig{})
+}
+
+// NewEventProcessorWithConfig creates a new EventProcessor with custom settings.
+func NewEventProcessorWithConfig(
+	pool *pgxpool.Pool,
+	webhookEventRepo repository.WebhookEventRepository,
+	videoRepo repository.VideoRepository,
+	channelRepo repository.ChannelRepository,
+	videoUpdateRepo repository.VideoUpdateRepository,
+	config EventProcessorConfig,
+) AsyncEventProcessor {
+	if config.MaxEnrichAge < 0 {
+		config.MaxEnrichAge = 0
+	}
+
 	return &eventProcessor{
 		pool:             pool,
 		webhookEventRepo: webhookEventRepo,
@@ -48,27 +108,43 @@ func NewEventProcessor(
 		channelRepo:      channelRepo,
 		videoUpdateRepo:  videoUpdateRepo,
 		queueClient:      nil, // Will be set via SetQueueClient if available
+		maxEnrichAge:     config.MaxEnrichAge,
+		now:              time.Now,
 	}
 }
 
 // SetQueueClient sets the queue client for enrichment job enqueueing (optional)
 func (p *eventProcessor) SetQueueClient(client *queue.Client) {
+	if client == nil {
+		p.queueClient = nil
+		return
+	}
 	p.queueClient = client
 }
 
 func (p *eventProcessor) ProcessEvent(ctx context.Context, rawXML string) error {
+	event, err := p.StoreEvent(ctx, rawXML)
+	if err != nil || event == nil {
+		return err
+	}
+
+	return p.ProcessStoredEvent(ctx, event)
+}
+
+func (p *eventProcessor) StoreEvent(ctx context.Context, rawXML string) (*StoredEvent, error) {
 	videoData, err := parser.ParseAtomFeed(rawXML)
 	if err != nil {
-		return fmt.Errorf("parse atom feed: %w", err)
+		return nil, recordParseFailure(ctx, p.webhookEventRepo, rawXML, err)
 	}
 
-	// Handle deleted videos - we still create the webhook event but don't update projections
+	// Handle deleted videos - we still create the webhook event (linked to the video and
+	// channel the notification names) but don't update projections
 	if videoData.IsDeleted {
-		_, err := p.webhookEventRepo.CreateWebhookEvent(ctx, rawXML, "", "")
+		_, err := p.webhookEventRepo.CreateWebhookEvent(ctx, rawXML, videoData.VideoID, videoData.ChannelID)
 		if err != nil {
-			return fmt.Errorf("create webhook event for deleted video: %w", err)
+			return nil, fmt.Errorf("create webhook event for deleted video: %w", err)
 		}
-		return nil
+		return nil, nil
 	}
 
 	// Create the webhook event first (outside transaction)
@@ -82,11 +158,57 @@ func (p *eventProcessor) ProcessEvent(ctx context.Context, rawXML string) error
 	if err != nil {
 		// If this is a duplicate, it's not an error - just ignore it
 		if db.IsDuplicateKey(err) {
-			return nil
+			return nil, nil
 		}
-		return fmt.Errorf("create webhook event: %w", err)
+		return nil, fmt.Errorf("create webhook event: %w", err)
+	}
+
+	return &StoredEvent{Event: webhookEvent, VideoData: videoData}, nil
+}
+
+func (p *eventProcessor) UnprocessedEvents(ctx context.Context, limit int) ([]*StoredEvent, bool, error) {
+	stored, err := p.webhookEventRepo.GetUnprocessedEvents(ctx, limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("get unprocessed events: %w", err)
+	}
+
+	events := make([]*StoredEvent, 0, len(stored))
+	for _, event := range stored {
+		// Only a prefix of the notification was stored, which cannot be parsed
+		if event.RawXMLTruncated {
+			msg := fmt.Sprintf("stored raw XML is truncated to %d bytes and cannot be re-parsed", len(event.RawXML))
+			if err := p.webhookEventRepo.MarkEventFailed(ctx, event.ID, models.ErrorCodeTruncatedXML, msg); err != nil {
+				return nil, false, fmt.Errorf("mark event %d failed: %w", event.ID, err)
+			}
+			continue
+		}
+
+		videoData, err := parser.ParseAtomFeed(event.RawXML)
+		if err != nil {
+			parseErr := fmt.Errorf("parse atom feed: %w", err)
+			if err := p.webhookEventRepo.MarkEventFailed(ctx, event.ID, models.ErrorCodeParse, parseErr.Error()); err != nil {
+				return nil, false, fmt.Errorf("mark event %d failed: %w", event.ID, err)
+			}
+			continue
+		}
+
+		// Deleted-video notifications have no projections to build
+		if videoData.IsDeleted {
+			if err := p.webhookEventRepo.MarkEventProcessed(ctx, event.ID, ""); err != nil {
+				return nil, false, fmt.Errorf("mark event %d processed: %w", event.ID, err)
+			}
+			continue
+		}
+
+		events = append(events, &StoredEvent{Event: event, VideoData: videoData})
 	}
 
+	return events, len(stored) == limit, nil
+}
+
+func (p *eventProcessor) ProcessStoredEvent(ctx context.Context, stored *StoredEvent) error {
+	webhookEvent, videoData := stored.Event, stored.VideoData
+
 	// Check if video exists BEFORE processing projections
 	// This is critical for determining if we should enqueue enrichment jobs
 	existingVideo, err := p.videoRepo.GetVideoByID(ctx, videoData.VideoID)
@@ -98,13 +220,17 @@ func (p *eventProcessor) ProcessEvent(ctx context.Context, rawXML string) error
 	// Process projections in a transaction
 	processingErr := p.processProjections(ctx, webhookEvent.ID, videoData)
 
-	// Mark the event as processed (with error if projections failed)
-	var errMsg string
+	// Mark the event as processed (with a categorized error if projections failed)
 	if processingErr != nil {
-		errMsg = processingErr.Error()
+		code := models.ErrorCodeDB
+		if db.IsDuplicateKey(processingErr) {
+			code = models.ErrorCodeDedupConflict
+		}
+		err = p.webhookEventRepo.MarkEventFailed(ctx, webhookEvent.ID, code, processingErr.Error())
+	} else {
+		err = p.webhookEventRepo.MarkEventProcessed(ctx, webhookEvent.ID, "")
 	}
-
-	if err := p.webhookEventRepo.MarkEventProcessed(ctx, webhookEvent.ID, errMsg); err != nil {
+	if err != nil {
 		// Log this but don't return - the event was already created
 		return fmt.Errorf("mark event processed: %w (original error: %v)", err, processingErr)
 	}
@@ -116,14 +242,7 @@ func (p *eventProcessor) ProcessEvent(ctx context.Context, rawXML string) error
 	// Enqueue enrichment job if queue client is available
 	// Only enqueue for new videos to avoid overwhelming the queue
 	if p.queueClient != nil && isNewVideo {
-		log.Printf("[EventProcessor] New video detected: %s (channel: %s), enqueueing enrichment job", videoData.VideoID, videoData.ChannelID)
-		// Enqueue enrichment job (don't fail the webhook if this fails)
-		if err := p.queueClient.EnqueueVideoEnrichment(ctx, videoData.VideoID, videoData.ChannelID, 0); err != nil {
-			log.Printf("[EventProcessor] Failed to enqueue enrichment job for video %s: %v", videoData.VideoID, err)
-			// Don't return error - the video was still processed successfully
-		} else {
-			log.Printf("[EventProcessor] Successfully enqueued enrichment job for new video: %s", videoData.VideoID)
-		}
+		p.enqueueEnrichment(ctx, webhookEvent.ID, videoData)
 	} else if p.queueClient != nil {
 		log.Printf("[EventProcessor] Video %s already exists, skipping enrichment", videoData.VideoID)
 	}
@@ -131,6 +250,52 @@ func (p *eventProcessor) ProcessEvent(ctx context.Context, rawXML string) error
 	return nil
 }
 
+// enqueueEnrichment enqueues enrichment of a new video, or records it as skipped when it
+// was published before the MaxEnrichAge cutoff. Failures are recorded on the event, not returned.
+func (p *eventProcessor) enqueueEnrichment(ctx context.Context, webhookEventID int64, videoData *parser.VideoData) {
+	if p.maxEnrichAge > 0 && !videoData.PublishedAt.IsZero() && videoData.PublishedAt.Before(p.now().Add(-p.maxEnrichAge)) {
+		log.Printf("[EventProcessor] New video %s was published %s, older than the enrichment cutoff, skipping enrichment",
+			videoData.VideoID, videoData.PublishedAt.Format(time.RFC3339))
+		if err := p.queueClient.RecordSkippedVideoEnrichment(ctx, videoData.VideoID, videoData.ChannelID, SkipReasonTooOld); err != nil {
+			log.Printf("[EventProcessor] Failed to record skipped enrichment for video %s: %v", videoData.VideoID, err)
+		}
+		return
+	}
+
+	log.Printf("[EventProcessor] New video detected: %s (channel: %s), enqueueing enrichment job", videoData.VideoID, videoData.ChannelID)
+	// Enqueue enrichment job (don't fail the webhook if this fails)
+	if err := p.queueClient.EnqueueVideoEnrichment(ctx, videoData.VideoID, videoData.ChannelID, 0); err != nil {
+		log.Printf("[EventProcessor] Failed to enqueue enrichment job for video %s: %v", videoData.VideoID, err)
+		// Don't return error - the video was still processed successfully
+		if markErr := p.webhookEventRepo.MarkEventFailed(ctx, webhookEventID, models.ErrorCodeEnqueueFailed, err.Error()); markErr != nil {
+			log.Printf("[EventProcessor] Failed to record enqueue failure for event %d: %v", webhookEventID, markErr)
+		}
+		return
+	}
+	log.Printf("[EventProcessor] Successfully enqueued enrichment job for new video: %s", videoData.VideoID)
+}
+
+// recordParseFailure stores an event whose XML could not be parsed and marks it with
+// ErrorCodeParse, so malformed notifications are kept for inspection. The parse error is returned.
+func recordParseFailure(ctx context.Context, repo repository.WebhookEventRepository, rawXML string, parseErr error) error {
+	parseErr = fmt.Errorf("parse atom feed: %w", parseErr)
+
+	event, err := repo.CreateWebhookEvent(ctx, rawXML, "", "")
+	if err != nil {
+		// A duplicate was already recorded on an earlier delivery
+		if !db.IsDuplicateKey(err) {
+			log.Printf("[EventProcessor] Failed to store unparseable webhook event: %v", err)
+		}
+		return parseErr
+	}
+
+	if err := repo.MarkEventFailed(ctx, event.ID, models.ErrorCodeParse, parseErr.Error()); err != nil {
+		log.Printf("[EventProcessor] Failed to record parse error for event %d: %v", event.ID, err)
+	}
+
+	return parseErr
+}
+
 func (p *eventProcessor) processProjections(ctx context.Context, webhookEventID int64, videoData *parser.VideoData) error {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
@@ -145,9 +310,11 @@ func (p *eventProcessor) processProjections(ctx context.Context, webhookEventID
 
 	updateType := p.determineUpdateType(existingVideo, videoData)
 
+	// The feed's author name is the channel title; when it is missing the upsert keeps any
+	// title already stored and enrichment backfills it later
 	channel := models.NewChannel(
 		videoData.ChannelID,
-		"", // Channel title not available in feed
+		videoData.AuthorName,
 		fmt.Sprintf("https://www.youtube.com/channel/%s", videoData.ChannelID),
 	)
 	if err := p.channelRepo.UpsertChannel(ctx, channel); err != nil {
@@ -196,3 +363,35 @@ func (p *eventProcessor) determineUpdateType(existingVideo *models.Video, videoD
 
 	return models.UpdateTypeUnknown
 }
+
+// ingestOnlyProcessor archives webhook events without building projections or
+// enqueueing enrichment, for deployments that process events later in batches.
+type ingestOnlyProcessor struct {
+	webhookEventRepo repository.WebhookEventRepository
+}
+
+// NewIngestOnlyProcessor creates an EventProcessor that only stores the raw event with its
+// parsed video and channel IDs. Events are left unprocessed so a later batch run can pick
+// them up with GetUnprocessedEvents; videos, channels and video updates are not written.
+func NewIngestOnlyProcessor(webhookEventRepo repository.WebhookEventRepository) EventProcessor {
+	return &ingestOnlyProcessor{webhookEventRepo: webhookEventRepo}
+}
+
+// SetQueueClient is a no-op: ingest-only mode never enqueues jobs
+func (p *ingestOnlyProcessor) SetQueueClient(client *queue.Client) {}
+
+func (p *ingestOnlyProcessor) ProcessEvent(ctx context.Context, rawXML string) error {
+	videoData, err := parser.ParseAtomFeed(rawXML)
+	if err != nil {
+		return recordParseFailure(ctx, p.webhookEventRepo, rawXML, err)
+	}
+
+	if _, err := p.webhookEventRepo.CreateWebhookEvent(ctx, rawXML, videoData.VideoID, videoData.ChannelID); err != nil {
+		if db.IsDuplicateKey(err) {
+			return nil
+		}
+		return fmt.Errorf("create webhook event: %w", err)
+	}
+
+	return nil
+}
@@ -3,12 +3,14 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/parser"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -38,6 +40,16 @@ func (m *mockWebhookEventRepo) MarkEventProcessed(ctx context.Context, eventID i
 	return args.Error(0)
 }
 
+func (m *mockWebhookEventRepo) MarkEventFailed(ctx context.Context, eventID int64, code models.ProcessingErrorCode, processingError string) error {
+	args := m.Called(ctx, eventID, code, processingError)
+	return args.Error(0)
+}
+
+func (m *mockWebhookEventRepo) MarkEventsProcessed(ctx context.Context, ids []int64, receivedBefore *time.Time) (int64, error) {
+	args := m.Called(ctx, ids, receivedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *mockWebhookEventRepo) GetEventByID(ctx context.Context, eventID int64) (*models.WebhookEvent, error) {
 	args := m.Called(ctx, eventID)
 	if args.Get(0) == nil {
@@ -69,6 +81,19 @@ func (m *mockWebhookEventRepo) List(ctx context.Context, filters *repository.Web
 	return args.Get(0).([]*models.WebhookEvent), args.Int(1), args.Error(2)
 }
 
+func (m *mockWebhookEventRepo) GetEventsMissingVideoID(ctx context.Context, afterID int64, limit int) ([]*models.WebhookEvent, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookEvent), args.Error(1)
+}
+
+func (m *mockWebhookEventRepo) SetEventLinkage(ctx context.Context, eventID int64, videoID, channelID string) error {
+	args := m.Called(ctx, eventID, videoID, channelID)
+	return args.Error(0)
+}
+
 type mockVideoRepo struct {
 	mock.Mock
 }
@@ -86,6 +111,14 @@ func (m *mockVideoRepo) GetVideoByID(ctx context.Context, videoID string) (*mode
 	return args.Get(0).(*models.Video), args.Error(1)
 }
 
+func (m *mockVideoRepo) GetVideosByIDs(ctx context.Context, videoIDs []string) (map[string]*models.Video, error) {
+	args := m.Called(ctx, videoIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*models.Video), args.Error(1)
+}
+
 func (m *mockVideoRepo) GetVideosByChannelID(ctx context.Context, channelID string, limit int) ([]*models.Video, error) {
 	args := m.Called(ctx, channelID, limit)
 	return args.Get(0).([]*models.Video), args.Error(1)
@@ -116,6 +149,11 @@ func (m *mockVideoRepo) Delete(ctx context.Context, videoID string) error {
 	return args.Error(0)
 }
 
+func (m *mockVideoRepo) SoftDelete(ctx context.Context, videoID string, deletedAt time.Time) error {
+	args := m.Called(ctx, videoID, deletedAt)
+	return args.Error(0)
+}
+
 func (m *mockVideoRepo) List(ctx context.Context, filters *repository.VideoFilters) ([]*models.Video, int, error) {
 	args := m.Called(ctx, filters)
 	if args.Get(0) == nil {
@@ -124,6 +162,21 @@ func (m *mockVideoRepo) List(ctx context.Context, filters *repository.VideoFilte
 	return args.Get(0).([]*models.Video), args.Int(1), args.Error(2)
 }
 
+func (m *mockVideoRepo) GetVideosForAvailabilityCheck(ctx context.Context, publishedBefore, checkedBefore time.Time, limit int) ([]*models.Video, error) {
+	args := m.Called(ctx, publishedBefore, checkedBefore, limit)
+	return args.Get(0).([]*models.Video), args.Error(1)
+}
+
+func (m *mockVideoRepo) MarkAvailabilityChecked(ctx context.Context, videoIDs []string, checkedAt time.Time) error {
+	args := m.Called(ctx, videoIDs, checkedAt)
+	return args.Error(0)
+}
+
+func (m *mockVideoRepo) MarkUnavailable(ctx context.Context, videoID, reason string, unavailableAt time.Time) error {
+	args := m.Called(ctx, videoID, reason, unavailableAt)
+	return args.Error(0)
+}
+
 type mockChannelRepo struct {
 	mock.Mock
 }
@@ -155,6 +208,24 @@ func (m *mockChannelRepo) GetChannelsByLastUpdated(ctx context.Context, since ti
 	return args.Get(0).([]*models.Channel), args.Error(1)
 }
 
+func (m *mockChannelRepo) BackfillTitle(ctx context.Context, channelID, title string) (bool, error) {
+	args := m.Called(ctx, channelID, title)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockChannelRepo) RecordTitleChange(ctx context.Context, channelID, title string) (*models.ChannelNameChange, error) {
+	args := m.Called(ctx, channelID, title)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChannelNameChange), args.Error(1)
+}
+
+func (m *mockChannelRepo) GetNameHistory(ctx context.Context, channelID string) ([]*models.ChannelNameChange, error) {
+	args := m.Called(ctx, channelID)
+	return args.Get(0).([]*models.ChannelNameChange), args.Error(1)
+}
+
 func (m *mockChannelRepo) Create(ctx context.Context, channel *models.Channel) error {
 	args := m.Called(ctx, channel)
 	return args.Error(0)
@@ -226,11 +297,21 @@ func TestEventProcessor_ProcessEvent_InvalidXML(t *testing.T) {
 	channelRepo := new(mockChannelRepo)
 	videoUpdateRepo := new(mockVideoUpdateRepo)
 
+	webhookEventRepo.On("CreateWebhookEvent", mock.Anything, "invalid xml", "", "").
+		Return(&models.WebhookEvent{ID: 7, RawXML: "invalid xml"}, nil)
+	webhookEventRepo.On("MarkEventFailed", mock.Anything, int64(7), models.ErrorCodeParse, mock.MatchedBy(func(msg string) bool {
+		return strings.HasPrefix(msg, "parse atom feed")
+	})).Return(nil)
+
 	processor := NewEventProcessor(nil, webhookEventRepo, videoRepo, channelRepo, videoUpdateRepo)
 
 	err := processor.ProcessEvent(context.Background(), "invalid xml")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "parse atom feed")
+
+	// The unparseable event is kept and categorized as a parse error
+	webhookEventRepo.AssertExpectations(t)
+	videoRepo.AssertNotCalled(t, "GetVideoByID", mock.Anything, mock.Anything)
 }
 
 func TestEventProcessor_ProcessEvent_DeletedVideo(t *testing.T) {
@@ -251,7 +332,7 @@ func TestEventProcessor_ProcessEvent_DeletedVideo(t *testing.T) {
 		RawXML: deletedXML,
 	}
 
-	webhookEventRepo.On("CreateWebhookEvent", mock.Anything, deletedXML, "", "").
+	webhookEventRepo.On("CreateWebhookEvent", mock.Anything, deletedXML, "deleted123", "").
 		Return(webhookEvent, nil)
 
 	processor := NewEventProcessor(nil, webhookEventRepo, videoRepo, channelRepo, videoUpdateRepo)
@@ -266,6 +347,67 @@ func TestEventProcessor_ProcessEvent_DeletedVideo(t *testing.T) {
 	videoUpdateRepo.AssertNotCalled(t, "CreateVideoUpdate")
 }
 
+func TestEventProcessor_StoreEvent_StoresVideoAndChannelIDs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		rawXML        string
+		wantVideoID   string
+		wantChannelID string
+	}{
+		{
+			name: "new or updated video",
+			rawXML: `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>test123</yt:videoId>
+    <yt:channelId>UCtest</yt:channelId>
+    <title>Test Video</title>
+    <published>2025-01-15T10:00:00+00:00</published>
+    <updated>2025-01-15T11:00:00+00:00</updated>
+  </entry>
+</feed>`,
+			wantVideoID:   "test123",
+			wantChannelID: "UCtest",
+		},
+		{
+			name: "deleted video",
+			rawXML: `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <yt:deleted-entry ref="yt:video:deleted123" when="2025-01-15T12:00:00+00:00">
+    <yt:by>
+      <name>Test Channel</name>
+      <uri>https://www.youtube.com/channel/UCtest</uri>
+    </yt:by>
+  </yt:deleted-entry>
+</feed>`,
+			wantVideoID:   "deleted123",
+			wantChannelID: "UCtest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			webhookEventRepo := new(mockWebhookEventRepo)
+			// Reporting a duplicate ends processing once the event row has been written
+			webhookEventRepo.On("CreateWebhookEvent", mock.Anything, tt.rawXML, tt.wantVideoID, tt.wantChannelID).
+				Return(nil, db.ErrDuplicateKey).Twice()
+
+			for _, processor := range []EventProcessor{
+				NewEventProcessor(nil, webhookEventRepo, nil, nil, nil),
+				NewIngestOnlyProcessor(webhookEventRepo),
+			} {
+				_ = processor.ProcessEvent(context.Background(), tt.rawXML)
+			}
+
+			webhookEventRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestEventProcessor_ProcessEvent_DuplicateEvent(t *testing.T) {
 	t.Parallel()
 
@@ -391,3 +533,107 @@ func TestEventProcessor_ProcessEvent_CreateEventError(t *testing.T) {
 
 	webhookEventRepo.AssertExpectations(t)
 }
+
+// fakeVideoEnqueuer records enqueued and skipped video enrichments
+type fakeVideoEnqueuer struct {
+	enqueued []string
+	skipped  map[string]string
+}
+
+func (f *fakeVideoEnqueuer) EnqueueVideoEnrichment(ctx context.Context, videoID, channelID string, priority int) error {
+	f.enqueued = append(f.enqueued, videoID)
+	return nil
+}
+
+func (f *fakeVideoEnqueuer) RecordSkippedVideoEnrichment(ctx context.Context, videoID, channelID, reason string) error {
+	f.skipped[videoID] = reason
+	return nil
+}
+
+func TestEventProcessor_EnqueueEnrichment_MaxEnrichAge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+	enqueuer := &fakeVideoEnqueuer{skipped: make(map[string]string)}
+
+	processor := NewEventProcessorWithConfig(nil, new(mockWebhookEventRepo), new(mockVideoRepo), new(mockChannelRepo), new(mockVideoUpdateRepo),
+		EventProcessorConfig{MaxEnrichAge: 30 * 24 * time.Hour}).(*eventProcessor)
+	processor.queueClient = enqueuer
+	processor.now = func() time.Time { return now }
+
+	processor.enqueueEnrichment(context.Background(), 1, &parser.VideoData{
+		VideoID: "archived", ChannelID: "UCtest", PublishedAt: now.AddDate(-2, 0, 0),
+	})
+	processor.enqueueEnrichment(context.Background(), 2, &parser.VideoData{
+		VideoID: "recent", ChannelID: "UCtest", PublishedAt: now.Add(-24 * time.Hour),
+	})
+
+	assert.Equal(t, []string{"recent"}, enqueuer.enqueued)
+	assert.Equal(t, map[string]string{"archived": SkipReasonTooOld}, enqueuer.skipped)
+}
+
+func TestEventProcessor_UnprocessedEvents(t *testing.T) {
+	t.Parallel()
+
+	updateXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>queued123</yt:videoId>
+    <yt:channelId>UCqueued</yt:channelId>
+    <title>Queued Video</title>
+    <published>2025-01-15T10:00:00+00:00</published>
+    <updated>2025-01-15T11:00:00+00:00</updated>
+  </entry>
+</feed>`
+	deletedXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <yt:deleted-entry ref="yt:video:deleted123" when="2025-01-15T12:00:00+00:00"></yt:deleted-entry>
+</feed>`
+
+	webhookEventRepo := new(mockWebhookEventRepo)
+	webhookEventRepo.On("GetUnprocessedEvents", mock.Anything, 3).Return([]*models.WebhookEvent{
+		{ID: 1, RawXML: updateXML},
+		{ID: 2, RawXML: deletedXML},
+		{ID: 3, RawXML: "<feed"},
+	}, nil)
+	webhookEventRepo.On("MarkEventProcessed", mock.Anything, int64(2), "").Return(nil)
+	webhookEventRepo.On("MarkEventFailed", mock.Anything, int64(3), models.ErrorCodeParse, mock.Anything).Return(nil)
+
+	processor := NewEventProcessor(nil, webhookEventRepo, nil, nil, nil)
+
+	events, more, err := processor.UnprocessedEvents(context.Background(), 3)
+	require.NoError(t, err)
+	assert.True(t, more, "a full batch may have more events behind it")
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(1), events[0].Event.ID)
+	assert.Equal(t, "queued123", events[0].VideoData.VideoID)
+
+	webhookEventRepo.AssertExpectations(t)
+}
+
+func TestEventProcessor_UnprocessedEvents_TruncatedXML(t *testing.T) {
+	t.Parallel()
+
+	truncatedXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>truncated123</yt:videoId>`
+
+	webhookEventRepo := new(mockWebhookEventRepo)
+	webhookEventRepo.On("GetUnprocessedEvents", mock.Anything, 10).Return([]*models.WebhookEvent{
+		{ID: 4, RawXML: truncatedXML, RawXMLTruncated: true},
+	}, nil)
+	webhookEventRepo.On("MarkEventFailed", mock.Anything, int64(4), models.ErrorCodeTruncatedXML, mock.MatchedBy(func(msg string) bool {
+		return strings.Contains(msg, "truncated")
+	})).Return(nil)
+
+	processor := NewEventProcessor(nil, webhookEventRepo, nil, nil, nil)
+
+	events, more, err := processor.UnprocessedEvents(context.Background(), 10)
+	require.NoError(t, err)
+	assert.False(t, more)
+	assert.Empty(t, events)
+
+	webhookEventRepo.AssertExpectations(t)
+	webhookEventRepo.AssertNotCalled(t, "MarkEventFailed", mock.Anything, int64(4), models.ErrorCodeParse, mock.Anything)
+}
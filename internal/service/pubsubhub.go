@@ -31,23 +31,56 @@ type PubSubHub interface {
 	Unsubscribe(ctx context.Context, req *SubscribeRequest) (*SubscribeResponse, error)
 }
 
+// userAgentProduct is the product name in the default User-Agent of hub requests.
+const userAgentProduct = "youtube-webhook-ingestion"
+
+// DefaultUserAgent returns the User-Agent sent to the hub for the given build version,
+// e.g. "youtube-webhook-ingestion/v1.2.0".
+func DefaultUserAgent(version string) string {
+	if version == "" {
+		version = "dev"
+	}
+	return userAgentProduct + "/" + version
+}
+
+// PubSubHubConfig holds optional settings for PubSubHubService.
+type PubSubHubConfig struct {
+	// UserAgent is sent as the User-Agent header. Defaults to DefaultUserAgent("dev").
+	UserAgent string
+
+	// Headers are additional headers sent on every hub request, such as Referer.
+	Headers map[string]string
+}
+
 // PubSubHubService handles interactions with the PubSubHubbub hub.
 type PubSubHubService struct {
-	client HTTPClient
-	logger *slog.Logger
+	client    HTTPClient
+	logger    *slog.Logger
+	userAgent string
+	headers   map[string]string
 }
 
 // NewPubSubHubService creates a new PubSubHubService.
 func NewPubSubHubService(client HTTPClient, logger *slog.Logger) *PubSubHubService {
+	return NewPubSubHubServiceWithConfig(client, logger, PubSubHubConfig{})
+}
+
+// NewPubSubHubServiceWithConfig creates a new PubSubHubService with custom request headers.
+func NewPubSubHubServiceWithConfig(client HTTPClient, logger *slog.Logger, config PubSubHubConfig) *PubSubHubService {
 	if client == nil {
 		client = &http.Client{}
 	}
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent("")
+	}
 	return &PubSubHubService{
-		client: client,
-		logger: logger,
+		client:    client,
+		logger:    logger,
+		userAgent: config.UserAgent,
+		headers:   config.Headers,
 	}
 }
 
@@ -97,7 +130,7 @@ func (s *PubSubHubService) Subscribe(ctx context.Context, req *SubscribeRequest)
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.setHeaders(httpReq)
 
 	s.logger.Info("sending subscription request to hub",
 		"hub_url", req.HubURL,
@@ -188,7 +221,7 @@ func (s *PubSubHubService) Unsubscribe(ctx context.Context, req *SubscribeReques
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.setHeaders(httpReq)
 
 	s.logger.Info("sending unsubscription request to hub",
 		"hub_url", req.HubURL,
@@ -232,6 +265,16 @@ func (s *PubSubHubService) Unsubscribe(ctx context.Context, req *SubscribeReques
 	return response, nil
 }
 
+// setHeaders sets the configured headers on a hub request. The form content type
+// is always set last so extra headers cannot break the request body encoding.
+func (s *PubSubHubService) setHeaders(httpReq *http.Request) {
+	for name, value := range s.headers {
+		httpReq.Header.Set(name, value)
+	}
+	httpReq.Header.Set("User-Agent", s.userAgent)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+}
+
 // validateRequest validates the subscription request parameters.
 func (s *PubSubHubService) validateRequest(req *SubscribeRequest) error {
 	if req == nil {
@@ -427,3 +427,67 @@ func TestPubSubHubService_Subscribe_FormData(t *testing.T) {
 	assert.True(t, result.Accepted)
 	client.AssertExpectations(t)
 }
+
+func TestPubSubHubService_Subscribe_RequestHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		config        PubSubHubConfig
+		wantUserAgent string
+		wantReferer   string
+	}{
+		{
+			name:          "default user agent",
+			config:        PubSubHubConfig{},
+			wantUserAgent: "youtube-webhook-ingestion/dev",
+		},
+		{
+			name: "configured user agent and referer",
+			config: PubSubHubConfig{
+				UserAgent: "my-ingester/1.2.3 (+https://example.com)",
+				Headers:   map[string]string{"Referer": "https://example.com"},
+			},
+			wantUserAgent: "my-ingester/1.2.3 (+https://example.com)",
+			wantReferer:   "https://example.com",
+		},
+		{
+			name: "extra headers cannot override the content type",
+			config: PubSubHubConfig{
+				Headers: map[string]string{"Content-Type": "text/plain"},
+			},
+			wantUserAgent: "youtube-webhook-ingestion/dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := new(mockHTTPClient)
+			service := NewPubSubHubServiceWithConfig(client, nil, tt.config)
+
+			req := &SubscribeRequest{
+				HubURL:       "https://pubsubhubbub.appspot.com/subscribe",
+				TopicURL:     "https://www.youtube.com/xml/feeds/videos.xml?channel_id=UCtest",
+				CallbackURL:  "https://example.com/webhook",
+				LeaseSeconds: 432000,
+			}
+
+			resp := &http.Response{
+				StatusCode: http.StatusAccepted,
+				Body:       io.NopCloser(bytes.NewBufferString("")),
+			}
+			client.On("Do", mock.MatchedBy(func(r *http.Request) bool {
+				return r.Header.Get("User-Agent") == tt.wantUserAgent &&
+					r.Header.Get("Referer") == tt.wantReferer &&
+					r.Header.Get("Content-Type") == "application/x-www-form-urlencoded"
+			})).Return(resp, nil)
+
+			_, err := service.Subscribe(context.Background(), req)
+
+			require.NoError(t, err)
+			client.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package quota
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operation types under which YouTube Data API usage is recorded
+const (
+	OpVideosList          = "videos_list"
+	OpChannelsList        = "channels_list"
+	OpSearchList          = "search_list"
+	OpCaptionsList        = "captions_list"
+	OpVideoCategoriesList = "video_categories_list"
+)
+
+// CostTable maps operation types to their quota cost in units
+type CostTable map[string]int
+
+// DefaultCosts are the costs Google documents at
+// https://developers.google.com/youtube/v3/determine_quota_cost. The cost of a list call does
+// not depend on the parts requested.
+var DefaultCosts = CostTable{
+	OpVideosList:          1,
+	OpChannelsList:        1,
+	OpSearchList:          100,
+	OpCaptionsList:        50,
+	OpVideoCategoriesList: 1,
+}
+
+// Cost returns the quota cost of an operation. Operations missing from the table, or a nil
+// table, fall back to DefaultCosts, and unknown operations cost 1 unit.
+func (t CostTable) Cost(operationType string) int {
+	if cost, ok := t[operationType]; ok {
+		return cost
+	}
+	if cost, ok := DefaultCosts[operationType]; ok {
+		return cost
+	}
+	return 1
+}
+
+// ParseCostTable parses comma-separated operation=cost overrides such as
+// "search_list=100,captions_list=50" into a table. Operations not listed keep their default cost.
+func ParseCostTable(s string) (CostTable, error) {
+	table := make(CostTable, len(DefaultCosts))
+	for op, cost := range DefaultCosts {
+		table[op] = cost
+	}
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		op, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid quota cost %q (expected operation=cost)", entry)
+		}
+		op = strings.TrimSpace(op)
+		if _, known := DefaultCosts[op]; !known {
+			return nil, fmt.Errorf("unknown quota operation %q", op)
+		}
+		cost, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("invalid quota cost for %s: %q", op, value)
+		}
+		table[op] = cost
+	}
+
+	return table, nil
+}
@@ -0,0 +1,36 @@
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostTable_Cost(t *testing.T) {
+	var unset CostTable
+	assert.Equal(t, 100, unset.Cost(OpSearchList), "a nil table uses the default costs")
+	assert.Equal(t, 1, unset.Cost("unknown_operation"))
+
+	table := CostTable{OpSearchList: 150}
+	assert.Equal(t, 150, table.Cost(OpSearchList))
+	assert.Equal(t, 50, table.Cost(OpCaptionsList), "unlisted operations keep their default cost")
+}
+
+func TestParseCostTable(t *testing.T) {
+	table, err := ParseCostTable(" search_list = 150, videos_list=2 ,")
+	require.NoError(t, err)
+	assert.Equal(t, 150, table.Cost(OpSearchList))
+	assert.Equal(t, 2, table.Cost(OpVideosList))
+	assert.Equal(t, 1, table.Cost(OpChannelsList))
+	assert.Equal(t, 100, DefaultCosts[OpSearchList], "overrides do not change the defaults")
+
+	table, err = ParseCostTable("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCosts, table)
+
+	for _, invalid := range []string{"search_list", "search_list=abc", "search_list=-1", "playlists_list=1"} {
+		_, err := ParseCostTable(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
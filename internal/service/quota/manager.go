@@ -2,18 +2,63 @@ package quota
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
 	"ad-tracker/youtube-webhook-ingestion/internal/model"
 )
 
+// ErrQuotaExhausted is returned when an operation is refused because the quota threshold for its priority was reached
+var ErrQuotaExhausted = errors.New("youtube api quota exhausted")
+
+// Priority determines which threshold an operation is checked against
+type Priority int
+
+const (
+	// PriorityBackground is for queued work such as enrichment. It stops at the
+	// threshold minus the interactive reserve.
+	PriorityBackground Priority = iota
+
+	// PriorityInteractive is for user-initiated calls such as channel resolution.
+	// It may use the reserve up to the full threshold.
+	PriorityInteractive
+)
+
 // Manager handles YouTube API quota management
 type Manager struct {
 	repo             repository.QuotaRepository
 	dailyLimit       int
 	thresholdPercent int // Stop processing when this % of quota is used
+
+	// interactiveReservePercent is the share of the daily quota below the threshold
+	// that background work may not use, so interactive calls are not starved
+	interactiveReservePercent int
+
+	// In-memory usage cache, enabled by SetSyncInterval. Checks and recorded usage are
+	// served from synced plus pending; pending usage is written to the repository by Sync.
+	mu           sync.Mutex
+	syncInterval time.Duration
+	synced       *model.QuotaInfo // today's usage as last read from the repository
+	syncedAt     time.Time
+	pending      map[string]*pendingUsage // recorded usage not yet persisted, by operation type
+	pendingDate  time.Time                // quota day the pending usage belongs to
+	pendingCost  int
+	pendingOps   int
+	now          func() time.Time
+
+	// held is quota reserved by Reserve for calls not recorded yet. It counts against the
+	// threshold of further reservations until the calls are recorded or the reservation is released.
+	held int
+}
+
+// pendingUsage is usage of one operation type recorded in memory but not yet persisted
+type pendingUsage struct {
+	cost  int
+	calls int
 }
 
 // NewManager creates a new quota manager
@@ -29,38 +74,305 @@ func NewManager(repo repository.QuotaRepository, dailyLimit int, thresholdPercen
 		repo:             repo,
 		dailyLimit:       dailyLimit,
 		thresholdPercent: thresholdPercent,
+		pending:          make(map[string]*pendingUsage),
+		now:              time.Now,
+	}
+}
+
+// SetSyncInterval keeps quota usage in memory and reconciles it with the repository at most
+// once per interval, and when the day changes. Checks then cost no query; usage recorded by
+// other processes is seen after the next sync. Zero (the default) reads and writes the
+// repository on every call.
+func (m *Manager) SetSyncInterval(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.syncInterval = interval
+}
+
+// Sync writes usage recorded in memory to the repository and re-reads today's usage.
+// It is a no-op when the cache is disabled.
+func (m *Manager) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.syncInterval <= 0 {
+		return nil
 	}
+	return m.syncLocked(ctx)
 }
 
-// CheckQuotaAvailable checks if there's enough quota to proceed
+func (m *Manager) syncLocked(ctx context.Context) error {
+	// Pending usage is written against the day it was recorded on, so usage recorded
+	// before midnight and flushed after it is not counted against the new day
+	for operationType, usage := range m.pending {
+		var err error
+		if m.pendingDate.IsZero() {
+			err = m.repo.IncrementQuota(ctx, usage.cost, operationType)
+		} else {
+			err = m.repo.IncrementQuotaForDate(ctx, m.pendingDate, usage.cost, usage.calls, operationType)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to persist quota usage: %w", err)
+		}
+		delete(m.pending, operationType)
+		m.pendingCost -= usage.cost
+		m.pendingOps -= usage.calls
+	}
+
+	info, err := m.repo.GetTodaysQuota(ctx)
+	if err != nil {
+		return err
+	}
+	m.synced = info
+	m.syncedAt = m.now()
+	return nil
+}
+
+// usageLocked returns today's usage including pending usage, syncing first when the
+// snapshot is older than the sync interval or its quota day has ended. The caller holds m.mu.
+func (m *Manager) usageLocked(ctx context.Context) (*model.QuotaInfo, error) {
+	now := m.now()
+	if m.synced == nil || now.Sub(m.syncedAt) >= m.syncInterval || dayEnded(m.synced, now) {
+		if err := m.syncLocked(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	info := *m.synced
+	info.QuotaUsed += m.pendingCost
+	info.QuotaRemaining -= m.pendingCost
+	info.OperationsCount += m.pendingOps
+	return &info, nil
+}
+
+// dayEnded reports whether the quota day of info, which follows the database's timezone,
+// is over at now. A snapshot without a reset time never ends early.
+func dayEnded(info *model.QuotaInfo, now time.Time) bool {
+	return !info.ResetsAt.IsZero() && !now.Before(info.ResetsAt)
+}
+
+// usage returns today's usage from the cache when it is enabled, or from the repository
+func (m *Manager) usage(ctx context.Context) (*model.QuotaInfo, error) {
+	m.mu.Lock()
+	if m.syncInterval <= 0 {
+		m.mu.Unlock()
+		return m.repo.GetTodaysQuota(ctx)
+	}
+	defer m.mu.Unlock()
+	return m.usageLocked(ctx)
+}
+
+// SetInteractiveReserve reserves percent of the daily quota for interactive operations.
+// Background operations stop at thresholdPercent-percent; interactive ones at thresholdPercent.
+func (m *Manager) SetInteractiveReserve(percent int) {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > m.thresholdPercent {
+		percent = m.thresholdPercent
+	}
+	m.interactiveReservePercent = percent
+}
+
+// threshold returns the quota usage at which operations of the given priority stop
+func (m *Manager) threshold(priority Priority) int {
+	percent := m.thresholdPercent
+	if priority == PriorityBackground {
+		percent -= m.interactiveReservePercent
+	}
+	return (m.dailyLimit * percent) / 100
+}
+
+// CheckQuotaAvailable checks if there's enough quota to proceed with background work
 // Returns true if quota is available, false otherwise
 func (m *Manager) CheckQuotaAvailable(ctx context.Context, requiredQuota int) (bool, *model.QuotaInfo, error) {
-	info, err := m.repo.GetTodaysQuota(ctx)
+	return m.CheckQuotaAvailableFor(ctx, PriorityBackground, requiredQuota)
+}
+
+// CheckQuotaAvailableFor checks if there's enough quota to proceed with an operation of the given priority
+func (m *Manager) CheckQuotaAvailableFor(ctx context.Context, priority Priority, requiredQuota int) (bool, *model.QuotaInfo, error) {
+	info, err := m.usage(ctx)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to get quota info: %w", err)
 	}
 
+	return m.fits(info, priority, requiredQuota), info, nil
+}
+
+// Reserve holds quotaCost for operationType if it fits under the threshold for priority,
+// counting the units already held by other reservations. The check and the hold happen under
+// one lock, so concurrent callers in this process cannot overshoot the threshold between them.
+//
+// The returned context carries the reservation: usage recorded with it by RecordQuotaUsage,
+// e.g. by the YouTube client making the reserved call, is recorded as usual and taken off the
+// hold. Callers call Release with the context once the work is done, freeing the units the
+// calls did not use.
+func (m *Manager) Reserve(ctx context.Context, priority Priority, quotaCost int, operationType string) (context.Context, bool, error) {
+	return m.ReserveUsage(ctx, priority, Usage{operationType: quotaCost})
+}
+
+// ReserveUsage is Reserve for work that spans several operation types, such as resolving a
+// channel URL with search.list and channels.list. Either all of usage is held or none.
+func (m *Manager) ReserveUsage(ctx context.Context, priority Priority, usage Usage) (context.Context, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var info *model.QuotaInfo
+	var err error
+	if m.syncInterval <= 0 {
+		info, err = m.repo.GetTodaysQuota(ctx)
+	} else {
+		info, err = m.usageLocked(ctx)
+	}
+	if err != nil {
+		return ctx, false, fmt.Errorf("failed to get quota info: %w", err)
+	}
+
+	held := *info
+	held.QuotaUsed += m.held
+	held.QuotaRemaining -= m.held
+	usage = usage.normalized()
+	if !m.fits(&held, priority, usage.Total()) {
+		return ctx, false, nil
+	}
+
+	m.held += usage.Total()
+	return context.WithValue(ctx, reservationKey{}, &reservation{manager: m, remaining: usage}), true, nil
+}
+
+// Release frees the units of the reservation in ctx that were not used by the calls it covered.
+// It is a no-op for a context without a reservation or one already released.
+func Release(ctx context.Context) {
+	r, ok := ctx.Value(reservationKey{}).(*reservation)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	unused := r.remaining.Total()
+	r.remaining = Usage{}
+	r.mu.Unlock()
+	r.manager.unhold(unused)
+}
+
+// unhold takes units off the quota held by reservations
+func (m *Manager) unhold(units int) {
+	if units == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.held -= units
+}
+
+// Usage is an amount of quota by operation type
+type Usage map[string]int
+
+// Total returns the quota of all operation types
+func (u Usage) Total() int {
+	total := 0
+	for _, quotaCost := range u {
+		total += quotaCost
+	}
+	return total
+}
+
+// normalized returns a copy of u with usage of an unnamed operation type recorded as "other"
+func (u Usage) normalized() Usage {
+	out := make(Usage, len(u))
+	for operationType, quotaCost := range u {
+		if operationType == "" {
+			operationType = "other"
+		}
+		out[operationType] += quotaCost
+	}
+	return out
+}
+
+// reservationKey is the context key of the reservation made by Reserve
+type reservationKey struct{}
+
+// reservation is quota held for calls that have not been recorded yet
+type reservation struct {
+	manager   *Manager
+	mu        sync.Mutex
+	remaining Usage
+}
+
+// drawReserved takes up to quotaCost units of operationType off the hold of the reservation in
+// ctx, once the usage has been recorded
+func drawReserved(ctx context.Context, quotaCost int, operationType string) {
+	r, ok := ctx.Value(reservationKey{}).(*reservation)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	drawn := min(r.remaining[operationType], quotaCost)
+	r.remaining[operationType] -= drawn
+	r.mu.Unlock()
+	r.manager.unhold(drawn)
+}
+
+// addPendingLocked records usage in memory against the quota day of the current snapshot.
+// The caller holds m.mu.
+func (m *Manager) addPendingLocked(quotaCost int, operationType string) {
+	if operationType == "" {
+		operationType = "other"
+	}
+	if m.synced != nil {
+		m.pendingDate = m.synced.Date
+	}
+	usage, ok := m.pending[operationType]
+	if !ok {
+		usage = &pendingUsage{}
+		m.pending[operationType] = usage
+	}
+	usage.cost += quotaCost
+	usage.calls++
+	m.pendingCost += quotaCost
+	m.pendingOps++
+}
+
+// fits reports whether requiredQuota more usage stays within the threshold for priority
+func (m *Manager) fits(info *model.QuotaInfo, priority Priority, requiredQuota int) bool {
 	// Check against threshold
-	thresholdQuota := (m.dailyLimit * m.thresholdPercent) / 100
+	thresholdQuota := m.threshold(priority)
 
 	if info.QuotaUsed >= thresholdQuota {
 		log.Printf("[Quota] Threshold reached: %d/%d (%.1f%%)", info.QuotaUsed, m.dailyLimit,
 			float64(info.QuotaUsed)/float64(m.dailyLimit)*100)
-		return false, info, nil
+		return false
 	}
 
 	// Check if we have enough for this operation
 	if info.QuotaUsed+requiredQuota > thresholdQuota {
 		log.Printf("[Quota] Not enough quota for operation: need %d, have %d remaining (threshold %d)",
 			requiredQuota, info.QuotaRemaining, thresholdQuota)
-		return false, info, nil
+		return false
 	}
 
-	return true, info, nil
+	return true
 }
 
-// RecordQuotaUsage records API quota usage
+// RecordQuotaUsage records API quota usage. Usage covered by a reservation in ctx is taken
+// off the reservation's hold once recorded.
 func (m *Manager) RecordQuotaUsage(ctx context.Context, quotaCost int, operationType string) error {
+	if operationType == "" {
+		operationType = "other"
+	}
+	defer drawReserved(ctx, quotaCost, operationType)
+
+	m.mu.Lock()
+	if m.syncInterval > 0 {
+		// Bring the snapshot up to date first, so usage pending from a day that has
+		// ended is flushed and this usage is attributed to the current day
+		if _, err := m.usageLocked(ctx); err != nil {
+			log.Printf("[Quota] Warning: failed to sync quota usage: %v", err)
+		}
+		m.addPendingLocked(quotaCost, operationType)
+		m.mu.Unlock()
+		return nil
+	}
+	m.mu.Unlock()
+
 	if err := m.repo.IncrementQuota(ctx, quotaCost, operationType); err != nil {
 		return fmt.Errorf("failed to record quota usage: %w", err)
 	}
@@ -78,12 +390,12 @@ func (m *Manager) RecordQuotaUsage(ctx context.Context, quotaCost int, operation
 
 // GetQuotaInfo returns current quota information
 func (m *Manager) GetQuotaInfo(ctx context.Context) (*model.QuotaInfo, error) {
-	return m.repo.GetTodaysQuota(ctx)
+	return m.usage(ctx)
 }
 
 // GetQuotaUsagePercentage returns the percentage of daily quota used
 func (m *Manager) GetQuotaUsagePercentage(ctx context.Context) (float64, error) {
-	info, err := m.repo.GetTodaysQuota(ctx)
+	info, err := m.usage(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -91,25 +403,25 @@ func (m *Manager) GetQuotaUsagePercentage(ctx context.Context) (float64, error)
 	return float64(info.QuotaUsed) / float64(m.dailyLimit) * 100, nil
 }
 
-// IsQuotaExhausted checks if quota threshold has been reached
+// IsQuotaExhausted checks if the background quota threshold has been reached
 func (m *Manager) IsQuotaExhausted(ctx context.Context) (bool, error) {
-	info, err := m.repo.GetTodaysQuota(ctx)
+	info, err := m.usage(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	thresholdQuota := (m.dailyLimit * m.thresholdPercent) / 100
+	thresholdQuota := m.threshold(PriorityBackground)
 	return info.QuotaUsed >= thresholdQuota, nil
 }
 
-// GetRemainingQuota returns how much quota is remaining before threshold
+// GetRemainingQuota returns how much quota is remaining before the background threshold
 func (m *Manager) GetRemainingQuota(ctx context.Context) (int, error) {
-	info, err := m.repo.GetTodaysQuota(ctx)
+	info, err := m.usage(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	thresholdQuota := (m.dailyLimit * m.thresholdPercent) / 100
+	thresholdQuota := m.threshold(PriorityBackground)
 	remaining := thresholdQuota - info.QuotaUsed
 
 	if remaining < 0 {
@@ -0,0 +1,314 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+)
+
+// mockQuotaRepo reports a fixed usage for today
+type mockQuotaRepo struct {
+	repository.QuotaRepository
+	used int
+}
+
+func (m *mockQuotaRepo) GetTodaysQuota(ctx context.Context) (*model.QuotaInfo, error) {
+	return &model.QuotaInfo{QuotaUsed: m.used, QuotaLimit: 10000, QuotaRemaining: 10000 - m.used}, nil
+}
+
+func TestManager_InteractiveReserve(t *testing.T) {
+	tests := []struct {
+		name            string
+		used            int
+		wantBackground  bool
+		wantInteractive bool
+	}{
+		{name: "below background threshold", used: 7000, wantBackground: true, wantInteractive: true},
+		{name: "at background threshold", used: 8000, wantBackground: false, wantInteractive: true},
+		{name: "inside reserve", used: 8500, wantBackground: false, wantInteractive: true},
+		{name: "at full threshold", used: 9000, wantBackground: false, wantInteractive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(&mockQuotaRepo{used: tt.used}, 10000, 90)
+			m.SetInteractiveReserve(10)
+
+			// Enrichment checks quota as background work
+			available, _, err := m.CheckQuotaAvailable(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if available != tt.wantBackground {
+				t.Errorf("expected background available=%v, got %v", tt.wantBackground, available)
+			}
+
+			// The channel resolver checks quota as interactive work
+			available, _, err = m.CheckQuotaAvailableFor(context.Background(), PriorityInteractive, 1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if available != tt.wantInteractive {
+				t.Errorf("expected interactive available=%v, got %v", tt.wantInteractive, available)
+			}
+		})
+	}
+}
+
+func TestManager_NoReserveSharesThreshold(t *testing.T) {
+	m := NewManager(&mockQuotaRepo{used: 8500}, 10000, 90)
+
+	available, _, err := m.CheckQuotaAvailable(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !available {
+		t.Error("expected background work to use the full threshold when no reserve is configured")
+	}
+}
+
+// countingQuotaRepo keeps usage in memory and counts the queries made against it.
+// When day is set, usage is kept per quota day and the day ends at resetsAt.
+type countingQuotaRepo struct {
+	repository.QuotaRepository
+	mu       sync.Mutex
+	used     int
+	byType   map[string]int
+	reads    int
+	day      time.Time
+	resetsAt time.Time
+	byDay    map[time.Time]int
+}
+
+func (r *countingQuotaRepo) GetTodaysQuota(ctx context.Context) (*model.QuotaInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	used := r.used
+	if !r.day.IsZero() {
+		used = r.byDay[r.day]
+	}
+	return &model.QuotaInfo{QuotaUsed: used, QuotaLimit: 1000, QuotaRemaining: 1000 - used, Date: r.day, ResetsAt: r.resetsAt}, nil
+}
+
+func (r *countingQuotaRepo) IncrementQuotaForDate(ctx context.Context, date time.Time, quotaCost, calls int, operationType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byDay[date] += quotaCost
+	r.byType[operationType] += quotaCost
+	return nil
+}
+
+func (r *countingQuotaRepo) IncrementQuota(ctx context.Context, quotaCost int, operationType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.used += quotaCost
+	r.byType[operationType] += quotaCost
+	return nil
+}
+
+func TestManager_SyncInterval_ConcurrentReservations(t *testing.T) {
+	repo := &countingQuotaRepo{used: 900, byType: make(map[string]int)}
+	m := NewManager(repo, 1000, 100)
+	m.SetSyncInterval(time.Hour)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 250; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, ok, err := m.Reserve(context.Background(), PriorityBackground, 1, "videos_list")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if ok {
+				if err := m.RecordQuotaUsage(ctx, 1, "videos_list"); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 100 {
+		t.Errorf("expected 100 reservations to fit under the threshold, got %d", reserved)
+	}
+	if repo.reads != 1 || repo.used != 900 {
+		t.Errorf("expected one read and no writes before sync, got %d reads and usage %d", repo.reads, repo.used)
+	}
+
+	info, err := m.GetQuotaInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.QuotaUsed != 1000 || info.OperationsCount != 100 {
+		t.Errorf("expected cached usage 1000 over 100 operations, got %d over %d", info.QuotaUsed, info.OperationsCount)
+	}
+
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.used != 1000 || repo.byType["videos_list"] != 100 {
+		t.Errorf("expected sync to persist 100 videos_list units, got usage %d (%v)", repo.used, repo.byType)
+	}
+	info, err = m.GetQuotaInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.QuotaUsed != 1000 {
+		t.Errorf("expected usage 1000 after sync, got %d", info.QuotaUsed)
+	}
+}
+
+func TestManager_SyncInterval_Reconciles(t *testing.T) {
+	repo := &countingQuotaRepo{byType: make(map[string]int)}
+	m := NewManager(repo, 1000, 100)
+	m.SetSyncInterval(time.Minute)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	if err := m.RecordQuotaUsage(context.Background(), 5, "channels_list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := m.CheckQuotaAvailable(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.reads != 1 || repo.used != 0 {
+		t.Fatalf("expected one read and usage kept in memory, got %d reads and usage %d", repo.reads, repo.used)
+	}
+
+	// Within the interval, usage stays in memory
+	if err := m.RecordQuotaUsage(context.Background(), 3, "channels_list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if _, _, err := m.CheckQuotaAvailable(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.reads != 1 || repo.used != 0 {
+		t.Errorf("expected no sync within the interval, got %d reads and usage %d", repo.reads, repo.used)
+	}
+
+	// Another process records usage; the next sync after the interval picks it up
+	repo.used += 50
+	now = now.Add(time.Minute)
+	info, err := m.GetQuotaInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.used != 58 || info.QuotaUsed != 58 {
+		t.Errorf("expected usage 58 after the interval, got %d in the repository and %d cached", repo.used, info.QuotaUsed)
+	}
+
+	// A new day syncs even within the interval, so the daily reset is seen
+	repo.used = 0
+	now = time.Date(2025, 1, 2, 0, 0, 1, 0, time.UTC)
+	m.synced.ResetsAt = time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	info, err = m.GetQuotaInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.QuotaUsed != 0 {
+		t.Errorf("expected usage to reset on a new day, got %d", info.QuotaUsed)
+	}
+}
+
+func TestManager_SyncInterval_FlushesPendingUsageIntoItsDay(t *testing.T) {
+	// The database runs in a timezone ahead of the process: its day ends at 22:00 UTC
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := &countingQuotaRepo{
+		byType:   make(map[string]int),
+		byDay:    make(map[time.Time]int),
+		day:      day1,
+		resetsAt: time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC),
+	}
+	m := NewManager(repo, 1000, 100)
+	m.SetSyncInterval(time.Hour)
+
+	now := time.Date(2025, 1, 1, 21, 50, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	if err := m.RecordQuotaUsage(context.Background(), 7, "videos_list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The database day rolls over within the sync interval, on the same process day
+	now = time.Date(2025, 1, 1, 22, 5, 0, 0, time.UTC)
+	repo.day = day2
+	repo.resetsAt = time.Date(2025, 1, 2, 22, 0, 0, 0, time.UTC)
+
+	if err := m.RecordQuotaUsage(context.Background(), 3, "videos_list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.byDay[day1] != 7 {
+		t.Errorf("expected usage from before the rollover to be flushed into its day, got %v", repo.byDay)
+	}
+
+	info, err := m.GetQuotaInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.QuotaUsed != 3 {
+		t.Errorf("expected only usage after the rollover to count against the new day, got %d", info.QuotaUsed)
+	}
+
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.byDay[day1] != 7 || repo.byDay[day2] != 3 {
+		t.Errorf("expected 7 units on the first day and 3 on the second, got %v", repo.byDay)
+	}
+}
+
+func TestManager_ReserveHoldsUntilRecordedOrReleased(t *testing.T) {
+	repo := &countingQuotaRepo{byType: make(map[string]int)}
+	m := NewManager(repo, 1000, 100)
+
+	ctx, ok, err := m.ReserveUsage(context.Background(), PriorityBackground, Usage{"videos_list": 1, "captions_list": 900})
+	if err != nil || !ok {
+		t.Fatalf("expected the reservation to succeed, got %v, %v", ok, err)
+	}
+	if repo.used != 0 {
+		t.Fatalf("expected a reservation to record nothing, got usage %d", repo.used)
+	}
+
+	// Held units count against further reservations
+	if _, ok, err := m.Reserve(context.Background(), PriorityBackground, 100, "search_list"); err != nil || ok {
+		t.Errorf("expected the held units to refuse the reservation, got %v, %v", ok, err)
+	}
+
+	// Recorded calls are counted once, and usage beyond the reservation is recorded too
+	if err := m.RecordQuotaUsage(ctx, 1, "videos_list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.RecordQuotaUsage(ctx, 50, "captions_list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.RecordQuotaUsage(ctx, 1, "channels_list"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.used != 52 {
+		t.Errorf("expected recorded usage 52, got %d", repo.used)
+	}
+	if _, ok, err := m.Reserve(context.Background(), PriorityBackground, 100, "search_list"); err != nil || ok {
+		t.Errorf("expected the unused held units to still refuse the reservation, got %v, %v", ok, err)
+	}
+
+	// Releasing frees the units the calls did not use
+	Release(ctx)
+	Release(ctx)
+	if _, ok, err := m.Reserve(context.Background(), PriorityBackground, 948, "search_list"); err != nil || !ok {
+		t.Errorf("expected the released units to fit, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+)
+
+// DefaultSponsorBackfillBatchSize is how many undetected videos are enqueued per backfill run
+const DefaultSponsorBackfillBatchSize = 20
+
+// SponsorDetectionEnqueuer enqueues sponsor detection tasks
+type SponsorDetectionEnqueuer interface {
+	EnqueueSponsorDetection(ctx context.Context, videoID, title, description, detectionJobID string, priority int) error
+}
+
+// SponsorDetectionBackfiller enqueues sponsor detection for enriched videos that never had a
+// detection job, e.g. videos enriched before sponsor detection was enabled.
+type SponsorDetectionBackfiller struct {
+	sponsorRepo repository.SponsorDetectionRepository
+	enqueuer    SponsorDetectionEnqueuer
+	llmModel    string
+	batchSize   int
+}
+
+// NewSponsorDetectionBackfiller creates a backfiller. A batchSize of zero uses the default.
+func NewSponsorDetectionBackfiller(
+	sponsorRepo repository.SponsorDetectionRepository,
+	enqueuer SponsorDetectionEnqueuer,
+	llmModel string,
+	batchSize int,
+) *SponsorDetectionBackfiller {
+	if batchSize <= 0 {
+		batchSize = DefaultSponsorBackfillBatchSize
+	}
+
+	return &SponsorDetectionBackfiller{
+		sponsorRepo: sponsorRepo,
+		enqueuer:    enqueuer,
+		llmModel:    llmModel,
+		batchSize:   batchSize,
+	}
+}
+
+// BackfillBatch creates a pending detection job for one batch of undetected videos and
+// enqueues each, returning how many were enqueued. A failing video is logged and skipped.
+func (b *SponsorDetectionBackfiller) BackfillBatch(ctx context.Context) (int, error) {
+	videos, err := b.sponsorRepo.GetVideosWithoutDetection(ctx, b.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("get videos without detection: %w", err)
+	}
+
+	enqueued := 0
+	for _, video := range videos {
+		job := &models.SponsorDetectionJob{
+			VideoID:  video.VideoID,
+			LLMModel: b.llmModel,
+			Status:   "pending",
+		}
+
+		// The job is created first so the video is not selected again by the next run
+		if err := b.sponsorRepo.CreateDetectionJob(ctx, job); err != nil {
+			log.Printf("[SponsorBackfill] Failed to create detection job for video %s: %v", video.VideoID, err)
+			continue
+		}
+
+		if err := b.enqueuer.EnqueueSponsorDetection(ctx, video.VideoID, video.Title, video.Description, job.ID.String(), 0); err != nil {
+			log.Printf("[SponsorBackfill] Failed to enqueue detection for video %s: %v", video.VideoID, err)
+			errMsg := err.Error()
+			if err := b.sponsorRepo.UpdateDetectionJobStatus(ctx, job.ID, "failed", &errMsg); err != nil {
+				log.Printf("[SponsorBackfill] Failed to mark detection job %s failed: %v", job.ID, err)
+			}
+			continue
+		}
+		enqueued++
+	}
+
+	return enqueued, nil
+}
+
+// Run calls BackfillBatch every interval until ctx is cancelled
+func (b *SponsorDetectionBackfiller) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, func(ctx context.Context) {
+		enqueued, err := b.BackfillBatch(ctx)
+		switch {
+		case err != nil:
+			log.Printf("[SponsorBackfill] Backfill failed: %v", err)
+		case enqueued > 0:
+			log.Printf("[SponsorBackfill] Enqueued sponsor detection for %d videos", enqueued)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackfillSponsorRepo serves undetected videos and records created jobs
+type fakeBackfillSponsorRepo struct {
+	repository.SponsorDetectionRepository
+	undetected []*models.UndetectedVideo
+	jobs       map[uuid.UUID]*models.SponsorDetectionJob
+}
+
+func (f *fakeBackfillSponsorRepo) GetVideosWithoutDetection(ctx context.Context, limit int) ([]*models.UndetectedVideo, error) {
+	return f.undetected, nil
+}
+
+func (f *fakeBackfillSponsorRepo) CreateDetectionJob(ctx context.Context, job *models.SponsorDetectionJob) error {
+	job.ID = uuid.New()
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeBackfillSponsorRepo) UpdateDetectionJobStatus(ctx context.Context, jobID uuid.UUID, status string, errorMsg *string) error {
+	f.jobs[jobID].Status = status
+	return nil
+}
+
+// fakeSponsorEnqueuer records enqueued videos and fails for the configured ones
+type fakeSponsorEnqueuer struct {
+	enqueued []string
+	failFor  map[string]bool
+}
+
+func (f *fakeSponsorEnqueuer) EnqueueSponsorDetection(ctx context.Context, videoID, title, description, detectionJobID string, priority int) error {
+	if f.failFor[videoID] {
+		return errors.New("redis unavailable")
+	}
+	f.enqueued = append(f.enqueued, videoID)
+	return nil
+}
+
+func TestSponsorDetectionBackfiller_BackfillBatch(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeBackfillSponsorRepo{
+		undetected: []*models.UndetectedVideo{
+			{VideoID: "video1", Title: "One", Description: "sponsored by Acme"},
+			{VideoID: "video2", Title: "Two", Description: "sponsored by Globex"},
+		},
+		jobs: make(map[uuid.UUID]*models.SponsorDetectionJob),
+	}
+	enqueuer := &fakeSponsorEnqueuer{failFor: map[string]bool{"video2": true}}
+
+	backfiller := NewSponsorDetectionBackfiller(repo, enqueuer, "llama3.2", 0)
+
+	enqueued, err := backfiller.BackfillBatch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, enqueued)
+	assert.Equal(t, []string{"video1"}, enqueuer.enqueued)
+
+	require.Len(t, repo.jobs, 2)
+	statuses := make(map[string]string)
+	for _, job := range repo.jobs {
+		assert.Equal(t, "llama3.2", job.LLMModel)
+		statuses[job.VideoID] = job.Status
+	}
+	assert.Equal(t, map[string]string{"video1": "pending", "video2": "failed"}, statuses)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+)
+
+// trendingChartSize is how many chart positions are fetched per region; 200 is YouTube's maximum
+const trendingChartSize = 200
+
+// trendingChartPages is how many videos.list calls one region's chart takes, at 50 per page
+const trendingChartPages = trendingChartSize / 50
+
+// ChartFetcher fetches the video IDs on a region's mostPopular chart, in chart order
+type ChartFetcher interface {
+	FetchMostPopular(ctx context.Context, regionCode string, maxResults int) ([]string, error)
+	QuotaCost(operationType string) int
+}
+
+// TrendingSnapshotResult summarizes one trending snapshot run
+type TrendingSnapshotResult struct {
+	Regions        int // regions whose chart was fetched
+	Snapshots      int // tracked videos newly recorded as trending
+	QuotaExhausted bool
+}
+
+// TrendingSnapshotter records which tracked videos appear on the mostPopular chart per region and day
+type TrendingSnapshotter struct {
+	trendingRepo  repository.TrendingRepository
+	fetcher       ChartFetcher
+	quotaReserver QuotaReserver
+	regions       []string
+	now           func() time.Time
+}
+
+// NewTrendingSnapshotter creates a snapshotter for the given region codes (ISO 3166-1 alpha-2)
+func NewTrendingSnapshotter(
+	trendingRepo repository.TrendingRepository,
+	fetcher ChartFetcher,
+	quotaReserver QuotaReserver,
+	regions []string,
+) *TrendingSnapshotter {
+	return &TrendingSnapshotter{
+		trendingRepo:  trendingRepo,
+		fetcher:       fetcher,
+		quotaReserver: quotaReserver,
+		regions:       regions,
+		now:           time.Now,
+	}
+}
+
+// SnapshotAll fetches each region's chart and records the tracked videos on it.
+// It stops early once the quota threshold is reached; a failing region is logged and skipped.
+func (s *TrendingSnapshotter) SnapshotAll(ctx context.Context) (*TrendingSnapshotResult, error) {
+	result := &TrendingSnapshotResult{}
+	snapshotDate := s.now().UTC().Truncate(24 * time.Hour)
+
+	for _, region := range s.regions {
+		usage := quota.Usage{quota.OpVideosList: trendingChartPages * s.fetcher.QuotaCost(quota.OpVideosList)}
+		regionCtx, available, err := s.quotaReserver.ReserveUsage(ctx, quota.PriorityBackground, usage)
+		if err != nil {
+			return result, fmt.Errorf("check quota: %w", err)
+		}
+		if !available {
+			result.QuotaExhausted = true
+			return result, nil
+		}
+
+		videoIDs, err := s.fetcher.FetchMostPopular(regionCtx, region, trendingChartSize)
+		quota.Release(regionCtx)
+		if err != nil {
+			log.Printf("[Trending] Failed to fetch chart for %s: %v", region, err)
+			continue
+		}
+		result.Regions++
+
+		created, err := s.trendingRepo.SaveChartSnapshot(ctx, region, snapshotDate, videoIDs)
+		if err != nil {
+			log.Printf("[Trending] Failed to save chart snapshot for %s: %v", region, err)
+			continue
+		}
+		result.Snapshots += created
+	}
+
+	return result, nil
+}
+
+// Run calls SnapshotAll every interval until ctx is cancelled
+func (s *TrendingSnapshotter) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, func(ctx context.Context) {
+		result, err := s.SnapshotAll(ctx)
+		switch {
+		case err != nil:
+			log.Printf("[Trending] Snapshot failed: %v", err)
+		case result.QuotaExhausted:
+			log.Printf("[Trending] Stopped after %d regions, quota threshold reached", result.Regions)
+		default:
+			log.Printf("[Trending] Snapshotted %d regions: %d tracked videos newly trending", result.Regions, result.Snapshots)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChartFetcher serves a fixed chart per region
+type fakeChartFetcher struct {
+	charts map[string][]string
+	costs  quota.CostTable
+}
+
+func (f *fakeChartFetcher) QuotaCost(operationType string) int {
+	return f.costs.Cost(operationType)
+}
+
+func (f *fakeChartFetcher) FetchMostPopular(ctx context.Context, regionCode string, maxResults int) ([]string, error) {
+	return f.charts[regionCode], nil
+}
+
+// fakeTrendingRepo records snapshots for tracked videos only, like the real repository
+type fakeTrendingRepo struct {
+	tracked   map[string]bool
+	snapshots []*model.TrendingSnapshot
+}
+
+func (f *fakeTrendingRepo) SaveChartSnapshot(ctx context.Context, regionCode string, snapshotDate time.Time, chartVideoIDs []string) (int, error) {
+	created := 0
+	for i, videoID := range chartVideoIDs {
+		if !f.tracked[videoID] {
+			continue
+		}
+		f.snapshots = append(f.snapshots, &model.TrendingSnapshot{
+			VideoID:      videoID,
+			RegionCode:   regionCode,
+			SnapshotDate: snapshotDate,
+			ChartRank:    i + 1,
+		})
+		created++
+	}
+	return created, nil
+}
+
+func (f *fakeTrendingRepo) GetSnapshotsByVideoID(ctx context.Context, videoID string) ([]*model.TrendingSnapshot, error) {
+	var result []*model.TrendingSnapshot
+	for _, s := range f.snapshots {
+		if s.VideoID == videoID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func TestTrendingSnapshotter_RecordsTrackedVideo(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeTrendingRepo{tracked: map[string]bool{"tracked1": true}}
+	fetcher := &fakeChartFetcher{charts: map[string][]string{
+		"US": {"popular1", "tracked1", "popular2"},
+		"GB": {"popular3"},
+	}}
+
+	snapshotter := NewTrendingSnapshotter(repo, fetcher, &fakeQuotaReserver{available: true}, []string{"US", "GB"})
+	snapshotter.now = func() time.Time { return time.Date(2025, 6, 1, 15, 30, 0, 0, time.UTC) }
+
+	result, err := snapshotter.SnapshotAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Regions)
+	assert.Equal(t, 1, result.Snapshots)
+
+	snapshots, err := repo.GetSnapshotsByVideoID(context.Background(), "tracked1")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "US", snapshots[0].RegionCode)
+	assert.Equal(t, 2, snapshots[0].ChartRank)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), snapshots[0].SnapshotDate)
+}
+
+func TestTrendingSnapshotter_ReservesConfiguredCost(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeTrendingRepo{tracked: map[string]bool{}}
+	fetcher := &fakeChartFetcher{
+		charts: map[string][]string{"US": {"popular1"}},
+		costs:  quota.CostTable{quota.OpVideosList: 3},
+	}
+	reserver := &fakeQuotaReserver{available: true}
+
+	snapshotter := NewTrendingSnapshotter(repo, fetcher, reserver, []string{"US"})
+	_, err := snapshotter.SnapshotAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, quota.Usage{quota.OpVideosList: 4 * 3}, reserver.reserved, "one videos.list call per page of 50 at the configured cost")
+}
+
+func TestTrendingSnapshotter_RespectsQuota(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeTrendingRepo{tracked: map[string]bool{"tracked1": true}}
+	fetcher := &fakeChartFetcher{charts: map[string][]string{"US": {"tracked1"}}}
+
+	snapshotter := NewTrendingSnapshotter(repo, fetcher, &fakeQuotaReserver{available: false}, []string{"US"})
+
+	result, err := snapshotter.SnapshotAll(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.QuotaExhausted)
+	assert.Zero(t, result.Regions)
+	assert.Empty(t, repo.snapshots)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/youtube"
+)
+
+// Defaults for the video availability re-check
+const (
+	DefaultAvailabilityBatchSize    = 50 // videos.list accepts at most 50 IDs
+	DefaultAvailabilityMinAge       = 7 * 24 * time.Hour
+	DefaultAvailabilityRecheckAfter = 30 * 24 * time.Hour
+)
+
+// ReasonNotReturned is recorded when videos.list returns no item for a known video
+const ReasonNotReturned = youtube.ReasonNotReturned
+
+// VideoFetcher fetches video details from the YouTube API
+type VideoFetcher interface {
+	FetchVideos(ctx context.Context, videoIDs []string) ([]*model.VideoEnrichment, []string, int, error)
+	FetchVideosQuotaUsage(videoCount int) quota.Usage
+}
+
+// QuotaReserver reserves quota before background work spends it. The returned context
+// carries the reservation to the API calls it covers; it is released with quota.Release.
+type QuotaReserver interface {
+	ReserveUsage(ctx context.Context, priority quota.Priority, usage quota.Usage) (context.Context, bool, error)
+}
+
+// VideoAvailabilityConfig configures which videos are re-checked and how many per run
+type VideoAvailabilityConfig struct {
+	BatchSize    int           // videos checked per run, at most 50
+	MinAge       time.Duration // only videos published at least this long ago
+	RecheckAfter time.Duration // skip videos confirmed available more recently than this
+}
+
+// VideoAvailabilityResult summarizes one availability re-check run
+type VideoAvailabilityResult struct {
+	Checked        int  // videos sent to videos.list
+	Available      int  // videos still returned by the API
+	Unavailable    int  // videos newly marked unavailable
+	QuotaExhausted bool // the run was skipped because the quota threshold was reached
+}
+
+// VideoAvailabilityChecker detects videos removed from YouTube without a deleted-entry
+// notification by periodically re-fetching a sample of older videos.
+type VideoAvailabilityChecker struct {
+	videoRepo     repository.VideoRepository
+	fetcher       VideoFetcher
+	quotaReserver QuotaReserver
+	config        VideoAvailabilityConfig
+	now           func() time.Time
+}
+
+// NewVideoAvailabilityChecker creates a new availability checker. Zero config values use the defaults.
+func NewVideoAvailabilityChecker(
+	videoRepo repository.VideoRepository,
+	fetcher VideoFetcher,
+	quotaReserver QuotaReserver,
+	config VideoAvailabilityConfig,
+) *VideoAvailabilityChecker {
+	if config.BatchSize <= 0 || config.BatchSize > DefaultAvailabilityBatchSize {
+		config.BatchSize = DefaultAvailabilityBatchSize
+	}
+	if config.MinAge <= 0 {
+		config.MinAge = DefaultAvailabilityMinAge
+	}
+	if config.RecheckAfter <= 0 {
+		config.RecheckAfter = DefaultAvailabilityRecheckAfter
+	}
+
+	return &VideoAvailabilityChecker{
+		videoRepo:     videoRepo,
+		fetcher:       fetcher,
+		quotaReserver: quotaReserver,
+		config:        config,
+		now:           time.Now,
+	}
+}
+
+// CheckBatch re-fetches one batch of older videos and marks those the API no longer returns as unavailable
+func (c *VideoAvailabilityChecker) CheckBatch(ctx context.Context) (*VideoAvailabilityResult, error) {
+	result := &VideoAvailabilityResult{}
+	now := c.now()
+
+	videos, err := c.videoRepo.GetVideosForAvailabilityCheck(ctx, now.Add(-c.config.MinAge), now.Add(-c.config.RecheckAfter), c.config.BatchSize)
+	if err != nil {
+		return result, fmt.Errorf("get videos for availability check: %w", err)
+	}
+	if len(videos) == 0 {
+		return result, nil
+	}
+
+	// One videos.list call, plus the captions.list calls of caption language inference
+	ctx, available, err := c.quotaReserver.ReserveUsage(ctx, quota.PriorityBackground, c.fetcher.FetchVideosQuotaUsage(len(videos)))
+	if err != nil {
+		return result, fmt.Errorf("check quota: %w", err)
+	}
+	if !available {
+		result.QuotaExhausted = true
+		return result, nil
+	}
+	defer quota.Release(ctx)
+
+	videoIDs := make([]string, 0, len(videos))
+	for _, video := range videos {
+		videoIDs = append(videoIDs, video.VideoID)
+	}
+
+	_, missingIDs, _, err := c.fetcher.FetchVideos(ctx, videoIDs)
+	if err != nil {
+		// Nothing is marked when the API call itself fails
+		return result, fmt.Errorf("fetch videos: %w", err)
+	}
+	result.Checked = len(videoIDs)
+
+	missing := make(map[string]bool, len(missingIDs))
+	for _, videoID := range missingIDs {
+		missing[videoID] = true
+	}
+
+	var stillAvailable []string
+	for _, videoID := range videoIDs {
+		if !missing[videoID] {
+			stillAvailable = append(stillAvailable, videoID)
+			continue
+		}
+
+		if err := c.videoRepo.MarkUnavailable(ctx, videoID, ReasonNotReturned, now); err != nil {
+			log.Printf("[Availability] Failed to mark video %s unavailable: %v", videoID, err)
+			continue
+		}
+		log.Printf("[Availability] Video %s is no longer available: %s", videoID, ReasonNotReturned)
+		result.Unavailable++
+	}
+
+	if err := c.videoRepo.MarkAvailabilityChecked(ctx, stillAvailable, now); err != nil {
+		return result, fmt.Errorf("mark availability checked: %w", err)
+	}
+	result.Available = len(stillAvailable)
+
+	return result, nil
+}
+
+// Run calls CheckBatch every interval until ctx is cancelled
+func (c *VideoAvailabilityChecker) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, func(ctx context.Context) {
+		result, err := c.CheckBatch(ctx)
+		switch {
+		case err != nil:
+			log.Printf("[Availability] Re-check failed: %v", err)
+		case result.QuotaExhausted:
+			log.Printf("[Availability] Skipping re-check, quota threshold reached")
+		case result.Checked > 0:
+			log.Printf("[Availability] Re-checked %d videos: %d available, %d unavailable",
+				result.Checked, result.Available, result.Unavailable)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVideoFetcher returns enrichments only for the video IDs it knows about
+type fakeVideoFetcher struct {
+	existing      map[string]bool
+	requested     []string
+	inferCaptions bool
+}
+
+func (f *fakeVideoFetcher) FetchVideosQuotaUsage(videoCount int) quota.Usage {
+	usage := quota.Usage{quota.OpVideosList: quota.DefaultCosts.Cost(quota.OpVideosList)}
+	if f.inferCaptions {
+		usage[quota.OpCaptionsList] = videoCount * quota.DefaultCosts.Cost(quota.OpCaptionsList)
+	}
+	return usage
+}
+
+func (f *fakeVideoFetcher) FetchVideos(ctx context.Context, videoIDs []string) ([]*model.VideoEnrichment, []string, int, error) {
+	f.requested = videoIDs
+	var enrichments []*model.VideoEnrichment
+	var missing []string
+	for _, id := range videoIDs {
+		if f.existing[id] {
+			enrichments = append(enrichments, &model.VideoEnrichment{VideoID: id})
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return enrichments, missing, 1, nil
+}
+
+type fakeQuotaReserver struct {
+	available bool
+	reserved  quota.Usage
+}
+
+func (f *fakeQuotaReserver) ReserveUsage(ctx context.Context, priority quota.Priority, usage quota.Usage) (context.Context, bool, error) {
+	if f.available {
+		if f.reserved == nil {
+			f.reserved = quota.Usage{}
+		}
+		for operationType, quotaCost := range usage {
+			f.reserved[operationType] += quotaCost
+		}
+	}
+	return ctx, f.available, nil
+}
+
+func TestVideoAvailabilityChecker_MarksMissingVideoUnavailable(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	videoRepo := new(mockVideoRepo)
+	videoRepo.On("GetVideosForAvailabilityCheck", mock.Anything,
+		now.Add(-DefaultAvailabilityMinAge), now.Add(-DefaultAvailabilityRecheckAfter), DefaultAvailabilityBatchSize,
+	).Return([]*models.Video{
+		{VideoID: "still-here"},
+		{VideoID: "removed"},
+	}, nil)
+	videoRepo.On("MarkUnavailable", mock.Anything, "removed", ReasonNotReturned, now).Return(nil)
+	videoRepo.On("MarkAvailabilityChecked", mock.Anything, []string{"still-here"}, now).Return(nil)
+
+	fetcher := &fakeVideoFetcher{existing: map[string]bool{"still-here": true}}
+
+	checker := NewVideoAvailabilityChecker(videoRepo, fetcher, &fakeQuotaReserver{available: true}, VideoAvailabilityConfig{})
+	checker.now = func() time.Time { return now }
+
+	result, err := checker.CheckBatch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"still-here", "removed"}, fetcher.requested)
+	assert.Equal(t, 2, result.Checked)
+	assert.Equal(t, 1, result.Available)
+	assert.Equal(t, 1, result.Unavailable)
+	assert.False(t, result.QuotaExhausted)
+
+	videoRepo.AssertExpectations(t)
+}
+
+func TestVideoAvailabilityChecker_RespectsQuota(t *testing.T) {
+	t.Parallel()
+
+	videoRepo := new(mockVideoRepo)
+	videoRepo.On("GetVideosForAvailabilityCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]*models.Video{{VideoID: "removed"}}, nil)
+
+	fetcher := &fakeVideoFetcher{}
+
+	checker := NewVideoAvailabilityChecker(videoRepo, fetcher, &fakeQuotaReserver{available: false}, VideoAvailabilityConfig{})
+
+	result, err := checker.CheckBatch(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, result.QuotaExhausted)
+	assert.Nil(t, fetcher.requested, "expected no API call when quota is exhausted")
+	videoRepo.AssertNotCalled(t, "MarkUnavailable", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVideoAvailabilityChecker_ReservesCaptionInference(t *testing.T) {
+	t.Parallel()
+
+	videoRepo := new(mockVideoRepo)
+	videoRepo.On("GetVideosForAvailabilityCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]*models.Video{{VideoID: "a"}, {VideoID: "b"}, {VideoID: "c"}}, nil)
+	videoRepo.On("MarkAvailabilityChecked", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	fetcher := &fakeVideoFetcher{existing: map[string]bool{"a": true, "b": true, "c": true}, inferCaptions: true}
+	reserver := &fakeQuotaReserver{available: true}
+
+	checker := NewVideoAvailabilityChecker(videoRepo, fetcher, reserver, VideoAvailabilityConfig{})
+	_, err := checker.CheckBatch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, quota.Usage{quota.OpVideosList: 1, quota.OpCaptionsList: 3 * 50}, reserver.reserved,
+		"one videos.list call and a captions.list call per video")
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/repository"
+	"ad-tracker/youtube-webhook-ingestion/internal/parser"
+)
+
+// DefaultBackfillBatchSize is the number of webhook events read per batch when backfilling
+const DefaultBackfillBatchSize = 500
+
+// WebhookEventBackfillResult summarizes a webhook event linkage backfill run
+type WebhookEventBackfillResult struct {
+	Scanned int // events with a NULL video_id that were examined
+	Updated int // events whose video_id and channel_id were written back
+	Skipped int // deleted-video notifications without a video ID
+	Failed  int // events whose raw XML could not be parsed or written back
+}
+
+// BackfillWebhookEventLinkage re-parses the raw XML of webhook events stored without a
+// video_id and writes the extracted video and channel IDs back, so filtering events by
+// video_id also covers events ingested before those columns were populated.
+func BackfillWebhookEventLinkage(ctx context.Context, repo repository.WebhookEventRepository, batchSize int) (*WebhookEventBackfillResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBackfillBatchSize
+	}
+
+	result := &WebhookEventBackfillResult{}
+
+	// Page by ID so events that stay NULL (deleted or unparseable) are not read again
+	var afterID int64
+	for {
+		events, err := repo.GetEventsMissingVideoID(ctx, afterID, batchSize)
+		if err != nil {
+			return result, fmt.Errorf("get events missing video id: %w", err)
+		}
+
+		for _, event := range events {
+			afterID = event.ID
+			result.Scanned++
+
+			videoData, err := parser.ParseAtomFeed(event.RawXML)
+			if err != nil {
+				log.Printf("[Backfill] Failed to parse webhook event %d: %v", event.ID, err)
+				result.Failed++
+				continue
+			}
+
+			if videoData.VideoID == "" {
+				result.Skipped++
+				continue
+			}
+
+			if err := repo.SetEventLinkage(ctx, event.ID, videoData.VideoID, videoData.ChannelID); err != nil {
+				log.Printf("[Backfill] Failed to update webhook event %d: %v", event.ID, err)
+				result.Failed++
+				continue
+			}
+
+			result.Updated++
+		}
+
+		if len(events) < batchSize {
+			return result, nil
+		}
+	}
+}
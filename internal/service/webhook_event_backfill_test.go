@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/db/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillWebhookEventLinkage(t *testing.T) {
+	t.Parallel()
+
+	validXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <yt:videoId>test123</yt:videoId>
+    <yt:channelId>UCtest</yt:channelId>
+    <title>Test Video</title>
+    <published>2025-01-15T10:00:00+00:00</published>
+    <updated>2025-01-15T11:00:00+00:00</updated>
+  </entry>
+</feed>`
+
+	deletedXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <yt:deleted-entry ref="yt:video:deleted123" when="2025-01-15T12:00:00+00:00"/>
+</feed>`
+
+	// A deleted entry whose ref does not name a video carries nothing to link
+	unlinkableXML := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <yt:deleted-entry when="2025-01-15T12:00:00+00:00"/>
+</feed>`
+
+	webhookEventRepo := new(mockWebhookEventRepo)
+
+	// A full batch of two, then a short final batch
+	webhookEventRepo.On("GetEventsMissingVideoID", mock.Anything, int64(0), 2).Return([]*models.WebhookEvent{
+		{ID: 1, RawXML: validXML},
+		{ID: 2, RawXML: "invalid xml"},
+	}, nil)
+	webhookEventRepo.On("GetEventsMissingVideoID", mock.Anything, int64(2), 2).Return([]*models.WebhookEvent{
+		{ID: 5, RawXML: deletedXML},
+		{ID: 6, RawXML: unlinkableXML},
+	}, nil)
+	webhookEventRepo.On("GetEventsMissingVideoID", mock.Anything, int64(6), 2).Return([]*models.WebhookEvent{}, nil)
+
+	webhookEventRepo.On("SetEventLinkage", mock.Anything, int64(1), "test123", "UCtest").Return(nil)
+	webhookEventRepo.On("SetEventLinkage", mock.Anything, int64(5), "deleted123", "").Return(nil)
+
+	result, err := BackfillWebhookEventLinkage(context.Background(), webhookEventRepo, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, result.Scanned)
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Failed)
+
+	webhookEventRepo.AssertExpectations(t)
+	webhookEventRepo.AssertNumberOfCalls(t, "SetEventLinkage", 2)
+}
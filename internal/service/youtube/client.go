@@ -1,21 +1,35 @@
 package youtube
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
 	"google.golang.org/api/youtube/v3"
 
 	"ad-tracker/youtube-webhook-ingestion/internal/model"
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
 )
 
+// ErrChannelNotFound is returned when a channel lookup matches no channel
+var ErrChannelNotFound = errors.New("channel not found")
+
+// ErrInvalidChannelURL is returned when a URL is not a YouTube URL or has no recognizable channel identifier
+var ErrInvalidChannelURL = errors.New("invalid channel URL")
+
 // QuotaTracker is an interface for tracking quota usage
 type QuotaTracker interface {
 	RecordQuotaUsage(ctx context.Context, quotaCost int, operationType string) error
@@ -24,8 +38,11 @@ type QuotaTracker interface {
 // Client wraps the YouTube Data API v3 client
 type Client struct {
 	service      *youtube.Service
-	apiKey       string
 	quotaTracker QuotaTracker
+	quotaCosts   quota.CostTable // nil uses quota.DefaultCosts
+
+	// inferCaptionLanguage fetches caption tracks for videos without a reported language
+	inferCaptionLanguage bool
 }
 
 // NewClient creates a new YouTube API client
@@ -34,32 +51,122 @@ func NewClient(apiKey string) (*Client, error) {
 		return nil, fmt.Errorf("YouTube API key is required")
 	}
 
-	service, err := youtube.NewService(context.Background(), option.WithAPIKey(apiKey))
+	service, err := newService(context.Background(), apiKey, http.DefaultTransport)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create YouTube service: %w", err)
 	}
 
 	return &Client{
 		service:      service,
-		apiKey:       apiKey,
 		quotaTracker: nil, // Can be set later with SetQuotaTracker
 	}, nil
 }
 
+// newService creates a YouTube service that authenticates with apiKey and sends its calls
+// through base, keeping raw response bodies for the calls that ask for them
+func newService(ctx context.Context, apiKey string, base http.RoundTripper, opts ...option.ClientOption) (*youtube.Service, error) {
+	transport, err := htransport.NewTransport(ctx, base, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, option.WithHTTPClient(&http.Client{Transport: &rawResponseTransport{base: transport}}))
+	return youtube.NewService(ctx, opts...)
+}
+
+// rawResponseKey is the context key of the buffer a call's raw response body is copied to
+type rawResponseKey struct{}
+
+// withRawResponse returns a context whose service calls copy their response body to buf, for
+// fields the generated types cannot represent
+func withRawResponse(ctx context.Context, buf *bytes.Buffer) context.Context {
+	return context.WithValue(ctx, rawResponseKey{}, buf)
+}
+
+// rawResponseTransport copies response bodies to the buffer of requests made with withRawResponse
+type rawResponseTransport struct {
+	base http.RoundTripper
+}
+
+func (t *rawResponseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	buf, ok := req.Context().Value(rawResponseKey{}).(*bytes.Buffer)
+	if err != nil || !ok {
+		return res, err
+	}
+
+	// Only the last attempt's body is kept if the call is retried
+	buf.Reset()
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(res.Body, buf), res.Body}
+	return res, nil
+}
+
 // SetQuotaTracker sets the quota tracker for this client
 func (c *Client) SetQuotaTracker(tracker QuotaTracker) {
 	c.quotaTracker = tracker
 }
 
+// SetCaptionLanguageInference enables inferring a language from caption tracks for videos whose
+// snippet reports neither defaultLanguage nor defaultAudioLanguage, in the FetchVideosWithOptions
+// calls that ask for it. Each such video with captions costs an extra captions.list call (50
+// quota units).
+func (c *Client) SetCaptionLanguageInference(enabled bool) {
+	c.inferCaptionLanguage = enabled
+}
+
+// SetQuotaCosts sets the quota cost of each API operation, for when YouTube changes its pricing.
+// Operations the table does not list keep their default cost.
+func (c *Client) SetQuotaCosts(costs quota.CostTable) {
+	c.quotaCosts = costs
+}
+
+// QuotaCost returns the configured quota cost of an API operation
+func (c *Client) QuotaCost(operationType string) int {
+	return c.quotaCosts.Cost(operationType)
+}
+
+// recordQuotaUsage records one call of an API operation with the quota tracker, if any,
+// and returns its cost
+func (c *Client) recordQuotaUsage(ctx context.Context, operationType string) int {
+	cost := c.QuotaCost(operationType)
+	if c.quotaTracker != nil {
+		if err := c.quotaTracker.RecordQuotaUsage(ctx, cost, operationType); err != nil {
+			log.Printf("[YouTube Client] Warning: failed to record %s quota usage: %v", operationType, err)
+		}
+	}
+	return cost
+}
+
+// ReasonNotReturned is recorded as the unavailability reason of a requested video that
+// videos.list returned no item for
+const ReasonNotReturned = "not returned by videos.list"
+
+// FetchOptions selects optional lookups FetchVideosWithOptions makes after videos.list
+type FetchOptions struct {
+	// InferLanguage infers a language from caption tracks for videos without a reported one,
+	// if caption language inference is enabled on the client
+	InferLanguage bool
+}
+
 // FetchVideos retrieves comprehensive data for up to 50 videos in a single batch
-// Returns enrichment data and the quota cost of the operation
-func (c *Client) FetchVideos(ctx context.Context, videoIDs []string) ([]*model.VideoEnrichment, int, error) {
+// Returns enrichment data, the requested IDs that had no item in the response (private,
+// deleted or otherwise unavailable videos), and the quota cost of the operation
+func (c *Client) FetchVideos(ctx context.Context, videoIDs []string) ([]*model.VideoEnrichment, []string, int, error) {
+	return c.FetchVideosWithOptions(ctx, videoIDs, FetchOptions{})
+}
+
+// FetchVideosWithOptions is FetchVideos with optional lookups. Only callers that store the
+// enrichments should ask for them, since they spend extra quota.
+func (c *Client) FetchVideosWithOptions(ctx context.Context, videoIDs []string, opts FetchOptions) ([]*model.VideoEnrichment, []string, int, error) {
 	if len(videoIDs) == 0 {
-		return nil, 0, fmt.Errorf("no video IDs provided")
+		return nil, nil, 0, fmt.Errorf("no video IDs provided")
 	}
 
 	if len(videoIDs) > 50 {
-		return nil, 0, fmt.Errorf("too many video IDs (max 50, got %d)", len(videoIDs))
+		return nil, nil, 0, fmt.Errorf("too many video IDs (max 50, got %d)", len(videoIDs))
 	}
 
 	// Request all available parts for comprehensive data
@@ -74,43 +181,278 @@ func (c *Client) FetchVideos(ctx context.Context, videoIDs []string) ([]*model.V
 		"player",
 	}
 
-	call := c.service.Videos.List(parts).Id(videoIDs...).Context(ctx)
+	response, commentCountPresent, err := c.listVideos(ctx, parts, videoIDs)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to fetch videos from YouTube API: %w", err)
+	}
+
+	// Track quota usage for Videos.List API call; its cost does not depend on the parts requested
+	quotaCost := c.recordQuotaUsage(ctx, quota.OpVideosList)
 
-	response, err := call.Do()
+	enrichments := make([]*model.VideoEnrichment, 0, len(response.Items))
+	returned := make(map[string]bool, len(response.Items))
+
+	for _, item := range response.Items {
+		enrichment := c.mapVideoToEnrichment(item, parts, response.Etag, commentCountPresent[item.Id])
+		enrichments = append(enrichments, enrichment)
+		returned[item.Id] = true
+	}
+
+	if c.inferCaptionLanguage && opts.InferLanguage {
+		quotaCost += c.inferLanguages(ctx, enrichments)
+	}
+
+	return enrichments, missingVideoIDs(videoIDs, returned), quotaCost, nil
+}
+
+// FetchVideosQuotaUsage returns the quota FetchVideos spends on videoCount videos, by
+// operation type: one videos.list call.
+func (c *Client) FetchVideosQuotaUsage(videoCount int) quota.Usage {
+	return c.FetchVideosQuotaUsageWithOptions(videoCount, FetchOptions{})
+}
+
+// FetchVideosQuotaUsageWithOptions returns the most quota FetchVideosWithOptions spends on
+// videoCount videos, by operation type: one videos.list call, plus a captions.list call per
+// video when language inference is enabled and requested, as any of them may lack a
+// reported language.
+func (c *Client) FetchVideosQuotaUsageWithOptions(videoCount int, opts FetchOptions) quota.Usage {
+	usage := quota.Usage{quota.OpVideosList: c.QuotaCost(quota.OpVideosList)}
+	if c.inferCaptionLanguage && opts.InferLanguage {
+		usage[quota.OpCaptionsList] = videoCount * c.QuotaCost(quota.OpCaptionsList)
+	}
+	return usage
+}
+
+// inferLanguages sets InferredLanguage from caption tracks on enrichments that have captions but
+// no API-reported language, and returns the quota spent. A failed lookup only leaves the field unset.
+func (c *Client) inferLanguages(ctx context.Context, enrichments []*model.VideoEnrichment) int {
+	quotaCost := 0
+	for _, enrichment := range enrichments {
+		if enrichment.DefaultLanguage != nil || enrichment.DefaultAudioLanguage != nil {
+			continue
+		}
+		if enrichment.Caption == nil || *enrichment.Caption != "true" {
+			continue
+		}
+
+		response, err := c.service.Captions.List([]string{"snippet"}, enrichment.VideoID).
+			Fields("items(snippet(language,trackKind))").
+			Context(ctx).
+			Do()
+		if err != nil {
+			log.Printf("[YouTube Client] Warning: failed to list captions for video %s: %v", enrichment.VideoID, err)
+			continue
+		}
+
+		captionsCost := c.recordQuotaUsage(ctx, quota.OpCaptionsList)
+		quotaCost += captionsCost
+		enrichment.QuotaCost += captionsCost
+
+		enrichment.InferredLanguage = inferLanguageFromCaptions(response.Items)
+	}
+	return quotaCost
+}
+
+// inferLanguageFromCaptions picks the language a video is most likely spoken in from its caption
+// tracks. YouTube's automatic (ASR) track is transcribed from the audio, so its language wins;
+// otherwise the language of the standard tracks is used when they all agree. Several differing
+// standard tracks are usually translations, so no language is inferred from them.
+func inferLanguageFromCaptions(tracks []*youtube.Caption) *string {
+	var standard []string
+	for _, track := range tracks {
+		if track.Snippet == nil || track.Snippet.Language == "" {
+			continue
+		}
+		switch track.Snippet.TrackKind {
+		case "asr":
+			return strPtr(track.Snippet.Language)
+		case "forced":
+			// Forced tracks only cover foreign-language passages
+		default:
+			if !slices.Contains(standard, track.Snippet.Language) {
+				standard = append(standard, track.Snippet.Language)
+			}
+		}
+	}
+
+	if len(standard) == 1 {
+		return strPtr(standard[0])
+	}
+	return nil
+}
+
+// missingVideoIDs returns the requested IDs not in returned, in request order and without duplicates
+func missingVideoIDs(requested []string, returned map[string]bool) []string {
+	var missing []string
+	seen := make(map[string]bool, len(requested))
+	for _, videoID := range requested {
+		if returned[videoID] || seen[videoID] {
+			continue
+		}
+		seen[videoID] = true
+		missing = append(missing, videoID)
+	}
+	return missing
+}
+
+// maxChartResults is the most videos YouTube returns for a chart
+const maxChartResults = 200
+
+// FetchMostPopular retrieves the IDs of the videos on the mostPopular chart for a region, in chart order.
+// Up to maxResults IDs are returned (at most 200); each page of 50 costs 1 quota unit.
+func (c *Client) FetchMostPopular(ctx context.Context, regionCode string, maxResults int) ([]string, error) {
+	if regionCode == "" {
+		return nil, fmt.Errorf("region code is required")
+	}
+	if maxResults <= 0 || maxResults > maxChartResults {
+		maxResults = maxChartResults
+	}
+
+	var videoIDs []string
+	pageToken := ""
+	for len(videoIDs) < maxResults {
+		pageSize := min(maxResults-len(videoIDs), 50)
+		call := c.service.Videos.List([]string{"id"}).
+			Chart("mostPopular").
+			RegionCode(regionCode).
+			MaxResults(int64(pageSize)).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		response, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch mostPopular chart for %s: %w", regionCode, err)
+		}
+
+		// Track quota usage for Videos.List(chart) API call
+		c.recordQuotaUsage(ctx, quota.OpVideosList)
+
+		for _, item := range response.Items {
+			videoIDs = append(videoIDs, item.Id)
+		}
+
+		pageToken = response.NextPageToken
+		if pageToken == "" || len(response.Items) == 0 {
+			break
+		}
+	}
+
+	if len(videoIDs) > maxResults {
+		videoIDs = videoIDs[:maxResults]
+	}
+
+	return videoIDs, nil
+}
+
+// FetchVideoCategories retrieves the video categories of a region as a map of category ID to
+// localized title, with a single videoCategories.list call (1 quota unit).
+func (c *Client) FetchVideoCategories(ctx context.Context, regionCode string) (map[string]string, error) {
+	if regionCode == "" {
+		return nil, fmt.Errorf("region code is required")
+	}
+
+	response, err := c.service.VideoCategories.List([]string{"snippet"}).
+		RegionCode(regionCode).
+		Context(ctx).
+		Do()
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch videos from YouTube API: %w", err)
+		return nil, fmt.Errorf("failed to fetch video categories for %s: %w", regionCode, err)
 	}
 
-	// Quota cost calculation per Google's official documentation:
-	// videos.list base cost = 1 unit (regardless of parts requested)
-	// Source: https://developers.google.com/youtube/v3/determine_quota_cost
-	quotaCost := 1
+	// Track quota usage for VideoCategories.List API call
+	c.recordQuotaUsage(ctx, quota.OpVideoCategoriesList)
 
-	// Track quota usage for Videos.List API call
-	// Per Google documentation: videos.list costs 1 unit
-	if c.quotaTracker != nil {
-		if err := c.quotaTracker.RecordQuotaUsage(ctx, quotaCost, "videos_list"); err != nil {
-			log.Printf("[YouTube Client] Warning: failed to record videos.list quota usage: %v", err)
+	categories := make(map[string]string, len(response.Items))
+	for _, item := range response.Items {
+		if item.Snippet == nil {
+			continue
 		}
+		categories[item.Id] = item.Snippet.Title
 	}
 
-	enrichments := make([]*model.VideoEnrichment, 0, len(response.Items))
+	return categories, nil
+}
+
+// FetchLiveStatus retrieves the live state and concurrent viewers of up to 50 videos with a
+// single videos.list call (1 quota unit). Videos the API does not return are omitted.
+func (c *Client) FetchLiveStatus(ctx context.Context, videoIDs []string) ([]*model.LiveStatus, error) {
+	if len(videoIDs) == 0 {
+		return nil, nil
+	}
+	if len(videoIDs) > 50 {
+		return nil, fmt.Errorf("cannot fetch more than 50 videos at once, got %d", len(videoIDs))
+	}
 
+	response, err := c.service.Videos.List([]string{"snippet", "liveStreamingDetails"}).
+		Id(videoIDs...).
+		Fields("items(id,snippet/liveBroadcastContent,liveStreamingDetails/concurrentViewers)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch live status: %w", err)
+	}
+
+	c.recordQuotaUsage(ctx, quota.OpVideosList)
+
+	statuses := make([]*model.LiveStatus, 0, len(response.Items))
 	for _, item := range response.Items {
-		enrichment := c.mapVideoToEnrichment(item, parts, response.Etag)
-		enrichments = append(enrichments, enrichment)
+		status := &model.LiveStatus{VideoID: item.Id}
+		if item.Snippet != nil {
+			status.LiveBroadcastContent = item.Snippet.LiveBroadcastContent
+		}
+		// concurrentViewers is omitted once the stream ends and may be hidden by the owner
+		if item.LiveStreamingDetails != nil && status.LiveBroadcastContent == "live" && item.LiveStreamingDetails.ConcurrentViewers > 0 {
+			status.ConcurrentViewers = int64Ptr(int64(item.LiveStreamingDetails.ConcurrentViewers))
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// listVideos calls videos.list and decodes the response.
+//
+// The generated client decodes an omitted statistics.commentCount as 0, but YouTube
+// omits it when comments are disabled. The raw response is therefore also read so
+// the second return value can record, per video ID, whether commentCount was present.
+func (c *Client) listVideos(ctx context.Context, parts, videoIDs []string) (*youtube.VideoListResponse, map[string]bool, error) {
+	var raw bytes.Buffer
+	response, err := c.service.Videos.List(parts).
+		Id(videoIDs...).
+		Context(withRawResponse(ctx, &raw)).
+		Do()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var statistics struct {
+		Items []struct {
+			ID         string                     `json:"id"`
+			Statistics map[string]json.RawMessage `json:"statistics"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(raw.Bytes(), &statistics); err != nil {
+		return nil, nil, fmt.Errorf("decode videos.list statistics: %w", err)
+	}
+
+	commentCountPresent := make(map[string]bool, len(statistics.Items))
+	for _, item := range statistics.Items {
+		_, ok := item.Statistics["commentCount"]
+		commentCountPresent[item.ID] = ok
 	}
 
-	return enrichments, quotaCost, nil
+	return response, commentCountPresent, nil
 }
 
 // mapVideoToEnrichment converts YouTube API video response to our enrichment model
-func (c *Client) mapVideoToEnrichment(video *youtube.Video, partsRequested []string, etag string) *model.VideoEnrichment {
+func (c *Client) mapVideoToEnrichment(video *youtube.Video, partsRequested []string, etag string, commentCountPresent bool) *model.VideoEnrichment {
 	enrichment := &model.VideoEnrichment{
 		VideoID:           video.Id,
 		APIResponseEtag:   strPtr(etag),
 		APIPartsRequested: partsRequested,
-		QuotaCost:         1, // Official quota cost per Google documentation
+		QuotaCost:         c.QuotaCost(quota.OpVideosList),
 		RawAPIResponse:    make(map[string]interface{}),
 	}
 
@@ -122,6 +464,16 @@ func (c *Client) mapVideoToEnrichment(video *youtube.Video, partsRequested []str
 		"kind": video.Kind,
 	}
 
+	// The API omits tags both when a video has none and when snippet was not requested, so
+	// whether tags were fetched is decided by the requested parts: an empty list records a
+	// video without tags, and nil (stored as NULL) records that snippet was not fetched
+	if slices.Contains(partsRequested, "snippet") {
+		enrichment.Tags = []string{}
+		if video.Snippet != nil && video.Snippet.Tags != nil {
+			enrichment.Tags = video.Snippet.Tags
+		}
+	}
+
 	// Map Snippet data
 	if video.Snippet != nil {
 		enrichment.Description = strPtr(video.Snippet.Description)
@@ -129,12 +481,7 @@ func (c *Client) mapVideoToEnrichment(video *youtube.Video, partsRequested []str
 		enrichment.DefaultLanguage = strPtr(video.Snippet.DefaultLanguage)
 		enrichment.DefaultAudioLanguage = strPtr(video.Snippet.DefaultAudioLanguage)
 		enrichment.CategoryID = strPtr(video.Snippet.CategoryId)
-
-		if video.Snippet.Tags != nil {
-			enrichment.Tags = video.Snippet.Tags
-		} else {
-			enrichment.Tags = []string{}
-		}
+		enrichment.LiveBroadcastContent = strPtr(video.Snippet.LiveBroadcastContent)
 
 		// Map thumbnails
 		if video.Snippet.Thumbnails != nil {
@@ -190,7 +537,12 @@ func (c *Client) mapVideoToEnrichment(video *youtube.Video, partsRequested []str
 		enrichment.LikeCount = int64Ptr(int64(video.Statistics.LikeCount))
 		enrichment.DislikeCount = int64Ptr(int64(video.Statistics.DislikeCount))
 		enrichment.FavoriteCount = int64Ptr(int64(video.Statistics.FavoriteCount))
-		enrichment.CommentCount = int64Ptr(int64(video.Statistics.CommentCount))
+
+		// A missing commentCount means comments are disabled, not that there are none
+		enrichment.CommentsDisabled = boolPtr(!commentCountPresent)
+		if commentCountPresent {
+			enrichment.CommentCount = int64Ptr(int64(video.Statistics.CommentCount))
+		}
 	}
 
 	// Map Status
@@ -224,7 +576,6 @@ func (c *Client) mapVideoToEnrichment(video *youtube.Video, partsRequested []str
 
 	// Map LiveStreamingDetails
 	if video.LiveStreamingDetails != nil {
-		enrichment.LiveBroadcastContent = strPtr(video.Status.PrivacyStatus) // Note: actual field would be in snippet
 		enrichment.ConcurrentViewers = int64Ptr(int64(video.LiveStreamingDetails.ConcurrentViewers))
 
 		if video.LiveStreamingDetails.ScheduledStartTime != "" {
@@ -244,11 +595,13 @@ func (c *Client) mapVideoToEnrichment(video *youtube.Video, partsRequested []str
 		}
 	}
 
+	enrichment.IsPremiere = enrichment.DetectPremiere()
+
 	return enrichment
 }
 
 // FetchVideosBatch is an alias for FetchVideos for clarity
-func (c *Client) FetchVideosBatch(ctx context.Context, videoIDs []string) ([]*model.VideoEnrichment, int, error) {
+func (c *Client) FetchVideosBatch(ctx context.Context, videoIDs []string) ([]*model.VideoEnrichment, []string, int, error) {
 	return c.FetchVideos(ctx, videoIDs)
 }
 
@@ -307,14 +660,33 @@ func BatchVideoIDs(videoIDs []string, batchSize int) [][]string {
 }
 
 // ParseVideoDuration converts ISO 8601 duration to seconds
-// Example: "PT4M13S" -> 253 seconds
+// Example: "PT4M13S" -> 253 seconds, "P1DT2H" -> 93600 seconds
+// Long livestream archives carry a day component before the T; other date units are rejected.
 func ParseVideoDuration(duration string) (int, error) {
-	if !strings.HasPrefix(duration, "PT") {
-		return 0, fmt.Errorf("invalid duration format: %s", duration)
+	original := duration
+	if !strings.HasPrefix(duration, "P") {
+		return 0, fmt.Errorf("invalid duration format: %s", original)
+	}
+
+	// Split the date portion (days) from the time portion
+	datePart, timePart, hasTime := strings.Cut(strings.TrimPrefix(duration, "P"), "T")
+	if datePart == "" && !hasTime {
+		return 0, fmt.Errorf("invalid duration format: %s", original)
+	}
+
+	var days int
+	if datePart != "" {
+		if !strings.HasSuffix(datePart, "D") {
+			return 0, fmt.Errorf("invalid duration format: %s", original)
+		}
+		d, err := strconv.Atoi(strings.TrimSuffix(datePart, "D"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration format: %s", original)
+		}
+		days = d
 	}
 
-	// Remove PT prefix
-	duration = strings.TrimPrefix(duration, "PT")
+	duration = timePart
 
 	var hours, minutes, seconds int
 
@@ -347,7 +719,7 @@ func ParseVideoDuration(duration string) (int, error) {
 		seconds = s
 	}
 
-	return hours*3600 + minutes*60 + seconds, nil
+	return days*86400 + hours*3600 + minutes*60 + seconds, nil
 }
 
 // ChannelEnrichment represents extended channel metadata from YouTube API
@@ -376,9 +748,10 @@ type ChannelEnrichment struct {
 // - https://www.youtube.com/channel/UCxxxxxx
 // - https://www.youtube.com/c/CustomName
 // - https://www.youtube.com/user/Username
+// - https://www.youtube.com/live/VIDEO_ID and https://www.youtube.com/embed/VIDEO_ID (the video's channel)
 func (c *Client) ResolveChannelByURL(ctx context.Context, urlStr string) (*ChannelEnrichment, error) {
 	// Parse the URL
-	channelID, handle, username, customURL, err := parseYouTubeURL(urlStr)
+	channelID, handle, username, customURL, videoID, err := parseYouTubeURL(urlStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse YouTube URL: %w", err)
 	}
@@ -388,6 +761,11 @@ func (c *Client) ResolveChannelByURL(ctx context.Context, urlStr string) (*Chann
 		return c.GetChannelDetails(ctx, channelID)
 	}
 
+	// If we have a video ID, resolve the channel that owns it
+	if videoID != "" {
+		return c.resolveChannelByVideoID(ctx, videoID)
+	}
+
 	// If we have a handle, search by handle
 	if handle != "" {
 		return c.resolveChannelByHandle(ctx, handle)
@@ -403,7 +781,52 @@ func (c *Client) ResolveChannelByURL(ctx context.Context, urlStr string) (*Chann
 		return c.resolveChannelByCustomURL(ctx, customURL)
 	}
 
-	return nil, fmt.Errorf("unable to extract channel identifier from URL")
+	return nil, fmt.Errorf("%w: unable to extract channel identifier", ErrInvalidChannelURL)
+}
+
+// ResolveQuotaUsage returns the quota ResolveChannelByURL spends resolving urlStr, by operation
+// type. A URL that cannot be parsed needs none, as it is rejected before any call.
+func (c *Client) ResolveQuotaUsage(urlStr string) quota.Usage {
+	channelID, handle, username, customURL, videoID, err := parseYouTubeURL(urlStr)
+	if err != nil {
+		return quota.Usage{}
+	}
+
+	channelsList := c.QuotaCost(quota.OpChannelsList)
+	switch {
+	case channelID != "":
+		return quota.Usage{quota.OpChannelsList: channelsList}
+	case videoID != "":
+		return quota.Usage{quota.OpVideosList: c.QuotaCost(quota.OpVideosList), quota.OpChannelsList: channelsList}
+	case handle != "", username != "":
+		// A lookup by handle or username, then the full details
+		return quota.Usage{quota.OpChannelsList: 2 * channelsList}
+	case customURL != "":
+		// A search, the candidates' details, then the full details of the match
+		return quota.Usage{quota.OpSearchList: c.QuotaCost(quota.OpSearchList), quota.OpChannelsList: 2 * channelsList}
+	}
+	return quota.Usage{}
+}
+
+// resolveChannelByVideoID looks up the channel that uploaded a video (1 unit for
+// videos.list plus 1 for channels.list)
+func (c *Client) resolveChannelByVideoID(ctx context.Context, videoID string) (*ChannelEnrichment, error) {
+	response, err := c.service.Videos.List([]string{"snippet"}).
+		Id(videoID).
+		Fields("items(id,snippet/channelId)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch video from YouTube API: %w", err)
+	}
+
+	c.recordQuotaUsage(ctx, quota.OpVideosList)
+
+	if len(response.Items) == 0 || response.Items[0].Snippet == nil || response.Items[0].Snippet.ChannelId == "" {
+		return nil, fmt.Errorf("%w: no channel for video %s", ErrChannelNotFound, videoID)
+	}
+
+	return c.GetChannelDetails(ctx, response.Items[0].Snippet.ChannelId)
 }
 
 // GetChannelDetails fetches comprehensive channel metadata by channel ID
@@ -425,28 +848,18 @@ func (c *Client) GetChannelDetails(ctx context.Context, channelID string) (*Chan
 	}
 
 	// Track quota usage for Channels.List API call
-	// Per Google documentation: channels.list costs 1 unit
-	if c.quotaTracker != nil {
-		if err := c.quotaTracker.RecordQuotaUsage(ctx, 1, "channels_list"); err != nil {
-			log.Printf("[YouTube Client] Warning: failed to record channels.list quota usage: %v", err)
-		}
-	}
+	channelsCost := c.recordQuotaUsage(ctx, quota.OpChannelsList)
 
 	if len(response.Items) == 0 {
-		return nil, fmt.Errorf("channel not found: %s", channelID)
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, channelID)
 	}
 
 	channel := response.Items[0]
 
-	// Quota cost per Google's official documentation:
-	// channels.list base cost = 1 unit (regardless of parts requested)
-	// Source: https://developers.google.com/youtube/v3/determine_quota_cost
-	quotaCost := 1
-
 	enrichment := &ChannelEnrichment{
 		ChannelID:       channel.Id,
 		APIResponseEtag: response.Etag,
-		QuotaCost:       quotaCost,
+		QuotaCost:       channelsCost,
 	}
 
 	// Map snippet data
@@ -517,15 +930,10 @@ func (c *Client) resolveChannelByHandle(ctx context.Context, handle string) (*Ch
 	}
 
 	// Track quota usage for Channels.List(ForHandle) API call
-	// Per Google documentation: channels.list costs 1 unit
-	if c.quotaTracker != nil {
-		if err := c.quotaTracker.RecordQuotaUsage(ctx, 1, "channels_list"); err != nil {
-			log.Printf("[YouTube Client] Warning: failed to record channels.list quota usage: %v", err)
-		}
-	}
+	lookupCost := c.recordQuotaUsage(ctx, quota.OpChannelsList)
 
 	if len(response.Items) == 0 {
-		return nil, fmt.Errorf("channel not found for handle: @%s", handle)
+		return nil, fmt.Errorf("%w for handle: @%s", ErrChannelNotFound, handle)
 	}
 
 	// Use the first result and fetch full details (this makes another API call and tracks quota separately)
@@ -535,8 +943,8 @@ func (c *Client) resolveChannelByHandle(ctx context.Context, handle string) (*Ch
 		return nil, err
 	}
 
-	// Update total quota cost to include both Channels.List calls (1 + 1 = 2)
-	enrichment.QuotaCost = 2
+	// Update total quota cost to include both Channels.List calls
+	enrichment.QuotaCost += lookupCost
 
 	return enrichment, nil
 }
@@ -558,15 +966,10 @@ func (c *Client) resolveChannelByUsername(ctx context.Context, username string)
 	}
 
 	// Track quota usage for Channels.List(ForUsername) API call
-	// Per Google documentation: channels.list costs 1 unit
-	if c.quotaTracker != nil {
-		if err := c.quotaTracker.RecordQuotaUsage(ctx, 1, "channels_list"); err != nil {
-			log.Printf("[YouTube Client] Warning: failed to record channels.list quota usage: %v", err)
-		}
-	}
+	lookupCost := c.recordQuotaUsage(ctx, quota.OpChannelsList)
 
 	if len(response.Items) == 0 {
-		return nil, fmt.Errorf("channel not found for username: %s", username)
+		return nil, fmt.Errorf("%w for username: %s", ErrChannelNotFound, username)
 	}
 
 	// Use the first result and fetch full details (this makes another API call and tracks quota separately)
@@ -576,12 +979,34 @@ func (c *Client) resolveChannelByUsername(ctx context.Context, username string)
 		return nil, err
 	}
 
-	// Update total quota cost to include both Channels.List calls (1 + 1 = 2)
-	enrichment.QuotaCost = 2
+	// Update total quota cost to include both Channels.List calls
+	enrichment.QuotaCost += lookupCost
 
 	return enrichment, nil
 }
 
+// customURLSearchMaxResults is the number of search results considered when resolving a custom URL
+const customURLSearchMaxResults = 5
+
+// ChannelCandidate is a possible match when a channel cannot be resolved unambiguously
+type ChannelCandidate struct {
+	ChannelID       string `json:"channel_id"`
+	Title           string `json:"title"`
+	CustomURL       string `json:"custom_url,omitempty"`
+	SubscriberCount int64  `json:"subscriber_count"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+}
+
+// AmbiguousChannelError is returned when a search matches several channels and none is a confident match
+type AmbiguousChannelError struct {
+	Query      string
+	Candidates []ChannelCandidate
+}
+
+func (e *AmbiguousChannelError) Error() string {
+	return fmt.Sprintf("ambiguous channel match for '%s': %d candidates", e.Query, len(e.Candidates))
+}
+
 // resolveChannelByCustomURL searches for a channel by custom URL
 func (c *Client) resolveChannelByCustomURL(ctx context.Context, customURL string) (*ChannelEnrichment, error) {
 	// For custom URLs, we need to use the Search API
@@ -589,7 +1014,7 @@ func (c *Client) resolveChannelByCustomURL(ctx context.Context, customURL string
 	call := c.service.Search.List([]string{"snippet"}).
 		Q(customURL).
 		Type("channel").
-		MaxResults(5).
+		MaxResults(customURLSearchMaxResults).
 		Context(ctx)
 
 	response, err := call.Do()
@@ -598,38 +1023,144 @@ func (c *Client) resolveChannelByCustomURL(ctx context.Context, customURL string
 	}
 
 	// Track quota usage for Search.List API call
-	// Per Google documentation: search.list costs 100 units
-	if c.quotaTracker != nil {
-		if err := c.quotaTracker.RecordQuotaUsage(ctx, 100, "search_list"); err != nil {
-			log.Printf("[YouTube Client] Warning: failed to record search.list quota usage: %v", err)
-		}
-	}
+	searchCost := c.recordQuotaUsage(ctx, quota.OpSearchList)
 
 	if len(response.Items) == 0 {
-		return nil, fmt.Errorf("channel not found for custom URL: %s", customURL)
+		return nil, fmt.Errorf("%w for custom URL: %s", ErrChannelNotFound, customURL)
 	}
 
-	// Take the first result (best match)
-	channelID := response.Items[0].Id.ChannelId
-	if channelID == "" {
+	channelIDs := make([]string, 0, len(response.Items))
+	for _, item := range response.Items {
+		if item.Id != nil && item.Id.ChannelId != "" {
+			channelIDs = append(channelIDs, item.Id.ChannelId)
+		}
+	}
+	if len(channelIDs) == 0 {
 		return nil, fmt.Errorf("search result did not contain a channel ID")
 	}
 
+	// Fetch subscriber counts and custom URLs so the results can be disambiguated
+	candidates, err := c.getChannelCandidates(ctx, channelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	match, ok := selectChannelCandidate(customURL, candidates)
+	if !ok {
+		return nil, &AmbiguousChannelError{Query: customURL, Candidates: candidates}
+	}
+
 	// Fetch full channel details (this makes another API call and tracks quota separately)
-	enrichment, err := c.GetChannelDetails(ctx, channelID)
+	enrichment, err := c.GetChannelDetails(ctx, match.ChannelID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update total quota cost to include both Search.List (100) + Channels.List (1)
-	enrichment.QuotaCost = 101
+	// Update total quota cost to include Search.List and the candidate Channels.List call
+	enrichment.QuotaCost += searchCost + c.QuotaCost(quota.OpChannelsList)
 
 	return enrichment, nil
 }
 
+// getChannelCandidates fetches summary details for a set of channel IDs, preserving their order
+func (c *Client) getChannelCandidates(ctx context.Context, channelIDs []string) ([]ChannelCandidate, error) {
+	call := c.service.Channels.List([]string{"snippet", "statistics"}).Id(channelIDs...).Context(ctx)
+	response, err := call.Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch candidate channels from YouTube API: %w", err)
+	}
+
+	c.recordQuotaUsage(ctx, quota.OpChannelsList)
+
+	byID := make(map[string]ChannelCandidate, len(response.Items))
+	for _, channel := range response.Items {
+		candidate := ChannelCandidate{ChannelID: channel.Id}
+		if channel.Snippet != nil {
+			candidate.Title = channel.Snippet.Title
+			candidate.CustomURL = channel.Snippet.CustomUrl
+			if channel.Snippet.Thumbnails != nil && channel.Snippet.Thumbnails.Default != nil {
+				candidate.ThumbnailURL = channel.Snippet.Thumbnails.Default.Url
+			}
+		}
+		if channel.Statistics != nil {
+			candidate.SubscriberCount = int64(channel.Statistics.SubscriberCount)
+		}
+		byID[channel.Id] = candidate
+	}
+
+	// Keep the search ranking order
+	candidates := make([]ChannelCandidate, 0, len(byID))
+	for _, id := range channelIDs {
+		if candidate, ok := byID[id]; ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w for candidate IDs: %s", ErrChannelNotFound, strings.Join(channelIDs, ","))
+	}
+
+	return candidates, nil
+}
+
+// selectChannelCandidate picks a confident match for a custom URL search
+// A match is confident when there is only one candidate, or exactly one candidate's custom URL equals the query
+func selectChannelCandidate(query string, candidates []ChannelCandidate) (*ChannelCandidate, bool) {
+	if len(candidates) == 1 {
+		return &candidates[0], true
+	}
+
+	normalizedQuery := strings.ToLower(strings.TrimPrefix(query, "@"))
+
+	var match *ChannelCandidate
+	for i := range candidates {
+		customURL := strings.ToLower(strings.TrimPrefix(candidates[i].CustomURL, "@"))
+		if customURL == "" || customURL != normalizedQuery {
+			continue
+		}
+		if match != nil {
+			return nil, false
+		}
+		match = &candidates[i]
+	}
+
+	if match == nil {
+		return nil, false
+	}
+
+	return match, true
+}
+
+// NormalizeChannelURL returns a canonical key for the channel a URL refers to, so different
+// spellings of the same channel URL (scheme, host, trailing segments, handle case) compare equal
+func NormalizeChannelURL(urlStr string) (string, error) {
+	channelID, handle, username, customURL, videoID, err := parseYouTubeURL(urlStr)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case channelID != "":
+		return "channel:" + channelID, nil
+	case videoID != "":
+		// Video IDs are case-sensitive
+		return "video:" + videoID, nil
+	case handle != "":
+		// Handles are case-insensitive
+		return "handle:" + strings.ToLower(handle), nil
+	case username != "":
+		return "user:" + strings.ToLower(username), nil
+	default:
+		return "c:" + strings.ToLower(customURL), nil
+	}
+}
+
+// videoPathRegex matches the /live/<id> and /embed/<id> video URL forms
+var videoPathRegex = regexp.MustCompile(`^/(?:live|embed)/([a-zA-Z0-9_-]{11})(?:/|$)`)
+
 // parseYouTubeURL extracts channel identifiers from various YouTube URL formats
-// Returns: (channelID, handle, username, customURL, error)
-func parseYouTubeURL(urlStr string) (string, string, string, string, error) {
+// Returns: (channelID, handle, username, customURL, videoID, error)
+func parseYouTubeURL(urlStr string) (string, string, string, string, string, error) {
 	// Clean up the URL
 	urlStr = strings.TrimSpace(urlStr)
 
@@ -640,13 +1171,13 @@ func parseYouTubeURL(urlStr string) (string, string, string, string, error) {
 
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
-		return "", "", "", "", fmt.Errorf("invalid URL: %w", err)
+		return "", "", "", "", "", fmt.Errorf("%w: %w", ErrInvalidChannelURL, err)
 	}
 
 	// Check if it's a YouTube domain
 	host := strings.ToLower(parsedURL.Host)
 	if !strings.Contains(host, "youtube.com") && !strings.Contains(host, "youtu.be") {
-		return "", "", "", "", fmt.Errorf("not a YouTube URL: %s", host)
+		return "", "", "", "", "", fmt.Errorf("%w: not a YouTube URL: %s", ErrInvalidChannelURL, host)
 	}
 
 	path := parsedURL.Path
@@ -659,13 +1190,13 @@ func parseYouTubeURL(urlStr string) (string, string, string, string, error) {
 		if idx := strings.Index(handle, "/"); idx != -1 {
 			handle = handle[:idx]
 		}
-		return "", handle, "", "", nil
+		return "", handle, "", "", "", nil
 	}
 
 	// Pattern 2: /channel/UCxxxxxx
 	channelIDRegex := regexp.MustCompile(`^/channel/(UC[a-zA-Z0-9_-]{22})`)
 	if matches := channelIDRegex.FindStringSubmatch(path); len(matches) > 1 {
-		return matches[1], "", "", "", nil
+		return matches[1], "", "", "", "", nil
 	}
 
 	// Pattern 3: /c/CustomName
@@ -675,7 +1206,7 @@ func parseYouTubeURL(urlStr string) (string, string, string, string, error) {
 		if idx := strings.Index(customName, "/"); idx != -1 {
 			customName = customName[:idx]
 		}
-		return "", "", "", customName, nil
+		return "", "", "", customName, "", nil
 	}
 
 	// Pattern 4: /user/Username
@@ -685,8 +1216,13 @@ func parseYouTubeURL(urlStr string) (string, string, string, string, error) {
 		if idx := strings.Index(username, "/"); idx != -1 {
 			username = username[:idx]
 		}
-		return "", "", username, "", nil
+		return "", "", username, "", "", nil
+	}
+
+	// Pattern 5: /live/VIDEO_ID or /embed/VIDEO_ID, resolved through the video's channel
+	if matches := videoPathRegex.FindStringSubmatch(path); len(matches) > 1 {
+		return "", "", "", "", matches[1], nil
 	}
 
-	return "", "", "", "", fmt.Errorf("unsupported YouTube URL format: %s", urlStr)
+	return "", "", "", "", "", fmt.Errorf("%w: unsupported YouTube URL format: %s", ErrInvalidChannelURL, urlStr)
 }
@@ -0,0 +1,633 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ad-tracker/youtube-webhook-ingestion/internal/service/quota"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// newTestClient creates a Client backed by a fake YouTube Data API server
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	service, err := newService(context.Background(), "test-key", server.Client().Transport,
+		option.WithEndpoint(server.URL+"/"),
+	)
+	require.NoError(t, err)
+
+	return &Client{service: service}
+}
+
+// fakeChannel is the minimal channel shape used by the fake API server
+type fakeChannel struct {
+	ID          string
+	Title       string
+	CustomURL   string
+	Subscribers uint64
+	VideoIDs    []string
+}
+
+// fakeYouTubeAPI serves search.list, channels.list and videos.list responses for the given channels
+func fakeYouTubeAPI(t *testing.T, channels []fakeChannel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/search"):
+			resp := &youtube.SearchListResponse{}
+			for _, ch := range channels {
+				resp.Items = append(resp.Items, &youtube.SearchResult{
+					Id: &youtube.ResourceId{Kind: "youtube#channel", ChannelId: ch.ID},
+				})
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+
+		case strings.HasSuffix(r.URL.Path, "/channels"):
+			requested := map[string]bool{}
+			for _, id := range r.URL.Query()["id"] {
+				for _, part := range strings.Split(id, ",") {
+					requested[part] = true
+				}
+			}
+
+			resp := &youtube.ChannelListResponse{}
+			for _, ch := range channels {
+				if !requested[ch.ID] {
+					continue
+				}
+				resp.Items = append(resp.Items, &youtube.Channel{
+					Id: ch.ID,
+					Snippet: &youtube.ChannelSnippet{
+						Title:     ch.Title,
+						CustomUrl: ch.CustomURL,
+						Thumbnails: &youtube.ThumbnailDetails{
+							Default: &youtube.Thumbnail{Url: "https://yt3.example.com/" + ch.ID + ".jpg"},
+						},
+					},
+					Statistics: &youtube.ChannelStatistics{SubscriberCount: ch.Subscribers},
+				})
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+
+		case strings.HasSuffix(r.URL.Path, "/videos"):
+			resp := &youtube.VideoListResponse{}
+			for _, ch := range channels {
+				for _, videoID := range ch.VideoIDs {
+					if videoID == r.URL.Query().Get("id") {
+						resp.Items = append(resp.Items, &youtube.Video{
+							Id:      videoID,
+							Snippet: &youtube.VideoSnippet{ChannelId: ch.ID},
+						})
+					}
+				}
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestResolveChannelByCustomURL_ConfidentMatch(t *testing.T) {
+	client := newTestClient(t, fakeYouTubeAPI(t, []fakeChannel{
+		{ID: "UCaaaaaaaaaaaaaaaaaaaaaa", Title: "Cooking Fan", CustomURL: "@cookingfan", Subscribers: 1200},
+		{ID: "UCbbbbbbbbbbbbbbbbbbbbbb", Title: "Cooking", CustomURL: "@cooking", Subscribers: 950000},
+	}))
+
+	enrichment, err := client.ResolveChannelByURL(context.Background(), "https://www.youtube.com/c/Cooking")
+	require.NoError(t, err)
+
+	assert.Equal(t, "UCbbbbbbbbbbbbbbbbbbbbbb", enrichment.ChannelID)
+	assert.Equal(t, int64(950000), enrichment.SubscriberCount)
+	assert.Equal(t, 102, enrichment.QuotaCost)
+}
+
+func TestResolveChannelByCustomURL_AmbiguousMatch(t *testing.T) {
+	client := newTestClient(t, fakeYouTubeAPI(t, []fakeChannel{
+		{ID: "UCaaaaaaaaaaaaaaaaaaaaaa", Title: "Gaming", CustomURL: "@gamingclips", Subscribers: 5000},
+		{ID: "UCbbbbbbbbbbbbbbbbbbbbbb", Title: "Gaming", CustomURL: "@gamingnews", Subscribers: 72000},
+		{ID: "UCcccccccccccccccccccccc", Title: "Gaming Daily", CustomURL: "", Subscribers: 300},
+	}))
+
+	enrichment, err := client.ResolveChannelByURL(context.Background(), "https://www.youtube.com/c/Gaming")
+	require.Error(t, err)
+	assert.Nil(t, enrichment)
+
+	var ambiguousErr *AmbiguousChannelError
+	require.True(t, errors.As(err, &ambiguousErr))
+	assert.Equal(t, "Gaming", ambiguousErr.Query)
+	require.Len(t, ambiguousErr.Candidates, 3)
+
+	// Candidates keep the search ranking and carry subscriber counts and thumbnails
+	assert.Equal(t, "UCaaaaaaaaaaaaaaaaaaaaaa", ambiguousErr.Candidates[0].ChannelID)
+	assert.Equal(t, int64(72000), ambiguousErr.Candidates[1].SubscriberCount)
+	assert.Equal(t, "https://yt3.example.com/UCcccccccccccccccccccccc.jpg", ambiguousErr.Candidates[2].ThumbnailURL)
+}
+
+func TestResolveChannelByURL_VideoURLs(t *testing.T) {
+	client := newTestClient(t, fakeYouTubeAPI(t, []fakeChannel{
+		{ID: "UCaaaaaaaaaaaaaaaaaaaaaa", Title: "Other", VideoIDs: []string{"aaaaaaaaaaa"}},
+		{ID: "UCbbbbbbbbbbbbbbbbbbbbbb", Title: "Streamer", Subscribers: 4200, VideoIDs: []string{"dQw4w9WgXcQ"}},
+	}))
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "live URL", url: "https://www.youtube.com/live/dQw4w9WgXcQ?si=abc123"},
+		{name: "embed URL", url: "https://www.youtube.com/embed/dQw4w9WgXcQ"},
+		{name: "embed URL without scheme", url: "youtube.com/embed/dQw4w9WgXcQ?start=30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enrichment, err := client.ResolveChannelByURL(context.Background(), tt.url)
+			require.NoError(t, err)
+
+			assert.Equal(t, "UCbbbbbbbbbbbbbbbbbbbbbb", enrichment.ChannelID)
+			assert.Equal(t, "Streamer", enrichment.Title)
+			assert.Equal(t, int64(4200), enrichment.SubscriberCount)
+		})
+	}
+
+	key, err := NormalizeChannelURL("https://youtube.com/live/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, "video:dQw4w9WgXcQ", key)
+}
+
+func TestResolveChannelByURL_Errors(t *testing.T) {
+	client := newTestClient(t, fakeYouTubeAPI(t, nil))
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{name: "unknown channel ID", url: "https://www.youtube.com/channel/UCzzzzzzzzzzzzzzzzzzzzzz", wantErr: ErrChannelNotFound},
+		{name: "custom URL with no search results", url: "https://www.youtube.com/c/NoSuchChannel", wantErr: ErrChannelNotFound},
+		{name: "not a YouTube URL", url: "https://vimeo.com/channels/staffpicks", wantErr: ErrInvalidChannelURL},
+		{name: "unsupported YouTube URL format", url: "https://www.youtube.com/playlist?list=PL123", wantErr: ErrInvalidChannelURL},
+		{name: "unknown live video", url: "https://www.youtube.com/live/zzzzzzzzzzz", wantErr: ErrChannelNotFound},
+		{name: "malformed embed video ID", url: "https://www.youtube.com/embed/short", wantErr: ErrInvalidChannelURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ResolveChannelByURL(context.Background(), tt.url)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFetchVideos_CommentsDisabled(t *testing.T) {
+	// commentCount is omitted by YouTube when comments are disabled
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasSuffix(r.URL.Path, "/videos"))
+		assert.Equal(t, "test-key", r.URL.Query().Get("key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"etag": "etag123",
+			"items": [
+				{"id": "disabled123", "statistics": {"viewCount": "5000", "likeCount": "40"}},
+				{"id": "nocomments123", "statistics": {"viewCount": "10", "likeCount": "0", "commentCount": "0"}},
+				{"id": "nostats123", "snippet": {"title": "No statistics"}}
+			]
+		}`))
+	})
+
+	enrichments, missing, quotaCost, err := client.FetchVideos(context.Background(), []string{"disabled123", "nocomments123", "nostats123"})
+	require.NoError(t, err)
+	require.Len(t, enrichments, 3)
+	assert.Empty(t, missing)
+	assert.Equal(t, 1, quotaCost)
+
+	disabled := enrichments[0]
+	require.NotNil(t, disabled.CommentsDisabled)
+	assert.True(t, *disabled.CommentsDisabled)
+	assert.Nil(t, disabled.CommentCount, "disabled comments should leave comment_count null")
+	require.NotNil(t, disabled.ViewCount)
+	assert.Equal(t, int64(5000), *disabled.ViewCount)
+
+	noComments := enrichments[1]
+	require.NotNil(t, noComments.CommentsDisabled)
+	assert.False(t, *noComments.CommentsDisabled)
+	require.NotNil(t, noComments.CommentCount)
+	assert.Equal(t, int64(0), *noComments.CommentCount)
+
+	noStats := enrichments[2]
+	assert.Nil(t, noStats.CommentsDisabled, "unknown when statistics were not returned")
+	assert.Nil(t, noStats.CommentCount)
+}
+
+func TestFetchVideos_ReportsMissingIDs(t *testing.T) {
+	// Private and deleted videos are left out of the response without an error
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"public1", "private1", "public2"}, r.URL.Query()["id"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"items": [
+				{"id": "public1", "snippet": {"title": "First"}},
+				{"id": "public2", "snippet": {"title": "Second"}}
+			]
+		}`))
+	})
+
+	enrichments, missing, _, err := client.FetchVideos(context.Background(), []string{"public1", "private1", "public2"})
+	require.NoError(t, err)
+	require.Len(t, enrichments, 2)
+	assert.Equal(t, []string{"private1"}, missing)
+}
+
+func TestFetchVideos_APIError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "message": "quota exceeded", "errors": [{"reason": "quotaExceeded"}]}}`))
+	})
+
+	_, _, _, err := client.FetchVideos(context.Background(), []string{"video123"})
+	require.Error(t, err)
+
+	var apiErr *googleapi.Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusForbidden, apiErr.Code)
+}
+
+func TestSelectChannelCandidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		candidates []ChannelCandidate
+		wantID     string
+		wantOK     bool
+	}{
+		{
+			name:       "single candidate is a confident match",
+			query:      "Anything",
+			candidates: []ChannelCandidate{{ChannelID: "UC1", CustomURL: "@other"}},
+			wantID:     "UC1",
+			wantOK:     true,
+		},
+		{
+			name:  "unique custom URL match is case-insensitive",
+			query: "MyChannel",
+			candidates: []ChannelCandidate{
+				{ChannelID: "UC1", CustomURL: "@mychannelclips"},
+				{ChannelID: "UC2", CustomURL: "@mychannel"},
+			},
+			wantID: "UC2",
+			wantOK: true,
+		},
+		{
+			name:  "no custom URL match is ambiguous",
+			query: "MyChannel",
+			candidates: []ChannelCandidate{
+				{ChannelID: "UC1", CustomURL: "@one"},
+				{ChannelID: "UC2", CustomURL: "@two"},
+			},
+			wantOK: false,
+		},
+		{
+			name:  "duplicate custom URL matches are ambiguous",
+			query: "dup",
+			candidates: []ChannelCandidate{
+				{ChannelID: "UC1", CustomURL: "@dup"},
+				{ChannelID: "UC2", CustomURL: "dup"},
+			},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := selectChannelCandidate(tt.query, tt.candidates)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				require.NotNil(t, got)
+				assert.Equal(t, tt.wantID, got.ChannelID)
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+}
+
+func TestFetchMostPopular_Pages(t *testing.T) {
+	var pages int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasSuffix(r.URL.Path, "/videos"))
+		assert.Equal(t, "mostPopular", r.URL.Query().Get("chart"))
+		assert.Equal(t, "US", r.URL.Query().Get("regionCode"))
+		pages++
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"nextPageToken": "page2", "items": [{"id": "first"}, {"id": "second"}]}`))
+			return
+		}
+		assert.Equal(t, "page2", r.URL.Query().Get("pageToken"))
+		w.Write([]byte(`{"items": [{"id": "third"}]}`))
+	})
+
+	videoIDs, err := client.FetchMostPopular(context.Background(), "US", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "third"}, videoIDs)
+	assert.Equal(t, 2, pages)
+}
+
+func TestFetchVideoCategories(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasSuffix(r.URL.Path, "/videoCategories"))
+		assert.Equal(t, "US", r.URL.Query().Get("regionCode"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": [
+			{"id": "10", "snippet": {"title": "Music"}},
+			{"id": "28", "snippet": {"title": "Science & Technology"}}
+		]}`))
+	})
+
+	categories, err := client.FetchVideoCategories(context.Background(), "US")
+	require.NoError(t, err)
+	assert.Equal(t, "Science & Technology", categories["28"])
+	assert.Equal(t, "Music", categories["10"])
+}
+
+func TestParseVideoDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		duration string
+		want     int
+		wantErr  bool
+	}{
+		{duration: "PT4M13S", want: 253},
+		{duration: "PT1H", want: 3600},
+		{duration: "P1DT2H3M4S", want: 93784},
+		{duration: "P1DT2H", want: 93600},
+		{duration: "P2D", want: 172800},
+		{duration: "P0D", want: 0},
+		{duration: "4M13S", wantErr: true},
+		{duration: "P", wantErr: true},
+		{duration: "P1WT1H", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.duration, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseVideoDuration(tt.duration)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMapVideoToEnrichment_TagsFetchedVsNotFetched(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{}
+
+	t.Run("snippet fetched without tags", func(t *testing.T) {
+		video := &youtube.Video{Id: "video123", Snippet: &youtube.VideoSnippet{Title: "No tags"}}
+
+		enrichment := client.mapVideoToEnrichment(video, []string{"snippet", "statistics"}, "etag", true)
+		require.NotNil(t, enrichment.Tags)
+		assert.Empty(t, enrichment.Tags)
+	})
+
+	t.Run("snippet not requested", func(t *testing.T) {
+		video := &youtube.Video{Id: "video123", Statistics: &youtube.VideoStatistics{ViewCount: 10}}
+
+		enrichment := client.mapVideoToEnrichment(video, []string{"statistics"}, "etag", true)
+		assert.Nil(t, enrichment.Tags)
+	})
+
+	t.Run("snippet present but not listed in requested parts", func(t *testing.T) {
+		video := &youtube.Video{Id: "video123", Snippet: &youtube.VideoSnippet{Title: "No tags"}}
+
+		enrichment := client.mapVideoToEnrichment(video, []string{"statistics"}, "etag", true)
+		assert.Nil(t, enrichment.Tags)
+	})
+
+	t.Run("snippet requested but missing from the response", func(t *testing.T) {
+		video := &youtube.Video{Id: "video123"}
+
+		enrichment := client.mapVideoToEnrichment(video, []string{"snippet"}, "etag", true)
+		require.NotNil(t, enrichment.Tags)
+		assert.Empty(t, enrichment.Tags)
+	})
+
+	t.Run("snippet fetched with tags", func(t *testing.T) {
+		video := &youtube.Video{Id: "video123", Snippet: &youtube.VideoSnippet{Tags: []string{"go", "tutorial"}}}
+
+		enrichment := client.mapVideoToEnrichment(video, []string{"snippet"}, "etag", true)
+		assert.Equal(t, []string{"go", "tutorial"}, enrichment.Tags)
+	})
+}
+
+func TestFetchVideos_InfersLanguageFromCaptions(t *testing.T) {
+	var captionRequests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/videos"):
+			w.Write([]byte(`{
+				"items": [
+					{"id": "blanklang1", "snippet": {"title": "No language"}, "contentDetails": {"caption": "true"}},
+					{"id": "reported1", "snippet": {"title": "Reported", "defaultAudioLanguage": "en"}, "contentDetails": {"caption": "true"}},
+					{"id": "nocaptions1", "snippet": {"title": "No captions"}, "contentDetails": {"caption": "false"}}
+				]
+			}`))
+		case strings.HasSuffix(r.URL.Path, "/captions"):
+			captionRequests = append(captionRequests, r.URL.Query().Get("videoId"))
+			w.Write([]byte(`{
+				"items": [
+					{"snippet": {"language": "en", "trackKind": "standard"}},
+					{"snippet": {"language": "de", "trackKind": "asr"}}
+				]
+			}`))
+		default:
+			t.Fatalf("unexpected request path %s", r.URL.Path)
+		}
+	})
+	client.SetCaptionLanguageInference(true)
+
+	// Callers that do not ask for inference, like the availability checker, spend no captions quota
+	enrichments, _, quotaCost, err := client.FetchVideos(context.Background(), []string{"blanklang1"})
+	require.NoError(t, err)
+	assert.Empty(t, captionRequests)
+	assert.Equal(t, 1, quotaCost)
+	assert.Nil(t, enrichments[0].InferredLanguage)
+	assert.Equal(t, quota.Usage{quota.OpVideosList: 1}, client.FetchVideosQuotaUsage(3))
+
+	opts := FetchOptions{InferLanguage: true}
+	assert.Equal(t, quota.Usage{quota.OpVideosList: 1, quota.OpCaptionsList: 3 * quota.DefaultCosts[quota.OpCaptionsList]},
+		client.FetchVideosQuotaUsageWithOptions(3, opts))
+
+	enrichments, _, quotaCost, err = client.FetchVideosWithOptions(context.Background(), []string{"blanklang1", "reported1", "nocaptions1"}, opts)
+	require.NoError(t, err)
+	require.Len(t, enrichments, 3)
+
+	assert.Equal(t, []string{"blanklang1"}, captionRequests, "only videos with captions and no reported language are looked up")
+	assert.Equal(t, 1+quota.DefaultCosts[quota.OpCaptionsList], quotaCost)
+
+	blank := enrichments[0]
+	require.NotNil(t, blank.InferredLanguage)
+	assert.Equal(t, "de", *blank.InferredLanguage)
+	assert.Nil(t, blank.DefaultLanguage, "API-reported fields stay unset")
+	assert.Nil(t, blank.DefaultAudioLanguage)
+	assert.Equal(t, 1+quota.DefaultCosts[quota.OpCaptionsList], blank.QuotaCost)
+
+	assert.Nil(t, enrichments[1].InferredLanguage)
+	assert.Nil(t, enrichments[2].InferredLanguage)
+}
+
+func TestInferLanguageFromCaptions(t *testing.T) {
+	track := func(language, kind string) *youtube.Caption {
+		return &youtube.Caption{Snippet: &youtube.CaptionSnippet{Language: language, TrackKind: kind}}
+	}
+
+	tests := []struct {
+		name   string
+		tracks []*youtube.Caption
+		want   *string
+	}{
+		{"no tracks", nil, nil},
+		{"automatic track wins", []*youtube.Caption{track("fr", "standard"), track("es", "asr")}, strPtr("es")},
+		{"single standard language", []*youtube.Caption{track("ja", "standard"), track("ja", "standard")}, strPtr("ja")},
+		{"translations are ambiguous", []*youtube.Caption{track("en", "standard"), track("pt", "standard")}, nil},
+		{"forced tracks ignored", []*youtube.Caption{track("it", "standard"), track("en", "forced")}, strPtr("it")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, inferLanguageFromCaptions(tt.tracks))
+		})
+	}
+}
+
+// recordingQuotaTracker records the cost of each quota usage by operation type
+type recordingQuotaTracker struct {
+	costs map[string][]int
+}
+
+func (r *recordingQuotaTracker) RecordQuotaUsage(ctx context.Context, quotaCost int, operationType string) error {
+	r.costs[operationType] = append(r.costs[operationType], quotaCost)
+	return nil
+}
+
+func TestClient_RecordsConfiguredQuotaCosts(t *testing.T) {
+	channels := fakeYouTubeAPI(t, []fakeChannel{
+		{ID: "UCbbbbbbbbbbbbbbbbbbbbbb", Title: "Cooking", CustomURL: "@cooking", Subscribers: 950000},
+	})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/videos"):
+			w.Write([]byte(`{"items": [{"id": "video1", "snippet": {"title": "No language"}, "contentDetails": {"caption": "true"}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/captions"):
+			w.Write([]byte(`{"items": [{"snippet": {"language": "en", "trackKind": "asr"}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/videoCategories"):
+			w.Write([]byte(`{"items": [{"id": "10", "snippet": {"title": "Music"}}]}`))
+		default:
+			channels(w, r)
+		}
+	})
+
+	costs, err := quota.ParseCostTable("videos_list=3,channels_list=7,search_list=250,captions_list=60,video_categories_list=5")
+	require.NoError(t, err)
+	tracker := &recordingQuotaTracker{costs: map[string][]int{}}
+	client.SetQuotaTracker(tracker)
+	client.SetQuotaCosts(costs)
+	client.SetCaptionLanguageInference(true)
+
+	ctx := context.Background()
+
+	enrichments, _, quotaCost, err := client.FetchVideosWithOptions(ctx, []string{"video1"}, FetchOptions{InferLanguage: true})
+	require.NoError(t, err)
+	require.Len(t, enrichments, 1)
+	assert.Equal(t, 3+60, quotaCost)
+	assert.Equal(t, 3+60, enrichments[0].QuotaCost)
+
+	_, err = client.FetchVideoCategories(ctx, "US")
+	require.NoError(t, err)
+
+	channel, err := client.GetChannelDetails(ctx, "UCbbbbbbbbbbbbbbbbbbbbbb")
+	require.NoError(t, err)
+	assert.Equal(t, 7, channel.QuotaCost)
+
+	// A custom URL costs a search plus channels.list calls for the candidates and the details
+	channel, err = client.ResolveChannelByURL(ctx, "https://www.youtube.com/c/Cooking")
+	require.NoError(t, err)
+	assert.Equal(t, 250+7+7, channel.QuotaCost)
+
+	assert.Equal(t, map[string][]int{
+		quota.OpVideosList:          {3},
+		quota.OpCaptionsList:        {60},
+		quota.OpVideoCategoriesList: {5},
+		quota.OpChannelsList:        {7, 7, 7},
+		quota.OpSearchList:          {250},
+	}, tracker.costs)
+}
+
+func TestClient_ResolveQuotaUsageMatchesRecordedUsage(t *testing.T) {
+	client := newTestClient(t, fakeYouTubeAPI(t, []fakeChannel{
+		{ID: "UCbbbbbbbbbbbbbbbbbbbbbb", Title: "Cooking", CustomURL: "@cooking", Subscribers: 950000},
+	}))
+	costs, err := quota.ParseCostTable("channels_list=7,search_list=250")
+	require.NoError(t, err)
+	client.SetQuotaCosts(costs)
+
+	for _, urlStr := range []string{
+		"https://www.youtube.com/c/Cooking",
+		"https://www.youtube.com/channel/UCbbbbbbbbbbbbbbbbbbbbbb",
+	} {
+		t.Run(urlStr, func(t *testing.T) {
+			tracker := &recordingQuotaTracker{costs: map[string][]int{}}
+			client.SetQuotaTracker(tracker)
+
+			_, err := client.ResolveChannelByURL(context.Background(), urlStr)
+			require.NoError(t, err)
+
+			recorded := quota.Usage{}
+			for operationType, calls := range tracker.costs {
+				for _, cost := range calls {
+					recorded[operationType] += cost
+				}
+			}
+			assert.Equal(t, recorded, client.ResolveQuotaUsage(urlStr))
+		})
+	}
+
+	assert.Equal(t, quota.Usage{}, client.ResolveQuotaUsage("not a url"))
+}
@@ -0,0 +1,9 @@
+-- Deferred and skipped jobs have no equivalent in the original status set
+UPDATE enrichment_jobs SET status = 'cancelled' WHERE status IN ('deferred', 'skipped');
+
+ALTER TABLE enrichment_jobs DROP CONSTRAINT chk_status;
+ALTER TABLE enrichment_jobs ADD CONSTRAINT chk_status
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'));
+
+ALTER TABLE enrichment_jobs
+DROP COLUMN defer_reason;
@@ -0,0 +1,11 @@
+-- Allow enrichment jobs to be deferred or skipped (e.g. live streams and premieres
+-- that have no final stats yet) and record why
+
+ALTER TABLE enrichment_jobs
+ADD COLUMN defer_reason TEXT;
+
+COMMENT ON COLUMN enrichment_jobs.defer_reason IS 'Why the job was deferred or skipped instead of processed (e.g. live_broadcast_content=upcoming)';
+
+ALTER TABLE enrichment_jobs DROP CONSTRAINT chk_status;
+ALTER TABLE enrichment_jobs ADD CONSTRAINT chk_status
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled', 'deferred', 'skipped'));
@@ -0,0 +1,8 @@
+-- Compressed raw responses cannot be decompressed in SQL and are dropped;
+-- the structured enrichment columns are unaffected
+
+ALTER TABLE channel_api_enrichments
+DROP COLUMN raw_api_response_gzip;
+
+ALTER TABLE video_api_enrichments
+DROP COLUMN raw_api_response_gzip;
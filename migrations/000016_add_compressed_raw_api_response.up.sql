@@ -0,0 +1,13 @@
+-- Optionally store raw YouTube API responses gzip-compressed. When
+-- raw_api_response_gzip is set, raw_api_response is left NULL. Existing rows
+-- keep their uncompressed JSONB and are read as before, so no backfill is needed.
+
+ALTER TABLE video_api_enrichments
+ADD COLUMN raw_api_response_gzip BYTEA;
+
+COMMENT ON COLUMN video_api_enrichments.raw_api_response_gzip IS 'Gzip-compressed raw API response JSON; takes precedence over raw_api_response when set';
+
+ALTER TABLE channel_api_enrichments
+ADD COLUMN raw_api_response_gzip BYTEA;
+
+COMMENT ON COLUMN channel_api_enrichments.raw_api_response_gzip IS 'Gzip-compressed raw API response JSON; takes precedence over raw_api_response when set';
@@ -0,0 +1,2 @@
+ALTER TABLE video_api_enrichments
+DROP COLUMN comments_disabled;
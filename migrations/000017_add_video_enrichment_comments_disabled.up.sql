@@ -0,0 +1,7 @@
+-- Distinguish videos with comments disabled (YouTube omits commentCount) from
+-- enrichments where statistics were not fetched
+
+ALTER TABLE video_api_enrichments
+ADD COLUMN comments_disabled BOOLEAN;
+
+COMMENT ON COLUMN video_api_enrichments.comments_disabled IS 'True when comments are disabled and comment_count is therefore NULL; NULL when statistics were not fetched';
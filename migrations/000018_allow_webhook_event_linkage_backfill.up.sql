@@ -0,0 +1,23 @@
+-- Allow video_id and channel_id to be filled in on events stored before they were
+-- extracted. A value may only go from NULL to non-NULL; set values stay immutable.
+
+CREATE OR REPLACE FUNCTION prevent_webhook_events_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'Deleting webhook events is not allowed';
+    ELSIF TG_OP = 'UPDATE' AND OLD.id IS DISTINCT FROM NEW.id THEN
+        RAISE EXCEPTION 'Modifying webhook event IDs is not allowed';
+    ELSIF TG_OP = 'UPDATE' AND (
+        OLD.raw_xml IS DISTINCT FROM NEW.raw_xml OR
+        OLD.content_hash IS DISTINCT FROM NEW.content_hash OR
+        OLD.received_at IS DISTINCT FROM NEW.received_at OR
+        OLD.created_at IS DISTINCT FROM NEW.created_at OR
+        (OLD.video_id IS NOT NULL AND OLD.video_id IS DISTINCT FROM NEW.video_id) OR
+        (OLD.channel_id IS NOT NULL AND OLD.channel_id IS DISTINCT FROM NEW.channel_id)
+    ) THEN
+        RAISE EXCEPTION 'Modifying immutable fields in webhook events is not allowed';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,7 @@
+-- Snapshot of the channel's subscriber count when a video is enriched, so views
+-- can be normalized against channel size at the time
+
+ALTER TABLE video_api_enrichments
+ADD COLUMN channel_subscriber_count_at_enrichment BIGINT;
+
+COMMENT ON COLUMN video_api_enrichments.channel_subscriber_count_at_enrichment IS 'Subscriber count from the latest channel enrichment at video enrichment time; NULL when the channel was never enriched or hides its count';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_videos_availability_check;
+
+ALTER TABLE videos
+DROP COLUMN availability_checked_at,
+DROP COLUMN unavailable_reason,
+DROP COLUMN unavailable_at;
@@ -0,0 +1,15 @@
+-- Track videos removed from YouTube without a deleted-entry notification.
+-- The enricher periodically re-fetches older videos and records those that
+-- videos.list no longer returns.
+
+ALTER TABLE videos
+ADD COLUMN unavailable_at TIMESTAMPTZ,
+ADD COLUMN unavailable_reason TEXT,
+ADD COLUMN availability_checked_at TIMESTAMPTZ;
+
+CREATE INDEX idx_videos_availability_check ON videos(availability_checked_at NULLS FIRST, published_at)
+WHERE unavailable_at IS NULL;
+
+COMMENT ON COLUMN videos.unavailable_at IS 'When the video was found to be removed or unavailable; NULL while available';
+COMMENT ON COLUMN videos.unavailable_reason IS 'Why the video was marked unavailable, e.g. not returned by videos.list';
+COMMENT ON COLUMN videos.availability_checked_at IS 'Last time the availability re-check confirmed the video still exists';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_webhook_events_error_code;
+
+ALTER TABLE webhook_events
+DROP COLUMN error_code;
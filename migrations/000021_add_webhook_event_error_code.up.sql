@@ -0,0 +1,11 @@
+-- Classify webhook event processing failures so causes can be aggregated.
+-- processing_error keeps the free-text detail.
+
+ALTER TABLE webhook_events
+ADD COLUMN error_code TEXT
+    CONSTRAINT webhook_events_error_code_check
+    CHECK (error_code IN ('parse_error', 'dedup_conflict', 'enqueue_failed', 'db_error'));
+
+CREATE INDEX idx_webhook_events_error_code ON webhook_events(error_code) WHERE error_code IS NOT NULL;
+
+COMMENT ON COLUMN webhook_events.error_code IS 'Category of the processing failure; NULL when processing succeeded or the error was recorded without a category';
@@ -0,0 +1,17 @@
+-- Daily snapshots of tracked videos that appeared on a region's mostPopular chart.
+-- Only videos already in the videos table are recorded.
+CREATE TABLE video_trending_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    video_id VARCHAR(20) NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    region_code VARCHAR(2) NOT NULL,
+    snapshot_date DATE NOT NULL,
+    chart_rank INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_video_trending_snapshot UNIQUE (video_id, region_code, snapshot_date)
+);
+
+CREATE INDEX idx_video_trending_snapshots_region_date ON video_trending_snapshots(region_code, snapshot_date DESC);
+
+COMMENT ON TABLE video_trending_snapshots IS 'Tracked videos seen on the videos.list mostPopular chart, one row per video, region and day';
+COMMENT ON COLUMN video_trending_snapshots.chart_rank IS '1-based position on the chart when the snapshot was taken';
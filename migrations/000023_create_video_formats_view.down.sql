@@ -0,0 +1 @@
+DROP VIEW IF EXISTS video_formats;
@@ -0,0 +1,22 @@
+-- Classifies each enriched video as a Short or long-form video from the duration of its latest
+-- enrichment. YouTube does not expose a Shorts flag, so videos up to 180 seconds long are treated
+-- as Shorts. Videos without a parseable, non-zero duration (e.g. live streams) are left out.
+CREATE OR REPLACE VIEW video_formats AS
+SELECT
+    video_id,
+    duration_seconds,
+    CASE WHEN duration_seconds <= 180 THEN 'short' ELSE 'long' END AS format
+FROM (
+    SELECT DISTINCT ON (e.video_id)
+        e.video_id,
+        CASE
+            WHEN e.duration ~ '^P(\d+D)?(T(\d+H)?(\d+M)?(\d+S)?)?$'
+            THEN EXTRACT(EPOCH FROM e.duration::interval)::INTEGER
+        END AS duration_seconds
+    FROM video_api_enrichments e
+    WHERE e.duration IS NOT NULL
+    ORDER BY e.video_id, e.enriched_at DESC
+) latest
+WHERE duration_seconds > 0;
+
+COMMENT ON VIEW video_formats IS 'Shorts (<= 180s) vs long-form classification from the latest enrichment duration';
@@ -0,0 +1,15 @@
+-- Concurrent-viewer time series for livestreams, recorded by the enricher's live viewer poller.
+-- When a stream is no longer live a final row with its new live_broadcast_content and no viewer
+-- count is recorded, which stops polling until a later enrichment sees the video live again.
+CREATE TABLE video_live_viewer_samples (
+    id BIGSERIAL PRIMARY KEY,
+    video_id VARCHAR(20) NOT NULL REFERENCES videos(video_id) ON DELETE CASCADE,
+    live_broadcast_content VARCHAR(50),
+    concurrent_viewers BIGINT,
+    sampled_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_video_live_viewer_samples_video_sampled ON video_live_viewer_samples(video_id, sampled_at DESC);
+
+COMMENT ON TABLE video_live_viewer_samples IS 'Concurrent viewers of live videos, sampled at LIVE_VIEWER_POLL_MINUTES';
+COMMENT ON COLUMN video_live_viewer_samples.live_broadcast_content IS 'snippet.liveBroadcastContent at sample time; NULL when videos.list no longer returned the video';
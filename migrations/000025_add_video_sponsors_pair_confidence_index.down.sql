@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_video_sponsors_video_sponsor ON video_sponsors(video_id, sponsor_id);
+
+DROP INDEX IF EXISTS idx_video_sponsors_pair_confidence;
@@ -0,0 +1,7 @@
+-- Supports picking one row per (video_id, sponsor_id) pair across detection jobs, highest
+-- confidence first (SELECT DISTINCT ON (video_id, sponsor_id) ... ORDER BY confidence DESC).
+-- It covers every query the (video_id, sponsor_id) index served, so that index is replaced.
+CREATE INDEX idx_video_sponsors_pair_confidence
+    ON video_sponsors(video_id, sponsor_id, confidence DESC, detected_at DESC);
+
+DROP INDEX IF EXISTS idx_video_sponsors_video_sponsor;
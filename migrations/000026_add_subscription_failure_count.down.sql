@@ -0,0 +1,9 @@
+-- Abandoned subscriptions have no equivalent in the original status set
+UPDATE pubsub_subscriptions SET status = 'failed' WHERE status = 'abandoned';
+
+ALTER TABLE pubsub_subscriptions DROP CONSTRAINT chk_status;
+ALTER TABLE pubsub_subscriptions ADD CONSTRAINT chk_status
+    CHECK (status IN ('pending', 'active', 'expired', 'failed'));
+
+ALTER TABLE pubsub_subscriptions
+DROP COLUMN failure_count;
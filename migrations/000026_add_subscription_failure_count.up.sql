@@ -0,0 +1,11 @@
+-- Count consecutive renewal failures so subscriptions that keep failing (e.g. deleted
+-- channels) can be abandoned instead of retried forever
+
+ALTER TABLE pubsub_subscriptions
+ADD COLUMN failure_count INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN pubsub_subscriptions.failure_count IS 'Consecutive failed subscribe/renew attempts; reset when the hub accepts a request';
+
+ALTER TABLE pubsub_subscriptions DROP CONSTRAINT chk_status;
+ALTER TABLE pubsub_subscriptions ADD CONSTRAINT chk_status
+    CHECK (status IN ('pending', 'active', 'expired', 'failed', 'abandoned'));
@@ -0,0 +1,21 @@
+-- Audit trail of mutating API operations (channels, videos, subscriptions, sponsor corrections).
+-- before/after hold the resource as returned by the API; before is NULL for creates and after
+-- is NULL for deletes.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    api_key_id VARCHAR(64),
+    actor VARCHAR(255),
+    action VARCHAR(20) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at DESC);
+CREATE INDEX idx_audit_log_resource ON audit_log(resource_type, resource_id, created_at DESC);
+
+COMMENT ON TABLE audit_log IS 'Who changed what through the REST API';
+COMMENT ON COLUMN audit_log.api_key_id IS 'First 12 hex characters of the SHA-256 of the API key used';
+COMMENT ON COLUMN audit_log.actor IS 'Free-form X-Actor request header, e.g. a user name behind a shared key';
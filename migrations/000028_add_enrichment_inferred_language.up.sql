@@ -0,0 +1,7 @@
+-- Language inferred from caption tracks when the API reports no default language.
+-- Kept apart from default_language/default_audio_language, which only hold API-reported values
+
+ALTER TABLE video_api_enrichments
+ADD COLUMN inferred_language VARCHAR(20);
+
+COMMENT ON COLUMN video_api_enrichments.inferred_language IS 'BCP-47 code inferred from caption tracks when the API reported neither default language; not an API field';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_video_api_enrichments_created_at_id;
@@ -0,0 +1,2 @@
+-- Incremental exports page through enrichments in (created_at, id) order
+CREATE INDEX idx_video_api_enrichments_created_at_id ON video_api_enrichments(created_at, id);
@@ -0,0 +1,7 @@
+-- Premieres are uploaded videos scheduled to air like a live stream. The API reports them as
+-- upcoming broadcasts, so they are told apart from upcoming live streams by having a duration
+
+ALTER TABLE video_api_enrichments
+ADD COLUMN is_premiere BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN video_api_enrichments.is_premiere IS 'Upcoming broadcast with a scheduled start and a non-zero duration, i.e. a premiere rather than a live stream; computed at enrichment time';
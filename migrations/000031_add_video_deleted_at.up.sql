@@ -0,0 +1,7 @@
+-- Soft-deleted videos are hidden from video lists but keep their enrichment history and
+-- sponsor relationships, which a hard delete would cascade away.
+
+ALTER TABLE videos
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN videos.deleted_at IS 'When the video was soft-deleted through the API; NULL while listed';
@@ -0,0 +1,7 @@
+-- Oversized notification bodies are stored cut to the configured size. content_hash
+-- is still computed over the full body, so deduplication is unaffected.
+
+ALTER TABLE webhook_events
+ADD COLUMN raw_xml_truncated BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN webhook_events.raw_xml_truncated IS 'raw_xml holds only a prefix of the received body, which exceeded the stored size cap';
@@ -0,0 +1,13 @@
+-- Previous titles of renamed channels. The enricher records a row when channels.list reports
+-- a title different from the stored one, before replacing the stored title.
+CREATE TABLE channel_name_history (
+    id BIGSERIAL PRIMARY KEY,
+    channel_id VARCHAR(30) NOT NULL REFERENCES channels(channel_id) ON DELETE CASCADE,
+    old_title VARCHAR(500) NOT NULL,
+    new_title VARCHAR(500) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_channel_name_history_channel_changed ON channel_name_history(channel_id, changed_at DESC);
+
+COMMENT ON TABLE channel_name_history IS 'Channel title changes detected by channel enrichment';
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS increment_quota_usage_on(DATE, INTEGER, VARCHAR, INTEGER);
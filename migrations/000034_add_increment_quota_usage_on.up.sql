@@ -0,0 +1,44 @@
+-- increment_quota_usage_on records usage against an explicit date rather than CURRENT_DATE.
+-- The quota manager buffers usage in memory and uses it to flush usage recorded before
+-- midnight into the day it belongs to, even when the flush runs after midnight.
+CREATE OR REPLACE FUNCTION increment_quota_usage_on(
+    p_date DATE,
+    p_quota_cost INTEGER,
+    p_operation_type VARCHAR DEFAULT 'other',
+    p_calls INTEGER DEFAULT 1
+)
+RETURNS void AS $$
+BEGIN
+    INSERT INTO api_quota_usage (
+        date,
+        quota_used,
+        quota_limit,
+        operations_count,
+        videos_list_calls,
+        channels_list_calls,
+        search_list_calls,
+        other_calls
+    ) VALUES (
+        p_date,
+        p_quota_cost,
+        10000,  -- Default YouTube API v3 quota
+        p_calls,
+        CASE WHEN p_operation_type = 'videos_list' THEN p_calls ELSE 0 END,
+        CASE WHEN p_operation_type = 'channels_list' THEN p_calls ELSE 0 END,
+        CASE WHEN p_operation_type = 'search_list' THEN p_calls ELSE 0 END,
+        CASE WHEN p_operation_type = 'other' THEN p_calls ELSE 0 END
+    )
+    ON CONFLICT (date) DO UPDATE SET
+        quota_used = api_quota_usage.quota_used + p_quota_cost,
+        operations_count = api_quota_usage.operations_count + p_calls,
+        videos_list_calls = api_quota_usage.videos_list_calls +
+            CASE WHEN p_operation_type = 'videos_list' THEN p_calls ELSE 0 END,
+        channels_list_calls = api_quota_usage.channels_list_calls +
+            CASE WHEN p_operation_type = 'channels_list' THEN p_calls ELSE 0 END,
+        search_list_calls = api_quota_usage.search_list_calls +
+            CASE WHEN p_operation_type = 'search_list' THEN p_calls ELSE 0 END,
+        other_calls = api_quota_usage.other_calls +
+            CASE WHEN p_operation_type = 'other' THEN p_calls ELSE 0 END,
+        updated_at = NOW();
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+ALTER TABLE pubsub_subscriptions
+DROP COLUMN lease_requested_at;
@@ -0,0 +1,7 @@
+-- Record when a subscribe request was last accepted by the hub. The unauthenticated
+-- verification callback may only confirm a lease while such a request is pending.
+
+ALTER TABLE pubsub_subscriptions
+ADD COLUMN lease_requested_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN pubsub_subscriptions.lease_requested_at IS 'When a subscribe request awaiting hub verification was sent; cleared once the verification confirms the lease';
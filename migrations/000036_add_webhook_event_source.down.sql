@@ -0,0 +1,2 @@
+ALTER TABLE webhook_events
+DROP COLUMN source;
@@ -0,0 +1,8 @@
+-- Records whether an event was delivered by the hub or built from a polled channel feed.
+-- Existing rows all came from webhooks.
+
+ALTER TABLE webhook_events
+ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT 'webhook'
+CHECK (source IN ('webhook', 'poll'));
+
+COMMENT ON COLUMN webhook_events.source IS 'webhook for hub deliveries, poll for notifications built from a polled channel feed';
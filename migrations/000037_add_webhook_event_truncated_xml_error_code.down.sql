@@ -0,0 +1,6 @@
+-- truncated_xml has no equivalent in the original set; the detail stays in processing_error
+UPDATE webhook_events SET error_code = NULL WHERE error_code = 'truncated_xml';
+
+ALTER TABLE webhook_events DROP CONSTRAINT webhook_events_error_code_check;
+ALTER TABLE webhook_events ADD CONSTRAINT webhook_events_error_code_check
+    CHECK (error_code IN ('parse_error', 'dedup_conflict', 'enqueue_failed', 'db_error'));
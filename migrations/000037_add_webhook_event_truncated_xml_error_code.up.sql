@@ -0,0 +1,6 @@
+-- Events whose stored raw_xml was truncated cannot be re-parsed when they are
+-- recovered from the database, so they fail with their own category
+
+ALTER TABLE webhook_events DROP CONSTRAINT webhook_events_error_code_check;
+ALTER TABLE webhook_events ADD CONSTRAINT webhook_events_error_code_check
+    CHECK (error_code IN ('parse_error', 'dedup_conflict', 'enqueue_failed', 'db_error', 'truncated_xml'));